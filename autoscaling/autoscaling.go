@@ -43,30 +43,7 @@ func New(auth aws.Auth, region aws.Region) *AutoScaling {
 // Error encapsulates an error returned by the AWS Auto Scaling API.
 //
 // See http://goo.gl/VZGuC for more details.
-type Error struct {
-	// HTTP status code (200, 403, ...)
-	StatusCode int
-	// AutoScaling error code ("UnsupportedOperation", ...)
-	Code string
-	// The error type
-	Type string
-	// The human-oriented error message
-	Message   string
-	RequestId string `xml:"RequestID"`
-}
-
-func (err *Error) Error() string {
-	if err.Code == "" {
-		return err.Message
-	}
-
-	return fmt.Sprintf("%s (%s)", err.Message, err.Code)
-}
-
-type xmlErrors struct {
-	RequestId string  `xml:"RequestId"`
-	Errors    []Error `xml:"Error"`
-}
+type Error = aws.Error
 
 func (as *AutoScaling) query(params map[string]string, resp interface{}) error {
 	params["Version"] = "2011-01-01"
@@ -112,21 +89,7 @@ func (as *AutoScaling) query(params map[string]string, resp interface{}) error {
 }
 
 func buildError(r *http.Response) error {
-	var (
-		err    Error
-		errors xmlErrors
-	)
-	xml.NewDecoder(r.Body).Decode(&errors)
-	if len(errors.Errors) > 0 {
-		err = errors.Errors[0]
-	}
-
-	err.RequestId = errors.RequestId
-	err.StatusCode = r.StatusCode
-	if err.Message == "" {
-		err.Message = r.Status
-	}
-	return &err
+	return aws.BuildError(r)
 }
 
 func multimap(p map[string]string) url.Values {
@@ -10,6 +10,7 @@
 package aws
 
 import (
+	"bytes"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
@@ -18,6 +19,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/vaughan0/go-ini"
@@ -41,39 +43,58 @@ type ServiceInfo struct {
 //
 // See http://goo.gl/d8BP1 for more details.
 type Region struct {
-	Name                   string // the canonical name of this region.
-	EC2Endpoint            string
-	S3Endpoint             string
-	S3BucketEndpoint       string // Not needed by AWS S3. Use ${bucket} for bucket name.
-	S3LocationConstraint   bool   // true if this region requires a LocationConstraint declaration.
-	S3LowercaseBucket      bool   // true if the region requires bucket names to be lower case.
-	SDBEndpoint            string
-	SESEndpoint            string
-	SNSEndpoint            string
-	SQSEndpoint            string
-	IAMEndpoint            string
-	ELBEndpoint            string
-	DynamoDBEndpoint       string
-	CloudWatchServicepoint ServiceInfo
-	AutoScalingEndpoint    string
-	RDSEndpoint            ServiceInfo
-	STSEndpoint            string
-	CloudFormationEndpoint string
-	ECSEndpoint            string
+	Name                    string // the canonical name of this region.
+	EC2Endpoint             string
+	S3Endpoint              string
+	S3BucketEndpoint        string // Not needed by AWS S3. Use ${bucket} for bucket name.
+	S3LocationConstraint    bool   // true if this region requires a LocationConstraint declaration.
+	S3LowercaseBucket       bool   // true if the region requires bucket names to be lower case.
+	S3SignatureV4           bool   // true if the region only accepts S3 requests signed with Signature Version 4.
+	SDBEndpoint             string
+	SESEndpoint             string
+	SNSEndpoint             string
+	SQSEndpoint             string
+	IAMEndpoint             string
+	ELBEndpoint             string
+	DynamoDBEndpoint        string
+	CloudWatchServicepoint  ServiceInfo
+	AutoScalingEndpoint     string
+	RDSEndpoint             ServiceInfo
+	STSEndpoint             string
+	CloudFormationEndpoint  string
+	ECSEndpoint             string
+	DynamoDBStreamsEndpoint string
 }
 
 var Regions = map[string]Region{
 	APNortheast.Name:  APNortheast,
+	APNortheast2.Name: APNortheast2,
+	APSouth.Name:      APSouth,
 	APSoutheast.Name:  APSoutheast,
 	APSoutheast2.Name: APSoutheast2,
+	CACentral.Name:    CACentral,
 	EUCentral.Name:    EUCentral,
 	EUWest.Name:       EUWest,
+	EUWest2.Name:      EUWest2,
 	USEast.Name:       USEast,
 	USWest.Name:       USWest,
 	USWest2.Name:      USWest2,
 	USGovWest.Name:    USGovWest,
+	USGovEast.Name:    USGovEast,
 	SAEast.Name:       SAEast,
 	CNNorth.Name:      CNNorth,
+	CNNorthwest.Name:  CNNorthwest,
+}
+
+// GetRegion returns the region with the given canonical name, such as
+// "eu-central-1". Unlike indexing Regions, it fails for unknown names
+// rather than returning a Region without endpoints.
+func GetRegion(name string) (Region, error) {
+	region, ok := Regions[name]
+	if !ok {
+		return Region{}, fmt.Errorf("unknown AWS region %q", name)
+	}
+	return region, nil
 }
 
 // Designates a signer interface suitable for signing AWS requests, params
@@ -148,35 +169,147 @@ func (s *Service) Query(method, path string, params map[string]string) (resp *ht
 }
 
 func (s *Service) BuildError(r *http.Response) error {
-	errors := ErrorResponse{}
-	xml.NewDecoder(r.Body).Decode(&errors)
+	return BuildError(r)
+}
+
+type ErrorResponse struct {
+	Errors    Error  `xml:"Error"`
+	RequestId string // A unique ID for tracking the request
+}
+
+// Error encapsulates an error returned by an AWS service. The error types of
+// the service packages are aliases of Error.
+type Error struct {
+	// HTTP status code (400, 403, ...)
+	StatusCode int `xml:"-"`
+	// The error type, Sender or Receiver, if known
+	Type string `xml:",omitempty"`
+	// AWS error code ("UnsupportedOperation", "NoSuchKey", ...)
+	Code string
+	// The human-oriented error message
+	Message string
+	// The ID of the failed request, to be given to AWS support
+	RequestId string `xml:",omitempty"`
+	// The ID of the S3 host which handled the request
+	HostId string `xml:",omitempty"`
+	// The S3 bucket the error is about, if any
+	BucketName string `xml:",omitempty"`
+	// Retryable is true if the request failed for a transient reason, such
+	// as throttling or an internal error, and may succeed if sent again.
+	Retryable bool `xml:"-"`
+}
+
+func (err *Error) Error() string {
+	if err.Code == "" {
+		return err.Message
+	}
+
+	return fmt.Sprintf("%s (%s)", err.Message, err.Code)
+}
+
+// xmlErrorResponse holds the error responses of the XML services, which
+// come as <Error> (S3), <ErrorResponse><Error> (query APIs) or
+// <Response><Errors><Error> (EC2) documents.
+type xmlErrorResponse struct {
+	Error
+	RequestID    string  `xml:"RequestID"`
+	Errors       []Error `xml:"Error"`
+	NestedErrors []Error `xml:"Errors>Error"`
+}
+
+// jsonErrorResponse holds the error responses of the JSON services, such as
+// DynamoDB.
+type jsonErrorResponse struct {
+	Type    string `json:"__type"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// BuildError reads the body of the error response r and returns the error
+// it describes, in either the XML or the JSON error format. The request ID
+// and S3 host ID are taken from the response headers when missing from the
+// body.
+func BuildError(r *http.Response) *Error {
+	body, _ := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+
 	var err Error
-	err = errors.Errors
-	err.RequestId = errors.RequestId
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		var resp jsonErrorResponse
+		json.Unmarshal(trimmed, &resp)
+		// The type is of the form com.amazon.coral.validate#ValidationException
+		err.Code = resp.Code
+		if err.Code == "" {
+			err.Code = resp.Type[strings.LastIndex(resp.Type, "#")+1:]
+		}
+		err.Message = resp.Message
+	} else {
+		var resp xmlErrorResponse
+		xml.Unmarshal(body, &resp)
+		switch {
+		case len(resp.Errors) > 0:
+			err = resp.Errors[0]
+		case len(resp.NestedErrors) > 0:
+			err = resp.NestedErrors[0]
+		default:
+			err = resp.Error
+		}
+		if err.RequestId == "" {
+			err.RequestId = resp.RequestId
+		}
+		if err.RequestId == "" {
+			err.RequestId = resp.RequestID
+		}
+	}
+
+	if err.RequestId == "" {
+		err.RequestId = r.Header.Get("X-Amzn-Requestid")
+	}
+	if err.RequestId == "" {
+		err.RequestId = r.Header.Get("X-Amz-Request-Id")
+	}
+	if err.HostId == "" {
+		err.HostId = r.Header.Get("X-Amz-Id-2")
+	}
 	err.StatusCode = r.StatusCode
 	if err.Message == "" {
 		err.Message = r.Status
 	}
+	err.Retryable = r.StatusCode >= 500 || r.StatusCode == 429 || isThrottlingCode(err.Code)
 	return &err
 }
 
-type ErrorResponse struct {
-	Errors    Error  `xml:"Error"`
-	RequestId string // A unique ID for tracking the request
+// AsError returns the *Error held by err, if any. Errors wrapping an *Error
+// are unwrapped through their Unwrap method.
+func AsError(err error) (*Error, bool) {
+	for err != nil {
+		if e, ok := err.(*Error); ok {
+			return e, true
+		}
+		u, ok := err.(interface {
+			Unwrap() error
+		})
+		if !ok {
+			break
+		}
+		err = u.Unwrap()
+	}
+	return nil, false
 }
 
-type Error struct {
-	StatusCode int
-	Type       string
-	Code       string
-	Message    string
-	RequestId  string
+// IsCode reports whether err is an *Error with the given AWS error code.
+func IsCode(err error, code string) bool {
+	e, ok := AsError(err)
+	return ok && e.Code == code
 }
 
-func (err *Error) Error() string {
-	return fmt.Sprintf("Type: %s, Code: %s, Message: %s",
-		err.Type, err.Code, err.Message,
-	)
+// RequestID returns the ID of the failed request of err if it is an *Error,
+// or the empty string.
+func RequestID(err error) string {
+	if e, ok := AsError(err); ok {
+		return e.RequestId
+	}
+	return ""
 }
 
 type Auth struct {
@@ -189,7 +322,9 @@ func (a *Auth) Token() string {
 	if a.token == "" {
 		return ""
 	}
-	if time.Since(a.expiration) >= -30*time.Second { //in an ideal world this should be zero assuming the instance is synching it's clock
+	// A zero expiration means the token is not known to expire, as for
+	// tokens read from the environment or the shared credentials file.
+	if !a.expiration.IsZero() && time.Since(a.expiration) >= -30*time.Second { //in an ideal world this should be zero assuming the instance is synching it's clock
 		*a, _ = GetAuth("", "", "", time.Time{})
 	}
 	return a.token
@@ -271,60 +406,43 @@ func GetMetaData(path string) (contents []byte, err error) {
 	return []byte(body), err
 }
 
-func getInstanceCredentials() (cred credentials, err error) {
-	credentialPath := "iam/security-credentials/"
-
-	// Get the instance role
-	role, err := GetMetaData(credentialPath)
-	if err != nil {
-		return
-	}
-
-	// Get the instance role credentials
-	credentialJSON, err := GetMetaData(credentialPath + string(role))
-	if err != nil {
-		return
-	}
-
-	err = json.Unmarshal([]byte(credentialJSON), &cred)
-	return
-}
-
 // GetAuth creates an Auth based on either passed in credentials,
-// environment information or instance based role credentials.
+// environment information, the shared credentials file, instance based
+// role credentials or the ECS task role, tried in that order.
 func GetAuth(accessKey string, secretKey, token string, expiration time.Time) (auth Auth, err error) {
 	// First try passed in credentials
 	if accessKey != "" && secretKey != "" {
 		return Auth{accessKey, secretKey, token, expiration}, nil
 	}
 
-	// Next try to get auth from the shared credentials file
-	auth, err = SharedAuth()
+	// Next try to get auth from the environment
+	auth, err = EnvAuth()
 	if err == nil {
 		// Found auth, return
 		return
 	}
 
-	// Next try to get auth from the environment
-	auth, err = EnvAuth()
+	// Next try to get auth from the shared credentials file
+	auth, err = SharedAuth("")
 	if err == nil {
 		// Found auth, return
 		return
 	}
 
 	// Next try getting auth from the instance role
-	cred, err := getInstanceCredentials()
+	auth, err = instanceRole.Credentials()
 	if err == nil {
 		// Found auth, return
-		auth.AccessKey = cred.AccessKeyId
-		auth.SecretKey = cred.SecretAccessKey
-		auth.token = cred.Token
-		exptdate, err := time.Parse("2006-01-02T15:04:05Z", cred.Expiration)
-		if err != nil {
-			err = fmt.Errorf("Error Parseing expiration date: cred.Expiration :%s , error: %s \n", cred.Expiration, err)
+		return
+	}
+
+	// Next try getting auth from the ECS task role
+	if os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI") != "" {
+		auth, err = containerRole.Credentials()
+		if err == nil {
+			// Found auth, return
+			return
 		}
-		auth.expiration = exptdate
-		return auth, err
 	}
 	err = errors.New("No valid AWS authentication found")
 	return auth, err
@@ -356,16 +474,27 @@ func EnvAuth() (auth Auth, err error) {
 }
 
 // SharedAuth creates an Auth based on shared credentials stored in
-// $HOME/.aws/credentials. The AWS_PROFILE environment variables is used to
-// select the profile.
-func SharedAuth() (auth Auth, err error) {
-	var profileName = os.Getenv("AWS_PROFILE")
-
-	if profileName == "" {
-		profileName = "default"
+// $HOME/.aws/credentials, or in the file named by the
+// AWS_SHARED_CREDENTIALS_FILE environment variable. An empty profile
+// selects the profile named by the AWS_PROFILE environment variable, or
+// "default". aws_session_token is used if present.
+//
+// Profiles without keys may name a command printing credentials in their
+// credential_process setting, in the credentials file or in the shared
+// config file, $HOME/.aws/config or the file named by the AWS_CONFIG_FILE
+// environment variable. See ProcessProvider for details.
+func SharedAuth(profile string) (auth Auth, err error) {
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
 	}
 
-	var credentialsFile = os.Getenv("AWS_CREDENTIAL_FILE")
+	var credentialsFile = os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = os.Getenv("AWS_CREDENTIAL_FILE")
+	}
 	if credentialsFile == "" {
 		var homeDir = os.Getenv("HOME")
 		if homeDir == "" {
@@ -376,29 +505,69 @@ func SharedAuth() (auth Auth, err error) {
 	}
 
 	file, err := ini.LoadFile(credentialsFile)
+	var section = file[profile]
+	if section["aws_access_key_id"] == "" {
+		command := section["credential_process"]
+		if command == "" {
+			var cerr error
+			if command, cerr = sharedConfigValue(profile, "credential_process"); cerr != nil {
+				return auth, cerr
+			}
+		}
+		if command != "" {
+			return processAuth(command)
+		}
+	}
 	if err != nil {
-		err = errors.New("Couldn't parse AWS credentials file")
+		err = fmt.Errorf("Couldn't parse AWS credentials file %s: %v", credentialsFile, err)
 		return
 	}
 
-	var profile = file[profileName]
-	if profile == nil {
-		err = errors.New("Couldn't find profile in AWS credentials file")
+	if section == nil {
+		err = fmt.Errorf("Couldn't find profile %q in AWS credentials file %s", profile, credentialsFile)
 		return
 	}
 
-	auth.AccessKey = profile["aws_access_key_id"]
-	auth.SecretKey = profile["aws_secret_access_key"]
+	auth.AccessKey = section["aws_access_key_id"]
+	auth.SecretKey = section["aws_secret_access_key"]
+	auth.token = section["aws_session_token"]
 
 	if auth.AccessKey == "" {
-		err = errors.New("AWS_ACCESS_KEY_ID not found in environment in credentials file")
+		err = fmt.Errorf("aws_access_key_id not found in profile %q of AWS credentials file %s", profile, credentialsFile)
 	}
 	if auth.SecretKey == "" {
-		err = errors.New("AWS_SECRET_ACCESS_KEY not found in credentials file")
+		err = fmt.Errorf("aws_secret_access_key not found in profile %q of AWS credentials file %s", profile, credentialsFile)
 	}
 	return
 }
 
+// sharedConfigValue returns the value of key in profile of the shared
+// config file, where profiles other than "default" are in sections named
+// "profile NAME". A missing file has no values.
+func sharedConfigValue(profile, key string) (string, error) {
+	var configFile = os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		var homeDir = os.Getenv("HOME")
+		if homeDir == "" {
+			return "", nil
+		}
+		configFile = homeDir + "/.aws/config"
+	}
+
+	file, err := ini.LoadFile(configFile)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("Couldn't parse AWS config file %s: %v", configFile, err)
+	}
+	if value, ok := file.Get("profile "+profile, key); ok || profile != "default" {
+		return value, nil
+	}
+	value, _ := file.Get("default", key)
+	return value, nil
+}
+
 // Encode takes a string and URI-encodes it in a way suitable
 // to be used in AWS signatures.
 func Encode(s string) string {
@@ -1,7 +1,10 @@
 package aws_test
 
 import (
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"strings"
 	"testing"
@@ -36,7 +39,7 @@ func (s *S) TearDownTest(c *C) {
 func (s *S) TestSharedAuthNoHome(c *C) {
 	os.Clearenv()
 	os.Setenv("AWS_PROFILE", "foo")
-	_, err := aws.SharedAuth()
+	_, err := aws.SharedAuth("")
 	c.Assert(err, ErrorMatches, "Could not get HOME")
 }
 
@@ -44,8 +47,8 @@ func (s *S) TestSharedAuthNoCredentialsFile(c *C) {
 	os.Clearenv()
 	os.Setenv("AWS_PROFILE", "foo")
 	os.Setenv("HOME", "/tmp")
-	_, err := aws.SharedAuth()
-	c.Assert(err, ErrorMatches, "Couldn't parse AWS credentials file")
+	_, err := aws.SharedAuth("")
+	c.Assert(err, ErrorMatches, "Couldn't parse AWS credentials file /tmp/.aws/credentials: .*")
 }
 
 func (s *S) TestSharedAuthNoProfileInFile(c *C) {
@@ -66,8 +69,8 @@ func (s *S) TestSharedAuthNoProfileInFile(c *C) {
 	ioutil.WriteFile(d+"/.aws/credentials", []byte("[bar]\n"), 0644)
 	os.Setenv("HOME", d)
 
-	_, err = aws.SharedAuth()
-	c.Assert(err, ErrorMatches, "Couldn't find profile in AWS credentials file")
+	_, err = aws.SharedAuth("")
+	c.Assert(err, ErrorMatches, `Couldn't find profile "foo" in AWS credentials file .*/\.aws/credentials`)
 }
 
 func (s *S) TestSharedAuthNoKeysInProfile(c *C) {
@@ -88,8 +91,8 @@ func (s *S) TestSharedAuthNoKeysInProfile(c *C) {
 	ioutil.WriteFile(d+"/.aws/credentials", []byte("[bar]\nawsaccesskeyid = AK.."), 0644)
 	os.Setenv("HOME", d)
 
-	_, err = aws.SharedAuth()
-	c.Assert(err, ErrorMatches, "AWS_SECRET_ACCESS_KEY not found in credentials file")
+	_, err = aws.SharedAuth("")
+	c.Assert(err, ErrorMatches, `aws_secret_access_key not found in profile "bar" of AWS credentials file .*/\.aws/credentials`)
 }
 
 func (s *S) TestSharedAuthDefaultCredentials(c *C) {
@@ -109,7 +112,7 @@ func (s *S) TestSharedAuthDefaultCredentials(c *C) {
 	ioutil.WriteFile(d+"/.aws/credentials", []byte("[default]\naws_access_key_id = access\naws_secret_access_key = secret\n"), 0644)
 	os.Setenv("HOME", d)
 
-	auth, err := aws.SharedAuth()
+	auth, err := aws.SharedAuth("")
 	c.Assert(err, IsNil)
 	c.Assert(auth, Equals, aws.Auth{SecretKey: "secret", AccessKey: "access"})
 }
@@ -132,11 +135,55 @@ func (s *S) TestSharedAuth(c *C) {
 	ioutil.WriteFile(d+"/.aws/credentials", []byte("[bar]\naws_access_key_id = access\naws_secret_access_key = secret\n"), 0644)
 	os.Setenv("HOME", d)
 
-	auth, err := aws.SharedAuth()
+	auth, err := aws.SharedAuth("")
 	c.Assert(err, IsNil)
 	c.Assert(auth, Equals, aws.Auth{SecretKey: "secret", AccessKey: "access"})
 }
 
+func (s *S) TestSharedAuthProfile(c *C) {
+	os.Clearenv()
+	os.Setenv("AWS_PROFILE", "bar")
+
+	f, err := ioutil.TempFile("", "")
+	if err != nil {
+		panic(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("[bar]\naws_access_key_id = access\naws_secret_access_key = secret\n" +
+		"[baz]\naws_access_key_id = access2\naws_secret_access_key = secret2\naws_session_token = token2\n")
+	f.Close()
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", f.Name())
+
+	auth, err := aws.SharedAuth("baz")
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "access2")
+	c.Assert(auth.SecretKey, Equals, "secret2")
+	c.Assert(auth.Token(), Equals, "token2")
+
+	auth, err = aws.SharedAuth("")
+	c.Assert(err, IsNil)
+	c.Assert(auth, Equals, aws.Auth{SecretKey: "secret", AccessKey: "access"})
+
+	_, err = aws.SharedAuth("missing")
+	c.Assert(err, ErrorMatches, `Couldn't find profile "missing" in AWS credentials file `+f.Name())
+}
+
+func (s *S) TestSharedAuthMalformedFile(c *C) {
+	os.Clearenv()
+
+	f, err := ioutil.TempFile("", "")
+	if err != nil {
+		panic(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("[default]\naws_access_key_id access\n")
+	f.Close()
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", f.Name())
+
+	_, err = aws.SharedAuth("")
+	c.Assert(err, ErrorMatches, "Couldn't parse AWS credentials file "+f.Name()+": .*line 2.*")
+}
+
 func (s *S) TestEnvAuthNoSecret(c *C) {
 	os.Clearenv()
 	_, err := aws.EnvAuth()
@@ -209,3 +256,170 @@ func (s *S) TestRegionsAreNamed(c *C) {
 		c.Assert(n, Equals, r.Name)
 	}
 }
+
+func (s *S) TestRegionsHaveEndpoints(c *C) {
+	for n, r := range aws.Regions {
+		endpoints := map[string]string{
+			"EC2":             r.EC2Endpoint,
+			"S3":              r.S3Endpoint,
+			"SNS":             r.SNSEndpoint,
+			"SQS":             r.SQSEndpoint,
+			"IAM":             r.IAMEndpoint,
+			"ELB":             r.ELBEndpoint,
+			"DynamoDB":        r.DynamoDBEndpoint,
+			"CloudWatch":      r.CloudWatchServicepoint.Endpoint,
+			"AutoScaling":     r.AutoScalingEndpoint,
+			"RDS":             r.RDSEndpoint.Endpoint,
+			"STS":             r.STSEndpoint,
+			"CloudFormation":  r.CloudFormationEndpoint,
+			"ECS":             r.ECSEndpoint,
+			"DynamoDBStreams": r.DynamoDBStreamsEndpoint,
+		}
+		for service, endpoint := range endpoints {
+			c.Check(endpoint, Matches, "https://.*", Commentf("%s endpoint of %s", service, n))
+		}
+	}
+}
+
+func (s *S) TestGetRegion(c *C) {
+	r, err := aws.GetRegion("eu-central-1")
+	c.Assert(err, IsNil)
+	c.Assert(r, Equals, aws.EUCentral)
+	c.Assert(r.S3SignatureV4, Equals, true)
+
+	for _, name := range []string{"ap-south-1", "ap-northeast-2", "ca-central-1", "eu-west-2", "cn-northwest-1", "us-gov-east-1"} {
+		r, err := aws.GetRegion(name)
+		c.Assert(err, IsNil)
+		c.Assert(r.Name, Equals, name)
+	}
+
+	_, err = aws.GetRegion("mars-north-1")
+	c.Assert(err, ErrorMatches, `unknown AWS region "mars-north-1"`)
+}
+
+func errorResponse(status int, header http.Header, body string) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+var buildErrorTests = []struct {
+	status int
+	header http.Header
+	body   string
+	err    aws.Error
+}{{
+	// Query APIs
+	400, nil, `<ErrorResponse>
+  <Error>
+    <Type>Sender</Type>
+    <Code>ValidationError</Code>
+    <Message>Stack does not exist</Message>
+  </Error>
+  <RequestId>70a76d42-9665-11e2-9fdf-211deEXAMPLE</RequestId>
+</ErrorResponse>`,
+	aws.Error{StatusCode: 400, Type: "Sender", Code: "ValidationError", Message: "Stack does not exist", RequestId: "70a76d42-9665-11e2-9fdf-211deEXAMPLE"},
+}, {
+	// EC2
+	503, nil, `<Response>
+  <Errors><Error><Code>RequestLimitExceeded</Code><Message>Request limit exceeded.</Message></Error></Errors>
+  <RequestID>0503f4e9-bbd6-483c-b54f-c4ae9f3b30f4</RequestID>
+</Response>`,
+	aws.Error{StatusCode: 503, Code: "RequestLimitExceeded", Message: "Request limit exceeded.", RequestId: "0503f4e9-bbd6-483c-b54f-c4ae9f3b30f4", Retryable: true},
+}, {
+	// S3
+	404, http.Header{"X-Amz-Id-2": {"header-host-id"}}, `<Error>
+  <Code>NoSuchBucket</Code>
+  <Message>The specified bucket does not exist</Message>
+  <BucketName>bucket</BucketName>
+  <RequestId>3F1B667FAD71C3D8</RequestId>
+  <HostId>L4ee/zrm1irFXY5F45fKXIRdOf9ktsKY</HostId>
+</Error>`,
+	aws.Error{StatusCode: 404, Code: "NoSuchBucket", Message: "The specified bucket does not exist", BucketName: "bucket", RequestId: "3F1B667FAD71C3D8", HostId: "L4ee/zrm1irFXY5F45fKXIRdOf9ktsKY"},
+}, {
+	// S3 HEAD responses have no body
+	403, http.Header{"X-Amz-Request-Id": {"3F1B667FAD71C3D8"}, "X-Amz-Id-2": {"host-id"}}, "",
+	aws.Error{StatusCode: 403, Message: "403 Forbidden", RequestId: "3F1B667FAD71C3D8", HostId: "host-id"},
+}, {
+	// DynamoDB
+	400, http.Header{"X-Amzn-Requestid": {"LDM6CJP8RMQ1FHKSC1RBVJFPNVV4KQNSO5AEMF66Q9ASUAAJG"}},
+	`{"__type":"com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException","message":"Rate exceeded"}`,
+	aws.Error{StatusCode: 400, Code: "ProvisionedThroughputExceededException", Message: "Rate exceeded", RequestId: "LDM6CJP8RMQ1FHKSC1RBVJFPNVV4KQNSO5AEMF66Q9ASUAAJG", Retryable: true},
+}, {
+	400, nil, `{"__type":"ResourceNotFoundException","Message":"Requested resource not found"}`,
+	aws.Error{StatusCode: 400, Code: "ResourceNotFoundException", Message: "Requested resource not found"},
+}, {
+	500, nil, "not an error document",
+	aws.Error{StatusCode: 500, Message: "500 Internal Server Error", Retryable: true},
+}}
+
+func (s *S) TestBuildError(c *C) {
+	for i, t := range buildErrorTests {
+		err := aws.BuildError(errorResponse(t.status, t.header, t.body))
+		c.Check(*err, Equals, t.err, Commentf("test %d", i))
+	}
+}
+
+func (s *S) TestErrorString(c *C) {
+	c.Assert((&aws.Error{Code: "NoSuchKey", Message: "The specified key does not exist."}).Error(), Equals, "The specified key does not exist. (NoSuchKey)")
+	c.Assert((&aws.Error{Message: "500 Internal Server Error"}).Error(), Equals, "500 Internal Server Error")
+}
+
+// wrappedError wraps an error as the error types of some packages do.
+type wrappedError struct {
+	err error
+}
+
+func (e *wrappedError) Error() string { return e.err.Error() }
+func (e *wrappedError) Unwrap() error { return e.err }
+
+func (s *S) TestErrorHelpers(c *C) {
+	var err error = &aws.Error{Code: "Throttling", RequestId: "request-id"}
+	c.Assert(aws.IsCode(err, "Throttling"), Equals, true)
+	c.Assert(aws.IsCode(err, "NoSuchKey"), Equals, false)
+	c.Assert(aws.RequestID(err), Equals, "request-id")
+
+	err = &wrappedError{err}
+	c.Assert(aws.IsCode(err, "Throttling"), Equals, true)
+	c.Assert(aws.RequestID(err), Equals, "request-id")
+	awsErr, ok := aws.AsError(err)
+	c.Assert(ok, Equals, true)
+	c.Assert(awsErr.Code, Equals, "Throttling")
+
+	err = errors.New("Throttling")
+	c.Assert(aws.IsCode(err, "Throttling"), Equals, false)
+	c.Assert(aws.RequestID(err), Equals, "")
+	c.Assert(aws.IsCode(nil, "Throttling"), Equals, false)
+	_, ok = aws.AsError(err)
+	c.Assert(ok, Equals, false)
+}
+
+func (s *S) TestGetAuthEnvBeforeShared(c *C) {
+	os.Clearenv()
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	os.Setenv("AWS_ACCESS_KEY_ID", "access")
+
+	f, err := ioutil.TempFile("", "")
+	if err != nil {
+		panic(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("[default]\naws_access_key_id = shared-access\naws_secret_access_key = shared-secret\n")
+	f.Close()
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", f.Name())
+
+	auth, err := aws.GetAuth("", "", "", time.Time{})
+	c.Assert(err, IsNil)
+	c.Assert(auth, Equals, aws.Auth{SecretKey: "secret", AccessKey: "access"})
+
+	os.Unsetenv("AWS_ACCESS_KEY_ID")
+	auth, err = aws.GetAuth("", "", "", time.Time{})
+	c.Assert(err, IsNil)
+	c.Assert(auth, Equals, aws.Auth{SecretKey: "shared-secret", AccessKey: "shared-access"})
+}
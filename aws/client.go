@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"crypto/tls"
 	"math"
 	"net"
 	"net/http"
@@ -52,6 +53,18 @@ func NewClient(rt *ResilientTransport) *http.Client {
 	}
 }
 
+// NewInsecureClient returns an http.Client which does not verify the TLS
+// certificates of servers. It is only meant for test servers using
+// self-signed certificates.
+func NewInsecureClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+}
+
 var retryingTransport = &ResilientTransport{
 	Deadline: func() time.Time {
 		return time.Now().Add(5 * time.Second)
@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Error codes returned by the services when the time of a request is too
+// far from their own. Some are also returned for other reasons, so a
+// response is only taken as a skew error if its Date header is off too.
+var clockSkewCodes = []string{
+	"RequestTimeTooSkewed",
+	"RequestExpired",
+	"RequestInTheFuture",
+	"InvalidSignatureException",
+	"SignatureDoesNotMatch",
+	"AuthFailure",
+}
+
+// ClockSkewTolerance is how far the time reported by a service may be from
+// the local time, adjusted by the current offset, before a skew error is
+// handled by adjusting the offset.
+var ClockSkewTolerance = time.Minute
+
+// Clock tracks the offset between the local clock and the clock of AWS,
+// learnt from the Date header of skew errors, so that requests are signed
+// with the time of AWS even if the local clock drifts.
+//
+// The zero value has no offset. Clock is safe for concurrent use.
+type Clock struct {
+	offset atomic.Int64
+}
+
+// Offset returns how far the AWS clock is ahead of the local clock.
+func (c *Clock) Offset() time.Duration {
+	return time.Duration(c.offset.Load())
+}
+
+// Now returns the current AWS time, which requests should be signed with.
+func (c *Clock) Now() time.Time {
+	return time.Now().Add(c.Offset())
+}
+
+// Adjust reports whether resp is an error caused by the skew of the clock,
+// in which case it updates the offset from the Date header of resp. The
+// body of error responses is read in memory to be inspected, and is
+// replaced by a reader over the same content.
+func (c *Clock) Adjust(resp *http.Response) bool {
+	if resp == nil || resp.StatusCode < 400 || resp.StatusCode >= 500 {
+		return false
+	}
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return false
+	}
+	offset := date.Sub(time.Now())
+	if skew := offset - c.Offset(); skew > -ClockSkewTolerance && skew < ClockSkewTolerance {
+		return false
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	for _, code := range clockSkewCodes {
+		if bytes.Contains(body, []byte("<Code>"+code+"</Code>")) || bytes.Contains(body, []byte("#"+code+`"`)) || bytes.Contains(body, []byte(`"`+code+`"`)) {
+			c.offset.Store(int64(offset))
+			return true
+		}
+	}
+	return false
+}
+
+// Do sends the request returned by sign with policy and client. sign is
+// given the time to sign the request with. If the request fails because
+// of the skew of the clock, the offset is adjusted and the request is
+// signed and sent once more.
+func (c *Clock) Do(policy *RetryPolicy, client *http.Client, sign func(now time.Time) (*http.Request, error)) (*http.Response, error) {
+	req, err := sign(c.Now())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := policy.Do(client, req)
+	if err != nil || !c.Adjust(resp) {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	req, err = sign(c.Now())
+	if err != nil {
+		return nil, err
+	}
+	return policy.Do(client, req)
+}
@@ -0,0 +1,121 @@
+package aws_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+	. "gopkg.in/check.v1"
+)
+
+// skewedServer returns a server whose clock is ahead of the local one by
+// skew. It rejects requests whose X-Amz-Date is more than five minutes off
+// its own time with code, and counts the requests it gets.
+func skewedServer(skew time.Duration, code string, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		now := time.Now().Add(skew)
+		w.Header().Set("Date", now.UTC().Format(http.TimeFormat))
+		signed, err := time.Parse(aws.ISO8601BasicFormat, r.Header.Get("X-Amz-Date"))
+		if err != nil || signed.Sub(now) > 5*time.Minute || now.Sub(signed) > 5*time.Minute {
+			w.WriteHeader(403)
+			fmt.Fprintf(w, "<Error><Code>%s</Code><Message>The difference between the request time and the current time is too large.</Message></Error>", code)
+			return
+		}
+		fmt.Fprint(w, "<Response/>")
+	}))
+}
+
+func signedRequest(url string) func(time.Time) (*http.Request, error) {
+	return func(now time.Time) (*http.Request, error) {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-Amz-Date", now.UTC().Format(aws.ISO8601BasicFormat))
+		return req, nil
+	}
+}
+
+func (s *S) TestClockDo(c *C) {
+	var requests int32
+	ts := skewedServer(10*time.Minute, "RequestTimeTooSkewed", &requests)
+	defer ts.Close()
+
+	var clock aws.Clock
+	c.Assert(clock.Offset(), Equals, time.Duration(0))
+
+	resp, err := clock.Do(&aws.NoRetry, http.DefaultClient, signedRequest(ts.URL))
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, 200)
+	c.Assert(requests, Equals, int32(2))
+
+	// The offset has the precision of the Date header
+	offset := clock.Offset()
+	c.Assert(offset > 10*time.Minute-2*time.Second && offset < 10*time.Minute+2*time.Second, Equals, true, Commentf("offset %v", offset))
+	c.Assert(clock.Now().Sub(time.Now()) > 9*time.Minute, Equals, true)
+
+	// The offset applies to the following requests
+	resp, err = clock.Do(&aws.NoRetry, http.DefaultClient, signedRequest(ts.URL))
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, 200)
+	c.Assert(requests, Equals, int32(3))
+}
+
+func (s *S) TestClockDoRetriesOnce(c *C) {
+	var requests int32
+	ts := skewedServer(10*time.Minute, "RequestTimeTooSkewed", &requests)
+	defer ts.Close()
+
+	// A request signed with a fixed time keeps failing
+	var clock aws.Clock
+	sign := func(time.Time) (*http.Request, error) {
+		return signedRequest(ts.URL)(time.Now())
+	}
+	resp, err := clock.Do(&aws.NoRetry, http.DefaultClient, sign)
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, 403)
+	c.Assert(requests, Equals, int32(2))
+}
+
+func (s *S) TestClockAdjust(c *C) {
+	var requests int32
+	ts := skewedServer(10*time.Minute, "AccessDenied", &requests)
+	defer ts.Close()
+
+	// Other errors don't adjust the clock
+	var clock aws.Clock
+	resp, err := clock.Do(&aws.NoRetry, http.DefaultClient, signedRequest(ts.URL))
+	c.Assert(err, IsNil)
+	c.Assert(resp.StatusCode, Equals, 403)
+	c.Assert(requests, Equals, int32(1))
+	c.Assert(clock.Offset(), Equals, time.Duration(0))
+
+	// The body is still readable
+	c.Assert(aws.IsCode(aws.BuildError(resp), "AccessDenied"), Equals, true)
+	resp.Body.Close()
+}
+
+func (s *S) TestClockAdjustWithinTolerance(c *C) {
+	var requests int32
+	ts := skewedServer(-10*time.Second, "AuthFailure", &requests)
+	defer ts.Close()
+
+	// The server rejects every request, but its clock is close enough
+	var clock aws.Clock
+	sign := func(time.Time) (*http.Request, error) {
+		return http.NewRequest("GET", ts.URL, nil)
+	}
+	resp, err := clock.Do(&aws.NoRetry, http.DefaultClient, sign)
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, 403)
+	c.Assert(requests, Equals, int32(1))
+	c.Assert(clock.Offset(), Equals, time.Duration(0))
+}
@@ -0,0 +1,282 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialsProvider is implemented by sources of credentials that may
+// change over time, such as temporary credentials which must be renewed
+// before they expire.
+type CredentialsProvider interface {
+	// Credentials returns valid credentials, renewing them if needed.
+	Credentials() (Auth, error)
+}
+
+// CurrentAuth returns the credentials of provider, or auth if provider is
+// nil. Service clients call it before signing each request so that renewed
+// credentials are picked up without creating a new client.
+func CurrentAuth(auth Auth, provider CredentialsProvider) (Auth, error) {
+	if provider == nil {
+		return auth, nil
+	}
+	return provider.Credentials()
+}
+
+// DefaultExpiryWindow is how long before their expiration temporary
+// credentials are renewed by default.
+const DefaultExpiryWindow = 5 * time.Minute
+
+const (
+	defaultMetadataEndpoint  = "http://169.254.169.254/latest/meta-data/"
+	defaultContainerEndpoint = "http://169.254.170.2"
+)
+
+// credentialsCache holds credentials until shortly before they expire.
+// Credentials without an expiration are held forever.
+type credentialsCache struct {
+	mu   sync.Mutex
+	auth Auth
+}
+
+// get returns the cached credentials, calling renew for new ones if they
+// expire within window. Concurrent callers wait for a single renewal.
+func (c *credentialsCache) get(window time.Duration, renew func() (Auth, error)) (Auth, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if window == 0 {
+		window = DefaultExpiryWindow
+	}
+	if c.auth.AccessKey != "" && (c.auth.expiration.IsZero() || time.Now().Add(window).Before(c.auth.expiration)) {
+		return c.auth, nil
+	}
+
+	auth, err := renew()
+	if err != nil {
+		return Auth{}, err
+	}
+	c.auth = auth
+	return auth, nil
+}
+
+// RenewingProvider provides credentials obtained from a renewal function,
+// such as temporary credentials issued by STS. The credentials are cached
+// and renewed shortly before their expiration.
+//
+// RenewingProvider is safe for concurrent use. Concurrent callers needing
+// a renewal wait for a single call to the renewal function.
+type RenewingProvider struct {
+	// Renew returns new credentials. Credentials without an expiration
+	// are never renewed.
+	Renew func() (Auth, error)
+
+	// ExpiryWindow is how long before their expiration credentials are
+	// renewed. It defaults to DefaultExpiryWindow.
+	ExpiryWindow time.Duration
+
+	cache credentialsCache
+}
+
+// NewRenewingProvider returns a RenewingProvider getting credentials
+// from renew.
+func NewRenewingProvider(renew func() (Auth, error)) *RenewingProvider {
+	return &RenewingProvider{Renew: renew}
+}
+
+// Credentials returns the cached credentials, renewing them if they are
+// about to expire.
+func (p *RenewingProvider) Credentials() (Auth, error) {
+	return p.cache.get(p.ExpiryWindow, p.Renew)
+}
+
+// InstanceRoleProvider provides the temporary credentials of the IAM role
+// of the EC2 instance, as published by the instance metadata service. The
+// credentials are cached and renewed shortly before they expire.
+//
+// InstanceRoleProvider is safe for concurrent use. Concurrent callers
+// needing a renewal wait for a single request to the metadata service.
+type InstanceRoleProvider struct {
+	// Endpoint is the base URL of the metadata service. It defaults to
+	// http://169.254.169.254/latest/meta-data/.
+	Endpoint string
+
+	// Client performs the requests to the metadata service. It defaults
+	// to RetryingClient.
+	Client *http.Client
+
+	// ExpiryWindow is how long before their expiration credentials are
+	// renewed. It defaults to DefaultExpiryWindow.
+	ExpiryWindow time.Duration
+
+	cache credentialsCache
+}
+
+// NewInstanceRoleProvider returns an InstanceRoleProvider using the
+// metadata service of the current instance.
+func NewInstanceRoleProvider() *InstanceRoleProvider {
+	return &InstanceRoleProvider{}
+}
+
+// instanceRole is shared by GetAuth callers so the credentials are only
+// fetched once per renewal.
+var instanceRole = NewInstanceRoleProvider()
+
+// Credentials returns the cached credentials of the instance role, fetching
+// new ones from the metadata service if they are about to expire.
+func (p *InstanceRoleProvider) Credentials() (Auth, error) {
+	return p.cache.get(p.ExpiryWindow, p.fetch)
+}
+
+func (p *InstanceRoleProvider) fetch() (Auth, error) {
+	credentialPath := "iam/security-credentials/"
+
+	// Get the instance role
+	role, err := p.get(credentialPath)
+	if err != nil {
+		return Auth{}, err
+	}
+	// Several roles are listed one per line, use the first one
+	name := strings.TrimSpace(strings.SplitN(string(role), "\n", 2)[0])
+	if name == "" {
+		return Auth{}, fmt.Errorf("no IAM role found in instance metadata")
+	}
+
+	// Get the instance role credentials
+	credentialJSON, err := p.get(credentialPath + name)
+	if err != nil {
+		return Auth{}, err
+	}
+
+	return parseCredentials(credentialJSON, fmt.Sprintf("IAM role %q", name))
+}
+
+func (p *InstanceRoleProvider) get(path string) ([]byte, error) {
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = defaultMetadataEndpoint
+	}
+	client := p.Client
+	if client == nil {
+		client = RetryingClient
+	}
+
+	return fetchCredentials(client, strings.TrimSuffix(endpoint, "/")+"/"+path)
+}
+
+// ContainerProvider provides the temporary credentials of the IAM role of
+// the ECS task, as served by the container credentials endpoint at the
+// path given by the AWS_CONTAINER_CREDENTIALS_RELATIVE_URI environment
+// variable. The credentials are cached and renewed shortly before they
+// expire.
+//
+// ContainerProvider is safe for concurrent use. Concurrent callers needing
+// a renewal wait for a single request to the credentials endpoint.
+type ContainerProvider struct {
+	// Endpoint is the base URL of the container credentials endpoint.
+	// It defaults to http://169.254.170.2.
+	Endpoint string
+
+	// RelativeURI is the path of the task credentials under Endpoint. It
+	// defaults to the AWS_CONTAINER_CREDENTIALS_RELATIVE_URI environment
+	// variable.
+	RelativeURI string
+
+	// Client performs the requests to the credentials endpoint. It
+	// defaults to RetryingClient.
+	Client *http.Client
+
+	// ExpiryWindow is how long before their expiration credentials are
+	// renewed. It defaults to DefaultExpiryWindow.
+	ExpiryWindow time.Duration
+
+	cache credentialsCache
+}
+
+// NewContainerProvider returns a ContainerProvider using the credentials
+// endpoint of the current ECS task.
+func NewContainerProvider() *ContainerProvider {
+	return &ContainerProvider{}
+}
+
+// containerRole is shared by GetAuth callers so the credentials are only
+// fetched once per renewal.
+var containerRole = NewContainerProvider()
+
+// Credentials returns the cached credentials of the task role, fetching new
+// ones from the credentials endpoint if they are about to expire.
+func (p *ContainerProvider) Credentials() (Auth, error) {
+	return p.cache.get(p.ExpiryWindow, p.fetch)
+}
+
+func (p *ContainerProvider) fetch() (Auth, error) {
+	uri := p.RelativeURI
+	if uri == "" {
+		uri = os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI")
+	}
+	if uri == "" {
+		return Auth{}, errors.New("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI not found in environment")
+	}
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = defaultContainerEndpoint
+	}
+	client := p.Client
+	if client == nil {
+		client = RetryingClient
+	}
+
+	credentialJSON, err := fetchCredentials(client, strings.TrimSuffix(endpoint, "/")+"/"+strings.TrimPrefix(uri, "/"))
+	if err != nil {
+		return Auth{}, err
+	}
+	return parseCredentials(credentialJSON, "ECS task role")
+}
+
+// fetchCredentials returns the body of the response to a GET of url.
+func fetchCredentials(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("Code %d returned for url %s", resp.StatusCode, url)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// parseCredentials parses the temporary credentials of role, in the JSON
+// format used by the instance metadata service and the container
+// credentials endpoint.
+func parseCredentials(data []byte, role string) (Auth, error) {
+	var cred credentials
+	if err := json.Unmarshal(data, &cred); err != nil {
+		return Auth{}, fmt.Errorf("cannot parse credentials of %s: %v", role, err)
+	}
+	if cred.Code != "" && cred.Code != "Success" {
+		return Auth{}, fmt.Errorf("cannot get credentials of %s: %s", role, cred.Code)
+	}
+	if cred.AccessKeyId == "" || cred.SecretAccessKey == "" {
+		return Auth{}, fmt.Errorf("no credentials found for %s", role)
+	}
+
+	expiration, err := time.Parse(time.RFC3339, cred.Expiration)
+	if err != nil {
+		return Auth{}, fmt.Errorf("cannot parse expiration of %s credentials: %v", role, err)
+	}
+	return Auth{
+		AccessKey:  cred.AccessKeyId,
+		SecretKey:  cred.SecretAccessKey,
+		token:      cred.Token,
+		expiration: expiration,
+	}, nil
+}
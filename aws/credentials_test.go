@@ -0,0 +1,281 @@
+package aws_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+	. "gopkg.in/check.v1"
+)
+
+// fakeMetadata serves the credentials of the "test-role" IAM role, with
+// keys numbered by the number of credential requests served so far.
+type fakeMetadata struct {
+	server     *httptest.Server
+	fetches    int32
+	expiration time.Duration
+	delay      time.Duration
+	body       string
+}
+
+func newFakeMetadata(expiration time.Duration) *fakeMetadata {
+	m := &fakeMetadata{expiration: expiration}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest/meta-data/iam/security-credentials/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "test-role")
+	})
+	mux.HandleFunc("/latest/meta-data/iam/security-credentials/test-role", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&m.fetches, 1)
+		time.Sleep(m.delay)
+		if m.body != "" {
+			fmt.Fprint(w, m.body)
+			return
+		}
+		fmt.Fprintf(w, `{
+  "Code" : "Success",
+  "LastUpdated" : "2014-12-12T12:00:00Z",
+  "Type" : "AWS-HMAC",
+  "AccessKeyId" : "access-%d",
+  "SecretAccessKey" : "secret-%d",
+  "Token" : "token-%d",
+  "Expiration" : "%s"
+}`, n, n, n, time.Now().Add(m.expiration).UTC().Format(time.RFC3339))
+	})
+	m.server = httptest.NewServer(mux)
+	return m
+}
+
+func (m *fakeMetadata) provider() *aws.InstanceRoleProvider {
+	p := aws.NewInstanceRoleProvider()
+	p.Endpoint = m.server.URL + "/latest/meta-data/"
+	return p
+}
+
+func (s *S) TestInstanceRoleProvider(c *C) {
+	m := newFakeMetadata(time.Hour)
+	defer m.server.Close()
+	p := m.provider()
+
+	for i := 0; i < 3; i++ {
+		auth, err := p.Credentials()
+		c.Assert(err, IsNil)
+		c.Assert(auth.AccessKey, Equals, "access-1")
+		c.Assert(auth.SecretKey, Equals, "secret-1")
+		c.Assert(auth.Token(), Equals, "token-1")
+		c.Assert(auth.Expiration().After(time.Now().Add(50*time.Minute)), Equals, true)
+	}
+	c.Assert(atomic.LoadInt32(&m.fetches), Equals, int32(1))
+}
+
+func (s *S) TestInstanceRoleProviderRefresh(c *C) {
+	// The credentials expire within the expiry window, so each call
+	// renews them.
+	m := newFakeMetadata(3 * time.Minute)
+	defer m.server.Close()
+	p := m.provider()
+
+	auth, err := p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "access-1")
+
+	auth, err = p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "access-2")
+
+	p.ExpiryWindow = time.Minute
+	auth, err = p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "access-2")
+	c.Assert(atomic.LoadInt32(&m.fetches), Equals, int32(2))
+}
+
+func (s *S) TestInstanceRoleProviderSingleFlight(c *C) {
+	m := newFakeMetadata(time.Hour)
+	m.delay = 50 * time.Millisecond
+	defer m.server.Close()
+	p := m.provider()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			auth, err := p.Credentials()
+			c.Check(err, IsNil)
+			c.Check(auth.AccessKey, Equals, "access-1")
+		}()
+	}
+	wg.Wait()
+	c.Assert(atomic.LoadInt32(&m.fetches), Equals, int32(1))
+}
+
+func (s *S) TestInstanceRoleProviderErrors(c *C) {
+	m := newFakeMetadata(time.Hour)
+	defer m.server.Close()
+	p := m.provider()
+
+	m.body = "not json"
+	_, err := p.Credentials()
+	c.Assert(err, ErrorMatches, `cannot parse credentials of IAM role "test-role": .*`)
+
+	m.body = `{"Code": "AssumeRoleUnauthorizedAccess"}`
+	_, err = p.Credentials()
+	c.Assert(err, ErrorMatches, `cannot get credentials of IAM role "test-role": AssumeRoleUnauthorizedAccess`)
+
+	p.Endpoint = m.server.URL + "/missing/"
+	_, err = p.Credentials()
+	c.Assert(err, ErrorMatches, "Code 404 returned for url .*/missing/iam/security-credentials/")
+}
+
+// fakeContainer serves ECS task credentials at /v2/credentials/task-id,
+// with keys numbered like fakeMetadata.
+type fakeContainer struct {
+	server     *httptest.Server
+	fetches    int32
+	expiration time.Duration
+	body       string
+}
+
+func newFakeContainer(expiration time.Duration) *fakeContainer {
+	m := &fakeContainer{expiration: expiration}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/credentials/task-id", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&m.fetches, 1)
+		if m.body != "" {
+			fmt.Fprint(w, m.body)
+			return
+		}
+		fmt.Fprintf(w, `{
+  "RoleArn": "arn:aws:iam::123456789012:role/task-role",
+  "AccessKeyId": "access-%d",
+  "SecretAccessKey": "secret-%d",
+  "Token": "token-%d",
+  "Expiration": "%s"
+}`, n, n, n, time.Now().Add(m.expiration).UTC().Format(time.RFC3339))
+	})
+	m.server = httptest.NewServer(mux)
+	return m
+}
+
+func (m *fakeContainer) provider() *aws.ContainerProvider {
+	p := aws.NewContainerProvider()
+	p.Endpoint = m.server.URL
+	return p
+}
+
+func (s *S) TestContainerProvider(c *C) {
+	m := newFakeContainer(time.Hour)
+	defer m.server.Close()
+	os.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "/v2/credentials/task-id")
+	p := m.provider()
+
+	for i := 0; i < 3; i++ {
+		auth, err := p.Credentials()
+		c.Assert(err, IsNil)
+		c.Assert(auth.AccessKey, Equals, "access-1")
+		c.Assert(auth.SecretKey, Equals, "secret-1")
+		c.Assert(auth.Token(), Equals, "token-1")
+		c.Assert(auth.Expiration().After(time.Now().Add(50*time.Minute)), Equals, true)
+	}
+	c.Assert(atomic.LoadInt32(&m.fetches), Equals, int32(1))
+}
+
+func (s *S) TestContainerProviderRefresh(c *C) {
+	m := newFakeContainer(3 * time.Minute)
+	defer m.server.Close()
+	p := m.provider()
+	p.RelativeURI = "v2/credentials/task-id"
+
+	auth, err := p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "access-1")
+
+	auth, err = p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "access-2")
+
+	p.ExpiryWindow = time.Minute
+	auth, err = p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "access-2")
+	c.Assert(atomic.LoadInt32(&m.fetches), Equals, int32(2))
+}
+
+func (s *S) TestContainerProviderErrors(c *C) {
+	m := newFakeContainer(time.Hour)
+	defer m.server.Close()
+	os.Clearenv()
+	p := m.provider()
+
+	_, err := p.Credentials()
+	c.Assert(err, ErrorMatches, "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI not found in environment")
+
+	p.RelativeURI = "/v2/credentials/task-id"
+	m.body = "not json"
+	_, err = p.Credentials()
+	c.Assert(err, ErrorMatches, "cannot parse credentials of ECS task role: .*")
+
+	m.body = `{"RoleArn": "arn:aws:iam::123456789012:role/task-role"}`
+	_, err = p.Credentials()
+	c.Assert(err, ErrorMatches, "no credentials found for ECS task role")
+
+	m.body = `{"AccessKeyId": "access", "SecretAccessKey": "secret", "Expiration": "tomorrow"}`
+	_, err = p.Credentials()
+	c.Assert(err, ErrorMatches, "cannot parse expiration of ECS task role credentials: .*")
+
+	p.RelativeURI = "/v2/credentials/other-task"
+	_, err = p.Credentials()
+	c.Assert(err, ErrorMatches, "Code 404 returned for url .*/v2/credentials/other-task")
+	c.Assert(atomic.LoadInt32(&m.fetches), Equals, int32(3))
+}
+
+func (s *S) TestRenewingProvider(c *C) {
+	var renewals int32
+	var expiration time.Duration
+	p := aws.NewRenewingProvider(func() (aws.Auth, error) {
+		n := atomic.AddInt32(&renewals, 1)
+		key := fmt.Sprintf("access-%d", n)
+		return *aws.NewAuth(key, "secret", "token", time.Now().Add(expiration)), nil
+	})
+
+	expiration = time.Hour
+	for i := 0; i < 3; i++ {
+		auth, err := p.Credentials()
+		c.Assert(err, IsNil)
+		c.Assert(auth.AccessKey, Equals, "access-1")
+	}
+
+	// Force a renewal of the cached credentials.
+	p.ExpiryWindow = 2 * time.Hour
+	auth, err := p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "access-2")
+	c.Assert(atomic.LoadInt32(&renewals), Equals, int32(2))
+}
+
+func (s *S) TestRenewingProviderError(c *C) {
+	p := aws.NewRenewingProvider(func() (aws.Auth, error) {
+		return aws.Auth{}, fmt.Errorf("renewal failed")
+	})
+	_, err := p.Credentials()
+	c.Assert(err, ErrorMatches, "renewal failed")
+}
+
+func (s *S) TestCurrentAuth(c *C) {
+	static := aws.Auth{AccessKey: "static", SecretKey: "secret"}
+	auth, err := aws.CurrentAuth(static, nil)
+	c.Assert(err, IsNil)
+	c.Assert(auth, Equals, static)
+
+	p := aws.NewRenewingProvider(func() (aws.Auth, error) {
+		return aws.Auth{AccessKey: "renewed", SecretKey: "secret"}, nil
+	})
+	auth, err = aws.CurrentAuth(static, p)
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "renewed")
+}
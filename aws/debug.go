@@ -0,0 +1,125 @@
+package aws
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+)
+
+// Debugger is notified of the requests sent by the service clients and of
+// the responses received, for each attempt.
+type Debugger interface {
+	// LogRequest is called before req is sent. Credentials are redacted
+	// from req, whose body is not available.
+	LogRequest(req *http.Request)
+
+	// LogResponse is called when the attempt ends with either resp or
+	// err. body holds the start of the response body, up to
+	// DebugBodyLimit bytes, and resp.Body still yields all of it.
+	LogResponse(resp *http.Response, body []byte, err error)
+}
+
+// Debug, when not nil, is notified of all the requests made by the service
+// clients. It must be set before requests are made, and be safe for
+// concurrent use.
+var Debug Debugger
+
+// DebugBodyLimit is the maximum size of the response bodies passed to
+// Debug.
+var DebugBodyLimit = 64 << 10
+
+// Header and query parameters holding credentials, hidden from Debug.
+var (
+	redactedHeaders = []string{"Authorization", "X-Amz-Security-Token", "X-Amzn-Authorization"}
+	redactedParams  = []string{"Signature", "SecurityToken", "X-Amz-Signature", "X-Amz-Security-Token", "x-amz-security-token"}
+)
+
+const redacted = "REDACTED"
+
+// NewDebugLogger returns a Debugger writing requests and responses to w.
+func NewDebugLogger(w io.Writer) Debugger {
+	return &debugLogger{w: w}
+}
+
+type debugLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *debugLogger) LogRequest(req *http.Request) {
+	dump, err := httputil.DumpRequestOut(req, false)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		fmt.Fprintf(l.w, "---[ REQUEST %s %s ]---\n%v\n", req.Method, req.URL, err)
+		return
+	}
+	fmt.Fprintf(l.w, "---[ REQUEST ]---\n%s", dump)
+}
+
+func (l *debugLogger) LogResponse(resp *http.Response, body []byte, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		fmt.Fprintf(l.w, "---[ RESPONSE ERROR ]---\n%v\n", err)
+		return
+	}
+	dump, _ := httputil.DumpResponse(&http.Response{
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Proto:      resp.Proto,
+		ProtoMajor: resp.ProtoMajor,
+		ProtoMinor: resp.ProtoMinor,
+		Header:     resp.Header,
+	}, false)
+	fmt.Fprintf(l.w, "---[ RESPONSE ]---\n%s%s\n", dump, body)
+}
+
+// debugRequest returns a copy of req without credentials nor body.
+func debugRequest(req *http.Request) *http.Request {
+	r := *req
+	r.Body = nil
+	r.GetBody = nil
+	r.ContentLength = 0
+
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	for _, h := range redactedHeaders {
+		if r.Header.Get(h) != "" {
+			r.Header.Set(h, redacted)
+		}
+	}
+
+	if req.URL != nil && req.URL.RawQuery != "" {
+		u := *req.URL
+		query := u.Query()
+		for _, p := range redactedParams {
+			if _, ok := query[p]; ok {
+				query.Set(p, redacted)
+			}
+		}
+		u.RawQuery = query.Encode()
+		r.URL = &u
+	}
+	return &r
+}
+
+// debugResponse reads the start of the body of resp for Debug, and makes
+// resp.Body yield all of it again.
+func debugResponse(resp *http.Response) []byte {
+	if resp == nil || resp.Body == nil {
+		return nil
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, int64(DebugBodyLimit)))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	return body
+}
@@ -0,0 +1,121 @@
+package aws_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goamz/goamz/aws"
+	. "gopkg.in/check.v1"
+)
+
+// recordingDebugger keeps the requests and response bodies it is given.
+type recordingDebugger struct {
+	requests []*http.Request
+	bodies   []string
+	errs     []error
+}
+
+func (d *recordingDebugger) LogRequest(req *http.Request) {
+	d.requests = append(d.requests, req)
+}
+
+func (d *recordingDebugger) LogResponse(resp *http.Response, body []byte, err error) {
+	d.bodies = append(d.bodies, string(body))
+	d.errs = append(d.errs, err)
+}
+
+func (s *S) TestDebugRedactsCredentials(c *C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c.Check(req.Header.Get("Authorization"), Equals, "AWS4-HMAC-SHA256 Signature=secret")
+		fmt.Fprint(w, "response body")
+	}))
+	defer srv.Close()
+
+	d := &recordingDebugger{}
+	aws.Debug = d
+	defer func() {
+		aws.Debug = nil
+	}()
+
+	req, err := http.NewRequest("GET", srv.URL+"/?Action=List&Signature=secret&SecurityToken=token", nil)
+	c.Assert(err, IsNil)
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Signature=secret")
+	req.Header.Set("X-Amz-Security-Token", "token")
+	req.Header.Set("X-Amz-Date", "20150830T123600Z")
+
+	resp, err := aws.NoRetry.Do(http.DefaultClient, req)
+	c.Assert(err, IsNil)
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	c.Assert(err, IsNil)
+	c.Assert(string(body), Equals, "response body")
+
+	c.Assert(d.requests, HasLen, 1)
+	logged := d.requests[0]
+	c.Assert(logged.Header.Get("Authorization"), Equals, "REDACTED")
+	c.Assert(logged.Header.Get("X-Amz-Security-Token"), Equals, "REDACTED")
+	c.Assert(logged.Header.Get("X-Amz-Date"), Equals, "20150830T123600Z")
+	c.Assert(logged.URL.Query().Get("Signature"), Equals, "REDACTED")
+	c.Assert(logged.URL.Query().Get("SecurityToken"), Equals, "REDACTED")
+	c.Assert(logged.URL.Query().Get("Action"), Equals, "List")
+	c.Assert(d.bodies, DeepEquals, []string{"response body"})
+	c.Assert(d.errs, DeepEquals, []error{nil})
+
+	// The request sent is left untouched
+	c.Assert(req.Header.Get("Authorization"), Equals, "AWS4-HMAC-SHA256 Signature=secret")
+}
+
+func (s *S) TestDebugBodyLimit(c *C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "0123456789")
+	}))
+	defer srv.Close()
+
+	d := &recordingDebugger{}
+	aws.Debug = d
+	aws.DebugBodyLimit = 4
+	defer func() {
+		aws.Debug = nil
+		aws.DebugBodyLimit = 64 << 10
+	}()
+
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	c.Assert(err, IsNil)
+	resp, err := aws.NoRetry.Do(http.DefaultClient, req)
+	c.Assert(err, IsNil)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	c.Assert(string(body), Equals, "0123456789")
+	c.Assert(d.bodies, DeepEquals, []string{"0123"})
+}
+
+func (s *S) TestDebugLogger(c *C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(503)
+		fmt.Fprint(w, "<Error><Code>ServiceUnavailable</Code></Error>")
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	aws.Debug = aws.NewDebugLogger(&out)
+	defer func() {
+		aws.Debug = nil
+	}()
+
+	req, err := http.NewRequest("GET", srv.URL+"/?Signature=secret", nil)
+	c.Assert(err, IsNil)
+	req.Header.Set("Authorization", "secret")
+	resp, err := aws.NoRetry.Do(http.DefaultClient, req)
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+
+	log := out.String()
+	c.Assert(strings.Contains(log, "secret"), Equals, false, Commentf("%s", log))
+	c.Assert(log, Matches, "(?s)---\\[ REQUEST \\]---\nGET /\\?Signature=REDACTED HTTP/1.1\r\n.*Authorization: REDACTED\r\n.*")
+	c.Assert(log, Matches, "(?s).*---\\[ RESPONSE \\]---\nHTTP/1.1 503 Service Unavailable\r\n.*<Error><Code>ServiceUnavailable</Code></Error>\n")
+}
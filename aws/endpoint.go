@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// endpointSetters set the endpoint of a service in a Region, by the name
+// of the service in its AWS endpoints.
+var endpointSetters = map[string]func(r *Region, endpoint string){
+	"ec2":                  func(r *Region, e string) { r.EC2Endpoint = e },
+	"s3":                   func(r *Region, e string) { r.S3Endpoint = e },
+	"sdb":                  func(r *Region, e string) { r.SDBEndpoint = e },
+	"email":                func(r *Region, e string) { r.SESEndpoint = e },
+	"sns":                  func(r *Region, e string) { r.SNSEndpoint = e },
+	"sqs":                  func(r *Region, e string) { r.SQSEndpoint = e },
+	"iam":                  func(r *Region, e string) { r.IAMEndpoint = e },
+	"elasticloadbalancing": func(r *Region, e string) { r.ELBEndpoint = e },
+	"dynamodb":             func(r *Region, e string) { r.DynamoDBEndpoint = e },
+	"monitoring":           func(r *Region, e string) { r.CloudWatchServicepoint = ServiceInfo{e, V2Signature} },
+	"autoscaling":          func(r *Region, e string) { r.AutoScalingEndpoint = e },
+	"rds":                  func(r *Region, e string) { r.RDSEndpoint = ServiceInfo{e, V2Signature} },
+	"sts":                  func(r *Region, e string) { r.STSEndpoint = e },
+	"cloudformation":       func(r *Region, e string) { r.CloudFormationEndpoint = e },
+	"ecs":                  func(r *Region, e string) { r.ECSEndpoint = e },
+	"streams.dynamodb":     func(r *Region, e string) { r.DynamoDBStreamsEndpoint = e },
+}
+
+// CustomRegion returns a Region named name where all the services are
+// served from endpoint, as with localstack (http://localhost:4566). S3
+// buckets are addressed with the path style.
+//
+// Use an http.Client from NewInsecureClient in the service clients if
+// the endpoint has a self-signed certificate.
+func CustomRegion(name, endpoint string) Region {
+	r := Region{Name: name}
+	for _, set := range endpointSetters {
+		set(&r, endpoint)
+	}
+	return r
+}
+
+// RegionFromEndpointMap returns a Region with the endpoints given by
+// endpoints, keyed by the names of the services in their AWS endpoints:
+// "ec2", "s3", "sqs", "dynamodb", "email" for SES, and so on. The "*" key
+// gives the endpoint of the services not listed, and the "region" key the
+// name of the region, which defaults to "us-east-1" as it is part of the
+// Signature Version 4 scope.
+//
+// As with CustomRegion, S3 buckets are addressed with the path style.
+func RegionFromEndpointMap(endpoints map[string]string) (Region, error) {
+	name := endpoints["region"]
+	if name == "" {
+		name = USEast.Name
+	}
+
+	var r Region
+	if endpoint, ok := endpoints["*"]; ok {
+		if err := checkEndpoint("*", endpoint); err != nil {
+			return Region{}, err
+		}
+		r = CustomRegion(name, endpoint)
+	} else {
+		r = Region{Name: name}
+	}
+
+	services := make([]string, 0, len(endpoints))
+	for service := range endpoints {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	for _, service := range services {
+		if service == "region" || service == "*" {
+			continue
+		}
+		set, ok := endpointSetters[service]
+		if !ok {
+			return Region{}, fmt.Errorf("unknown service %q in endpoint map", service)
+		}
+		endpoint := endpoints[service]
+		if err := checkEndpoint(service, endpoint); err != nil {
+			return Region{}, err
+		}
+		set(&r, endpoint)
+	}
+	return r, nil
+}
+
+func checkEndpoint(service, endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint for %s: %v", service, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid endpoint for %s: %q is not an http or https URL", service, endpoint)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package aws_test
+
+import (
+	"github.com/goamz/goamz/aws"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) TestCustomRegion(c *C) {
+	r := aws.CustomRegion("local", "http://localhost:4566")
+	c.Assert(r.Name, Equals, "local")
+	c.Assert(r.S3Endpoint, Equals, "http://localhost:4566")
+	c.Assert(r.S3BucketEndpoint, Equals, "")
+	c.Assert(r.EC2Endpoint, Equals, "http://localhost:4566")
+	c.Assert(r.SQSEndpoint, Equals, "http://localhost:4566")
+	c.Assert(r.DynamoDBEndpoint, Equals, "http://localhost:4566")
+	c.Assert(r.CloudWatchServicepoint, Equals, aws.ServiceInfo{"http://localhost:4566", aws.V2Signature})
+}
+
+func (s *S) TestRegionFromEndpointMap(c *C) {
+	r, err := aws.RegionFromEndpointMap(map[string]string{
+		"s3":       "http://localhost:4572",
+		"dynamodb": "https://localhost:8000",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(r, Equals, aws.Region{
+		Name:             "us-east-1",
+		S3Endpoint:       "http://localhost:4572",
+		DynamoDBEndpoint: "https://localhost:8000",
+	})
+
+	r, err = aws.RegionFromEndpointMap(map[string]string{
+		"region": "eu-west-1",
+		"*":      "http://localhost:4566",
+		"sqs":    "http://localhost:4576",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(r.Name, Equals, "eu-west-1")
+	c.Assert(r.SQSEndpoint, Equals, "http://localhost:4576")
+	c.Assert(r.SNSEndpoint, Equals, "http://localhost:4566")
+}
+
+func (s *S) TestRegionFromEndpointMapErrors(c *C) {
+	_, err := aws.RegionFromEndpointMap(map[string]string{"s4": "http://localhost:4572"})
+	c.Assert(err, ErrorMatches, `unknown service "s4" in endpoint map`)
+
+	_, err = aws.RegionFromEndpointMap(map[string]string{"s3": "localhost:4572"})
+	c.Assert(err, ErrorMatches, `invalid endpoint for s3: "localhost:4572" is not an http or https URL`)
+
+	_, err = aws.RegionFromEndpointMap(map[string]string{"*": "ftp://localhost"})
+	c.Assert(err, ErrorMatches, `invalid endpoint for \*: .*`)
+}
@@ -0,0 +1,152 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProcessTimeout is how long a credential process may run by
+// default before it is killed.
+const DefaultProcessTimeout = time.Minute
+
+// ProcessProvider provides the credentials printed by an external command,
+// as configured by the credential_process setting of the shared AWS
+// config. The command is run by the shell and must print a JSON document
+// such as:
+//
+//	{
+//	  "Version": 1,
+//	  "AccessKeyId": "...",
+//	  "SecretAccessKey": "...",
+//	  "SessionToken": "...",
+//	  "Expiration": "2015-01-01T00:00:00Z"
+//	}
+//
+// The credentials are cached and renewed shortly before they expire.
+// Credentials without an Expiration are never renewed.
+//
+// ProcessProvider is safe for concurrent use. Concurrent callers needing a
+// renewal wait for a single run of the command.
+type ProcessProvider struct {
+	// Command is the command line run to get credentials.
+	Command string
+
+	// Timeout is how long the command may run. It defaults to
+	// DefaultProcessTimeout.
+	Timeout time.Duration
+
+	// ExpiryWindow is how long before their expiration credentials are
+	// renewed. It defaults to DefaultExpiryWindow.
+	ExpiryWindow time.Duration
+
+	cache credentialsCache
+}
+
+// NewProcessProvider returns a ProcessProvider running command.
+func NewProcessProvider(command string) *ProcessProvider {
+	return &ProcessProvider{Command: command}
+}
+
+// Credentials returns the cached credentials, running the command for new
+// ones if they are about to expire.
+func (p *ProcessProvider) Credentials() (Auth, error) {
+	return p.cache.get(p.ExpiryWindow, p.run)
+}
+
+type processCredentials struct {
+	Version         int
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      string
+}
+
+func (p *ProcessProvider) run() (Auth, error) {
+	timeout := p.Timeout
+	if timeout == 0 {
+		timeout = DefaultProcessTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd.exe", "/C", p.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", p.Command)
+	}
+	// Don't wait for children of the command still holding its output
+	// once it is killed
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return Auth{}, p.errorf(&stderr, "timed out after %v", timeout)
+	}
+	if err != nil {
+		return Auth{}, p.errorf(&stderr, "failed: %v", err)
+	}
+
+	var cred processCredentials
+	if err := json.Unmarshal(stdout.Bytes(), &cred); err != nil {
+		return Auth{}, p.errorf(&stderr, "printed invalid credentials: %v", err)
+	}
+	if cred.Version != 1 {
+		return Auth{}, p.errorf(&stderr, "printed credentials of unsupported version %d", cred.Version)
+	}
+	if cred.AccessKeyId == "" || cred.SecretAccessKey == "" {
+		return Auth{}, p.errorf(&stderr, "printed no AccessKeyId or SecretAccessKey")
+	}
+
+	auth := Auth{
+		AccessKey: cred.AccessKeyId,
+		SecretKey: cred.SecretAccessKey,
+		token:     cred.SessionToken,
+	}
+	if cred.Expiration != "" {
+		auth.expiration, err = time.Parse(time.RFC3339, cred.Expiration)
+		if err != nil {
+			return Auth{}, p.errorf(&stderr, "printed an invalid Expiration: %v", err)
+		}
+	}
+	return auth, nil
+}
+
+// errorf returns an error about the command, ending with what it wrote to
+// stderr.
+func (p *ProcessProvider) errorf(stderr *bytes.Buffer, format string, args ...interface{}) error {
+	msg := fmt.Sprintf("credential_process %q ", p.Command) + fmt.Sprintf(format, args...)
+	if s := strings.TrimSpace(stderr.String()); s != "" {
+		msg += ": " + s
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// processProviders holds the providers of the credential processes found
+// in the shared config by SharedAuth, so that their credentials are
+// cached across calls.
+var processProviders = struct {
+	sync.Mutex
+	m map[string]*ProcessProvider
+}{m: make(map[string]*ProcessProvider)}
+
+func processAuth(command string) (Auth, error) {
+	processProviders.Lock()
+	p := processProviders.m[command]
+	if p == nil {
+		p = NewProcessProvider(command)
+		processProviders.m[command] = p
+	}
+	processProviders.Unlock()
+	return p.Credentials()
+}
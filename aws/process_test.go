@@ -0,0 +1,169 @@
+package aws_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+	. "gopkg.in/check.v1"
+)
+
+// credentialScript writes a script printing output, counting its runs in
+// the returned file, and returns the command running it.
+func credentialScript(c *C, output string) (command, runs string) {
+	if runtime.GOOS == "windows" {
+		c.Skip("credential process tests need a POSIX shell")
+	}
+	d := c.MkDir()
+	runs = filepath.Join(d, "runs")
+	script := filepath.Join(d, "credentials.sh")
+	err := ioutil.WriteFile(script, []byte(fmt.Sprintf("echo run >> %s\n%s\n", runs, output)), 0755)
+	c.Assert(err, IsNil)
+	return "sh " + script, runs
+}
+
+func countRuns(c *C, runs string) int {
+	data, err := ioutil.ReadFile(runs)
+	c.Assert(err, IsNil)
+	return strings.Count(string(data), "run\n")
+}
+
+func (s *S) TestProcessProvider(c *C) {
+	expiration := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	command, runs := credentialScript(c, `cat <<EOF
+{
+  "Version": 1,
+  "AccessKeyId": "access",
+  "SecretAccessKey": "secret",
+  "SessionToken": "token",
+  "Expiration": "`+expiration+`"
+}
+EOF`)
+	p := aws.NewProcessProvider(command)
+
+	for i := 0; i < 3; i++ {
+		auth, err := p.Credentials()
+		c.Assert(err, IsNil)
+		c.Assert(auth.AccessKey, Equals, "access")
+		c.Assert(auth.SecretKey, Equals, "secret")
+		c.Assert(auth.Token(), Equals, "token")
+		c.Assert(auth.Expiration().Format(time.RFC3339), Equals, expiration)
+	}
+	c.Assert(countRuns(c, runs), Equals, 1)
+
+	// Force a renewal
+	p.ExpiryWindow = 2 * time.Hour
+	_, err := p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(countRuns(c, runs), Equals, 2)
+}
+
+func (s *S) TestProcessProviderWithoutExpiration(c *C) {
+	command, runs := credentialScript(c, `echo '{"Version": 1, "AccessKeyId": "access", "SecretAccessKey": "secret"}'`)
+	p := aws.NewProcessProvider(command)
+	p.ExpiryWindow = 24 * time.Hour
+
+	for i := 0; i < 2; i++ {
+		auth, err := p.Credentials()
+		c.Assert(err, IsNil)
+		c.Assert(auth, Equals, aws.Auth{AccessKey: "access", SecretKey: "secret"})
+	}
+	c.Assert(countRuns(c, runs), Equals, 1)
+}
+
+var processErrorTests = []struct {
+	output string
+	err    string
+}{{
+	"echo 'token expired, run login' >&2; exit 3",
+	`credential_process ".*" failed: exit status 3: token expired, run login`,
+}, {
+	"echo not json; echo 'warning: cache miss' >&2",
+	`credential_process ".*" printed invalid credentials: .*: warning: cache miss`,
+}, {
+	`echo '{"Version": 2, "AccessKeyId": "access", "SecretAccessKey": "secret"}'`,
+	`credential_process ".*" printed credentials of unsupported version 2`,
+}, {
+	`echo '{"Version": 1, "AccessKeyId": "access"}'`,
+	`credential_process ".*" printed no AccessKeyId or SecretAccessKey`,
+}, {
+	`echo '{"Version": 1, "AccessKeyId": "access", "SecretAccessKey": "secret", "Expiration": "soon"}'`,
+	`credential_process ".*" printed an invalid Expiration: .*`,
+}}
+
+func (s *S) TestProcessProviderErrors(c *C) {
+	for i, t := range processErrorTests {
+		command, _ := credentialScript(c, t.output)
+		_, err := aws.NewProcessProvider(command).Credentials()
+		c.Check(err, ErrorMatches, t.err, Commentf("test %d", i))
+	}
+}
+
+func (s *S) TestProcessProviderTimeout(c *C) {
+	command, _ := credentialScript(c, "echo 'waiting for approval' >&2; sleep 10")
+	p := aws.NewProcessProvider(command)
+	p.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := p.Credentials()
+	c.Assert(err, ErrorMatches, `credential_process ".*" timed out after 100ms: waiting for approval`)
+	c.Assert(time.Since(start) < 5*time.Second, Equals, true)
+}
+
+func (s *S) TestSharedAuthCredentialProcess(c *C) {
+	command, runs := credentialScript(c, `echo '{"Version": 1, "AccessKeyId": "process-access", "SecretAccessKey": "process-secret"}'`)
+
+	// Keep PATH to find the shell
+	path := os.Getenv("PATH")
+	os.Clearenv()
+	os.Setenv("PATH", path)
+	d := c.MkDir()
+	os.Setenv("HOME", d)
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join(d, "config"))
+	err := ioutil.WriteFile(filepath.Join(d, "config"), []byte("[profile dev]\ncredential_process = "+command+"\n"), 0644)
+	c.Assert(err, IsNil)
+
+	// No credentials file
+	auth, err := aws.SharedAuth("dev")
+	c.Assert(err, IsNil)
+	c.Assert(auth, Equals, aws.Auth{AccessKey: "process-access", SecretKey: "process-secret"})
+
+	// The profile is also used by the default chain
+	os.Setenv("AWS_PROFILE", "dev")
+	auth, err = aws.GetAuth("", "", "", time.Time{})
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "process-access")
+	c.Assert(countRuns(c, runs), Equals, 1)
+
+	_, err = aws.SharedAuth("default")
+	c.Assert(err, ErrorMatches, "Couldn't parse AWS credentials file .*")
+}
+
+func (s *S) TestSharedAuthCredentialProcessInCredentialsFile(c *C) {
+	command, _ := credentialScript(c, `echo '{"Version": 1, "AccessKeyId": "process-access", "SecretAccessKey": "process-secret"}'`)
+
+	// Keep PATH to find the shell
+	path := os.Getenv("PATH")
+	os.Clearenv()
+	os.Setenv("PATH", path)
+	d := c.MkDir()
+	os.Setenv("HOME", d)
+	os.Mkdir(filepath.Join(d, ".aws"), 0755)
+	err := ioutil.WriteFile(filepath.Join(d, ".aws", "credentials"), []byte("[default]\ncredential_process = "+command+"\n"+
+		"[static]\naws_access_key_id = access\naws_secret_access_key = secret\ncredential_process = false\n"), 0644)
+	c.Assert(err, IsNil)
+
+	auth, err := aws.SharedAuth("")
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "process-access")
+
+	// Keys take precedence over credential_process
+	auth, err = aws.SharedAuth("static")
+	c.Assert(err, IsNil)
+	c.Assert(auth, Equals, aws.Auth{AccessKey: "access", SecretKey: "secret"})
+}
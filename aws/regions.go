@@ -7,6 +7,7 @@ var USGovWest = Region{
 	"",
 	true,
 	true,
+	false,
 	"",
 	"",
 	"https://sns.us-gov-west-1.amazonaws.com",
@@ -20,6 +21,7 @@ var USGovWest = Region{
 	"https://sts.amazonaws.com",
 	"https://cloudformation.us-gov-west-1.amazonaws.com",
 	"https://ecs.us-gov-west-1.amazonaws.com",
+	"https://streams.dynamodb.us-gov-west-1.amazonaws.com",
 }
 
 var USEast = Region{
@@ -29,6 +31,7 @@ var USEast = Region{
 	"",
 	false,
 	false,
+	false,
 	"https://sdb.amazonaws.com",
 	"https://email.us-east-1.amazonaws.com",
 	"https://sns.us-east-1.amazonaws.com",
@@ -42,6 +45,7 @@ var USEast = Region{
 	"https://sts.amazonaws.com",
 	"https://cloudformation.us-east-1.amazonaws.com",
 	"https://ecs.us-east-1.amazonaws.com",
+	"https://streams.dynamodb.us-east-1.amazonaws.com",
 }
 
 var USWest = Region{
@@ -51,6 +55,7 @@ var USWest = Region{
 	"",
 	true,
 	true,
+	false,
 	"https://sdb.us-west-1.amazonaws.com",
 	"",
 	"https://sns.us-west-1.amazonaws.com",
@@ -64,6 +69,7 @@ var USWest = Region{
 	"https://sts.amazonaws.com",
 	"https://cloudformation.us-west-1.amazonaws.com",
 	"https://ecs.us-west-1.amazonaws.com",
+	"https://streams.dynamodb.us-west-1.amazonaws.com",
 }
 
 var USWest2 = Region{
@@ -73,6 +79,7 @@ var USWest2 = Region{
 	"",
 	true,
 	true,
+	false,
 	"https://sdb.us-west-2.amazonaws.com",
 	"https://email.us-west-2.amazonaws.com",
 	"https://sns.us-west-2.amazonaws.com",
@@ -86,6 +93,7 @@ var USWest2 = Region{
 	"https://sts.amazonaws.com",
 	"https://cloudformation.us-west-2.amazonaws.com",
 	"https://ecs.us-west-2.amazonaws.com",
+	"https://streams.dynamodb.us-west-2.amazonaws.com",
 }
 
 var EUWest = Region{
@@ -95,6 +103,7 @@ var EUWest = Region{
 	"",
 	true,
 	true,
+	false,
 	"https://sdb.eu-west-1.amazonaws.com",
 	"https://email.eu-west-1.amazonaws.com",
 	"https://sns.eu-west-1.amazonaws.com",
@@ -108,6 +117,7 @@ var EUWest = Region{
 	"https://sts.amazonaws.com",
 	"https://cloudformation.eu-west-1.amazonaws.com",
 	"https://ecs.eu-west-1.amazonaws.com",
+	"https://streams.dynamodb.eu-west-1.amazonaws.com",
 }
 
 var EUCentral = Region{
@@ -117,6 +127,7 @@ var EUCentral = Region{
 	"",
 	true,
 	true,
+	true,
 	"https://sdb.eu-central-1.amazonaws.com",
 	"https://email.eu-central-1.amazonaws.com",
 	"https://sns.eu-central-1.amazonaws.com",
@@ -130,6 +141,7 @@ var EUCentral = Region{
 	"https://sts.amazonaws.com",
 	"https://cloudformation.eu-central-1.amazonaws.com",
 	"https://ecs.eu-central-1.amazonaws.com",
+	"https://streams.dynamodb.eu-central-1.amazonaws.com",
 }
 
 var APSoutheast = Region{
@@ -139,6 +151,7 @@ var APSoutheast = Region{
 	"",
 	true,
 	true,
+	false,
 	"https://sdb.ap-southeast-1.amazonaws.com",
 	"",
 	"https://sns.ap-southeast-1.amazonaws.com",
@@ -152,6 +165,7 @@ var APSoutheast = Region{
 	"https://sts.amazonaws.com",
 	"https://cloudformation.ap-southeast-1.amazonaws.com",
 	"https://ecs.ap-southeast-1.amazonaws.com",
+	"https://streams.dynamodb.ap-southeast-1.amazonaws.com",
 }
 
 var APSoutheast2 = Region{
@@ -161,6 +175,7 @@ var APSoutheast2 = Region{
 	"",
 	true,
 	true,
+	false,
 	"https://sdb.ap-southeast-2.amazonaws.com",
 	"",
 	"https://sns.ap-southeast-2.amazonaws.com",
@@ -174,6 +189,7 @@ var APSoutheast2 = Region{
 	"https://sts.amazonaws.com",
 	"https://cloudformation.ap-southeast-2.amazonaws.com",
 	"https://ecs.ap-southeast-2.amazonaws.com",
+	"https://streams.dynamodb.ap-southeast-2.amazonaws.com",
 }
 
 var APNortheast = Region{
@@ -183,6 +199,7 @@ var APNortheast = Region{
 	"",
 	true,
 	true,
+	false,
 	"https://sdb.ap-northeast-1.amazonaws.com",
 	"",
 	"https://sns.ap-northeast-1.amazonaws.com",
@@ -196,6 +213,7 @@ var APNortheast = Region{
 	"https://sts.amazonaws.com",
 	"https://cloudformation.ap-northeast-1.amazonaws.com",
 	"https://ecs.ap-northeast-1.amazonaws.com",
+	"https://streams.dynamodb.ap-northeast-1.amazonaws.com",
 }
 
 var SAEast = Region{
@@ -205,6 +223,7 @@ var SAEast = Region{
 	"",
 	true,
 	true,
+	false,
 	"https://sdb.sa-east-1.amazonaws.com",
 	"",
 	"https://sns.sa-east-1.amazonaws.com",
@@ -218,6 +237,7 @@ var SAEast = Region{
 	"https://sts.amazonaws.com",
 	"https://cloudformation.sa-east-1.amazonaws.com",
 	"https://ecs.sa-east-1.amazonaws.com",
+	"https://streams.dynamodb.sa-east-1.amazonaws.com",
 }
 
 var CNNorth = Region{
@@ -227,6 +247,7 @@ var CNNorth = Region{
 	"",
 	true,
 	true,
+	true,
 	"https://sdb.cn-north-1.amazonaws.com.cn",
 	"",
 	"https://sns.cn-north-1.amazonaws.com.cn",
@@ -240,4 +261,149 @@ var CNNorth = Region{
 	"https://sts.cn-north-1.amazonaws.com.cn",
 	"https://cloudformation.cn-north-1.amazonaws.com.cn",
 	"https://ecs.cn-north-1.amazonaws.com.cn",
+	"https://streams.dynamodb.cn-north-1.amazonaws.com.cn",
+}
+
+var USGovEast = Region{
+	"us-gov-east-1",
+	"https://ec2.us-gov-east-1.amazonaws.com",
+	"https://s3-fips.us-gov-east-1.amazonaws.com",
+	"",
+	true,
+	true,
+	true,
+	"",
+	"",
+	"https://sns.us-gov-east-1.amazonaws.com",
+	"https://sqs.us-gov-east-1.amazonaws.com",
+	"https://iam.us-gov.amazonaws.com",
+	"https://elasticloadbalancing.us-gov-east-1.amazonaws.com",
+	"https://dynamodb.us-gov-east-1.amazonaws.com",
+	ServiceInfo{"https://monitoring.us-gov-east-1.amazonaws.com", V4Signature},
+	"https://autoscaling.us-gov-east-1.amazonaws.com",
+	ServiceInfo{"https://rds.us-gov-east-1.amazonaws.com", V4Signature},
+	"https://sts.us-gov-east-1.amazonaws.com",
+	"https://cloudformation.us-gov-east-1.amazonaws.com",
+	"https://ecs.us-gov-east-1.amazonaws.com",
+	"https://streams.dynamodb.us-gov-east-1.amazonaws.com",
+}
+
+var CACentral = Region{
+	"ca-central-1",
+	"https://ec2.ca-central-1.amazonaws.com",
+	"https://s3.ca-central-1.amazonaws.com",
+	"",
+	true,
+	true,
+	true,
+	"",
+	"",
+	"https://sns.ca-central-1.amazonaws.com",
+	"https://sqs.ca-central-1.amazonaws.com",
+	"https://iam.amazonaws.com",
+	"https://elasticloadbalancing.ca-central-1.amazonaws.com",
+	"https://dynamodb.ca-central-1.amazonaws.com",
+	ServiceInfo{"https://monitoring.ca-central-1.amazonaws.com", V4Signature},
+	"https://autoscaling.ca-central-1.amazonaws.com",
+	ServiceInfo{"https://rds.ca-central-1.amazonaws.com", V4Signature},
+	"https://sts.amazonaws.com",
+	"https://cloudformation.ca-central-1.amazonaws.com",
+	"https://ecs.ca-central-1.amazonaws.com",
+	"https://streams.dynamodb.ca-central-1.amazonaws.com",
+}
+
+var EUWest2 = Region{
+	"eu-west-2",
+	"https://ec2.eu-west-2.amazonaws.com",
+	"https://s3.eu-west-2.amazonaws.com",
+	"",
+	true,
+	true,
+	true,
+	"",
+	"",
+	"https://sns.eu-west-2.amazonaws.com",
+	"https://sqs.eu-west-2.amazonaws.com",
+	"https://iam.amazonaws.com",
+	"https://elasticloadbalancing.eu-west-2.amazonaws.com",
+	"https://dynamodb.eu-west-2.amazonaws.com",
+	ServiceInfo{"https://monitoring.eu-west-2.amazonaws.com", V4Signature},
+	"https://autoscaling.eu-west-2.amazonaws.com",
+	ServiceInfo{"https://rds.eu-west-2.amazonaws.com", V4Signature},
+	"https://sts.amazonaws.com",
+	"https://cloudformation.eu-west-2.amazonaws.com",
+	"https://ecs.eu-west-2.amazonaws.com",
+	"https://streams.dynamodb.eu-west-2.amazonaws.com",
+}
+
+var APNortheast2 = Region{
+	"ap-northeast-2",
+	"https://ec2.ap-northeast-2.amazonaws.com",
+	"https://s3.ap-northeast-2.amazonaws.com",
+	"",
+	true,
+	true,
+	true,
+	"",
+	"",
+	"https://sns.ap-northeast-2.amazonaws.com",
+	"https://sqs.ap-northeast-2.amazonaws.com",
+	"https://iam.amazonaws.com",
+	"https://elasticloadbalancing.ap-northeast-2.amazonaws.com",
+	"https://dynamodb.ap-northeast-2.amazonaws.com",
+	ServiceInfo{"https://monitoring.ap-northeast-2.amazonaws.com", V4Signature},
+	"https://autoscaling.ap-northeast-2.amazonaws.com",
+	ServiceInfo{"https://rds.ap-northeast-2.amazonaws.com", V4Signature},
+	"https://sts.amazonaws.com",
+	"https://cloudformation.ap-northeast-2.amazonaws.com",
+	"https://ecs.ap-northeast-2.amazonaws.com",
+	"https://streams.dynamodb.ap-northeast-2.amazonaws.com",
+}
+
+var APSouth = Region{
+	"ap-south-1",
+	"https://ec2.ap-south-1.amazonaws.com",
+	"https://s3.ap-south-1.amazonaws.com",
+	"",
+	true,
+	true,
+	true,
+	"",
+	"",
+	"https://sns.ap-south-1.amazonaws.com",
+	"https://sqs.ap-south-1.amazonaws.com",
+	"https://iam.amazonaws.com",
+	"https://elasticloadbalancing.ap-south-1.amazonaws.com",
+	"https://dynamodb.ap-south-1.amazonaws.com",
+	ServiceInfo{"https://monitoring.ap-south-1.amazonaws.com", V4Signature},
+	"https://autoscaling.ap-south-1.amazonaws.com",
+	ServiceInfo{"https://rds.ap-south-1.amazonaws.com", V4Signature},
+	"https://sts.amazonaws.com",
+	"https://cloudformation.ap-south-1.amazonaws.com",
+	"https://ecs.ap-south-1.amazonaws.com",
+	"https://streams.dynamodb.ap-south-1.amazonaws.com",
+}
+
+var CNNorthwest = Region{
+	"cn-northwest-1",
+	"https://ec2.cn-northwest-1.amazonaws.com.cn",
+	"https://s3.cn-northwest-1.amazonaws.com.cn",
+	"",
+	true,
+	true,
+	true,
+	"",
+	"",
+	"https://sns.cn-northwest-1.amazonaws.com.cn",
+	"https://sqs.cn-northwest-1.amazonaws.com.cn",
+	"https://iam.cn-north-1.amazonaws.com.cn",
+	"https://elasticloadbalancing.cn-northwest-1.amazonaws.com.cn",
+	"https://dynamodb.cn-northwest-1.amazonaws.com.cn",
+	ServiceInfo{"https://monitoring.cn-northwest-1.amazonaws.com.cn", V4Signature},
+	"https://autoscaling.cn-northwest-1.amazonaws.com.cn",
+	ServiceInfo{"https://rds.cn-northwest-1.amazonaws.com.cn", V4Signature},
+	"https://sts.cn-northwest-1.amazonaws.com.cn",
+	"https://cloudformation.cn-northwest-1.amazonaws.com.cn",
+	"https://ecs.cn-northwest-1.amazonaws.com.cn",
+	"https://streams.dynamodb.cn-northwest-1.amazonaws.com.cn",
 }
@@ -0,0 +1,186 @@
+package aws
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrBodyNotReplayable is returned by RetryPolicy.Do when a request should
+// be retried but its body was consumed by the failed attempt and cannot be
+// sent again, as for streaming uploads. Requests created by http.NewRequest
+// with a *bytes.Reader, *bytes.Buffer or *strings.Reader body are replayable.
+var ErrBodyNotReplayable = errors.New("aws: request failed and its body cannot be replayed for a retry")
+
+// RetryPolicy describes how failed requests are retried. Delays between
+// attempts grow exponentially from BaseDelay up to MaxDelay.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first
+	// one. Zero or one disables retries.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between attempts. Zero means no limit.
+	MaxDelay time.Duration
+
+	// Jitter is the fraction, between 0 and 1, of each delay which is
+	// randomized so that clients failing together don't retry together.
+	Jitter float64
+
+	// ShouldRetry reports whether a request which ended with resp and err
+	// should be retried. It defaults to the ShouldRetry function.
+	ShouldRetry func(resp *http.Response, err error) bool
+
+	// Sleep waits between attempts. It defaults to time.Sleep, and is
+	// mostly replaced by tests checking the delays.
+	Sleep func(d time.Duration)
+}
+
+// DefaultRetryPolicy is used by service clients without a RetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   100 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Jitter:      0.5,
+}
+
+// NoRetry makes a single attempt per request. It is mostly useful for tests.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// Error codes returned by the services when requests are throttled.
+var throttlingCodes = []string{
+	"Throttling",
+	"ThrottlingException",
+	"RequestLimitExceeded",
+	"ProvisionedThroughputExceededException",
+	"RequestThrottled",
+	"SlowDown",
+}
+
+// ShouldRetry reports whether a request which ended with resp and err
+// failed for a transient reason: a temporary network error or a reset
+// connection, a 5xx or 429 status, or an error response with a throttling
+// code, in either the XML or the JSON error format.
+//
+// The body of error responses is read in memory so it can be inspected,
+// and is replaced by a reader over the same content.
+func ShouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return isTransientError(err)
+	}
+	if resp == nil {
+		return false
+	}
+	if resp.StatusCode >= 500 || resp.StatusCode == 429 {
+		return true
+	}
+	if resp.StatusCode < 400 {
+		return false
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return isTransientError(err)
+	}
+	for _, code := range throttlingCodes {
+		if bytes.Contains(body, []byte("<Code>"+code+"</Code>")) || bytes.Contains(body, []byte("#"+code+`"`)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isThrottlingCode(code string) bool {
+	for _, c := range throttlingCodes {
+		if code == c {
+			return true
+		}
+	}
+	return false
+}
+
+func isTransientError(err error) bool {
+	if uerr, ok := err.(*url.Error); ok {
+		err = uerr.Err
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+	if neterr, ok := err.(net.Error); ok && (neterr.Timeout() || neterr.Temporary()) {
+		return true
+	}
+	return strings.Contains(err.Error(), "connection reset by peer")
+}
+
+// Delay returns how long to wait after the given failed attempt, starting
+// at zero for the first one, before trying again.
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Exp2(float64(attempt))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay -= delay * p.Jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// Backoff waits for the delay after the given failed attempt.
+func (p *RetryPolicy) Backoff(attempt int) {
+	sleep := p.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	sleep(p.Delay(attempt))
+}
+
+// Do sends req with client, retrying it as long as the policy allows. The
+// body of req is re-created with req.GetBody before each retry, and
+// ErrBodyNotReplayable is returned if it can't be.
+func (p *RetryPolicy) Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	shouldRetry := p.ShouldRetry
+	if shouldRetry == nil {
+		shouldRetry = ShouldRetry
+	}
+
+	for attempt := 0; ; attempt++ {
+		if Debug != nil {
+			Debug.LogRequest(debugRequest(req))
+		}
+		resp, err := client.Do(req)
+		if Debug != nil {
+			Debug.LogResponse(resp, debugResponse(resp), err)
+		}
+		if attempt+1 >= p.MaxAttempts || !shouldRetry(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return nil, ErrBodyNotReplayable
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		p.Backoff(attempt)
+	}
+}
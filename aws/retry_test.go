@@ -0,0 +1,187 @@
+package aws_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+	. "gopkg.in/check.v1"
+)
+
+// failingServer answers with the given status and body to the first
+// len(failures) requests, and with 200 afterwards. It records the bodies of
+// the requests.
+type failingServer struct {
+	*httptest.Server
+	failures []string
+	status   int
+	bodies   []string
+}
+
+func newFailingServer(status int, failures ...string) *failingServer {
+	s := &failingServer{status: status, failures: failures}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		s.bodies = append(s.bodies, string(body))
+		if len(s.failures) > 0 {
+			w.WriteHeader(s.status)
+			fmt.Fprint(w, s.failures[0])
+			s.failures = s.failures[1:]
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	return s
+}
+
+var testRetryPolicy = aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+func (s *S) TestRetryPolicyRetriesServerErrors(c *C) {
+	srv := newFailingServer(503, "unavailable", "unavailable")
+	defer srv.Close()
+
+	req, err := http.NewRequest("PUT", srv.URL, strings.NewReader("payload"))
+	c.Assert(err, IsNil)
+	resp, err := testRetryPolicy.Do(http.DefaultClient, req)
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+
+	c.Assert(resp.StatusCode, Equals, 200)
+	c.Assert(srv.bodies, DeepEquals, []string{"payload", "payload", "payload"})
+}
+
+func (s *S) TestRetryPolicyMaxAttempts(c *C) {
+	srv := newFailingServer(500, "1", "2", "3", "4")
+	defer srv.Close()
+
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	c.Assert(err, IsNil)
+	resp, err := testRetryPolicy.Do(http.DefaultClient, req)
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+
+	c.Assert(resp.StatusCode, Equals, 500)
+	body, _ := ioutil.ReadAll(resp.Body)
+	c.Assert(string(body), Equals, "3")
+	c.Assert(srv.bodies, HasLen, 3)
+}
+
+func (s *S) TestRetryPolicyThrottling(c *C) {
+	for _, body := range []string{
+		`<Response><Errors><Error><Code>Throttling</Code><Message>Rate exceeded</Message></Error></Errors></Response>`,
+		`<Response><Errors><Error><Code>RequestLimitExceeded</Code></Error></Errors></Response>`,
+		`{"__type":"com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException","message":"slow down"}`,
+	} {
+		srv := newFailingServer(400, body)
+		req, err := http.NewRequest("GET", srv.URL, nil)
+		c.Assert(err, IsNil)
+		resp, err := testRetryPolicy.Do(http.DefaultClient, req)
+		c.Assert(err, IsNil)
+		resp.Body.Close()
+		srv.Close()
+
+		c.Check(resp.StatusCode, Equals, 200, Commentf("body: %s", body))
+		c.Check(srv.bodies, HasLen, 2, Commentf("body: %s", body))
+	}
+}
+
+func (s *S) TestRetryPolicyClientErrors(c *C) {
+	srv := newFailingServer(400, `<Response><Errors><Error><Code>InvalidParameterValue</Code></Error></Errors></Response>`)
+	defer srv.Close()
+
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	c.Assert(err, IsNil)
+	resp, err := testRetryPolicy.Do(http.DefaultClient, req)
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+
+	// The body inspected by ShouldRetry is still readable
+	c.Assert(resp.StatusCode, Equals, 400)
+	body, _ := ioutil.ReadAll(resp.Body)
+	c.Assert(string(body), Matches, ".*InvalidParameterValue.*")
+	c.Assert(srv.bodies, HasLen, 1)
+}
+
+func (s *S) TestRetryPolicyBodyNotReplayable(c *C) {
+	srv := newFailingServer(503, "unavailable")
+	defer srv.Close()
+
+	req, err := http.NewRequest("PUT", srv.URL, ioutil.NopCloser(strings.NewReader("stream")))
+	c.Assert(err, IsNil)
+	_, err = testRetryPolicy.Do(http.DefaultClient, req)
+	c.Assert(err, Equals, aws.ErrBodyNotReplayable)
+	c.Assert(srv.bodies, DeepEquals, []string{"stream"})
+}
+
+func (s *S) TestNoRetry(c *C) {
+	srv := newFailingServer(503, "unavailable")
+	defer srv.Close()
+
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	c.Assert(err, IsNil)
+	resp, err := aws.NoRetry.Do(http.DefaultClient, req)
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, 503)
+	c.Assert(srv.bodies, HasLen, 1)
+}
+
+func (s *S) TestRetryPolicyCustomPredicate(c *C) {
+	srv := newFailingServer(404, "not yet")
+	defer srv.Close()
+
+	policy := testRetryPolicy
+	policy.ShouldRetry = func(resp *http.Response, err error) bool {
+		return err == nil && resp.StatusCode == 404
+	}
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	c.Assert(err, IsNil)
+	resp, err := policy.Do(http.DefaultClient, req)
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, 200)
+}
+
+func (s *S) TestShouldRetryErrors(c *C) {
+	reset := &url.Error{Op: "Get", URL: "http://example.com", Err: errors.New("read tcp 127.0.0.1:80: connection reset by peer")}
+	c.Assert(aws.ShouldRetry(nil, reset), Equals, true)
+	c.Assert(aws.ShouldRetry(nil, errors.New("bad request")), Equals, false)
+}
+
+func (s *S) TestRetryPolicyDelay(c *C) {
+	policy := aws.RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	c.Assert(policy.Delay(0), Equals, 100*time.Millisecond)
+	c.Assert(policy.Delay(1), Equals, 200*time.Millisecond)
+	c.Assert(policy.Delay(3), Equals, 800*time.Millisecond)
+	c.Assert(policy.Delay(4), Equals, time.Second)
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := policy.Delay(2)
+		c.Assert(d >= 200*time.Millisecond && d <= 400*time.Millisecond, Equals, true, Commentf("delay %v", d))
+	}
+}
+
+func (s *S) TestRetryPolicySleep(c *C) {
+	srv := newFailingServer(500, "1", "2", "3")
+	defer srv.Close()
+
+	var delays []time.Duration
+	policy := aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, Sleep: func(d time.Duration) {
+		delays = append(delays, d)
+	}}
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	c.Assert(err, IsNil)
+	resp, err := policy.Do(http.DefaultClient, req)
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+
+	c.Assert(resp.StatusCode, Equals, 500)
+	c.Assert(delays, DeepEquals, []time.Duration{time.Second, 2 * time.Second})
+}
@@ -6,11 +6,13 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -127,6 +129,84 @@ func NewV4Signer(auth Auth, serviceName string, region Region) *V4Signer {
 	return &V4Signer{auth: auth, serviceName: serviceName, region: region}
 }
 
+// UnsignedPayload may be set as the "x-amz-content-sha256" header of a
+// request before signing it to leave its body out of the signature. This
+// allows signing streaming bodies without reading them first. Only S3
+// supports unsigned payloads.
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+/*
+SignV4 signs req for service in the named region with the AWS Signature Version 4
+Signing Process, using auth's credentials. See V4Signer.Sign for details.
+*/
+func SignV4(req *http.Request, auth Auth, region, service string) {
+	NewV4Signer(auth, service, Region{Name: region}).Sign(req)
+}
+
+// MaxPresignExpires is the longest validity of a presigned request.
+const MaxPresignExpires = 7 * 24 * time.Hour
+
+/*
+PresignV4 signs req for service in the named region with the query string variant of the
+AWS Signature Version 4 Signing Process, and returns the resulting URL. Anyone holding the URL
+may make the request until it expires, without knowing auth's credentials.
+
+The signature is moved into the "X-Amz-*" query parameters of req.URL, which also carries the
+session token of temporary credentials in "X-Amz-Security-Token". The request time is taken from
+the "x-amz-date" or "date" header as with Sign, or is the current time, and the "x-amz-date" header
+is removed. The remaining headers, such as "host", are signed and must be sent with the URL.
+
+S3 payloads are left unsigned unless req has an "x-amz-content-sha256" header, the payloads of
+other services are read to be hashed. expires must be between one second and MaxPresignExpires.
+*/
+func PresignV4(req *http.Request, auth Auth, region, service string, expires time.Duration) (string, error) {
+	if expires < time.Second || expires > MaxPresignExpires {
+		return "", fmt.Errorf("presigned request expiration must be between 1s and %v, not %v", MaxPresignExpires, expires)
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	s := NewV4Signer(auth, service, Region{Name: region})
+	t := s.requestTime(req)
+	req.Header.Del("x-amz-date")
+	req.Header.Del("Authorization")
+	req.Header.Set("host", req.Host)
+
+	payload := UnsignedPayload
+	if service != "s3" || req.Header.Get("x-amz-content-sha256") != "" {
+		payload = s.payloadHash(req)
+	}
+
+	query := req.URL.Query()
+	query.Del("X-Amz-Signature")
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", auth.AccessKey+"/"+s.credentialScope(t))
+	query.Set("X-Amz-Date", t.Format(ISO8601BasicFormat))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", s.signedHeaders(req.Header))
+	if token := auth.Token(); token != "" {
+		query.Set("X-Amz-Security-Token", token)
+	}
+	// Encode the query as it is signed, so that spaces are sent as "%20"
+	req.URL.RawQuery = query.Encode()
+	req.URL.RawQuery = s.canonicalQueryString(req.URL)
+
+	creq := s.canonicalRequestWithPayload(req, payload)
+	signature := s.signature(t, s.stringToSign(t, creq))
+	req.URL.RawQuery += "&X-Amz-Signature=" + signature
+	return req.URL.String(), nil
+}
+
+/*
+SignPolicyV4 returns the hex encoded Signature Version 4 signature of policy, the base64 encoded
+policy document of a browser upload to S3 with an HTML form, for service in the named region at time t.
+The policy must require the "x-amz-credential" field to be the credential scope for t with auth's access key.
+*/
+func SignPolicyV4(policy string, auth Auth, region, service string, t time.Time) string {
+	s := NewV4Signer(auth, service, Region{Name: region})
+	return s.signature(t, policy)
+}
+
 /*
 Sign a request according to the AWS Signature Version 4 Signing Process. (http://goo.gl/u1OWZz)
 
@@ -135,12 +215,22 @@ or "date" header was not available in the original request. In addition, AWS Sig
 the "host" header to be a signed header, therefor the Sign method will manually set a "host" header from
 the request.Host.
 
+If auth holds a session token, it is sent and signed in the "x-amz-security-token" header. The payload
+hash is taken from the "x-amz-content-sha256" header when present, in which case the body is not read,
+and S3 requests are given that header as it is required by S3.
+
 The signed request will include a new "Authorization" header indicating that the request has been signed.
 
 Any changes to the request after signing the request will invalidate the signature.
 */
 func (s *V4Signer) Sign(req *http.Request) {
-	req.Header.Set("host", req.Host)                  // host header must be included as a signed header
+	req.Header.Set("host", req.Host) // host header must be included as a signed header
+	if token := s.auth.Token(); token != "" {
+		req.Header.Set("x-amz-security-token", token)
+	}
+	if s.serviceName == "s3" && req.Header.Get("x-amz-content-sha256") == "" {
+		req.Header.Set("x-amz-content-sha256", s.payloadHash(req))
+	}
 	t := s.requestTime(req)                           // Get requst time
 	creq := s.canonicalRequest(req)                   // Build canonical request
 	sts := s.stringToSign(t, creq)                    // Build string to sign
@@ -201,13 +291,18 @@ canonicalRequest method creates the canonical request according to Task 1 of the
       HexEncode(Hash(Payload))
 */
 func (s *V4Signer) canonicalRequest(req *http.Request) string {
+	return s.canonicalRequestWithPayload(req, s.payloadHash(req))
+}
+
+// canonicalRequestWithPayload creates the canonical request of req with the given payload hash.
+func (s *V4Signer) canonicalRequestWithPayload(req *http.Request, payloadHash string) string {
 	c := new(bytes.Buffer)
 	fmt.Fprintf(c, "%s\n", req.Method)
 	fmt.Fprintf(c, "%s\n", s.canonicalURI(req.URL))
 	fmt.Fprintf(c, "%s\n", s.canonicalQueryString(req.URL))
 	fmt.Fprintf(c, "%s\n\n", s.canonicalHeaders(req.Header))
 	fmt.Fprintf(c, "%s\n", s.signedHeaders(req.Header))
-	fmt.Fprintf(c, "%s", s.payloadHash(req))
+	fmt.Fprintf(c, "%s", payloadHash)
 	return c.String()
 }
 
@@ -216,6 +311,10 @@ func (s *V4Signer) canonicalURI(u *url.URL) string {
 	if u.RawQuery != "" {
 		canonicalPath = canonicalPath[:len(canonicalPath)-len(u.RawQuery)-1]
 	}
+	if s.serviceName == "s3" {
+		// S3 does not normalize paths, keys may hold "//" or "."
+		return canonicalPath
+	}
 	slash := strings.HasSuffix(canonicalPath, "/")
 	canonicalPath = path.Clean(canonicalPath)
 	if canonicalPath != "/" && slash {
@@ -227,12 +326,12 @@ func (s *V4Signer) canonicalURI(u *url.URL) string {
 func (s *V4Signer) canonicalQueryString(u *url.URL) string {
 	var a []string
 	for k, vs := range u.Query() {
-		k = url.QueryEscape(k)
+		k = queryEscape(k)
 		for _, v := range vs {
 			if v == "" {
 				a = append(a, k+"=")
 			} else {
-				v = url.QueryEscape(v)
+				v = queryEscape(v)
 				a = append(a, k+"="+v)
 			}
 		}
@@ -241,6 +340,12 @@ func (s *V4Signer) canonicalQueryString(u *url.URL) string {
 	return strings.Join(a, "&")
 }
 
+// queryEscape escapes s as required by the canonical query string, where
+// spaces must be encoded as "%20" rather than "+".
+func queryEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
 func (s *V4Signer) canonicalHeaders(h http.Header) string {
 	i, a := 0, make([]string, len(h))
 	for k, v := range h {
@@ -266,18 +371,22 @@ func (s *V4Signer) signedHeaders(h http.Header) string {
 }
 
 func (s *V4Signer) payloadHash(req *http.Request) string {
-	var b []byte
+	if h := req.Header.Get("x-amz-content-sha256"); h != "" {
+		return h
+	}
 	if req.Body == nil {
-		b = []byte("")
-	} else {
-		var err error
-		b, err = ioutil.ReadAll(req.Body)
-		if err != nil {
-			// TODO: I REALLY DON'T LIKE THIS PANIC!!!!
-			panic(err)
-		}
+		return s.hash("")
+	}
+	b, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		// TODO: I REALLY DON'T LIKE THIS PANIC!!!!
+		panic(err)
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	// Keep the body replayable for retries
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
 	}
-	req.Body = ioutil.NopCloser(bytes.NewBuffer(b))
 	return s.hash(string(b))
 }
 
@@ -41,30 +41,7 @@ const debug = false
 // Error encapsulates an error returned by the AWS CloudFormation API.
 //
 // See http://goo.gl/zDZbuQ  for more details.
-type Error struct {
-	// HTTP status code (200, 403, ...)
-	StatusCode int
-	// Error type
-	Type string `xml:"Type"`
-	// CloudFormation error code
-	Code string `xml:"Code"`
-	// The human-oriented error message
-	Message   string `xml:"Message"`
-	RequestId string `xml:"RequestID"`
-}
-
-func (err *Error) Error() string {
-	if err.Code == "" {
-		return err.Message
-	}
-
-	return fmt.Sprintf("%s (%s)", err.Message, err.Code)
-}
-
-type xmlErrors struct {
-	RequestId string  `xml:"RequestId"`
-	Errors    []Error `xml:"Error"`
-}
+type Error = aws.Error
 
 func (c *CloudFormation) query(params map[string]string, resp interface{}) error {
 	params["Version"] = "2010-05-15"
@@ -111,21 +88,7 @@ func (c *CloudFormation) query(params map[string]string, resp interface{}) error
 }
 
 func buildError(r *http.Response) error {
-	var (
-		err    Error
-		errors xmlErrors
-	)
-	xml.NewDecoder(r.Body).Decode(&errors)
-	if len(errors.Errors) > 0 {
-		err = errors.Errors[0]
-	}
-
-	err.RequestId = errors.RequestId
-	err.StatusCode = r.StatusCode
-	if err.Message == "" {
-		err.Message = r.Status
-	}
-	return &err
+	return aws.BuildError(r)
 }
 
 func makeParams(action string) map[string]string {
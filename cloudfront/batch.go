@@ -0,0 +1,135 @@
+package cloudfront
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BatchOptions configures how SignURLs signs URLs.
+type BatchOptions struct {
+	// Concurrency is the number of goroutines signing URLs in parallel.
+	// URLs are signed sequentially when it is lower than 2.
+	Concurrency int
+
+	// SharedPolicy makes SignURLs sign a single custom policy granting access
+	// to everything under the deepest directory the paths have in common,
+	// rather than a canned policy per URL. All URLs then carry the same
+	// policy and signature, at the cost of each of them granting access to
+	// the whole directory. Paths with no common directory below BaseURL are
+	// signed individually.
+	SharedPolicy bool
+}
+
+// SignURLError reports the path SignURLs failed to sign.
+type SignURLError struct {
+	Index int
+	Path  string
+	Err   error
+}
+
+func (err *SignURLError) Error() string {
+	return fmt.Sprintf("cannot sign URL for path %q: %v", err.Path, err.Err)
+}
+
+// SignURLs returns signed URLs granting access to paths until expires, in
+// the same order, as configured by cf.Batch. If any path cannot be signed a
+// *SignURLError is returned for the first of them.
+func (cf *CloudFront) SignURLs(paths []string, expires time.Time) ([]string, error) {
+	if err := cf.checkExpires(expires); err != nil {
+		return nil, err
+	}
+
+	if cf.Batch.SharedPolicy && len(paths) > 1 {
+		urls, err := cf.signURLsShared(paths, expires)
+		if urls != nil || err != nil {
+			return urls, err
+		}
+	}
+
+	urls := make([]string, len(paths))
+	errs := make([]error, len(paths))
+
+	workers := cf.Batch.Concurrency
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+
+	if workers < 2 {
+		var buf bytes.Buffer
+		for i, path := range paths {
+			urls[i], errs[i] = cf.cannedSignedURL(&buf, path, "", expires)
+		}
+	} else {
+		indexes := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var buf bytes.Buffer
+				for i := range indexes {
+					urls[i], errs[i] = cf.cannedSignedURL(&buf, paths[i], "", expires)
+				}
+			}()
+		}
+		for i := range paths {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, &SignURLError{Index: i, Path: paths[i], Err: err}
+		}
+	}
+	return urls, nil
+}
+
+// signURLsShared signs paths with a single policy for their common
+// directory. It returns nil URLs and no error when they have none.
+func (cf *CloudFront) signURLsShared(paths []string, expires time.Time) ([]string, error) {
+	root, err := cf.resourceURL("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	uris := make([]*url.URL, len(paths))
+	prefix := ""
+	for i, path := range paths {
+		if uris[i], err = cf.resourceURL(path, ""); err != nil {
+			return nil, &SignURLError{Index: i, Path: path, Err: err}
+		}
+		resource := uris[i].String()
+		if i == 0 {
+			prefix = resource
+			continue
+		}
+		for !strings.HasPrefix(resource, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	prefix = prefix[:strings.LastIndex(prefix, "/")+1]
+	if len(prefix) <= len(root.String()) {
+		return nil, nil
+	}
+
+	policy, signature, err := cf.Sign(NewCustomPolicy(prefix+"*", PolicyOptions{Expires: expires}))
+	if err != nil {
+		return nil, err
+	}
+
+	params := fmt.Sprintf("Policy=%s&Signature=%s&Key-Pair-Id=%s", encodeBase64(policy), signature, cf.keyPairId)
+
+	urls := make([]string, len(uris))
+	for i, uri := range uris {
+		urls[i] = signedURL(uri, params)
+	}
+	return urls, nil
+}
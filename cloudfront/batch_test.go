@@ -0,0 +1,195 @@
+package cloudfront
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignURLs(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com/")
+	verifier := NewVerifier(testPublicKey(t), "test-key-pair-1231245")
+	expires := time.Now().Add(time.Hour)
+
+	paths := []string{"a/1.jpg", "b/2.jpg", "c/3.jpg", "4.jpg", "a/5.jpg"}
+
+	for _, concurrency := range []int{0, 1, 3, 10} {
+		cf.Batch.Concurrency = concurrency
+
+		urls, err := cf.SignURLs(paths, expires)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(urls) != len(paths) {
+			t.Fatalf("Expected %d URLs, got %d", len(paths), len(urls))
+		}
+
+		for i, u := range urls {
+			expected, err := cf.CannedSignedURL(paths[i], "", expires)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u != expected {
+				t.Errorf("Concurrency %d, URL %d: expected %s, got %s", concurrency, i, expected, u)
+			}
+			if err := verifier.VerifyURL(u); err != nil {
+				t.Errorf("Concurrency %d, URL %d: %v", concurrency, i, err)
+			}
+		}
+	}
+}
+
+func TestSignURLsSharedPolicy(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com/")
+	cf.Batch.SharedPolicy = true
+	verifier := NewVerifier(testPublicKey(t), "test-key-pair-1231245")
+	expires := time.Now().Add(time.Hour)
+
+	urls, err := cf.SignURLs([]string{"videos/hls/seg-1.ts", "videos/hls/seg-2.ts", "videos/hls-low/seg-1.ts"}, expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature := ""
+	for i, u := range urls {
+		if !strings.Contains(u, "Policy=") {
+			t.Fatalf("URL %d is not signed with a custom policy: %s", i, u)
+		}
+		sig := u[strings.Index(u, "Signature="):]
+		if i > 0 && sig != signature {
+			t.Errorf("URL %d has a different signature", i)
+		}
+		signature = sig
+
+		if err := verifier.VerifyURL(u); err != nil {
+			t.Errorf("URL %d: %v", i, err)
+		}
+	}
+
+	policy, _, _, err := cf.CookieWithPolicy("https://cloudfront.com/videos/*", PolicyOptions{Expires: expires})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(urls[0], "Policy="+policy+"&") {
+		t.Errorf("Expected a policy for the common directory, got %s", urls[0])
+	}
+
+	// Nothing in common but the root: every URL gets its own canned policy.
+	urls, err = cf.SignURLs([]string{"a/1.jpg", "b/2.jpg"}, expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, u := range urls {
+		if !strings.Contains(u, "Expires=") {
+			t.Errorf("URL %d is not signed with a canned policy: %s", i, u)
+		}
+	}
+}
+
+// failingSigner refuses to sign the given digests.
+type failingSigner struct {
+	key     *rsa.PrivateKey
+	refused map[string]bool
+}
+
+func (s *failingSigner) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+func (s *failingSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if s.refused[string(digest)] {
+		return nil, errors.New("refused")
+	}
+	return s.key.Sign(rand, digest, opts)
+}
+
+func TestSignURLsError(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	paths := []string{"dir/ok", "dir/bad", "dir/fine", "dir/worse"}
+
+	signer := &failingSigner{key: testPrivateKey(t), refused: map[string]bool{}}
+	for _, path := range []string{"dir/bad", "dir/worse"} {
+		policy, err := NewCannedPolicy("https://cloudfront.com/"+path, expires).JSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest := sha1.Sum(policy)
+		signer.refused[string(digest[:])] = true
+	}
+
+	cf, err := NewWithSigner("https://cloudfront.com/", signer, "test-key-pair-1231245")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, concurrency := range []int{0, 4} {
+		cf.Batch.Concurrency = concurrency
+
+		_, err := cf.SignURLs(paths, expires)
+		signErr, ok := err.(*SignURLError)
+		if !ok {
+			t.Fatalf("Concurrency %d: expected a *SignURLError, got %#v", concurrency, err)
+		}
+		if signErr.Index != 1 || signErr.Path != "dir/bad" {
+			t.Errorf("Concurrency %d: expected the error to be about path 1, got %d (%s)", concurrency, signErr.Index, signErr.Path)
+		}
+		if !strings.Contains(err.Error(), `"dir/bad"`) {
+			t.Errorf("Concurrency %d: expected the error to name the path, got %v", concurrency, err)
+		}
+	}
+}
+
+func benchmarkPaths(n int) []string {
+	paths := make([]string, n)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("videos/hls/segment-%d.ts", i)
+	}
+	return paths
+}
+
+func BenchmarkSignURLsLoop(b *testing.B) {
+	cf := testCloudFront(b, "https://cloudfront.com/")
+	paths := benchmarkPaths(100)
+	expires := time.Now().Add(time.Hour)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, path := range paths {
+			if _, err := cf.CannedSignedURL(path, "", expires); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func benchmarkSignURLs(b *testing.B, opts BatchOptions) {
+	cf := testCloudFront(b, "https://cloudfront.com/")
+	cf.Batch = opts
+	paths := benchmarkPaths(100)
+	expires := time.Now().Add(time.Hour)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cf.SignURLs(paths, expires); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSignURLs(b *testing.B) {
+	benchmarkSignURLs(b, BatchOptions{})
+}
+
+func BenchmarkSignURLsParallel(b *testing.B) {
+	benchmarkSignURLs(b, BatchOptions{Concurrency: 8})
+}
+
+func BenchmarkSignURLsSharedPolicy(b *testing.B) {
+	benchmarkSignURLs(b, BatchOptions{SharedPolicy: true})
+}
@@ -0,0 +1,183 @@
+package cloudfront
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+)
+
+// APIVersion is the version of the CloudFront API used by Client.
+const APIVersion = "2014-11-06"
+
+const xmlns = "http://cloudfront.amazonaws.com/doc/" + APIVersion + "/"
+
+const defaultEndpoint = "https://cloudfront.amazonaws.com"
+
+// Client performs requests against the CloudFront API to manage
+// distributions and their content.
+type Client struct {
+	aws.Auth
+
+	// Credentials, when not nil, provides the credentials used to sign
+	// each request instead of Auth, so that temporary credentials can be
+	// renewed without creating a new Client.
+	Credentials aws.CredentialsProvider
+
+	// RetryPolicy is used to retry failed requests. It defaults to
+	// aws.DefaultRetryPolicy.
+	RetryPolicy *aws.RetryPolicy
+
+	// HTTPClient performs the requests. It defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// Endpoint is the base URL of the CloudFront API. It defaults to
+	// https://cloudfront.amazonaws.com.
+	Endpoint string
+
+	// clock corrects the time requests are signed with when the local
+	// clock is skewed.
+	clock aws.Clock
+}
+
+// NewClient returns a Client performing requests with auth.
+func NewClient(auth aws.Auth) *Client {
+	return &Client{Auth: auth, Endpoint: defaultEndpoint}
+}
+
+// Error encapsulates an error returned by the CloudFront API.
+type Error = aws.Error
+
+// PreconditionFailedError is returned when the ETag passed along an update or
+// a deletion does not match the current version of the resource, because it
+// was modified concurrently. The resource should be read again and the
+// change applied on its latest version.
+type PreconditionFailedError Error
+
+func (err *PreconditionFailedError) Error() string {
+	return (*Error)(err).Error()
+}
+
+// Unwrap returns err as an *aws.Error, for aws.IsCode and aws.RequestID.
+func (err *PreconditionFailedError) Unwrap() error {
+	return (*Error)(err)
+}
+
+func buildError(r *http.Response) error {
+	err := aws.BuildError(r)
+	if err.Code == "PreconditionFailed" {
+		return (*PreconditionFailedError)(err)
+	}
+	return err
+}
+
+// query performs a request on path, relative to the API version, and
+// decodes the XML response into result unless it is nil. When body is not
+// nil it is marshalled as the XML request body. The response headers are
+// returned so callers can read the ETag and Location of resources.
+func (c *Client) query(method, path string, headers map[string]string, body, result interface{}) (http.Header, error) {
+	return c.queryVersion(APIVersion, method, path, headers, body, result)
+}
+
+// queryVersion is like query for a path relative to another version of the
+// API, for calls added after APIVersion.
+func (c *Client) queryVersion(version, method, path string, headers map[string]string, body, result interface{}) (http.Header, error) {
+	var data []byte
+	if body != nil {
+		b, err := xml.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		data = append([]byte(xml.Header), b...)
+	}
+
+	auth, err := aws.CurrentAuth(c.Auth, c.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := c.RetryPolicy
+	if policy == nil {
+		policy = &aws.DefaultRetryPolicy
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	r, err := c.clock.Do(policy, client, func(now time.Time) (*http.Request, error) {
+		var reader io.Reader
+		if data != nil {
+			reader = bytes.NewReader(data)
+		}
+		hreq, err := http.NewRequest(method, c.Endpoint+"/"+version+path, reader)
+		if err != nil {
+			return nil, err
+		}
+
+		if body != nil {
+			hreq.Header.Set("Content-Type", "text/xml")
+		}
+		for k, v := range headers {
+			hreq.Header.Set(k, v)
+		}
+		hreq.Header.Set("X-Amz-Date", now.UTC().Format(aws.ISO8601BasicFormat))
+
+		token := auth.Token()
+		if token != "" {
+			hreq.Header.Set("X-Amz-Security-Token", token)
+		}
+
+		signer := aws.NewV4Signer(auth, "cloudfront", aws.USEast)
+		signer.Sign(hreq)
+		return hreq, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode < 200 || r.StatusCode >= 300 {
+		return nil, buildError(r)
+	}
+
+	if result != nil {
+		if err := xml.NewDecoder(r.Body).Decode(result); err != nil {
+			return nil, err
+		}
+	}
+	return r.Header, nil
+}
+
+// Delays between polls of the wait helpers. They grow exponentially from
+// the minimum up to the maximum.
+var (
+	minPollDelay = 5 * time.Second
+	maxPollDelay = time.Minute
+)
+
+// waitFor calls done with growing delays until it returns true, an error,
+// or timeout is reached.
+func waitFor(timeout time.Duration, done func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	delay := minPollDelay
+	for {
+		ok, err := done()
+		if err != nil || ok {
+			return err
+		}
+
+		if time.Now().Add(delay).After(deadline) {
+			return fmt.Errorf("timed out after %v", timeout)
+		}
+		time.Sleep(delay)
+
+		delay *= 2
+		if delay > maxPollDelay {
+			delay = maxPollDelay
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package cloudfront
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+)
+
+type testResponse struct {
+	Status  int
+	Headers map[string]string
+	Body    string
+}
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   string
+}
+
+// testAPI serves the given responses in order and records the requests made
+// to it.
+type testAPI struct {
+	*httptest.Server
+	t         *testing.T
+	responses []testResponse
+	requests  []recordedRequest
+}
+
+func newTestAPI(t *testing.T, responses ...testResponse) (*testAPI, *Client) {
+	api := &testAPI{t: t, responses: responses}
+	api.Server = httptest.NewServer(api)
+
+	client := NewClient(aws.Auth{AccessKey: "abc", SecretKey: "123"})
+	client.Endpoint = api.URL
+	return api, client
+}
+
+func (api *testAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	api.requests = append(api.requests, recordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.RawQuery,
+		Header: req.Header,
+		Body:   string(body),
+	})
+
+	if len(api.responses) == 0 {
+		api.t.Errorf("Unexpected request %s %s", req.Method, req.URL)
+		w.WriteHeader(500)
+		return
+	}
+
+	resp := api.responses[0]
+	api.responses = api.responses[1:]
+	for k, v := range resp.Headers {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(resp.Status)
+	w.Write([]byte(resp.Body))
+}
+
+func TestClientSignsRequests(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 200, Body: GetInvalidationResponse})
+	defer api.Close()
+
+	if _, err := client.GetInvalidation("EDFDVBD6EXAMPLE", "IDFDVBD632BHDS5"); err != nil {
+		t.Fatal(err)
+	}
+
+	req := api.requests[0]
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=abc/") || !strings.Contains(auth, "/us-east-1/cloudfront/aws4_request") {
+		t.Fatalf("Unexpected Authorization header: %s", auth)
+	}
+}
+
+// rotatingCredentials returns new keys each time they are requested.
+type rotatingCredentials struct {
+	n int
+}
+
+func (r *rotatingCredentials) Credentials() (aws.Auth, error) {
+	r.n++
+	return aws.Auth{AccessKey: fmt.Sprintf("access-%d", r.n), SecretKey: "secret"}, nil
+}
+
+func TestClientCredentialsRotation(t *testing.T) {
+	api, client := newTestAPI(t,
+		testResponse{Status: 200, Body: GetInvalidationResponse},
+		testResponse{Status: 200, Body: GetInvalidationResponse},
+	)
+	defer api.Close()
+	client.Credentials = &rotatingCredentials{}
+
+	for i := 1; i <= 2; i++ {
+		if _, err := client.GetInvalidation("EDFDVBD6EXAMPLE", "IDFDVBD632BHDS5"); err != nil {
+			t.Fatal(err)
+		}
+		auth := api.requests[i-1].Header.Get("Authorization")
+		if !strings.HasPrefix(auth, fmt.Sprintf("AWS4-HMAC-SHA256 Credential=access-%d/", i)) {
+			t.Fatalf("Request %d: unexpected Authorization header: %s", i, auth)
+		}
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	api, client := newTestAPI(t,
+		testResponse{Status: 503, Body: ""},
+		testResponse{Status: 200, Body: GetInvalidationResponse},
+	)
+	defer api.Close()
+	client.RetryPolicy = &aws.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+
+	if _, err := client.GetInvalidation("EDFDVBD6EXAMPLE", "IDFDVBD632BHDS5"); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(api.requests))
+	}
+
+	api.responses = []testResponse{{Status: 503, Body: ""}}
+	client.RetryPolicy = &aws.NoRetry
+	if _, err := client.GetInvalidation("EDFDVBD6EXAMPLE", "IDFDVBD632BHDS5"); err == nil {
+		t.Fatal("Expected an error without retries")
+	}
+}
+
+func TestClientHTTPClientTimeout(t *testing.T) {
+	hang := make(chan struct{})
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-hang
+	}))
+	defer api.Close()
+	defer close(hang)
+
+	client := NewClient(aws.Auth{AccessKey: "abc", SecretKey: "123"})
+	client.Endpoint = api.URL
+	client.RetryPolicy = &aws.NoRetry
+	client.HTTPClient = &http.Client{Timeout: 50 * time.Millisecond}
+
+	start := time.Now()
+	_, err := client.GetInvalidation("EDFDVBD6EXAMPLE", "IDFDVBD632BHDS5")
+	if err == nil || !strings.Contains(err.Error(), "Timeout") {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Request took %v despite the timeout", elapsed)
+	}
+}
+
+func TestClientError(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 404, Body: NoSuchDistributionResponse})
+	defer api.Close()
+
+	_, err := client.GetInvalidation("EDFDVBD6EXAMPLE", "IDFDVBD632BHDS5")
+	cferr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("Expected an *Error, got %#v", err)
+	}
+	if cferr.StatusCode != 404 || cferr.Code != "NoSuchDistribution" || cferr.Type != "Sender" {
+		t.Fatalf("Unexpected error: %#v", cferr)
+	}
+	if cferr.RequestId != "a2b8a1d5-4a1c-11e4-8a8b-EXAMPLE" {
+		t.Fatalf("Unexpected request id: %s", cferr.RequestId)
+	}
+	if cferr.Error() != "The specified distribution does not exist. (NoSuchDistribution)" {
+		t.Fatalf("Unexpected error message: %s", cferr.Error())
+	}
+}
+
+func setFastPolling() func() {
+	min, max := minPollDelay, maxPollDelay
+	minPollDelay, maxPollDelay = time.Millisecond, 2*time.Millisecond
+	return func() {
+		minPollDelay, maxPollDelay = min, max
+	}
+}
@@ -1,65 +1,168 @@
 package cloudfront
 
 import (
+	"bytes"
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type CloudFront struct {
-	BaseURL   string
+	BaseURL string
+
+	// Batch configures how SignURLs signs URLs.
+	Batch BatchOptions
+
+	// Clock tells the time relative expirations are computed from. It
+	// defaults to the system clock.
+	Clock Clock
+
+	// Rand is the source of randomness passed to the signer. It defaults to
+	// crypto/rand.Reader.
+	Rand io.Reader
+
+	baseURL   *url.URL
 	keyPairId string
-	key       *rsa.PrivateKey
+	key       crypto.Signer
+	keys      map[string]crypto.Signer
+}
+
+// Clock tells the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (cf *CloudFront) now() time.Time {
+	if cf.Clock == nil {
+		return systemClock{}.Now()
+	}
+	return cf.Clock.Now()
+}
+
+func (cf *CloudFront) rand() io.Reader {
+	if cf.Rand == nil {
+		return rand.Reader
+	}
+	return cf.Rand
 }
 
 var base64Replacer = strings.NewReplacer("=", "_", "+", "-", "/", "~")
 
-func New(baseurl string, key *rsa.PrivateKey, keyPairId string) *CloudFront {
+// New returns a CloudFront signing URLs and cookies for resources under
+// baseurl, which must be an absolute URL such as "https://dxxx.cloudfront.net".
+func New(baseurl string, key *rsa.PrivateKey, keyPairId string) (*CloudFront, error) {
+	return NewWithSigner(baseurl, key, keyPairId)
+}
+
+// NewWithSigner is like New but signs policies with signer, so the RSA key
+// may be kept outside the process, e.g. in an HSM or AWS KMS. The signer is
+// asked to sign the SHA-1 digest of the policy with PKCS #1 v1.5, which is
+// what signing with an *rsa.PrivateKey does.
+func NewWithSigner(baseurl string, signer crypto.Signer, keyPairId string) (*CloudFront, error) {
+	uri, err := parseBaseURL(baseurl)
+	if err != nil {
+		return nil, err
+	}
+
 	return &CloudFront{
 		BaseURL:   baseurl,
+		baseURL:   uri,
 		keyPairId: keyPairId,
-		key:       key,
-	}
+		key:       signer,
+		keys:      map[string]crypto.Signer{keyPairId: signer},
+	}, nil
 }
 
-type epochTime struct {
-	EpochTime int64 `json:"AWS:EpochTime"`
+// AddKeyPair registers another key pair, so that URLs and cookies can be
+// signed with either while rotating keys. The default key pair is unchanged.
+//
+// AddKeyPair and SetDefaultKeyPair must not be called while cf is signing.
+func (cf *CloudFront) AddKeyPair(keyPairId string, key *rsa.PrivateKey) {
+	cf.AddSigner(keyPairId, key)
 }
 
-type condition struct {
-	DateLessThan epochTime
+// AddSigner is like AddKeyPair for a key held by signer.
+func (cf *CloudFront) AddSigner(keyPairId string, signer crypto.Signer) {
+	if cf.keys == nil {
+		cf.keys = map[string]crypto.Signer{}
+		if cf.key != nil {
+			cf.keys[cf.keyPairId] = cf.key
+		}
+	}
+	cf.keys[keyPairId] = signer
 }
 
-type statement struct {
-	Resource  string
-	Condition condition
+// SetDefaultKeyPair makes cf sign with the registered key pair keyPairId.
+func (cf *CloudFront) SetDefaultKeyPair(keyPairId string) error {
+	signer, err := cf.signer(keyPairId)
+	if err != nil {
+		return err
+	}
+	cf.keyPairId, cf.key = keyPairId, signer
+	return nil
 }
 
-type policy struct {
-	Statement []statement
+// SignWith returns a copy of cf signing with the registered key pair
+// keyPairId instead of the default one.
+func (cf *CloudFront) SignWith(keyPairId string) (*CloudFront, error) {
+	signer, err := cf.signer(keyPairId)
+	if err != nil {
+		return nil, err
+	}
+	c := *cf
+	c.keyPairId, c.key = keyPairId, signer
+	return &c, nil
 }
 
-func buildPolicy(resource string, expireTime time.Time) ([]byte, error) {
-	p := &policy{
-		Statement: []statement{
-			statement{
-				Resource: resource,
-				Condition: condition{
-					DateLessThan: epochTime{
-						EpochTime: expireTime.Truncate(time.Millisecond).Unix(),
-					},
-				},
-			},
-		}}
+// Verifier returns a Verifier accepting signatures made by any of the
+// registered key pairs, checking expirations against the Clock of cf.
+func (cf *CloudFront) Verifier() (*Verifier, error) {
+	v := &Verifier{Clock: cf.Clock, keys: map[string]*rsa.PublicKey{}}
+	for keyPairId, signer := range cf.keys {
+		key, ok := signer.Public().(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("key pair %s is not an RSA key pair", keyPairId)
+		}
+		v.keys[keyPairId] = key
+	}
+	return v, nil
+}
 
-	return json.Marshal(p)
+func (cf *CloudFront) signer(keyPairId string) (crypto.Signer, error) {
+	if keyPairId == cf.keyPairId && cf.key != nil {
+		return cf.key, nil
+	}
+	signer, ok := cf.keys[keyPairId]
+	if !ok {
+		return nil, fmt.Errorf("unknown key pair %s", keyPairId)
+	}
+	return signer, nil
+}
+
+func parseBaseURL(baseurl string) (*url.URL, error) {
+	uri, err := url.Parse(baseurl)
+	if err != nil {
+		return nil, fmt.Errorf("bad CloudFront base URL %q: %v", baseurl, err)
+	}
+	if uri.Scheme == "" || uri.Host == "" {
+		return nil, fmt.Errorf("bad CloudFront base URL %q: scheme and host are required", baseurl)
+	}
+	return uri, nil
 }
 
 func (cf *CloudFront) generateSignature(policy []byte) (string, error) {
@@ -70,64 +173,286 @@ func (cf *CloudFront) generateSignature(policy []byte) (string, error) {
 
 	hashed := hash.Sum(nil)
 
-	signed, err := rsa.SignPKCS1v15(rand.Reader, cf.key, crypto.SHA1, hashed)
+	signed, err := cf.key.Sign(cf.rand(), hashed, crypto.SHA1)
 	if err != nil {
 		return "", err
 	}
 
-	encoded := base64Replacer.Replace(base64.StdEncoding.EncodeToString(signed))
-	return encoded, nil
+	return encodeBase64(signed), nil
+}
+
+// encodeBase64 encodes b in the URL-safe variant of base64 CloudFront uses
+// for policies and signatures.
+func encodeBase64(b []byte) string {
+	return base64Replacer.Replace(base64.StdEncoding.EncodeToString(b))
 }
 
 func (cf *CloudFront) Cookie(resource string, expires time.Time) (b64Policy, b64SignedPolicy, keyPairId string, err error) {
+	uri, err := cf.resourceURL(resource, "")
+	if err != nil {
+		return
+	}
+
+	policy, b64SignedPolicy, err := cf.Sign(NewCannedPolicy(uri.String(), expires))
+	if err != nil {
+		return
+	}
+
+	keyPairId = cf.keyPairId
+	b64Policy = encodeBase64(policy)
+	return
+}
+
+// Names of the cookies CloudFront reads signed cookie values from.
+const (
+	PolicyCookieName    = "CloudFront-Policy"
+	SignatureCookieName = "CloudFront-Signature"
+	KeyPairIdCookieName = "CloudFront-Key-Pair-Id"
+)
+
+// CookieAttributes holds the attributes set on every signed cookie returned
+// by Cookies. The cookies' MaxAge and Expires are derived from the policy
+// expiration time.
+type CookieAttributes struct {
+	Domain   string
+	Path     string
+	Secure   bool
+	HttpOnly bool
+}
+
+// Cookies returns the signed cookies granting access to resource until
+// expires, ready to be set on a response.
+func (cf *CloudFront) Cookies(resource string, expires time.Time, attrs CookieAttributes) ([]*http.Cookie, error) {
+	b64Policy, b64SignedPolicy, keyPairId, err := cf.Cookie(resource, expires)
+	if err != nil {
+		return nil, err
+	}
 
-	//create policy
-	policy, err := buildPolicy(strings.TrimSuffix(cf.BaseURL, "/")+"/"+resource, expires)
+	maxAge := int(expires.Sub(cf.now()) / time.Second)
+	if maxAge <= 0 {
+		maxAge = -1
+	}
+
+	values := []struct{ name, value string }{
+		{PolicyCookieName, b64Policy},
+		{SignatureCookieName, b64SignedPolicy},
+		{KeyPairIdCookieName, keyPairId},
+	}
+
+	cookies := make([]*http.Cookie, 0, len(values))
+	for _, v := range values {
+		cookies = append(cookies, &http.Cookie{
+			Name:     v.name,
+			Value:    v.value,
+			Domain:   attrs.Domain,
+			Path:     attrs.Path,
+			Expires:  expires,
+			MaxAge:   maxAge,
+			Secure:   attrs.Secure,
+			HttpOnly: attrs.HttpOnly,
+		})
+	}
+	return cookies, nil
+}
+
+// SetCookies sets the signed cookies granting access to resource until
+// expires on w.
+func (cf *CloudFront) SetCookies(w http.ResponseWriter, resource string, expires time.Time, attrs CookieAttributes) error {
+	cookies, err := cf.Cookies(resource, expires, attrs)
 	if err != nil {
+		return err
+	}
+
+	for _, cookie := range cookies {
+		http.SetCookie(w, cookie)
+	}
+	return nil
+}
+
+// CookieWithPolicy returns the values of the CloudFront-Policy,
+// CloudFront-Signature and CloudFront-Key-Pair-Id cookies for a custom
+// policy. Unlike Cookie, resourcePattern is used as is and may contain
+// wildcards, e.g. "https://dxxx.cloudfront.net/videos/1234/*", so a single
+// set of cookies can grant access to many objects. The pattern must include
+// the scheme and host, unless it is "*" which grants access to all content
+// served by the distribution.
+func (cf *CloudFront) CookieWithPolicy(resourcePattern string, opts PolicyOptions) (b64Policy, b64SignedPolicy, keyPairId string, err error) {
+	if err = validateResourcePattern(resourcePattern); err != nil {
 		return
 	}
 
-	b64SignedPolicy, err = cf.generateSignature(policy)
+	policy, b64SignedPolicy, err := cf.Sign(NewCustomPolicy(resourcePattern, opts))
 	if err != nil {
 		return
 	}
 
 	keyPairId = cf.keyPairId
-	b64Policy = base64Replacer.Replace(base64.StdEncoding.EncodeToString(policy))
+	b64Policy = encodeBase64(policy)
 	return
 }
 
+func validateResourcePattern(pattern string) error {
+	if pattern == "*" {
+		return nil
+	}
+
+	i := strings.Index(pattern, "://")
+	if i <= 0 {
+		return fmt.Errorf("resource pattern %q must include the scheme and host", pattern)
+	}
+
+	host := pattern[i+3:]
+	if j := strings.Index(host, "/"); j >= 0 {
+		host = host[:j]
+	}
+	if host == "" {
+		return fmt.Errorf("resource pattern %q must include the scheme and host", pattern)
+	}
+	return nil
+}
+
+// reservedParams are the query parameters CloudFront reads the signature
+// from; they must not be present in the query string of the signed resource.
+var reservedParams = []string{"Expires", "Policy", "Signature", "Key-Pair-Id"}
+
+// resourceURL builds the URL of path under BaseURL with queryString. The same
+// value is used as the policy resource and as the base of the signed URL so
+// that both always match. Path segments are URL-encoded.
+func (cf *CloudFront) resourceURL(path, queryString string) (*url.URL, error) {
+	if queryString != "" {
+		query, err := url.ParseQuery(queryString)
+		if err != nil {
+			return nil, err
+		}
+		for _, param := range reservedParams {
+			if _, ok := query[param]; ok {
+				return nil, fmt.Errorf("query string must not contain the %s parameter", param)
+			}
+		}
+	}
+
+	// The parsed base URL is shared, work on a copy.
+	var uri url.URL
+	if cf.baseURL != nil {
+		uri = *cf.baseURL
+	} else {
+		parsed, err := parseBaseURL(cf.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		uri = *parsed
+	}
+
+	// The policy must hold the resource as the client will request it, so
+	// the path is set encoded and decoded alike for uri.String() to return
+	// it exactly as escaped here.
+	rawPath := strings.TrimSuffix(uri.EscapedPath(), "/") + "/" + escapePath(strings.TrimPrefix(path, "/"))
+	decoded, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return nil, err
+	}
+	uri.Path = decoded
+	uri.RawPath = rawPath
+	uri.RawQuery = queryString
+	return &uri, nil
+}
+
+// escapePath percent-encodes the characters of path which are not allowed in
+// a URL path segment, such as spaces and non-ASCII characters. Escape
+// sequences already in path are kept as is, so that encoded paths are not
+// encoded twice, while a '%' not followed by two hexadecimal digits is
+// encoded.
+func escapePath(path string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '%' && i+2 < len(path) && isHex(path[i+1]) && isHex(path[i+2]):
+			buf.WriteString(path[i : i+3])
+			i += 2
+		case c == '/':
+			buf.WriteByte(c)
+		default:
+			buf.WriteString(url.PathEscape(path[i : i+1]))
+		}
+	}
+	return buf.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// signedURL appends the signature parameters to the resource URL.
+func signedURL(uri *url.URL, params string) string {
+	if uri.RawQuery != "" {
+		uri.RawQuery += "&"
+	}
+	uri.RawQuery += params
+	return uri.String()
+}
+
 // Creates a signed url using RSAwithSHA1 as specified by
 // http://docs.aws.amazon.com/AmazonCloudFront/latest/DeveloperGuide/private-content-creating-signed-url-canned-policy.html#private-content-canned-policy-creating-signature
+//
+// expires is truncated to whole seconds. ErrInvalidExpiry is returned if it
+// is zero or already past.
 func (cf *CloudFront) CannedSignedURL(path, queryString string, expires time.Time) (string, error) {
-	resource := strings.TrimSuffix(cf.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/")
-	if queryString != "" {
-		resource = path + "?" + queryString
+	var buf bytes.Buffer
+	return cf.cannedSignedURL(&buf, path, queryString, expires)
+}
+
+// cannedSignedURL is CannedSignedURL building the policy in buf.
+func (cf *CloudFront) cannedSignedURL(buf *bytes.Buffer, path, queryString string, expires time.Time) (string, error) {
+	uri, err := cf.resourceURL(path, queryString)
+	if err != nil {
+		return "", err
 	}
 
-	policy, err := buildPolicy(resource, expires)
+	signature, err := cf.sign(buf, NewCannedPolicy(uri.String(), expires))
 	if err != nil {
 		return "", err
 	}
 
-	signature, err := cf.generateSignature(policy)
+	return signedURL(uri, fmt.Sprintf("Expires=%d&Signature=%s&Key-Pair-Id=%s", expires.Unix(), signature, cf.keyPairId)), nil
+}
+
+// SignedURLFor returns a URL for path signed with a canned policy, valid for
+// ttl from now as told by cf.Clock.
+func (cf *CloudFront) SignedURLFor(path string, ttl time.Duration) (string, error) {
+	return cf.CannedSignedURL(path, "", cf.now().Add(ttl))
+}
+
+// Creates a signed url using a custom policy, allowing access to be limited
+// to a time window and/or a source IP range as specified by
+// http://docs.aws.amazon.com/AmazonCloudFront/latest/DeveloperGuide/private-content-creating-signed-url-custom-policy.html
+func (cf *CloudFront) SignedURLWithPolicy(path, queryString string, opts PolicyOptions) (string, error) {
+	uri, err := cf.resourceURL(path, queryString)
 	if err != nil {
 		return "", err
 	}
 
-	// TOOD: Do this once
-	uri, err := url.Parse(cf.BaseURL)
+	policy, signature, err := cf.Sign(NewCustomPolicy(uri.String(), opts))
 	if err != nil {
 		return "", err
 	}
 
-	uri.RawQuery = queryString
-	if queryString != "" {
-		uri.RawQuery += "&"
+	return signedURL(uri, fmt.Sprintf("Policy=%s&Signature=%s&Key-Pair-Id=%s", encodeBase64(policy), signature, cf.keyPairId)), nil
+}
+
+// StreamingSignedParams returns the Expires, Signature and Key-Pair-Id query
+// parameters granting access to a stream of an RTMP distribution until
+// expires. For RTMP distributions the signed resource is the stream name
+// (e.g. "videos/mp4/movie.mp4") rather than an URL under BaseURL; the player
+// appends the parameters to the stream name itself.
+func (cf *CloudFront) StreamingSignedParams(streamName string, expires time.Time) (url.Values, error) {
+	_, signature, err := cf.Sign(NewCannedPolicy(strings.TrimPrefix(streamName, "/"), expires))
+	if err != nil {
+		return nil, err
 	}
 
-	expireTime := expires.Truncate(time.Millisecond).Unix()
-	uri.Path = path
-	uri.RawQuery += fmt.Sprintf("Expires=%d&Signature=%s&Key-Pair-Id=%s", expireTime, signature, cf.keyPairId)
-	return uri.String(), nil
+	params := url.Values{}
+	params.Set("Expires", strconv.FormatInt(expires.Unix(), 10))
+	params.Set("Signature", signature)
+	params.Set("Key-Pair-Id", cf.keyPairId)
+	return params, nil
 }
@@ -1,14 +1,85 @@
 package cloudfront
 
 import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
+	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 )
 
+func testPrivateKey(t testing.TB) *rsa.PrivateKey {
+	rawKey, err := ioutil.ReadFile("testdata/key.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pemKey, _ := pem.Decode(rawKey)
+	privateKey, err := x509.ParsePKCS1PrivateKey(pemKey.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return privateKey
+}
+
+func testPublicKey(t *testing.T) *rsa.PublicKey {
+	rawKey, err := ioutil.ReadFile("testdata/key.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pemKey, _ := pem.Decode(rawKey)
+	publicKey, err := x509.ParsePKIXPublicKey(pemKey.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return publicKey.(*rsa.PublicKey)
+}
+
+// testClock returns a clock stopped an hour before the expiration time used
+// by most tests, so that signing with it is allowed.
+func testClock() *fakeClock {
+	return &fakeClock{time.Unix(1396015221, 0).Add(-time.Hour)}
+}
+
+func testCloudFront(t testing.TB, baseURL string) *CloudFront {
+	cf, err := New(baseURL, testPrivateKey(t), "test-key-pair-1231245")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf.Clock = testClock()
+	return cf
+}
+
+// verifySignature checks an URL-safe base64 signature of an URL-safe base64
+// policy against the test public key and returns the decoded policy.
+func verifySignature(t *testing.T, b64Policy, b64Signature string) string {
+	policy, err := base64.StdEncoding.DecodeString(base64Restorer.Replace(b64Policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(base64Restorer.Replace(b64Signature))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashed := sha1.Sum(policy)
+	if err := rsa.VerifyPKCS1v15(testPublicKey(t), crypto.SHA1, hashed[:], signature); err != nil {
+		t.Fatalf("Signature does not validate: %v", err)
+	}
+	return string(policy)
+}
+
 func TestSignedCannedURL(t *testing.T) {
 	rawKey, err := ioutil.ReadFile("testdata/key.pem")
 	if err != nil {
@@ -25,6 +96,7 @@ func TestSignedCannedURL(t *testing.T) {
 		key:       privateKey,
 		keyPairId: "test-key-pair-1231245",
 		BaseURL:   "https://cloudfront.com",
+		Clock:     testClock(),
 	}
 
 	expireTime, err := time.Parse(time.RFC3339, "2014-03-28T14:00:21Z")
@@ -50,3 +122,649 @@ func TestSignedCannedURL(t *testing.T) {
 		t.Fatal("Encoded signature is empty")
 	}
 }
+
+func TestSignedURLWithPolicy(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com")
+
+	opts := PolicyOptions{
+		Expires:    time.Unix(1396015221, 0),
+		ActiveFrom: time.Unix(1396011621, 0),
+		SourceIP:   "192.0.2.1",
+	}
+
+	uri, err := cf.SignedURLWithPolicy("/test", "test=value", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := parsed.Query()
+	if query.Get("Expires") != "" {
+		t.Fatal("Custom policy URL must not carry Expires")
+	}
+	if query.Get("test") != "value" {
+		t.Fatalf("Unexpected query string: %s", parsed.RawQuery)
+	}
+	if query.Get("Key-Pair-Id") != "test-key-pair-1231245" {
+		t.Fatalf("Unexpected Key-Pair-Id: %s", query.Get("Key-Pair-Id"))
+	}
+	if parsed.Path != "/test" {
+		t.Fatalf("Unexpected path: %s", parsed.Path)
+	}
+
+	policy := verifySignature(t, query.Get("Policy"), query.Get("Signature"))
+	expected := `{"Statement":[{"Resource":"https://cloudfront.com/test?test=value",` +
+		`"Condition":{"DateLessThan":{"AWS:EpochTime":1396015221},` +
+		`"DateGreaterThan":{"AWS:EpochTime":1396011621},` +
+		`"IpAddress":{"AWS:SourceIp":"192.0.2.1/32"}}}]}`
+	if policy != expected {
+		t.Fatalf("Unexpected policy:\n%s\nexpected:\n%s", policy, expected)
+	}
+}
+
+func TestCustomPolicyOmitsEmptyConditions(t *testing.T) {
+	expires := time.Unix(1396015221, 0)
+
+	custom, err := NewCustomPolicy("https://cloudfront.com/test", PolicyOptions{Expires: expires}).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"Statement":[{"Resource":"https://cloudfront.com/test",` +
+		`"Condition":{"DateLessThan":{"AWS:EpochTime":1396015221}}}]}`
+	if string(custom) != expected {
+		t.Fatalf("Unexpected policy:\n%s\nexpected:\n%s", custom, expected)
+	}
+
+	canned, err := NewCannedPolicy("https://cloudfront.com/test", expires).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(canned) != expected {
+		t.Fatalf("Unexpected canned policy:\n%s\nexpected:\n%s", canned, expected)
+	}
+}
+
+func TestCustomPolicyErrors(t *testing.T) {
+	expires := time.Unix(1396015221, 0)
+
+	tests := []PolicyOptions{
+		{},
+		{Expires: expires, ActiveFrom: expires.Add(time.Hour)},
+		{Expires: expires.Add(500 * time.Millisecond), ActiveFrom: expires},
+		{Expires: expires, SourceIP: "not-an-ip"},
+	}
+
+	for i, opts := range tests {
+		if _, err := NewCustomPolicy("https://cloudfront.com/test", opts).JSON(); err == nil {
+			t.Errorf("Test %d: expected an error for %+v", i, opts)
+		}
+	}
+
+	policy, err := NewCustomPolicy("https://cloudfront.com/test", PolicyOptions{Expires: expires, SourceIP: "192.0.2.0/24"}).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(policy), `"AWS:SourceIp":"192.0.2.0/24"`) {
+		t.Fatalf("Unexpected policy: %s", policy)
+	}
+}
+
+func TestCookieWithPolicy(t *testing.T) {
+	cf := testCloudFront(t, "https://dxxx.cloudfront.net")
+
+	opts := PolicyOptions{Expires: time.Unix(1396015221, 0)}
+
+	b64Policy, b64Signature, keyPairId, err := cf.CookieWithPolicy("https://dxxx.cloudfront.net/videos/1234/*", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyPairId != "test-key-pair-1231245" {
+		t.Fatalf("Unexpected Key-Pair-Id: %s", keyPairId)
+	}
+
+	policy := verifySignature(t, b64Policy, b64Signature)
+	expected := `{"Statement":[{"Resource":"https://dxxx.cloudfront.net/videos/1234/*",` +
+		`"Condition":{"DateLessThan":{"AWS:EpochTime":1396015221}}}]}`
+	if policy != expected {
+		t.Fatalf("Unexpected policy:\n%s\nexpected:\n%s", policy, expected)
+	}
+
+	b64Policy, b64Signature, _, err = cf.CookieWithPolicy("*", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy = verifySignature(t, b64Policy, b64Signature)
+	if !strings.Contains(policy, `"Resource":"*"`) {
+		t.Fatalf("Unexpected policy: %s", policy)
+	}
+}
+
+func TestCookieWithPolicyRejectsBarePaths(t *testing.T) {
+	cf := testCloudFront(t, "https://dxxx.cloudfront.net")
+
+	opts := PolicyOptions{Expires: time.Unix(1396015221, 0)}
+
+	for _, pattern := range []string{"/videos/1234/*", "videos/*", "https:///videos/*", "://dxxx.cloudfront.net/*"} {
+		if _, _, _, err := cf.CookieWithPolicy(pattern, opts); err == nil {
+			t.Errorf("Expected an error for resource pattern %q", pattern)
+		}
+	}
+}
+
+// fakeClock is a Clock stopped at a fixed time.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestCookies(t *testing.T) {
+	cf := testCloudFront(t, "https://dxxx.cloudfront.net")
+	cf.Clock = &fakeClock{time.Unix(1396015221, 0)}
+
+	expires := time.Unix(1396015221, 0).Add(time.Hour)
+	attrs := CookieAttributes{
+		Domain:   "example.com",
+		Path:     "/videos",
+		Secure:   true,
+		HttpOnly: true,
+	}
+
+	cookies, err := cf.Cookies("videos/1234.mp4", expires, attrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b64Policy, b64Signature, keyPairId, err := cf.Cookie("videos/1234.mp4", expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct{ name, value string }{
+		{"CloudFront-Policy", b64Policy},
+		{"CloudFront-Signature", b64Signature},
+		{"CloudFront-Key-Pair-Id", keyPairId},
+	}
+
+	if len(cookies) != len(expected) {
+		t.Fatalf("Expected %d cookies, got %d", len(expected), len(cookies))
+	}
+
+	for i, cookie := range cookies {
+		if cookie.Name != expected[i].name {
+			t.Errorf("Cookie %d: expected name %s, got %s", i, expected[i].name, cookie.Name)
+		}
+		if cookie.Value != expected[i].value {
+			t.Errorf("Cookie %s: expected value %s, got %s", cookie.Name, expected[i].value, cookie.Value)
+		}
+		if cookie.Domain != "example.com" || cookie.Path != "/videos" || !cookie.Secure || !cookie.HttpOnly {
+			t.Errorf("Cookie %s: unexpected attributes %+v", cookie.Name, cookie)
+		}
+		if cookie.MaxAge != 3600 {
+			t.Errorf("Cookie %s: unexpected MaxAge %d", cookie.Name, cookie.MaxAge)
+		}
+	}
+}
+
+func TestSetCookies(t *testing.T) {
+	cf := testCloudFront(t, "https://dxxx.cloudfront.net")
+
+	w := httptest.NewRecorder()
+	err := cf.SetCookies(w, "videos/1234.mp4", time.Now().Add(time.Hour), CookieAttributes{Path: "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := http.Response{Header: w.Header()}
+	names := []string{}
+	for _, cookie := range resp.Cookies() {
+		names = append(names, cookie.Name)
+	}
+
+	if strings.Join(names, ",") != "CloudFront-Policy,CloudFront-Signature,CloudFront-Key-Pair-Id" {
+		t.Fatalf("Unexpected cookies set: %v", names)
+	}
+}
+
+func TestCannedSignedURLResource(t *testing.T) {
+	expires := time.Unix(1396015221, 0)
+
+	tests := []struct {
+		baseURL, path, query, expected string
+	}{
+		{"https://cloudfront.com", "test", "", "https://cloudfront.com/test"},
+		{"https://cloudfront.com/", "/test", "", "https://cloudfront.com/test"},
+		{"https://cloudfront.com", "test", "test=value", "https://cloudfront.com/test?test=value"},
+		{"https://cloudfront.com/", "/test", "test=value", "https://cloudfront.com/test?test=value"},
+		{"https://cloudfront.com/prefix/", "/dir/test/", "a=1&b=2", "https://cloudfront.com/prefix/dir/test/?a=1&b=2"},
+		{"https://cloudfront.com", "my dir/t\u00e9st.mp4", "", "https://cloudfront.com/my%20dir/t%C3%A9st.mp4"},
+	}
+
+	for _, test := range tests {
+		cf := testCloudFront(t, test.baseURL)
+
+		uri, err := cf.CannedSignedURL(test.path, test.query, expires)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(uri, test.expected) {
+			t.Errorf("Unexpected URL %s, expected it to start with %s", uri, test.expected)
+		}
+
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		query := parsed.Query()
+
+		policy, err := NewCannedPolicy(test.expected, expires).JSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b64Policy := base64Replacer.Replace(base64.StdEncoding.EncodeToString(policy))
+
+		decoded := verifySignature(t, b64Policy, query.Get("Signature"))
+		if !strings.Contains(decoded, `"Resource":"`+test.expected+`"`) {
+			t.Errorf("Policy resource does not match %s: %s", test.expected, decoded)
+		}
+
+		// The resource is the final URL up to the signature parameters.
+		resource := uri[:strings.Index(uri, "Expires=")-1]
+		if resource != test.expected {
+			t.Errorf("Signed URL resource %s does not match policy resource %s", resource, test.expected)
+		}
+	}
+}
+
+func TestResourcePathEncoding(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com/")
+	expires := time.Unix(1396015221, 0)
+
+	tests := []struct {
+		path, expected string
+	}{
+		{"videos/My Movie (2019)/index.m3u8", "https://cloudfront.com/videos/My%20Movie%20%282019%29/index.m3u8"},
+		{"a+b.jpg", "https://cloudfront.com/a+b.jpg"},
+		{"100%.jpg", "https://cloudfront.com/100%25.jpg"},
+		{"50%off", "https://cloudfront.com/50%25off"},
+		{"été/日本.txt", "https://cloudfront.com/%C3%A9t%C3%A9/%E6%97%A5%E6%9C%AC.txt"},
+		{"My%20Movie/index.m3u8", "https://cloudfront.com/My%20Movie/index.m3u8"},
+		{"%C3%A9t%C3%A9.txt", "https://cloudfront.com/%C3%A9t%C3%A9.txt"},
+		{"a%2Fb", "https://cloudfront.com/a%2Fb"},
+		{"half%20encoded file", "https://cloudfront.com/half%20encoded%20file"},
+		{"what?.txt", "https://cloudfront.com/what%3F.txt"},
+	}
+
+	for _, test := range tests {
+		uri, err := cf.CannedSignedURL(test.path, "", expires)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resource := uri[:strings.Index(uri, "?Expires=")]
+		if resource != test.expected {
+			t.Errorf("%q: expected URL resource %s, got %s", test.path, test.expected, resource)
+		}
+
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		policy, err := NewCannedPolicy(test.expected, expires).JSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b64Policy := base64Replacer.Replace(base64.StdEncoding.EncodeToString(policy))
+		verifySignature(t, b64Policy, parsed.Query().Get("Signature"))
+
+		b64Policy, b64Signature, _, err := cf.Cookie(test.path, expires)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := verifySignature(t, b64Policy, b64Signature)
+		if !strings.Contains(decoded, `"Resource":"`+test.expected+`"`) {
+			t.Errorf("%q: cookie policy resource does not match %s: %s", test.path, test.expected, decoded)
+		}
+	}
+}
+
+func TestSignedURLWithPolicyResource(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com/")
+
+	uri, err := cf.SignedURLWithPolicy("/my dir/test", "a=1", PolicyOptions{Expires: time.Unix(1396015221, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+
+	expected := "https://cloudfront.com/my%20dir/test?a=1"
+	policy := verifySignature(t, query.Get("Policy"), query.Get("Signature"))
+	if !strings.Contains(policy, `"Resource":"`+expected+`"`) {
+		t.Fatalf("Policy resource does not match %s: %s", expected, policy)
+	}
+	if resource := uri[:strings.Index(uri, "&Policy=")]; resource != expected {
+		t.Fatalf("Signed URL resource %s does not match policy resource %s", resource, expected)
+	}
+}
+
+func TestSignedURLReservedParams(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com")
+	expires := time.Unix(1396015221, 0)
+
+	for _, query := range []string{"Expires=1", "a=1&Signature=abc", "Key-Pair-Id=x", "Policy=abc"} {
+		if _, err := cf.CannedSignedURL("test", query, expires); err == nil {
+			t.Errorf("Expected an error for query string %q", query)
+		}
+		if _, err := cf.SignedURLWithPolicy("test", query, PolicyOptions{Expires: expires}); err == nil {
+			t.Errorf("Expected an error for query string %q", query)
+		}
+	}
+}
+
+func TestNewValidatesBaseURL(t *testing.T) {
+	for _, baseURL := range []string{"", "cloudfront.com", "/videos", "https://", "://cloudfront.com", "https://cloud front.com/%zz"} {
+		if _, err := New(baseURL, testPrivateKey(t), "test-key-pair-1231245"); err == nil {
+			t.Errorf("Expected an error for base URL %q", baseURL)
+		}
+	}
+}
+
+func TestSignedURLDoesNotMutateBaseURL(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com/prefix")
+	expires := time.Unix(1396015221, 0)
+
+	if _, err := cf.CannedSignedURL("first", "a=1", expires); err != nil {
+		t.Fatal(err)
+	}
+	uri, err := cf.CannedSignedURL("second", "", expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(uri, "https://cloudfront.com/prefix/second?Expires=") {
+		t.Fatalf("Unexpected URL: %s", uri)
+	}
+	if cf.baseURL.String() != "https://cloudfront.com/prefix" {
+		t.Fatalf("Base URL was modified: %s", cf.baseURL)
+	}
+}
+
+func BenchmarkCannedSignedURL(b *testing.B) {
+	cf := testCloudFront(b, "https://cloudfront.com")
+	expires := time.Now().Add(time.Hour)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cf.CannedSignedURL("videos/1234.mp4", "quality=hd", expires); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResourceURL(b *testing.B) {
+	cf := testCloudFront(b, "https://cloudfront.com")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cf.resourceURL("videos/1234.mp4", "quality=hd"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// recordingSigner signs with the test key and records what it was asked to
+// sign, standing in for a key held in an HSM or KMS.
+type recordingSigner struct {
+	key    *rsa.PrivateKey
+	rand   io.Reader
+	digest []byte
+	opts   crypto.SignerOpts
+}
+
+func (s *recordingSigner) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+func (s *recordingSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.rand = rand
+	s.digest = digest
+	s.opts = opts
+	return s.key.Sign(rand, digest, opts)
+}
+
+func TestNewWithSigner(t *testing.T) {
+	signer := &recordingSigner{key: testPrivateKey(t)}
+	cf, err := NewWithSigner("https://cloudfront.com", signer, "test-key-pair-1231245")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf.Clock = testClock()
+
+	b64Policy, b64Signature, _, err := cf.Cookie("test", time.Unix(1396015221, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := verifySignature(t, b64Policy, b64Signature)
+	expected := sha1.Sum([]byte(policy))
+	if string(signer.digest) != string(expected[:]) {
+		t.Fatalf("Signer was asked to sign %x, expected the SHA-1 of the policy %x", signer.digest, expected)
+	}
+	if signer.opts.HashFunc() != crypto.SHA1 {
+		t.Fatalf("Unexpected hash function: %v", signer.opts.HashFunc())
+	}
+}
+
+func TestSignedURLFor(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com")
+	clock := &fakeClock{time.Unix(1396015221, 500000000)}
+	cf.Clock = clock
+
+	uri, err := cf.SignedURLFor("test", 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := cf.CannedSignedURL("test", "", time.Unix(1396015821, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri != expected {
+		t.Fatalf("Expected %s, got %s", expected, uri)
+	}
+	if !strings.Contains(uri, "?Expires=1396015821&") {
+		t.Fatalf("Unexpected Expires in %s", uri)
+	}
+
+	clock.now = clock.now.Add(time.Hour)
+	uri, err = cf.SignedURLFor("test", 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(uri, "?Expires=1396019421&") {
+		t.Fatalf("Unexpected Expires in %s", uri)
+	}
+}
+
+func TestInvalidExpiry(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com")
+	cf.Clock = &fakeClock{time.Unix(1396015221, 500000000)}
+
+	for _, expires := range []time.Time{
+		{},
+		time.Unix(1396015220, 0),
+		time.Unix(1396015221, 0),
+		time.Unix(1396015221, 999999999),
+	} {
+		if _, err := cf.CannedSignedURL("test", "", expires); err != ErrInvalidExpiry {
+			t.Errorf("CannedSignedURL(%v): expected ErrInvalidExpiry, got %v", expires, err)
+		}
+		if _, _, _, err := cf.Cookie("test", expires); err != ErrInvalidExpiry {
+			t.Errorf("Cookie(%v): expected ErrInvalidExpiry, got %v", expires, err)
+		}
+		if _, err := cf.SignedURLWithPolicy("test", "", PolicyOptions{Expires: expires}); err != ErrInvalidExpiry {
+			t.Errorf("SignedURLWithPolicy(%v): expected ErrInvalidExpiry, got %v", expires, err)
+		}
+		if _, err := cf.StreamingSignedParams("test", expires); err != ErrInvalidExpiry {
+			t.Errorf("StreamingSignedParams(%v): expected ErrInvalidExpiry, got %v", expires, err)
+		}
+	}
+
+	if _, err := cf.CannedSignedURL("test", "", time.Unix(1396015222, 0)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExpiresTruncatedToSeconds(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com")
+
+	for _, nsec := range []int64{0, 1, 499999999, 500000000, 999999999} {
+		expires := time.Unix(1396015221, nsec)
+
+		uri, err := cf.CannedSignedURL("test", "", expires)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(uri, "?Expires=1396015221&") {
+			t.Errorf("%v: unexpected Expires in %s", expires, uri)
+		}
+
+		b64Policy, b64Signature, _, err := cf.CookieWithPolicy("*", PolicyOptions{
+			Expires:    expires,
+			ActiveFrom: time.Unix(1396015000, nsec),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		policy := verifySignature(t, b64Policy, b64Signature)
+		if !strings.Contains(policy, `"DateLessThan":{"AWS:EpochTime":1396015221}`) ||
+			!strings.Contains(policy, `"DateGreaterThan":{"AWS:EpochTime":1396015000}`) {
+			t.Errorf("%v: unexpected policy %s", expires, policy)
+		}
+	}
+
+	// Times in other locations are the same instants.
+	uri, err := cf.CannedSignedURL("test", "", time.Unix(1396015221, 0).In(time.FixedZone("UTC+5", 5*3600)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(uri, "?Expires=1396015221&") {
+		t.Errorf("Unexpected Expires in %s", uri)
+	}
+}
+
+func TestRandSource(t *testing.T) {
+	signer := &recordingSigner{key: testPrivateKey(t)}
+	cf, err := NewWithSigner("https://cloudfront.com", signer, "test-key-pair-1231245")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf.Clock = testClock()
+
+	source := strings.NewReader("not random at all")
+	cf.Rand = source
+
+	first, err := cf.CannedSignedURL("test", "", time.Unix(1396015221, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.rand != source {
+		t.Fatalf("Signer was not given the configured random source")
+	}
+
+	second, err := cf.CannedSignedURL("test", "", time.Unix(1396015221, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Fatalf("Signing is not reproducible: %s != %s", first, second)
+	}
+}
+
+func TestSignWith(t *testing.T) {
+	newKey, err := LoadKeyFromPEM(readTestData(t, "key-2.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cf := testCloudFront(t, "https://cloudfront.com")
+	cf.AddKeyPair("new-key-pair", newKey)
+
+	signer, err := cf.SignWith("new-key-pair")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, keyPairId, err := signer.Cookie("test", time.Unix(1396015221, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyPairId != "new-key-pair" {
+		t.Fatalf("Expected to sign with new-key-pair, got %s", keyPairId)
+	}
+
+	// The default key pair of cf is unchanged.
+	b64Policy, b64Signature, keyPairId, err := cf.Cookie("test", time.Unix(1396015221, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyPairId != "test-key-pair-1231245" {
+		t.Fatalf("Expected to sign with test-key-pair-1231245, got %s", keyPairId)
+	}
+	verifySignature(t, b64Policy, b64Signature)
+
+	if _, err := cf.SignWith("unknown"); err == nil {
+		t.Fatal("Expected an error for an unknown key pair")
+	}
+	if err := cf.SetDefaultKeyPair("unknown"); err == nil {
+		t.Fatal("Expected an error for an unknown key pair")
+	}
+}
+
+func TestStreamingSignedParams(t *testing.T) {
+	cf := testCloudFront(t, "rtmp://s5c39gqb8ow64r.cloudfront.net/cfx/st")
+
+	params, err := cf.StreamingSignedParams("videos/mp4/movie.mp4", time.Unix(1396015221, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if params.Get("Expires") != "1396015221" || params.Get("Key-Pair-Id") != "test-key-pair-1231245" {
+		t.Fatalf("Unexpected params: %v", params)
+	}
+
+	policy, err := NewCannedPolicy("videos/mp4/movie.mp4", time.Unix(1396015221, 0)).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64Policy := base64Replacer.Replace(base64.StdEncoding.EncodeToString(policy))
+
+	decoded := verifySignature(t, b64Policy, params.Get("Signature"))
+	expected := `{"Statement":[{"Resource":"videos/mp4/movie.mp4","Condition":{"DateLessThan":{"AWS:EpochTime":1396015221}}}]}`
+	if decoded != expected {
+		t.Fatalf("Unexpected policy:\n%s\nexpected:\n%s", decoded, expected)
+	}
+	if strings.Contains(decoded, "://") {
+		t.Fatalf("Streaming policy resource must not have a scheme: %s", decoded)
+	}
+
+	// A leading slash is not part of the stream name.
+	other, err := cf.StreamingSignedParams("/videos/mp4/movie.mp4", time.Unix(1396015221, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifySignature(t, b64Policy, other.Get("Signature"))
+}
@@ -0,0 +1,590 @@
+package cloudfront
+
+import (
+	"encoding/xml"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Aliases are the CNAMEs of a distribution.
+type Aliases struct {
+	Quantity int
+	Items    []string `xml:"Items>CNAME"`
+}
+
+// S3OriginConfig configures an Amazon S3 bucket origin. OriginAccessIdentity
+// is either empty or "origin-access-identity/cloudfront/<id>".
+type S3OriginConfig struct {
+	OriginAccessIdentity string
+}
+
+// CustomOriginConfig configures an origin that is not an S3 bucket.
+type CustomOriginConfig struct {
+	HTTPPort             int
+	HTTPSPort            int
+	OriginProtocolPolicy string
+}
+
+// Origin is a location CloudFront gets objects from. Exactly one of
+// S3OriginConfig and CustomOriginConfig must be set.
+type Origin struct {
+	Id                 string
+	DomainName         string
+	OriginPath         string
+	S3OriginConfig     *S3OriginConfig     `xml:",omitempty"`
+	CustomOriginConfig *CustomOriginConfig `xml:",omitempty"`
+}
+
+// Origins are the origins of a distribution.
+type Origins struct {
+	Quantity int
+	Items    []Origin `xml:"Items>Origin"`
+}
+
+// Names is a list of header or cookie names.
+type Names struct {
+	Quantity int
+	Items    []string `xml:"Items>Name"`
+}
+
+// CookiePreference tells which cookies are forwarded to the origin. Forward
+// is one of "none", "all" or "whitelist".
+type CookiePreference struct {
+	Forward          string
+	WhitelistedNames *Names `xml:",omitempty"`
+}
+
+// ForwardedValues tells what, besides the path, is forwarded to the origin.
+type ForwardedValues struct {
+	QueryString bool
+	Cookies     CookiePreference
+	Headers     Names
+}
+
+// TrustedSigners are the AWS accounts allowed to create signed URLs for the
+// content of a cache behavior or a streaming distribution. "self" designates
+// the distribution owner.
+type TrustedSigners struct {
+	Enabled  bool
+	Quantity int
+	Items    []string `xml:"Items>AwsAccountNumber"`
+}
+
+// Methods is a list of HTTP methods.
+type Methods struct {
+	Quantity int
+	Items    []string `xml:"Items>Method"`
+}
+
+// AllowedMethods are the HTTP methods CloudFront processes and forwards to
+// the origin, and the subset of them it caches responses for.
+type AllowedMethods struct {
+	Quantity      int
+	Items         []string `xml:"Items>Method"`
+	CachedMethods *Methods `xml:",omitempty"`
+}
+
+// CacheBehavior configures how CloudFront serves requests matching
+// PathPattern. The default cache behavior of a distribution has no
+// PathPattern.
+type CacheBehavior struct {
+	PathPattern          string `xml:",omitempty"`
+	TargetOriginId       string
+	ForwardedValues      ForwardedValues
+	TrustedSigners       TrustedSigners
+	ViewerProtocolPolicy string
+	MinTTL               int64
+	AllowedMethods       *AllowedMethods `xml:",omitempty"`
+	SmoothStreaming      bool
+}
+
+// CacheBehaviors are the cache behaviors of a distribution, in order of
+// precedence.
+type CacheBehaviors struct {
+	Quantity int
+	Items    []CacheBehavior `xml:"Items>CacheBehavior"`
+}
+
+// CustomErrorResponse replaces the response CloudFront returns for an HTTP
+// error code. Errors are cached for ErrorCachingMinTTL seconds, and not at
+// all if it points to 0. CloudFront caches them for 300 seconds when it is
+// nil.
+type CustomErrorResponse struct {
+	ErrorCode          int
+	ResponsePagePath   string `xml:",omitempty"`
+	ResponseCode       string `xml:",omitempty"`
+	ErrorCachingMinTTL *int64 `xml:",omitempty"`
+}
+
+// CustomErrorResponses are the custom error responses of a distribution.
+type CustomErrorResponses struct {
+	Quantity int
+	Items    []CustomErrorResponse `xml:"Items>CustomErrorResponse"`
+}
+
+// Logging configures access logs of a distribution.
+type Logging struct {
+	Enabled        bool
+	IncludeCookies bool
+	Bucket         string
+	Prefix         string
+}
+
+// ViewerCertificate configures the certificate used for HTTPS requests.
+type ViewerCertificate struct {
+	IAMCertificateId             string `xml:",omitempty"`
+	CloudFrontDefaultCertificate bool   `xml:",omitempty"`
+	SSLSupportMethod             string `xml:",omitempty"`
+	MinimumProtocolVersion       string `xml:",omitempty"`
+}
+
+// GeoRestriction restricts the countries content is served to.
+// RestrictionType is one of "none", "whitelist" or "blacklist", and Items
+// are ISO 3166-1-alpha-2 country codes.
+type GeoRestriction struct {
+	RestrictionType string
+	Quantity        int
+	Items           []string `xml:"Items>Location"`
+}
+
+// Restrictions are the restrictions on who content is served to.
+type Restrictions struct {
+	GeoRestriction GeoRestriction
+}
+
+// DistributionConfig is the configuration of a distribution.
+type DistributionConfig struct {
+	XMLName              xml.Name
+	CallerReference      string
+	Aliases              Aliases
+	DefaultRootObject    string
+	Origins              Origins
+	DefaultCacheBehavior CacheBehavior
+	CacheBehaviors       CacheBehaviors
+	CustomErrorResponses *CustomErrorResponses `xml:",omitempty"`
+	Comment              string
+	Logging              Logging
+	PriceClass           string `xml:",omitempty"`
+	Enabled              bool
+	ViewerCertificate    *ViewerCertificate `xml:",omitempty"`
+	Restrictions         *Restrictions      `xml:",omitempty"`
+}
+
+// KeyPairIds are the ids of the active key pairs of a trusted signer.
+type KeyPairIds struct {
+	Quantity int
+	Items    []string `xml:"Items>KeyPairId"`
+}
+
+// ActiveSigner is a trusted signer with active key pairs.
+type ActiveSigner struct {
+	AwsAccountNumber string
+	KeyPairIds       KeyPairIds
+}
+
+// ActiveTrustedSigners are the trusted signers of a distribution with
+// active key pairs.
+type ActiveTrustedSigners struct {
+	Enabled  bool
+	Quantity int
+	Items    []ActiveSigner `xml:"Items>Signer"`
+}
+
+// Distribution is a distribution with its configuration.
+type Distribution struct {
+	Id                            string
+	Status                        string
+	LastModifiedTime              string
+	InProgressInvalidationBatches int
+	DomainName                    string
+	ActiveTrustedSigners          ActiveTrustedSigners
+	DistributionConfig            DistributionConfig
+}
+
+// Distribution statuses.
+const (
+	DistributionInProgress = "InProgress"
+	DistributionDeployed   = "Deployed"
+)
+
+// Values of OriginProtocolPolicy, telling how CloudFront connects to custom
+// origins.
+const (
+	OriginProtocolHTTPOnly    = "http-only"
+	OriginProtocolMatchViewer = "match-viewer"
+)
+
+// Values of ViewerProtocolPolicy, telling which protocols viewers may use.
+const (
+	ViewerProtocolAllowAll        = "allow-all"
+	ViewerProtocolHTTPSOnly       = "https-only"
+	ViewerProtocolRedirectToHTTPS = "redirect-to-https"
+)
+
+// Price classes, restricting the edge locations serving a distribution.
+const (
+	PriceClassAll = "PriceClass_All"
+	PriceClass200 = "PriceClass_200"
+	PriceClass100 = "PriceClass_100"
+)
+
+// DefaultDeployTimeout is how long WaitUntilDeployed waits for a
+// distribution to be deployed.
+const DefaultDeployTimeout = 30 * time.Minute
+
+// NewS3Origin returns an origin getting objects from the S3 bucket whose
+// domain name is bucketDomainName (e.g. "mybucket.s3.amazonaws.com").
+// originAccessIdentity is either empty or
+// "origin-access-identity/cloudfront/<id>".
+func NewS3Origin(id, bucketDomainName, originAccessIdentity string) Origin {
+	return Origin{
+		Id:         id,
+		DomainName: bucketDomainName,
+		S3OriginConfig: &S3OriginConfig{
+			OriginAccessIdentity: originAccessIdentity,
+		},
+	}
+}
+
+// NewCustomOrigin returns an origin getting objects from the web server
+// domainName on the standard HTTP and HTTPS ports. protocolPolicy is one of
+// OriginProtocolHTTPOnly and OriginProtocolMatchViewer.
+func NewCustomOrigin(id, domainName, protocolPolicy string) Origin {
+	return Origin{
+		Id:         id,
+		DomainName: domainName,
+		CustomOriginConfig: &CustomOriginConfig{
+			HTTPPort:             80,
+			HTTPSPort:            443,
+			OriginProtocolPolicy: protocolPolicy,
+		},
+	}
+}
+
+// NewNames returns the list of header or cookie names.
+func NewNames(names ...string) Names {
+	return Names{Quantity: len(names), Items: names}
+}
+
+// NewTrustedSigners returns enabled trusted signers for the given AWS
+// account numbers, or "self".
+func NewTrustedSigners(accounts ...string) TrustedSigners {
+	return TrustedSigners{Enabled: true, Quantity: len(accounts), Items: accounts}
+}
+
+// NewCacheBehavior returns a cache behavior for requests matching
+// pathPattern, which is empty for the default cache behavior, served from
+// the origin targetOriginId. Neither query strings, cookies nor headers are
+// forwarded to the origin, viewers may use HTTP or HTTPS, URLs need not be
+// signed and only GET and HEAD requests are allowed.
+func NewCacheBehavior(pathPattern, targetOriginId string) CacheBehavior {
+	return CacheBehavior{
+		PathPattern:    pathPattern,
+		TargetOriginId: targetOriginId,
+		ForwardedValues: ForwardedValues{
+			Cookies: CookiePreference{Forward: "none"},
+		},
+		ViewerProtocolPolicy: ViewerProtocolAllowAll,
+		AllowedMethods: &AllowedMethods{
+			Quantity: 2,
+			Items:    []string{"GET", "HEAD"},
+			CachedMethods: &Methods{
+				Quantity: 2,
+				Items:    []string{"GET", "HEAD"},
+			},
+		},
+	}
+}
+
+// NewDistributionConfig returns the configuration of an enabled distribution
+// serving all requests from origin with the NewCacheBehavior defaults, using
+// all edge locations and the CloudFront certificate for HTTPS.
+func NewDistributionConfig(callerReference string, origin Origin) *DistributionConfig {
+	return &DistributionConfig{
+		CallerReference:      callerReference,
+		Origins:              Origins{Quantity: 1, Items: []Origin{origin}},
+		DefaultCacheBehavior: NewCacheBehavior("", origin.Id),
+		PriceClass:           PriceClassAll,
+		Enabled:              true,
+		ViewerCertificate:    &ViewerCertificate{CloudFrontDefaultCertificate: true},
+		Restrictions: &Restrictions{
+			GeoRestriction: GeoRestriction{RestrictionType: "none"},
+		},
+	}
+}
+
+// AddAliases adds CNAMEs to the distribution.
+func (cfg *DistributionConfig) AddAliases(cnames ...string) {
+	cfg.Aliases.Items = append(cfg.Aliases.Items, cnames...)
+	cfg.Aliases.Quantity = len(cfg.Aliases.Items)
+}
+
+// AddOrigin adds an origin cache behaviors may target.
+func (cfg *DistributionConfig) AddOrigin(origin Origin) {
+	cfg.Origins.Items = append(cfg.Origins.Items, origin)
+	cfg.Origins.Quantity = len(cfg.Origins.Items)
+}
+
+// AddCacheBehavior adds a cache behavior, with a lower precedence than the
+// ones already added.
+func (cfg *DistributionConfig) AddCacheBehavior(behavior CacheBehavior) {
+	cfg.CacheBehaviors.Items = append(cfg.CacheBehaviors.Items, behavior)
+	cfg.CacheBehaviors.Quantity = len(cfg.CacheBehaviors.Items)
+}
+
+// request returns the copy of cfg sent to the API. The quantities of its
+// lists are set from their items, and the settings CloudFront requires but
+// which are left empty get the NewCacheBehavior defaults.
+func (cfg *DistributionConfig) request() *DistributionConfig {
+	req := *cfg
+	req.XMLName = xml.Name{Space: xmlns, Local: "DistributionConfig"}
+
+	req.Aliases.Quantity = len(req.Aliases.Items)
+	req.Origins.Quantity = len(req.Origins.Items)
+
+	req.DefaultCacheBehavior = req.DefaultCacheBehavior.request()
+	behaviors := make([]CacheBehavior, len(req.CacheBehaviors.Items))
+	for i, behavior := range req.CacheBehaviors.Items {
+		behaviors[i] = behavior.request()
+	}
+	req.CacheBehaviors = CacheBehaviors{Quantity: len(behaviors), Items: behaviors}
+
+	if req.CustomErrorResponses != nil {
+		responses := *req.CustomErrorResponses
+		responses.Quantity = len(responses.Items)
+		req.CustomErrorResponses = &responses
+	}
+	if req.Restrictions != nil {
+		restrictions := *req.Restrictions
+		if restrictions.GeoRestriction.RestrictionType == "" {
+			restrictions.GeoRestriction.RestrictionType = "none"
+		}
+		restrictions.GeoRestriction.Quantity = len(restrictions.GeoRestriction.Items)
+		req.Restrictions = &restrictions
+	}
+	return &req
+}
+
+func (b CacheBehavior) request() CacheBehavior {
+	if b.ForwardedValues.Cookies.Forward == "" {
+		b.ForwardedValues.Cookies.Forward = "none"
+	}
+	if names := b.ForwardedValues.Cookies.WhitelistedNames; names != nil {
+		whitelisted := NewNames(names.Items...)
+		b.ForwardedValues.Cookies.WhitelistedNames = &whitelisted
+	}
+	b.ForwardedValues.Headers.Quantity = len(b.ForwardedValues.Headers.Items)
+	b.TrustedSigners.Quantity = len(b.TrustedSigners.Items)
+	if b.ViewerProtocolPolicy == "" {
+		b.ViewerProtocolPolicy = ViewerProtocolAllowAll
+	}
+	if b.AllowedMethods != nil {
+		methods := *b.AllowedMethods
+		methods.Quantity = len(methods.Items)
+		if methods.CachedMethods != nil {
+			methods.CachedMethods = &Methods{
+				Quantity: len(methods.CachedMethods.Items),
+				Items:    methods.CachedMethods.Items,
+			}
+		}
+		b.AllowedMethods = &methods
+	}
+	return b
+}
+
+// DistributionSummary is an entry of a distribution listing.
+type DistributionSummary struct {
+	Id                   string
+	Status               string
+	LastModifiedTime     string
+	DomainName           string
+	Aliases              Aliases
+	Origins              Origins
+	DefaultCacheBehavior CacheBehavior
+	CacheBehaviors       CacheBehaviors
+	CustomErrorResponses CustomErrorResponses
+	Comment              string
+	PriceClass           string
+	Enabled              bool
+	ViewerCertificate    ViewerCertificate
+	Restrictions         Restrictions
+}
+
+type distributionList struct {
+	Marker      string
+	NextMarker  string
+	MaxItems    int
+	IsTruncated bool
+	Quantity    int
+	Items       []DistributionSummary `xml:"Items>DistributionSummary"`
+}
+
+// ListDistributions returns all the distributions of the account, following
+// the pagination of the listing.
+func (c *Client) ListDistributions() ([]DistributionSummary, error) {
+	var summaries []DistributionSummary
+
+	marker := ""
+	for {
+		path := "/distribution"
+		if marker != "" {
+			path += "?Marker=" + url.QueryEscape(marker)
+		}
+
+		resp := &distributionList{}
+		if _, err := c.query("GET", path, nil, nil, resp); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, resp.Items...)
+
+		if !resp.IsTruncated || resp.NextMarker == "" {
+			return summaries, nil
+		}
+		marker = resp.NextMarker
+	}
+}
+
+// CreateDistribution creates a distribution configured by cfg and returns it,
+// with its Id and DomainName, and its ETag. The distribution is InProgress
+// until its configuration is deployed to all edge locations, which
+// WaitUntilDeployed waits for.
+//
+// NewDistributionConfig returns a configuration with sane defaults to start
+// from. The quantities of the lists of cfg need not be set, they are
+// computed from their items.
+func (c *Client) CreateDistribution(cfg *DistributionConfig) (*Distribution, string, error) {
+	resp := &Distribution{}
+	header, err := c.query("POST", "/distribution", nil, cfg.request(), resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, header.Get("ETag"), nil
+}
+
+// GetDistribution returns the distribution id, and its ETag which must be
+// passed along when updating or deleting it.
+func (c *Client) GetDistribution(id string) (*Distribution, string, error) {
+	resp := &Distribution{}
+	header, err := c.query("GET", "/distribution/"+id, nil, nil, resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, header.Get("ETag"), nil
+}
+
+// FindDistributionByAlias returns the distribution serving cname, either
+// because it is one of its aliases or because it matches one of its wildcard
+// aliases (e.g. "*.example.com"). Exact aliases take precedence over
+// wildcard ones. It returns nil and no error when no distribution serves
+// cname.
+func (c *Client) FindDistributionByAlias(cname string) (*DistributionSummary, error) {
+	summaries, err := c.ListDistributions()
+	if err != nil {
+		return nil, err
+	}
+
+	cname = strings.ToLower(strings.TrimSuffix(cname, "."))
+
+	var wildcard *DistributionSummary
+	for i := range summaries {
+		for _, alias := range summaries[i].Aliases.Items {
+			alias = strings.ToLower(alias)
+			if alias == cname {
+				return &summaries[i], nil
+			}
+			if wildcard == nil && strings.HasPrefix(alias, "*.") && strings.HasSuffix(cname, alias[1:]) {
+				wildcard = &summaries[i]
+			}
+		}
+	}
+	return wildcard, nil
+}
+
+// UpdateDistribution replaces the configuration of the distribution id by
+// cfg. etag is the ETag of the distribution returned when it was last read;
+// a PreconditionFailedError is returned if the distribution has been modified
+// since. The updated distribution and its new ETag are returned.
+//
+// The configuration is replaced as a whole, so cfg should be the latest
+// configuration of the distribution with the desired changes applied.
+func (c *Client) UpdateDistribution(id string, cfg *DistributionConfig, etag string) (*Distribution, string, error) {
+	resp := &Distribution{}
+	headers := map[string]string{"If-Match": etag}
+	header, err := c.query("PUT", "/distribution/"+id+"/config", headers, cfg.request(), resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, header.Get("ETag"), nil
+}
+
+// DisableDistribution disables the distribution id, which must be done before
+// deleting it, and returns it with its new ETag. Nothing is changed if the
+// distribution is already disabled.
+func (c *Client) DisableDistribution(id string) (*Distribution, string, error) {
+	d, etag, err := c.GetDistribution(id)
+	if err != nil {
+		return nil, "", err
+	}
+	if !d.DistributionConfig.Enabled {
+		return d, etag, nil
+	}
+
+	cfg := d.DistributionConfig
+	cfg.Enabled = false
+	return c.UpdateDistribution(id, &cfg, etag)
+}
+
+// DeleteDistribution deletes the distribution id, which must be disabled and
+// deployed. etag is the ETag of the distribution returned when it was last
+// read or updated.
+func (c *Client) DeleteDistribution(id, etag string) error {
+	headers := map[string]string{"If-Match": etag}
+	_, err := c.query("DELETE", "/distribution/"+id, headers, nil, nil)
+	return err
+}
+
+// WaitUntilDistributionDeployed polls the distribution id until its status
+// is Deployed, and returns it with its ETag. It gives up after timeout.
+func (c *Client) WaitUntilDistributionDeployed(id string, timeout time.Duration) (*Distribution, string, error) {
+	var (
+		d    *Distribution
+		etag string
+	)
+	err := waitFor(timeout, func() (bool, error) {
+		var err error
+		d, etag, err = c.GetDistribution(id)
+		if err != nil {
+			return false, err
+		}
+		return d.Status == DistributionDeployed, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return d, etag, nil
+}
+
+// WaitUntilDeployed is WaitUntilDistributionDeployed giving up after
+// DefaultDeployTimeout.
+func (c *Client) WaitUntilDeployed(id string) (*Distribution, string, error) {
+	return c.WaitUntilDistributionDeployed(id, DefaultDeployTimeout)
+}
+
+// DisableAndDeleteDistribution deletes the distribution id, disabling it and
+// waiting for the change to be deployed first as CloudFront requires. It
+// gives up after timeout, which should allow for the deployment to complete
+// (usually up to 15 minutes).
+func (c *Client) DisableAndDeleteDistribution(id string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	if _, _, err := c.DisableDistribution(id); err != nil {
+		return err
+	}
+
+	_, etag, err := c.WaitUntilDistributionDeployed(id, deadline.Sub(time.Now()))
+	if err != nil {
+		return err
+	}
+	return c.DeleteDistribution(id, etag)
+}
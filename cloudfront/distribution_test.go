@@ -0,0 +1,458 @@
+package cloudfront
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListDistributions(t *testing.T) {
+	api, client := newTestAPI(t,
+		testResponse{Status: 200, Body: ListDistributionsResponsePage1},
+		testResponse{Status: 200, Body: ListDistributionsResponsePage2})
+	defer api.Close()
+
+	summaries, err := client.ListDistributions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(api.requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(api.requests))
+	}
+	if api.requests[0].Path != "/2014-11-06/distribution" || api.requests[0].Query != "" {
+		t.Fatalf("Unexpected first request: %s?%s", api.requests[0].Path, api.requests[0].Query)
+	}
+	if api.requests[1].Query != "Marker=EMLARXS9EXAMPLE" {
+		t.Fatalf("Unexpected second request query: %s", api.requests[1].Query)
+	}
+
+	if len(summaries) != 2 {
+		t.Fatalf("Expected 2 distributions, got %d", len(summaries))
+	}
+
+	d := summaries[0]
+	if d.Id != "EDFDVBD6EXAMPLE" || d.DomainName != "d111111abcdef8.cloudfront.net" || !d.Enabled || d.Status != DistributionDeployed {
+		t.Fatalf("Unexpected distribution: %+v", d)
+	}
+	if !reflect.DeepEqual(d.Aliases.Items, []string{"www.example.com", "*.example.org"}) {
+		t.Fatalf("Unexpected aliases: %v", d.Aliases.Items)
+	}
+	expectedOrigin := Origin{
+		Id:         "example-Amazon S3-origin",
+		DomainName: "myawsbucket.s3.amazonaws.com",
+		S3OriginConfig: &S3OriginConfig{
+			OriginAccessIdentity: "origin-access-identity/cloudfront/E74FTE3AEXAMPLE",
+		},
+	}
+	if !reflect.DeepEqual(d.Origins.Items, []Origin{expectedOrigin}) {
+		t.Fatalf("Unexpected origins: %+v", d.Origins.Items)
+	}
+	if !reflect.DeepEqual(d.DefaultCacheBehavior.AllowedMethods.CachedMethods.Items, []string{"GET", "HEAD"}) {
+		t.Fatalf("Unexpected cached methods: %+v", d.DefaultCacheBehavior.AllowedMethods)
+	}
+
+	d = summaries[1]
+	if d.Id != "EMLARXS9EXAMPLE" || d.Enabled || d.Status != DistributionInProgress {
+		t.Fatalf("Unexpected distribution: %+v", d)
+	}
+	custom := d.Origins.Items[0].CustomOriginConfig
+	if custom == nil || custom.HTTPSPort != 443 || custom.OriginProtocolPolicy != "match-viewer" {
+		t.Fatalf("Unexpected custom origin: %+v", d.Origins.Items[0])
+	}
+	if d.DefaultCacheBehavior.MinTTL != 3600 || d.DefaultCacheBehavior.ViewerProtocolPolicy != "redirect-to-https" {
+		t.Fatalf("Unexpected default cache behavior: %+v", d.DefaultCacheBehavior)
+	}
+}
+
+func TestGetDistribution(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{
+		Status:  200,
+		Headers: map[string]string{"ETag": "E2QWRUHAPOMQZL"},
+		Body:    GetDistributionResponse,
+	})
+	defer api.Close()
+
+	d, etag, err := client.GetDistribution("EDFDVBD6EXAMPLE")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if api.requests[0].Path != "/2014-11-06/distribution/EDFDVBD6EXAMPLE" {
+		t.Fatalf("Unexpected path: %s", api.requests[0].Path)
+	}
+	if etag != "E2QWRUHAPOMQZL" {
+		t.Fatalf("Unexpected ETag: %s", etag)
+	}
+
+	if d.Id != "EDFDVBD6EXAMPLE" || d.InProgressInvalidationBatches != 1 {
+		t.Fatalf("Unexpected distribution: %+v", d)
+	}
+	signers := d.ActiveTrustedSigners
+	if !signers.Enabled || signers.Items[0].AwsAccountNumber != "self" || signers.Items[0].KeyPairIds.Items[0] != "APKA9ONS7QCOWEXAMPLE" {
+		t.Fatalf("Unexpected active trusted signers: %+v", signers)
+	}
+
+	config := d.DistributionConfig
+	if config.CallerReference != "example1" || config.DefaultRootObject != "index.html" || !config.Enabled {
+		t.Fatalf("Unexpected config: %+v", config)
+	}
+	cookies := config.DefaultCacheBehavior.ForwardedValues.Cookies
+	if cookies.Forward != "whitelist" || !reflect.DeepEqual(cookies.WhitelistedNames.Items, []string{"session"}) {
+		t.Fatalf("Unexpected cookie preference: %+v", cookies)
+	}
+	if !reflect.DeepEqual(config.DefaultCacheBehavior.TrustedSigners.Items, []string{"self"}) {
+		t.Fatalf("Unexpected trusted signers: %+v", config.DefaultCacheBehavior.TrustedSigners)
+	}
+	if len(config.CacheBehaviors.Items) != 1 || config.CacheBehaviors.Items[0].PathPattern != "*.jpg" || config.CacheBehaviors.Items[0].MinTTL != 86400 {
+		t.Fatalf("Unexpected cache behaviors: %+v", config.CacheBehaviors)
+	}
+	ttl := int64(30)
+	expectedError := CustomErrorResponse{ErrorCode: 404, ResponsePagePath: "/errors/404.html", ResponseCode: "200", ErrorCachingMinTTL: &ttl}
+	if !reflect.DeepEqual(config.CustomErrorResponses.Items, []CustomErrorResponse{expectedError}) {
+		t.Fatalf("Unexpected custom error responses: %+v", config.CustomErrorResponses)
+	}
+	if !config.Logging.Enabled || config.Logging.Bucket != "myawslogbucket.s3.amazonaws.com" {
+		t.Fatalf("Unexpected logging: %+v", config.Logging)
+	}
+	if !reflect.DeepEqual(config.Restrictions.GeoRestriction.Items, []string{"US", "CA"}) {
+		t.Fatalf("Unexpected restrictions: %+v", config.Restrictions)
+	}
+}
+
+func TestFindDistributionByAlias(t *testing.T) {
+	tests := []struct {
+		cname, id string
+	}{
+		{"www.example.com", "EDFDVBD6EXAMPLE"},
+		{"CDN.example.com.", "EMLARXS9EXAMPLE"},
+		{"static.example.org", "EDFDVBD6EXAMPLE"},
+		{"example.org", ""},
+		{"unknown.example.net", ""},
+	}
+
+	for _, test := range tests {
+		api, client := newTestAPI(t,
+			testResponse{Status: 200, Body: ListDistributionsResponsePage1},
+			testResponse{Status: 200, Body: ListDistributionsResponsePage2})
+
+		d, err := client.FindDistributionByAlias(test.cname)
+		api.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id := ""
+		if d != nil {
+			id = d.Id
+		}
+		if id != test.id {
+			t.Errorf("%s: expected distribution %q, got %q", test.cname, test.id, id)
+		}
+	}
+}
+
+func TestUpdateDistribution(t *testing.T) {
+	api, client := newTestAPI(t,
+		testResponse{Status: 200, Headers: map[string]string{"ETag": "E2QWRUHAPOMQZL"}, Body: GetDistributionResponse},
+		testResponse{Status: 200, Headers: map[string]string{"ETag": "E3RXSVIBQPNRAM"}, Body: GetDistributionResponse})
+	defer api.Close()
+
+	d, etag, err := client.GetDistribution("EDFDVBD6EXAMPLE")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := d.DistributionConfig
+	cfg.Aliases.Items = append(cfg.Aliases.Items, "cdn.example.com")
+	cfg.Aliases.Quantity = len(cfg.Aliases.Items)
+	cfg.DefaultCacheBehavior.MinTTL = 60
+
+	_, etag, err = client.UpdateDistribution("EDFDVBD6EXAMPLE", &cfg, etag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if etag != "E3RXSVIBQPNRAM" {
+		t.Fatalf("Unexpected ETag: %s", etag)
+	}
+
+	req := api.requests[1]
+	if req.Method != "PUT" || req.Path != "/2014-11-06/distribution/EDFDVBD6EXAMPLE/config" {
+		t.Fatalf("Unexpected request: %s %s", req.Method, req.Path)
+	}
+	if req.Header.Get("If-Match") != "E2QWRUHAPOMQZL" {
+		t.Fatalf("Unexpected If-Match header: %s", req.Header.Get("If-Match"))
+	}
+	if !strings.Contains(req.Body, `<DistributionConfig xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">`) {
+		t.Fatalf("Missing namespace in body: %s", req.Body)
+	}
+
+	// The whole configuration is sent back.
+	sent := DistributionConfig{}
+	if err := xml.Unmarshal([]byte(req.Body), &sent); err != nil {
+		t.Fatal(err)
+	}
+	sent.XMLName = cfg.XMLName
+	if !reflect.DeepEqual(sent, cfg) {
+		t.Fatalf("Sent configuration does not match:\n%+v\nexpected:\n%+v", sent, cfg)
+	}
+}
+
+func TestUpdateDistributionPreconditionFailed(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 412, Body: PreconditionFailedResponse})
+	defer api.Close()
+
+	_, _, err := client.UpdateDistribution("EDFDVBD6EXAMPLE", &DistributionConfig{}, "E2QWRUHAPOMQZL")
+	perr, ok := err.(*PreconditionFailedError)
+	if !ok {
+		t.Fatalf("Expected a *PreconditionFailedError, got %#v", err)
+	}
+	if perr.StatusCode != 412 || perr.Code != "PreconditionFailed" {
+		t.Fatalf("Unexpected error: %#v", perr)
+	}
+}
+
+func TestDisableDistribution(t *testing.T) {
+	disabled := strings.Replace(GetDistributionResponse, "<Enabled>true</Enabled>\n      <ViewerCertificate>", "<Enabled>false</Enabled>\n      <ViewerCertificate>", 1)
+
+	api, client := newTestAPI(t,
+		testResponse{Status: 200, Headers: map[string]string{"ETag": "E2QWRUHAPOMQZL"}, Body: GetDistributionResponse},
+		testResponse{Status: 200, Headers: map[string]string{"ETag": "E3RXSVIBQPNRAM"}, Body: disabled})
+	defer api.Close()
+
+	d, etag, err := client.DisableDistribution("EDFDVBD6EXAMPLE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.DistributionConfig.Enabled || etag != "E3RXSVIBQPNRAM" {
+		t.Fatalf("Unexpected result: enabled=%v etag=%s", d.DistributionConfig.Enabled, etag)
+	}
+	if !strings.Contains(api.requests[1].Body, "<Logging><Enabled>true</Enabled>") || !strings.Contains(api.requests[1].Body, "</Logging><PriceClass>PriceClass_All</PriceClass><Enabled>false</Enabled>") {
+		t.Fatalf("Unexpected body: %s", api.requests[1].Body)
+	}
+
+	// Already disabled: no update.
+	api2, client2 := newTestAPI(t, testResponse{Status: 200, Headers: map[string]string{"ETag": "E3RXSVIBQPNRAM"}, Body: disabled})
+	defer api2.Close()
+	if _, _, err := client2.DisableDistribution("EDFDVBD6EXAMPLE"); err != nil {
+		t.Fatal(err)
+	}
+	if len(api2.requests) != 1 {
+		t.Fatalf("Expected a single request, got %d", len(api2.requests))
+	}
+}
+
+func TestDisableAndDeleteDistribution(t *testing.T) {
+	defer setFastPolling()()
+
+	disabled := strings.Replace(GetDistributionResponse, "<Enabled>true</Enabled>\n      <ViewerCertificate>", "<Enabled>false</Enabled>\n      <ViewerCertificate>", 1)
+	inProgress := strings.Replace(disabled, "<Status>Deployed</Status>", "<Status>InProgress</Status>", 1)
+
+	api, client := newTestAPI(t,
+		testResponse{Status: 200, Headers: map[string]string{"ETag": "E1"}, Body: GetDistributionResponse},
+		testResponse{Status: 200, Headers: map[string]string{"ETag": "E2"}, Body: inProgress},
+		testResponse{Status: 200, Headers: map[string]string{"ETag": "E2"}, Body: inProgress},
+		testResponse{Status: 200, Headers: map[string]string{"ETag": "E2"}, Body: disabled},
+		testResponse{Status: 204})
+	defer api.Close()
+
+	if err := client.DisableAndDeleteDistribution("EDFDVBD6EXAMPLE", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	methods := []string{}
+	for _, req := range api.requests {
+		methods = append(methods, req.Method)
+	}
+	if strings.Join(methods, " ") != "GET PUT GET GET DELETE" {
+		t.Fatalf("Unexpected requests: %v", methods)
+	}
+
+	del := api.requests[4]
+	if del.Path != "/2014-11-06/distribution/EDFDVBD6EXAMPLE" || del.Header.Get("If-Match") != "E2" {
+		t.Fatalf("Unexpected delete request: %s If-Match=%s", del.Path, del.Header.Get("If-Match"))
+	}
+}
+
+func TestCreateDistribution(t *testing.T) {
+	inProgress := strings.Replace(GetDistributionResponse, "<Status>Deployed</Status>", "<Status>InProgress</Status>", 1)
+	api, client := newTestAPI(t, testResponse{
+		Status:  201,
+		Headers: map[string]string{"ETag": "E2QWRUHAPOMQZL"},
+		Body:    inProgress,
+	})
+	defer api.Close()
+
+	cfg := NewDistributionConfig("site-20141119", NewS3Origin("assets", "myawsbucket.s3.amazonaws.com", ""))
+	cfg.AddAliases("www.example.com")
+	cfg.AddOrigin(NewCustomOrigin("api", "api.example.com", OriginProtocolMatchViewer))
+
+	apiBehavior := NewCacheBehavior("/api/*", "api")
+	apiBehavior.ForwardedValues.QueryString = true
+	apiBehavior.ForwardedValues.Headers = NewNames("Authorization")
+	apiBehavior.ViewerProtocolPolicy = ViewerProtocolHTTPSOnly
+	cfg.AddCacheBehavior(apiBehavior)
+
+	cfg.Comment = "Example site"
+	cfg.Logging = Logging{Enabled: true, Bucket: "logs.s3.amazonaws.com", Prefix: "site/"}
+	cfg.PriceClass = PriceClass100
+
+	d, etag, err := client.CreateDistribution(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Id != "EDFDVBD6EXAMPLE" || d.DomainName != "d111111abcdef8.cloudfront.net" || d.Status != DistributionInProgress {
+		t.Fatalf("Unexpected distribution: %+v", d)
+	}
+	if etag != "E2QWRUHAPOMQZL" {
+		t.Fatalf("Unexpected ETag: %s", etag)
+	}
+
+	req := api.requests[0]
+	if req.Method != "POST" || req.Path != "/2014-11-06/distribution" {
+		t.Fatalf("Unexpected request: %s %s", req.Method, req.Path)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<DistributionConfig xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">` +
+		`<CallerReference>site-20141119</CallerReference>` +
+		`<Aliases><Quantity>1</Quantity><Items><CNAME>www.example.com</CNAME></Items></Aliases>` +
+		`<DefaultRootObject></DefaultRootObject>` +
+		`<Origins><Quantity>2</Quantity><Items>` +
+		`<Origin><Id>assets</Id><DomainName>myawsbucket.s3.amazonaws.com</DomainName><OriginPath></OriginPath>` +
+		`<S3OriginConfig><OriginAccessIdentity></OriginAccessIdentity></S3OriginConfig></Origin>` +
+		`<Origin><Id>api</Id><DomainName>api.example.com</DomainName><OriginPath></OriginPath>` +
+		`<CustomOriginConfig><HTTPPort>80</HTTPPort><HTTPSPort>443</HTTPSPort><OriginProtocolPolicy>match-viewer</OriginProtocolPolicy></CustomOriginConfig></Origin>` +
+		`</Items></Origins>` +
+		`<DefaultCacheBehavior><TargetOriginId>assets</TargetOriginId>` +
+		`<ForwardedValues><QueryString>false</QueryString><Cookies><Forward>none</Forward></Cookies><Headers><Quantity>0</Quantity><Items></Items></Headers></ForwardedValues>` +
+		`<TrustedSigners><Enabled>false</Enabled><Quantity>0</Quantity><Items></Items></TrustedSigners>` +
+		`<ViewerProtocolPolicy>allow-all</ViewerProtocolPolicy><MinTTL>0</MinTTL>` +
+		`<AllowedMethods><Quantity>2</Quantity><Items><Method>GET</Method><Method>HEAD</Method></Items>` +
+		`<CachedMethods><Quantity>2</Quantity><Items><Method>GET</Method><Method>HEAD</Method></Items></CachedMethods></AllowedMethods>` +
+		`<SmoothStreaming>false</SmoothStreaming></DefaultCacheBehavior>` +
+		`<CacheBehaviors><Quantity>1</Quantity><Items><CacheBehavior><PathPattern>/api/*</PathPattern><TargetOriginId>api</TargetOriginId>` +
+		`<ForwardedValues><QueryString>true</QueryString><Cookies><Forward>none</Forward></Cookies><Headers><Quantity>1</Quantity><Items><Name>Authorization</Name></Items></Headers></ForwardedValues>` +
+		`<TrustedSigners><Enabled>false</Enabled><Quantity>0</Quantity><Items></Items></TrustedSigners>` +
+		`<ViewerProtocolPolicy>https-only</ViewerProtocolPolicy><MinTTL>0</MinTTL>` +
+		`<AllowedMethods><Quantity>2</Quantity><Items><Method>GET</Method><Method>HEAD</Method></Items>` +
+		`<CachedMethods><Quantity>2</Quantity><Items><Method>GET</Method><Method>HEAD</Method></Items></CachedMethods></AllowedMethods>` +
+		`<SmoothStreaming>false</SmoothStreaming></CacheBehavior></Items></CacheBehaviors>` +
+		`<Comment>Example site</Comment>` +
+		`<Logging><Enabled>true</Enabled><IncludeCookies>false</IncludeCookies><Bucket>logs.s3.amazonaws.com</Bucket><Prefix>site/</Prefix></Logging>` +
+		`<PriceClass>PriceClass_100</PriceClass><Enabled>true</Enabled>` +
+		`<ViewerCertificate><CloudFrontDefaultCertificate>true</CloudFrontDefaultCertificate></ViewerCertificate>` +
+		`<Restrictions><GeoRestriction><RestrictionType>none</RestrictionType><Quantity>0</Quantity><Items></Items></GeoRestriction></Restrictions>` +
+		`</DistributionConfig>`
+	if req.Body != expected {
+		t.Fatalf("Unexpected body:\n%s\nexpected:\n%s", req.Body, expected)
+	}
+}
+
+func TestCreateDistributionDefaults(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 201, Body: GetDistributionResponse})
+	defer api.Close()
+
+	cfg := &DistributionConfig{
+		CallerReference: "minimal",
+		Aliases:         Aliases{Items: []string{"a.example.com", "b.example.com"}},
+		Origins:         Origins{Items: []Origin{NewS3Origin("assets", "myawsbucket.s3.amazonaws.com", "")}},
+		DefaultCacheBehavior: CacheBehavior{
+			TargetOriginId: "assets",
+			TrustedSigners: NewTrustedSigners("self"),
+			AllowedMethods: &AllowedMethods{Items: []string{"GET", "HEAD", "OPTIONS"}},
+		},
+		Restrictions: &Restrictions{GeoRestriction: GeoRestriction{RestrictionType: "whitelist", Items: []string{"US", "CA"}}},
+	}
+	if _, _, err := client.CreateDistribution(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := DistributionConfig{}
+	if err := xml.Unmarshal([]byte(api.requests[0].Body), &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent.Aliases.Quantity != 2 || sent.Origins.Quantity != 1 || sent.DefaultCacheBehavior.AllowedMethods.Quantity != 3 {
+		t.Fatalf("Quantities not set: %+v", sent)
+	}
+	if sent.Restrictions.GeoRestriction.Quantity != 2 {
+		t.Fatalf("Unexpected restrictions: %+v", sent.Restrictions)
+	}
+	b := sent.DefaultCacheBehavior
+	if b.ForwardedValues.Cookies.Forward != "none" || b.ViewerProtocolPolicy != ViewerProtocolAllowAll {
+		t.Fatalf("Defaults not set: %+v", b)
+	}
+	if !b.TrustedSigners.Enabled || b.TrustedSigners.Quantity != 1 {
+		t.Fatalf("Unexpected trusted signers: %+v", b.TrustedSigners)
+	}
+
+	// The caller's configuration is left untouched.
+	if cfg.Aliases.Quantity != 0 || cfg.DefaultCacheBehavior.AllowedMethods.Quantity != 0 || cfg.DefaultCacheBehavior.ViewerProtocolPolicy != "" {
+		t.Fatalf("Configuration was modified: %+v", cfg)
+	}
+}
+
+func TestCreateDistributionErrorCachingDisabled(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 201, Body: GetDistributionResponse})
+	defer api.Close()
+
+	cfg := NewDistributionConfig("no-error-caching", NewS3Origin("assets", "myawsbucket.s3.amazonaws.com", ""))
+	disabled := int64(0)
+	cfg.CustomErrorResponses = &CustomErrorResponses{Items: []CustomErrorResponse{{ErrorCode: 503, ErrorCachingMinTTL: &disabled}}}
+	if _, _, err := client.CreateDistribution(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	body := api.requests[0].Body
+	expected := `<CustomErrorResponses><Quantity>1</Quantity><Items><CustomErrorResponse>` +
+		`<ErrorCode>503</ErrorCode><ErrorCachingMinTTL>0</ErrorCachingMinTTL>` +
+		`</CustomErrorResponse></Items></CustomErrorResponses>`
+	if !strings.Contains(body, expected) {
+		t.Fatalf("Unexpected body:\n%s\nexpected it to contain:\n%s", body, expected)
+	}
+	sent := DistributionConfig{}
+	if err := xml.Unmarshal([]byte(body), &sent); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sent.CustomErrorResponses.Items, cfg.CustomErrorResponses.Items) {
+		t.Fatalf("Unexpected custom error responses: %+v", sent.CustomErrorResponses)
+	}
+}
+
+func TestCreateDistributionErrorCachingDefault(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 201, Body: GetDistributionResponse})
+	defer api.Close()
+
+	cfg := NewDistributionConfig("default-error-caching", NewS3Origin("assets", "myawsbucket.s3.amazonaws.com", ""))
+	cfg.CustomErrorResponses = &CustomErrorResponses{Items: []CustomErrorResponse{{ErrorCode: 404, ResponsePagePath: "/404.html", ResponseCode: "404"}}}
+	if _, _, err := client.CreateDistribution(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// CloudFront applies its default TTL when none is sent.
+	body := api.requests[0].Body
+	expected := `<CustomErrorResponse><ErrorCode>404</ErrorCode><ResponsePagePath>/404.html</ResponsePagePath>` +
+		`<ResponseCode>404</ResponseCode></CustomErrorResponse>`
+	if !strings.Contains(body, expected) || strings.Contains(body, "ErrorCachingMinTTL") {
+		t.Fatalf("Unexpected body:\n%s\nexpected it to contain:\n%s", body, expected)
+	}
+}
+
+func TestWaitUntilDeployed(t *testing.T) {
+	defer setFastPolling()()
+
+	inProgress := strings.Replace(GetDistributionResponse, "<Status>Deployed</Status>", "<Status>InProgress</Status>", 1)
+	api, client := newTestAPI(t,
+		testResponse{Status: 200, Body: inProgress},
+		testResponse{Status: 200, Headers: map[string]string{"ETag": "E2"}, Body: GetDistributionResponse})
+	defer api.Close()
+
+	d, etag, err := client.WaitUntilDeployed("EDFDVBD6EXAMPLE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Status != DistributionDeployed || etag != "E2" || len(api.requests) != 2 {
+		t.Fatalf("Unexpected result: %s (ETag %s) after %d requests", d.Status, etag, len(api.requests))
+	}
+}
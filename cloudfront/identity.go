@@ -0,0 +1,135 @@
+package cloudfront
+
+import (
+	"encoding/xml"
+	"net/url"
+)
+
+// OriginAccessIdentityConfig is the configuration of an origin access
+// identity.
+type OriginAccessIdentityConfig struct {
+	XMLName         xml.Name
+	CallerReference string
+	Comment         string
+}
+
+// OriginAccessIdentity is a virtual identity CloudFront uses to get objects
+// from S3 origins. S3CanonicalUserId grants it access in bucket policies and
+// ACLs.
+type OriginAccessIdentity struct {
+	Id                string
+	S3CanonicalUserId string
+	Config            OriginAccessIdentityConfig `xml:"CloudFrontOriginAccessIdentityConfig"`
+}
+
+// OriginAccessIdentitySummary is an entry of an origin access identity
+// listing.
+type OriginAccessIdentitySummary struct {
+	Id                string
+	S3CanonicalUserId string
+	Comment           string
+}
+
+type originAccessIdentityList struct {
+	Marker      string
+	NextMarker  string
+	MaxItems    int
+	IsTruncated bool
+	Quantity    int
+	Items       []OriginAccessIdentitySummary `xml:"Items>CloudFrontOriginAccessIdentitySummary"`
+}
+
+const identityPath = "/origin-access-identity/cloudfront"
+
+// CreateOriginAccessIdentity creates an origin access identity and returns it
+// with its ETag.
+//
+// Creation is idempotent on callerReference: if an identity was already
+// created with it, that identity is returned, even if its comment differs.
+func (c *Client) CreateOriginAccessIdentity(callerReference, comment string) (*OriginAccessIdentity, string, error) {
+	req := &OriginAccessIdentityConfig{
+		XMLName:         xml.Name{Space: xmlns, Local: "CloudFrontOriginAccessIdentityConfig"},
+		CallerReference: callerReference,
+		Comment:         comment,
+	}
+
+	resp := &OriginAccessIdentity{}
+	header, err := c.query("POST", identityPath, nil, req, resp)
+	if err == nil {
+		return resp, header.Get("ETag"), nil
+	}
+
+	if cferr, ok := err.(*Error); ok && cferr.Code == "CloudFrontOriginAccessIdentityAlreadyExists" {
+		identity, etag, ferr := c.findOriginAccessIdentity(callerReference)
+		if ferr != nil {
+			return nil, "", ferr
+		}
+		if identity != nil {
+			return identity, etag, nil
+		}
+	}
+	return nil, "", err
+}
+
+// findOriginAccessIdentity returns the identity created with
+// callerReference, or nil if there is none.
+func (c *Client) findOriginAccessIdentity(callerReference string) (*OriginAccessIdentity, string, error) {
+	summaries, err := c.ListOriginAccessIdentities()
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, summary := range summaries {
+		identity, etag, err := c.GetOriginAccessIdentity(summary.Id)
+		if err != nil {
+			return nil, "", err
+		}
+		if identity.Config.CallerReference == callerReference {
+			return identity, etag, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// ListOriginAccessIdentities returns all the origin access identities of the
+// account, following the pagination of the listing.
+func (c *Client) ListOriginAccessIdentities() ([]OriginAccessIdentitySummary, error) {
+	var summaries []OriginAccessIdentitySummary
+
+	marker := ""
+	for {
+		path := identityPath
+		if marker != "" {
+			path += "?Marker=" + url.QueryEscape(marker)
+		}
+
+		resp := &originAccessIdentityList{}
+		if _, err := c.query("GET", path, nil, nil, resp); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, resp.Items...)
+
+		if !resp.IsTruncated || resp.NextMarker == "" {
+			return summaries, nil
+		}
+		marker = resp.NextMarker
+	}
+}
+
+// GetOriginAccessIdentity returns the origin access identity id and its ETag.
+func (c *Client) GetOriginAccessIdentity(id string) (*OriginAccessIdentity, string, error) {
+	resp := &OriginAccessIdentity{}
+	header, err := c.query("GET", identityPath+"/"+id, nil, nil, resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, header.Get("ETag"), nil
+}
+
+// DeleteOriginAccessIdentity deletes the origin access identity id. etag is
+// the ETag of the identity returned when it was last read.
+func (c *Client) DeleteOriginAccessIdentity(id, etag string) error {
+	headers := map[string]string{"If-Match": etag}
+	_, err := c.query("DELETE", identityPath+"/"+id, headers, nil, nil)
+	return err
+}
@@ -0,0 +1,110 @@
+package cloudfront
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateOriginAccessIdentity(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{
+		Status:  201,
+		Headers: map[string]string{"ETag": "E2QWRUHAPOMQZL"},
+		Body:    CreateOriginAccessIdentityResponse,
+	})
+	defer api.Close()
+
+	identity, etag, err := client.CreateOriginAccessIdentity("staging-assets", "Staging assets bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := api.requests[0]
+	if req.Method != "POST" || req.Path != "/2014-11-06/origin-access-identity/cloudfront" {
+		t.Fatalf("Unexpected request: %s %s", req.Method, req.Path)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<CloudFrontOriginAccessIdentityConfig xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">` +
+		`<CallerReference>staging-assets</CallerReference><Comment>Staging assets bucket</Comment>` +
+		`</CloudFrontOriginAccessIdentityConfig>`
+	if req.Body != expected {
+		t.Fatalf("Unexpected body:\n%s\nexpected:\n%s", req.Body, expected)
+	}
+
+	if etag != "E2QWRUHAPOMQZL" || identity.Id != "E74FTE3AEXAMPLE" {
+		t.Fatalf("Unexpected identity: %+v (ETag %s)", identity, etag)
+	}
+	if identity.S3CanonicalUserId != "cd13868f797c227fbea2830611a26fe0a21ba1b826ab4bed9b7771c9aEXAMPLE" {
+		t.Fatalf("Unexpected S3CanonicalUserId: %s", identity.S3CanonicalUserId)
+	}
+	if identity.Config.CallerReference != "staging-assets" || identity.Config.Comment != "Staging assets bucket" {
+		t.Fatalf("Unexpected config: %+v", identity.Config)
+	}
+}
+
+func TestCreateOriginAccessIdentityAlreadyExists(t *testing.T) {
+	api, client := newTestAPI(t,
+		testResponse{Status: 409, Body: OriginAccessIdentityAlreadyExistsResponse},
+		testResponse{Status: 200, Body: ListOriginAccessIdentitiesResponse},
+		testResponse{Status: 200, Headers: map[string]string{"ETag": "E1"}, Body: OtherOriginAccessIdentityResponse},
+		testResponse{Status: 200, Headers: map[string]string{"ETag": "E2"}, Body: CreateOriginAccessIdentityResponse})
+	defer api.Close()
+
+	identity, etag, err := client.CreateOriginAccessIdentity("staging-assets", "New comment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Id != "E74FTE3AEXAMPLE" || etag != "E2" {
+		t.Fatalf("Unexpected identity: %+v (ETag %s)", identity, etag)
+	}
+	if api.requests[3].Path != "/2014-11-06/origin-access-identity/cloudfront/E74FTE3AEXAMPLE" {
+		t.Fatalf("Unexpected request: %s", api.requests[3].Path)
+	}
+}
+
+func TestCreateOriginAccessIdentityAlreadyExistsNotFound(t *testing.T) {
+	api, client := newTestAPI(t,
+		testResponse{Status: 409, Body: OriginAccessIdentityAlreadyExistsResponse},
+		testResponse{Status: 200, Body: ListOriginAccessIdentitiesResponse},
+		testResponse{Status: 200, Body: OtherOriginAccessIdentityResponse},
+		testResponse{Status: 200, Body: CreateOriginAccessIdentityResponse})
+	defer api.Close()
+
+	_, _, err := client.CreateOriginAccessIdentity("unknown", "")
+	cferr, ok := err.(*Error)
+	if !ok || cferr.Code != "CloudFrontOriginAccessIdentityAlreadyExists" {
+		t.Fatalf("Expected the original error, got %#v", err)
+	}
+}
+
+func TestListOriginAccessIdentities(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 200, Body: ListOriginAccessIdentitiesResponse})
+	defer api.Close()
+
+	summaries, err := client.ListOriginAccessIdentities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 2 || summaries[0].Id != "E2Y7O3FJEXAMPLE" || summaries[1].Comment != "Staging assets bucket" {
+		t.Fatalf("Unexpected identities: %+v", summaries)
+	}
+	if !strings.HasSuffix(summaries[1].S3CanonicalUserId, "EXAMPLE") {
+		t.Fatalf("Unexpected S3CanonicalUserId: %s", summaries[1].S3CanonicalUserId)
+	}
+}
+
+func TestDeleteOriginAccessIdentity(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 204})
+	defer api.Close()
+
+	if err := client.DeleteOriginAccessIdentity("E74FTE3AEXAMPLE", "E2QWRUHAPOMQZL"); err != nil {
+		t.Fatal(err)
+	}
+
+	req := api.requests[0]
+	if req.Method != "DELETE" || req.Path != "/2014-11-06/origin-access-identity/cloudfront/E74FTE3AEXAMPLE" {
+		t.Fatalf("Unexpected request: %s %s", req.Method, req.Path)
+	}
+	if req.Header.Get("If-Match") != "E2QWRUHAPOMQZL" {
+		t.Fatalf("Unexpected If-Match header: %s", req.Header.Get("If-Match"))
+	}
+}
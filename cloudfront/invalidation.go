@@ -0,0 +1,133 @@
+package cloudfront
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Paths is a list of object paths, as represented in invalidation batches.
+type Paths struct {
+	Quantity int
+	Items    []string `xml:"Items>Path"`
+}
+
+// InvalidationBatch describes the objects to remove from edge caches.
+type InvalidationBatch struct {
+	XMLName         xml.Name `xml:"InvalidationBatch"`
+	Paths           Paths
+	CallerReference string
+}
+
+// Invalidation describes an invalidation request and its progress.
+type Invalidation struct {
+	Id                string
+	Status            string
+	CreateTime        string
+	InvalidationBatch InvalidationBatch
+}
+
+// Invalidation statuses.
+const (
+	InvalidationInProgress = "InProgress"
+	InvalidationCompleted  = "Completed"
+)
+
+// InvalidationSummary is an entry of an InvalidationList.
+type InvalidationSummary struct {
+	Id         string
+	CreateTime string
+	Status     string
+}
+
+// InvalidationList is a page of invalidations of a distribution.
+type InvalidationList struct {
+	Marker      string
+	NextMarker  string
+	MaxItems    int
+	IsTruncated bool
+	Quantity    int
+	Items       []InvalidationSummary `xml:"Items>InvalidationSummary"`
+}
+
+type createInvalidationRequest struct {
+	XMLName         xml.Name `xml:"InvalidationBatch"`
+	Xmlns           string   `xml:"xmlns,attr"`
+	Paths           Paths
+	CallerReference string
+}
+
+// CreateInvalidation removes the objects at paths from the edge caches of the
+// distribution. callerReference must be unique to the request; reusing it
+// with different paths fails with the InvalidationBatchAlreadyExists error
+// code.
+func (c *Client) CreateInvalidation(distributionID string, paths []string, callerReference string) (*Invalidation, error) {
+	req := &createInvalidationRequest{
+		Xmlns:           xmlns,
+		Paths:           Paths{Quantity: len(paths), Items: paths},
+		CallerReference: callerReference,
+	}
+
+	resp := &Invalidation{}
+	path := fmt.Sprintf("/distribution/%s/invalidation", distributionID)
+	if _, err := c.query("POST", path, nil, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetInvalidation returns the invalidation invalidationID of the
+// distribution.
+func (c *Client) GetInvalidation(distributionID, invalidationID string) (*Invalidation, error) {
+	resp := &Invalidation{}
+	path := fmt.Sprintf("/distribution/%s/invalidation/%s", distributionID, invalidationID)
+	if _, err := c.query("GET", path, nil, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListInvalidations returns a page of the invalidations of the distribution,
+// most recent first. The first page is requested with an empty marker, and
+// the following ones with the NextMarker of the previous page while
+// IsTruncated is true. A maxItems of zero uses the API default.
+func (c *Client) ListInvalidations(distributionID, marker string, maxItems int) (*InvalidationList, error) {
+	params := url.Values{}
+	if marker != "" {
+		params.Set("Marker", marker)
+	}
+	if maxItems > 0 {
+		params.Set("MaxItems", strconv.Itoa(maxItems))
+	}
+
+	path := fmt.Sprintf("/distribution/%s/invalidation", distributionID)
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	resp := &InvalidationList{}
+	if _, err := c.query("GET", path, nil, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// WaitUntilInvalidationCompleted polls the invalidation until its status is
+// Completed, and returns it. It gives up after timeout.
+func (c *Client) WaitUntilInvalidationCompleted(distributionID, invalidationID string, timeout time.Duration) (*Invalidation, error) {
+	var inv *Invalidation
+	err := waitFor(timeout, func() (bool, error) {
+		var err error
+		inv, err = c.GetInvalidation(distributionID, invalidationID)
+		if err != nil {
+			return false, err
+		}
+		return inv.Status == InvalidationCompleted, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
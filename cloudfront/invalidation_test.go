@@ -0,0 +1,137 @@
+package cloudfront
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateInvalidation(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 201, Body: CreateInvalidationResponse})
+	defer api.Close()
+
+	inv, err := client.CreateInvalidation("EDFDVBD6EXAMPLE", []string{"/images/image1.jpg", "/videos/*"}, "deploy-20141119")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := api.requests[0]
+	if req.Method != "POST" || req.Path != "/2014-11-06/distribution/EDFDVBD6EXAMPLE/invalidation" {
+		t.Fatalf("Unexpected request: %s %s", req.Method, req.Path)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<InvalidationBatch xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">` +
+		`<Paths><Quantity>2</Quantity><Items><Path>/images/image1.jpg</Path><Path>/videos/*</Path></Items></Paths>` +
+		`<CallerReference>deploy-20141119</CallerReference></InvalidationBatch>`
+	if req.Body != expected {
+		t.Fatalf("Unexpected body:\n%s\nexpected:\n%s", req.Body, expected)
+	}
+
+	if inv.Id != "IDFDVBD632BHDS5" || inv.Status != InvalidationInProgress || inv.CreateTime != "2014-11-19T19:37:58Z" {
+		t.Fatalf("Unexpected invalidation: %+v", inv)
+	}
+	if !reflect.DeepEqual(inv.InvalidationBatch.Paths.Items, []string{"/images/image1.jpg", "/videos/*"}) {
+		t.Fatalf("Unexpected paths: %v", inv.InvalidationBatch.Paths.Items)
+	}
+}
+
+func TestCreateInvalidationCallerReferenceCollision(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 400, Body: InvalidationBatchAlreadyExistsResponse})
+	defer api.Close()
+
+	_, err := client.CreateInvalidation("EDFDVBD6EXAMPLE", []string{"/index.html"}, "deploy-20141119")
+	cferr, ok := err.(*Error)
+	if !ok || cferr.Code != "InvalidationBatchAlreadyExists" {
+		t.Fatalf("Expected an InvalidationBatchAlreadyExists error, got %#v", err)
+	}
+}
+
+func TestGetInvalidation(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 200, Body: GetInvalidationResponse})
+	defer api.Close()
+
+	inv, err := client.GetInvalidation("EDFDVBD6EXAMPLE", "IDFDVBD632BHDS5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := api.requests[0]
+	if req.Method != "GET" || req.Path != "/2014-11-06/distribution/EDFDVBD6EXAMPLE/invalidation/IDFDVBD632BHDS5" {
+		t.Fatalf("Unexpected request: %s %s", req.Method, req.Path)
+	}
+	if inv.Status != InvalidationCompleted || inv.InvalidationBatch.CallerReference != "deploy-20141119" {
+		t.Fatalf("Unexpected invalidation: %+v", inv)
+	}
+}
+
+func TestListInvalidations(t *testing.T) {
+	api, client := newTestAPI(t,
+		testResponse{Status: 200, Body: ListInvalidationsResponse},
+		testResponse{Status: 200, Body: ListInvalidationsResponse})
+	defer api.Close()
+
+	list, err := client.ListInvalidations("EDFDVBD6EXAMPLE", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.requests[0].Query != "" {
+		t.Fatalf("Unexpected query: %s", api.requests[0].Query)
+	}
+
+	if !list.IsTruncated || list.NextMarker != "Invalidation ID" || list.MaxItems != 2 || list.Quantity != 2 {
+		t.Fatalf("Unexpected list: %+v", list)
+	}
+	expected := []InvalidationSummary{
+		{Id: "Second Invalidation ID", CreateTime: "2014-11-20T19:37:58Z", Status: "Completed"},
+		{Id: "First Invalidation ID", CreateTime: "2014-11-19T19:37:58Z", Status: "InProgress"},
+	}
+	if !reflect.DeepEqual(list.Items, expected) {
+		t.Fatalf("Unexpected items: %+v", list.Items)
+	}
+
+	if _, err := client.ListInvalidations("EDFDVBD6EXAMPLE", list.NextMarker, 2); err != nil {
+		t.Fatal(err)
+	}
+	if api.requests[1].Query != "Marker=Invalidation+ID&MaxItems=2" {
+		t.Fatalf("Unexpected query: %s", api.requests[1].Query)
+	}
+}
+
+func TestWaitUntilInvalidationCompleted(t *testing.T) {
+	defer setFastPolling()()
+
+	api, client := newTestAPI(t,
+		testResponse{Status: 200, Body: CreateInvalidationResponse},
+		testResponse{Status: 200, Body: CreateInvalidationResponse},
+		testResponse{Status: 200, Body: GetInvalidationResponse})
+	defer api.Close()
+
+	inv, err := client.WaitUntilInvalidationCompleted("EDFDVBD6EXAMPLE", "IDFDVBD632BHDS5", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.Status != InvalidationCompleted {
+		t.Fatalf("Unexpected status: %s", inv.Status)
+	}
+	if len(api.requests) != 3 {
+		t.Fatalf("Expected 3 polls, got %d", len(api.requests))
+	}
+}
+
+func TestWaitUntilInvalidationCompletedTimeout(t *testing.T) {
+	defer setFastPolling()()
+
+	responses := []testResponse{}
+	for i := 0; i < 100; i++ {
+		responses = append(responses, testResponse{Status: 200, Body: CreateInvalidationResponse})
+	}
+	api, client := newTestAPI(t, responses...)
+	defer api.Close()
+
+	_, err := client.WaitUntilInvalidationCompleted("EDFDVBD6EXAMPLE", "IDFDVBD632BHDS5", 10*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package cloudfront
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// LoadKeyFromPEM parses the private key of a CloudFront key pair, as
+// downloaded from the AWS console, from its PEM encoding. Both PKCS #1
+// ("RSA PRIVATE KEY") and PKCS #8 ("PRIVATE KEY") encodings are supported.
+func LoadKeyFromPEM(data []byte) (*rsa.PrivateKey, error) {
+	return loadKeyFromPEM(data, nil)
+}
+
+// LoadKeyFromEncryptedPEM is like LoadKeyFromPEM but decrypts the PEM block
+// with passphrase first.
+func LoadKeyFromEncryptedPEM(data, passphrase []byte) (*rsa.PrivateKey, error) {
+	return loadKeyFromPEM(data, passphrase)
+}
+
+// NewFromPEMFile returns a CloudFront signing with the private key read from
+// the PEM file at path.
+func NewFromPEMFile(baseurl, path, keyPairId string) (*CloudFront, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := LoadKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return New(baseurl, key, keyPairId)
+}
+
+func loadKeyFromPEM(data, passphrase []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM encoded key found")
+	}
+
+	der := block.Bytes
+	if x509.IsEncryptedPEMBlock(block) {
+		if passphrase == nil {
+			return nil, errors.New("PEM block is encrypted, a passphrase is required")
+		}
+		var err error
+		der, err = x509.DecryptPEMBlock(block, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decrypt PEM block: %v", err)
+		}
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse PKCS #1 private key: %v", err)
+		}
+		return key, nil
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse PKCS #8 private key: %v", err)
+		}
+		switch key := key.(type) {
+		case *rsa.PrivateKey:
+			return key, nil
+		case *ecdsa.PrivateKey:
+			return nil, errors.New("CloudFront key pairs must be RSA keys, found an EC key")
+		default:
+			return nil, fmt.Errorf("CloudFront key pairs must be RSA keys, found %T", key)
+		}
+	case "EC PRIVATE KEY":
+		return nil, errors.New("CloudFront key pairs must be RSA keys, found an EC key")
+	case "ENCRYPTED PRIVATE KEY":
+		return nil, errors.New("encrypted PKCS #8 keys are not supported, decrypt the key first")
+	}
+	return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+}
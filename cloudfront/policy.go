@@ -0,0 +1,195 @@
+package cloudfront
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ErrInvalidExpiry is returned when asked to sign a policy whose expiration
+// time is zero or not after the current time as told by CloudFront.Clock.
+// Policies have a resolution of one second, so an expiration time within the
+// current second is already past.
+var ErrInvalidExpiry = errors.New("cloudfront: expiration time is zero or in the past")
+
+type epochTime struct {
+	EpochTime int64 `json:"AWS:EpochTime"`
+}
+
+type sourceIP struct {
+	SourceIP string `json:"AWS:SourceIp"`
+}
+
+type condition struct {
+	DateLessThan    epochTime
+	DateGreaterThan *epochTime `json:",omitempty"`
+	IpAddress       *sourceIP  `json:",omitempty"`
+}
+
+type statement struct {
+	Resource  string
+	Condition condition
+}
+
+// policyDocument is the JSON representation of a Policy.
+type policyDocument struct {
+	Statement []statement
+}
+
+// PolicyOptions holds the conditions of a custom policy. Expires is
+// required; ActiveFrom and SourceIP are left out of the policy when unset.
+// Times are truncated to whole seconds.
+//
+// SourceIP may be a single address or a CIDR range. A single address is
+// restricted to itself (/32 or /128).
+type PolicyOptions struct {
+	Expires    time.Time
+	ActiveFrom time.Time
+	SourceIP   string
+}
+
+// Policy grants access to Resource under the conditions of its
+// PolicyOptions. Resource is an URL, or for custom policies a pattern where
+// '*' matches any sequence of characters and '?' any single character.
+type Policy struct {
+	Resource string
+	PolicyOptions
+}
+
+// NewCannedPolicy returns the canned policy granting access to resource until
+// expires. CloudFront rebuilds canned policies from signed URLs, so resource
+// must be the URL the policy is signed for, without the signature
+// parameters.
+func NewCannedPolicy(resource string, expires time.Time) *Policy {
+	return &Policy{
+		Resource:      resource,
+		PolicyOptions: PolicyOptions{Expires: expires},
+	}
+}
+
+// NewCustomPolicy returns the custom policy granting access to resource
+// under the conditions of opts.
+func NewCustomPolicy(resource string, opts PolicyOptions) *Policy {
+	return &Policy{
+		Resource:      resource,
+		PolicyOptions: opts,
+	}
+}
+
+// JSON returns the policy document, as signed and as CloudFront expects it:
+// without whitespace, with the statement fields in the order Resource then
+// Condition, and the conditions in the order DateLessThan, DateGreaterThan
+// then IpAddress. Signatures are computed on these exact bytes.
+func (p *Policy) JSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := p.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJSON appends the policy document to buf, so callers signing many
+// policies can reuse buffers.
+func (p *Policy) writeJSON(buf *bytes.Buffer) error {
+	if p.Expires.IsZero() {
+		return errors.New("policy expiration time is required")
+	}
+
+	cond := condition{
+		DateLessThan: epochTime{
+			EpochTime: p.Expires.Unix(),
+		},
+	}
+
+	if !p.ActiveFrom.IsZero() {
+		if p.ActiveFrom.Unix() >= p.Expires.Unix() {
+			return errors.New("policy start time must be before its expiration time")
+		}
+		cond.DateGreaterThan = &epochTime{
+			EpochTime: p.ActiveFrom.Unix(),
+		}
+	}
+
+	if p.SourceIP != "" {
+		cidr, err := sourceCIDR(p.SourceIP)
+		if err != nil {
+			return err
+		}
+		cond.IpAddress = &sourceIP{SourceIP: cidr}
+	}
+
+	doc := &policyDocument{
+		Statement: []statement{
+			statement{
+				Resource:  p.Resource,
+				Condition: cond,
+			},
+		}}
+
+	// CloudFront rebuilds canned policies from the URL, so characters like
+	// '&' in the resource must not be escaped.
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	// Drop the newline Encode terminates the policy with.
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
+
+// sourceCIDR normalizes an address or CIDR range to the notation
+// CloudFront expects in an AWS:SourceIp condition.
+func sourceCIDR(ip string) (string, error) {
+	if _, ipnet, err := net.ParseCIDR(ip); err == nil {
+		return ipnet.String(), nil
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return "", fmt.Errorf("invalid source IP %q", ip)
+	}
+	if addr.To4() != nil {
+		return addr.String() + "/32", nil
+	}
+	return addr.String() + "/128", nil
+}
+
+// Sign returns the JSON document of p and its signature, encoded in the
+// URL-safe variant of base64 CloudFront expects in URLs and cookies. The
+// document is the one passed along a custom policy; for canned policies
+// CloudFront rebuilds it from the URL.
+//
+// ErrInvalidExpiry is returned if p expires at a zero time or already
+// expired.
+func (cf *CloudFront) Sign(p *Policy) (policyJSON []byte, signature string, err error) {
+	var buf bytes.Buffer
+	signature, err = cf.sign(&buf, p)
+	if err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), signature, nil
+}
+
+// sign is Sign writing the policy document in buf.
+func (cf *CloudFront) sign(buf *bytes.Buffer, p *Policy) (string, error) {
+	if err := cf.checkExpires(p.Expires); err != nil {
+		return "", err
+	}
+
+	buf.Reset()
+	if err := p.writeJSON(buf); err != nil {
+		return "", err
+	}
+	return cf.generateSignature(buf.Bytes())
+}
+
+func (cf *CloudFront) checkExpires(expires time.Time) error {
+	if expires.IsZero() || expires.Unix() <= cf.now().Unix() {
+		return ErrInvalidExpiry
+	}
+	return nil
+}
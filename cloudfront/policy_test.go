@@ -0,0 +1,70 @@
+package cloudfront
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// The golden files hold policy documents byte for byte, without a trailing
+// newline. Any change to them breaks the signatures of existing URLs and
+// cookies.
+func TestPolicyJSONGolden(t *testing.T) {
+	expires := time.Unix(1396015221, 0)
+
+	tests := []struct {
+		golden string
+		policy *Policy
+	}{
+		{"policy-canned.json", NewCannedPolicy("https://dxxx.cloudfront.net/videos/movie.mp4?a=1&b=2", expires)},
+		{"policy-custom.json", NewCustomPolicy("https://dxxx.cloudfront.net/videos/*", PolicyOptions{
+			Expires:    expires,
+			ActiveFrom: expires.Add(-time.Hour),
+			SourceIP:   "192.0.2.0/24",
+		})},
+		{"policy-special-chars.json", NewCustomPolicy("https://dxxx.cloudfront.net/<a>&b/é?.txt", PolicyOptions{
+			Expires:  expires.Add(999 * time.Millisecond),
+			SourceIP: "2001:db8::1",
+		})},
+	}
+
+	for _, test := range tests {
+		policy, err := test.policy.JSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := readTestData(t, test.golden)
+		if !bytes.Equal(policy, expected) {
+			t.Errorf("%s: policy does not match:\n%s\nexpected:\n%s", test.golden, policy, expected)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	cf := testCloudFront(t, "https://dxxx.cloudfront.net")
+	policy := NewCannedPolicy("https://dxxx.cloudfront.net/videos/movie.mp4?a=1&b=2", time.Unix(1396015221, 0))
+
+	policyJSON, signature, err := cf.Sign(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(policyJSON, readTestData(t, "policy-canned.json")) {
+		t.Fatalf("Unexpected policy: %s", policyJSON)
+	}
+	verifySignature(t, encodeBase64(policyJSON), signature)
+
+	// Signed URLs are signed on the same document.
+	uri, err := cf.CannedSignedURL("videos/movie.mp4", "a=1&b=2", policy.Expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "https://dxxx.cloudfront.net/videos/movie.mp4?a=1&b=2&Expires=1396015221&Signature=" + signature + "&Key-Pair-Id=test-key-pair-1231245"
+	if uri != expected {
+		t.Fatalf("Unexpected URL %s, expected %s", uri, expected)
+	}
+
+	cf.Clock = &fakeClock{policy.Expires}
+	if _, _, err := cf.Sign(policy); err != ErrInvalidExpiry {
+		t.Fatalf("Expected ErrInvalidExpiry, got %v", err)
+	}
+}
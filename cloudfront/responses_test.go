@@ -0,0 +1,568 @@
+package cloudfront
+
+var NoSuchDistributionResponse = `
+<?xml version="1.0"?>
+<ErrorResponse xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Error>
+      <Type>Sender</Type>
+      <Code>NoSuchDistribution</Code>
+      <Message>The specified distribution does not exist.</Message>
+   </Error>
+   <RequestId>a2b8a1d5-4a1c-11e4-8a8b-EXAMPLE</RequestId>
+</ErrorResponse>
+`
+
+var InvalidationBatchAlreadyExistsResponse = `
+<?xml version="1.0"?>
+<ErrorResponse xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Error>
+      <Type>Sender</Type>
+      <Code>InvalidationBatchAlreadyExists</Code>
+      <Message>Invalidation batch specified in the request already exists.</Message>
+   </Error>
+   <RequestId>b3c9b2e6-4a1c-11e4-8a8b-EXAMPLE</RequestId>
+</ErrorResponse>
+`
+
+var CreateInvalidationResponse = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Invalidation xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Id>IDFDVBD632BHDS5</Id>
+   <Status>InProgress</Status>
+   <CreateTime>2014-11-19T19:37:58Z</CreateTime>
+   <InvalidationBatch>
+      <Paths>
+         <Quantity>2</Quantity>
+         <Items>
+            <Path>/images/image1.jpg</Path>
+            <Path>/videos/*</Path>
+         </Items>
+      </Paths>
+      <CallerReference>deploy-20141119</CallerReference>
+   </InvalidationBatch>
+</Invalidation>
+`
+
+var GetInvalidationResponse = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Invalidation xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Id>IDFDVBD632BHDS5</Id>
+   <Status>Completed</Status>
+   <CreateTime>2014-11-19T19:37:58Z</CreateTime>
+   <InvalidationBatch>
+      <Paths>
+         <Quantity>2</Quantity>
+         <Items>
+            <Path>/images/image1.jpg</Path>
+            <Path>/videos/*</Path>
+         </Items>
+      </Paths>
+      <CallerReference>deploy-20141119</CallerReference>
+   </InvalidationBatch>
+</Invalidation>
+`
+
+var ListInvalidationsResponse = `
+<?xml version="1.0" encoding="UTF-8"?>
+<InvalidationList xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Marker>EGTXBD79EXAMPLE</Marker>
+   <NextMarker>Invalidation ID</NextMarker>
+   <MaxItems>2</MaxItems>
+   <IsTruncated>true</IsTruncated>
+   <Quantity>2</Quantity>
+   <Items>
+      <InvalidationSummary>
+         <Id>Second Invalidation ID</Id>
+         <CreateTime>2014-11-20T19:37:58Z</CreateTime>
+         <Status>Completed</Status>
+      </InvalidationSummary>
+      <InvalidationSummary>
+         <Id>First Invalidation ID</Id>
+         <CreateTime>2014-11-19T19:37:58Z</CreateTime>
+         <Status>InProgress</Status>
+      </InvalidationSummary>
+   </Items>
+</InvalidationList>
+`
+
+var ListDistributionsResponsePage1 = `
+<?xml version="1.0" encoding="UTF-8"?>
+<DistributionList xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Marker></Marker>
+   <NextMarker>EMLARXS9EXAMPLE</NextMarker>
+   <MaxItems>1</MaxItems>
+   <IsTruncated>true</IsTruncated>
+   <Quantity>1</Quantity>
+   <Items>
+      <DistributionSummary>
+         <Id>EDFDVBD6EXAMPLE</Id>
+         <Status>Deployed</Status>
+         <LastModifiedTime>2014-11-19T19:37:58Z</LastModifiedTime>
+         <DomainName>d111111abcdef8.cloudfront.net</DomainName>
+         <Aliases>
+            <Quantity>2</Quantity>
+            <Items>
+               <CNAME>www.example.com</CNAME>
+               <CNAME>*.example.org</CNAME>
+            </Items>
+         </Aliases>
+         <Origins>
+            <Quantity>1</Quantity>
+            <Items>
+               <Origin>
+                  <Id>example-Amazon S3-origin</Id>
+                  <DomainName>myawsbucket.s3.amazonaws.com</DomainName>
+                  <OriginPath></OriginPath>
+                  <S3OriginConfig>
+                     <OriginAccessIdentity>origin-access-identity/cloudfront/E74FTE3AEXAMPLE</OriginAccessIdentity>
+                  </S3OriginConfig>
+               </Origin>
+            </Items>
+         </Origins>
+         <DefaultCacheBehavior>
+            <TargetOriginId>example-Amazon S3-origin</TargetOriginId>
+            <ForwardedValues>
+               <QueryString>true</QueryString>
+               <Cookies>
+                  <Forward>none</Forward>
+               </Cookies>
+               <Headers>
+                  <Quantity>0</Quantity>
+               </Headers>
+            </ForwardedValues>
+            <TrustedSigners>
+               <Enabled>false</Enabled>
+               <Quantity>0</Quantity>
+            </TrustedSigners>
+            <ViewerProtocolPolicy>allow-all</ViewerProtocolPolicy>
+            <MinTTL>0</MinTTL>
+            <AllowedMethods>
+               <Quantity>2</Quantity>
+               <Items>
+                  <Method>GET</Method>
+                  <Method>HEAD</Method>
+               </Items>
+               <CachedMethods>
+                  <Quantity>2</Quantity>
+                  <Items>
+                     <Method>GET</Method>
+                     <Method>HEAD</Method>
+                  </Items>
+               </CachedMethods>
+            </AllowedMethods>
+            <SmoothStreaming>false</SmoothStreaming>
+         </DefaultCacheBehavior>
+         <CacheBehaviors>
+            <Quantity>0</Quantity>
+         </CacheBehaviors>
+         <CustomErrorResponses>
+            <Quantity>0</Quantity>
+         </CustomErrorResponses>
+         <Comment>example comment</Comment>
+         <PriceClass>PriceClass_All</PriceClass>
+         <Enabled>true</Enabled>
+         <ViewerCertificate>
+            <CloudFrontDefaultCertificate>true</CloudFrontDefaultCertificate>
+            <MinimumProtocolVersion>SSLv3</MinimumProtocolVersion>
+         </ViewerCertificate>
+         <Restrictions>
+            <GeoRestriction>
+               <RestrictionType>none</RestrictionType>
+               <Quantity>0</Quantity>
+            </GeoRestriction>
+         </Restrictions>
+      </DistributionSummary>
+   </Items>
+</DistributionList>
+`
+
+var ListDistributionsResponsePage2 = `
+<?xml version="1.0" encoding="UTF-8"?>
+<DistributionList xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Marker>EMLARXS9EXAMPLE</Marker>
+   <MaxItems>1</MaxItems>
+   <IsTruncated>false</IsTruncated>
+   <Quantity>1</Quantity>
+   <Items>
+      <DistributionSummary>
+         <Id>EMLARXS9EXAMPLE</Id>
+         <Status>InProgress</Status>
+         <LastModifiedTime>2014-11-20T10:12:01Z</LastModifiedTime>
+         <DomainName>d222222abcdef8.cloudfront.net</DomainName>
+         <Aliases>
+            <Quantity>1</Quantity>
+            <Items>
+               <CNAME>cdn.example.com</CNAME>
+            </Items>
+         </Aliases>
+         <Origins>
+            <Quantity>1</Quantity>
+            <Items>
+               <Origin>
+                  <Id>example-custom-origin</Id>
+                  <DomainName>www.example.com</DomainName>
+                  <OriginPath>/production</OriginPath>
+                  <CustomOriginConfig>
+                     <HTTPPort>80</HTTPPort>
+                     <HTTPSPort>443</HTTPSPort>
+                     <OriginProtocolPolicy>match-viewer</OriginProtocolPolicy>
+                  </CustomOriginConfig>
+               </Origin>
+            </Items>
+         </Origins>
+         <DefaultCacheBehavior>
+            <TargetOriginId>example-custom-origin</TargetOriginId>
+            <ForwardedValues>
+               <QueryString>false</QueryString>
+               <Cookies>
+                  <Forward>all</Forward>
+               </Cookies>
+               <Headers>
+                  <Quantity>0</Quantity>
+               </Headers>
+            </ForwardedValues>
+            <TrustedSigners>
+               <Enabled>false</Enabled>
+               <Quantity>0</Quantity>
+            </TrustedSigners>
+            <ViewerProtocolPolicy>redirect-to-https</ViewerProtocolPolicy>
+            <MinTTL>3600</MinTTL>
+            <SmoothStreaming>false</SmoothStreaming>
+         </DefaultCacheBehavior>
+         <CacheBehaviors>
+            <Quantity>0</Quantity>
+         </CacheBehaviors>
+         <CustomErrorResponses>
+            <Quantity>0</Quantity>
+         </CustomErrorResponses>
+         <Comment></Comment>
+         <PriceClass>PriceClass_100</PriceClass>
+         <Enabled>false</Enabled>
+         <ViewerCertificate>
+            <CloudFrontDefaultCertificate>true</CloudFrontDefaultCertificate>
+         </ViewerCertificate>
+         <Restrictions>
+            <GeoRestriction>
+               <RestrictionType>none</RestrictionType>
+               <Quantity>0</Quantity>
+            </GeoRestriction>
+         </Restrictions>
+      </DistributionSummary>
+   </Items>
+</DistributionList>
+`
+
+var GetDistributionResponse = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Distribution xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Id>EDFDVBD6EXAMPLE</Id>
+   <Status>Deployed</Status>
+   <LastModifiedTime>2014-11-19T19:37:58Z</LastModifiedTime>
+   <InProgressInvalidationBatches>1</InProgressInvalidationBatches>
+   <DomainName>d111111abcdef8.cloudfront.net</DomainName>
+   <ActiveTrustedSigners>
+      <Enabled>true</Enabled>
+      <Quantity>1</Quantity>
+      <Items>
+         <Signer>
+            <AwsAccountNumber>self</AwsAccountNumber>
+            <KeyPairIds>
+               <Quantity>1</Quantity>
+               <Items>
+                  <KeyPairId>APKA9ONS7QCOWEXAMPLE</KeyPairId>
+               </Items>
+            </KeyPairIds>
+         </Signer>
+      </Items>
+   </ActiveTrustedSigners>
+   <DistributionConfig>
+      <CallerReference>example1</CallerReference>
+      <Aliases>
+         <Quantity>1</Quantity>
+         <Items>
+            <CNAME>www.example.com</CNAME>
+         </Items>
+      </Aliases>
+      <DefaultRootObject>index.html</DefaultRootObject>
+      <Origins>
+         <Quantity>1</Quantity>
+         <Items>
+            <Origin>
+               <Id>example-Amazon S3-origin</Id>
+               <DomainName>myawsbucket.s3.amazonaws.com</DomainName>
+               <OriginPath></OriginPath>
+               <S3OriginConfig>
+                  <OriginAccessIdentity>origin-access-identity/cloudfront/E74FTE3AEXAMPLE</OriginAccessIdentity>
+               </S3OriginConfig>
+            </Origin>
+         </Items>
+      </Origins>
+      <DefaultCacheBehavior>
+         <TargetOriginId>example-Amazon S3-origin</TargetOriginId>
+         <ForwardedValues>
+            <QueryString>true</QueryString>
+            <Cookies>
+               <Forward>whitelist</Forward>
+               <WhitelistedNames>
+                  <Quantity>1</Quantity>
+                  <Items>
+                     <Name>session</Name>
+                  </Items>
+               </WhitelistedNames>
+            </Cookies>
+            <Headers>
+               <Quantity>1</Quantity>
+               <Items>
+                  <Name>Origin</Name>
+               </Items>
+            </Headers>
+         </ForwardedValues>
+         <TrustedSigners>
+            <Enabled>true</Enabled>
+            <Quantity>1</Quantity>
+            <Items>
+               <AwsAccountNumber>self</AwsAccountNumber>
+            </Items>
+         </TrustedSigners>
+         <ViewerProtocolPolicy>https-only</ViewerProtocolPolicy>
+         <MinTTL>0</MinTTL>
+         <SmoothStreaming>false</SmoothStreaming>
+      </DefaultCacheBehavior>
+      <CacheBehaviors>
+         <Quantity>1</Quantity>
+         <Items>
+            <CacheBehavior>
+               <PathPattern>*.jpg</PathPattern>
+               <TargetOriginId>example-Amazon S3-origin</TargetOriginId>
+               <ForwardedValues>
+                  <QueryString>false</QueryString>
+                  <Cookies>
+                     <Forward>none</Forward>
+                  </Cookies>
+                  <Headers>
+                     <Quantity>0</Quantity>
+                  </Headers>
+               </ForwardedValues>
+               <TrustedSigners>
+                  <Enabled>false</Enabled>
+                  <Quantity>0</Quantity>
+               </TrustedSigners>
+               <ViewerProtocolPolicy>allow-all</ViewerProtocolPolicy>
+               <MinTTL>86400</MinTTL>
+               <SmoothStreaming>false</SmoothStreaming>
+            </CacheBehavior>
+         </Items>
+      </CacheBehaviors>
+      <CustomErrorResponses>
+         <Quantity>1</Quantity>
+         <Items>
+            <CustomErrorResponse>
+               <ErrorCode>404</ErrorCode>
+               <ResponsePagePath>/errors/404.html</ResponsePagePath>
+               <ResponseCode>200</ResponseCode>
+               <ErrorCachingMinTTL>30</ErrorCachingMinTTL>
+            </CustomErrorResponse>
+         </Items>
+      </CustomErrorResponses>
+      <Comment>example comment</Comment>
+      <Logging>
+         <Enabled>true</Enabled>
+         <IncludeCookies>false</IncludeCookies>
+         <Bucket>myawslogbucket.s3.amazonaws.com</Bucket>
+         <Prefix>example.com.</Prefix>
+      </Logging>
+      <PriceClass>PriceClass_All</PriceClass>
+      <Enabled>true</Enabled>
+      <ViewerCertificate>
+         <CloudFrontDefaultCertificate>true</CloudFrontDefaultCertificate>
+      </ViewerCertificate>
+      <Restrictions>
+         <GeoRestriction>
+            <RestrictionType>whitelist</RestrictionType>
+            <Quantity>2</Quantity>
+            <Items>
+               <Location>US</Location>
+               <Location>CA</Location>
+            </Items>
+         </GeoRestriction>
+      </Restrictions>
+   </DistributionConfig>
+</Distribution>
+`
+
+var PreconditionFailedResponse = `
+<?xml version="1.0"?>
+<ErrorResponse xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Error>
+      <Type>Sender</Type>
+      <Code>PreconditionFailed</Code>
+      <Message>The If-Match version is missing or not valid for the resource.</Message>
+   </Error>
+   <RequestId>c4dac3f7-4a1c-11e4-8a8b-EXAMPLE</RequestId>
+</ErrorResponse>
+`
+
+var CreateOriginAccessIdentityResponse = `
+<?xml version="1.0" encoding="UTF-8"?>
+<CloudFrontOriginAccessIdentity xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Id>E74FTE3AEXAMPLE</Id>
+   <S3CanonicalUserId>cd13868f797c227fbea2830611a26fe0a21ba1b826ab4bed9b7771c9aEXAMPLE</S3CanonicalUserId>
+   <CloudFrontOriginAccessIdentityConfig>
+      <CallerReference>staging-assets</CallerReference>
+      <Comment>Staging assets bucket</Comment>
+   </CloudFrontOriginAccessIdentityConfig>
+</CloudFrontOriginAccessIdentity>
+`
+
+var OtherOriginAccessIdentityResponse = `
+<?xml version="1.0" encoding="UTF-8"?>
+<CloudFrontOriginAccessIdentity xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Id>E2Y7O3FJEXAMPLE</Id>
+   <S3CanonicalUserId>2f8f6e57e4a0bd6b7f5b1b7d9a3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8eEXAMPLE</S3CanonicalUserId>
+   <CloudFrontOriginAccessIdentityConfig>
+      <CallerReference>production-assets</CallerReference>
+      <Comment>Production assets bucket</Comment>
+   </CloudFrontOriginAccessIdentityConfig>
+</CloudFrontOriginAccessIdentity>
+`
+
+var OriginAccessIdentityAlreadyExistsResponse = `
+<?xml version="1.0"?>
+<ErrorResponse xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Error>
+      <Type>Sender</Type>
+      <Code>CloudFrontOriginAccessIdentityAlreadyExists</Code>
+      <Message>If the CallerReference is a value you already sent in a previous request to create an identity but the content of the CloudFrontOriginAccessIdentityConfig is different from the original request, CloudFront returns a CloudFrontOriginAccessIdentityAlreadyExists error.</Message>
+   </Error>
+   <RequestId>d5ebd4a8-4a1c-11e4-8a8b-EXAMPLE</RequestId>
+</ErrorResponse>
+`
+
+var ListOriginAccessIdentitiesResponse = `
+<?xml version="1.0" encoding="UTF-8"?>
+<CloudFrontOriginAccessIdentityList xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Marker></Marker>
+   <MaxItems>100</MaxItems>
+   <IsTruncated>false</IsTruncated>
+   <Quantity>2</Quantity>
+   <Items>
+      <CloudFrontOriginAccessIdentitySummary>
+         <Id>E2Y7O3FJEXAMPLE</Id>
+         <S3CanonicalUserId>2f8f6e57e4a0bd6b7f5b1b7d9a3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8eEXAMPLE</S3CanonicalUserId>
+         <Comment>Production assets bucket</Comment>
+      </CloudFrontOriginAccessIdentitySummary>
+      <CloudFrontOriginAccessIdentitySummary>
+         <Id>E74FTE3AEXAMPLE</Id>
+         <S3CanonicalUserId>cd13868f797c227fbea2830611a26fe0a21ba1b826ab4bed9b7771c9aEXAMPLE</S3CanonicalUserId>
+         <Comment>Staging assets bucket</Comment>
+      </CloudFrontOriginAccessIdentitySummary>
+   </Items>
+</CloudFrontOriginAccessIdentityList>
+`
+
+var ListTagsForResourceResponse = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Tags xmlns="http://cloudfront.amazonaws.com/doc/2015-07-27/">
+   <Items>
+      <Tag>
+         <Key>cost-center</Key>
+         <Value>media</Value>
+      </Tag>
+      <Tag>
+         <Key>environment</Key>
+         <Value>production</Value>
+      </Tag>
+   </Items>
+</Tags>
+`
+
+var StreamingDistributionResponse = `
+<?xml version="1.0" encoding="UTF-8"?>
+<StreamingDistribution xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Id>EGTXBD79EXAMPLE</Id>
+   <Status>InProgress</Status>
+   <LastModifiedTime>2014-11-19T19:37:58Z</LastModifiedTime>
+   <DomainName>s5c39gqb8ow64r.cloudfront.net</DomainName>
+   <ActiveTrustedSigners>
+      <Enabled>true</Enabled>
+      <Quantity>1</Quantity>
+      <Items>
+         <Signer>
+            <AwsAccountNumber>self</AwsAccountNumber>
+            <KeyPairIds>
+               <Quantity>1</Quantity>
+               <Items>
+                  <KeyPairId>APKAI72T5DYBXEXAMPLE</KeyPairId>
+               </Items>
+            </KeyPairIds>
+         </Signer>
+      </Items>
+   </ActiveTrustedSigners>
+   <StreamingDistributionConfig>
+      <CallerReference>media-2014</CallerReference>
+      <S3Origin>
+         <DomainName>mediabucket.s3.amazonaws.com</DomainName>
+         <OriginAccessIdentity>origin-access-identity/cloudfront/E74FTE3AEXAMPLE</OriginAccessIdentity>
+      </S3Origin>
+      <Aliases>
+         <Quantity>1</Quantity>
+         <Items>
+            <CNAME>media.example.com</CNAME>
+         </Items>
+      </Aliases>
+      <Comment>Media streaming</Comment>
+      <Logging>
+         <Enabled>true</Enabled>
+         <Bucket>logs.s3.amazonaws.com</Bucket>
+         <Prefix>rtmp/</Prefix>
+      </Logging>
+      <TrustedSigners>
+         <Enabled>true</Enabled>
+         <Quantity>1</Quantity>
+         <Items>
+            <AwsAccountNumber>self</AwsAccountNumber>
+         </Items>
+      </TrustedSigners>
+      <PriceClass>PriceClass_All</PriceClass>
+      <Enabled>true</Enabled>
+   </StreamingDistributionConfig>
+</StreamingDistribution>
+`
+
+var ListStreamingDistributionsResponse = `
+<?xml version="1.0" encoding="UTF-8"?>
+<StreamingDistributionList xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">
+   <Marker></Marker>
+   <MaxItems>100</MaxItems>
+   <IsTruncated>false</IsTruncated>
+   <Quantity>1</Quantity>
+   <Items>
+      <StreamingDistributionSummary>
+         <Id>EGTXBD79EXAMPLE</Id>
+         <Status>Deployed</Status>
+         <LastModifiedTime>2014-11-19T19:37:58Z</LastModifiedTime>
+         <DomainName>s5c39gqb8ow64r.cloudfront.net</DomainName>
+         <S3Origin>
+            <DomainName>mediabucket.s3.amazonaws.com</DomainName>
+            <OriginAccessIdentity>origin-access-identity/cloudfront/E74FTE3AEXAMPLE</OriginAccessIdentity>
+         </S3Origin>
+         <Aliases>
+            <Quantity>1</Quantity>
+            <Items>
+               <CNAME>media.example.com</CNAME>
+            </Items>
+         </Aliases>
+         <TrustedSigners>
+            <Enabled>true</Enabled>
+            <Quantity>1</Quantity>
+            <Items>
+               <AwsAccountNumber>self</AwsAccountNumber>
+            </Items>
+         </TrustedSigners>
+         <Comment>Media streaming</Comment>
+         <PriceClass>PriceClass_All</PriceClass>
+         <Enabled>true</Enabled>
+      </StreamingDistributionSummary>
+   </Items>
+</StreamingDistributionList>
+`
@@ -0,0 +1,150 @@
+package cloudfront
+
+import (
+	"encoding/xml"
+	"net/url"
+)
+
+// S3Origin is the Amazon S3 bucket a streaming distribution gets media files
+// from. OriginAccessIdentity is either empty or
+// "origin-access-identity/cloudfront/<id>".
+type S3Origin struct {
+	DomainName           string
+	OriginAccessIdentity string
+}
+
+// StreamingLogging configures access logs of a streaming distribution.
+type StreamingLogging struct {
+	Enabled bool
+	Bucket  string
+	Prefix  string
+}
+
+// StreamingDistributionConfig is the configuration of an RTMP streaming
+// distribution.
+type StreamingDistributionConfig struct {
+	XMLName         xml.Name
+	CallerReference string
+	S3Origin        S3Origin
+	Aliases         Aliases
+	Comment         string
+	Logging         StreamingLogging
+	TrustedSigners  TrustedSigners
+	PriceClass      string `xml:",omitempty"`
+	Enabled         bool
+}
+
+// request returns the copy of cfg sent to the API, with the quantities of its
+// lists set from their items.
+func (cfg *StreamingDistributionConfig) request() *StreamingDistributionConfig {
+	req := *cfg
+	req.XMLName = xml.Name{Space: xmlns, Local: "StreamingDistributionConfig"}
+	req.Aliases.Quantity = len(req.Aliases.Items)
+	req.TrustedSigners.Quantity = len(req.TrustedSigners.Items)
+	return &req
+}
+
+// StreamingDistribution is a streaming distribution with its configuration.
+type StreamingDistribution struct {
+	Id                          string
+	Status                      string
+	LastModifiedTime            string
+	DomainName                  string
+	ActiveTrustedSigners        ActiveTrustedSigners
+	StreamingDistributionConfig StreamingDistributionConfig
+}
+
+// StreamingDistributionSummary is an entry of a streaming distribution
+// listing.
+type StreamingDistributionSummary struct {
+	Id               string
+	Status           string
+	LastModifiedTime string
+	DomainName       string
+	S3Origin         S3Origin
+	Aliases          Aliases
+	TrustedSigners   TrustedSigners
+	Comment          string
+	PriceClass       string
+	Enabled          bool
+}
+
+type streamingDistributionList struct {
+	Marker      string
+	NextMarker  string
+	MaxItems    int
+	IsTruncated bool
+	Quantity    int
+	Items       []StreamingDistributionSummary `xml:"Items>StreamingDistributionSummary"`
+}
+
+const streamingPath = "/streaming-distribution"
+
+// CreateStreamingDistribution creates a streaming distribution configured by
+// cfg and returns it with its ETag.
+func (c *Client) CreateStreamingDistribution(cfg *StreamingDistributionConfig) (*StreamingDistribution, string, error) {
+	resp := &StreamingDistribution{}
+	header, err := c.query("POST", streamingPath, nil, cfg.request(), resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, header.Get("ETag"), nil
+}
+
+// ListStreamingDistributions returns all the streaming distributions of the
+// account, following the pagination of the listing.
+func (c *Client) ListStreamingDistributions() ([]StreamingDistributionSummary, error) {
+	var summaries []StreamingDistributionSummary
+
+	marker := ""
+	for {
+		path := streamingPath
+		if marker != "" {
+			path += "?Marker=" + url.QueryEscape(marker)
+		}
+
+		resp := &streamingDistributionList{}
+		if _, err := c.query("GET", path, nil, nil, resp); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, resp.Items...)
+
+		if !resp.IsTruncated || resp.NextMarker == "" {
+			return summaries, nil
+		}
+		marker = resp.NextMarker
+	}
+}
+
+// GetStreamingDistribution returns the streaming distribution id, and its
+// ETag which must be passed along when updating or deleting it.
+func (c *Client) GetStreamingDistribution(id string) (*StreamingDistribution, string, error) {
+	resp := &StreamingDistribution{}
+	header, err := c.query("GET", streamingPath+"/"+id, nil, nil, resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, header.Get("ETag"), nil
+}
+
+// UpdateStreamingDistribution replaces the configuration of the streaming
+// distribution id by cfg, like UpdateDistribution does for web
+// distributions. The updated distribution and its new ETag are returned.
+func (c *Client) UpdateStreamingDistribution(id string, cfg *StreamingDistributionConfig, etag string) (*StreamingDistribution, string, error) {
+	resp := &StreamingDistribution{}
+	headers := map[string]string{"If-Match": etag}
+	header, err := c.query("PUT", streamingPath+"/"+id+"/config", headers, cfg.request(), resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, header.Get("ETag"), nil
+}
+
+// DeleteStreamingDistribution deletes the streaming distribution id, which
+// must be disabled and deployed. etag is the ETag of the distribution
+// returned when it was last read or updated.
+func (c *Client) DeleteStreamingDistribution(id, etag string) error {
+	headers := map[string]string{"If-Match": etag}
+	_, err := c.query("DELETE", streamingPath+"/"+id, headers, nil, nil)
+	return err
+}
@@ -0,0 +1,209 @@
+package cloudfront
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func testStreamingDistributionConfig() *StreamingDistributionConfig {
+	return &StreamingDistributionConfig{
+		CallerReference: "media-2014",
+		S3Origin: S3Origin{
+			DomainName:           "mediabucket.s3.amazonaws.com",
+			OriginAccessIdentity: "origin-access-identity/cloudfront/E74FTE3AEXAMPLE",
+		},
+		Aliases: Aliases{Quantity: 1, Items: []string{"media.example.com"}},
+		Comment: "Media streaming",
+		Logging: StreamingLogging{Enabled: true, Bucket: "logs.s3.amazonaws.com", Prefix: "rtmp/"},
+		TrustedSigners: TrustedSigners{
+			Enabled:  true,
+			Quantity: 1,
+			Items:    []string{"self"},
+		},
+		PriceClass: "PriceClass_All",
+		Enabled:    true,
+	}
+}
+
+const testStreamingDistributionConfigXML = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+	`<StreamingDistributionConfig xmlns="http://cloudfront.amazonaws.com/doc/2014-11-06/">` +
+	`<CallerReference>media-2014</CallerReference>` +
+	`<S3Origin><DomainName>mediabucket.s3.amazonaws.com</DomainName>` +
+	`<OriginAccessIdentity>origin-access-identity/cloudfront/E74FTE3AEXAMPLE</OriginAccessIdentity></S3Origin>` +
+	`<Aliases><Quantity>1</Quantity><Items><CNAME>media.example.com</CNAME></Items></Aliases>` +
+	`<Comment>Media streaming</Comment>` +
+	`<Logging><Enabled>true</Enabled><Bucket>logs.s3.amazonaws.com</Bucket><Prefix>rtmp/</Prefix></Logging>` +
+	`<TrustedSigners><Enabled>true</Enabled><Quantity>1</Quantity><Items><AwsAccountNumber>self</AwsAccountNumber></Items></TrustedSigners>` +
+	`<PriceClass>PriceClass_All</PriceClass><Enabled>true</Enabled>` +
+	`</StreamingDistributionConfig>`
+
+func TestCreateStreamingDistribution(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{
+		Status:  201,
+		Headers: map[string]string{"ETag": "E2QWRUHEXAMPLE"},
+		Body:    StreamingDistributionResponse,
+	})
+	defer api.Close()
+
+	d, etag, err := client.CreateStreamingDistribution(testStreamingDistributionConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := api.requests[0]
+	if req.Method != "POST" || req.Path != "/2014-11-06/streaming-distribution" {
+		t.Fatalf("Unexpected request: %s %s", req.Method, req.Path)
+	}
+	if req.Body != testStreamingDistributionConfigXML {
+		t.Fatalf("Unexpected body:\n%s\nexpected:\n%s", req.Body, testStreamingDistributionConfigXML)
+	}
+
+	if etag != "E2QWRUHEXAMPLE" || d.Id != "EGTXBD79EXAMPLE" || d.Status != DistributionInProgress {
+		t.Fatalf("Unexpected distribution: %+v (ETag %s)", d, etag)
+	}
+	if d.DomainName != "s5c39gqb8ow64r.cloudfront.net" {
+		t.Fatalf("Unexpected domain name: %s", d.DomainName)
+	}
+	if !reflect.DeepEqual(d.ActiveTrustedSigners.Items[0].KeyPairIds.Items, []string{"APKAI72T5DYBXEXAMPLE"}) {
+		t.Fatalf("Unexpected active trusted signers: %+v", d.ActiveTrustedSigners)
+	}
+
+	cfg := d.StreamingDistributionConfig
+	cfg.XMLName = testStreamingDistributionConfig().XMLName
+	if !reflect.DeepEqual(&cfg, testStreamingDistributionConfig()) {
+		t.Fatalf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestStreamingDistributionQuantities(t *testing.T) {
+	api, client := newTestAPI(t,
+		testResponse{Status: 201, Body: StreamingDistributionResponse},
+		testResponse{Status: 200, Body: StreamingDistributionResponse},
+	)
+	defer api.Close()
+
+	cfg := testStreamingDistributionConfig()
+	cfg.Aliases = Aliases{Items: []string{"media.example.com", "rtmp.example.com"}}
+	cfg.TrustedSigners = TrustedSigners{Enabled: true, Quantity: 3, Items: []string{"self"}}
+	if _, _, err := client.CreateStreamingDistribution(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.UpdateStreamingDistribution("EGTXBD79EXAMPLE", cfg, "E2QWRUHEXAMPLE"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, req := range api.requests {
+		sent := StreamingDistributionConfig{}
+		if err := xml.Unmarshal([]byte(req.Body), &sent); err != nil {
+			t.Fatal(err)
+		}
+		if sent.Aliases.Quantity != 2 || sent.TrustedSigners.Quantity != 1 {
+			t.Fatalf("Quantities not set: %+v", sent)
+		}
+	}
+
+	// The caller's configuration is left untouched.
+	if cfg.Aliases.Quantity != 0 || cfg.TrustedSigners.Quantity != 3 {
+		t.Fatalf("Configuration was modified: %+v", cfg)
+	}
+}
+
+func TestListStreamingDistributions(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 200, Body: ListStreamingDistributionsResponse})
+	defer api.Close()
+
+	summaries, err := client.ListStreamingDistributions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.requests[0].Path != "/2014-11-06/streaming-distribution" {
+		t.Fatalf("Unexpected request: %s", api.requests[0].Path)
+	}
+
+	if len(summaries) != 1 {
+		t.Fatalf("Expected 1 streaming distribution, got %d", len(summaries))
+	}
+	d := summaries[0]
+	if d.Id != "EGTXBD79EXAMPLE" || d.Status != DistributionDeployed || !d.Enabled {
+		t.Fatalf("Unexpected streaming distribution: %+v", d)
+	}
+	if d.S3Origin.DomainName != "mediabucket.s3.amazonaws.com" || !d.TrustedSigners.Enabled {
+		t.Fatalf("Unexpected streaming distribution: %+v", d)
+	}
+}
+
+func TestGetStreamingDistribution(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{
+		Status:  200,
+		Headers: map[string]string{"ETag": "E2QWRUHEXAMPLE"},
+		Body:    StreamingDistributionResponse,
+	})
+	defer api.Close()
+
+	d, etag, err := client.GetStreamingDistribution("EGTXBD79EXAMPLE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.requests[0].Method != "GET" || api.requests[0].Path != "/2014-11-06/streaming-distribution/EGTXBD79EXAMPLE" {
+		t.Fatalf("Unexpected request: %s %s", api.requests[0].Method, api.requests[0].Path)
+	}
+	if etag != "E2QWRUHEXAMPLE" || d.StreamingDistributionConfig.Comment != "Media streaming" {
+		t.Fatalf("Unexpected distribution: %+v (ETag %s)", d, etag)
+	}
+}
+
+func TestUpdateStreamingDistribution(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{
+		Status:  200,
+		Headers: map[string]string{"ETag": "E3NEWETAGEXAMPLE"},
+		Body:    StreamingDistributionResponse,
+	})
+	defer api.Close()
+
+	_, etag, err := client.UpdateStreamingDistribution("EGTXBD79EXAMPLE", testStreamingDistributionConfig(), "E2QWRUHEXAMPLE")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := api.requests[0]
+	if req.Method != "PUT" || req.Path != "/2014-11-06/streaming-distribution/EGTXBD79EXAMPLE/config" {
+		t.Fatalf("Unexpected request: %s %s", req.Method, req.Path)
+	}
+	if req.Header.Get("If-Match") != "E2QWRUHEXAMPLE" {
+		t.Fatalf("Unexpected If-Match header: %s", req.Header.Get("If-Match"))
+	}
+	if req.Body != testStreamingDistributionConfigXML {
+		t.Fatalf("Unexpected body:\n%s\nexpected:\n%s", req.Body, testStreamingDistributionConfigXML)
+	}
+	if etag != "E3NEWETAGEXAMPLE" {
+		t.Fatalf("Unexpected ETag: %s", etag)
+	}
+}
+
+func TestUpdateStreamingDistributionPreconditionFailed(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 412, Body: PreconditionFailedResponse})
+	defer api.Close()
+
+	_, _, err := client.UpdateStreamingDistribution("EGTXBD79EXAMPLE", testStreamingDistributionConfig(), "stale")
+	if _, ok := err.(*PreconditionFailedError); !ok {
+		t.Fatalf("Expected a *PreconditionFailedError, got %#v", err)
+	}
+}
+
+func TestDeleteStreamingDistribution(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 204})
+	defer api.Close()
+
+	if err := client.DeleteStreamingDistribution("EGTXBD79EXAMPLE", "E2QWRUHEXAMPLE"); err != nil {
+		t.Fatal(err)
+	}
+
+	req := api.requests[0]
+	if req.Method != "DELETE" || req.Path != "/2014-11-06/streaming-distribution/EGTXBD79EXAMPLE" {
+		t.Fatalf("Unexpected request: %s %s", req.Method, req.Path)
+	}
+	if req.Header.Get("If-Match") != "E2QWRUHEXAMPLE" {
+		t.Fatalf("Unexpected If-Match header: %s", req.Header.Get("If-Match"))
+	}
+}
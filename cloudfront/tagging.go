@@ -0,0 +1,86 @@
+package cloudfront
+
+import (
+	"encoding/xml"
+	"net/url"
+	"sort"
+)
+
+// TaggingAPIVersion is the version of the CloudFront API used for tagging,
+// which is not available in APIVersion.
+const TaggingAPIVersion = "2015-07-27"
+
+const taggingXmlns = "http://cloudfront.amazonaws.com/doc/" + TaggingAPIVersion + "/"
+
+// Tag is a key and value pair attached to a CloudFront resource.
+type Tag struct {
+	Key   string
+	Value string
+}
+
+type tags struct {
+	XMLName xml.Name
+	Items   []Tag `xml:"Items>Tag"`
+}
+
+type tagKeys struct {
+	XMLName xml.Name
+	Items   []string `xml:"Items>Key"`
+}
+
+// DistributionARN returns the ARN of the distribution distributionId owned
+// by the AWS account accountId, which identifies it to the tagging calls.
+func DistributionARN(accountId, distributionId string) string {
+	return "arn:aws:cloudfront::" + accountId + ":distribution/" + distributionId
+}
+
+// TagResource adds tags to the resource arn, replacing the values of the
+// tags it already has with the same keys.
+func (c *Client) TagResource(arn string, tagMap map[string]string) error {
+	keys := make([]string, 0, len(tagMap))
+	for k := range tagMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	req := &tags{XMLName: xml.Name{Space: taggingXmlns, Local: "Tags"}}
+	for _, k := range keys {
+		req.Items = append(req.Items, Tag{Key: k, Value: tagMap[k]})
+	}
+
+	_, err := c.queryVersion(TaggingAPIVersion, "POST", taggingPath("Tag", arn), nil, req, nil)
+	return err
+}
+
+// UntagResource removes the tags with the given keys from the resource arn.
+func (c *Client) UntagResource(arn string, keys []string) error {
+	req := &tagKeys{
+		XMLName: xml.Name{Space: taggingXmlns, Local: "TagKeys"},
+		Items:   keys,
+	}
+
+	_, err := c.queryVersion(TaggingAPIVersion, "POST", taggingPath("Untag", arn), nil, req, nil)
+	return err
+}
+
+// ListTagsForResource returns the tags of the resource arn.
+func (c *Client) ListTagsForResource(arn string) (map[string]string, error) {
+	resp := &tags{}
+	if _, err := c.queryVersion(TaggingAPIVersion, "GET", taggingPath("", arn), nil, nil, resp); err != nil {
+		return nil, err
+	}
+
+	tagMap := make(map[string]string, len(resp.Items))
+	for _, tag := range resp.Items {
+		tagMap[tag.Key] = tag.Value
+	}
+	return tagMap, nil
+}
+
+func taggingPath(operation, arn string) string {
+	params := url.Values{"Resource": {arn}}
+	if operation != "" {
+		params.Set("Operation", operation)
+	}
+	return "/tagging?" + params.Encode()
+}
@@ -0,0 +1,94 @@
+package cloudfront
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+const testDistributionARN = "arn:aws:cloudfront::123456789012:distribution/EDFDVBD6EXAMPLE"
+
+func TestDistributionARN(t *testing.T) {
+	if arn := DistributionARN("123456789012", "EDFDVBD6EXAMPLE"); arn != testDistributionARN {
+		t.Fatalf("Unexpected ARN %s", arn)
+	}
+}
+
+func checkTaggingRequest(t *testing.T, req recordedRequest, method, operation string) {
+	if req.Method != method || req.Path != "/2015-07-27/tagging" {
+		t.Fatalf("Unexpected request: %s %s", req.Method, req.Path)
+	}
+	query, err := url.ParseQuery(req.Query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("Resource") != testDistributionARN || query.Get("Operation") != operation {
+		t.Fatalf("Unexpected query: %s", req.Query)
+	}
+}
+
+func TestTagResource(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 204})
+	defer api.Close()
+
+	err := client.TagResource(testDistributionARN, map[string]string{"environment": "production", "cost-center": "media"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := api.requests[0]
+	checkTaggingRequest(t, req, "POST", "Tag")
+	expected := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<Tags xmlns="http://cloudfront.amazonaws.com/doc/2015-07-27/"><Items>` +
+		`<Tag><Key>cost-center</Key><Value>media</Value></Tag>` +
+		`<Tag><Key>environment</Key><Value>production</Value></Tag>` +
+		`</Items></Tags>`
+	if req.Body != expected {
+		t.Fatalf("Unexpected body:\n%s\nexpected:\n%s", req.Body, expected)
+	}
+}
+
+func TestUntagResource(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 204})
+	defer api.Close()
+
+	if err := client.UntagResource(testDistributionARN, []string{"environment", "cost-center"}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := api.requests[0]
+	checkTaggingRequest(t, req, "POST", "Untag")
+	expected := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<TagKeys xmlns="http://cloudfront.amazonaws.com/doc/2015-07-27/"><Items>` +
+		`<Key>environment</Key><Key>cost-center</Key>` +
+		`</Items></TagKeys>`
+	if req.Body != expected {
+		t.Fatalf("Unexpected body:\n%s\nexpected:\n%s", req.Body, expected)
+	}
+}
+
+func TestListTagsForResource(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 200, Body: ListTagsForResourceResponse})
+	defer api.Close()
+
+	tags, err := client.ListTagsForResource(testDistributionARN)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkTaggingRequest(t, api.requests[0], "GET", "")
+	expected := map[string]string{"cost-center": "media", "environment": "production"}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Fatalf("Unexpected tags: %v", tags)
+	}
+}
+
+func TestListTagsForResourceError(t *testing.T) {
+	api, client := newTestAPI(t, testResponse{Status: 404, Body: NoSuchDistributionResponse})
+	defer api.Close()
+
+	_, err := client.ListTagsForResource(testDistributionARN)
+	if cferr, ok := err.(*Error); !ok || cferr.Code != "NoSuchDistribution" {
+		t.Fatalf("Expected a NoSuchDistribution error, got %#v", err)
+	}
+}
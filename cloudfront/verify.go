@@ -0,0 +1,225 @@
+package cloudfront
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned by Verifier when a signed URL or signed cookies are not
+// valid. Errors about malformed input are returned as is.
+var (
+	ErrExpired           = errors.New("cloudfront: policy has expired")
+	ErrNotYetValid       = errors.New("cloudfront: policy is not valid yet")
+	ErrSignatureMismatch = errors.New("cloudfront: signature does not match policy")
+	ErrResourceMismatch  = errors.New("cloudfront: policy does not grant access to resource")
+	ErrKeyPairMismatch   = errors.New("cloudfront: unknown key pair id")
+)
+
+var base64Restorer = strings.NewReplacer("_", "=", "-", "+", "~", "/")
+
+// Verifier checks signed URLs and signed cookies generated with the private
+// key of a CloudFront key pair, the way CloudFront would.
+//
+// Verifier has no knowledge of the client, so IpAddress conditions of custom
+// policies are not checked.
+type Verifier struct {
+	// Clock tells the time expirations are checked against. It defaults to
+	// the system clock.
+	Clock Clock
+
+	keys map[string]*rsa.PublicKey
+}
+
+func (v *Verifier) now() time.Time {
+	if v.Clock == nil {
+		return systemClock{}.Now()
+	}
+	return v.Clock.Now()
+}
+
+// NewVerifier returns a Verifier accepting signatures made by the key pair
+// keyPairId, whose public key is key.
+func NewVerifier(key *rsa.PublicKey, keyPairId string) *Verifier {
+	return &Verifier{
+		keys: map[string]*rsa.PublicKey{keyPairId: key},
+	}
+}
+
+// AddKey makes v also accept signatures made by the key pair keyPairId,
+// whose public key is key.
+func (v *Verifier) AddKey(key *rsa.PublicKey, keyPairId string) {
+	v.keys[keyPairId] = key
+}
+
+// VerifyURL checks a signed URL, using either a canned or a custom policy.
+func (v *Verifier) VerifyURL(rawurl string) error {
+	uri, err := url.Parse(rawurl)
+	if err != nil {
+		return err
+	}
+
+	query, err := url.ParseQuery(uri.RawQuery)
+	if err != nil {
+		return err
+	}
+
+	key, err := v.publicKey(query.Get("Key-Pair-Id"))
+	if err != nil {
+		return err
+	}
+
+	signature := query.Get("Signature")
+	if signature == "" {
+		return errors.New("cloudfront: URL is not signed")
+	}
+
+	resource := *uri
+	resource.RawQuery = stripReservedParams(uri.RawQuery)
+
+	var p []byte
+	if b64Policy := query.Get("Policy"); b64Policy != "" {
+		if p, err = decodeBase64(b64Policy); err != nil {
+			return err
+		}
+	} else {
+		expires, err := strconv.ParseInt(query.Get("Expires"), 10, 64)
+		if err != nil {
+			return errors.New("cloudfront: URL has neither a valid Expires nor a Policy parameter")
+		}
+		if p, err = NewCannedPolicy(resource.String(), time.Unix(expires, 0)).JSON(); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := verifyPolicy(key, p, signature, v.now())
+	if err != nil {
+		return err
+	}
+
+	if !matchResource(parsed.Statement[0].Resource, resource.String()) {
+		return ErrResourceMismatch
+	}
+	return nil
+}
+
+// VerifyCookies checks the values of the CloudFront-Policy,
+// CloudFront-Signature and CloudFront-Key-Pair-Id cookies.
+func (v *Verifier) VerifyCookies(policy, signature, keyPairId string) error {
+	key, err := v.publicKey(keyPairId)
+	if err != nil {
+		return err
+	}
+
+	p, err := decodeBase64(policy)
+	if err != nil {
+		return err
+	}
+
+	_, err = verifyPolicy(key, p, signature, v.now())
+	return err
+}
+
+func (v *Verifier) publicKey(keyPairId string) (*rsa.PublicKey, error) {
+	key, ok := v.keys[keyPairId]
+	if !ok {
+		return nil, ErrKeyPairMismatch
+	}
+	return key, nil
+}
+
+// verifyPolicy checks the signature of the JSON policy p with key and its
+// date conditions, and returns the parsed policy.
+func verifyPolicy(key *rsa.PublicKey, p []byte, b64Signature string, t time.Time) (*policyDocument, error) {
+	signature, err := decodeBase64(b64Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	hashed := sha1.Sum(p)
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA1, hashed[:], signature); err != nil {
+		return nil, ErrSignatureMismatch
+	}
+
+	parsed := &policyDocument{}
+	if err := json.Unmarshal(p, parsed); err != nil {
+		return nil, err
+	}
+	if len(parsed.Statement) != 1 {
+		return nil, errors.New("cloudfront: policy must have exactly one statement")
+	}
+
+	now := t.Unix()
+	cond := parsed.Statement[0].Condition
+	if now >= cond.DateLessThan.EpochTime {
+		return nil, ErrExpired
+	}
+	if cond.DateGreaterThan != nil && now < cond.DateGreaterThan.EpochTime {
+		return nil, ErrNotYetValid
+	}
+	return parsed, nil
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(base64Restorer.Replace(s))
+}
+
+// stripReservedParams removes the signature parameters from a raw query
+// string, keeping the order of the remaining ones.
+func stripReservedParams(rawQuery string) string {
+	kept := []string{}
+	for _, kv := range strings.Split(rawQuery, "&") {
+		if kv == "" {
+			continue
+		}
+		key := kv
+		if i := strings.Index(kv, "="); i >= 0 {
+			key = kv[:i]
+		}
+		reserved := false
+		for _, param := range reservedParams {
+			if key == param {
+				reserved = true
+				break
+			}
+		}
+		if !reserved {
+			kept = append(kept, kv)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+// matchResource reports whether resource matches pattern, where '*' in
+// pattern matches any sequence of characters and '?' any single character.
+func matchResource(pattern, resource string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(resource); i >= 0; i-- {
+				if matchResource(pattern[1:], resource[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if resource == "" {
+				return false
+			}
+		default:
+			if resource == "" || pattern[0] != resource[0] {
+				return false
+			}
+		}
+		pattern = pattern[1:]
+		resource = resource[1:]
+	}
+	return resource == ""
+}
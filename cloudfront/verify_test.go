@@ -0,0 +1,235 @@
+package cloudfront
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func testVerifier(t *testing.T) *Verifier {
+	return NewVerifier(testPublicKey(t), "test-key-pair-1231245")
+}
+
+func TestVerifyCannedURL(t *testing.T) {
+	v := testVerifier(t)
+
+	for _, query := range []string{"", "test=value", "a=1&b=2"} {
+		cf := testCloudFront(t, "https://cloudfront.com/")
+		uri, err := cf.CannedSignedURL("/my dir/test", query, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := v.VerifyURL(uri); err != nil {
+			t.Errorf("%s: %v", uri, err)
+		}
+	}
+}
+
+func TestVerifyCustomPolicyURL(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com")
+	v := testVerifier(t)
+
+	uri, err := cf.SignedURLWithPolicy("test", "a=1", PolicyOptions{
+		Expires:    time.Now().Add(time.Hour),
+		ActiveFrom: time.Now().Add(-time.Hour),
+		SourceIP:   "192.0.2.0/24",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.VerifyURL(uri); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyURLErrors(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com")
+	cf.Clock = &fakeClock{time.Now().Add(-2 * time.Hour)}
+	v := testVerifier(t)
+
+	expired, err := cf.CannedSignedURL("test", "", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.VerifyURL(expired); err != ErrExpired {
+		t.Errorf("Expected ErrExpired, got %v", err)
+	}
+
+	notYetValid, err := cf.SignedURLWithPolicy("test", "", PolicyOptions{
+		Expires:    time.Now().Add(2 * time.Hour),
+		ActiveFrom: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.VerifyURL(notYetValid); err != ErrNotYetValid {
+		t.Errorf("Expected ErrNotYetValid, got %v", err)
+	}
+
+	valid, err := cf.CannedSignedURL("test", "a=1", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := strings.Replace(valid, "a=1", "a=2", 1)
+	if err := v.VerifyURL(tampered); err != ErrSignatureMismatch {
+		t.Errorf("Expected ErrSignatureMismatch, got %v", err)
+	}
+
+	otherKeyPair := strings.Replace(valid, "Key-Pair-Id=test-key-pair-1231245", "Key-Pair-Id=other", 1)
+	if err := v.VerifyURL(otherKeyPair); err != ErrKeyPairMismatch {
+		t.Errorf("Expected ErrKeyPairMismatch, got %v", err)
+	}
+
+	if err := v.VerifyURL("https://cloudfront.com/test"); err == nil {
+		t.Error("Expected an error for an unsigned URL")
+	}
+}
+
+func TestVerifyURLResourceMismatch(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com")
+	v := testVerifier(t)
+
+	uri, err := cf.SignedURLWithPolicy("test", "", PolicyOptions{Expires: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := strings.Replace(uri, "/test?", "/other?", 1)
+	if err := v.VerifyURL(other); err != ErrResourceMismatch {
+		t.Fatalf("Expected ErrResourceMismatch, got %v", err)
+	}
+}
+
+func TestVerifyCookies(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com")
+	cf.Clock = &fakeClock{time.Now().Add(-2 * time.Hour)}
+	v := testVerifier(t)
+
+	policy, signature, keyPairId, err := cf.Cookie("test", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.VerifyCookies(policy, signature, keyPairId); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, signature, keyPairId, err = cf.CookieWithPolicy("https://cloudfront.com/videos/*", PolicyOptions{
+		Expires: time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.VerifyCookies(policy, signature, keyPairId); err != ErrExpired {
+		t.Fatalf("Expected ErrExpired, got %v", err)
+	}
+
+	_, otherSignature, _, err := cf.Cookie("other", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.VerifyCookies(policy, otherSignature, keyPairId); err != ErrSignatureMismatch {
+		t.Fatalf("Expected ErrSignatureMismatch, got %v", err)
+	}
+}
+
+func TestVerifyClock(t *testing.T) {
+	cf := testCloudFront(t, "https://cloudfront.com")
+	cf.Clock = testClock()
+	expires := time.Unix(1396015221, 0)
+
+	uri, err := cf.CannedSignedURL("test", "", expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The URL expired long ago by the system clock, not by the one of cf.
+	if err := testVerifier(t).VerifyURL(uri); err != ErrExpired {
+		t.Errorf("Expected ErrExpired, got %v", err)
+	}
+	v, err := cf.Verifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.VerifyURL(uri); err != nil {
+		t.Error(err)
+	}
+	v.Clock = &fakeClock{expires}
+	if err := v.VerifyURL(uri); err != ErrExpired {
+		t.Errorf("Expected ErrExpired, got %v", err)
+	}
+}
+
+func TestVerifyRotatedKeyPairs(t *testing.T) {
+	newKey, err := LoadKeyFromPEM(readTestData(t, "key-2.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cf := testCloudFront(t, "https://cloudfront.com")
+	expires := time.Now().Add(time.Hour)
+
+	oldURL, err := cf.CannedSignedURL("test", "", expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cf.AddKeyPair("new-key-pair", newKey)
+	if err := cf.SetDefaultKeyPair("new-key-pair"); err != nil {
+		t.Fatal(err)
+	}
+	newURL, err := cf.CannedSignedURL("test", "", expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(newURL, "&Key-Pair-Id=new-key-pair") {
+		t.Fatalf("Expected the new key pair to be the default: %s", newURL)
+	}
+
+	v, err := cf.Verifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uri := range []string{oldURL, newURL} {
+		if err := v.VerifyURL(uri); err != nil {
+			t.Errorf("%s: %v", uri, err)
+		}
+	}
+
+	v = testVerifier(t)
+	if err := v.VerifyURL(newURL); err != ErrKeyPairMismatch {
+		t.Fatalf("Expected ErrKeyPairMismatch before adding the new key, got %v", err)
+	}
+	v.AddKey(&newKey.PublicKey, "new-key-pair")
+	if err := v.VerifyURL(newURL); err != nil {
+		t.Fatal(err)
+	}
+
+	// A URL claiming to be signed by the old key pair with the new key is
+	// rejected.
+	forged := strings.Replace(newURL, "new-key-pair", "test-key-pair-1231245", 1)
+	if err := v.VerifyURL(forged); err != ErrSignatureMismatch {
+		t.Fatalf("Expected ErrSignatureMismatch, got %v", err)
+	}
+}
+
+func TestMatchResource(t *testing.T) {
+	tests := []struct {
+		pattern, resource string
+		match             bool
+	}{
+		{"*", "https://cloudfront.com/test", true},
+		{"https://cloudfront.com/videos/*", "https://cloudfront.com/videos/1234/seg1.ts", true},
+		{"https://cloudfront.com/videos/*", "https://cloudfront.com/images/1.png", false},
+		{"https://cloudfront.com/seg?.ts", "https://cloudfront.com/seg1.ts", true},
+		{"https://cloudfront.com/seg?.ts", "https://cloudfront.com/seg10.ts", false},
+		{"https://cloudfront.com/test", "https://cloudfront.com/test", true},
+		{"https://cloudfront.com/test", "https://cloudfront.com/test2", false},
+	}
+
+	for _, test := range tests {
+		if matchResource(test.pattern, test.resource) != test.match {
+			t.Errorf("matchResource(%q, %q) != %v", test.pattern, test.resource, test.match)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package dynamodb
 
 import (
+	"encoding/base64"
 	"strconv"
 )
 
@@ -9,6 +10,9 @@ const (
 	TYPE_NUMBER = "N"
 	TYPE_BINARY = "B"
 	TYPE_BOOL   = "BOOL"
+	TYPE_NULL   = "NULL"
+	TYPE_LIST   = "L"
+	TYPE_MAP    = "M"
 
 	TYPE_STRING_SET = "SS"
 	TYPE_NUMBER_SET = "NS"
@@ -39,11 +43,18 @@ type PrimaryKey struct {
 	RangeAttribute *Attribute
 }
 
+// Attribute is a named attribute of an item. Strings and numbers are in
+// Value as written, numbers keeping their precision, binaries are in Value
+// in base64, and booleans as "true" or "false". Sets are in SetValues in
+// the same way, the elements of lists in List and the attributes of maps
+// in Map by name. NULL attributes have no value.
 type Attribute struct {
 	Type      string
 	Name      string
 	Value     string
 	SetValues []string
+	List      []Attribute
+	Map       map[string]*Attribute
 	Exists    string // exists on dynamodb? Values: "true", "false", or ""
 }
 
@@ -53,6 +64,14 @@ type AttributeComparison struct {
 	AttributeValueList []Attribute // contains attributes with only types and names (value ignored)
 }
 
+// NewAttributeComparison returns a comparison of an attribute with values,
+// as many as the operator takes: none for COMPARISON_ATTRIBUTE_EXISTS and
+// COMPARISON_ATTRIBUTE_DOES_NOT_EXIST, two for COMPARISON_BETWEEN and any
+// for COMPARISON_IN.
+func NewAttributeComparison(attributeName string, comparisonOperator string, values ...Attribute) *AttributeComparison {
+	return &AttributeComparison{attributeName, comparisonOperator, values}
+}
+
 func NewEqualInt64AttributeComparison(attributeName string, equalToValue int64) *AttributeComparison {
 	numeric := NewNumericAttribute(attributeName, strconv.FormatInt(equalToValue, 10))
 	return &AttributeComparison{attributeName,
@@ -93,6 +112,14 @@ func NewBinaryAttributeComparison(attributeName string, comparisonOperator strin
 	}
 }
 
+func NewBooleanAttributeComparison(attributeName string, comparisonOperator string, value bool) *AttributeComparison {
+	return NewAttributeComparison(attributeName, comparisonOperator, *NewBooleanAttribute(attributeName, value))
+}
+
+func NewBytesAttributeComparison(attributeName string, comparisonOperator string, value []byte) *AttributeComparison {
+	return NewAttributeComparison(attributeName, comparisonOperator, *NewBytesAttribute(attributeName, value))
+}
+
 func NewStringAttribute(name string, value string) *Attribute {
 	return &Attribute{
 		Type:  TYPE_STRING,
@@ -117,6 +144,12 @@ func NewBinaryAttribute(name string, value string) *Attribute {
 	}
 }
 
+// NewBytesAttribute returns a binary attribute with value, encoded in
+// base64.
+func NewBytesAttribute(name string, value []byte) *Attribute {
+	return NewBinaryAttribute(name, base64.StdEncoding.EncodeToString(value))
+}
+
 func NewBooleanAttribute(name string, value bool) *Attribute {
 	return &Attribute{
 		Type:  TYPE_BOOL,
@@ -149,6 +182,95 @@ func NewBinarySetAttribute(name string, values []string) *Attribute {
 	}
 }
 
+// NewBytesSetAttribute returns a binary set attribute with values, encoded
+// in base64.
+func NewBytesSetAttribute(name string, values [][]byte) *Attribute {
+	encoded := make([]string, len(values))
+	for i, v := range values {
+		encoded[i] = base64.StdEncoding.EncodeToString(v)
+	}
+	return NewBinarySetAttribute(name, encoded)
+}
+
+func NewNullAttribute(name string) *Attribute {
+	return &Attribute{
+		Type: TYPE_NULL,
+		Name: name,
+	}
+}
+
+// NewListAttribute returns a list attribute of values, whose names are
+// ignored.
+func NewListAttribute(name string, values []Attribute) *Attribute {
+	return &Attribute{
+		Type: TYPE_LIST,
+		Name: name,
+		List: values,
+	}
+}
+
+// NewMapAttribute returns a map attribute of attributes, by their names.
+func NewMapAttribute(name string, attributes []Attribute) *Attribute {
+	m := make(map[string]*Attribute, len(attributes))
+	for i := range attributes {
+		m[attributes[i].Name] = &attributes[i]
+	}
+	return &Attribute{
+		Type: TYPE_MAP,
+		Name: name,
+		Map:  m,
+	}
+}
+
+// Bytes decodes the value of a binary attribute.
+func (a *Attribute) Bytes() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(a.Value)
+}
+
+// BinarySetValues decodes the values of a binary set attribute.
+func (a *Attribute) BinarySetValues() ([][]byte, error) {
+	values := make([][]byte, len(a.SetValues))
+	for i, v := range a.SetValues {
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = b
+	}
+	return values, nil
+}
+
+// wireValue returns the value of the attribute as sent to DynamoDB, keyed
+// by its type.
+func (a *Attribute) wireValue() msi {
+	var v interface{}
+	switch a.Type {
+	case TYPE_BOOL:
+		v = a.Value == "true"
+	case TYPE_NULL:
+		v = true
+	case TYPE_LIST:
+		list := make([]interface{}, len(a.List))
+		for i := range a.List {
+			list[i] = a.List[i].wireValue()
+		}
+		v = list
+	case TYPE_MAP:
+		m := msi{}
+		for name, attribute := range a.Map {
+			m[name] = attribute.wireValue()
+		}
+		v = m
+	default:
+		if a.SetType() {
+			v = a.SetValues
+		} else {
+			v = a.Value
+		}
+	}
+	return msi{a.Type: v}
+}
+
 func (a *Attribute) SetType() bool {
 	switch a.Type {
 	case TYPE_BINARY_SET, TYPE_NUMBER_SET, TYPE_STRING_SET:
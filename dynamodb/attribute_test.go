@@ -0,0 +1,150 @@
+package dynamodb_test
+
+import (
+	"encoding/json"
+
+	"github.com/goamz/goamz/dynamodb"
+	. "gopkg.in/check.v1"
+)
+
+type AttributeSuite struct{}
+
+var _ = Suite(&AttributeSuite{})
+
+// capturedItem is an item with attributes of every type, as returned by
+// DynamoDB.
+const capturedItem = `{
+	"Id": {"S": "u1"},
+	"Balance": {"N": "12345678901234567890.123456789"},
+	"Avatar": {"B": "iVBORw0KGgo="},
+	"Verified": {"BOOL": false},
+	"Nickname": {"NULL": true},
+	"Tags": {"SS": ["a", "b"]},
+	"Scores": {"NS": ["1", "2.5", "-3E+2"]},
+	"Keys": {"BS": ["AAE=", "/w=="]},
+	"Logins": {"L": [{"N": "1"}, {"S": "two"}, {"L": [{"BOOL": true}]}, {"M": {"at": {"N": "3"}}}]},
+	"Profile": {"M": {
+		"Age": {"N": "42"},
+		"Address": {"M": {"City": {"S": "Paris"}, "Zip": {"NULL": true}}},
+		"Photos": {"BS": ["AQID"]}
+	}}
+}`
+
+func (s *AttributeSuite) TestAttributeRoundTrip(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		if r.Target == "DynamoDB_20120810.GetItem" {
+			return 200, `{"Item": ` + capturedItem + `}`
+		}
+		return 200, `{}`
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	item, err := users.GetItem(&dynamodb.Key{HashKey: "u1"})
+	c.Assert(err, IsNil)
+	c.Assert(item, HasLen, 10)
+	c.Assert(item["Balance"], DeepEquals, dynamodb.NewNumericAttribute("Balance", "12345678901234567890.123456789"))
+	avatar, err := item["Avatar"].Bytes()
+	c.Assert(err, IsNil)
+	c.Assert(avatar, DeepEquals, []byte("\x89PNG\r\n\x1a\n"))
+	c.Assert(item["Verified"], DeepEquals, dynamodb.NewBooleanAttribute("Verified", false))
+	c.Assert(item["Nickname"], DeepEquals, dynamodb.NewNullAttribute("Nickname"))
+	c.Assert(item["Scores"].SetValues, DeepEquals, []string{"1", "2.5", "-3E+2"})
+	keys, err := item["Keys"].BinarySetValues()
+	c.Assert(err, IsNil)
+	c.Assert(keys, DeepEquals, [][]byte{{0, 1}, {0xff}})
+	c.Assert(item["Logins"], DeepEquals, dynamodb.NewListAttribute("Logins", []dynamodb.Attribute{
+		*dynamodb.NewNumericAttribute("", "1"),
+		*dynamodb.NewStringAttribute("", "two"),
+		*dynamodb.NewListAttribute("", []dynamodb.Attribute{*dynamodb.NewBooleanAttribute("", true)}),
+		*dynamodb.NewMapAttribute("", []dynamodb.Attribute{*dynamodb.NewNumericAttribute("at", "3")}),
+	}))
+	profile := item["Profile"].Map
+	c.Assert(profile["Age"].Value, Equals, "42")
+	c.Assert(profile["Address"].Map["Zip"], DeepEquals, dynamodb.NewNullAttribute("Zip"))
+	c.Assert(profile["Photos"], DeepEquals, dynamodb.NewBytesSetAttribute("Photos", [][]byte{{1, 2, 3}}))
+
+	// The attributes are written back as they were read
+	var attributes []dynamodb.Attribute
+	for name, a := range item {
+		if name != "Id" {
+			attributes = append(attributes, *a)
+		}
+	}
+	ok, err := users.PutItem("u1", "", attributes)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	var expected map[string]interface{}
+	c.Assert(json.Unmarshal([]byte(capturedItem), &expected), IsNil)
+	c.Assert(req.Body["Item"], DeepEquals, expected)
+}
+
+func (s *AttributeSuite) TestComparisons(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, `{"Count": 0, "Items": []}`
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	_, err := users.Scan([]dynamodb.AttributeComparison{
+		*dynamodb.NewAttributeComparison("Age", dynamodb.COMPARISON_BETWEEN,
+			*dynamodb.NewNumericAttribute("Age", "18"), *dynamodb.NewNumericAttribute("Age", "65")),
+		*dynamodb.NewAttributeComparison("Nickname", dynamodb.COMPARISON_ATTRIBUTE_EXISTS),
+		*dynamodb.NewBooleanAttributeComparison("Verified", dynamodb.COMPARISON_EQUAL, true),
+		*dynamodb.NewBytesAttributeComparison("Avatar", dynamodb.COMPARISON_BEGINS_WITH, []byte{0x89}),
+		*dynamodb.NewAttributeComparison("Tags", dynamodb.COMPARISON_CONTAINS, *dynamodb.NewStringAttribute("Tags", "a")),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(req.Body["ScanFilter"], DeepEquals, map[string]interface{}{
+		"Age": map[string]interface{}{
+			"ComparisonOperator": "BETWEEN",
+			"AttributeValueList": []interface{}{map[string]interface{}{"N": "18"}, map[string]interface{}{"N": "65"}},
+		},
+		"Nickname": map[string]interface{}{
+			"ComparisonOperator": "NOT_NULL",
+			"AttributeValueList": []interface{}{},
+		},
+		"Verified": map[string]interface{}{
+			"ComparisonOperator": "EQ",
+			"AttributeValueList": []interface{}{map[string]interface{}{"BOOL": true}},
+		},
+		"Avatar": map[string]interface{}{
+			"ComparisonOperator": "BEGINS_WITH",
+			"AttributeValueList": []interface{}{map[string]interface{}{"B": "iQ=="}},
+		},
+		"Tags": map[string]interface{}{
+			"ComparisonOperator": "CONTAINS",
+			"AttributeValueList": []interface{}{map[string]interface{}{"S": "a"}},
+		},
+	})
+}
+
+func (s *AttributeSuite) TestUnmarshalAttributes(c *C) {
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		return 200, `{"Item": ` + capturedItem + `}`
+	})
+	defer done()
+	users, _ := batchTables(server)
+	attributes, err := users.GetItem(&dynamodb.Key{HashKey: "u1"})
+	c.Assert(err, IsNil)
+
+	// Booleans are read from BOOL attributes as well as numbers, and NULL
+	// attributes are left alone
+	user := struct {
+		Id       string
+		Verified bool
+		Nickname string
+		Tags     []string
+		Avatar   []byte
+	}{Verified: true, Nickname: "unset"}
+	c.Assert(dynamodb.UnmarshalAttributes(&attributes, &user), IsNil)
+	c.Assert(user.Id, Equals, "u1")
+	c.Assert(user.Verified, Equals, false)
+	c.Assert(user.Nickname, Equals, "unset")
+	c.Assert(user.Tags, DeepEquals, []string{"a", "b"})
+	c.Assert(user.Avatar, DeepEquals, []byte("\x89PNG\r\n\x1a\n"))
+}
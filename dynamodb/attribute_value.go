@@ -0,0 +1,99 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AttributeValue is a value of any of the DynamoDB types, given by Type as
+// one of the TYPE_ constants. The value is in the field for its type:
+// Value for strings and numbers, which are kept as written, Bytes for
+// binaries, Bool for booleans, SetValues for string and number sets,
+// BinarySetValues for binary sets, List for lists and Map for maps. NULL
+// values have none.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_AttributeValue.html for details.
+type AttributeValue struct {
+	Type            string
+	Value           string
+	Bytes           []byte
+	Bool            bool
+	SetValues       []string
+	BinarySetValues [][]byte
+	List            []AttributeValue
+	Map             map[string]AttributeValue
+}
+
+// MarshalJSON encodes the value as sent to DynamoDB, with binaries in
+// base64.
+func (a AttributeValue) MarshalJSON() ([]byte, error) {
+	var v interface{}
+	switch a.Type {
+	case TYPE_STRING, TYPE_NUMBER:
+		v = a.Value
+	case TYPE_BINARY:
+		v = a.Bytes
+	case TYPE_BOOL:
+		v = a.Bool
+	case TYPE_NULL:
+		v = true
+	case TYPE_STRING_SET, TYPE_NUMBER_SET:
+		v = a.SetValues
+	case TYPE_BINARY_SET:
+		v = a.BinarySetValues
+	case TYPE_LIST:
+		list := a.List
+		if list == nil {
+			list = []AttributeValue{}
+		}
+		v = list
+	case TYPE_MAP:
+		m := a.Map
+		if m == nil {
+			m = map[string]AttributeValue{}
+		}
+		v = m
+	default:
+		return nil, fmt.Errorf("Invalid attribute type %q", a.Type)
+	}
+	return json.Marshal(map[string]interface{}{a.Type: v})
+}
+
+// UnmarshalJSON decodes the value as received from DynamoDB.
+func (a *AttributeValue) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) != 1 {
+		return fmt.Errorf("Invalid attribute value %s", data)
+	}
+	*a = AttributeValue{}
+	for t, raw := range fields {
+		a.Type = t
+		var err error
+		switch t {
+		case TYPE_STRING, TYPE_NUMBER:
+			err = json.Unmarshal(raw, &a.Value)
+		case TYPE_BINARY:
+			err = json.Unmarshal(raw, &a.Bytes)
+		case TYPE_BOOL:
+			err = json.Unmarshal(raw, &a.Bool)
+		case TYPE_NULL:
+		case TYPE_STRING_SET, TYPE_NUMBER_SET:
+			err = json.Unmarshal(raw, &a.SetValues)
+		case TYPE_BINARY_SET:
+			err = json.Unmarshal(raw, &a.BinarySetValues)
+		case TYPE_LIST:
+			err = json.Unmarshal(raw, &a.List)
+		case TYPE_MAP:
+			err = json.Unmarshal(raw, &a.Map)
+		default:
+			return fmt.Errorf("Invalid attribute type %q", t)
+		}
+		if err != nil {
+			return fmt.Errorf("Invalid attribute value %s: %v", data, err)
+		}
+	}
+	return nil
+}
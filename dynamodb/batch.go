@@ -0,0 +1,307 @@
+package dynamodb
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	simplejson "github.com/bitly/go-simplejson"
+	"github.com/goamz/goamz/aws"
+)
+
+// BATCH_GET_MAX_KEYS is the most keys a BatchGetItem request reads, and
+// BATCH_WRITE_MAX_ITEMS the most puts and deletes a BatchWriteItem request
+// makes.
+const (
+	BATCH_GET_MAX_KEYS    = 100
+	BATCH_WRITE_MAX_ITEMS = 25
+)
+
+// BatchRetryPolicy is the backoff between the requests of ExecuteAll
+// sending again the keys and items DynamoDB left unprocessed, which gives
+// up after MaxAttempts requests of each batch.
+var BatchRetryPolicy = aws.RetryPolicy{
+	MaxAttempts: 8,
+	BaseDelay:   50 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Jitter:      0.5,
+}
+
+// ErrUnprocessedItems is returned by the batch requests which left keys or
+// items unprocessed.
+var ErrUnprocessedItems = errors.New("One or more unprocessed items.")
+
+// batch sends a batch request with requestItems, and then again with the
+// requests left unprocessed in the field unprocessed of its responses,
+// backing off as given by BatchRetryPolicy, until none is left or the
+// policy gives up. handle is called with every response. It returns the
+// requests left unprocessed, which on errors are the requests failing or
+// nil if the first one failed.
+func (s *Server) batch(target string, requestItems map[string]interface{}, unprocessed string, handle func(json *simplejson.Json, jsonResponse []byte) error) (map[string]interface{}, error) {
+	policy := BatchRetryPolicy
+	var sent map[string]interface{}
+	for attempt := 0; ; attempt++ {
+		q := NewEmptyQuery()
+		q.buffer["RequestItems"] = requestItems
+		jsonResponse, err := s.queryServer(target, q)
+		if err == nil {
+			var json *simplejson.Json
+			json, err = simplejson.NewJson(jsonResponse)
+			if err == nil && handle != nil {
+				err = handle(json, jsonResponse)
+			}
+			if err == nil {
+				requestItems, _ = json.Get(unprocessed).Map()
+			}
+		}
+		if err != nil {
+			return sent, err
+		}
+		sent = requestItems
+		if len(requestItems) == 0 {
+			return nil, nil
+		}
+		if attempt+1 >= policy.MaxAttempts {
+			return requestItems, nil
+		}
+		policy.Backoff(attempt)
+	}
+}
+
+// tablesByName returns the tables of a batch request by name.
+func tablesByName(tables []*Table) map[string]*Table {
+	byName := make(map[string]*Table, len(tables))
+	for _, t := range tables {
+		byName[t.Name] = t
+	}
+	return byName
+}
+
+func sortTables(tables []*Table) {
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+}
+
+func (batchGetItem *BatchGetItem) tables() []*Table {
+	tables := make([]*Table, 0, len(batchGetItem.Keys))
+	for t := range batchGetItem.Keys {
+		tables = append(tables, t)
+	}
+	sortTables(tables)
+	return tables
+}
+
+// chunks splits the keys of the request in batches of up to
+// BATCH_GET_MAX_KEYS keys.
+func (batchGetItem *BatchGetItem) chunks() []map[*Table][]Key {
+	var chunks []map[*Table][]Key
+	n := BATCH_GET_MAX_KEYS
+	for _, t := range batchGetItem.tables() {
+		keys := batchGetItem.Keys[t]
+		for len(keys) > 0 {
+			if n == BATCH_GET_MAX_KEYS {
+				chunks = append(chunks, make(map[*Table][]Key))
+				n = 0
+			}
+			m := len(keys)
+			if m > BATCH_GET_MAX_KEYS-n {
+				m = BATCH_GET_MAX_KEYS - n
+			}
+			chunks[len(chunks)-1][t] = keys[:m]
+			keys = keys[m:]
+			n += m
+		}
+	}
+	return chunks
+}
+
+// addUnprocessed adds to unprocessed the keys of the RequestItems of a
+// BatchGetItem request.
+func (batchGetItem *BatchGetItem) addUnprocessed(unprocessed map[*Table][]Key, requestItems map[string]interface{}) {
+	byName := tablesByName(batchGetItem.tables())
+	for name, request := range requestItems {
+		t := byName[name]
+		if t == nil {
+			continue
+		}
+		request, _ := request.(map[string]interface{})
+		keys, _ := request["Keys"].([]interface{})
+		for _, key := range keys {
+			attributes, ok := key.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			var k Key
+			parsed := parseAttributes(attributes)
+			if a := parsed[t.Key.KeyAttribute.Name]; a != nil {
+				k.HashKey = a.Value
+			}
+			if t.Key.HasRange() {
+				if a := parsed[t.Key.RangeAttribute.Name]; a != nil {
+					k.RangeKey = a.Value
+				}
+			}
+			unprocessed[t] = append(unprocessed[t], k)
+		}
+	}
+}
+
+// ExecuteAll reads all the keys of the request, in as many requests of up
+// to BATCH_GET_MAX_KEYS keys as needed. The keys DynamoDB leaves unprocessed
+// are read again, backing off as given by BatchRetryPolicy. It returns the
+// items read by table name, and fails with ErrUnprocessedItems and the
+// keys still unprocessed when the policy gives up. On other errors,
+// unprocessed holds all the keys not read.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_BatchGetItem.html for details.
+func (batchGetItem *BatchGetItem) ExecuteAll() (results map[string][]map[string]*Attribute, unprocessed map[*Table][]Key, err error) {
+	results = make(map[string][]map[string]*Attribute)
+	unprocessed = make(map[*Table][]Key)
+	handle := func(json *simplejson.Json, jsonResponse []byte) error {
+		return parseBatchGetResponses(json, jsonResponse, results)
+	}
+	chunks := batchGetItem.chunks()
+	for i, chunk := range chunks {
+		requestItems := getRequestItems(chunk, batchGetItem.Options)
+		left, err := batchGetItem.Server.batch(target("BatchGetItem"), requestItems, "UnprocessedKeys", handle)
+		batchGetItem.addUnprocessed(unprocessed, left)
+		if err != nil {
+			rest := chunks[i+1:]
+			if left == nil {
+				rest = chunks[i:]
+			}
+			for _, chunk := range rest {
+				for t, keys := range chunk {
+					unprocessed[t] = append(unprocessed[t], keys...)
+				}
+			}
+			return results, unprocessed, err
+		}
+	}
+	if len(unprocessed) > 0 {
+		return results, unprocessed, ErrUnprocessedItems
+	}
+	return results, nil, nil
+}
+
+// writeRequest is a put or a delete of a BatchWriteItem request.
+type writeRequest struct {
+	table      *Table
+	action     string
+	attributes []Attribute
+}
+
+func (batchWriteItem *BatchWriteItem) tables() []*Table {
+	tables := make([]*Table, 0, len(batchWriteItem.ItemActions))
+	for t := range batchWriteItem.ItemActions {
+		tables = append(tables, t)
+	}
+	sortTables(tables)
+	return tables
+}
+
+// chunks splits the puts and deletes of the request in batches of up to
+// BATCH_WRITE_MAX_ITEMS requests.
+func (batchWriteItem *BatchWriteItem) chunks() []map[*Table]map[string][][]Attribute {
+	var requests []writeRequest
+	for _, t := range batchWriteItem.tables() {
+		itemActions := batchWriteItem.ItemActions[t]
+		actions := make([]string, 0, len(itemActions))
+		for action := range itemActions {
+			actions = append(actions, action)
+		}
+		sort.Strings(actions)
+		for _, action := range actions {
+			for _, attributes := range itemActions[action] {
+				requests = append(requests, writeRequest{t, action, attributes})
+			}
+		}
+	}
+
+	var chunks []map[*Table]map[string][][]Attribute
+	for i, r := range requests {
+		if i%BATCH_WRITE_MAX_ITEMS == 0 {
+			chunks = append(chunks, make(map[*Table]map[string][][]Attribute))
+		}
+		addWriteRequest(chunks[len(chunks)-1], r.table, r.action, r.attributes)
+	}
+	return chunks
+}
+
+func addWriteRequest(itemActions map[*Table]map[string][][]Attribute, t *Table, action string, attributes []Attribute) {
+	if itemActions[t] == nil {
+		itemActions[t] = make(map[string][][]Attribute)
+	}
+	itemActions[t][action] = append(itemActions[t][action], attributes)
+}
+
+// addUnprocessed adds to unprocessed the puts and deletes of the
+// RequestItems of a BatchWriteItem request, with their attributes in
+// order of name.
+func (batchWriteItem *BatchWriteItem) addUnprocessed(unprocessed map[*Table]map[string][][]Attribute, requestItems map[string]interface{}) {
+	byName := tablesByName(batchWriteItem.tables())
+	for name, requests := range requestItems {
+		t := byName[name]
+		if t == nil {
+			continue
+		}
+		requests, _ := requests.([]interface{})
+		for _, request := range requests {
+			request, _ := request.(map[string]interface{})
+			for _, r := range []struct{ action, field string }{{"Put", "Item"}, {"Delete", "Key"}} {
+				body, ok := request[r.action+"Request"].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				item, _ := body[r.field].(map[string]interface{})
+				parsed := parseAttributes(item)
+				names := make([]string, 0, len(parsed))
+				for name := range parsed {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				attributes := make([]Attribute, len(names))
+				for i, name := range names {
+					attributes[i] = *parsed[name]
+				}
+				addWriteRequest(unprocessed, t, r.action, attributes)
+			}
+		}
+	}
+}
+
+// ExecuteAll makes all the puts and deletes of the request, in as many
+// requests of up to BATCH_WRITE_MAX_ITEMS puts and deletes as needed. The
+// ones DynamoDB leaves unprocessed are sent again, backing off as given
+// by BatchRetryPolicy. It fails with ErrUnprocessedItems and the puts and
+// deletes still unprocessed when the policy gives up. On other errors,
+// unprocessed holds all the puts and deletes not made.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_BatchWriteItem.html for details.
+func (batchWriteItem *BatchWriteItem) ExecuteAll() (unprocessed map[*Table]map[string][][]Attribute, err error) {
+	unprocessed = make(map[*Table]map[string][][]Attribute)
+	chunks := batchWriteItem.chunks()
+	for i, chunk := range chunks {
+		left, err := batchWriteItem.Server.batch(target("BatchWriteItem"), writeRequestItems(chunk), "UnprocessedItems", nil)
+		batchWriteItem.addUnprocessed(unprocessed, left)
+		if err != nil {
+			rest := chunks[i+1:]
+			if left == nil {
+				rest = chunks[i:]
+			}
+			for _, chunk := range rest {
+				for t, itemActions := range chunk {
+					for action, items := range itemActions {
+						for _, attributes := range items {
+							addWriteRequest(unprocessed, t, action, attributes)
+						}
+					}
+				}
+			}
+			return unprocessed, err
+		}
+	}
+	if len(unprocessed) > 0 {
+		return unprocessed, ErrUnprocessedItems
+	}
+	return nil, nil
+}
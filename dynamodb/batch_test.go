@@ -0,0 +1,241 @@
+package dynamodb_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/dynamodb"
+	. "gopkg.in/check.v1"
+)
+
+type BatchSuite struct {
+	policy aws.RetryPolicy
+}
+
+var _ = Suite(&BatchSuite{})
+
+func (s *BatchSuite) SetUpTest(c *C) {
+	s.policy = dynamodb.BatchRetryPolicy
+	dynamodb.BatchRetryPolicy = aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+}
+
+func (s *BatchSuite) TearDownTest(c *C) {
+	dynamodb.BatchRetryPolicy = s.policy
+}
+
+func batchTables(server *dynamodb.Server) (users, logins *dynamodb.Table) {
+	users = server.NewTable("Users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("Id", "")})
+	logins = server.NewTable("Logins", dynamodb.PrimaryKey{
+		KeyAttribute:   dynamodb.NewStringAttribute("User", ""),
+		RangeAttribute: dynamodb.NewNumericAttribute("At", ""),
+	})
+	return
+}
+
+// requestItems returns the RequestItems of a batch request by table.
+func requestItems(r *fakeRequest) map[string]interface{} {
+	items, _ := r.Body["RequestItems"].(map[string]interface{})
+	return items
+}
+
+func marshal(c *C, v interface{}) string {
+	data, err := json.Marshal(v)
+	c.Assert(err, IsNil)
+	return string(data)
+}
+
+func (s *BatchSuite) TestBatchGetItemChunks(c *C) {
+	var sizes []string
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		c.Check(r.Target, Equals, "DynamoDB_20120810.BatchGetItem")
+		responses := map[string][]interface{}{}
+		unprocessed := map[string]interface{}{}
+		size := ""
+		for _, name := range []string{"Logins", "Users"} {
+			request, ok := requestItems(r)[name].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			keys := request["Keys"].([]interface{})
+			size += fmt.Sprint(name, "=", len(keys), " ")
+			// The first request of 100 users leaves the last 10 unprocessed
+			if name == "Users" && len(keys) == 100 && len(sizes) == 1 {
+				request["Keys"] = keys[90:]
+				unprocessed[name] = request
+				keys = keys[:90]
+			}
+			responses[name] = keys
+		}
+		sizes = append(sizes, size)
+		return 200, marshal(c, map[string]interface{}{"Responses": responses, "UnprocessedKeys": unprocessed})
+	})
+	defer done()
+	users, logins := batchTables(server)
+
+	var userKeys, loginKeys []dynamodb.Key
+	for i := 0; i < 250; i++ {
+		userKeys = append(userKeys, dynamodb.Key{HashKey: fmt.Sprint("u", i)})
+	}
+	for i := 0; i < 30; i++ {
+		loginKeys = append(loginKeys, dynamodb.Key{HashKey: "u1", RangeKey: fmt.Sprint(i)})
+	}
+	batch := users.BatchGetItems(userKeys).AddTableWithOptions(logins, loginKeys, &dynamodb.BatchGetOptions{
+		ConsistentRead:           true,
+		ProjectionExpression:     "#a",
+		ExpressionAttributeNames: map[string]string{"#a": "At"},
+	})
+
+	results, unprocessed, err := batch.ExecuteAll()
+	c.Assert(err, IsNil)
+	c.Assert(unprocessed, IsNil)
+	c.Assert(sizes, DeepEquals, []string{
+		"Logins=30 Users=70 ",
+		"Users=100 ",
+		"Users=10 ",
+		"Users=80 ",
+	})
+	c.Assert(results["Users"], HasLen, 250)
+	c.Assert(results["Logins"], HasLen, 30)
+	c.Assert(results["Users"][160]["Id"].Value, Equals, "u160")
+	c.Assert(results["Users"][249]["Id"].Value, Equals, "u249")
+	c.Assert(results["Logins"][2]["At"], DeepEquals, dynamodb.NewNumericAttribute("At", "2"))
+}
+
+func (s *BatchSuite) TestBatchGetItemOptions(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, `{"Responses": {}}`
+	})
+	defer done()
+	users, logins := batchTables(server)
+
+	batch := users.BatchGetItems([]dynamodb.Key{{HashKey: "u1"}})
+	batch.AddTableWithOptions(logins, []dynamodb.Key{{HashKey: "u1", RangeKey: "3"}}, &dynamodb.BatchGetOptions{
+		ConsistentRead:           true,
+		ProjectionExpression:     "#a",
+		ExpressionAttributeNames: map[string]string{"#a": "At"},
+	})
+	_, err := batch.Execute()
+	c.Assert(err, IsNil)
+	c.Assert(requestItems(req), DeepEquals, map[string]interface{}{
+		"Users": map[string]interface{}{
+			"Keys": []interface{}{map[string]interface{}{"Id": map[string]interface{}{"S": "u1"}}},
+		},
+		"Logins": map[string]interface{}{
+			"Keys": []interface{}{map[string]interface{}{
+				"User": map[string]interface{}{"S": "u1"},
+				"At":   map[string]interface{}{"N": "3"},
+			}},
+			"ConsistentRead":           true,
+			"ProjectionExpression":     "#a",
+			"ExpressionAttributeNames": map[string]interface{}{"#a": "At"},
+		},
+	})
+}
+
+func (s *BatchSuite) TestBatchGetItemUnprocessed(c *C) {
+	requests := 0
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		requests++
+		if requests == 4 {
+			return 400, `{"__type": "com.amazonaws.dynamodb.v20120810#ResourceNotFoundException", "message": "Requested resource not found"}`
+		}
+		// The key u5 is never processed
+		users := requestItems(r)["Users"].(map[string]interface{})
+		keys, unprocessed := []interface{}{}, []interface{}(nil)
+		for _, key := range users["Keys"].([]interface{}) {
+			if key.(map[string]interface{})["Id"].(map[string]interface{})["S"] == "u5" {
+				unprocessed = append(unprocessed, key)
+			} else {
+				keys = append(keys, key)
+			}
+		}
+		response := map[string]interface{}{"Responses": map[string]interface{}{"Users": keys}}
+		if unprocessed != nil {
+			response["UnprocessedKeys"] = map[string]interface{}{"Users": map[string]interface{}{"Keys": unprocessed}}
+		}
+		return 200, marshal(c, response)
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	var keys []dynamodb.Key
+	for i := 0; i < 10; i++ {
+		keys = append(keys, dynamodb.Key{HashKey: fmt.Sprint("u", i)})
+	}
+	results, unprocessed, err := users.BatchGetItems(keys).ExecuteAll()
+	c.Assert(err, Equals, dynamodb.ErrUnprocessedItems)
+	c.Assert(requests, Equals, 3)
+	c.Assert(results["Users"], HasLen, 9)
+	c.Assert(unprocessed, DeepEquals, map[*dynamodb.Table][]dynamodb.Key{users: {{HashKey: "u5"}}})
+
+	// The keys of the failed request and the following ones are unprocessed
+	for i := 10; i < 250; i++ {
+		keys = append(keys, dynamodb.Key{HashKey: fmt.Sprint("u", i)})
+	}
+	requests = 2
+	results, unprocessed, err = users.BatchGetItems(keys).ExecuteAll()
+	c.Assert(aws.IsCode(err, "ResourceNotFoundException"), Equals, true)
+	c.Assert(requests, Equals, 4)
+	c.Assert(results["Users"], HasLen, 99)
+	c.Assert(unprocessed[users], HasLen, 151)
+	c.Assert(unprocessed[users][0], DeepEquals, dynamodb.Key{HashKey: "u5"})
+	c.Assert(unprocessed[users][1], DeepEquals, dynamodb.Key{HashKey: "u100"})
+}
+
+func (s *BatchSuite) TestBatchWriteItemUnprocessed(c *C) {
+	var sizes []int
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		c.Check(r.Target, Equals, "DynamoDB_20120810.BatchWriteItem")
+		// The put of p3 is never processed
+		var unprocessed []interface{}
+		for _, request := range requestItems(r)["Users"].([]interface{}) {
+			put, ok := request.(map[string]interface{})["PutRequest"].(map[string]interface{})
+			if ok && put["Item"].(map[string]interface{})["Id"].(map[string]interface{})["S"] == "p3" {
+				unprocessed = append(unprocessed, request)
+			}
+		}
+		sizes = append(sizes, len(requestItems(r)["Users"].([]interface{})))
+		if unprocessed == nil {
+			return 200, `{"UnprocessedItems": {}}`
+		}
+		return 200, marshal(c, map[string]interface{}{"UnprocessedItems": map[string]interface{}{"Users": unprocessed}})
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	itemActions := map[string][][]dynamodb.Attribute{}
+	for i := 0; i < 30; i++ {
+		itemActions["Put"] = append(itemActions["Put"], []dynamodb.Attribute{
+			*dynamodb.NewStringAttribute("Id", fmt.Sprint("p", i)),
+			*dynamodb.NewNumericAttribute("Count", fmt.Sprint(i)),
+		})
+	}
+	for i := 0; i < 5; i++ {
+		itemActions["Delete"] = append(itemActions["Delete"], []dynamodb.Attribute{
+			*dynamodb.NewStringAttribute("Id", fmt.Sprint("d", i)),
+		})
+	}
+
+	// The deletes come first, and p3 is sent again until the policy gives up
+	unprocessed, err := users.BatchWriteItems(itemActions).ExecuteAll()
+	c.Assert(err, Equals, dynamodb.ErrUnprocessedItems)
+	c.Assert(sizes, DeepEquals, []int{25, 1, 1, 10})
+	c.Assert(unprocessed, DeepEquals, map[*dynamodb.Table]map[string][][]dynamodb.Attribute{
+		users: {"Put": {{
+			*dynamodb.NewNumericAttribute("Count", "3"),
+			*dynamodb.NewStringAttribute("Id", "p3"),
+		}}},
+	})
+
+	// Everything else is processed at once
+	delete(itemActions, "Put")
+	sizes = nil
+	unprocessed, err = users.BatchWriteItems(itemActions).ExecuteAll()
+	c.Assert(err, IsNil)
+	c.Assert(unprocessed, IsNil)
+	c.Assert(sizes, DeepEquals, []int{5})
+}
@@ -0,0 +1,144 @@
+package dynamodb
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// ConsumedCapacity is the capacity consumed by a request sent with
+// ReturnConsumedCapacity. With RETURN_CONSUMED_CAPACITY_INDEXES, Table and
+// the indexes by name hold the part of the capacity consumed by each.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_ConsumedCapacity.html for details.
+type ConsumedCapacity struct {
+	TableName          string
+	CapacityUnits      float64
+	ReadCapacityUnits  float64
+	WriteCapacityUnits float64
+
+	Table                  *Capacity
+	LocalSecondaryIndexes  map[string]*Capacity
+	GlobalSecondaryIndexes map[string]*Capacity
+}
+
+// Capacity is the capacity consumed by a request on a table or an index.
+type Capacity struct {
+	CapacityUnits      float64
+	ReadCapacityUnits  float64
+	WriteCapacityUnits float64
+}
+
+func (c *Capacity) add(other *Capacity) {
+	c.CapacityUnits += other.CapacityUnits
+	c.ReadCapacityUnits += other.ReadCapacityUnits
+	c.WriteCapacityUnits += other.WriteCapacityUnits
+}
+
+// add adds the capacity consumed by another request on the same table.
+func (c *ConsumedCapacity) add(other *ConsumedCapacity) {
+	c.CapacityUnits += other.CapacityUnits
+	c.ReadCapacityUnits += other.ReadCapacityUnits
+	c.WriteCapacityUnits += other.WriteCapacityUnits
+	if other.Table != nil {
+		if c.Table == nil {
+			c.Table = &Capacity{}
+		}
+		c.Table.add(other.Table)
+	}
+	c.LocalSecondaryIndexes = addIndexCapacity(c.LocalSecondaryIndexes, other.LocalSecondaryIndexes)
+	c.GlobalSecondaryIndexes = addIndexCapacity(c.GlobalSecondaryIndexes, other.GlobalSecondaryIndexes)
+}
+
+func addIndexCapacity(indexes, other map[string]*Capacity) map[string]*Capacity {
+	for name, capacity := range other {
+		if indexes == nil {
+			indexes = make(map[string]*Capacity)
+		}
+		if indexes[name] == nil {
+			indexes[name] = &Capacity{}
+		}
+		indexes[name].add(capacity)
+	}
+	return indexes
+}
+
+// CapacityCounter adds up the capacity consumed by requests by table. It
+// is safe to use from several goroutines, and counts the requests of a
+// Server with:
+//
+//	server.OnConsumedCapacity = counter.Add
+type CapacityCounter struct {
+	mu     sync.Mutex
+	tables map[string]*ConsumedCapacity
+}
+
+// Add adds the capacity consumed by a request.
+func (c *CapacityCounter) Add(target string, consumed *ConsumedCapacity) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tables == nil {
+		c.tables = make(map[string]*ConsumedCapacity)
+	}
+	total := c.tables[consumed.TableName]
+	if total == nil {
+		total = &ConsumedCapacity{TableName: consumed.TableName}
+		c.tables[consumed.TableName] = total
+	}
+	total.add(consumed)
+}
+
+// Totals returns the capacity consumed so far by table name, and resets
+// the counter if reset is true.
+func (c *CapacityCounter) Totals(reset bool) map[string]*ConsumedCapacity {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	totals := make(map[string]*ConsumedCapacity, len(c.tables))
+	for name, total := range c.tables {
+		copied := &ConsumedCapacity{TableName: name}
+		copied.add(total)
+		totals[name] = copied
+	}
+	if reset {
+		c.tables = nil
+	}
+	return totals
+}
+
+// capacityTargets are the requests taking a ReturnConsumedCapacity.
+var capacityTargets = map[string]bool{
+	target("GetItem"):            true,
+	target("PutItem"):            true,
+	target("UpdateItem"):         true,
+	target("DeleteItem"):         true,
+	target("Query"):              true,
+	target("Scan"):               true,
+	target("BatchGetItem"):       true,
+	target("BatchWriteItem"):     true,
+	target("TransactGetItems"):   true,
+	target("TransactWriteItems"): true,
+}
+
+// reportCapacity calls s.OnConsumedCapacity with the capacity in a
+// response, which the batch requests give by table.
+func (s *Server) reportCapacity(target string, jsonResponse []byte) {
+	var resp struct {
+		ConsumedCapacity json.RawMessage
+	}
+	if json.Unmarshal(jsonResponse, &resp) != nil || len(resp.ConsumedCapacity) == 0 {
+		return
+	}
+	var consumed []*ConsumedCapacity
+	if bytes.HasPrefix(resp.ConsumedCapacity, []byte("[")) {
+		json.Unmarshal(resp.ConsumedCapacity, &consumed)
+	} else {
+		var c *ConsumedCapacity
+		json.Unmarshal(resp.ConsumedCapacity, &c)
+		consumed = append(consumed, c)
+	}
+	for _, c := range consumed {
+		if c != nil {
+			s.OnConsumedCapacity(target, c)
+		}
+	}
+}
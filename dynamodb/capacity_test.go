@@ -0,0 +1,145 @@
+package dynamodb_test
+
+import (
+	"time"
+
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/dynamodb"
+	. "gopkg.in/check.v1"
+)
+
+type CapacitySuite struct {
+	policy aws.RetryPolicy
+	delays []time.Duration
+}
+
+var _ = Suite(&CapacitySuite{})
+
+func (s *CapacitySuite) SetUpTest(c *C) {
+	s.policy, s.delays = dynamodb.DefaultRetryPolicy, nil
+	dynamodb.DefaultRetryPolicy.Jitter = 0
+	dynamodb.DefaultRetryPolicy.Sleep = func(d time.Duration) {
+		s.delays = append(s.delays, d)
+	}
+}
+
+func (s *CapacitySuite) TearDownTest(c *C) {
+	dynamodb.DefaultRetryPolicy = s.policy
+}
+
+var name = []dynamodb.Attribute{*dynamodb.NewStringAttribute("name", "n")}
+
+const throughputExceeded = `{"__type": "com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException", "message": "The level of configured provisioned throughput for the table was exceeded"}`
+
+func (s *CapacitySuite) TestThroughputBackoff(c *C) {
+	requests := 0
+	failures := []string{throughputExceeded, throughputExceeded,
+		`{"__type": "com.amazon.coral.availability#ThrottlingException", "message": "Rate of requests exceeds the allowed throughput"}`}
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		requests++
+		if len(failures) > 0 {
+			failure := failures[0]
+			failures = failures[1:]
+			return 400, failure
+		}
+		return 200, `{"Attributes": {}}`
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	// Servers without a policy back off with DefaultRetryPolicy
+	server.RetryPolicy = nil
+	_, err := users.PutItemWithOptions("u1", "", name, nil)
+	c.Assert(err, IsNil)
+	c.Assert(requests, Equals, 4)
+	c.Assert(s.delays, DeepEquals, []time.Duration{25 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond})
+
+	// The policy of the server gives up after its attempts
+	requests, s.delays = 0, nil
+	failures = []string{throughputExceeded, throughputExceeded, throughputExceeded, throughputExceeded}
+	var delays []time.Duration
+	server.RetryPolicy = &aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 3 * time.Second, Sleep: func(d time.Duration) {
+		delays = append(delays, d)
+	}}
+	_, err = users.PutItemWithOptions("u1", "", name, nil)
+	c.Assert(aws.IsCode(err, "ProvisionedThroughputExceededException"), Equals, true)
+	c.Assert(requests, Equals, 3)
+	c.Assert(delays, DeepEquals, []time.Duration{time.Second, 2 * time.Second})
+	c.Assert(s.delays, IsNil)
+}
+
+func (s *CapacitySuite) TestConsumedCapacityIndexes(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, `{"Count": 0, "Items": [], "ConsumedCapacity": {
+			"TableName": "Orders", "CapacityUnits": 3, "ReadCapacityUnits": 3,
+			"Table": {"CapacityUnits": 1, "ReadCapacityUnits": 1},
+			"GlobalSecondaryIndexes": {"ByCustomer": {"CapacityUnits": 2, "ReadCapacityUnits": 2}}
+		}}`
+	})
+	defer done()
+	orders := server.NewTable("Orders", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("OrderId", "")})
+
+	result, err := orders.QueryWithOptions([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("CustomerId", "c1"),
+	}, &dynamodb.QueryOptions{IndexName: "ByCustomer", ReturnConsumedCapacity: dynamodb.RETURN_CONSUMED_CAPACITY_INDEXES})
+	c.Assert(err, IsNil)
+	c.Assert(req.Body["ReturnConsumedCapacity"], Equals, "INDEXES")
+	c.Assert(result.ConsumedCapacity, DeepEquals, &dynamodb.ConsumedCapacity{
+		TableName:              "Orders",
+		CapacityUnits:          3,
+		ReadCapacityUnits:      3,
+		Table:                  &dynamodb.Capacity{CapacityUnits: 1, ReadCapacityUnits: 1},
+		GlobalSecondaryIndexes: map[string]*dynamodb.Capacity{"ByCustomer": {CapacityUnits: 2, ReadCapacityUnits: 2}},
+	})
+}
+
+func (s *CapacitySuite) TestCapacityCounter(c *C) {
+	var requests []*fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		requests = append(requests, r)
+		switch r.Target {
+		case "DynamoDB_20120810.PutItem":
+			return 200, `{"ConsumedCapacity": {"TableName": "Users", "CapacityUnits": 1, "Table": {"CapacityUnits": 1}}}`
+		case "DynamoDB_20120810.BatchWriteItem":
+			return 200, `{"UnprocessedItems": {}, "ConsumedCapacity": [
+				{"TableName": "Users", "CapacityUnits": 2, "Table": {"CapacityUnits": 2}},
+				{"TableName": "Logins", "CapacityUnits": 4, "LocalSecondaryIndexes": {"ByAt": {"CapacityUnits": 2}}}
+			]}`
+		}
+		return 200, `{"TableNames": []}`
+	})
+	defer done()
+	users, logins := batchTables(server)
+	counter := &dynamodb.CapacityCounter{}
+	server.OnConsumedCapacity = counter.Add
+
+	_, err := users.PutItemWithOptions("u1", "", name, &dynamodb.WriteOptions{ReturnConsumedCapacity: dynamodb.RETURN_CONSUMED_CAPACITY_TOTAL})
+	c.Assert(err, IsNil)
+	_, err = users.PutItemWithOptions("u2", "", name, nil)
+	c.Assert(err, IsNil)
+	batch := users.BatchWriteItems(map[string][][]dynamodb.Attribute{"Delete": {{*dynamodb.NewStringAttribute("Id", "u1")}}})
+	batch.AddTable(logins, &map[string][][]dynamodb.Attribute{"Delete": {{
+		*dynamodb.NewStringAttribute("User", "u1"),
+		*dynamodb.NewNumericAttribute("At", "1"),
+	}}})
+	_, err = batch.ExecuteAll()
+	c.Assert(err, IsNil)
+	_, err = server.ListTables()
+	c.Assert(err, IsNil)
+
+	// The requests ask for the capacity unless they already do
+	c.Assert(requests, HasLen, 4)
+	c.Assert(requests[0].Body["ReturnConsumedCapacity"], Equals, "TOTAL")
+	c.Assert(requests[1].Body["ReturnConsumedCapacity"], Equals, "INDEXES")
+	c.Assert(requests[2].Body["ReturnConsumedCapacity"], Equals, "INDEXES")
+	c.Assert(requests[3].Body["ReturnConsumedCapacity"], IsNil)
+
+	c.Assert(counter.Totals(true), DeepEquals, map[string]*dynamodb.ConsumedCapacity{
+		"Users": {TableName: "Users", CapacityUnits: 4, Table: &dynamodb.Capacity{CapacityUnits: 4}},
+		"Logins": {TableName: "Logins", CapacityUnits: 4,
+			LocalSecondaryIndexes: map[string]*dynamodb.Capacity{"ByAt": {CapacityUnits: 2}}},
+	})
+	c.Assert(counter.Totals(false), HasLen, 0)
+}
@@ -0,0 +1,75 @@
+package dynamodb
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// AddInt atomically adds delta to the number attribute attr of the item
+// with the given key, creating the attribute with delta, and the item, if
+// needed, and returns the new value. The value is read back as written by
+// DynamoDB, which keeps up to 38 digits, and it fails if it isn't an
+// int64.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/WorkingWithItems.html#WorkingWithItems.AtomicCounters for details.
+func (t *Table) AddInt(key *Key, attr string, delta int64) (int64, error) {
+	a, err := t.addOrDelete("ADD", key, NewNumericAttribute(attr, strconv.FormatInt(delta, 10)))
+	if err != nil {
+		return 0, err
+	}
+	if a == nil || a.Type != TYPE_NUMBER {
+		return 0, fmt.Errorf("Attribute %s is not a number", attr)
+	}
+	n, err := strconv.ParseInt(a.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Attribute %s is not an int64: %s", attr, a.Value)
+	}
+	return n, nil
+}
+
+// AddToSet atomically adds the values of set, a string, number or binary
+// set attribute, to the attribute with its name of the item with the given
+// key, creating the attribute, and the item, if needed, and returns the
+// new set.
+func (t *Table) AddToSet(key *Key, set *Attribute) (*Attribute, error) {
+	if err := checkSet(set); err != nil {
+		return nil, err
+	}
+	return t.addOrDelete("ADD", key, set)
+}
+
+// DeleteFromSet atomically deletes the values of set from the attribute
+// with its name of the item with the given key, as AddToSet, and returns
+// the new set, or nil if it was left empty, which removes the attribute.
+func (t *Table) DeleteFromSet(key *Key, set *Attribute) (*Attribute, error) {
+	if err := checkSet(set); err != nil {
+		return nil, err
+	}
+	return t.addOrDelete("DELETE", key, set)
+}
+
+func checkSet(set *Attribute) error {
+	if !set.SetType() {
+		return fmt.Errorf("Attribute %s is not a set", set.Name)
+	}
+	if len(set.SetValues) == 0 {
+		return fmt.Errorf("Attribute %s has no values", set.Name)
+	}
+	return nil
+}
+
+// addOrDelete updates an attribute with an ADD or a DELETE action, and
+// returns its new value.
+func (t *Table) addOrDelete(action string, key *Key, value *Attribute) (*Attribute, error) {
+	operand := *value
+	operand.Name = ":value"
+	result, err := t.UpdateItemWithOptions(key, action+" #attr :value", &WriteOptions{
+		ExpressionAttributeNames:  map[string]string{"#attr": value.Name},
+		ExpressionAttributeValues: []Attribute{operand},
+		ReturnValues:              RETURN_VALUES_UPDATED_NEW,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Attributes[value.Name], nil
+}
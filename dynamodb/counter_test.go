@@ -0,0 +1,132 @@
+package dynamodb_test
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/goamz/goamz/dynamodb"
+	. "gopkg.in/check.v1"
+)
+
+type CounterSuite struct{}
+
+var _ = Suite(&CounterSuite{})
+
+func (s *CounterSuite) TestAddInt(c *C) {
+	// The fake applies the updates one at a time, as DynamoDB does
+	var mu sync.Mutex
+	counters := map[string]int64{}
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		mu.Lock()
+		defer mu.Unlock()
+		c.Check(r.Target, Equals, "DynamoDB_20120810.UpdateItem")
+		c.Check(r.Body["UpdateExpression"], Equals, "ADD #attr :value")
+		c.Check(r.Body["ReturnValues"], Equals, "UPDATED_NEW")
+		id := r.Body["Key"].(map[string]interface{})["Id"].(map[string]interface{})["S"].(string)
+		attr := r.Body["ExpressionAttributeNames"].(map[string]interface{})["#attr"].(string)
+		value := r.Body["ExpressionAttributeValues"].(map[string]interface{})[":value"].(map[string]interface{})["N"].(string)
+		delta, _ := strconv.ParseInt(value, 10, 64)
+		counters[id] += delta
+		return 200, marshal(c, map[string]interface{}{"Attributes": map[string]interface{}{
+			attr: map[string]interface{}{"N": strconv.FormatInt(counters[id], 10)},
+		}})
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	var wg sync.WaitGroup
+	var values []int
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				n, err := users.AddInt(&dynamodb.Key{HashKey: "u1"}, "visits", 1)
+				c.Check(err, IsNil)
+				mu.Lock()
+				values = append(values, int(n))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every update saw its own value
+	sort.Ints(values)
+	c.Assert(values, HasLen, 200)
+	for i, n := range values {
+		c.Assert(n, Equals, i+1)
+	}
+
+	n, err := users.AddInt(&dynamodb.Key{HashKey: "u1"}, "visits", -200)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, int64(0))
+}
+
+func (s *CounterSuite) TestAddIntPrecision(c *C) {
+	value := "9223372036854775807"
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		return 200, `{"Attributes": {"visits": {"N": "` + value + `"}}}`
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	n, err := users.AddInt(&dynamodb.Key{HashKey: "u1"}, "visits", 1)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, int64(9223372036854775807))
+
+	value = "9223372036854775808"
+	_, err = users.AddInt(&dynamodb.Key{HashKey: "u1"}, "visits", 1)
+	c.Assert(err, ErrorMatches, "Attribute visits is not an int64: 9223372036854775808")
+	value = "1.5"
+	_, err = users.AddInt(&dynamodb.Key{HashKey: "u1"}, "visits", 1)
+	c.Assert(err, ErrorMatches, "Attribute visits is not an int64: 1.5")
+}
+
+func (s *CounterSuite) TestSets(c *C) {
+	var req *fakeRequest
+	response := `{"Attributes": {"Tags": {"SS": ["a", "b", "c"]}}}`
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, response
+	})
+	defer done()
+	_, logins := batchTables(server)
+	key := &dynamodb.Key{HashKey: "u1", RangeKey: "12"}
+
+	set, err := logins.AddToSet(key, dynamodb.NewStringSetAttribute("Tags", []string{"b", "c"}))
+	c.Assert(err, IsNil)
+	c.Assert(set, DeepEquals, dynamodb.NewStringSetAttribute("Tags", []string{"a", "b", "c"}))
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{
+		"TableName": "Logins",
+		"Key": map[string]interface{}{
+			"User": map[string]interface{}{"S": "u1"},
+			"At":   map[string]interface{}{"N": "12"},
+		},
+		"UpdateExpression":          "ADD #attr :value",
+		"ExpressionAttributeNames":  map[string]interface{}{"#attr": "Tags"},
+		"ExpressionAttributeValues": map[string]interface{}{":value": map[string]interface{}{"SS": []interface{}{"b", "c"}}},
+		"ReturnValues":              "UPDATED_NEW",
+	})
+
+	response = `{"Attributes": {"Keys": {"BS": ["AQI="]}}}`
+	set, err = logins.DeleteFromSet(key, dynamodb.NewBytesSetAttribute("Keys", [][]byte{{3}}))
+	c.Assert(err, IsNil)
+	c.Assert(req.Body["UpdateExpression"], Equals, "DELETE #attr :value")
+	c.Assert(req.Body["ExpressionAttributeValues"], DeepEquals, map[string]interface{}{":value": map[string]interface{}{"BS": []interface{}{"Aw=="}}})
+	c.Assert(set, DeepEquals, dynamodb.NewBytesSetAttribute("Keys", [][]byte{{1, 2}}))
+
+	// Sets left empty are removed
+	response = `{}`
+	set, err = logins.DeleteFromSet(key, dynamodb.NewNumericSetAttribute("Scores", []string{"1"}))
+	c.Assert(err, IsNil)
+	c.Assert(set, IsNil)
+
+	req = nil
+	_, err = logins.AddToSet(key, dynamodb.NewStringAttribute("Tags", "a"))
+	c.Assert(err, ErrorMatches, "Attribute Tags is not a set")
+	_, err = logins.DeleteFromSet(key, dynamodb.NewStringSetAttribute("Tags", nil))
+	c.Assert(err, ErrorMatches, "Attribute Tags has no values")
+	c.Assert(req, IsNil)
+}
@@ -1,7 +1,7 @@
 package dynamodb
 
-import simplejson "github.com/bitly/go-simplejson"
 import (
+	"bytes"
 	"errors"
 	"github.com/goamz/goamz/aws"
 	"io/ioutil"
@@ -14,6 +14,70 @@ import (
 type Server struct {
 	Auth   aws.Auth
 	Region aws.Region
+
+	// Credentials, when not nil, provides the credentials used to sign
+	// each request instead of Auth, so that temporary credentials can be
+	// renewed without creating a new Server.
+	Credentials aws.CredentialsProvider
+
+	// RetryPolicy is used to retry failed requests, including those
+	// exceeding the provisioned throughput or throttled. It defaults to
+	// DefaultRetryPolicy.
+	RetryPolicy *aws.RetryPolicy
+
+	// OnConsumedCapacity, if not nil, is called with the capacity consumed
+	// by every request reading or writing items, as by the Add method of
+	// a CapacityCounter. Requests without a ReturnConsumedCapacity then
+	// ask for it with RETURN_CONSUMED_CAPACITY_INDEXES.
+	OnConsumedCapacity func(target string, consumed *ConsumedCapacity)
+
+	// HTTPClient performs the requests. It defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// clock corrects the time requests are signed with when the local
+	// clock is skewed.
+	clock aws.Clock
+}
+
+// DefaultRetryPolicy is used by Servers without a RetryPolicy. It makes
+// more attempts than aws.DefaultRetryPolicy, starting sooner, as throughput
+// errors are frequent with DynamoDB and soon over.
+var DefaultRetryPolicy = aws.RetryPolicy{
+	MaxAttempts: 10,
+	BaseDelay:   25 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Jitter:      0.5,
+}
+
+// New creates a new Server for the DynamoDB endpoint of region.
+func New(auth aws.Auth, region aws.Region) *Server {
+	return &Server{Auth: auth, Region: region}
+}
+
+// LocalAuth is the dummy credentials NewWithEndpoint signs requests with
+// when it is given none, which DynamoDB local accepts as it only checks
+// their form.
+var LocalAuth = aws.Auth{AccessKey: "DUMMYKEY", SecretKey: "DUMMYSECRET"}
+
+// NewWithEndpoint creates a new Server for the DynamoDB and DynamoDB
+// Streams endpoint at the http or https URL endpoint, as DynamoDB local
+// (http://localhost:8000) or localstack, with LocalAuth if auth has no
+// access key. Requests are signed for us-east-1.
+//
+// Use an http.Client from aws.NewInsecureClient as HTTPClient if the
+// endpoint has a self-signed certificate.
+func NewWithEndpoint(auth aws.Auth, endpoint string) (*Server, error) {
+	region, err := aws.RegionFromEndpointMap(map[string]string{
+		"dynamodb":         endpoint,
+		"streams.dynamodb": endpoint,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if auth.AccessKey == "" {
+		auth = LocalAuth
+	}
+	return New(auth, region), nil
 }
 
 /*
@@ -33,65 +97,83 @@ func NewQuery(queryParts []string) *Query {
 // Specific error constants
 var ErrNotFound = errors.New("Item not found")
 
+// ErrConsistentReadOnGlobalIndex is returned by the reads of global
+// secondary indexes with ConsistentRead, which DynamoDB doesn't support.
+var ErrConsistentReadOnGlobalIndex = errors.New("Consistent reads are not supported on global secondary indexes")
+
 // Error represents an error in an operation with Dynamodb (following goamz/s3)
-type Error struct {
-	StatusCode int // HTTP status code (200, 403, ...)
-	Status     string
-	Code       string // Dynamodb error code ("MalformedQueryString", ...)
-	Message    string // The human-oriented error message
-}
+type Error = aws.Error
 
-func (e *Error) Error() string {
-	return e.Code + ": " + e.Message
+// ConditionalCheckFailedError is returned by the writes whose condition,
+// as a ConditionExpression or Expected attributes, isn't met by the item.
+type ConditionalCheckFailedError struct {
+	Err *Error
 }
 
-func buildError(r *http.Response, jsonBody []byte) error {
-
-	ddbError := Error{
-		StatusCode: r.StatusCode,
-		Status:     r.Status,
-	}
-	// TODO return error if Unmarshal fails?
+func (e *ConditionalCheckFailedError) Error() string {
+	return e.Err.Error()
+}
 
-	json, err := simplejson.NewJson(jsonBody)
-	if err != nil {
-		log.Printf("Failed to parse body as JSON")
-		return err
-	}
-	ddbError.Message = json.Get("message").MustString()
-
-	// Of the form: com.amazon.coral.validate#ValidationException
-	// We only want the last part
-	codeStr := json.Get("__type").MustString()
-	hashIndex := strings.Index(codeStr, "#")
-	if hashIndex > 0 {
-		codeStr = codeStr[hashIndex+1:]
-	}
-	ddbError.Code = codeStr
+func (e *ConditionalCheckFailedError) Unwrap() error {
+	return e.Err
+}
 
-	return &ddbError
+func buildError(r *http.Response, jsonBody []byte) error {
+	r.Body = ioutil.NopCloser(bytes.NewReader(jsonBody))
+	return aws.BuildError(r)
 }
 
 func (s *Server) queryServer(target string, query *Query) ([]byte, error) {
-	data := strings.NewReader(query.String())
-	hreq, err := http.NewRequest("POST", s.Region.DynamoDBEndpoint+"/", data)
+	return s.queryEndpoint(s.Region.DynamoDBEndpoint, target, query)
+}
+
+// queryEndpoint sends a request to the DynamoDB service at endpoint, which
+// is the endpoint of DynamoDB or of DynamoDB Streams.
+func (s *Server) queryEndpoint(endpoint, target string, query *Query) ([]byte, error) {
+	auth, err := aws.CurrentAuth(s.Auth, s.Credentials)
 	if err != nil {
 		return nil, err
 	}
 
-	hreq.Header.Set("Content-Type", "application/x-amz-json-1.0")
-	hreq.Header.Set("X-Amz-Date", time.Now().UTC().Format(aws.ISO8601BasicFormat))
-	hreq.Header.Set("X-Amz-Target", target)
-
-	token := s.Auth.Token()
-	if token != "" {
-		hreq.Header.Set("X-Amz-Security-Token", token)
+	policy := s.RetryPolicy
+	if policy == nil {
+		policy = &DefaultRetryPolicy
 	}
-
-	signer := aws.NewV4Signer(s.Auth, "dynamodb", s.Region)
-	signer.Sign(hreq)
-
-	resp, err := http.DefaultClient.Do(hreq)
+	if s.OnConsumedCapacity != nil && capacityTargets[target] {
+		if _, ok := query.buffer["ReturnConsumedCapacity"]; !ok {
+			query.AddReturnConsumedCapacity(RETURN_CONSUMED_CAPACITY_INDEXES)
+		}
+	}
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	// Regions without a name, as made up for DynamoDB local, would sign
+	// requests with a malformed credential scope.
+	signingRegion := s.Region
+	if signingRegion.Name == "" {
+		signingRegion.Name = aws.USEast.Name
+	}
+	resp, err := s.clock.Do(policy, client, func(now time.Time) (*http.Request, error) {
+		data := strings.NewReader(query.String())
+		hreq, err := http.NewRequest("POST", endpoint+"/", data)
+		if err != nil {
+			return nil, err
+		}
+
+		hreq.Header.Set("Content-Type", "application/x-amz-json-1.0")
+		hreq.Header.Set("X-Amz-Date", now.UTC().Format(aws.ISO8601BasicFormat))
+		hreq.Header.Set("X-Amz-Target", target)
+
+		token := auth.Token()
+		if token != "" {
+			hreq.Header.Set("X-Amz-Security-Token", token)
+		}
+
+		signer := aws.NewV4Signer(auth, "dynamodb", signingRegion)
+		signer.Sign(hreq)
+		return hreq, nil
+	})
 
 	if err != nil {
 		log.Printf("Error calling Amazon")
@@ -110,9 +192,26 @@ func (s *Server) queryServer(target string, query *Query) ([]byte, error) {
 	// "A response code of 200 indicates the operation was successful."
 	if resp.StatusCode != 200 {
 		ddbErr := buildError(resp, body)
+		if e, ok := ddbErr.(*Error); ok {
+			switch e.Code {
+			case "ConditionalCheckFailedException":
+				return nil, &ConditionalCheckFailedError{e}
+			case "LimitExceededException":
+				return nil, &LimitExceededError{e}
+			case "ExpiredIteratorException":
+				return nil, &ExpiredIteratorError{e}
+			case "TrimmedDataAccessException":
+				return nil, &TrimmedDataAccessError{e}
+			case "TransactionCanceledException":
+				return nil, transactionCanceled(e, body)
+			}
+		}
 		return nil, ddbErr
 	}
 
+	if s.OnConsumedCapacity != nil {
+		s.reportCapacity(target, body)
+	}
 	return body, nil
 }
 
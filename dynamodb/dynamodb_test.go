@@ -1,7 +1,11 @@
 package dynamodb_test
 
 import (
+	"encoding/json"
 	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -136,8 +140,12 @@ func setUpAuth(c *C) {
 	}
 	if *local {
 		c.Log("Using local server")
-		dynamodb_region = aws.Region{DynamoDBEndpoint: "http://127.0.0.1:8000"}
-		dynamodb_auth = aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}
+		server, err := dynamodb.NewWithEndpoint(aws.Auth{}, "http://127.0.0.1:8000")
+		if err != nil {
+			c.Fatal(err)
+		}
+		dynamodb_region = server.Region
+		dynamodb_auth = server.Auth
 	} else {
 		c.Log("Using REAL AMAZON SERVER")
 		dynamodb_region = aws.USEast
@@ -161,3 +169,96 @@ func findTableByName(tables []string, name string) bool {
 func Test(t *testing.T) {
 	TestingT(t)
 }
+
+// EndpointSuite runs requests against a fake DynamoDB endpoint, without
+// the need for DynamoDB local.
+type EndpointSuite struct{}
+
+// fakeRequest is a request received by a fake endpoint, with its decoded
+// JSON body.
+type fakeRequest struct {
+	Target string
+	Body   map[string]interface{}
+}
+
+// fakeServer returns a Server whose requests are answered by handle with
+// a status and a JSON body, and a function closing the endpoint.
+func fakeServer(c *C, handle func(req *fakeRequest) (int, string)) (*dynamodb.Server, func()) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &fakeRequest{Target: r.Header.Get("X-Amz-Target")}
+		data, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &req.Body); err != nil {
+			c.Errorf("invalid request body %s: %v", data, err)
+		}
+		status, body := handle(req)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	server := dynamodb.New(aws.Auth{AccessKey: "abc", SecretKey: "123"}, aws.Region{Name: "faux-region-1", DynamoDBEndpoint: srv.URL})
+	server.RetryPolicy = &aws.NoRetry
+	return server, srv.Close
+}
+
+var _ = Suite(&EndpointSuite{})
+
+func (s *EndpointSuite) TestCustomEndpoint(c *C) {
+	var target string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		target = req.Header.Get("X-Amz-Target")
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.Write([]byte(`{"TableNames": ["first", "second"]}`))
+	}))
+	defer srv.Close()
+
+	region, err := aws.RegionFromEndpointMap(map[string]string{"dynamodb": srv.URL})
+	c.Assert(err, IsNil)
+	server := dynamodb.New(aws.Auth{AccessKey: "abc", SecretKey: "123"}, region)
+	server.HTTPClient = aws.NewInsecureClient()
+	server.RetryPolicy = &aws.NoRetry
+
+	tables, err := server.ListTables()
+	c.Assert(err, IsNil)
+	c.Assert(tables, DeepEquals, []string{"first", "second"})
+	c.Assert(target, Equals, "DynamoDB_20120810.ListTables")
+
+	// Certificates are still verified by default
+	server.HTTPClient = nil
+	_, err = server.ListTables()
+	c.Assert(err, ErrorMatches, ".*certificate.*")
+}
+
+func (s *EndpointSuite) TestNewWithEndpoint(c *C) {
+	var authorizations, targets []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		authorizations = append(authorizations, req.Header.Get("Authorization"))
+		targets = append(targets, req.Header.Get("X-Amz-Target"))
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.Write([]byte(`{"TableNames": [], "Streams": []}`))
+	}))
+	defer srv.Close()
+
+	server, err := dynamodb.NewWithEndpoint(aws.Auth{}, srv.URL)
+	c.Assert(err, IsNil)
+	server.RetryPolicy = &aws.NoRetry
+	_, err = server.ListTables()
+	c.Assert(err, IsNil)
+	_, err = server.ListStreams("")
+	c.Assert(err, IsNil)
+
+	// The requests are signed with dummy credentials for us-east-1, and
+	// streams are read from the same endpoint
+	c.Assert(targets, DeepEquals, []string{"DynamoDB_20120810.ListTables", "DynamoDBStreams_20120810.ListStreams"})
+	c.Assert(authorizations[0], Matches, "AWS4-HMAC-SHA256 Credential=DUMMYKEY/[0-9]+/us-east-1/dynamodb/aws4_request, .*")
+	c.Assert(authorizations[1], Matches, "AWS4-HMAC-SHA256 Credential=DUMMYKEY/[0-9]+/us-east-1/dynamodb/aws4_request, .*")
+
+	// As are those of regions without a name
+	server = dynamodb.New(aws.Auth{AccessKey: "abc", SecretKey: "123"}, aws.Region{DynamoDBEndpoint: srv.URL})
+	server.RetryPolicy = &aws.NoRetry
+	_, err = server.ListTables()
+	c.Assert(err, IsNil)
+	c.Assert(authorizations[2], Matches, "AWS4-HMAC-SHA256 Credential=abc/[0-9]+/us-east-1/dynamodb/aws4_request, .*")
+
+	_, err = dynamodb.NewWithEndpoint(aws.Auth{}, "localhost:8000")
+	c.Assert(err, ErrorMatches, `invalid endpoint for .*: "localhost:8000" is not an http or https URL`)
+}
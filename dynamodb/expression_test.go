@@ -0,0 +1,199 @@
+package dynamodb_test
+
+import (
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/dynamodb"
+	. "gopkg.in/check.v1"
+)
+
+type ExpressionSuite struct{}
+
+var _ = Suite(&ExpressionSuite{})
+
+func (s *ExpressionSuite) TestPutItemConditionExpression(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, `{
+			"Attributes": {"Id": {"S": "u1"}, "name": {"S": "old"}, "version": {"N": "3"}},
+			"ConsumedCapacity": {"TableName": "Users", "CapacityUnits": 1}
+		}`
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	result, err := users.PutItemWithOptions("u1", "", []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("name", "new"),
+		*dynamodb.NewNumericAttribute("version", "4"),
+	}, &dynamodb.WriteOptions{
+		ConditionExpression:       "#name <> :name AND version = :version",
+		ExpressionAttributeNames:  map[string]string{"#name": "name"},
+		ExpressionAttributeValues: []dynamodb.Attribute{*dynamodb.NewStringAttribute(":name", "new"), *dynamodb.NewNumericAttribute(":version", "3")},
+		ReturnValues:              dynamodb.RETURN_VALUES_ALL_OLD,
+		ReturnConsumedCapacity:    dynamodb.RETURN_CONSUMED_CAPACITY_TOTAL,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(req.Target, Equals, "DynamoDB_20120810.PutItem")
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{
+		"TableName": "Users",
+		"Item": map[string]interface{}{
+			"Id":      map[string]interface{}{"S": "u1"},
+			"name":    map[string]interface{}{"S": "new"},
+			"version": map[string]interface{}{"N": "4"},
+		},
+		"ConditionExpression":      "#name <> :name AND version = :version",
+		"ExpressionAttributeNames": map[string]interface{}{"#name": "name"},
+		"ExpressionAttributeValues": map[string]interface{}{
+			":name":    map[string]interface{}{"S": "new"},
+			":version": map[string]interface{}{"N": "3"},
+		},
+		"ReturnValues":           "ALL_OLD",
+		"ReturnConsumedCapacity": "TOTAL",
+	})
+	c.Assert(result.Attributes["name"], DeepEquals, dynamodb.NewStringAttribute("name", "old"))
+	c.Assert(result.Attributes["version"].Value, Equals, "3")
+	c.Assert(result.ConsumedCapacity, DeepEquals, &dynamodb.ConsumedCapacity{TableName: "Users", CapacityUnits: 1})
+
+	// Puts only return the old attributes
+	_, err = users.PutItemWithOptions("u1", "", []dynamodb.Attribute{*dynamodb.NewStringAttribute("name", "new")}, &dynamodb.WriteOptions{
+		ReturnValues: dynamodb.RETURN_VALUES_UPDATED_NEW,
+	})
+	c.Assert(err, ErrorMatches, "Invalid ReturnValues UPDATED_NEW")
+}
+
+func (s *ExpressionSuite) TestConditionalCheckFailed(c *C) {
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		return 400, `{"__type": "com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException", "message": "The conditional request failed"}`
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	_, err := users.DeleteItemWithOptions(&dynamodb.Key{HashKey: "u1"}, &dynamodb.WriteOptions{
+		ConditionExpression:       "version = :version",
+		ExpressionAttributeValues: []dynamodb.Attribute{*dynamodb.NewNumericAttribute(":version", "3")},
+	})
+	e, ok := err.(*dynamodb.ConditionalCheckFailedError)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.Err.StatusCode, Equals, 400)
+	c.Assert(err, ErrorMatches, `The conditional request failed \(ConditionalCheckFailedException\)`)
+	c.Assert(aws.IsCode(err, "ConditionalCheckFailedException"), Equals, true)
+
+	// The legacy API fails the same way
+	_, err = users.ConditionalPutItem("u1", "", []dynamodb.Attribute{*dynamodb.NewStringAttribute("name", "new")},
+		[]dynamodb.Attribute{*dynamodb.NewStringAttribute("name", "old")})
+	_, ok = err.(*dynamodb.ConditionalCheckFailedError)
+	c.Assert(ok, Equals, true)
+}
+
+func (s *ExpressionSuite) TestUpdateItemExpression(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, `{"Attributes": {"visits": {"N": "8"}}}`
+	})
+	defer done()
+	_, logins := batchTables(server)
+
+	result, err := logins.UpdateItemWithOptions(&dynamodb.Key{HashKey: "u1", RangeKey: "12"}, "SET #count = #count + :one", &dynamodb.WriteOptions{
+		ConditionExpression:       "attribute_exists(#count)",
+		ExpressionAttributeNames:  map[string]string{"#count": "visits"},
+		ExpressionAttributeValues: []dynamodb.Attribute{*dynamodb.NewNumericAttribute(":one", "1")},
+		ReturnValues:              dynamodb.RETURN_VALUES_UPDATED_NEW,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(req.Target, Equals, "DynamoDB_20120810.UpdateItem")
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{
+		"TableName": "Logins",
+		"Key": map[string]interface{}{
+			"User": map[string]interface{}{"S": "u1"},
+			"At":   map[string]interface{}{"N": "12"},
+		},
+		"UpdateExpression":          "SET #count = #count + :one",
+		"ConditionExpression":       "attribute_exists(#count)",
+		"ExpressionAttributeNames":  map[string]interface{}{"#count": "visits"},
+		"ExpressionAttributeValues": map[string]interface{}{":one": map[string]interface{}{"N": "1"}},
+		"ReturnValues":              "UPDATED_NEW",
+	})
+	c.Assert(result.Attributes["visits"].Value, Equals, "8")
+	c.Assert(result.ConsumedCapacity, IsNil)
+
+	// Without options
+	result, err = logins.UpdateItemWithOptions(&dynamodb.Key{HashKey: "u1", RangeKey: "12"}, "REMOVE visits", nil)
+	c.Assert(err, IsNil)
+	c.Assert(req.Body["ConditionExpression"], IsNil)
+	c.Assert(req.Body["ReturnValues"], IsNil)
+
+	_, err = logins.UpdateItemWithOptions(&dynamodb.Key{HashKey: "u1", RangeKey: "12"}, "", nil)
+	c.Assert(err, ErrorMatches, "Update Expression Required")
+}
+
+func (s *ExpressionSuite) TestQueryExpressions(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, `{"Count": 1, "ScannedCount": 2, "Items": [{"User": {"S": "u1"}, "At": {"N": "12"}}],
+			"ConsumedCapacity": {"TableName": "Logins", "CapacityUnits": 0.5}}`
+	})
+	defer done()
+	_, logins := batchTables(server)
+
+	result, err := logins.QueryWithOptions(nil, &dynamodb.QueryOptions{
+		KeyConditionExpression:   "#user = :user AND At > :at",
+		FilterExpression:         "#status = :status",
+		ExpressionAttributeNames: map[string]string{"#user": "User", "#status": "status"},
+		ExpressionAttributeValues: []dynamodb.Attribute{
+			*dynamodb.NewStringAttribute(":user", "u1"),
+			*dynamodb.NewNumericAttribute(":at", "10"),
+			*dynamodb.NewStringAttribute(":status", "ok"),
+		},
+		ReturnConsumedCapacity: dynamodb.RETURN_CONSUMED_CAPACITY_TOTAL,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{
+		"TableName":                "Logins",
+		"KeyConditionExpression":   "#user = :user AND At > :at",
+		"FilterExpression":         "#status = :status",
+		"ExpressionAttributeNames": map[string]interface{}{"#user": "User", "#status": "status"},
+		"ExpressionAttributeValues": map[string]interface{}{
+			":user":   map[string]interface{}{"S": "u1"},
+			":at":     map[string]interface{}{"N": "10"},
+			":status": map[string]interface{}{"S": "ok"},
+		},
+		"ReturnConsumedCapacity": "TOTAL",
+	})
+	c.Assert(result.Items, HasLen, 1)
+	c.Assert(result.ConsumedCapacity, DeepEquals, &dynamodb.ConsumedCapacity{TableName: "Logins", CapacityUnits: 0.5})
+
+	_, err = logins.QueryWithOptions([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("User", "u1"),
+	}, &dynamodb.QueryOptions{KeyConditionExpression: "#user = :user"})
+	c.Assert(err, ErrorMatches, "Key conditions and KeyConditionExpression can't be used together")
+}
+
+func (s *ExpressionSuite) TestScanFilterExpression(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, `{"Count": 0, "ScannedCount": 5, "Items": []}`
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	_, err := users.ScanWithOptions(nil, &dynamodb.ScanOptions{
+		FilterExpression:          "begins_with(#name, :prefix)",
+		ExpressionAttributeNames:  map[string]string{"#name": "name"},
+		ExpressionAttributeValues: []dynamodb.Attribute{*dynamodb.NewStringAttribute(":prefix", "a")},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{
+		"TableName":                 "Users",
+		"FilterExpression":          "begins_with(#name, :prefix)",
+		"ExpressionAttributeNames":  map[string]interface{}{"#name": "name"},
+		"ExpressionAttributeValues": map[string]interface{}{":prefix": map[string]interface{}{"S": "a"}},
+	})
+
+	_, err = users.ScanWithOptions([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("name", "a"),
+	}, &dynamodb.ScanOptions{FilterExpression: "#name = :name"})
+	c.Assert(err, ErrorMatches, "Scan filters and FilterExpression can't be used together")
+}
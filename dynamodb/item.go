@@ -2,14 +2,28 @@ package dynamodb
 
 import simplejson "github.com/bitly/go-simplejson"
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"strconv"
 )
 
 type BatchGetItem struct {
 	Server *Server
 	Keys   map[*Table][]Key
+
+	// Options holds the options of the reads of some of the tables.
+	Options map[*Table]*BatchGetOptions
+}
+
+// BatchGetOptions holds the options of the reads of a table in a
+// BatchGetItem request. ProjectionExpression, with the placeholders of
+// ExpressionAttributeNames, limits the attributes of the items read.
+type BatchGetOptions struct {
+	ConsistentRead           bool
+	ProjectionExpression     string
+	ExpressionAttributeNames map[string]string
 }
 
 type BatchWriteItem struct {
@@ -18,14 +32,14 @@ type BatchWriteItem struct {
 }
 
 func (t *Table) BatchGetItems(keys []Key) *BatchGetItem {
-	batchGetItem := &BatchGetItem{t.Server, make(map[*Table][]Key)}
+	batchGetItem := &BatchGetItem{Server: t.Server, Keys: make(map[*Table][]Key)}
 
 	batchGetItem.Keys[t] = keys
 	return batchGetItem
 }
 
 func (t *Table) BatchWriteItems(itemActions map[string][][]Attribute) *BatchWriteItem {
-	batchWriteItem := &BatchWriteItem{t.Server, make(map[*Table]map[string][][]Attribute)}
+	batchWriteItem := &BatchWriteItem{Server: t.Server, ItemActions: make(map[*Table]map[string][][]Attribute)}
 
 	batchWriteItem.ItemActions[t] = itemActions
 	return batchWriteItem
@@ -36,6 +50,16 @@ func (batchGetItem *BatchGetItem) AddTable(t *Table, keys *[]Key) *BatchGetItem
 	return batchGetItem
 }
 
+// AddTableWithOptions adds the keys of a table read with options.
+func (batchGetItem *BatchGetItem) AddTableWithOptions(t *Table, keys []Key, options *BatchGetOptions) *BatchGetItem {
+	batchGetItem.Keys[t] = keys
+	if batchGetItem.Options == nil {
+		batchGetItem.Options = make(map[*Table]*BatchGetOptions)
+	}
+	batchGetItem.Options[t] = options
+	return batchGetItem
+}
+
 func (batchWriteItem *BatchWriteItem) AddTable(t *Table, itemActions *map[string][][]Attribute) *BatchWriteItem {
 	batchWriteItem.ItemActions[t] = *itemActions
 	return batchWriteItem
@@ -43,7 +67,7 @@ func (batchWriteItem *BatchWriteItem) AddTable(t *Table, itemActions *map[string
 
 func (batchGetItem *BatchGetItem) Execute() (map[string][]map[string]*Attribute, error) {
 	q := NewEmptyQuery()
-	q.AddGetRequestItems(batchGetItem.Keys)
+	q.buffer["RequestItems"] = getRequestItems(batchGetItem.Keys, batchGetItem.Options)
 
 	jsonResponse, err := batchGetItem.Server.queryServer("DynamoDB_20120810.BatchGetItem", q)
 	if err != nil {
@@ -57,27 +81,35 @@ func (batchGetItem *BatchGetItem) Execute() (map[string][]map[string]*Attribute,
 	}
 
 	results := make(map[string][]map[string]*Attribute)
+	if err := parseBatchGetResponses(json, jsonResponse, results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
 
+// parseBatchGetResponses adds the items of a BatchGetItem response to
+// results.
+func parseBatchGetResponses(json *simplejson.Json, jsonResponse []byte, results map[string][]map[string]*Attribute) error {
 	tables, err := json.Get("Responses").Map()
 	if err != nil {
 		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-		return nil, errors.New(message)
+		return errors.New(message)
 	}
 
 	for table, entries := range tables {
-		var tableResult []map[string]*Attribute
+		tableResult := results[table]
 
 		jsonEntriesArray, ok := entries.([]interface{})
 		if !ok {
 			message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-			return nil, errors.New(message)
+			return errors.New(message)
 		}
 
 		for _, entry := range jsonEntriesArray {
 			item, ok := entry.(map[string]interface{})
 			if !ok {
 				message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-				return nil, errors.New(message)
+				return errors.New(message)
 			}
 
 			unmarshalledItem := parseAttributes(item)
@@ -87,7 +119,7 @@ func (batchGetItem *BatchGetItem) Execute() (map[string][]map[string]*Attribute,
 		results[table] = tableResult
 	}
 
-	return results, nil
+	return nil
 }
 
 func (batchWriteItem *BatchWriteItem) Execute() (map[string]interface{}, error) {
@@ -115,7 +147,7 @@ func (batchWriteItem *BatchWriteItem) Execute() (map[string]interface{}, error)
 	if len(unprocessed) == 0 {
 		return nil, nil
 	} else {
-		return unprocessed, errors.New("One or more unprocessed items.")
+		return unprocessed, ErrUnprocessedItems
 	}
 
 }
@@ -306,7 +338,7 @@ func (t *Table) modifyAttributesWithExpressions(key *PrimaryKey, UpdateExpressio
 		q.AddExpressionAttributeNames(ExpressionAttributeNames)
 	}
 
-	if ExpressionAttributeValues != nil && len(ExpressionAttributeNames) != 0 {
+	if ExpressionAttributeValues != nil && len(ExpressionAttributeValues) != 0 {
 		q.AddExpressionAttributeValues(ExpressionAttributeValues)
 	}
 
@@ -346,71 +378,332 @@ func (t *Table) executeUpdate(q *Query) (bool, error) {
 	return true, nil
 }
 
+// Values of the ReturnValues parameter of write requests. PutItem and
+// DeleteItem requests only take RETURN_VALUES_NONE and
+// RETURN_VALUES_ALL_OLD.
+const (
+	RETURN_VALUES_NONE        = "NONE"
+	RETURN_VALUES_ALL_OLD     = "ALL_OLD"
+	RETURN_VALUES_UPDATED_OLD = "UPDATED_OLD"
+	RETURN_VALUES_ALL_NEW     = "ALL_NEW"
+	RETURN_VALUES_UPDATED_NEW = "UPDATED_NEW"
+)
+
+// Values of the ReturnConsumedCapacity parameter of requests.
+const (
+	RETURN_CONSUMED_CAPACITY_NONE    = "NONE"
+	RETURN_CONSUMED_CAPACITY_TOTAL   = "TOTAL"
+	RETURN_CONSUMED_CAPACITY_INDEXES = "INDEXES"
+)
+
+// WriteOptions holds the optional parameters of PutItem, UpdateItem and
+// DeleteItem requests. The write is only made if the item meets
+// ConditionExpression, and fails with a *ConditionalCheckFailedError
+// otherwise. The expressions refer to attribute names with placeholders
+// starting with #, as for reserved words, given in
+// ExpressionAttributeNames, and to values with placeholders starting with
+// :, given as the names of ExpressionAttributeValues.
+type WriteOptions struct {
+	ConditionExpression       string
+	ExpressionAttributeNames  map[string]string
+	ExpressionAttributeValues []Attribute
+
+	// ReturnValues is the attributes returned, as one of the
+	// RETURN_VALUES_ constants.
+	ReturnValues string
+
+	// ReturnConsumedCapacity is one of the RETURN_CONSUMED_CAPACITY_
+	// constants.
+	ReturnConsumedCapacity string
+}
+
+// WriteResult holds the response to a write request. Attributes holds the
+// attributes asked for with ReturnValues.
+type WriteResult struct {
+	Attributes       map[string]*Attribute
+	ConsumedCapacity *ConsumedCapacity
+}
+
+// addWriteOptions adds options to a write request, checking its
+// ReturnValues is among returnValues.
+func (q *Query) addWriteOptions(options *WriteOptions, returnValues ...string) error {
+	if options == nil {
+		return nil
+	}
+	if options.ReturnValues != "" {
+		valid := false
+		for _, v := range returnValues {
+			valid = valid || v == options.ReturnValues
+		}
+		if !valid {
+			return fmt.Errorf("Invalid ReturnValues %s", options.ReturnValues)
+		}
+		q.AddReturnValues(options.ReturnValues)
+	}
+	q.addExpressions(options.ExpressionAttributeNames, options.ExpressionAttributeValues, options.ReturnConsumedCapacity)
+	if options.ConditionExpression != "" {
+		q.AddConditionExpression(options.ConditionExpression)
+	}
+	return nil
+}
+
+// addExpressions adds the placeholders of the expressions of a request,
+// and its ReturnConsumedCapacity.
+func (q *Query) addExpressions(names map[string]string, values []Attribute, returnConsumedCapacity string) {
+	if len(names) > 0 {
+		q.AddExpressionAttributeNames(names)
+	}
+	if len(values) > 0 {
+		q.AddExpressionAttributeValues(values)
+	}
+	if returnConsumedCapacity != "" {
+		q.AddReturnConsumedCapacity(returnConsumedCapacity)
+	}
+}
+
+// writeItem runs a write request.
+func (s *Server) writeItem(target string, q *Query) (*WriteResult, error) {
+	jsonResponse, err := s.queryServer(target, q)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Attributes       map[string]interface{}
+		ConsumedCapacity *ConsumedCapacity
+	}
+	if err := json.Unmarshal(jsonResponse, &resp); err != nil {
+		return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+	}
+	result := &WriteResult{ConsumedCapacity: resp.ConsumedCapacity}
+	if resp.Attributes != nil {
+		result.Attributes = parseAttributes(resp.Attributes)
+	}
+	return result, nil
+}
+
+// PutItemWithOptions puts an item with the given key and attributes,
+// replacing the item with the same key.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_PutItem.html for details.
+func (t *Table) PutItemWithOptions(hashKey, rangeKey string, attributes []Attribute, options *WriteOptions) (*WriteResult, error) {
+	if len(attributes) == 0 {
+		return nil, errors.New("At least one attribute is required.")
+	}
+
+	q := NewQuery(t)
+	q.AddItem(append(attributes, t.Key.Clone(hashKey, rangeKey)...))
+	if err := q.addWriteOptions(options, RETURN_VALUES_NONE, RETURN_VALUES_ALL_OLD); err != nil {
+		return nil, err
+	}
+	return t.Server.writeItem(target("PutItem"), q)
+}
+
+// UpdateItemWithOptions updates the item with the given key as given by
+// updateExpression, creating it if needed.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_UpdateItem.html for details.
+func (t *Table) UpdateItemWithOptions(key *Key, updateExpression string, options *WriteOptions) (*WriteResult, error) {
+	if updateExpression == "" {
+		return nil, errors.New("Update Expression Required")
+	}
+
+	q := NewQuery(t)
+	q.AddKey(t, key)
+	q.AddUpdateExpression(updateExpression)
+	err := q.addWriteOptions(options, RETURN_VALUES_NONE, RETURN_VALUES_ALL_OLD,
+		RETURN_VALUES_UPDATED_OLD, RETURN_VALUES_ALL_NEW, RETURN_VALUES_UPDATED_NEW)
+	if err != nil {
+		return nil, err
+	}
+	return t.Server.writeItem(target("UpdateItem"), q)
+}
+
+// DeleteItemWithOptions deletes the item with the given key.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_DeleteItem.html for details.
+func (t *Table) DeleteItemWithOptions(key *Key, options *WriteOptions) (*WriteResult, error) {
+	q := NewQuery(t)
+	q.AddKey(t, key)
+	if err := q.addWriteOptions(options, RETURN_VALUES_NONE, RETURN_VALUES_ALL_OLD); err != nil {
+		return nil, err
+	}
+	return t.Server.writeItem(target("DeleteItem"), q)
+}
+
+// PutStruct puts the item with the attributes of the struct v, as
+// returned by Marshal, which include its non-empty key.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_PutItem.html for details.
+func (t *Table) PutStruct(v interface{}, options *WriteOptions) (*WriteResult, error) {
+	item, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range []*Attribute{t.Key.KeyAttribute, t.Key.RangeAttribute} {
+		if k == nil {
+			continue
+		}
+		if a, ok := item[k.Name]; !ok || a.Type != k.Type || a.Value == "" && len(a.Bytes) == 0 {
+			return nil, fmt.Errorf("The item needs its key attribute %s of type %s", k.Name, k.Type)
+		}
+	}
+
+	q := NewQuery(t)
+	q.buffer["Item"] = item
+	if err := q.addWriteOptions(options, RETURN_VALUES_NONE, RETURN_VALUES_ALL_OLD); err != nil {
+		return nil, err
+	}
+	return t.Server.writeItem(target("PutItem"), q)
+}
+
+// GetStruct stores the item with the given key in the struct v points to,
+// as by Unmarshal. It fails with ErrNotFound if there's no such item.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_GetItem.html for details.
+func (t *Table) GetStruct(key *Key, v interface{}) error {
+	return t.GetStructWithOptions(key, nil, v)
+}
+
+// GetStructWithOptions reads the item with the given key in v, as
+// GetStruct, with the options of GetItemWithOptions.
+func (t *Table) GetStructWithOptions(key *Key, options *GetOptions, v interface{}) error {
+	result, err := t.GetItemWithOptions(key, options)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(result.ItemValue, v)
+}
+
+// GetOptions holds the optional parameters of a GetItem request, as in
+// QueryOptions. Reads are eventually consistent unless ConsistentRead is
+// set.
+type GetOptions struct {
+	ConsistentRead           bool
+	ProjectionExpression     string
+	ExpressionAttributeNames map[string]string
+	ReturnConsumedCapacity   string
+}
+
+// GetResult holds the response to a GetItem request. ItemValue holds the
+// same item as Item, with attributes of any type.
+type GetResult struct {
+	Item             map[string]*Attribute
+	ItemValue        map[string]AttributeValue
+	ConsumedCapacity *ConsumedCapacity
+}
+
+// GetItemWithOptions reads the item with the given key. It fails with
+// ErrNotFound if there's no such item.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_GetItem.html for details.
+func (t *Table) GetItemWithOptions(key *Key, options *GetOptions) (*GetResult, error) {
+	q := NewQuery(t)
+	q.AddKey(t, key)
+	if options != nil {
+		q.ConsistentRead(options.ConsistentRead)
+		if options.ProjectionExpression != "" {
+			q.AddProjectionExpression(options.ProjectionExpression)
+		}
+		q.addExpressions(options.ExpressionAttributeNames, nil, options.ReturnConsumedCapacity)
+	}
+
+	jsonResponse, err := t.Server.queryServer(target("GetItem"), q)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Item             json.RawMessage
+		ConsumedCapacity *ConsumedCapacity
+	}
+	if err := json.Unmarshal(jsonResponse, &resp); err != nil {
+		return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+	}
+	if len(resp.Item) == 0 || string(resp.Item) == "null" {
+		return nil, ErrNotFound
+	}
+	var item map[string]interface{}
+	result := &GetResult{ConsumedCapacity: resp.ConsumedCapacity}
+	if json.Unmarshal(resp.Item, &item) != nil || json.Unmarshal(resp.Item, &result.ItemValue) != nil {
+		return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+	}
+	result.Item = parseAttributes(item)
+	return result, nil
+}
+
 func parseAttributes(s map[string]interface{}) map[string]*Attribute {
 	results := map[string]*Attribute{}
 
 	for key, value := range s {
-		if v, ok := value.(map[string]interface{}); ok {
-			if val, ok := v[TYPE_STRING].(string); ok {
-				results[key] = &Attribute{
-					Type:  TYPE_STRING,
-					Name:  key,
-					Value: val,
-				}
-			} else if val, ok := v[TYPE_NUMBER].(string); ok {
-				results[key] = &Attribute{
-					Type:  TYPE_NUMBER,
-					Name:  key,
-					Value: val,
-				}
-			} else if val, ok := v[TYPE_BINARY].(string); ok {
-				results[key] = &Attribute{
-					Type:  TYPE_BINARY,
-					Name:  key,
-					Value: val,
-				}
-			} else if vals, ok := v[TYPE_STRING_SET].([]interface{}); ok {
-				arry := make([]string, len(vals))
-				for i, ivalue := range vals {
-					if val, ok := ivalue.(string); ok {
-						arry[i] = val
-					}
-				}
-				results[key] = &Attribute{
-					Type:      TYPE_STRING_SET,
-					Name:      key,
-					SetValues: arry,
-				}
-			} else if vals, ok := v[TYPE_NUMBER_SET].([]interface{}); ok {
-				arry := make([]string, len(vals))
-				for i, ivalue := range vals {
-					if val, ok := ivalue.(string); ok {
-						arry[i] = val
-					}
-				}
-				results[key] = &Attribute{
-					Type:      TYPE_NUMBER_SET,
-					Name:      key,
-					SetValues: arry,
+		if a := parseAttribute(key, value); a != nil {
+			results[key] = a
+		} else {
+			log.Printf("Unsupported attribute value for %s: %v\n", key, value)
+		}
+	}
+
+	return results
+}
+
+// parseAttribute parses the value of an attribute as received from
+// DynamoDB, returning nil if it isn't valid.
+func parseAttribute(name string, value interface{}) *Attribute {
+	v, ok := value.(map[string]interface{})
+	if !ok || len(v) != 1 {
+		return nil
+	}
+	a := &Attribute{Name: name}
+	for t, val := range v {
+		a.Type = t
+		switch t {
+		case TYPE_STRING, TYPE_NUMBER, TYPE_BINARY:
+			if a.Value, ok = val.(string); !ok {
+				return nil
+			}
+		case TYPE_BOOL:
+			b, ok := val.(bool)
+			if !ok {
+				return nil
+			}
+			a.Value = strconv.FormatBool(b)
+		case TYPE_NULL:
+		case TYPE_STRING_SET, TYPE_NUMBER_SET, TYPE_BINARY_SET:
+			vals, ok := val.([]interface{})
+			if !ok {
+				return nil
+			}
+			a.SetValues = make([]string, len(vals))
+			for i, ivalue := range vals {
+				if a.SetValues[i], ok = ivalue.(string); !ok {
+					return nil
 				}
-			} else if vals, ok := v[TYPE_BINARY_SET].([]interface{}); ok {
-				arry := make([]string, len(vals))
-				for i, ivalue := range vals {
-					if val, ok := ivalue.(string); ok {
-						arry[i] = val
-					}
+			}
+		case TYPE_LIST:
+			vals, ok := val.([]interface{})
+			if !ok {
+				return nil
+			}
+			a.List = make([]Attribute, len(vals))
+			for i, ivalue := range vals {
+				element := parseAttribute("", ivalue)
+				if element == nil {
+					return nil
 				}
-				results[key] = &Attribute{
-					Type:      TYPE_BINARY_SET,
-					Name:      key,
-					SetValues: arry,
+				a.List[i] = *element
+			}
+		case TYPE_MAP:
+			m, ok := val.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			a.Map = make(map[string]*Attribute, len(m))
+			for key, ivalue := range m {
+				if a.Map[key] = parseAttribute(key, ivalue); a.Map[key] == nil {
+					return nil
 				}
 			}
-		} else {
-			log.Printf("type assertion to map[string] interface{} failed for : %s\n ", value)
+		default:
+			return nil
 		}
-
 	}
-
-	return results
+	return a
 }
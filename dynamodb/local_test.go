@@ -0,0 +1,179 @@
+package dynamodb_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/dynamodb"
+	. "gopkg.in/check.v1"
+)
+
+// LocalSuite runs requests against DynamoDB local, as set up by
+// setUpAuth, to check they are made as DynamoDB expects.
+type LocalSuite struct {
+	server       *dynamodb.Server
+	users        *dynamodb.Table
+	logins       *dynamodb.Table
+	descriptions []dynamodb.TableDescriptionT
+}
+
+var _ = Suite(&LocalSuite{})
+
+func (s *LocalSuite) SetUpSuite(c *C) {
+	setUpAuth(c)
+	if !*local {
+		c.Skip("LocalSuite only runs against DynamoDB local")
+	}
+	s.server = dynamodb.New(dynamodb_auth, dynamodb_region)
+	s.server.RetryPolicy = &aws.NoRetry
+
+	// The tables are named uniquely so that runs don't clash
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	s.users = s.createTable(c, "Users"+suffix, dynamodb.AttributeDefinitionT{"Id", "S"}, nil)
+	s.logins = s.createTable(c, "Logins"+suffix, dynamodb.AttributeDefinitionT{"User", "S"}, &dynamodb.AttributeDefinitionT{"At", "N"})
+}
+
+func (s *LocalSuite) createTable(c *C, name string, hash dynamodb.AttributeDefinitionT, rang *dynamodb.AttributeDefinitionT) *dynamodb.Table {
+	description := dynamodb.TableDescriptionT{
+		TableName:             name,
+		AttributeDefinitions:  []dynamodb.AttributeDefinitionT{hash},
+		KeySchema:             []dynamodb.KeySchemaT{{hash.Name, "HASH"}},
+		ProvisionedThroughput: dynamodb.ProvisionedThroughputT{ReadCapacityUnits: 5, WriteCapacityUnits: 5},
+	}
+	if rang != nil {
+		description.AttributeDefinitions = append(description.AttributeDefinitions, *rang)
+		description.KeySchema = append(description.KeySchema, dynamodb.KeySchemaT{rang.Name, "RANGE"})
+	}
+	_, err := s.server.CreateTable(description)
+	c.Assert(err, IsNil)
+	s.descriptions = append(s.descriptions, description)
+
+	active, err := s.server.WaitUntilTableActive(name)
+	c.Assert(err, IsNil)
+	pk, err := active.BuildPrimaryKey()
+	c.Assert(err, IsNil)
+	return s.server.NewTable(name, pk)
+}
+
+func (s *LocalSuite) TearDownSuite(c *C) {
+	for _, description := range s.descriptions {
+		_, err := s.server.DeleteTable(description)
+		c.Check(err, IsNil)
+	}
+}
+
+func (s *LocalSuite) TestPutGetItem(c *C) {
+	ok, err := s.users.PutItem("u1", "", []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("Name", "Ann"),
+		*dynamodb.NewNumericAttribute("Visits", "1"),
+		*dynamodb.NewBooleanAttribute("Verified", true),
+		*dynamodb.NewStringSetAttribute("Tags", []string{"a", "b"}),
+		*dynamodb.NewMapAttribute("Address", []dynamodb.Attribute{*dynamodb.NewStringAttribute("City", "Paris")}),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+
+	result, err := s.users.GetItemWithOptions(&dynamodb.Key{HashKey: "u1"}, &dynamodb.GetOptions{
+		ProjectionExpression:     "#n, Verified, Address",
+		ExpressionAttributeNames: map[string]string{"#n": "Name"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(result.Item, HasLen, 3)
+	c.Assert(result.Item["Name"], DeepEquals, dynamodb.NewStringAttribute("Name", "Ann"))
+	c.Assert(result.Item["Verified"], DeepEquals, dynamodb.NewBooleanAttribute("Verified", true))
+	c.Assert(result.Item["Address"].Map["City"].Value, Equals, "Paris")
+
+	n, err := s.users.AddInt(&dynamodb.Key{HashKey: "u1"}, "Visits", 2)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, int64(3))
+
+	_, err = s.users.GetItem(&dynamodb.Key{HashKey: "u0"})
+	c.Assert(err, Equals, dynamodb.ErrNotFound)
+}
+
+func (s *LocalSuite) TestBatchAndQuery(c *C) {
+	// More items than a request can write or read at once
+	var puts [][]dynamodb.Attribute
+	var keys []dynamodb.Key
+	for i := 0; i < 30; i++ {
+		at := fmt.Sprint(i)
+		puts = append(puts, []dynamodb.Attribute{
+			*dynamodb.NewStringAttribute("User", "u1"),
+			*dynamodb.NewNumericAttribute("At", at),
+		})
+		keys = append(keys, dynamodb.Key{HashKey: "u1", RangeKey: at})
+	}
+	puts = append(puts, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("User", "u2"),
+		*dynamodb.NewNumericAttribute("At", "1"),
+	})
+	unprocessed, err := s.logins.BatchWriteItems(map[string][][]dynamodb.Attribute{"Put": puts}).ExecuteAll()
+	c.Assert(err, IsNil)
+	c.Assert(unprocessed, HasLen, 0)
+
+	results, unread, err := s.logins.BatchGetItems(keys).ExecuteAll()
+	c.Assert(err, IsNil)
+	c.Assert(unread, HasLen, 0)
+	c.Assert(results[s.logins.Name], HasLen, 30)
+
+	result, err := s.logins.QueryWithOptions([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("User", "u1"),
+		*dynamodb.NewNumericAttributeComparison("At", dynamodb.COMPARISON_GREATER_THAN_OR_EQUAL, 25),
+	}, &dynamodb.QueryOptions{Descending: true, Limit: 3})
+	c.Assert(err, IsNil)
+	c.Assert(result.Items, HasLen, 3)
+	c.Assert(result.Items[0]["At"].Value, Equals, "29")
+	c.Assert(result.LastEvaluatedKey, NotNil)
+
+	pages, err := s.logins.QueryAll(nil, &dynamodb.QueryOptions{
+		KeyConditionExpression:    "#u = :u",
+		ExpressionAttributeNames:  map[string]string{"#u": "User"},
+		ExpressionAttributeValues: []dynamodb.Attribute{*dynamodb.NewStringAttribute(":u", "u1")},
+		Limit:                     7,
+	}, 0, func(item map[string]dynamodb.AttributeValue) error {
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(pages.Count, Equals, int64(30))
+
+	_, err = s.logins.BatchWriteItems(map[string][][]dynamodb.Attribute{"Delete": puts}).ExecuteAll()
+	c.Assert(err, IsNil)
+	count, err := s.logins.CountQuery([]dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("User", "u1")})
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, int64(0))
+}
+
+func (s *LocalSuite) TestTransactions(c *C) {
+	_, err := s.server.TransactWriteItems([]dynamodb.TransactWriteItem{
+		s.users.TransactPut("t1", "", []dynamodb.Attribute{*dynamodb.NewNumericAttribute("Logins", "0")}, nil),
+		s.logins.TransactPut("t1", "1", []dynamodb.Attribute{*dynamodb.NewStringAttribute("From", "web")}, nil),
+	}, nil)
+	c.Assert(err, IsNil)
+
+	// The second update isn't made as the first condition fails
+	_, err = s.server.TransactWriteItems([]dynamodb.TransactWriteItem{
+		s.logins.TransactPut("t1", "1", []dynamodb.Attribute{*dynamodb.NewStringAttribute("From", "app")}, &dynamodb.TransactOptions{
+			ConditionExpression:      "attribute_not_exists(#u)",
+			ExpressionAttributeNames: map[string]string{"#u": "User"},
+		}),
+		s.users.TransactUpdate(&dynamodb.Key{HashKey: "t1"}, "ADD Logins :one", &dynamodb.TransactOptions{
+			ExpressionAttributeValues: []dynamodb.Attribute{*dynamodb.NewNumericAttribute(":one", "1")},
+		}),
+	}, nil)
+	canceled, ok := err.(*dynamodb.TransactionCanceledError)
+	c.Assert(ok, Equals, true, Commentf("%v", err))
+	c.Assert(canceled.ConditionFailed(0), Equals, true)
+	c.Assert(canceled.ConditionFailed(1), Equals, false)
+
+	result, err := s.server.TransactGetItems([]dynamodb.TransactGetItem{
+		{Table: s.users, Key: &dynamodb.Key{HashKey: "t1"}},
+		{Table: s.logins, Key: &dynamodb.Key{HashKey: "t1", RangeKey: "1"}},
+		{Table: s.users, Key: &dynamodb.Key{HashKey: "t0"}},
+	}, "")
+	c.Assert(err, IsNil)
+	c.Assert(result.Items, HasLen, 3)
+	c.Assert(result.Items[0]["Logins"].Value, Equals, "0")
+	c.Assert(result.Items[1]["From"].Value, Equals, "web")
+	c.Assert(result.Items[2], IsNil)
+}
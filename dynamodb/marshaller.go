@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 )
 
@@ -45,7 +46,7 @@ func UnmarshalAttributes(attributesRef *map[string]*Attribute, m interface{}) er
 	for _, f := range cachedTypeFields(v.Type()) { // loop on each field
 		fv := fieldByIndex(v, f.index)
 		correlatedAttribute := attributes[f.name]
-		if correlatedAttribute == nil {
+		if correlatedAttribute == nil || correlatedAttribute.Type == TYPE_NULL {
 			continue
 		}
 		err := unmarshallAttribute(correlatedAttribute, fv)
@@ -68,6 +69,10 @@ func (builder *attributeBuilder) Push(attribute *Attribute) {
 func unmarshallAttribute(a *Attribute, v reflect.Value) error {
 	switch v.Kind() {
 	case reflect.Bool:
+		if a.Type == TYPE_BOOL {
+			v.SetBool(a.Value == "true")
+			break
+		}
 		n, err := strconv.ParseInt(a.Value, 10, 64)
 		if err != nil {
 			return fmt.Errorf("UnmarshalTypeError (bool) %#v: %#v", a.Value, err)
@@ -305,6 +310,411 @@ func isEmptyValueToOmit(v reflect.Value) bool {
 	return false
 }
 
+var timeType = reflect.TypeOf(time.Time{})
+
+// Marshal returns the attributes of a struct, or of a pointer to a struct,
+// named by the dynamodb tags of its fields as encoding/json does with
+// json tags. A field is skipped with the tag "-", or if it has the zero
+// value of its type with the option omitempty.
+//
+// Strings are S values, numbers N values, booleans BOOL values, byte
+// slices B values and time.Time values S values in RFC 3339 format. Other
+// slices and arrays are L values, or with the option set SS, NS or BS
+// values, and maps with string keys and structs are M values. Nil
+// pointers, interfaces, slices and maps, and empty sets, are NULL values.
+//
+// time.Time fields with the option ttl, as the TTL attribute of a table,
+// are N values of seconds since the Unix epoch instead, or NULL values
+// for the zero time, which then leaves the item without expiration.
+func Marshal(v interface{}) (map[string]AttributeValue, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("UnsupportedTypeError %#v", reflect.TypeOf(v))
+	}
+	return marshalStruct(rv)
+}
+
+func marshalStruct(v reflect.Value) (map[string]AttributeValue, error) {
+	m := make(map[string]AttributeValue)
+	for _, f := range cachedTagFields(v.Type(), "dynamodb") {
+		fv := fieldByIndex(v, f.index)
+		if !fv.IsValid() || f.omitEmpty && (isEmptyValue(fv) || fv.IsZero()) {
+			continue
+		}
+		var a AttributeValue
+		var err error
+		if f.ttl {
+			a, err = marshalTTL(fv)
+		} else {
+			a, err = marshalValue(fv, f.set)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[f.name] = a
+	}
+	return m, nil
+}
+
+func marshalValue(v reflect.Value, set bool) (AttributeValue, error) {
+	null := AttributeValue{Type: TYPE_NULL}
+	if !v.IsValid() {
+		return null, nil
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		return AttributeValue{Type: TYPE_STRING, Value: t.Format(time.RFC3339Nano)}, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return null, nil
+		}
+		return marshalValue(v.Elem(), set)
+
+	case reflect.String:
+		return AttributeValue{Type: TYPE_STRING, Value: v.String()}, nil
+
+	case reflect.Bool:
+		return AttributeValue{Type: TYPE_BOOL, Bool: v.Bool()}, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64:
+		n, err := numericReflectedValueString(v)
+		if err != nil {
+			return null, err
+		}
+		return AttributeValue{Type: TYPE_NUMBER, Value: n}, nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return null, nil
+		}
+		elem := v.Type().Elem()
+		if v.Kind() == reflect.Slice && elem.Kind() == reflect.Uint8 {
+			return AttributeValue{Type: TYPE_BINARY, Bytes: v.Bytes()}, nil
+		}
+		if set {
+			return marshalSet(v)
+		}
+		a := AttributeValue{Type: TYPE_LIST, List: make([]AttributeValue, v.Len())}
+		for i := range a.List {
+			var err error
+			if a.List[i], err = marshalValue(v.Index(i), false); err != nil {
+				return null, err
+			}
+		}
+		return a, nil
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return null, fmt.Errorf("UnsupportedTypeError %#v", v.Type())
+		}
+		if v.IsNil() {
+			return null, nil
+		}
+		a := AttributeValue{Type: TYPE_MAP, Map: make(map[string]AttributeValue, v.Len())}
+		for _, key := range v.MapKeys() {
+			value, err := marshalValue(v.MapIndex(key), false)
+			if err != nil {
+				return null, err
+			}
+			a.Map[key.String()] = value
+		}
+		return a, nil
+
+	case reflect.Struct:
+		m, err := marshalStruct(v)
+		if err != nil {
+			return null, err
+		}
+		return AttributeValue{Type: TYPE_MAP, Map: m}, nil
+	}
+	return null, fmt.Errorf("UnsupportedTypeError %#v", v.Type())
+}
+
+// marshalTTL returns the N value of a time.Time TTL attribute, in seconds
+// since the Unix epoch. Numbers are taken to be in seconds already.
+func marshalTTL(v reflect.Value) (AttributeValue, error) {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		return AttributeValue{Type: TYPE_NULL}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return marshalValue(v, false)
+	}
+	if v.Type() != timeType {
+		return AttributeValue{}, fmt.Errorf("UnsupportedTTLTypeError %#v", v.Type())
+	}
+	t := v.Interface().(time.Time)
+	if t.IsZero() {
+		return AttributeValue{Type: TYPE_NULL}, nil
+	}
+	return AttributeValue{Type: TYPE_NUMBER, Value: strconv.FormatInt(t.Unix(), 10)}, nil
+}
+
+// marshalSet returns the SS, NS or BS value of the slice or array v.
+func marshalSet(v reflect.Value) (AttributeValue, error) {
+	if v.Len() == 0 {
+		return AttributeValue{Type: TYPE_NULL}, nil
+	}
+	elem := v.Type().Elem()
+	switch elem.Kind() {
+	case reflect.String:
+		a := AttributeValue{Type: TYPE_STRING_SET, SetValues: make([]string, v.Len())}
+		for i := range a.SetValues {
+			a.SetValues[i] = v.Index(i).String()
+		}
+		return a, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64:
+		a := AttributeValue{Type: TYPE_NUMBER_SET, SetValues: make([]string, v.Len())}
+		for i := range a.SetValues {
+			var err error
+			if a.SetValues[i], err = numericReflectedValueString(v.Index(i)); err != nil {
+				return AttributeValue{}, err
+			}
+		}
+		return a, nil
+
+	case reflect.Slice:
+		if elem.Elem().Kind() == reflect.Uint8 {
+			a := AttributeValue{Type: TYPE_BINARY_SET, BinarySetValues: make([][]byte, v.Len())}
+			for i := range a.BinarySetValues {
+				a.BinarySetValues[i] = v.Index(i).Bytes()
+			}
+			return a, nil
+		}
+	}
+	return AttributeValue{}, fmt.Errorf("UnsupportedSetTypeError %#v", v.Type())
+}
+
+// Unmarshal stores attributes in the struct v points to, as named by Marshal.
+// The attributes without field are ignored, and the fields of NULL
+// attributes are set to their zero values. Numbers are also stored in
+// strings, booleans in N values as by MarshalAttributes, and time.Time
+// values in N values of seconds since the Unix epoch, as TTL attributes,
+// in UTC.
+func Unmarshal(attributes map[string]AttributeValue, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("InvalidUnmarshalError %#v", reflect.TypeOf(v))
+	}
+	return unmarshalStruct(attributes, rv.Elem())
+}
+
+func unmarshalStruct(attributes map[string]AttributeValue, v reflect.Value) error {
+	for _, f := range cachedTagFields(v.Type(), "dynamodb") {
+		a, ok := attributes[f.name]
+		if !ok {
+			continue
+		}
+		fv := v
+		for _, i := range f.index {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			fv = fv.Field(i)
+		}
+		if err := unmarshalValue(a, fv); err != nil {
+			return fmt.Errorf("%s: %v", f.name, err)
+		}
+	}
+	return nil
+}
+
+func unmarshalValue(a AttributeValue, v reflect.Value) error {
+	if a.Type == TYPE_NULL {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	typeError := func() error {
+		return fmt.Errorf("UnmarshalTypeError %s into %s", a.Type, v.Type())
+	}
+	if v.Type() == timeType {
+		if a.Type == TYPE_NUMBER {
+			// A TTL attribute, in seconds since the Unix epoch
+			seconds, err := strconv.ParseInt(a.Value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("UnmarshalTypeError (time) %#v: %v", a.Value, err)
+			}
+			v.Set(reflect.ValueOf(time.Unix(seconds, 0).UTC()))
+			return nil
+		}
+		if a.Type != TYPE_STRING {
+			return typeError()
+		}
+		t, err := time.Parse(time.RFC3339Nano, a.Value)
+		if err != nil {
+			return fmt.Errorf("UnmarshalTypeError (time) %#v: %v", a.Value, err)
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshalValue(a, v.Elem())
+
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return typeError()
+		}
+		i, err := a.interfaceValue()
+		if err != nil {
+			return err
+		}
+		if i != nil {
+			v.Set(reflect.ValueOf(i))
+		} else {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+
+	case reflect.String:
+		if a.Type != TYPE_STRING && a.Type != TYPE_NUMBER {
+			return typeError()
+		}
+		v.SetString(a.Value)
+		return nil
+
+	case reflect.Bool:
+		switch a.Type {
+		case TYPE_BOOL:
+			v.SetBool(a.Bool)
+		case TYPE_NUMBER:
+			return unmarshallAttribute(&Attribute{Type: a.Type, Value: a.Value}, v)
+		default:
+			return typeError()
+		}
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64:
+		if a.Type != TYPE_NUMBER {
+			return typeError()
+		}
+		return unmarshallAttribute(&Attribute{Type: a.Type, Value: a.Value}, v)
+
+	case reflect.Slice, reflect.Array:
+		elem := v.Type().Elem()
+		if v.Kind() == reflect.Slice && elem.Kind() == reflect.Uint8 {
+			if a.Type != TYPE_BINARY {
+				return typeError()
+			}
+			v.SetBytes(append([]byte(nil), a.Bytes...))
+			return nil
+		}
+		var values []AttributeValue
+		switch a.Type {
+		case TYPE_LIST:
+			values = a.List
+		case TYPE_STRING_SET, TYPE_NUMBER_SET:
+			t := map[string]string{TYPE_STRING_SET: TYPE_STRING, TYPE_NUMBER_SET: TYPE_NUMBER}[a.Type]
+			for _, s := range a.SetValues {
+				values = append(values, AttributeValue{Type: t, Value: s})
+			}
+		case TYPE_BINARY_SET:
+			for _, b := range a.BinarySetValues {
+				values = append(values, AttributeValue{Type: TYPE_BINARY, Bytes: b})
+			}
+		default:
+			return typeError()
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(values), len(values)))
+		} else {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		for i, value := range values {
+			if i >= v.Len() {
+				break
+			}
+			if err := unmarshalValue(value, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if a.Type != TYPE_MAP || v.Type().Key().Kind() != reflect.String {
+			return typeError()
+		}
+		m := reflect.MakeMapWithSize(v.Type(), len(a.Map))
+		for key, value := range a.Map {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := unmarshalValue(value, elem); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+		v.Set(m)
+		return nil
+
+	case reflect.Struct:
+		if a.Type != TYPE_MAP {
+			return typeError()
+		}
+		return unmarshalStruct(a.Map, v)
+	}
+	return fmt.Errorf("UnsupportedTypeError %#v", v.Type())
+}
+
+// interfaceValue returns the value of a as stored in an empty interface:
+// a string, a float64, a []byte, a bool, nil, a []string for SS and NS
+// values, a [][]byte, a []interface{} or a map[string]interface{}.
+func (a AttributeValue) interfaceValue() (interface{}, error) {
+	switch a.Type {
+	case TYPE_STRING:
+		return a.Value, nil
+	case TYPE_NUMBER:
+		n, err := strconv.ParseFloat(a.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("UnmarshalTypeError (number) %#v: %v", a.Value, err)
+		}
+		return n, nil
+	case TYPE_BINARY:
+		return a.Bytes, nil
+	case TYPE_BOOL:
+		return a.Bool, nil
+	case TYPE_NULL:
+		return nil, nil
+	case TYPE_STRING_SET, TYPE_NUMBER_SET:
+		return a.SetValues, nil
+	case TYPE_BINARY_SET:
+		return a.BinarySetValues, nil
+	case TYPE_LIST:
+		list := make([]interface{}, len(a.List))
+		for i, value := range a.List {
+			var err error
+			if list[i], err = value.interfaceValue(); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case TYPE_MAP:
+		m := make(map[string]interface{}, len(a.Map))
+		for key, value := range a.Map {
+			var err error
+			if m[key], err = value.interfaceValue(); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("Invalid attribute type %q", a.Type)
+}
+
 // ---------------- Below are copied handy functions from http://golang.org/src/pkg/encoding/json/encode.go --------------------------------
 func isEmptyValue(v reflect.Value) bool {
 	switch v.Kind() {
@@ -345,6 +755,8 @@ type field struct {
 	typ       reflect.Type
 	omitEmpty bool
 	quoted    bool
+	set       bool
+	ttl       bool
 }
 
 // byName sorts field by name, breaking ties with depth,
@@ -442,10 +854,11 @@ func parseTag(tag string) (string, tagOptions) {
 	return tag, tagOptions("")
 }
 
-// typeFields returns a list of fields that JSON should recognize for the given type.
+// typeFields returns a list of fields that JSON should recognize for the given type,
+// as named by their tagName tags.
 // The algorithm is breadth-first search over the set of structs to include - the top struct
 // and then any reachable anonymous structs.
-func typeFields(t reflect.Type) []field {
+func typeFields(t reflect.Type, tagName string) []field {
 	// Anonymous fields to explore at the current level and the next.
 	current := []field{}
 	next := []field{{typ: t}}
@@ -476,7 +889,7 @@ func typeFields(t reflect.Type) []field {
 				if sf.PkgPath != "" { // unexported
 					continue
 				}
-				tag := sf.Tag.Get("json")
+				tag := sf.Tag.Get(tagName)
 				if tag == "-" {
 					continue
 				}
@@ -501,7 +914,7 @@ func typeFields(t reflect.Type) []field {
 						name = sf.Name
 					}
 					fields = append(fields, field{name, tagged, index, ft,
-						opts.Contains("omitempty"), opts.Contains("string")})
+						opts.Contains("omitempty"), opts.Contains("string"), opts.Contains("set"), opts.Contains("ttl")})
 					if count[f.typ] > 1 {
 						// If there were multiple instances, add a second,
 						// so that the annihilation code will see a duplicate.
@@ -595,15 +1008,26 @@ func dominantField(fields []field) (field, bool) {
 	return fields[0], true
 }
 
+type fieldCacheKey struct {
+	t       reflect.Type
+	tagName string
+}
+
 var fieldCache struct {
 	sync.RWMutex
-	m map[reflect.Type][]field
+	m map[fieldCacheKey][]field
 }
 
-// cachedTypeFields is like typeFields but uses a cache to avoid repeated work.
+// cachedTypeFields is like typeFields with json tags but uses a cache to avoid repeated work.
 func cachedTypeFields(t reflect.Type) []field {
+	return cachedTagFields(t, "json")
+}
+
+// cachedTagFields is like typeFields but uses a cache to avoid repeated work.
+func cachedTagFields(t reflect.Type, tagName string) []field {
+	key := fieldCacheKey{t, tagName}
 	fieldCache.RLock()
-	f := fieldCache.m[t]
+	f := fieldCache.m[key]
 	fieldCache.RUnlock()
 	if f != nil {
 		return f
@@ -611,16 +1035,16 @@ func cachedTypeFields(t reflect.Type) []field {
 
 	// Compute fields without lock.
 	// Might duplicate effort but won't hold other computations back.
-	f = typeFields(t)
+	f = typeFields(t, tagName)
 	if f == nil {
 		f = []field{}
 	}
 
 	fieldCache.Lock()
 	if fieldCache.m == nil {
-		fieldCache.m = map[reflect.Type][]field{}
+		fieldCache.m = map[fieldCacheKey][]field{}
 	}
-	fieldCache.m[t] = f
+	fieldCache.m[key] = f
 	fieldCache.Unlock()
 	return f
 }
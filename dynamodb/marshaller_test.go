@@ -1,6 +1,7 @@
 package dynamodb_test
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/goamz/goamz/dynamodb"
@@ -281,3 +282,190 @@ func (s *MarshallerSuite) TestUnmarshalEmptySets(c *C) {
 	expected := testObjectWithNilSets()
 	c.Check(testObj, DeepEquals, expected)
 }
+
+type testOrderLine struct {
+	Sku    string
+	Scores []int `dynamodb:",set"`
+}
+
+type testOrder struct {
+	Customer string `dynamodb:"CustomerId"`
+	Id       string `dynamodb:"OrderId"`
+	Total    float64
+	Quantity uint16
+	Paid     bool
+	Note     string `dynamodb:",omitempty"`
+	Receipt  []byte
+	Tags     []string `dynamodb:",set"`
+	Lines    []testOrderLine
+	Counts   map[string]int
+	Created  time.Time
+	Shipped  *time.Time `dynamodb:",omitempty"`
+	Coupon   *string
+	Ignored  string `dynamodb:"-"`
+	Extra    interface{}
+}
+
+var testOrderCreated = time.Date(2016, 3, 4, 5, 6, 7, 800, time.UTC)
+
+func testOrderObject() *testOrder {
+	return &testOrder{
+		Customer: "c1",
+		Id:       "o1",
+		Total:    12.5,
+		Quantity: 3,
+		Paid:     true,
+		Receipt:  []byte("receipt"),
+		Tags:     []string{"gift", "express"},
+		Lines:    []testOrderLine{{Sku: "s1", Scores: []int{4, 5}}, {Sku: "s2"}},
+		Counts:   map[string]int{"views": 7},
+		Created:  testOrderCreated,
+		Ignored:  "ignored",
+		Extra:    "extra",
+	}
+}
+
+// testOrderJSON is testOrderObject on the wire, with an attribute without
+// field.
+const testOrderJSON = `{
+	"CustomerId": {"S": "c1"},
+	"OrderId": {"S": "o1"},
+	"Total": {"N": "12.5"},
+	"Quantity": {"N": "3"},
+	"Paid": {"BOOL": true},
+	"Receipt": {"B": "cmVjZWlwdA=="},
+	"Tags": {"SS": ["gift", "express"]},
+	"Lines": {"L": [
+		{"M": {"Sku": {"S": "s1"}, "Scores": {"NS": ["4", "5"]}}},
+		{"M": {"Sku": {"S": "s2"}, "Scores": {"NULL": true}}}
+	]},
+	"Counts": {"M": {"views": {"N": "7"}}},
+	"Created": {"S": "2016-03-04T05:06:07.0000008Z"},
+	"Coupon": {"NULL": true},
+	"Extra": {"S": "extra"},
+	"Unknown": {"S": "unknown"}
+}`
+
+func (s *MarshallerSuite) TestMarshalStruct(c *C) {
+	item, err := dynamodb.Marshal(testOrderObject())
+	c.Assert(err, IsNil)
+	c.Assert(item["CustomerId"], DeepEquals, dynamodb.AttributeValue{Type: "S", Value: "c1"})
+	c.Assert(item["Total"], DeepEquals, dynamodb.AttributeValue{Type: "N", Value: "12.5"})
+	c.Assert(item["Paid"], DeepEquals, dynamodb.AttributeValue{Type: "BOOL", Bool: true})
+	c.Assert(item["Receipt"], DeepEquals, dynamodb.AttributeValue{Type: "B", Bytes: []byte("receipt")})
+	c.Assert(item["Tags"], DeepEquals, dynamodb.AttributeValue{Type: "SS", SetValues: []string{"gift", "express"}})
+	c.Assert(item["Coupon"], DeepEquals, dynamodb.AttributeValue{Type: "NULL"})
+	c.Assert(item["Lines"].List[0].Map["Scores"], DeepEquals, dynamodb.AttributeValue{Type: "NS", SetValues: []string{"4", "5"}})
+	for _, name := range []string{"Note", "Shipped", "Ignored"} {
+		_, ok := item[name]
+		c.Check(ok, Equals, false, Commentf("%s", name))
+	}
+
+	// The item is sent as given by testOrderJSON
+	data, err := json.Marshal(item)
+	c.Assert(err, IsNil)
+	var obtained, expected map[string]interface{}
+	c.Assert(json.Unmarshal(data, &obtained), IsNil)
+	c.Assert(json.Unmarshal([]byte(testOrderJSON), &expected), IsNil)
+	delete(expected, "Unknown")
+	c.Assert(obtained, DeepEquals, expected)
+
+	_, err = dynamodb.Marshal("not a struct")
+	c.Assert(err, ErrorMatches, "UnsupportedTypeError .*")
+}
+
+func (s *MarshallerSuite) TestUnmarshalStruct(c *C) {
+	var item map[string]dynamodb.AttributeValue
+	c.Assert(json.Unmarshal([]byte(testOrderJSON), &item), IsNil)
+
+	order := &testOrder{Coupon: new(string)}
+	c.Assert(dynamodb.Unmarshal(item, order), IsNil)
+	expected := testOrderObject()
+	expected.Ignored = ""
+	c.Assert(order, DeepEquals, expected)
+
+	// Numbers keep their precision in strings, and are checked in numbers
+	var numbers struct {
+		Big   string
+		Small int8
+	}
+	err := dynamodb.Unmarshal(map[string]dynamodb.AttributeValue{
+		"Big": {Type: "N", Value: "123456789012345678901234567890.5"},
+	}, &numbers)
+	c.Assert(err, IsNil)
+	c.Assert(numbers.Big, Equals, "123456789012345678901234567890.5")
+	err = dynamodb.Unmarshal(map[string]dynamodb.AttributeValue{"Small": {Type: "N", Value: "300"}}, &numbers)
+	c.Assert(err, ErrorMatches, "Small: UnmarshalTypeError .*")
+	err = dynamodb.Unmarshal(map[string]dynamodb.AttributeValue{"Small": {Type: "S", Value: "1"}}, &numbers)
+	c.Assert(err, ErrorMatches, "Small: UnmarshalTypeError S into int8")
+
+	err = dynamodb.Unmarshal(item, testOrder{})
+	c.Assert(err, ErrorMatches, "InvalidUnmarshalError .*")
+}
+
+func (s *MarshallerSuite) TestAttributeValueJSON(c *C) {
+	for _, data := range []string{
+		`{"S":"text"}`,
+		`{"N":"-1.5E+10"}`,
+		`{"B":"AAEC"}`,
+		`{"BOOL":false}`,
+		`{"NULL":true}`,
+		`{"SS":["a","b"]}`,
+		`{"NS":["1","2.5"]}`,
+		`{"BS":["AAEC","Aw=="]}`,
+		`{"L":[{"S":"a"},{"L":[]},{"NULL":true}]}`,
+		`{"M":{"a":{"M":{"b":{"BOOL":true}}}}}`,
+	} {
+		var a dynamodb.AttributeValue
+		c.Assert(json.Unmarshal([]byte(data), &a), IsNil, Commentf("%s", data))
+		encoded, err := json.Marshal(a)
+		c.Assert(err, IsNil)
+		c.Check(string(encoded), Equals, data)
+	}
+
+	var a dynamodb.AttributeValue
+	c.Assert(json.Unmarshal([]byte(`{"BS":["AAEC"]}`), &a), IsNil)
+	c.Assert(a.BinarySetValues, DeepEquals, [][]byte{{0, 1, 2}})
+	c.Assert(json.Unmarshal([]byte(`{"X":"1"}`), &a), ErrorMatches, `Invalid attribute type "X"`)
+	c.Assert(json.Unmarshal([]byte(`{"S":"1","N":"1"}`), &a), ErrorMatches, "Invalid attribute value .*")
+}
+
+func (s *MarshallerSuite) TestPutAndGetStruct(c *C) {
+	var req *fakeRequest
+	response := `{}`
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, response
+	})
+	defer done()
+	pk, err := indexedTableDescription.BuildPrimaryKey()
+	c.Assert(err, IsNil)
+	orders := server.NewTable("Orders", pk)
+
+	_, err = orders.PutStruct(testOrderObject(), nil)
+	c.Assert(err, IsNil)
+	c.Assert(req.Target, Equals, "DynamoDB_20120810.PutItem")
+	var expected map[string]interface{}
+	c.Assert(json.Unmarshal([]byte(testOrderJSON), &expected), IsNil)
+	delete(expected, "Unknown")
+	c.Assert(req.Body["Item"], DeepEquals, expected)
+
+	_, err = orders.PutStruct(&testOrder{Customer: "c1"}, nil)
+	c.Assert(err, ErrorMatches, "The item needs its key attribute OrderId of type S")
+
+	response = `{"Item": ` + testOrderJSON + `}`
+	order := &testOrder{}
+	err = orders.GetStruct(&dynamodb.Key{HashKey: "c1", RangeKey: "o1"}, order)
+	c.Assert(err, IsNil)
+	c.Assert(req.Target, Equals, "DynamoDB_20120810.GetItem")
+	c.Assert(req.Body["Key"], DeepEquals, map[string]interface{}{
+		"CustomerId": map[string]interface{}{"S": "c1"},
+		"OrderId":    map[string]interface{}{"S": "o1"},
+	})
+	c.Assert(order.Lines[0].Scores, DeepEquals, []int{4, 5})
+	c.Assert(order.Created.Equal(testOrderCreated), Equals, true)
+
+	response = `{}`
+	err = orders.GetStruct(&dynamodb.Key{HashKey: "c1", RangeKey: "o2"}, order)
+	c.Assert(err, Equals, dynamodb.ErrNotFound)
+}
@@ -0,0 +1,94 @@
+package dynamodb
+
+import "errors"
+
+// ErrStopPages is returned by the function of QueryAll or ScanAll to stop
+// reading items, which they then do without error.
+var ErrStopPages = errors.New("Stop pages")
+
+// PagesResult holds the totals of the pages of results read by QueryAll
+// or ScanAll. ConsumedCapacity is the sum of the capacity of the pages,
+// if their request asked for it.
+type PagesResult struct {
+	Pages            int
+	Count            int64
+	ScannedCount     int64
+	ConsumedCapacity *ConsumedCapacity
+}
+
+// add adds the totals of a page of results.
+func (r *PagesResult) add(page *QueryResult) {
+	r.Pages++
+	r.Count += page.Count
+	r.ScannedCount += page.ScannedCount
+	if page.ConsumedCapacity != nil {
+		if r.ConsumedCapacity == nil {
+			r.ConsumedCapacity = &ConsumedCapacity{TableName: page.ConsumedCapacity.TableName}
+		}
+		r.ConsumedCapacity.add(page.ConsumedCapacity)
+	}
+}
+
+// readPages reads the pages of results of read, from the one after start,
+// until the last one, the items given to fn being maxItems, or the first
+// error.
+func readPages(start map[string]AttributeValue, read func(start map[string]AttributeValue) (*QueryResult, error), maxItems int64, fn func(item map[string]AttributeValue) error) (*PagesResult, error) {
+	result := &PagesResult{}
+	var items int64
+	for {
+		page, err := read(start)
+		if err != nil {
+			return result, err
+		}
+		result.add(page)
+		for _, item := range page.ItemValues {
+			if err := fn(item); err == ErrStopPages {
+				return result, nil
+			} else if err != nil {
+				return result, err
+			}
+			if items++; maxItems > 0 && items >= maxItems {
+				return result, nil
+			}
+		}
+		// Pages may be empty without being the last one
+		if len(page.LastEvaluatedKey) == 0 {
+			return result, nil
+		}
+		start = page.LastEvaluatedKey
+	}
+}
+
+// QueryAll queries the table as QueryWithOptions, calling fn with the
+// items of all the pages of results, up to maxItems items unless it is
+// zero. The Limit of options is the most items evaluated by each page,
+// and its ExclusiveStartKey the key after which the first page starts.
+// QueryAll stops at the first error of a request or of fn, or when fn
+// returns ErrStopPages, returning the totals of the pages read so far.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/Query.Pagination.html for details.
+func (t *Table) QueryAll(attributeComparisons []AttributeComparison, options *QueryOptions, maxItems int64, fn func(item map[string]AttributeValue) error) (*PagesResult, error) {
+	query := QueryOptions{}
+	if options != nil {
+		query = *options
+	}
+	return readPages(query.ExclusiveStartKey, func(start map[string]AttributeValue) (*QueryResult, error) {
+		query.ExclusiveStartKey = start
+		return t.QueryWithOptions(attributeComparisons, &query)
+	}, maxItems, fn)
+}
+
+// ScanAll scans the table as ScanWithOptions, calling fn with the items
+// of all the pages of results, as QueryAll.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/Scan.html#Scan.Pagination for details.
+func (t *Table) ScanAll(attributeComparisons []AttributeComparison, options *ScanOptions, maxItems int64, fn func(item map[string]AttributeValue) error) (*PagesResult, error) {
+	scan := ScanOptions{}
+	if options != nil {
+		scan = *options
+	}
+	return readPages(scan.ExclusiveStartKey, func(start map[string]AttributeValue) (*QueryResult, error) {
+		scan.ExclusiveStartKey = start
+		return t.ScanWithOptions(attributeComparisons, &scan)
+	}, maxItems, fn)
+}
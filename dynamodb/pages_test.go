@@ -0,0 +1,144 @@
+package dynamodb_test
+
+import (
+	"errors"
+
+	"github.com/goamz/goamz/dynamodb"
+	. "gopkg.in/check.v1"
+)
+
+type PagesSuite struct{}
+
+var _ = Suite(&PagesSuite{})
+
+// loginPages answers queries with two pages of two logins, then an empty
+// last page.
+func loginPages(c *C, r *fakeRequest) (int, string) {
+	login := func(at string) map[string]interface{} {
+		return map[string]interface{}{"User": map[string]interface{}{"S": "u1"}, "At": map[string]interface{}{"N": at}}
+	}
+	response := map[string]interface{}{"ConsumedCapacity": map[string]interface{}{"TableName": "Logins", "CapacityUnits": 0.5}}
+	start, _ := r.Body["ExclusiveStartKey"].(map[string]interface{})
+	switch {
+	case start == nil:
+		response["Items"] = []interface{}{login("1"), login("2")}
+		response["LastEvaluatedKey"] = login("2")
+	case start["At"].(map[string]interface{})["N"] == "2":
+		response["Items"] = []interface{}{login("3"), login("4")}
+		response["LastEvaluatedKey"] = login("4")
+	default:
+		response["Items"] = []interface{}{}
+	}
+	response["Count"] = len(response["Items"].([]interface{}))
+	response["ScannedCount"] = 2
+	return 200, marshal(c, response)
+}
+
+func (s *PagesSuite) TestQueryAll(c *C) {
+	var requests []*fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		requests = append(requests, r)
+		return loginPages(c, r)
+	})
+	defer done()
+	_, logins := batchTables(server)
+
+	type login struct {
+		User string
+		At   int64
+	}
+	var all []login
+	options := &dynamodb.QueryOptions{
+		KeyConditionExpression:    "#user = :user",
+		ExpressionAttributeNames:  map[string]string{"#user": "User"},
+		ExpressionAttributeValues: []dynamodb.Attribute{*dynamodb.NewStringAttribute(":user", "u1")},
+		ReturnConsumedCapacity:    dynamodb.RETURN_CONSUMED_CAPACITY_TOTAL,
+		Limit:                     2,
+	}
+	result, err := logins.QueryAll(nil, options, 0, func(item map[string]dynamodb.AttributeValue) error {
+		var l login
+		err := dynamodb.Unmarshal(item, &l)
+		all = append(all, l)
+		return err
+	})
+	c.Assert(err, IsNil)
+	c.Assert(all, DeepEquals, []login{{"u1", 1}, {"u1", 2}, {"u1", 3}, {"u1", 4}})
+	c.Assert(result, DeepEquals, &dynamodb.PagesResult{
+		Pages:            3,
+		Count:            4,
+		ScannedCount:     6,
+		ConsumedCapacity: &dynamodb.ConsumedCapacity{TableName: "Logins", CapacityUnits: 1.5},
+	})
+	c.Assert(requests, HasLen, 3)
+	c.Assert(requests[0].Body["ExclusiveStartKey"], IsNil)
+	c.Assert(requests[2].Body["ExclusiveStartKey"], DeepEquals, map[string]interface{}{
+		"User": map[string]interface{}{"S": "u1"},
+		"At":   map[string]interface{}{"N": "4"},
+	})
+	for _, r := range requests {
+		c.Check(r.Body["KeyConditionExpression"], Equals, "#user = :user")
+		c.Check(r.Body["Limit"], Equals, float64(2))
+	}
+	// The options are left as they were
+	c.Assert(options.ExclusiveStartKey, IsNil)
+
+	// The total limit stops in the middle of a page
+	requests, all = nil, nil
+	result, err = logins.QueryAll(nil, options, 3, func(item map[string]dynamodb.AttributeValue) error {
+		all = append(all, login{At: int64(len(all))})
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(all, HasLen, 3)
+	c.Assert(result.Pages, Equals, 2)
+	c.Assert(requests, HasLen, 2)
+
+	// As does the sentinel
+	requests = nil
+	count := 0
+	result, err = logins.QueryAll(nil, options, 0, func(item map[string]dynamodb.AttributeValue) error {
+		if count++; count == 2 {
+			return dynamodb.ErrStopPages
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(result.Pages, Equals, 1)
+	c.Assert(requests, HasLen, 1)
+
+	// While the other errors are returned
+	_, err = logins.QueryAll(nil, options, 0, func(item map[string]dynamodb.AttributeValue) error {
+		return errors.New("handler failed")
+	})
+	c.Assert(err, ErrorMatches, "handler failed")
+}
+
+func (s *PagesSuite) TestScanAll(c *C) {
+	var requests []*fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		requests = append(requests, r)
+		if len(requests) == 3 {
+			return 400, `{"__type": "com.amazonaws.dynamodb.v20120810#ResourceNotFoundException", "message": "Requested resource not found"}`
+		}
+		return loginPages(c, r)
+	})
+	defer done()
+	_, logins := batchTables(server)
+
+	// The pages read before an error are totalled
+	items := 0
+	result, err := logins.ScanAll(nil, &dynamodb.ScanOptions{FilterExpression: "At > :at",
+		ExpressionAttributeValues: []dynamodb.Attribute{*dynamodb.NewNumericAttribute(":at", "0")}},
+		0, func(item map[string]dynamodb.AttributeValue) error {
+			items++
+			return nil
+		})
+	c.Assert(err, ErrorMatches, `Requested resource not found \(ResourceNotFoundException\)`)
+	c.Assert(items, Equals, 4)
+	c.Assert(result.Pages, Equals, 2)
+	c.Assert(result.ConsumedCapacity.CapacityUnits, Equals, 1.0)
+	for _, r := range requests {
+		c.Check(r.Target, Equals, "DynamoDB_20120810.Scan")
+		c.Check(r.Body["FilterExpression"], Equals, "At > :at")
+	}
+}
@@ -0,0 +1,173 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ParallelScanOptions holds the options of ScanSegments. The options of
+// the scans of the segments are as in ScanOptions, whose Segment,
+// TotalSegments and ExclusiveStartKey are ignored.
+type ParallelScanOptions struct {
+	ScanOptions
+
+	// MaxCapacityPerSecond, if not zero, is the most read capacity units
+	// consumed per second by all the segments, which wait between their
+	// requests as needed. As the capacity of a request is only known once
+	// made, it may be exceeded by a request of each segment.
+	MaxCapacityPerSecond float64
+
+	// OnPage is called, if set, with every page of results of a segment
+	// once its items were handled, as a progress report.
+	OnPage func(segment int, result *QueryResult)
+}
+
+// SegmentError is the error of the scan of a segment by ScanSegments.
+type SegmentError struct {
+	Segment int
+	Err     error
+}
+
+func (e *SegmentError) Error() string {
+	return fmt.Sprintf("Segment %d: %v", e.Segment, e.Err)
+}
+
+func (e *SegmentError) Unwrap() error {
+	return e.Err
+}
+
+// ScanSegmentsError is returned by ScanSegments with the errors of the
+// segments which failed, in order of segment.
+type ScanSegmentsError struct {
+	Errors []*SegmentError
+}
+
+func (e *ScanSegmentsError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	return fmt.Sprintf("%d segments failed, first: %v", len(e.Errors), e.Errors[0])
+}
+
+// capacityLimiter spaces requests so that the capacity they consume stays
+// under a rate.
+type capacityLimiter struct {
+	rate float64
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait waits until the next request may be sent, or ctx is done.
+func (l *capacityLimiter) wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	d := time.Until(l.next)
+	l.mu.Unlock()
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// consume delays the next request for consumed to be within the rate.
+func (l *capacityLimiter) consume(consumed *ConsumedCapacity) {
+	if l.rate <= 0 || consumed == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now := time.Now(); l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(consumed.CapacityUnits / l.rate * float64(time.Second)))
+}
+
+// ScanSegments scans the table, or one of its indexes, in totalSegments
+// segments scanned at once by as many goroutines, each following its
+// pages of results. fn is called with every item, from the goroutines of
+// all the segments at once. The first error of a segment, including
+// errors returned by fn, stops the scans of the other segments, and
+// ScanSegments then fails with a *ScanSegmentsError.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/Scan.html#Scan.ParallelScan for details.
+func (t *Table) ScanSegments(totalSegments int, options *ParallelScanOptions, fn func(item map[string]AttributeValue) error) error {
+	if totalSegments < 1 {
+		return fmt.Errorf("Invalid TotalSegments %d", totalSegments)
+	}
+	if options == nil {
+		options = &ParallelScanOptions{}
+	}
+	limiter := &capacityLimiter{rate: options.MaxCapacityPerSecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errs := make([]*SegmentError, totalSegments)
+	var wg sync.WaitGroup
+	for segment := 0; segment < totalSegments; segment++ {
+		wg.Add(1)
+		go func(segment int) {
+			defer wg.Done()
+			if err := t.scanSegment(ctx, segment, totalSegments, options, limiter, fn); err != nil && err != context.Canceled {
+				errs[segment] = &SegmentError{segment, err}
+				cancel()
+			}
+		}(segment)
+	}
+	wg.Wait()
+
+	failed := &ScanSegmentsError{}
+	for _, err := range errs {
+		if err != nil {
+			failed.Errors = append(failed.Errors, err)
+		}
+	}
+	if len(failed.Errors) > 0 {
+		return failed
+	}
+	return nil
+}
+
+func (t *Table) scanSegment(ctx context.Context, segment, totalSegments int, options *ParallelScanOptions, limiter *capacityLimiter, fn func(item map[string]AttributeValue) error) error {
+	scan := options.ScanOptions
+	scan.Segment, scan.TotalSegments, scan.ExclusiveStartKey = segment, totalSegments, nil
+	if limiter.rate > 0 && scan.ReturnConsumedCapacity == "" {
+		scan.ReturnConsumedCapacity = RETURN_CONSUMED_CAPACITY_TOTAL
+	}
+	for {
+		if err := limiter.wait(ctx); err != nil {
+			return err
+		}
+		result, err := t.ScanWithOptions(nil, &scan)
+		if err != nil {
+			return err
+		}
+		limiter.consume(result.ConsumedCapacity)
+		for _, item := range result.ItemValues {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if options.OnPage != nil {
+			options.OnPage(segment, result)
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		scan.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,145 @@
+package dynamodb_test
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/dynamodb"
+	. "gopkg.in/check.v1"
+)
+
+type ParallelScanSuite struct{}
+
+var _ = Suite(&ParallelScanSuite{})
+
+// segmentPages answers the scans of a segment with pages of two items,
+// the last page being pages, or never ending if pages is zero.
+func segmentPages(c *C, r *fakeRequest, pages int) (int, string) {
+	segment := int(r.Body["Segment"].(float64))
+	page := 0
+	if start, ok := r.Body["ExclusiveStartKey"].(map[string]interface{}); ok {
+		fmt.Sscanf(start["Id"].(map[string]interface{})["S"].(string), "s%d-p%d", &segment, &page)
+		page++
+	}
+	response := map[string]interface{}{
+		"Count": 2,
+		"Items": []interface{}{
+			map[string]interface{}{"Id": map[string]interface{}{"S": fmt.Sprintf("s%d-p%d-a", segment, page)}},
+			map[string]interface{}{"Id": map[string]interface{}{"S": fmt.Sprintf("s%d-p%d-b", segment, page)}},
+		},
+		"ConsumedCapacity": map[string]interface{}{"TableName": "Users", "CapacityUnits": 5},
+	}
+	if pages == 0 || page+1 < pages {
+		response["LastEvaluatedKey"] = map[string]interface{}{"Id": map[string]interface{}{"S": fmt.Sprintf("s%d-p%d", segment, page)}}
+	}
+	return 200, marshal(c, response)
+}
+
+func (s *ParallelScanSuite) TestScanSegments(c *C) {
+	var mu sync.Mutex
+	var requests []*fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		mu.Lock()
+		requests = append(requests, r)
+		mu.Unlock()
+		return segmentPages(c, r, 2)
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	var items []string
+	pages := map[int]int{}
+	err := users.ScanSegments(3, &dynamodb.ParallelScanOptions{
+		ScanOptions: dynamodb.ScanOptions{FilterExpression: "attribute_exists(Id)"},
+		OnPage: func(segment int, result *dynamodb.QueryResult) {
+			mu.Lock()
+			pages[segment]++
+			mu.Unlock()
+		},
+	}, func(item map[string]dynamodb.AttributeValue) error {
+		mu.Lock()
+		items = append(items, item["Id"].Value)
+		mu.Unlock()
+		return nil
+	})
+	c.Assert(err, IsNil)
+	sort.Strings(items)
+	c.Assert(items, DeepEquals, []string{
+		"s0-p0-a", "s0-p0-b", "s0-p1-a", "s0-p1-b",
+		"s1-p0-a", "s1-p0-b", "s1-p1-a", "s1-p1-b",
+		"s2-p0-a", "s2-p0-b", "s2-p1-a", "s2-p1-b",
+	})
+	c.Assert(pages, DeepEquals, map[int]int{0: 2, 1: 2, 2: 2})
+	c.Assert(requests, HasLen, 6)
+	for _, r := range requests {
+		c.Check(r.Target, Equals, "DynamoDB_20120810.Scan")
+		c.Check(r.Body["TotalSegments"], Equals, float64(3))
+		c.Check(r.Body["FilterExpression"], Equals, "attribute_exists(Id)")
+		c.Check(r.Body["ReturnConsumedCapacity"], IsNil)
+	}
+}
+
+func (s *ParallelScanSuite) TestScanSegmentsErrors(c *C) {
+	failing := true
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		if failing && r.Body["Segment"] == float64(1) {
+			return 400, `{"__type": "com.amazonaws.dynamodb.v20120810#ResourceNotFoundException", "message": "Requested resource not found"}`
+		}
+		return segmentPages(c, r, 0)
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	// The failure of a segment stops the endless scans of the other ones
+	err := users.ScanSegments(3, nil, func(item map[string]dynamodb.AttributeValue) error { return nil })
+	e, ok := err.(*dynamodb.ScanSegmentsError)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.Errors, HasLen, 1)
+	c.Assert(e.Errors[0].Segment, Equals, 1)
+	c.Assert(aws.IsCode(e.Errors[0], "ResourceNotFoundException"), Equals, true)
+	c.Assert(err, ErrorMatches, `Segment 1: Requested resource not found \(ResourceNotFoundException\)`)
+
+	// As do the errors of fn
+	failing = false
+	err = users.ScanSegments(2, nil, func(item map[string]dynamodb.AttributeValue) error {
+		if item["Id"].Value == "s0-p3-b" {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+	c.Assert(err, ErrorMatches, "Segment 0: handler failed")
+
+	err = users.ScanSegments(0, nil, nil)
+	c.Assert(err, ErrorMatches, "Invalid TotalSegments 0")
+	_, err = users.ScanWithOptions(nil, &dynamodb.ScanOptions{Segment: 2, TotalSegments: 2})
+	c.Assert(err, ErrorMatches, "Segment 2 not between 0 and TotalSegments 2")
+}
+
+func (s *ParallelScanSuite) TestScanSegmentsCapacity(c *C) {
+	var mu sync.Mutex
+	var requests []*fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		mu.Lock()
+		requests = append(requests, r)
+		mu.Unlock()
+		return segmentPages(c, r, 3)
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	// Each page consumes 5 units, which is 50ms at 100 units per second,
+	// and both segments send their first request at once
+	start := time.Now()
+	err := users.ScanSegments(2, &dynamodb.ParallelScanOptions{MaxCapacityPerSecond: 100},
+		func(item map[string]dynamodb.AttributeValue) error { return nil })
+	c.Assert(err, IsNil)
+	c.Assert(time.Since(start) >= 200*time.Millisecond, Equals, true)
+	c.Assert(requests, HasLen, 6)
+	for _, r := range requests {
+		c.Check(r.Body["ReturnConsumedCapacity"], Equals, "TOTAL")
+	}
+}
@@ -1,12 +1,231 @@
 package dynamodb
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 
 	simplejson "github.com/bitly/go-simplejson"
 )
 
+// Values of the Select parameter of Query and Scan requests.
+const (
+	SELECT_ALL_ATTRIBUTES           = "ALL_ATTRIBUTES"
+	SELECT_ALL_PROJECTED_ATTRIBUTES = "ALL_PROJECTED_ATTRIBUTES"
+	SELECT_COUNT                    = "COUNT"
+	SELECT_SPECIFIC_ATTRIBUTES      = "SPECIFIC_ATTRIBUTES"
+)
+
+// QueryOptions holds the optional parameters of a Query request.
+type QueryOptions struct {
+	// IndexName is the secondary index queried instead of the table.
+	// The key conditions are then on the key of the index.
+	IndexName string
+
+	// Select is the attributes returned, as one of the SELECT_ constants.
+	// SELECT_ALL_PROJECTED_ATTRIBUTES is only valid for indexes, and
+	// SELECT_SPECIFIC_ATTRIBUTES, implied if Select is empty, needs
+	// AttributesToGet or ProjectionExpression.
+	Select                   string
+	AttributesToGet          []string
+	ProjectionExpression     string
+	ExpressionAttributeNames map[string]string
+
+	// Descending returns the items in descending order of range key,
+	// sending ScanIndexForward as false.
+	Descending bool
+
+	// ConsistentRead makes a strongly consistent read, which fails with
+	// ErrConsistentReadOnGlobalIndex for the global indexes of the table.
+	ConsistentRead bool
+
+	Limit int64
+
+	// KeyConditionExpression is the condition on the key of the items,
+	// instead of the attribute comparisons, and FilterExpression the
+	// condition on their other attributes, with placeholders as in
+	// WriteOptions.
+	KeyConditionExpression    string
+	FilterExpression          string
+	ExpressionAttributeValues []Attribute
+
+	// ReturnConsumedCapacity is one of the RETURN_CONSUMED_CAPACITY_
+	// constants.
+	ReturnConsumedCapacity string
+
+	// ExclusiveStartKey is the LastEvaluatedKey of the previous page of
+	// results.
+	ExclusiveStartKey map[string]AttributeValue
+
+	// TimeToLiveAttribute, if not empty, is the TTL attribute of the
+	// table. The items it has expired, which DynamoDB deletes up to a few
+	// days later, are then left out of the results and of their Count.
+	TimeToLiveAttribute string
+}
+
+// QueryResult holds the response to a Query or a Scan request. Count is
+// the number of items matching the request, which are only in Items if
+// it doesn't select SELECT_COUNT. ItemValues holds the same items with
+// attributes of any type. The results continue on another page, read
+// with LastEvaluatedKey as ExclusiveStartKey, unless it is empty.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_Query.html for details.
+type QueryResult struct {
+	Items            []map[string]*Attribute
+	ItemValues       []map[string]AttributeValue
+	Count            int64
+	ScannedCount     int64
+	LastEvaluatedKey map[string]AttributeValue
+	ConsumedCapacity *ConsumedCapacity
+}
+
+// checkRead checks the index and the attributes read by a Query or a
+// Scan.
+func (t *Table) checkRead(indexName, selectValue string, consistentRead bool, attributesToGet []string, projection string) error {
+	if consistentRead && indexName != "" {
+		if index := t.Index(indexName); index != nil && index.Global {
+			return ErrConsistentReadOnGlobalIndex
+		}
+	}
+	specific := len(attributesToGet) > 0 || projection != ""
+	if len(attributesToGet) > 0 && projection != "" {
+		return errors.New("AttributesToGet and ProjectionExpression can't be used together")
+	}
+	switch selectValue {
+	case "":
+	case SELECT_ALL_ATTRIBUTES, SELECT_COUNT:
+		if specific {
+			return fmt.Errorf("Select %s doesn't take specific attributes", selectValue)
+		}
+	case SELECT_ALL_PROJECTED_ATTRIBUTES:
+		if indexName == "" {
+			return errors.New("Select ALL_PROJECTED_ATTRIBUTES needs an IndexName")
+		}
+		if specific {
+			return fmt.Errorf("Select %s doesn't take specific attributes", selectValue)
+		}
+	case SELECT_SPECIFIC_ATTRIBUTES:
+		if !specific {
+			return errors.New("Select SPECIFIC_ATTRIBUTES needs AttributesToGet or ProjectionExpression")
+		}
+	default:
+		return fmt.Errorf("Invalid Select %s", selectValue)
+	}
+	return nil
+}
+
+// addReadOptions adds the parameters common to Query and Scan requests.
+func (q *Query) addReadOptions(indexName, selectValue string, attributesToGet []string, projection string, names map[string]string, consistentRead bool, limit int64) {
+	if indexName != "" {
+		q.AddIndex(indexName)
+	}
+	if selectValue != "" {
+		q.AddSelect(selectValue)
+	}
+	q.AddAttributesToGet(attributesToGet)
+	if projection != "" {
+		q.AddProjectionExpression(projection)
+	}
+	if len(names) > 0 {
+		q.AddExpressionAttributeNames(names)
+	}
+	q.ConsistentRead(consistentRead)
+	if limit > 0 {
+		q.AddLimit(limit)
+	}
+}
+
+// QueryWithOptions queries the table, or one of its indexes, for the
+// items with a key meeting attributeComparisons. Queries on an index in
+// t.Indexes are checked to have a condition on the hash key of the index.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_Query.html for details.
+func (t *Table) QueryWithOptions(attributeComparisons []AttributeComparison, options *QueryOptions) (*QueryResult, error) {
+	err := t.checkRead(options.IndexName, options.Select, options.ConsistentRead, options.AttributesToGet, options.ProjectionExpression)
+	if err != nil {
+		return nil, err
+	}
+	if options.KeyConditionExpression != "" && len(attributeComparisons) > 0 {
+		return nil, errors.New("Key conditions and KeyConditionExpression can't be used together")
+	}
+	key, keyOf := t.Key, "table "+t.Name
+	if options.IndexName != "" {
+		key, keyOf = PrimaryKey{}, ""
+		if index := t.Index(options.IndexName); index != nil {
+			key, keyOf = index.Key, "index "+index.Name
+		}
+	}
+	if key.KeyAttribute != nil && len(attributeComparisons) > 0 && !hasComparison(attributeComparisons, key.KeyAttribute.Name) {
+		return nil, fmt.Errorf("The key conditions of a query on %s need its hash key %s", keyOf, key.KeyAttribute.Name)
+	}
+
+	q := NewQuery(t)
+	if options.KeyConditionExpression != "" {
+		q.AddKeyConditionExpression(options.KeyConditionExpression)
+	} else {
+		q.AddKeyConditions(attributeComparisons)
+	}
+	if options.FilterExpression != "" {
+		q.AddFilterExpression(options.FilterExpression)
+	}
+	q.addReadOptions(options.IndexName, options.Select, options.AttributesToGet, options.ProjectionExpression, options.ExpressionAttributeNames, options.ConsistentRead, options.Limit)
+	q.addExpressions(nil, options.ExpressionAttributeValues, options.ReturnConsumedCapacity)
+	if len(options.ExclusiveStartKey) > 0 {
+		q.AddExclusiveStartKey(options.ExclusiveStartKey)
+	}
+	if options.Descending {
+		q.AddSortDirection(false)
+	}
+	result, err := t.Server.readQuery(target("Query"), q)
+	if err != nil {
+		return nil, err
+	}
+	return t.Server.dropExpired(result, options.TimeToLiveAttribute), nil
+}
+
+func hasComparison(comparisons []AttributeComparison, name string) bool {
+	for _, c := range comparisons {
+		if c.AttributeName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// readQuery runs a Query or a Scan request.
+func (s *Server) readQuery(target string, q *Query) (*QueryResult, error) {
+	jsonResponse, err := s.queryServer(target, q)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Items            []json.RawMessage
+		Count            int64
+		ScannedCount     int64
+		LastEvaluatedKey map[string]AttributeValue
+		ConsumedCapacity *ConsumedCapacity
+	}
+	if err := json.Unmarshal(jsonResponse, &resp); err != nil {
+		return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+	}
+	result := &QueryResult{
+		Count:            resp.Count,
+		ScannedCount:     resp.ScannedCount,
+		LastEvaluatedKey: resp.LastEvaluatedKey,
+		ConsumedCapacity: resp.ConsumedCapacity,
+	}
+	for _, data := range resp.Items {
+		var item map[string]interface{}
+		var values map[string]AttributeValue
+		if json.Unmarshal(data, &item) != nil || json.Unmarshal(data, &values) != nil {
+			return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+		}
+		result.Items = append(result.Items, parseAttributes(item))
+		result.ItemValues = append(result.ItemValues, values)
+	}
+	return result, nil
+}
+
 func (t *Table) Query(attributeComparisons []AttributeComparison) ([]map[string]*Attribute, error) {
 	q := NewQuery(t)
 	q.AddKeyConditions(attributeComparisons)
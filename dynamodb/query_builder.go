@@ -2,6 +2,7 @@ package dynamodb
 
 import (
 	"encoding/json"
+	"errors"
 	"sort"
 )
 
@@ -73,21 +74,43 @@ func (q *Query) ConsistentRead(c bool) {
 }
 
 func (q *Query) AddGetRequestItems(tableKeys map[*Table][]Key) {
+	q.buffer["RequestItems"] = getRequestItems(tableKeys, nil)
+}
+
+// getRequestItems returns the RequestItems of a BatchGetItem request
+// reading the keys of each table with its options.
+func getRequestItems(tableKeys map[*Table][]Key, options map[*Table]*BatchGetOptions) msi {
 	requestitems := msi{}
 	for table, keys := range tableKeys {
 		keyslist := []msi{}
 		for _, key := range keys {
 			keyslist = append(keyslist, keyAttributes(table, &key))
 		}
-		requestitems[table.Name] = msi{"Keys": keyslist}
+		request := msi{"Keys": keyslist}
+		if o := options[table]; o != nil {
+			if o.ConsistentRead {
+				request["ConsistentRead"] = true
+			}
+			if o.ProjectionExpression != "" {
+				request["ProjectionExpression"] = o.ProjectionExpression
+			}
+			if len(o.ExpressionAttributeNames) > 0 {
+				request["ExpressionAttributeNames"] = o.ExpressionAttributeNames
+			}
+		}
+		requestitems[table.Name] = request
 	}
-	q.buffer["RequestItems"] = requestitems
+	return requestitems
 }
 
 func (q *Query) AddWriteRequestItems(tableItems map[*Table]map[string][][]Attribute) {
-	b := q.buffer
+	q.buffer["RequestItems"] = writeRequestItems(tableItems)
+}
 
-	b["RequestItems"] = func() msi {
+// writeRequestItems returns the RequestItems of a BatchWriteItem request
+// making the puts and deletes of each table.
+func writeRequestItems(tableItems map[*Table]map[string][][]Attribute) msi {
+	return func() msi {
 		out := msi{}
 		for table, itemActions := range tableItems {
 			out[table.Name] = func() interface{} {
@@ -116,6 +139,40 @@ func (q *Query) AddWriteRequestItems(tableItems map[*Table]map[string][][]Attrib
 	}()
 }
 
+// AddTransactWriteItems adds the TransactItems of a TransactWriteItems
+// request, each with the request of its write under the name of its
+// action, and fails if one of them is invalid.
+func (q *Query) AddTransactWriteItems(items []TransactWriteItem) error {
+	transactItems := []interface{}{}
+	for _, item := range items {
+		if item.err != nil {
+			return item.err
+		}
+		if item.query == nil {
+			return errors.New("A transaction item needs a table")
+		}
+		transactItems = append(transactItems, msi{item.action: item.query.buffer})
+	}
+	q.buffer["TransactItems"] = transactItems
+	return nil
+}
+
+// AddTransactGetItems adds the TransactItems of a TransactGetItems
+// request.
+func (q *Query) AddTransactGetItems(items []TransactGetItem) {
+	transactItems := []interface{}{}
+	for _, item := range items {
+		get := NewQuery(item.Table)
+		get.AddKey(item.Table, item.Key)
+		if item.ProjectionExpression != "" {
+			get.AddProjectionExpression(item.ProjectionExpression)
+		}
+		get.addExpressions(item.ExpressionAttributeNames, nil, "")
+		transactItems = append(transactItems, msi{"Get": get.buffer})
+	}
+	q.buffer["TransactItems"] = transactItems
+}
+
 func (q *Query) AddCreateRequestTable(description TableDescriptionT) {
 	b := q.buffer
 
@@ -174,6 +231,53 @@ func (q *Query) AddCreateRequestTable(description TableDescriptionT) {
 	}
 }
 
+// AddUpdateRequestTable adds the changes of an UpdateTable request.
+func (q *Query) AddUpdateRequestTable(name string, update *TableUpdate) error {
+	b := q.buffer
+	b["TableName"] = name
+	if update.ProvisionedThroughput != nil {
+		b["ProvisionedThroughput"] = throughput(*update.ProvisionedThroughput)
+	}
+	if len(update.AttributeDefinitions) > 0 {
+		b["AttributeDefinitions"] = update.AttributeDefinitions
+	}
+	if len(update.GlobalSecondaryIndexUpdates) == 0 {
+		return nil
+	}
+	updates := []interface{}{}
+	for _, u := range update.GlobalSecondaryIndexUpdates {
+		switch {
+		case u.Create != nil && u.Update == nil && u.Delete == "":
+			updates = append(updates, msi{"Create": msi{
+				"IndexName":             u.Create.IndexName,
+				"KeySchema":             u.Create.KeySchema,
+				"Projection":            u.Create.Projection,
+				"ProvisionedThroughput": throughput(u.Create.ProvisionedThroughput),
+			}})
+		case u.Create == nil && u.Update != nil && u.Delete == "":
+			updates = append(updates, msi{"Update": msi{
+				"IndexName":             u.Update.IndexName,
+				"ProvisionedThroughput": throughput(u.Update.ProvisionedThroughput),
+			}})
+		case u.Create == nil && u.Update == nil && u.Delete != "":
+			updates = append(updates, msi{"Delete": msi{"IndexName": u.Delete}})
+		default:
+			return errors.New("An index update needs one of Create, Update or Delete")
+		}
+	}
+	b["GlobalSecondaryIndexUpdates"] = updates
+	return nil
+}
+
+// throughput returns the ProvisionedThroughput parameter of a table or an
+// index.
+func throughput(t ProvisionedThroughputT) msi {
+	return msi{
+		"ReadCapacityUnits":  t.ReadCapacityUnits,
+		"WriteCapacityUnits": t.WriteCapacityUnits,
+	}
+}
+
 func (q *Query) AddDeleteRequestTable(description TableDescriptionT) {
 	b := q.buffer
 	b["TableName"] = description.TableName
@@ -190,6 +294,10 @@ func (q *Query) AddSelect(value string) {
 	q.buffer["Select"] = value
 }
 
+func (q *Query) AddProjectionExpression(expression string) {
+	q.buffer["ProjectionExpression"] = expression
+}
+
 func (q *Query) AddIndex(value string) {
 	q.buffer["IndexName"] = value
 }
@@ -215,13 +323,17 @@ func (q *Query) AddParallelScanConfiguration(segment int, totalSegments int) {
 	q.buffer["TotalSegments"] = totalSegments
 }
 
+func (q *Query) AddExclusiveStartKey(key map[string]AttributeValue) {
+	q.buffer["ExclusiveStartKey"] = key
+}
+
 func buildComparisons(comparisons []AttributeComparison) msi {
 	out := msi{}
 
 	for _, c := range comparisons {
 		avlist := []interface{}{}
 		for _, attributeValue := range c.AttributeValueList {
-			avlist = append(avlist, msi{attributeValue.Type: attributeValue.Value})
+			avlist = append(avlist, attributeValue.wireValue())
 		}
 		out[c.AttributeName] = msi{
 			"AttributeValueList": avlist,
@@ -241,9 +353,7 @@ func (q *Query) AddUpdates(attributes []Attribute, action string) {
 	updates := msi{}
 	for _, a := range attributes {
 		au := msi{
-			"Value": msi{
-				a.Type: map[bool]interface{}{true: a.SetValues, false: a.Value}[a.SetType()],
-			},
+			"Value":  a.wireValue(),
 			"Action": action,
 		}
 		// Delete 'Value' from AttributeUpdates if Type is not Set
@@ -265,7 +375,7 @@ func (q *Query) AddExpected(attributes []Attribute) {
 		}
 		// If set Exists to false, we must remove Value
 		if value["Exists"] != "false" {
-			value["Value"] = msi{a.Type: map[bool]interface{}{true: a.SetValues, false: a.Value}[a.SetType()]}
+			value["Value"] = a.wireValue()
 		}
 		expected[a.Name] = value
 	}
@@ -280,6 +390,22 @@ func (q *Query) AddConditionExpression(expression string) {
 	q.buffer["ConditionExpression"] = expression
 }
 
+func (q *Query) AddKeyConditionExpression(expression string) {
+	q.buffer["KeyConditionExpression"] = expression
+}
+
+func (q *Query) AddFilterExpression(expression string) {
+	q.buffer["FilterExpression"] = expression
+}
+
+func (q *Query) AddReturnValues(value string) {
+	q.buffer["ReturnValues"] = value
+}
+
+func (q *Query) AddReturnConsumedCapacity(value string) {
+	q.buffer["ReturnConsumedCapacity"] = value
+}
+
 //map[#NAME] => name
 func (q *Query) AddExpressionAttributeNames(attributeNames map[string]string) {
 	q.buffer["ExpressionAttributeNames"] = attributeNames
@@ -293,8 +419,7 @@ func (q *Query) AddExpressionAttributeValues(attributes []Attribute) {
 func attributeList(attributes []Attribute) msi {
 	b := msi{}
 	for _, a := range attributes {
-		//UGH!!  (I miss the query operator)
-		b[a.Name] = msi{a.Type: map[bool]interface{}{true: a.SetValues, false: a.Value}[a.SetType()]}
+		b[a.Name] = a.wireValue()
 	}
 	return b
 }
@@ -0,0 +1,284 @@
+package dynamodb_test
+
+import (
+	"github.com/goamz/goamz/dynamodb"
+	. "gopkg.in/check.v1"
+)
+
+type QuerySuite struct{}
+
+var _ = Suite(&QuerySuite{})
+
+var indexedTableDescription = dynamodb.TableDescriptionT{
+	TableName: "Orders",
+	AttributeDefinitions: []dynamodb.AttributeDefinitionT{
+		{"CustomerId", "S"},
+		{"OrderId", "S"},
+		{"Created", "N"},
+		{"Status", "S"},
+	},
+	KeySchema: []dynamodb.KeySchemaT{
+		{"CustomerId", "HASH"},
+		{"OrderId", "RANGE"},
+	},
+	LocalSecondaryIndexes: []dynamodb.LocalSecondaryIndexT{{
+		IndexName: "byCreated",
+		KeySchema: []dynamodb.KeySchemaT{
+			{"CustomerId", "HASH"},
+			{"Created", "RANGE"},
+		},
+		Projection: dynamodb.ProjectionT{"ALL"},
+	}},
+	GlobalSecondaryIndexes: []dynamodb.GlobalSecondaryIndexT{{
+		IndexName: "byStatus",
+		KeySchema: []dynamodb.KeySchemaT{
+			{"Status", "HASH"},
+			{"Created", "RANGE"},
+		},
+		Projection: dynamodb.ProjectionT{"KEYS_ONLY"},
+	}},
+}
+
+// indexedTable returns the table of indexedTableDescription on server.
+func indexedTable(c *C, server *dynamodb.Server) *dynamodb.Table {
+	pk, err := indexedTableDescription.BuildPrimaryKey()
+	c.Assert(err, IsNil)
+	table := server.NewTable("Orders", pk)
+	table.Indexes, err = indexedTableDescription.BuildIndexes()
+	c.Assert(err, IsNil)
+	return table
+}
+
+func (s *QuerySuite) TestBuildIndexes(c *C) {
+	indexes, err := indexedTableDescription.BuildIndexes()
+	c.Assert(err, IsNil)
+	c.Assert(indexes, HasLen, 2)
+	c.Assert(indexes[0].Name, Equals, "byCreated")
+	c.Assert(indexes[0].Global, Equals, false)
+	c.Assert(*indexes[0].Key.KeyAttribute, DeepEquals, *dynamodb.NewStringAttribute("CustomerId", ""))
+	c.Assert(*indexes[0].Key.RangeAttribute, DeepEquals, *dynamodb.NewNumericAttribute("Created", ""))
+	c.Assert(indexes[1].Name, Equals, "byStatus")
+	c.Assert(indexes[1].Global, Equals, true)
+	c.Assert(indexes[1].Key.KeyAttribute.Name, Equals, "Status")
+}
+
+func (s *QuerySuite) TestQueryOnGlobalIndex(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, `{"Count": 2, "ScannedCount": 2, "Items": [
+			{"Status": {"S": "shipped"}, "Created": {"N": "2"}, "CustomerId": {"S": "c1"}, "OrderId": {"S": "o2"}},
+			{"Status": {"S": "shipped"}, "Created": {"N": "1"}, "CustomerId": {"S": "c2"}, "OrderId": {"S": "o1"}}
+		]}`
+	})
+	defer done()
+	table := indexedTable(c, server)
+
+	result, err := table.QueryWithOptions([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("Status", "shipped"),
+	}, &dynamodb.QueryOptions{
+		IndexName:  "byStatus",
+		Select:     dynamodb.SELECT_ALL_PROJECTED_ATTRIBUTES,
+		Descending: true,
+		Limit:      10,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(req.Target, Equals, "DynamoDB_20120810.Query")
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{
+		"TableName":        "Orders",
+		"IndexName":        "byStatus",
+		"Select":           "ALL_PROJECTED_ATTRIBUTES",
+		"ScanIndexForward": "false",
+		"Limit":            float64(10),
+		"KeyConditions": map[string]interface{}{
+			"Status": map[string]interface{}{
+				"AttributeValueList": []interface{}{map[string]interface{}{"S": "shipped"}},
+				"ComparisonOperator": "EQ",
+			},
+		},
+	})
+
+	c.Assert(result.Count, Equals, int64(2))
+	c.Assert(result.ScannedCount, Equals, int64(2))
+	c.Assert(result.Items, HasLen, 2)
+	c.Assert(result.Items[0]["OrderId"].Value, Equals, "o2")
+	c.Assert(result.Items[1]["Created"], DeepEquals, dynamodb.NewNumericAttribute("Created", "1"))
+}
+
+func (s *QuerySuite) TestQueryConsistentRead(c *C) {
+	var requests []*fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		requests = append(requests, r)
+		return 200, `{"Count": 0, "ScannedCount": 0, "Items": []}`
+	})
+	defer done()
+	table := indexedTable(c, server)
+
+	// Global indexes don't support consistent reads
+	status := []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("Status", "shipped")}
+	_, err := table.QueryWithOptions(status, &dynamodb.QueryOptions{IndexName: "byStatus", ConsistentRead: true})
+	c.Assert(err, Equals, dynamodb.ErrConsistentReadOnGlobalIndex)
+	_, err = table.ScanWithOptions(nil, &dynamodb.ScanOptions{IndexName: "byStatus", ConsistentRead: true})
+	c.Assert(err, Equals, dynamodb.ErrConsistentReadOnGlobalIndex)
+	c.Assert(requests, HasLen, 0)
+
+	// Local indexes and the table do
+	customer := []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("CustomerId", "c1")}
+	result, err := table.QueryWithOptions(customer, &dynamodb.QueryOptions{IndexName: "byCreated", ConsistentRead: true})
+	c.Assert(err, IsNil)
+	c.Assert(result.Items, HasLen, 0)
+	_, err = table.QueryWithOptions(customer, &dynamodb.QueryOptions{ConsistentRead: true})
+	c.Assert(err, IsNil)
+	c.Assert(requests, HasLen, 2)
+	c.Assert(requests[0].Body["IndexName"], Equals, "byCreated")
+	c.Assert(requests[0].Body["ConsistentRead"], Equals, "true")
+	c.Assert(requests[1].Body["IndexName"], IsNil)
+	c.Assert(requests[1].Body["ConsistentRead"], Equals, "true")
+
+	// Reads are eventually consistent by default
+	_, err = table.QueryWithOptions(status, &dynamodb.QueryOptions{IndexName: "byStatus"})
+	c.Assert(err, IsNil)
+	_, err = table.ScanWithOptions(nil, &dynamodb.ScanOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(requests, HasLen, 4)
+	_, ok := requests[2].Body["ConsistentRead"]
+	c.Assert(ok, Equals, false)
+	_, ok = requests[3].Body["ConsistentRead"]
+	c.Assert(ok, Equals, false)
+}
+
+func (s *QuerySuite) TestGetItemWithOptions(c *C) {
+	var req *fakeRequest
+	response := `{"Item": {"Id": {"S": "u1"}, "Visits": {"N": "3"}}, "ConsumedCapacity": {"TableName": "Users", "CapacityUnits": 1}}`
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, response
+	})
+	defer done()
+	users, _ := batchTables(server)
+	key := &dynamodb.Key{HashKey: "u1"}
+
+	result, err := users.GetItemWithOptions(key, &dynamodb.GetOptions{
+		ConsistentRead:           true,
+		ProjectionExpression:     "#v",
+		ExpressionAttributeNames: map[string]string{"#v": "Visits"},
+		ReturnConsumedCapacity:   dynamodb.RETURN_CONSUMED_CAPACITY_TOTAL,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{
+		"TableName":                "Users",
+		"Key":                      map[string]interface{}{"Id": map[string]interface{}{"S": "u1"}},
+		"ConsistentRead":           "true",
+		"ProjectionExpression":     "#v",
+		"ExpressionAttributeNames": map[string]interface{}{"#v": "Visits"},
+		"ReturnConsumedCapacity":   "TOTAL",
+	})
+	c.Assert(result.Item["Visits"], DeepEquals, dynamodb.NewNumericAttribute("Visits", "3"))
+	c.Assert(result.ItemValue["Id"].Value, Equals, "u1")
+	c.Assert(result.ConsumedCapacity, DeepEquals, &dynamodb.ConsumedCapacity{TableName: "Users", CapacityUnits: 1})
+
+	// Without options, and with GetItem, the read is eventually consistent
+	var user struct{ Visits int }
+	c.Assert(users.GetStructWithOptions(key, nil, &user), IsNil)
+	c.Assert(user.Visits, Equals, 3)
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{
+		"TableName": "Users",
+		"Key":       map[string]interface{}{"Id": map[string]interface{}{"S": "u1"}},
+	})
+	_, err = users.GetItem(key)
+	c.Assert(err, IsNil)
+	_, ok := req.Body["ConsistentRead"]
+	c.Assert(ok, Equals, false)
+	_, err = users.GetItemConsistent(key, true)
+	c.Assert(err, IsNil)
+	c.Assert(req.Body["ConsistentRead"], Equals, "true")
+
+	response = `{}`
+	_, err = users.GetItemWithOptions(key, &dynamodb.GetOptions{ConsistentRead: true})
+	c.Assert(err, Equals, dynamodb.ErrNotFound)
+}
+
+func (s *QuerySuite) TestQueryIndexKey(c *C) {
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		c.Errorf("unexpected request %v", r.Body)
+		return 500, "{}"
+	})
+	defer done()
+	table := indexedTable(c, server)
+
+	// The global index has another hash key than the table
+	customer := []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("CustomerId", "c1")}
+	_, err := table.QueryWithOptions(customer, &dynamodb.QueryOptions{IndexName: "byStatus"})
+	c.Assert(err, ErrorMatches, "The key conditions of a query on index byStatus need its hash key Status")
+
+	status := []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("Status", "shipped")}
+	_, err = table.QueryWithOptions(status, &dynamodb.QueryOptions{})
+	c.Assert(err, ErrorMatches, "The key conditions of a query on table Orders need its hash key CustomerId")
+}
+
+func (s *QuerySuite) TestQuerySelect(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, `{"Count": 7, "ScannedCount": 9}`
+	})
+	defer done()
+	table := indexedTable(c, server)
+	customer := []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("CustomerId", "c1")}
+
+	result, err := table.QueryWithOptions(customer, &dynamodb.QueryOptions{Select: dynamodb.SELECT_COUNT})
+	c.Assert(err, IsNil)
+	c.Assert(req.Body["Select"], Equals, "COUNT")
+	c.Assert(result.Count, Equals, int64(7))
+	c.Assert(result.ScannedCount, Equals, int64(9))
+	c.Assert(result.Items, HasLen, 0)
+
+	_, err = table.QueryWithOptions(customer, &dynamodb.QueryOptions{
+		Select:          dynamodb.SELECT_SPECIFIC_ATTRIBUTES,
+		AttributesToGet: []string{"OrderId", "Status"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(req.Body["Select"], Equals, "SPECIFIC_ATTRIBUTES")
+	c.Assert(req.Body["AttributesToGet"], DeepEquals, []interface{}{"OrderId", "Status"})
+
+	for _, t := range []struct {
+		options dynamodb.QueryOptions
+		err     string
+	}{
+		{dynamodb.QueryOptions{Select: dynamodb.SELECT_ALL_PROJECTED_ATTRIBUTES}, "Select ALL_PROJECTED_ATTRIBUTES needs an IndexName"},
+		{dynamodb.QueryOptions{Select: dynamodb.SELECT_SPECIFIC_ATTRIBUTES}, "Select SPECIFIC_ATTRIBUTES needs AttributesToGet or ProjectionExpression"},
+		{dynamodb.QueryOptions{Select: dynamodb.SELECT_COUNT, ProjectionExpression: "OrderId"}, "Select COUNT doesn't take specific attributes"},
+		{dynamodb.QueryOptions{AttributesToGet: []string{"OrderId"}, ProjectionExpression: "OrderId"}, "AttributesToGet and ProjectionExpression can't be used together"},
+		{dynamodb.QueryOptions{Select: "SOME"}, "Invalid Select SOME"},
+	} {
+		_, err := table.QueryWithOptions(customer, &t.options)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *QuerySuite) TestScanOnIndex(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, `{"Count": 1, "ScannedCount": 3, "Items": [{"Status": {"S": "shipped"}}]}`
+	})
+	defer done()
+	table := indexedTable(c, server)
+
+	result, err := table.ScanWithOptions(nil, &dynamodb.ScanOptions{
+		IndexName:                "byStatus",
+		ProjectionExpression:     "#s",
+		ExpressionAttributeNames: map[string]string{"#s": "Status"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(req.Target, Equals, "DynamoDB_20120810.Scan")
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{
+		"TableName":                "Orders",
+		"IndexName":                "byStatus",
+		"ProjectionExpression":     "#s",
+		"ExpressionAttributeNames": map[string]interface{}{"#s": "Status"},
+	})
+	c.Assert(result.Count, Equals, int64(1))
+	c.Assert(result.ScannedCount, Equals, int64(3))
+	c.Assert(result.Items[0]["Status"].Value, Equals, "shipped")
+}
@@ -3,6 +3,7 @@ package dynamodb
 import (
 	"errors"
 	"fmt"
+
 	simplejson "github.com/bitly/go-simplejson"
 )
 
@@ -49,3 +50,67 @@ func (t *Table) ParallelScan(attributeComparisons []AttributeComparison, segment
 	q.AddParallelScanConfiguration(segment, totalSegments)
 	return t.FetchResults(q)
 }
+
+// ScanOptions holds the optional parameters of a Scan request, which are
+// as in QueryOptions.
+type ScanOptions struct {
+	IndexName                string
+	Select                   string
+	AttributesToGet          []string
+	ProjectionExpression     string
+	ExpressionAttributeNames map[string]string
+	ConsistentRead           bool
+	Limit                    int64
+
+	FilterExpression          string
+	ExpressionAttributeValues []Attribute
+	ReturnConsumedCapacity    string
+	ExclusiveStartKey         map[string]AttributeValue
+
+	// Segment is the segment scanned, from 0, of a scan of the table in
+	// TotalSegments segments, if TotalSegments isn't zero.
+	Segment       int
+	TotalSegments int
+
+	// TimeToLiveAttribute, if not empty, is the TTL attribute of the
+	// table. The items it has expired, which DynamoDB deletes up to a few
+	// days later, are then left out of the results and of their Count.
+	TimeToLiveAttribute string
+}
+
+// ScanWithOptions returns the items of the table, or of one of its
+// indexes, meeting attributeComparisons.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_Scan.html for details.
+func (t *Table) ScanWithOptions(attributeComparisons []AttributeComparison, options *ScanOptions) (*QueryResult, error) {
+	err := t.checkRead(options.IndexName, options.Select, options.ConsistentRead, options.AttributesToGet, options.ProjectionExpression)
+	if err != nil {
+		return nil, err
+	}
+	q := NewQuery(t)
+	if len(attributeComparisons) > 0 {
+		if options.FilterExpression != "" {
+			return nil, errors.New("Scan filters and FilterExpression can't be used together")
+		}
+		q.AddScanFilter(attributeComparisons)
+	}
+	if options.FilterExpression != "" {
+		q.AddFilterExpression(options.FilterExpression)
+	}
+	q.addReadOptions(options.IndexName, options.Select, options.AttributesToGet, options.ProjectionExpression, options.ExpressionAttributeNames, options.ConsistentRead, options.Limit)
+	q.addExpressions(nil, options.ExpressionAttributeValues, options.ReturnConsumedCapacity)
+	if len(options.ExclusiveStartKey) > 0 {
+		q.AddExclusiveStartKey(options.ExclusiveStartKey)
+	}
+	if options.TotalSegments != 0 {
+		if options.Segment < 0 || options.Segment >= options.TotalSegments {
+			return nil, fmt.Errorf("Segment %d not between 0 and TotalSegments %d", options.Segment, options.TotalSegments)
+		}
+		q.AddParallelScanConfiguration(options.Segment, options.TotalSegments)
+	}
+	result, err := t.Server.readQuery(target("Scan"), q)
+	if err != nil {
+		return nil, err
+	}
+	return t.Server.dropExpired(result, options.TimeToLiveAttribute), nil
+}
@@ -0,0 +1,357 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+)
+
+// The positions GetShardIterator starts reading a shard from.
+const (
+	SHARD_ITERATOR_TRIM_HORIZON          = "TRIM_HORIZON"
+	SHARD_ITERATOR_LATEST                = "LATEST"
+	SHARD_ITERATOR_AT_SEQUENCE_NUMBER    = "AT_SEQUENCE_NUMBER"
+	SHARD_ITERATOR_AFTER_SEQUENCE_NUMBER = "AFTER_SEQUENCE_NUMBER"
+)
+
+// The names of the events of stream records.
+const (
+	EVENT_INSERT = "INSERT"
+	EVENT_MODIFY = "MODIFY"
+	EVENT_REMOVE = "REMOVE"
+)
+
+// ExpiredIteratorError is returned by GetRecords when the shard iterator
+// has expired, 15 minutes after GetShardIterator returned it. Readers get
+// a new one reading after the last record they read.
+type ExpiredIteratorError struct {
+	Err *Error
+}
+
+func (e *ExpiredIteratorError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExpiredIteratorError) Unwrap() error {
+	return e.Err
+}
+
+// TrimmedDataAccessError is returned by GetShardIterator and GetRecords
+// for records past the 24 hours streams keep, which can't be read.
+type TrimmedDataAccessError struct {
+	Err *Error
+}
+
+func (e *TrimmedDataAccessError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TrimmedDataAccessError) Unwrap() error {
+	return e.Err
+}
+
+type Stream struct {
+	StreamArn   string
+	StreamLabel string
+	TableName   string
+}
+
+type StreamDescription struct {
+	CreationRequestDateTime float64
+	KeySchema               []KeySchemaT
+	Shards                  []Shard
+	StreamArn               string
+	StreamLabel             string
+	StreamStatus            string
+	StreamViewType          string
+	TableName               string
+}
+
+// Shard is a shard of a stream. Shards are closed when they are split, or
+// after about 4 hours, and their records are then followed by those of
+// the shards with their ShardId as ParentShardId.
+type Shard struct {
+	ParentShardId       string
+	SequenceNumberRange SequenceNumberRange
+	ShardId             string
+}
+
+type SequenceNumberRange struct {
+	EndingSequenceNumber   string
+	StartingSequenceNumber string
+}
+
+// Closed reports whether the shard is closed, having all its records.
+func (s *Shard) Closed() bool {
+	return s.SequenceNumberRange.EndingSequenceNumber != ""
+}
+
+// Children returns the shards following the shard with the given id.
+func (d *StreamDescription) Children(shardId string) []Shard {
+	var children []Shard
+	for _, shard := range d.Shards {
+		if shard.ParentShardId == shardId {
+			children = append(children, shard)
+		}
+	}
+	return children
+}
+
+// Record is a change of an item of a table, read from its stream. The
+// images in the change are those given by the StreamViewType of the
+// stream.
+//
+// See https://docs.aws.amazon.com/amazondynamodbstreams/latest/APIReference/API_Record.html for details.
+type Record struct {
+	AwsRegion    string
+	Dynamodb     StreamRecord
+	EventID      string
+	EventName    string
+	EventSource  string
+	EventVersion string
+}
+
+type StreamRecord struct {
+	ApproximateCreationDateTime float64
+	Keys                        map[string]AttributeValue
+	NewImage                    map[string]AttributeValue
+	OldImage                    map[string]AttributeValue
+	SequenceNumber              string
+	SizeBytes                   int64
+	StreamViewType              string
+}
+
+// GetRecordsResult holds the records read by GetRecords. NextShardIterator
+// is empty once all the records of a closed shard have been read.
+type GetRecordsResult struct {
+	NextShardIterator string
+	Records           []Record
+}
+
+// queryStreams sends a request to DynamoDB Streams, at the
+// DynamoDBStreamsEndpoint of the region, or at its DynamoDBEndpoint if it has
+// none, as with DynamoDB local.
+func (s *Server) queryStreams(name string, query *Query, resp interface{}) error {
+	endpoint := s.Region.DynamoDBStreamsEndpoint
+	if endpoint == "" {
+		endpoint = s.Region.DynamoDBEndpoint
+	}
+	jsonResponse, err := s.queryEndpoint(endpoint, "DynamoDBStreams_20120810."+name, query)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(jsonResponse, resp); err != nil {
+		return fmt.Errorf("Unexpected response %s", jsonResponse)
+	}
+	return nil
+}
+
+// ListStreams returns the streams of the table with the given name, or of
+// all the tables if it is empty.
+//
+// See https://docs.aws.amazon.com/amazondynamodbstreams/latest/APIReference/API_ListStreams.html for details.
+func (s *Server) ListStreams(tableName string) ([]Stream, error) {
+	var streams []Stream
+	start := ""
+	for {
+		q := NewEmptyQuery()
+		if tableName != "" {
+			q.buffer["TableName"] = tableName
+		}
+		if start != "" {
+			q.buffer["ExclusiveStartStreamArn"] = start
+		}
+		var resp struct {
+			Streams                []Stream
+			LastEvaluatedStreamArn string
+		}
+		if err := s.queryStreams("ListStreams", q, &resp); err != nil {
+			return nil, err
+		}
+		streams = append(streams, resp.Streams...)
+		if resp.LastEvaluatedStreamArn == "" {
+			return streams, nil
+		}
+		start = resp.LastEvaluatedStreamArn
+	}
+}
+
+// DescribeStream returns the description of a stream, with all its
+// shards.
+//
+// See https://docs.aws.amazon.com/amazondynamodbstreams/latest/APIReference/API_DescribeStream.html for details.
+func (s *Server) DescribeStream(streamArn string) (*StreamDescription, error) {
+	var description *StreamDescription
+	start := ""
+	for {
+		q := NewEmptyQuery()
+		q.buffer["StreamArn"] = streamArn
+		if start != "" {
+			q.buffer["ExclusiveStartShardId"] = start
+		}
+		var resp struct {
+			StreamDescription struct {
+				StreamDescription
+				LastEvaluatedShardId string
+			}
+		}
+		if err := s.queryStreams("DescribeStream", q, &resp); err != nil {
+			return nil, err
+		}
+		if description == nil {
+			description = &resp.StreamDescription.StreamDescription
+		} else {
+			description.Shards = append(description.Shards, resp.StreamDescription.Shards...)
+		}
+		if resp.StreamDescription.LastEvaluatedShardId == "" {
+			return description, nil
+		}
+		start = resp.StreamDescription.LastEvaluatedShardId
+	}
+}
+
+// GetShardIterator returns an iterator reading a shard from the position
+// given by iteratorType, one of the SHARD_ITERATOR_ constants.
+// sequenceNumber is the sequence number of a record for the
+// SHARD_ITERATOR_AT_SEQUENCE_NUMBER and
+// SHARD_ITERATOR_AFTER_SEQUENCE_NUMBER positions, and is ignored for the
+// others.
+//
+// See https://docs.aws.amazon.com/amazondynamodbstreams/latest/APIReference/API_GetShardIterator.html for details.
+func (s *Server) GetShardIterator(streamArn, shardId, iteratorType, sequenceNumber string) (string, error) {
+	q := NewEmptyQuery()
+	q.buffer["StreamArn"] = streamArn
+	q.buffer["ShardId"] = shardId
+	q.buffer["ShardIteratorType"] = iteratorType
+	if iteratorType == SHARD_ITERATOR_AT_SEQUENCE_NUMBER || iteratorType == SHARD_ITERATOR_AFTER_SEQUENCE_NUMBER {
+		q.buffer["SequenceNumber"] = sequenceNumber
+	}
+	var resp struct {
+		ShardIterator string
+	}
+	if err := s.queryStreams("GetShardIterator", q, &resp); err != nil {
+		return "", err
+	}
+	return resp.ShardIterator, nil
+}
+
+// GetRecords reads up to limit records, or up to 1000 if limit is 0, with
+// a shard iterator. It fails with an *ExpiredIteratorError if the iterator
+// has expired.
+//
+// See https://docs.aws.amazon.com/amazondynamodbstreams/latest/APIReference/API_GetRecords.html for details.
+func (s *Server) GetRecords(shardIterator string, limit int64) (*GetRecordsResult, error) {
+	q := NewEmptyQuery()
+	q.buffer["ShardIterator"] = shardIterator
+	if limit > 0 {
+		q.AddLimit(limit)
+	}
+	result := &GetRecordsResult{}
+	if err := s.queryStreams("GetRecords", q, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// StreamPollPolicy is the backoff between the reads of FollowShard while
+// the shards it reads have no new records. Its MaxAttempts is ignored.
+var StreamPollPolicy = aws.RetryPolicy{
+	BaseDelay: 250 * time.Millisecond,
+	MaxDelay:  5 * time.Second,
+	Jitter:    0.1,
+}
+
+// followedShard is a shard read by FollowShard.
+type followedShard struct {
+	id       string
+	iterator string
+	// iteratorType and sequenceNumber give where to read the shard from
+	// when its iterator expires.
+	iteratorType   string
+	sequenceNumber string
+}
+
+// FollowShard reads the records of a shard of a stream from the position
+// given by iteratorType and sequenceNumber, as with GetShardIterator, and
+// calls fn with the id of the shard and each batch of records read. When
+// the shard is closed, it reads the shards following it from their start
+// in the same way, and so on, so that the records of an item are given to
+// fn in order.
+//
+// The shards are polled as given by StreamPollPolicy while they have no
+// new records, and read again after the last record given to fn when their
+// iterator expires. FollowShard returns the error returned by fn, which
+// stops it, or nil once all the shards are closed and read, as they are
+// when the stream is disabled.
+func (s *Server) FollowShard(streamArn, shardId, iteratorType, sequenceNumber string, fn func(shardId string, records []Record) error) error {
+	shards := []*followedShard{{id: shardId, iteratorType: iteratorType, sequenceNumber: sequenceNumber}}
+	for idle := 0; len(shards) > 0; {
+		var open []*followedShard
+		read := false
+		for _, shard := range shards {
+			closed, n, err := s.readShard(streamArn, shard, fn)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				read = true
+			}
+			if !closed {
+				open = append(open, shard)
+				continue
+			}
+			read = true
+			description, err := s.DescribeStream(streamArn)
+			if err != nil {
+				return err
+			}
+			for _, child := range description.Children(shard.id) {
+				open = append(open, &followedShard{id: child.ShardId, iteratorType: SHARD_ITERATOR_TRIM_HORIZON})
+			}
+		}
+		shards = open
+		if read {
+			idle = 0
+		} else if len(shards) > 0 {
+			StreamPollPolicy.Backoff(idle)
+			idle++
+		}
+	}
+	return nil
+}
+
+// readShard reads the next records of a shard, and reports whether it is
+// closed and all its records read.
+func (s *Server) readShard(streamArn string, shard *followedShard, fn func(shardId string, records []Record) error) (bool, int, error) {
+	for {
+		if shard.iterator == "" {
+			var err error
+			shard.iterator, err = s.GetShardIterator(streamArn, shard.id, shard.iteratorType, shard.sequenceNumber)
+			if err != nil {
+				return false, 0, err
+			}
+			if shard.iterator == "" {
+				return true, 0, nil
+			}
+		}
+		result, err := s.GetRecords(shard.iterator, 0)
+		if _, ok := err.(*ExpiredIteratorError); ok {
+			shard.iterator = ""
+			continue
+		}
+		if err != nil {
+			return false, 0, err
+		}
+		if len(result.Records) > 0 {
+			if err := fn(shard.id, result.Records); err != nil {
+				return false, 0, err
+			}
+			shard.iteratorType = SHARD_ITERATOR_AFTER_SEQUENCE_NUMBER
+			shard.sequenceNumber = result.Records[len(result.Records)-1].Dynamodb.SequenceNumber
+		}
+		shard.iterator = result.NextShardIterator
+		return shard.iterator == "", len(result.Records), nil
+	}
+}
@@ -0,0 +1,255 @@
+package dynamodb_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/dynamodb"
+	. "gopkg.in/check.v1"
+)
+
+type StreamsSuite struct {
+	policy aws.RetryPolicy
+	delays []time.Duration
+}
+
+var _ = Suite(&StreamsSuite{})
+
+func (s *StreamsSuite) SetUpTest(c *C) {
+	s.policy, s.delays = dynamodb.StreamPollPolicy, nil
+	dynamodb.StreamPollPolicy.Jitter = 0
+	dynamodb.StreamPollPolicy.Sleep = func(d time.Duration) {
+		s.delays = append(s.delays, d)
+	}
+}
+
+func (s *StreamsSuite) TearDownTest(c *C) {
+	dynamodb.StreamPollPolicy = s.policy
+}
+
+const streamArn = "arn:aws:dynamodb:us-east-1:123456789012:table/Orders/stream/2021-01-01T00:00:00.000"
+
+const expiredIterator = `{"__type": "com.amazonaws.dynamodb.v20120810#ExpiredIteratorException", "message": "Iterator expired"}`
+
+func (s *StreamsSuite) TestListStreams(c *C) {
+	var requests []*fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		requests = append(requests, r)
+		if r.Body["ExclusiveStartStreamArn"] == nil {
+			return 200, `{"Streams": [{"StreamArn": "a1", "StreamLabel": "l1", "TableName": "Orders"}], "LastEvaluatedStreamArn": "a1"}`
+		}
+		return 200, `{"Streams": [{"StreamArn": "a2", "StreamLabel": "l2", "TableName": "Orders"}]}`
+	})
+	defer done()
+
+	streams, err := server.ListStreams("Orders")
+	c.Assert(err, IsNil)
+	c.Assert(streams, DeepEquals, []dynamodb.Stream{
+		{StreamArn: "a1", StreamLabel: "l1", TableName: "Orders"},
+		{StreamArn: "a2", StreamLabel: "l2", TableName: "Orders"},
+	})
+	c.Assert(requests, HasLen, 2)
+	c.Assert(requests[0].Target, Equals, "DynamoDBStreams_20120810.ListStreams")
+	c.Assert(requests[0].Body, DeepEquals, map[string]interface{}{"TableName": "Orders"})
+	c.Assert(requests[1].Body, DeepEquals, map[string]interface{}{"TableName": "Orders", "ExclusiveStartStreamArn": "a1"})
+}
+
+func (s *StreamsSuite) TestDescribeStream(c *C) {
+	var requests []*fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		requests = append(requests, r)
+		if r.Body["ExclusiveStartShardId"] == nil {
+			return 200, `{"StreamDescription": {
+				"StreamArn": "` + streamArn + `", "StreamStatus": "ENABLED", "StreamViewType": "NEW_AND_OLD_IMAGES", "TableName": "Orders",
+				"KeySchema": [{"AttributeName": "OrderId", "KeyType": "HASH"}],
+				"Shards": [{"ShardId": "s1", "SequenceNumberRange": {"StartingSequenceNumber": "100", "EndingSequenceNumber": "200"}}],
+				"LastEvaluatedShardId": "s1"
+			}}`
+		}
+		return 200, `{"StreamDescription": {"StreamArn": "` + streamArn + `", "Shards": [
+			{"ShardId": "s2", "ParentShardId": "s1", "SequenceNumberRange": {"StartingSequenceNumber": "300"}},
+			{"ShardId": "s3", "ParentShardId": "s1", "SequenceNumberRange": {"StartingSequenceNumber": "400"}}
+		]}}`
+	})
+	defer done()
+
+	description, err := server.DescribeStream(streamArn)
+	c.Assert(err, IsNil)
+	c.Assert(requests, HasLen, 2)
+	c.Assert(requests[0].Target, Equals, "DynamoDBStreams_20120810.DescribeStream")
+	c.Assert(requests[1].Body, DeepEquals, map[string]interface{}{"StreamArn": streamArn, "ExclusiveStartShardId": "s1"})
+	c.Assert(description.StreamViewType, Equals, "NEW_AND_OLD_IMAGES")
+	c.Assert(description.KeySchema, DeepEquals, []dynamodb.KeySchemaT{{AttributeName: "OrderId", KeyType: "HASH"}})
+	c.Assert(description.Shards, HasLen, 3)
+	c.Assert(description.Shards[0].Closed(), Equals, true)
+	c.Assert(description.Shards[1].Closed(), Equals, false)
+
+	children := description.Children("s1")
+	c.Assert(children, HasLen, 2)
+	c.Assert(children[0].ShardId, Equals, "s2")
+	c.Assert(children[1].ShardId, Equals, "s3")
+	c.Assert(description.Children("s2"), HasLen, 0)
+}
+
+func (s *StreamsSuite) TestGetShardIterator(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, `{"ShardIterator": "it1"}`
+	})
+	defer done()
+
+	iterator, err := server.GetShardIterator(streamArn, "s1", dynamodb.SHARD_ITERATOR_TRIM_HORIZON, "100")
+	c.Assert(err, IsNil)
+	c.Assert(iterator, Equals, "it1")
+	c.Assert(req.Target, Equals, "DynamoDBStreams_20120810.GetShardIterator")
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{"StreamArn": streamArn, "ShardId": "s1", "ShardIteratorType": "TRIM_HORIZON"})
+
+	_, err = server.GetShardIterator(streamArn, "s1", dynamodb.SHARD_ITERATOR_AFTER_SEQUENCE_NUMBER, "100")
+	c.Assert(err, IsNil)
+	c.Assert(req.Body["ShardIteratorType"], Equals, "AFTER_SEQUENCE_NUMBER")
+	c.Assert(req.Body["SequenceNumber"], Equals, "100")
+}
+
+func (s *StreamsSuite) TestGetRecords(c *C) {
+	var req *fakeRequest
+	response := `{"NextShardIterator": "it2", "Records": [{
+		"eventID": "e1", "eventName": "MODIFY", "eventVersion": "1.1", "eventSource": "aws:dynamodb", "awsRegion": "us-east-1",
+		"dynamodb": {
+			"ApproximateCreationDateTime": 1609459200, "SequenceNumber": "111", "SizeBytes": 26, "StreamViewType": "NEW_AND_OLD_IMAGES",
+			"Keys": {"OrderId": {"S": "o1"}},
+			"OldImage": {"OrderId": {"S": "o1"}, "Total": {"N": "10"}},
+			"NewImage": {"OrderId": {"S": "o1"}, "Total": {"N": "12"}, "Paid": {"BOOL": true}}
+		}
+	}]}`
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		if response == "" {
+			return 400, expiredIterator
+		}
+		return 200, response
+	})
+	defer done()
+
+	result, err := server.GetRecords("it1", 10)
+	c.Assert(err, IsNil)
+	c.Assert(req.Target, Equals, "DynamoDBStreams_20120810.GetRecords")
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{"ShardIterator": "it1", "Limit": float64(10)})
+	c.Assert(result.NextShardIterator, Equals, "it2")
+	c.Assert(result.Records, HasLen, 1)
+	record := result.Records[0]
+	c.Assert(record.EventID, Equals, "e1")
+	c.Assert(record.EventName, Equals, dynamodb.EVENT_MODIFY)
+	c.Assert(record.Dynamodb.SequenceNumber, Equals, "111")
+	c.Assert(record.Dynamodb.Keys, DeepEquals, map[string]dynamodb.AttributeValue{"OrderId": {Type: "S", Value: "o1"}})
+	c.Assert(record.Dynamodb.OldImage["Total"].Value, Equals, "10")
+	c.Assert(record.Dynamodb.NewImage["Paid"], DeepEquals, dynamodb.AttributeValue{Type: "BOOL", Bool: true})
+
+	// Expired iterators are reported with their own type
+	response = ""
+	_, err = server.GetRecords("it1", 0)
+	expired, ok := err.(*dynamodb.ExpiredIteratorError)
+	c.Assert(ok, Equals, true, Commentf("%#v", err))
+	c.Assert(expired.Err.Code, Equals, "ExpiredIteratorException")
+	c.Assert(aws.IsCode(err, "ExpiredIteratorException"), Equals, true)
+}
+
+// records returns a GetRecords response with records of the given
+// sequence numbers.
+func records(next string, sequenceNumbers ...int) string {
+	body := `{"NextShardIterator": "` + next + `", "Records": [`
+	for i, n := range sequenceNumbers {
+		if i > 0 {
+			body += ", "
+		}
+		body += fmt.Sprintf(`{"eventName": "INSERT", "dynamodb": {"SequenceNumber": "%d", "Keys": {"OrderId": {"S": "o%d"}}}}`, n, n)
+	}
+	return body + "]}"
+}
+
+func (s *StreamsSuite) TestFollowShard(c *C) {
+	// s1 is split in s2 and s3, and its first iterator expires
+	var iterators []string
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		switch r.Target {
+		case "DynamoDBStreams_20120810.GetShardIterator":
+			iterator := fmt.Sprint(r.Body["ShardId"], "/", r.Body["ShardIteratorType"], "/", r.Body["SequenceNumber"])
+			iterators = append(iterators, iterator)
+			return 200, `{"ShardIterator": "` + iterator + `"}`
+		case "DynamoDBStreams_20120810.DescribeStream":
+			return 200, `{"StreamDescription": {"Shards": [
+				{"ShardId": "s1", "SequenceNumberRange": {"StartingSequenceNumber": "1", "EndingSequenceNumber": "3"}},
+				{"ShardId": "s2", "ParentShardId": "s1"},
+				{"ShardId": "s3", "ParentShardId": "s1"}
+			]}}`
+		}
+		switch r.Body["ShardIterator"] {
+		case "s1/LATEST/<nil>":
+			return 200, records("s1-a", 1, 2)
+		case "s1-a":
+			return 400, expiredIterator
+		case "s1/AFTER_SEQUENCE_NUMBER/2":
+			return 200, records("", 3)
+		case "s2/TRIM_HORIZON/<nil>":
+			return 200, records("s2-a", 4)
+		case "s2-a":
+			return 200, records("s2-b")
+		case "s2-b":
+			return 200, records("", 6)
+		case "s3/TRIM_HORIZON/<nil>":
+			return 200, records("s3-a")
+		case "s3-a", "s3-b":
+			return 200, records("s3-b")
+		}
+		c.Fatalf("unexpected request %v", r.Body)
+		return 500, "{}"
+	})
+	defer done()
+
+	var read []string
+	stop := errors.New("stop")
+	err := server.FollowShard(streamArn, "s1", dynamodb.SHARD_ITERATOR_LATEST, "", func(shardId string, records []dynamodb.Record) error {
+		for _, record := range records {
+			read = append(read, shardId+":"+record.Dynamodb.SequenceNumber)
+		}
+		if shardId == "s2" && len(read) == 5 {
+			return stop
+		}
+		return nil
+	})
+	c.Assert(err, Equals, stop)
+	c.Assert(read, DeepEquals, []string{"s1:1", "s1:2", "s1:3", "s2:4", "s2:6"})
+	c.Assert(iterators, DeepEquals, []string{
+		"s1/LATEST/<nil>",
+		"s1/AFTER_SEQUENCE_NUMBER/2",
+		"s2/TRIM_HORIZON/<nil>",
+		"s3/TRIM_HORIZON/<nil>",
+	})
+	// The follower waited while s2 and s3 had no records
+	c.Assert(s.delays, DeepEquals, []time.Duration{250 * time.Millisecond})
+}
+
+func (s *StreamsSuite) TestFollowShardDisabled(c *C) {
+	// The stream is disabled, closing its shard without children
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		switch r.Target {
+		case "DynamoDBStreams_20120810.GetShardIterator":
+			return 200, `{"ShardIterator": "it1"}`
+		case "DynamoDBStreams_20120810.DescribeStream":
+			return 200, `{"StreamDescription": {"StreamStatus": "DISABLED", "Shards": [{"ShardId": "s1"}]}}`
+		}
+		return 200, records("", 1)
+	})
+	defer done()
+
+	n := 0
+	err := server.FollowShard(streamArn, "s1", dynamodb.SHARD_ITERATOR_TRIM_HORIZON, "", func(shardId string, records []dynamodb.Record) error {
+		n += len(records)
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 1)
+	c.Assert(s.delays, HasLen, 0)
+}
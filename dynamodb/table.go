@@ -4,14 +4,40 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	simplejson "github.com/bitly/go-simplejson"
+	"github.com/goamz/goamz/aws"
 )
 
 type Table struct {
 	Server *Server
 	Name   string
 	Key    PrimaryKey
+
+	// Indexes are the secondary indexes of the table, as returned by
+	// BuildIndexes. They are only needed to check queries on indexes
+	// before they are sent.
+	Indexes []Index
+}
+
+// Index is a secondary index of a table. The key of a local index has the
+// hash key of the table, while a global index may have any key.
+type Index struct {
+	Name   string
+	Key    PrimaryKey
+	Global bool
+}
+
+// Index returns the index of t with the given name, or nil if t has no
+// such index in Indexes.
+func (t *Table) Index(name string) *Index {
+	for i := range t.Indexes {
+		if t.Indexes[i].Name == name {
+			return &t.Indexes[i]
+		}
+	}
+	return nil
 }
 
 type AttributeDefinitionT struct {
@@ -30,14 +56,21 @@ type ProjectionT struct {
 
 type LocalSecondaryIndexT struct {
 	IndexName      string
+	IndexArn       string
 	IndexSizeBytes int64
 	ItemCount      int64
 	KeySchema      []KeySchemaT
 	Projection     ProjectionT
 }
 
+// GlobalSecondaryIndexT describes a global secondary index. IndexStatus
+// is one of the TABLE_STATUS_ constants, and Backfilling is true while an
+// index being created is filled with the items of the table.
 type GlobalSecondaryIndexT struct {
 	IndexName             string
+	IndexArn              string
+	IndexStatus           string
+	Backfilling           bool
 	IndexSizeBytes        int64
 	ItemCount             int64
 	KeySchema             []KeySchemaT
@@ -46,24 +79,56 @@ type GlobalSecondaryIndexT struct {
 }
 
 type ProvisionedThroughputT struct {
+	LastDecreaseDateTime   float64
+	LastIncreaseDateTime   float64
 	NumberOfDecreasesToday int64
 	ReadCapacityUnits      int64
 	WriteCapacityUnits     int64
 }
 
+// Values of the TableStatus of tables and of the IndexStatus of global
+// secondary indexes.
+const (
+	TABLE_STATUS_CREATING = "CREATING"
+	TABLE_STATUS_UPDATING = "UPDATING"
+	TABLE_STATUS_DELETING = "DELETING"
+	TABLE_STATUS_ACTIVE   = "ACTIVE"
+)
+
+// TableDescriptionT describes a table, as returned by DescribeTable. Its
+// ItemCount and sizes are only updated every six hours or so.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_TableDescription.html for details.
 type TableDescriptionT struct {
 	AttributeDefinitions   []AttributeDefinitionT
 	CreationDateTime       float64
 	ItemCount              int64
 	KeySchema              []KeySchemaT
 	GlobalSecondaryIndexes []GlobalSecondaryIndexT
+	LatestStreamArn        string
+	LatestStreamLabel      string
 	LocalSecondaryIndexes  []LocalSecondaryIndexT
 	ProvisionedThroughput  ProvisionedThroughputT
+	TableArn               string
 	TableName              string
 	TableSizeBytes         int64
 	TableStatus            string
 }
 
+// Active reports whether the table and all its global secondary indexes
+// are active.
+func (t *TableDescriptionT) Active() bool {
+	if t.TableStatus != TABLE_STATUS_ACTIVE {
+		return false
+	}
+	for _, ind := range t.GlobalSecondaryIndexes {
+		if ind.IndexStatus != TABLE_STATUS_ACTIVE {
+			return false
+		}
+	}
+	return true
+}
+
 type describeTableResponse struct {
 	Table TableDescriptionT
 }
@@ -91,7 +156,11 @@ func (a *AttributeDefinitionT) GetEmptyAttribute() *Attribute {
 }
 
 func (t *TableDescriptionT) BuildPrimaryKey() (pk PrimaryKey, err error) {
-	for _, k := range t.KeySchema {
+	return t.buildKey(t.KeySchema)
+}
+
+func (t *TableDescriptionT) buildKey(schema []KeySchemaT) (pk PrimaryKey, err error) {
+	for _, k := range schema {
 		var attr *Attribute
 		ad := findAttributeDefinitionByName(t.AttributeDefinitions, k.AttributeName)
 		if ad == nil {
@@ -112,8 +181,29 @@ func (t *TableDescriptionT) BuildPrimaryKey() (pk PrimaryKey, err error) {
 	return
 }
 
+// BuildIndexes returns the secondary indexes of the table, with their
+// keys.
+func (t *TableDescriptionT) BuildIndexes() ([]Index, error) {
+	var indexes []Index
+	for _, ind := range t.LocalSecondaryIndexes {
+		key, err := t.buildKey(ind.KeySchema)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, Index{Name: ind.IndexName, Key: key})
+	}
+	for _, ind := range t.GlobalSecondaryIndexes {
+		key, err := t.buildKey(ind.KeySchema)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, Index{Name: ind.IndexName, Key: key, Global: true})
+	}
+	return indexes, nil
+}
+
 func (s *Server) NewTable(name string, key PrimaryKey) *Table {
-	return &Table{s, name, key}
+	return &Table{Server: s, Name: name, Key: key}
 }
 
 func (s *Server) ListTables() ([]string, error) {
@@ -209,6 +299,127 @@ func (s *Server) DescribeTable(name string) (*TableDescriptionT, error) {
 	return &r.Table, nil
 }
 
+// TableUpdate holds the changes of an UpdateTable request. The key
+// attributes of created indexes must be in AttributeDefinitions.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_UpdateTable.html for details.
+type TableUpdate struct {
+	// ProvisionedThroughput, if not nil, is the new throughput of the
+	// table.
+	ProvisionedThroughput       *ProvisionedThroughputT
+	AttributeDefinitions        []AttributeDefinitionT
+	GlobalSecondaryIndexUpdates []GlobalSecondaryIndexUpdate
+}
+
+// GlobalSecondaryIndexUpdate is the change of a global secondary index, of
+// which only one of the fields is set. Create creates an index, Update sets
+// the ProvisionedThroughput of the index with its IndexName, and Delete is
+// the name of an index deleted.
+type GlobalSecondaryIndexUpdate struct {
+	Create *GlobalSecondaryIndexT
+	Update *GlobalSecondaryIndexT
+	Delete string
+}
+
+// LimitExceededError is returned by the requests exceeding the limits of
+// the control plane, as by updating too many indexes at once or the
+// throughput of tables too often.
+type LimitExceededError struct {
+	Err *Error
+}
+
+func (e *LimitExceededError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LimitExceededError) Unwrap() error {
+	return e.Err
+}
+
+func (t *Table) UpdateTable(update *TableUpdate) (*TableDescriptionT, error) {
+	return t.Server.UpdateTable(t.Name, update)
+}
+
+// UpdateTable changes the throughput of the table with the given name,
+// and creates, updates or deletes its global secondary indexes. The table
+// is then UPDATING, and the indexes created CREATING, until they are
+// active, as waited for by WaitUntilTableActive. It fails with a
+// *LimitExceededError if too many indexes are changed at once.
+func (s *Server) UpdateTable(name string, update *TableUpdate) (*TableDescriptionT, error) {
+	if update.ProvisionedThroughput == nil && len(update.GlobalSecondaryIndexUpdates) == 0 {
+		return nil, fmt.Errorf("Nothing to update in table %s", name)
+	}
+	q := NewEmptyQuery()
+	if err := q.AddUpdateRequestTable(name, update); err != nil {
+		return nil, err
+	}
+
+	jsonResponse, err := s.queryServer(target("UpdateTable"), q)
+	if err != nil {
+		return nil, err
+	}
+
+	var r struct {
+		TableDescription TableDescriptionT
+	}
+	if err := json.Unmarshal(jsonResponse, &r); err != nil {
+		return nil, err
+	}
+	return &r.TableDescription, nil
+}
+
+// TableWaitPolicy is the backoff between the polls of
+// WaitUntilTableActive, which gives up after MaxAttempts polls.
+var TableWaitPolicy = aws.RetryPolicy{
+	MaxAttempts: 60,
+	BaseDelay:   time.Second,
+	MaxDelay:    20 * time.Second,
+	Jitter:      0.1,
+}
+
+// TableStatusError is returned by WaitUntilTableActive when the table, or
+// one of its global secondary indexes, isn't active in time, or is being
+// deleted.
+type TableStatusError struct {
+	Table TableDescriptionT
+}
+
+func (e *TableStatusError) Error() string {
+	if e.Table.TableStatus == TABLE_STATUS_ACTIVE {
+		for _, ind := range e.Table.GlobalSecondaryIndexes {
+			if ind.IndexStatus != TABLE_STATUS_ACTIVE {
+				return fmt.Sprintf("Index %s of table %s is %s, not ACTIVE", ind.IndexName, e.Table.TableName, ind.IndexStatus)
+			}
+		}
+	}
+	return fmt.Sprintf("Table %s is %s, not ACTIVE", e.Table.TableName, e.Table.TableStatus)
+}
+
+// WaitUntilTableActive polls the table with the given name, backing off as
+// given by TableWaitPolicy, until it and all its global secondary indexes
+// are active, as after CreateTable or UpdateTable, and returns its
+// description. It fails with a *TableStatusError if the table is being
+// deleted, or isn't active after the last poll.
+func (s *Server) WaitUntilTableActive(name string) (*TableDescriptionT, error) {
+	policy := TableWaitPolicy
+	var table *TableDescriptionT
+	for attempt := 0; ; attempt++ {
+		var err error
+		table, err = s.DescribeTable(name)
+		if err != nil {
+			return nil, err
+		}
+		if table.Active() {
+			return table, nil
+		}
+		if table.TableStatus == TABLE_STATUS_DELETING || attempt+1 >= policy.MaxAttempts {
+			break
+		}
+		policy.Backoff(attempt)
+	}
+	return nil, &TableStatusError{*table}
+}
+
 func keyParam(k *PrimaryKey, hashKey string, rangeKey string) string {
 	value := fmt.Sprintf("{\"HashKeyElement\":{%s}", keyValue(k.KeyAttribute.Type, hashKey))
 
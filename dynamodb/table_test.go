@@ -1,6 +1,10 @@
 package dynamodb_test
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/goamz/goamz/aws"
 	"github.com/goamz/goamz/dynamodb"
 	. "gopkg.in/check.v1"
 )
@@ -13,7 +17,7 @@ type TableSuite struct {
 func (s *TableSuite) SetUpSuite(c *C) {
 	setUpAuth(c)
 	s.DynamoDBTest.TableDescriptionT = s.TableDescriptionT
-	s.server = &dynamodb.Server{dynamodb_auth, dynamodb_region}
+	s.server = &dynamodb.Server{Auth: dynamodb_auth, Region: dynamodb_region}
 	pk, err := s.TableDescriptionT.BuildPrimaryKey()
 	if err != nil {
 		c.Skip(err.Error())
@@ -77,3 +81,155 @@ func (s *TableSuite) TestCreateListTable(c *C) {
 	c.Check(len(tables), Not(Equals), 0)
 	c.Check(findTableByName(tables, s.TableDescriptionT.TableName), Equals, true)
 }
+
+type UpdateTableSuite struct {
+	policy aws.RetryPolicy
+	delays []time.Duration
+}
+
+var _ = Suite(&UpdateTableSuite{})
+
+func (s *UpdateTableSuite) SetUpTest(c *C) {
+	s.policy, s.delays = dynamodb.TableWaitPolicy, nil
+	dynamodb.TableWaitPolicy = aws.RetryPolicy{MaxAttempts: 4, BaseDelay: time.Second, Sleep: func(d time.Duration) {
+		s.delays = append(s.delays, d)
+	}}
+}
+
+func (s *UpdateTableSuite) TearDownTest(c *C) {
+	dynamodb.TableWaitPolicy = s.policy
+}
+
+// ordersDescription returns the description of the Orders table, in
+// status, with an index ByStatus in indexStatus.
+func ordersDescription(status, indexStatus string) string {
+	return `{
+		"TableName": "Orders",
+		"TableArn": "arn:aws:dynamodb:us-east-1:123456789012:table/Orders",
+		"TableStatus": "` + status + `",
+		"ItemCount": 1024,
+		"TableSizeBytes": 65536,
+		"AttributeDefinitions": [{"AttributeName": "OrderId", "AttributeType": "S"}, {"AttributeName": "Status", "AttributeType": "S"}],
+		"KeySchema": [{"AttributeName": "OrderId", "KeyType": "HASH"}],
+		"ProvisionedThroughput": {"LastIncreaseDateTime": 1.4e9, "NumberOfDecreasesToday": 1, "ReadCapacityUnits": 10, "WriteCapacityUnits": 5},
+		"GlobalSecondaryIndexes": [{
+			"IndexName": "ByStatus",
+			"IndexArn": "arn:aws:dynamodb:us-east-1:123456789012:table/Orders/index/ByStatus",
+			"IndexStatus": "` + indexStatus + `",
+			"Backfilling": ` + fmt.Sprint(indexStatus == "CREATING") + `,
+			"ItemCount": 12,
+			"KeySchema": [{"AttributeName": "Status", "KeyType": "HASH"}],
+			"Projection": {"ProjectionType": "KEYS_ONLY"},
+			"ProvisionedThroughput": {"ReadCapacityUnits": 2, "WriteCapacityUnits": 2}
+		}]
+	}`
+}
+
+func (s *UpdateTableSuite) TestUpdateTable(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		if _, ok := r.Body["ProvisionedThroughput"]; !ok {
+			return 400, `{"__type": "com.amazonaws.dynamodb.v20120810#LimitExceededException", "message": "Subscriber limit exceeded: Only 1 online index can be created or deleted simultaneously per table"}`
+		}
+		return 200, `{"TableDescription": ` + ordersDescription("UPDATING", "CREATING") + `}`
+	})
+	defer done()
+
+	table, err := server.UpdateTable("Orders", &dynamodb.TableUpdate{
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughputT{ReadCapacityUnits: 20, WriteCapacityUnits: 10},
+		AttributeDefinitions:  []dynamodb.AttributeDefinitionT{{"Status", "S"}},
+		GlobalSecondaryIndexUpdates: []dynamodb.GlobalSecondaryIndexUpdate{
+			{Create: &dynamodb.GlobalSecondaryIndexT{
+				IndexName:             "ByStatus",
+				KeySchema:             []dynamodb.KeySchemaT{{"Status", "HASH"}},
+				Projection:            dynamodb.ProjectionT{"KEYS_ONLY"},
+				ProvisionedThroughput: dynamodb.ProvisionedThroughputT{ReadCapacityUnits: 2, WriteCapacityUnits: 2},
+			}},
+			{Update: &dynamodb.GlobalSecondaryIndexT{
+				IndexName:             "ByCustomer",
+				ProvisionedThroughput: dynamodb.ProvisionedThroughputT{ReadCapacityUnits: 4, WriteCapacityUnits: 1},
+			}},
+			{Delete: "ByDate"},
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(req.Target, Equals, "DynamoDB_20120810.UpdateTable")
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{
+		"TableName":             "Orders",
+		"ProvisionedThroughput": map[string]interface{}{"ReadCapacityUnits": float64(20), "WriteCapacityUnits": float64(10)},
+		"AttributeDefinitions":  []interface{}{map[string]interface{}{"AttributeName": "Status", "AttributeType": "S"}},
+		"GlobalSecondaryIndexUpdates": []interface{}{
+			map[string]interface{}{"Create": map[string]interface{}{
+				"IndexName":             "ByStatus",
+				"KeySchema":             []interface{}{map[string]interface{}{"AttributeName": "Status", "KeyType": "HASH"}},
+				"Projection":            map[string]interface{}{"ProjectionType": "KEYS_ONLY"},
+				"ProvisionedThroughput": map[string]interface{}{"ReadCapacityUnits": float64(2), "WriteCapacityUnits": float64(2)},
+			}},
+			map[string]interface{}{"Update": map[string]interface{}{
+				"IndexName":             "ByCustomer",
+				"ProvisionedThroughput": map[string]interface{}{"ReadCapacityUnits": float64(4), "WriteCapacityUnits": float64(1)},
+			}},
+			map[string]interface{}{"Delete": map[string]interface{}{"IndexName": "ByDate"}},
+		},
+	})
+	c.Assert(table.TableStatus, Equals, dynamodb.TABLE_STATUS_UPDATING)
+	c.Assert(table.TableArn, Equals, "arn:aws:dynamodb:us-east-1:123456789012:table/Orders")
+	c.Assert(table.ItemCount, Equals, int64(1024))
+	c.Assert(table.ProvisionedThroughput.NumberOfDecreasesToday, Equals, int64(1))
+	c.Assert(table.GlobalSecondaryIndexes, HasLen, 1)
+	c.Assert(table.GlobalSecondaryIndexes[0].IndexStatus, Equals, dynamodb.TABLE_STATUS_CREATING)
+	c.Assert(table.GlobalSecondaryIndexes[0].Backfilling, Equals, true)
+	c.Assert(table.GlobalSecondaryIndexes[0].ItemCount, Equals, int64(12))
+	c.Assert(table.Active(), Equals, false)
+
+	// Changing too many indexes at once exceeds a limit
+	_, err = server.UpdateTable("Orders", &dynamodb.TableUpdate{
+		GlobalSecondaryIndexUpdates: []dynamodb.GlobalSecondaryIndexUpdate{{Delete: "ByStatus"}},
+	})
+	_, ok := err.(*dynamodb.LimitExceededError)
+	c.Assert(ok, Equals, true)
+	c.Assert(aws.IsCode(err, "LimitExceededException"), Equals, true)
+
+	_, err = server.UpdateTable("Orders", &dynamodb.TableUpdate{})
+	c.Assert(err, ErrorMatches, "Nothing to update in table Orders")
+	_, err = server.UpdateTable("Orders", &dynamodb.TableUpdate{
+		GlobalSecondaryIndexUpdates: []dynamodb.GlobalSecondaryIndexUpdate{{Delete: "ByStatus", Update: &dynamodb.GlobalSecondaryIndexT{}}},
+	})
+	c.Assert(err, ErrorMatches, "An index update needs one of Create, Update or Delete")
+}
+
+func (s *UpdateTableSuite) TestWaitUntilTableActive(c *C) {
+	var statuses [][2]string
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		c.Check(r.Target, Equals, "DynamoDB_20120810.DescribeTable")
+		status := statuses[0]
+		if len(statuses) > 1 {
+			statuses = statuses[1:]
+		}
+		return 200, `{"Table": ` + ordersDescription(status[0], status[1]) + `}`
+	})
+	defer done()
+
+	// The table is active before its new index
+	statuses = [][2]string{{"UPDATING", "CREATING"}, {"ACTIVE", "CREATING"}, {"ACTIVE", "ACTIVE"}}
+	table, err := server.WaitUntilTableActive("Orders")
+	c.Assert(err, IsNil)
+	c.Assert(table.Active(), Equals, true)
+	c.Assert(s.delays, DeepEquals, []time.Duration{time.Second, 2 * time.Second})
+
+	s.delays = nil
+	statuses = [][2]string{{"ACTIVE", "CREATING"}}
+	_, err = server.WaitUntilTableActive("Orders")
+	c.Assert(err, ErrorMatches, "Index ByStatus of table Orders is CREATING, not ACTIVE")
+	c.Assert(s.delays, HasLen, 3)
+
+	s.delays = nil
+	statuses = [][2]string{{"DELETING", "DELETING"}}
+	_, err = server.WaitUntilTableActive("Orders")
+	e, ok := err.(*dynamodb.TableStatusError)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.Table.TableStatus, Equals, dynamodb.TABLE_STATUS_DELETING)
+	c.Assert(err, ErrorMatches, "Table Orders is DELETING, not ACTIVE")
+	c.Assert(s.delays, HasLen, 0)
+}
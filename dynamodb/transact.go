@@ -0,0 +1,286 @@
+package dynamodb
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// TRANSACT_MAX_ITEMS is the most items a transaction can read or write.
+// It was 25 until AWS raised the limit of TransactWriteItems and
+// TransactGetItems to 100 in 2022.
+const TRANSACT_MAX_ITEMS = 100
+
+// The codes of the reasons a transaction is canceled for, by item.
+const (
+	CANCELLATION_NONE                                = "None"
+	CANCELLATION_CONDITIONAL_CHECK_FAILED            = "ConditionalCheckFailed"
+	CANCELLATION_ITEM_COLLECTION_SIZE_LIMIT_EXCEEDED = "ItemCollectionSizeLimitExceeded"
+	CANCELLATION_TRANSACTION_CONFLICT                = "TransactionConflict"
+	CANCELLATION_PROVISIONED_THROUGHPUT_EXCEEDED     = "ProvisionedThroughputExceeded"
+	CANCELLATION_THROTTLING_ERROR                    = "ThrottlingError"
+	CANCELLATION_VALIDATION_ERROR                    = "ValidationError"
+)
+
+// TransactionCanceledError is returned by TransactWriteItems and
+// TransactGetItems when the transaction is canceled, as when the condition
+// of one of its items isn't met. CancellationReasons holds the reason of
+// each of its items, in order, with CANCELLATION_NONE for those that
+// didn't cancel it.
+type TransactionCanceledError struct {
+	Err                 *Error
+	CancellationReasons []CancellationReason
+}
+
+// CancellationReason is the reason an item canceled a transaction. Item is
+// the item whose condition failed, for TransactOptions with
+// ReturnValuesOnConditionCheckFailure.
+type CancellationReason struct {
+	Code    string
+	Message string
+	Item    map[string]*Attribute
+}
+
+func (e *TransactionCanceledError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransactionCanceledError) Unwrap() error {
+	return e.Err
+}
+
+// ConditionFailed reports whether the condition of the item at index i of
+// the transaction failed.
+func (e *TransactionCanceledError) ConditionFailed(i int) bool {
+	return i < len(e.CancellationReasons) && e.CancellationReasons[i].Code == CANCELLATION_CONDITIONAL_CHECK_FAILED
+}
+
+func transactionCanceled(e *Error, jsonBody []byte) *TransactionCanceledError {
+	var resp struct {
+		CancellationReasons []struct {
+			Code    string
+			Message string
+			Item    map[string]interface{}
+		}
+	}
+	json.Unmarshal(jsonBody, &resp)
+	err := &TransactionCanceledError{Err: e}
+	for _, r := range resp.CancellationReasons {
+		reason := CancellationReason{Code: r.Code, Message: r.Message}
+		if r.Item != nil {
+			reason.Item = parseAttributes(r.Item)
+		}
+		err.CancellationReasons = append(err.CancellationReasons, reason)
+	}
+	return err
+}
+
+// TransactOptions holds the optional parameters of the items of a
+// TransactWriteItems request, as WriteOptions. The item is returned in the
+// cancellation reason of the transaction when its ConditionExpression
+// isn't met if ReturnValuesOnConditionCheckFailure is
+// RETURN_VALUES_ALL_OLD.
+type TransactOptions struct {
+	ConditionExpression                 string
+	ExpressionAttributeNames            map[string]string
+	ExpressionAttributeValues           []Attribute
+	ReturnValuesOnConditionCheckFailure string
+}
+
+// TransactWriteItem is a write of a TransactWriteItems request, as
+// returned by the TransactPut, TransactUpdate, TransactDelete and
+// TransactConditionCheck methods of its table.
+type TransactWriteItem struct {
+	action string
+	query  *Query
+	err    error
+}
+
+func (t *Table) transactWriteItem(action string, key *Key, options *TransactOptions) TransactWriteItem {
+	q := NewQuery(t)
+	if key != nil {
+		q.AddKey(t, key)
+	}
+	if options != nil {
+		q.addExpressions(options.ExpressionAttributeNames, options.ExpressionAttributeValues, "")
+		if options.ConditionExpression != "" {
+			q.AddConditionExpression(options.ConditionExpression)
+		}
+		if options.ReturnValuesOnConditionCheckFailure != "" {
+			q.buffer["ReturnValuesOnConditionCheckFailure"] = options.ReturnValuesOnConditionCheckFailure
+		}
+	}
+	return TransactWriteItem{action: action, query: q}
+}
+
+// TransactPut puts an item in a transaction, as PutItemWithOptions.
+func (t *Table) TransactPut(hashKey, rangeKey string, attributes []Attribute, options *TransactOptions) TransactWriteItem {
+	item := t.transactWriteItem("Put", nil, options)
+	if len(attributes) == 0 {
+		item.err = errors.New("At least one attribute is required.")
+	}
+	item.query.AddItem(append(attributes, t.Key.Clone(hashKey, rangeKey)...))
+	return item
+}
+
+// TransactUpdate updates an item in a transaction, as
+// UpdateItemWithOptions.
+func (t *Table) TransactUpdate(key *Key, updateExpression string, options *TransactOptions) TransactWriteItem {
+	item := t.transactWriteItem("Update", key, options)
+	if updateExpression == "" {
+		item.err = errors.New("Update Expression Required")
+	}
+	item.query.AddUpdateExpression(updateExpression)
+	return item
+}
+
+// TransactDelete deletes an item in a transaction, as
+// DeleteItemWithOptions.
+func (t *Table) TransactDelete(key *Key, options *TransactOptions) TransactWriteItem {
+	return t.transactWriteItem("Delete", key, options)
+}
+
+// TransactConditionCheck cancels a transaction unless the item with the
+// given key meets the ConditionExpression of options.
+func (t *Table) TransactConditionCheck(key *Key, options *TransactOptions) TransactWriteItem {
+	item := t.transactWriteItem("ConditionCheck", key, options)
+	if options == nil || options.ConditionExpression == "" {
+		item.err = errors.New("A condition check needs a ConditionExpression")
+	}
+	return item
+}
+
+// TransactWriteOptions holds the optional parameters of a
+// TransactWriteItems request. A transaction is made only once with the
+// same ClientRequestToken for 10 minutes, when the requests retrying it
+// succeed without writing again. TransactWriteItems gives the transaction
+// a random token if it has none.
+type TransactWriteOptions struct {
+	ClientRequestToken     string
+	ReturnConsumedCapacity string
+}
+
+// TransactWriteResult holds the response to a TransactWriteItems request,
+// with the token of the transaction and the capacity it consumed by table.
+type TransactWriteResult struct {
+	ClientRequestToken string
+	ConsumedCapacity   []*ConsumedCapacity
+}
+
+// clientRequestToken returns a random ClientRequestToken, of at most 36
+// characters.
+func clientRequestToken() (string, error) {
+	buf := make([]byte, 18)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func checkTransactItems(n int) error {
+	if n == 0 || n > TRANSACT_MAX_ITEMS {
+		return fmt.Errorf("A transaction needs 1 to %d items, not %d", TRANSACT_MAX_ITEMS, n)
+	}
+	return nil
+}
+
+// TransactWriteItems makes all the writes of items, on items of any
+// tables, or none of them. It fails with a *TransactionCanceledError if
+// one of their conditions isn't met, or if they conflict with another
+// transaction.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_TransactWriteItems.html for details.
+func (s *Server) TransactWriteItems(items []TransactWriteItem, options *TransactWriteOptions) (*TransactWriteResult, error) {
+	if err := checkTransactItems(len(items)); err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &TransactWriteOptions{}
+	}
+	token := options.ClientRequestToken
+	if token == "" {
+		var err error
+		if token, err = clientRequestToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	q := NewEmptyQuery()
+	if err := q.AddTransactWriteItems(items); err != nil {
+		return nil, err
+	}
+	q.buffer["ClientRequestToken"] = token
+	if options.ReturnConsumedCapacity != "" {
+		q.AddReturnConsumedCapacity(options.ReturnConsumedCapacity)
+	}
+
+	jsonResponse, err := s.queryServer(target("TransactWriteItems"), q)
+	if err != nil {
+		return nil, err
+	}
+	result := &TransactWriteResult{ClientRequestToken: token}
+	if err := json.Unmarshal(jsonResponse, result); err != nil {
+		return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+	}
+	return result, nil
+}
+
+// TransactGetItem is a read of a TransactGetItems request. The attributes
+// read are limited by ProjectionExpression, with the placeholders of
+// ExpressionAttributeNames, if it is not empty.
+type TransactGetItem struct {
+	Table                    *Table
+	Key                      *Key
+	ProjectionExpression     string
+	ExpressionAttributeNames map[string]string
+}
+
+// TransactGetResult holds the response to a TransactGetItems request.
+// Items holds the items read, in the order of the reads, with nil for the
+// items not found.
+type TransactGetResult struct {
+	Items            []map[string]*Attribute
+	ConsumedCapacity []*ConsumedCapacity
+}
+
+// TransactGetItems reads items of any tables at once, as they are between
+// transactions. It fails with a *TransactionCanceledError if they are
+// being written by a transaction.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_TransactGetItems.html for details.
+func (s *Server) TransactGetItems(items []TransactGetItem, returnConsumedCapacity string) (*TransactGetResult, error) {
+	if err := checkTransactItems(len(items)); err != nil {
+		return nil, err
+	}
+	q := NewEmptyQuery()
+	q.AddTransactGetItems(items)
+	if returnConsumedCapacity != "" {
+		q.AddReturnConsumedCapacity(returnConsumedCapacity)
+	}
+
+	jsonResponse, err := s.queryServer(target("TransactGetItems"), q)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Responses []struct {
+			Item map[string]interface{}
+		}
+		ConsumedCapacity []*ConsumedCapacity
+	}
+	if err := json.Unmarshal(jsonResponse, &resp); err != nil {
+		return nil, fmt.Errorf("Unexpected response %s", jsonResponse)
+	}
+	result := &TransactGetResult{ConsumedCapacity: resp.ConsumedCapacity}
+	for _, r := range resp.Responses {
+		var item map[string]*Attribute
+		if r.Item != nil {
+			item = parseAttributes(r.Item)
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
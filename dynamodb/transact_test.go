@@ -0,0 +1,214 @@
+package dynamodb_test
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/dynamodb"
+	. "gopkg.in/check.v1"
+)
+
+type TransactSuite struct{}
+
+var _ = Suite(&TransactSuite{})
+
+func (s *TransactSuite) TestTransactWriteItems(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, `{"ConsumedCapacity": [{"TableName": "Users", "CapacityUnits": 2}, {"TableName": "Logins", "CapacityUnits": 4}]}`
+	})
+	defer done()
+	users, logins := batchTables(server)
+	orders := server.NewTable("Orders", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("OrderId", "")})
+
+	result, err := server.TransactWriteItems([]dynamodb.TransactWriteItem{
+		orders.TransactPut("o1", "", []dynamodb.Attribute{*dynamodb.NewStringAttribute("User", "u1")}, &dynamodb.TransactOptions{
+			ConditionExpression: "attribute_not_exists(OrderId)",
+		}),
+		users.TransactUpdate(&dynamodb.Key{HashKey: "u1"}, "SET #o = #o + :one", &dynamodb.TransactOptions{
+			ExpressionAttributeNames:  map[string]string{"#o": "Orders"},
+			ExpressionAttributeValues: []dynamodb.Attribute{*dynamodb.NewNumericAttribute(":one", "1")},
+		}),
+		logins.TransactDelete(&dynamodb.Key{HashKey: "u1", RangeKey: "12"}, nil),
+		users.TransactConditionCheck(&dynamodb.Key{HashKey: "u2"}, &dynamodb.TransactOptions{
+			ConditionExpression:                 "attribute_exists(Id)",
+			ReturnValuesOnConditionCheckFailure: dynamodb.RETURN_VALUES_ALL_OLD,
+		}),
+	}, &dynamodb.TransactWriteOptions{ClientRequestToken: "order-o1", ReturnConsumedCapacity: dynamodb.RETURN_CONSUMED_CAPACITY_TOTAL})
+	c.Assert(err, IsNil)
+	c.Assert(req.Target, Equals, "DynamoDB_20120810.TransactWriteItems")
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{
+		"ClientRequestToken":     "order-o1",
+		"ReturnConsumedCapacity": "TOTAL",
+		"TransactItems": []interface{}{
+			map[string]interface{}{"Put": map[string]interface{}{
+				"TableName": "Orders",
+				"Item": map[string]interface{}{
+					"OrderId": map[string]interface{}{"S": "o1"},
+					"User":    map[string]interface{}{"S": "u1"},
+				},
+				"ConditionExpression": "attribute_not_exists(OrderId)",
+			}},
+			map[string]interface{}{"Update": map[string]interface{}{
+				"TableName":                 "Users",
+				"Key":                       map[string]interface{}{"Id": map[string]interface{}{"S": "u1"}},
+				"UpdateExpression":          "SET #o = #o + :one",
+				"ExpressionAttributeNames":  map[string]interface{}{"#o": "Orders"},
+				"ExpressionAttributeValues": map[string]interface{}{":one": map[string]interface{}{"N": "1"}},
+			}},
+			map[string]interface{}{"Delete": map[string]interface{}{
+				"TableName": "Logins",
+				"Key": map[string]interface{}{
+					"User": map[string]interface{}{"S": "u1"},
+					"At":   map[string]interface{}{"N": "12"},
+				},
+			}},
+			map[string]interface{}{"ConditionCheck": map[string]interface{}{
+				"TableName":                           "Users",
+				"Key":                                 map[string]interface{}{"Id": map[string]interface{}{"S": "u2"}},
+				"ConditionExpression":                 "attribute_exists(Id)",
+				"ReturnValuesOnConditionCheckFailure": "ALL_OLD",
+			}},
+		},
+	})
+	c.Assert(result.ClientRequestToken, Equals, "order-o1")
+	c.Assert(result.ConsumedCapacity, DeepEquals, []*dynamodb.ConsumedCapacity{
+		{TableName: "Users", CapacityUnits: 2},
+		{TableName: "Logins", CapacityUnits: 4},
+	})
+}
+
+func (s *TransactSuite) TestTransactWriteItemsCanceled(c *C) {
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		return 400, `{"__type": "com.amazonaws.dynamodb.v20120810#TransactionCanceledException",
+			"Message": "Transaction cancelled, please refer cancellation reasons for specific reasons [None, ConditionalCheckFailed]",
+			"CancellationReasons": [
+				{"Code": "None"},
+				{"Code": "ConditionalCheckFailed", "Message": "The conditional request failed", "Item": {"Id": {"S": "u2"}, "Banned": {"BOOL": true}}}
+			]}`
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	_, err := server.TransactWriteItems([]dynamodb.TransactWriteItem{
+		users.TransactDelete(&dynamodb.Key{HashKey: "u1"}, nil),
+		users.TransactConditionCheck(&dynamodb.Key{HashKey: "u2"}, &dynamodb.TransactOptions{
+			ConditionExpression:                 "attribute_not_exists(Banned)",
+			ReturnValuesOnConditionCheckFailure: dynamodb.RETURN_VALUES_ALL_OLD,
+		}),
+	}, nil)
+	canceled, ok := err.(*dynamodb.TransactionCanceledError)
+	c.Assert(ok, Equals, true, Commentf("%#v", err))
+	c.Assert(aws.IsCode(err, "TransactionCanceledException"), Equals, true)
+	c.Assert(canceled.ConditionFailed(0), Equals, false)
+	c.Assert(canceled.ConditionFailed(1), Equals, true)
+	c.Assert(canceled.ConditionFailed(2), Equals, false)
+	c.Assert(canceled.CancellationReasons, DeepEquals, []dynamodb.CancellationReason{
+		{Code: dynamodb.CANCELLATION_NONE},
+		{Code: dynamodb.CANCELLATION_CONDITIONAL_CHECK_FAILED, Message: "The conditional request failed", Item: map[string]*dynamodb.Attribute{
+			"Id":     dynamodb.NewStringAttribute("Id", "u2"),
+			"Banned": dynamodb.NewBooleanAttribute("Banned", true),
+		}},
+	})
+}
+
+func (s *TransactSuite) TestTransactWriteItemsRetry(c *C) {
+	// The response to the first request is lost after a timeout
+	var mu sync.Mutex
+	var tokens []interface{}
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		mu.Lock()
+		tokens = append(tokens, r.Body["ClientRequestToken"])
+		first := len(tokens) == 1
+		mu.Unlock()
+		if first {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return 200, `{}`
+	})
+	defer done()
+	users, _ := batchTables(server)
+	server.HTTPClient = &http.Client{Timeout: 50 * time.Millisecond}
+	server.RetryPolicy = &aws.RetryPolicy{MaxAttempts: 2, Sleep: func(time.Duration) {}}
+
+	result, err := server.TransactWriteItems([]dynamodb.TransactWriteItem{
+		users.TransactDelete(&dynamodb.Key{HashKey: "u1"}, nil),
+	}, nil)
+	c.Assert(err, IsNil)
+
+	// The retry has the same token, for the transaction to be made once
+	mu.Lock()
+	defer mu.Unlock()
+	c.Assert(tokens, HasLen, 2)
+	c.Assert(tokens[1], Equals, tokens[0])
+	c.Assert(tokens[0], Equals, result.ClientRequestToken)
+	c.Assert(result.ClientRequestToken, HasLen, 36)
+}
+
+func (s *TransactSuite) TestTransactItemsInvalid(c *C) {
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		c.Errorf("unexpected request %v", r.Body)
+		return 500, "{}"
+	})
+	defer done()
+	users, _ := batchTables(server)
+	key := &dynamodb.Key{HashKey: "u1"}
+
+	_, err := server.TransactWriteItems(nil, nil)
+	c.Assert(err, ErrorMatches, "A transaction needs 1 to 100 items, not 0")
+	items := make([]dynamodb.TransactWriteItem, 101)
+	for i := range items {
+		items[i] = users.TransactDelete(key, nil)
+	}
+	_, err = server.TransactWriteItems(items, nil)
+	c.Assert(err, ErrorMatches, "A transaction needs 1 to 100 items, not 101")
+	_, err = server.TransactGetItems(nil, "")
+	c.Assert(err, ErrorMatches, "A transaction needs 1 to 100 items, not 0")
+
+	_, err = server.TransactWriteItems([]dynamodb.TransactWriteItem{users.TransactConditionCheck(key, nil)}, nil)
+	c.Assert(err, ErrorMatches, "A condition check needs a ConditionExpression")
+	_, err = server.TransactWriteItems([]dynamodb.TransactWriteItem{users.TransactPut("u1", "", nil, nil)}, nil)
+	c.Assert(err, ErrorMatches, "At least one attribute is required.")
+	_, err = server.TransactWriteItems([]dynamodb.TransactWriteItem{{}}, nil)
+	c.Assert(err, ErrorMatches, "A transaction item needs a table")
+}
+
+func (s *TransactSuite) TestTransactGetItems(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		return 200, `{"Responses": [{"Item": {"Id": {"S": "u1"}, "Orders": {"N": "3"}}}, {}]}`
+	})
+	defer done()
+	users, logins := batchTables(server)
+
+	result, err := server.TransactGetItems([]dynamodb.TransactGetItem{
+		{Table: users, Key: &dynamodb.Key{HashKey: "u1"}, ProjectionExpression: "Id, #o", ExpressionAttributeNames: map[string]string{"#o": "Orders"}},
+		{Table: logins, Key: &dynamodb.Key{HashKey: "u1", RangeKey: "12"}},
+	}, "")
+	c.Assert(err, IsNil)
+	c.Assert(req.Target, Equals, "DynamoDB_20120810.TransactGetItems")
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{
+		"TransactItems": []interface{}{
+			map[string]interface{}{"Get": map[string]interface{}{
+				"TableName":                "Users",
+				"Key":                      map[string]interface{}{"Id": map[string]interface{}{"S": "u1"}},
+				"ProjectionExpression":     "Id, #o",
+				"ExpressionAttributeNames": map[string]interface{}{"#o": "Orders"},
+			}},
+			map[string]interface{}{"Get": map[string]interface{}{
+				"TableName": "Logins",
+				"Key": map[string]interface{}{
+					"User": map[string]interface{}{"S": "u1"},
+					"At":   map[string]interface{}{"N": "12"},
+				},
+			}},
+		},
+	})
+	c.Assert(result.Items, HasLen, 2)
+	c.Assert(result.Items[0]["Orders"], DeepEquals, dynamodb.NewNumericAttribute("Orders", "3"))
+	c.Assert(result.Items[1], IsNil)
+}
@@ -0,0 +1,126 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// The TimeToLiveStatus of tables.
+const (
+	TTL_STATUS_ENABLING  = "ENABLING"
+	TTL_STATUS_DISABLING = "DISABLING"
+	TTL_STATUS_ENABLED   = "ENABLED"
+	TTL_STATUS_DISABLED  = "DISABLED"
+)
+
+// TimeToLiveSpecification is the TTL setting of a table. Its items expire
+// at the time of their AttributeName attribute, an N value of seconds
+// since the Unix epoch, if Enabled, and are deleted by DynamoDB within a
+// few days.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/TTL.html for details.
+type TimeToLiveSpecification struct {
+	AttributeName string
+	Enabled       bool
+}
+
+// TimeToLiveDescription is the TTL setting of a table, as returned by
+// DescribeTimeToLive. AttributeName is empty while TTL is disabled.
+type TimeToLiveDescription struct {
+	AttributeName    string
+	TimeToLiveStatus string
+}
+
+// Enabled reports whether the items of the table expire, or are about to.
+func (d *TimeToLiveDescription) Enabled() bool {
+	return d.TimeToLiveStatus == TTL_STATUS_ENABLED || d.TimeToLiveStatus == TTL_STATUS_ENABLING
+}
+
+// UpdateTimeToLive enables or disables the expiration of the items of the
+// table with the given name at the time of their attributeName attribute.
+// The setting takes up to an hour to apply, and can't be changed again
+// meanwhile.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_UpdateTimeToLive.html for details.
+func (s *Server) UpdateTimeToLive(name, attributeName string, enabled bool) (*TimeToLiveSpecification, error) {
+	q := NewEmptyQuery()
+	q.addTableByName(name)
+	q.buffer["TimeToLiveSpecification"] = TimeToLiveSpecification{AttributeName: attributeName, Enabled: enabled}
+
+	var resp struct {
+		TimeToLiveSpecification *TimeToLiveSpecification
+	}
+	jsonResponse, err := s.queryServer(target("UpdateTimeToLive"), q)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(jsonResponse, &resp); err != nil {
+		return nil, err
+	}
+	return resp.TimeToLiveSpecification, nil
+}
+
+// DescribeTimeToLive returns the TTL setting of the table with the given
+// name.
+//
+// See https://docs.aws.amazon.com/amazondynamodb/latest/APIReference/API_DescribeTimeToLive.html for details.
+func (s *Server) DescribeTimeToLive(name string) (*TimeToLiveDescription, error) {
+	q := NewEmptyQuery()
+	q.addTableByName(name)
+
+	var resp struct {
+		TimeToLiveDescription *TimeToLiveDescription
+	}
+	jsonResponse, err := s.queryServer(target("DescribeTimeToLive"), q)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(jsonResponse, &resp); err != nil {
+		return nil, err
+	}
+	return resp.TimeToLiveDescription, nil
+}
+
+// UpdateTimeToLive sets the TTL of the table, as Server.UpdateTimeToLive.
+func (t *Table) UpdateTimeToLive(attributeName string, enabled bool) (*TimeToLiveSpecification, error) {
+	return t.Server.UpdateTimeToLive(t.Name, attributeName, enabled)
+}
+
+// DescribeTimeToLive returns the TTL setting of the table.
+func (t *Table) DescribeTimeToLive() (*TimeToLiveDescription, error) {
+	return t.Server.DescribeTimeToLive(t.Name)
+}
+
+// Expired reports whether the item has expired at the given time by its
+// TTL attribute with the given name, as DynamoDB sees it. Items without
+// the attribute, or where it isn't a number, don't expire.
+func Expired(item map[string]AttributeValue, attributeName string, now time.Time) bool {
+	a, ok := item[attributeName]
+	if !ok || a.Type != TYPE_NUMBER {
+		return false
+	}
+	seconds, err := strconv.ParseFloat(a.Value, 64)
+	return err == nil && seconds <= float64(now.Unix())
+}
+
+// dropExpired leaves the items of result expired by their attributeName
+// attribute out of its items, and of its Count, if attributeName isn't
+// empty.
+func (s *Server) dropExpired(result *QueryResult, attributeName string) *QueryResult {
+	if attributeName == "" || result == nil {
+		return result
+	}
+	now := s.clock.Now()
+	items, values := result.Items[:0], result.ItemValues[:0]
+	for i, item := range result.ItemValues {
+		if Expired(item, attributeName, now) {
+			continue
+		}
+		items = append(items, result.Items[i])
+		values = append(values, item)
+	}
+	result.Count -= int64(len(result.ItemValues) - len(values))
+	result.Items, result.ItemValues = items, values
+	return result
+}
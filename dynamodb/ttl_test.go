@@ -0,0 +1,144 @@
+package dynamodb_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goamz/goamz/dynamodb"
+	. "gopkg.in/check.v1"
+)
+
+type TTLSuite struct{}
+
+var _ = Suite(&TTLSuite{})
+
+type session struct {
+	Id        string
+	ExpiresAt time.Time `dynamodb:",ttl"`
+	Created   time.Time
+}
+
+func (s *TTLSuite) TestMarshalTTL(c *C) {
+	// The same instant in different time zones has the same TTL
+	utc := time.Date(2021, 3, 28, 1, 30, 0, 0, time.UTC)
+	zones := []*time.Location{time.UTC, time.FixedZone("CET", 3600), time.FixedZone("PDT", -7*3600), time.FixedZone("NPT", 5*3600+45*60)}
+	for _, zone := range zones {
+		item, err := dynamodb.Marshal(session{Id: "s1", ExpiresAt: utc.In(zone), Created: utc.In(zone)})
+		c.Assert(err, IsNil)
+		c.Assert(item["ExpiresAt"], DeepEquals, dynamodb.AttributeValue{Type: "N", Value: "1616895000"}, Commentf("%s", zone))
+		c.Assert(item["Created"].Type, Equals, "S")
+
+		var read session
+		c.Assert(dynamodb.Unmarshal(item, &read), IsNil)
+		c.Assert(read.ExpiresAt, Equals, utc)
+		c.Assert(read.Created.Equal(utc), Equals, true)
+	}
+
+	// Fractions of seconds are dropped
+	item, err := dynamodb.Marshal(session{ExpiresAt: time.Unix(-1, 999999999)})
+	c.Assert(err, IsNil)
+	c.Assert(item["ExpiresAt"].Value, Equals, "-1")
+}
+
+func (s *TTLSuite) TestMarshalTTLTypes(c *C) {
+	// Zero times don't expire
+	item, err := dynamodb.Marshal(session{Id: "s1"})
+	c.Assert(err, IsNil)
+	c.Assert(item["ExpiresAt"], DeepEquals, dynamodb.AttributeValue{Type: "NULL"})
+
+	at := time.Unix(1616895000, 0)
+	pointers := struct {
+		ExpiresAt *time.Time `dynamodb:"ttl,ttl"`
+		Never     *time.Time `dynamodb:",ttl"`
+		Seconds   int64      `dynamodb:",ttl"`
+	}{ExpiresAt: &at, Seconds: 1616895000}
+	item, err = dynamodb.Marshal(pointers)
+	c.Assert(err, IsNil)
+	c.Assert(item, DeepEquals, map[string]dynamodb.AttributeValue{
+		"ttl":     {Type: "N", Value: "1616895000"},
+		"Never":   {Type: "NULL"},
+		"Seconds": {Type: "N", Value: "1616895000"},
+	})
+
+	_, err = dynamodb.Marshal(struct {
+		ExpiresAt string `dynamodb:",ttl"`
+	}{"tomorrow"})
+	c.Assert(err, ErrorMatches, "UnsupportedTTLTypeError .*")
+
+	var read session
+	err = dynamodb.Unmarshal(map[string]dynamodb.AttributeValue{"ExpiresAt": {Type: "N", Value: "1.5"}}, &read)
+	c.Assert(err, ErrorMatches, `ExpiresAt: UnmarshalTypeError \(time\) "1.5": .*`)
+}
+
+func (s *TTLSuite) TestTimeToLive(c *C) {
+	var req *fakeRequest
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		req = r
+		if r.Target == "DynamoDB_20120810.UpdateTimeToLive" {
+			return 200, `{"TimeToLiveSpecification": {"AttributeName": "ExpiresAt", "Enabled": true}}`
+		}
+		return 200, `{"TimeToLiveDescription": {"AttributeName": "ExpiresAt", "TimeToLiveStatus": "ENABLING"}}`
+	})
+	defer done()
+	users, _ := batchTables(server)
+
+	spec, err := users.UpdateTimeToLive("ExpiresAt", true)
+	c.Assert(err, IsNil)
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{
+		"TableName":               "Users",
+		"TimeToLiveSpecification": map[string]interface{}{"AttributeName": "ExpiresAt", "Enabled": true},
+	})
+	c.Assert(spec, DeepEquals, &dynamodb.TimeToLiveSpecification{AttributeName: "ExpiresAt", Enabled: true})
+
+	description, err := users.DescribeTimeToLive()
+	c.Assert(err, IsNil)
+	c.Assert(req.Target, Equals, "DynamoDB_20120810.DescribeTimeToLive")
+	c.Assert(req.Body, DeepEquals, map[string]interface{}{"TableName": "Users"})
+	c.Assert(description, DeepEquals, &dynamodb.TimeToLiveDescription{AttributeName: "ExpiresAt", TimeToLiveStatus: dynamodb.TTL_STATUS_ENABLING})
+	c.Assert(description.Enabled(), Equals, true)
+	c.Assert((&dynamodb.TimeToLiveDescription{TimeToLiveStatus: dynamodb.TTL_STATUS_DISABLED}).Enabled(), Equals, false)
+}
+
+func (s *TTLSuite) TestDropExpired(c *C) {
+	now := time.Now()
+	response := fmt.Sprintf(`{"Count": 4, "ScannedCount": 5, "Items": [
+		{"Id": {"S": "expired"}, "ExpiresAt": {"N": "%d"}},
+		{"Id": {"S": "valid"}, "ExpiresAt": {"N": "%d"}},
+		{"Id": {"S": "forever"}},
+		{"Id": {"S": "invalid"}, "ExpiresAt": {"S": "soon"}}
+	]}`, now.Add(-time.Hour).Unix(), now.Add(time.Hour).Unix())
+	server, done := fakeServer(c, func(r *fakeRequest) (int, string) {
+		return 200, response
+	})
+	defer done()
+	users, _ := batchTables(server)
+	ids := func(result *dynamodb.QueryResult) []string {
+		var ids []string
+		for i, item := range result.Items {
+			c.Assert(result.ItemValues[i]["Id"].Value, Equals, item["Id"].Value)
+			ids = append(ids, item["Id"].Value)
+		}
+		return ids
+	}
+
+	result, err := users.ScanWithOptions(nil, &dynamodb.ScanOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(ids(result), DeepEquals, []string{"expired", "valid", "forever", "invalid"})
+
+	result, err = users.ScanWithOptions(nil, &dynamodb.ScanOptions{TimeToLiveAttribute: "ExpiresAt"})
+	c.Assert(err, IsNil)
+	c.Assert(ids(result), DeepEquals, []string{"valid", "forever", "invalid"})
+	c.Assert(result.Count, Equals, int64(3))
+	c.Assert(result.ScannedCount, Equals, int64(5))
+
+	result, err = users.QueryWithOptions([]dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("Id", "u1"),
+	}, &dynamodb.QueryOptions{TimeToLiveAttribute: "ExpiresAt"})
+	c.Assert(err, IsNil)
+	c.Assert(ids(result), DeepEquals, []string{"valid", "forever", "invalid"})
+
+	item := map[string]dynamodb.AttributeValue{"ExpiresAt": {Type: "N", Value: "1616895000"}}
+	c.Assert(dynamodb.Expired(item, "ExpiresAt", time.Unix(1616894999, 0)), Equals, false)
+	c.Assert(dynamodb.Expired(item, "ExpiresAt", time.Unix(1616895000, 0)), Equals, true)
+	c.Assert(dynamodb.Expired(item, "Other", time.Unix(1616895000, 0)), Equals, false)
+}
@@ -11,9 +11,11 @@
 package ec2
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -33,13 +35,30 @@ const debug = false
 type EC2 struct {
 	aws.Auth
 	aws.Region
-	httpClient *http.Client
-	private    byte // Reserve the right of using private data.
+
+	// Credentials, when not nil, provides the credentials used to sign
+	// each request instead of Auth, so that temporary credentials can be
+	// renewed without creating a new EC2.
+	Credentials aws.CredentialsProvider
+
+	// RetryPolicy is used to retry failed requests. It defaults to
+	// aws.DefaultRetryPolicy.
+	RetryPolicy *aws.RetryPolicy
+
+	// HTTPClient performs the requests. New sets it to aws.RetryingClient,
+	// and http.DefaultClient is used if it is nil.
+	HTTPClient *http.Client
+
+	// clock corrects the time requests are signed with when the local
+	// clock is skewed.
+	clock aws.Clock
+
+	private byte // Reserve the right of using private data.
 }
 
 // NewWithClient creates a new EC2 with a custom http client
 func NewWithClient(auth aws.Auth, region aws.Region, client *http.Client) *EC2 {
-	return &EC2{auth, region, client, 0}
+	return &EC2{Auth: auth, Region: region, HTTPClient: client}
 }
 
 // New creates a new EC2.
@@ -97,38 +116,20 @@ func (f *Filter) addParams(params map[string]string) {
 // Error encapsulates an error returned by EC2.
 //
 // See http://goo.gl/VZGuC for more details.
-type Error struct {
-	// HTTP status code (200, 403, ...)
-	StatusCode int
-	// EC2 error code ("UnsupportedOperation", ...)
-	Code string
-	// The human-oriented error message
-	Message   string
-	RequestId string `xml:"RequestID"`
-}
-
-func (err *Error) Error() string {
-	if err.Code == "" {
-		return err.Message
-	}
-
-	return fmt.Sprintf("%s (%s)", err.Message, err.Code)
-}
-
-// For now a single error inst is being exposed. In the future it may be useful
-// to provide access to all of them, but rather than doing it as an array/slice,
-// use a *next pointer, so that it's backward compatible and it continues to be
-// easy to handle the first error, which is what most people will want.
-type xmlErrors struct {
-	RequestId string  `xml:"RequestID"`
-	Errors    []Error `xml:"Errors>Error"`
-}
+type Error = aws.Error
 
 var timeNow = time.Now
 
+// latestVersion is the API version of the requests with parameters or
+// results the default version lacks, such as the IPv6 ranges and
+// descriptions of security group rules and the encryption of volumes and
+// snapshots.
+const latestVersion = "2016-11-15"
+
 func (ec2 *EC2) query(params map[string]string, resp interface{}) error {
-	params["Version"] = "2014-02-01"
-	params["Timestamp"] = timeNow().In(time.UTC).Format(time.RFC3339)
+	if params["Version"] == "" {
+		params["Version"] = "2014-02-01"
+	}
 	endpoint, err := url.Parse(ec2.Region.EC2Endpoint)
 	if err != nil {
 		return err
@@ -136,12 +137,29 @@ func (ec2 *EC2) query(params map[string]string, resp interface{}) error {
 	if endpoint.Path == "" {
 		endpoint.Path = "/"
 	}
-	sign(ec2.Auth, "GET", endpoint.Path, params, endpoint.Host)
-	endpoint.RawQuery = multimap(params).Encode()
-	if debug {
-		log.Printf("get { %v } -> {\n", endpoint.String())
+	auth, err := aws.CurrentAuth(ec2.Auth, ec2.Credentials)
+	if err != nil {
+		return err
 	}
-	r, err := ec2.httpClient.Get(endpoint.String())
+	policy := ec2.RetryPolicy
+	if policy == nil {
+		policy = &aws.DefaultRetryPolicy
+	}
+	client := ec2.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	r, err := ec2.clock.Do(policy, client, func(time.Time) (*http.Request, error) {
+		// Use timeNow, which tests may fix, rather than the time given
+		params["Timestamp"] = timeNow().Add(ec2.clock.Offset()).In(time.UTC).Format(time.RFC3339)
+		delete(params, "Signature")
+		sign(auth, "GET", endpoint.Path, params, endpoint.Host)
+		endpoint.RawQuery = canonicalQuery(params)
+		if debug {
+			log.Printf("get { %v } -> {\n", endpoint.String())
+		}
+		return http.NewRequest("GET", endpoint.String(), nil)
+	})
 	if err != nil {
 		return err
 	}
@@ -159,27 +177,8 @@ func (ec2 *EC2) query(params map[string]string, resp interface{}) error {
 	return err
 }
 
-func multimap(p map[string]string) url.Values {
-	q := make(url.Values, len(p))
-	for k, v := range p {
-		q[k] = []string{v}
-	}
-	return q
-}
-
 func buildError(r *http.Response) error {
-	errors := xmlErrors{}
-	xml.NewDecoder(r.Body).Decode(&errors)
-	var err Error
-	if len(errors.Errors) > 0 {
-		err = errors.Errors[0]
-	}
-	err.RequestId = errors.RequestId
-	err.StatusCode = r.StatusCode
-	if err.Message == "" {
-		err.Message = r.Status
-	}
-	return &err
+	return aws.BuildError(r)
 }
 
 func makeParams(action string) map[string]string {
@@ -220,8 +219,17 @@ func addBlockDeviceParams(params map[string]string, blockDevices []BlockDeviceMa
 		if k.DeleteOnTermination {
 			params[prefix+"Ebs.DeleteOnTermination"] = "true"
 		}
+		if k.Encrypted {
+			params[prefix+"Ebs.Encrypted"] = "true"
+		}
+		if k.KmsKeyId != "" {
+			params[prefix+"Ebs.KmsKeyId"] = k.KmsKeyId
+		}
+		if k.VolumeId != "" {
+			params[prefix+"Ebs.VolumeId"] = k.VolumeId
+		}
 		if k.NoDevice {
-			params[prefix+"NoDevice"] = "true"
+			params[prefix+"NoDevice"] = ""
 		}
 	}
 }
@@ -303,10 +311,10 @@ type Instance struct {
 	ProductCodes          []ProductCode `xml:"productCodes>item"`     // The product codes attached to this instance
 
 	// Storage
-	RootDeviceType string        `xml:"rootDeviceType"`          // Valid values: ebs | instance-store
-	RootDeviceName string        `xml:"rootDeviceName"`          // The root device name (for example, /dev/sda1)
-	BlockDevices   []BlockDevice `xml:"blockDeviceMapping>item"` // Any block device mapping entries for the instance
-	EbsOptimized   bool          `xml:"ebsOptimized"`            // Indicates whether the instance is optimized for Amazon EBS I/O
+	RootDeviceType string               `xml:"rootDeviceType"`          // Valid values: ebs | instance-store
+	RootDeviceName string               `xml:"rootDeviceName"`          // The root device name (for example, /dev/sda1)
+	BlockDevices   []BlockDeviceMapping `xml:"blockDeviceMapping>item"` // Any block device mapping entries for the instance
+	EbsOptimized   bool                 `xml:"ebsOptimized"`            // Indicates whether the instance is optimized for Amazon EBS I/O
 
 	// Network
 	DNSName          string          `xml:"dnsName"`          // The public DNS name assigned to the instance. This element remains empty until the instance enters the running state
@@ -331,6 +339,9 @@ func (i Instance) IsSpotInstance() bool {
 	return false
 }
 
+// BlockDevice is the block device mapping of an instance.
+//
+// Deprecated: Instance.BlockDevices is a []BlockDeviceMapping.
 type BlockDevice struct {
 	DeviceName string `xml:"deviceName"`
 	EBS        EBS    `xml:"ebs"`
@@ -372,7 +383,7 @@ type InstanceNetworkInterface struct {
 // InstanceNetworkInterfaceAttachment describes a network interface attachment to an instance
 // See http://goo.gl/0ql0Cg for more details
 type InstanceNetworkInterfaceAttachment struct {
-	AttachmentID        string `xml:"attachmentID"`        // The ID of the network interface attachment.
+	AttachmentID        string `xml:"attachmentId"`        // The ID of the network interface attachment.
 	DeviceIndex         int32  `xml:"deviceIndex"`         // The index of the device on the instance for the network interface attachment.
 	Status              string `xml:"status"`              // Valid values: attaching | attached | detaching | detached
 	AttachTime          string `xml:"attachTime"`          // Time attached, as a Datetime
@@ -404,6 +415,16 @@ type IamInstanceProfile struct {
 	Name string `xml:"name"`
 }
 
+// addParams adds the profile to params, by ARN or by name.
+func (p IamInstanceProfile) addParams(params map[string]string) {
+	if p.ARN != "" {
+		params["IamInstanceProfile.Arn"] = p.ARN
+	}
+	if p.Name != "" {
+		params["IamInstanceProfile.Name"] = p.Name
+	}
+}
+
 // RunInstances starts new instances in EC2.
 // If options.MinCount and options.MaxCount are both zero, a single instance
 // will be started; otherwise if options.MaxCount is zero, options.MinCount
@@ -412,8 +433,6 @@ type IamInstanceProfile struct {
 // See http://goo.gl/Mcm3b for more details.
 func (ec2 *EC2) RunInstances(options *RunInstancesOptions) (resp *RunInstancesResp, err error) {
 	params := makeParams("RunInstances")
-	params["ImageId"] = options.ImageId
-	params["InstanceType"] = options.InstanceType
 	var min, max int
 	if options.MinCount == 0 && options.MaxCount == 0 {
 		min = 1
@@ -432,7 +451,34 @@ func (ec2 *EC2) RunInstances(options *RunInstancesOptions) (resp *RunInstancesRe
 		return nil, err
 	}
 	params["ClientToken"] = token
+	addLaunchParams(params, options)
+	if options.Tenancy != "" {
+		params["Placement.Tenancy"] = options.Tenancy
+	}
+	if options.DisableAPITermination {
+		params["DisableApiTermination"] = "true"
+	}
+	if options.ShutdownBehavior != "" {
+		params["InstanceInitiatedShutdownBehavior"] = options.ShutdownBehavior
+	}
+	if options.PrivateIPAddress != "" {
+		params["PrivateIpAddress"] = options.PrivateIPAddress
+	}
 
+	resp = &RunInstancesResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// addLaunchParams adds the parameters describing the instances to
+// launch, which RunInstances has in common with the launch specification
+// of RequestSpotInstances.
+func addLaunchParams(params map[string]string, options *RunInstancesOptions) {
+	params["ImageId"] = options.ImageId
+	params["InstanceType"] = options.InstanceType
 	if options.KeyName != "" {
 		params["KeyName"] = options.KeyName
 	}
@@ -453,9 +499,6 @@ func (ec2 *EC2) RunInstances(options *RunInstancesOptions) (resp *RunInstancesRe
 	if options.PlacementGroupName != "" {
 		params["Placement.GroupName"] = options.PlacementGroupName
 	}
-	if options.Tenancy != "" {
-		params["Placement.Tenancy"] = options.Tenancy
-	}
 	if options.Monitoring {
 		params["Monitoring.Enabled"] = "true"
 	}
@@ -495,33 +538,12 @@ func (ec2 *EC2) RunInstances(options *RunInstancesOptions) (resp *RunInstancesRe
 			}
 		}
 	}
-	if options.IamInstanceProfile.ARN != "" {
-		params["IamInstanceProfile.Arn"] = options.IamInstanceProfile.ARN
-	}
-	if options.IamInstanceProfile.Name != "" {
-		params["IamInstanceProfile.Name"] = options.IamInstanceProfile.Name
-	}
-	if options.DisableAPITermination {
-		params["DisableApiTermination"] = "true"
-	}
-	if options.ShutdownBehavior != "" {
-		params["InstanceInitiatedShutdownBehavior"] = options.ShutdownBehavior
-	}
-	if options.PrivateIPAddress != "" {
-		params["PrivateIpAddress"] = options.PrivateIPAddress
-	}
+	options.IamInstanceProfile.addParams(params)
 	if options.EbsOptimized {
 		params["EbsOptimized"] = "true"
 	}
 
 	addBlockDeviceParams(params, options.BlockDevices)
-
-	resp = &RunInstancesResp{}
-	err = ec2.query(params, resp)
-	if err != nil {
-		return nil, err
-	}
-	return
 }
 
 func clientToken() (string, error) {
@@ -587,6 +609,7 @@ func (ec2 *EC2) TerminateInstances(instIds []string) (resp *TerminateInstancesRe
 type DescribeInstancesResp struct {
 	RequestId    string        `xml:"requestId"`
 	Reservations []Reservation `xml:"reservationSet>item"`
+	NextToken    string        `xml:"nextToken"`
 }
 
 // Reservation represents details about a reservation in EC2.
@@ -609,6 +632,10 @@ func (ec2 *EC2) DescribeInstances(instIds []string, filter *Filter) (resp *Descr
 	params := makeParams("DescribeInstances")
 	addParamsList(params, "InstanceId", instIds)
 	filter.addParams(params)
+	return ec2.describeInstances(params)
+}
+
+func (ec2 *EC2) describeInstances(params map[string]string) (resp *DescribeInstancesResp, err error) {
 	resp = &DescribeInstancesResp{}
 	err = ec2.query(params, resp)
 	if err != nil {
@@ -627,11 +654,62 @@ func (ec2 *EC2) DescribeInstances(instIds []string, filter *Filter) (resp *Descr
 	return
 }
 
+// DescribeInstancesOptions encapsulates the query parameters of a page of
+// DescribeInstances results.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInstances.html for more details.
+type DescribeInstancesOptions struct {
+	InstanceIds []string // If non-empty, limit the query to this subset of instances. Can't be used with MaxResults.
+	MaxResults  int      // Maximum number of instances to return, between 5 and 1000. Zero returns all of them.
+	NextToken   string   // The token for the next page of results, from a prior call.
+}
+
+// DescribeInstancesPage returns a page of the details about instances in
+// EC2 which DescribeInstances returns, continued by the call with the
+// NextToken of the response, if any.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInstances.html for more details.
+func (ec2 *EC2) DescribeInstancesPage(options *DescribeInstancesOptions, filter *Filter) (resp *DescribeInstancesResp, err error) {
+	params := makeParams("DescribeInstances")
+	params["Version"] = latestVersion
+	addParamsList(params, "InstanceId", options.InstanceIds)
+	if options.MaxResults != 0 {
+		params["MaxResults"] = strconv.Itoa(options.MaxResults)
+	}
+	if options.NextToken != "" {
+		params["NextToken"] = options.NextToken
+	}
+	filter.addParams(params)
+	return ec2.describeInstances(params)
+}
+
+// InstancesAll returns the instances of all the reservations in EC2
+// matching filter, which is optional, following the pages of results.
+// Each page is retried as given by the retry policy of ec2 when
+// throttled.
+func (ec2 *EC2) InstancesAll(filter *Filter) ([]Instance, error) {
+	var instances []Instance
+	options := DescribeInstancesOptions{MaxResults: 1000}
+	for {
+		resp, err := ec2.DescribeInstancesPage(&options, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, rsv := range resp.Reservations {
+			instances = append(instances, rsv.Instances...)
+		}
+		if resp.NextToken == "" {
+			return instances, nil
+		}
+		options.NextToken = resp.NextToken
+	}
+}
+
 // DescribeInstanceStatusOptions encapsulates the query parameters for the corresponding action.
 //
 // See http:////goo.gl/2FBTdS for more details.
 type DescribeInstanceStatusOptions struct {
-	InstanceIds         []string // If non-empty, limit the query to this subset of instances. Maximum length of 100.
+	InstanceIds         []string // If non-empty, limit the query to this subset of instances. Maximum length of 100. Can't be used with MaxResults.
 	IncludeAllInstances bool     // If true, describe all instances, instead of just running instances (the default).
 	MaxResults          int      // Maximum number of results to return. Minimum of 5. Maximum of 1000.
 	NextToken           string   // The token for the next set of items to return. (You received this token from a prior call.)
@@ -662,18 +740,26 @@ type InstanceStatusItem struct {
 //
 // See http://goo.gl/PXsDTn for more details.
 type InstanceStatusEvent struct {
-	Code        string `xml:"code"`        // The associated code of the event.
-	Description string `xml:"description"` // A description of the event.
-	NotBefore   string `xml:"notBefore"`   // The earliest scheduled start time for the event.
-	NotAfter    string `xml:"notAfter"`    // The latest scheduled end time for the event.
+	Id          string    `xml:"instanceEventId"` // The ID of the event.
+	Code        string    `xml:"code"`            // The associated code of the event, e.g. instance-retirement or system-maintenance.
+	Description string    `xml:"description"`     // A description of the event.
+	NotBefore   time.Time `xml:"notBefore"`       // The earliest scheduled start time for the event.
+	NotAfter    time.Time `xml:"notAfter"`        // The latest scheduled end time for the event, zero if none.
+}
+
+// Done returns whether the event is over, as EC2 keeps describing
+// completed and canceled events for a while with a description starting
+// with "[Completed]" or "[Canceled]".
+func (e InstanceStatusEvent) Done() bool {
+	return strings.HasPrefix(e.Description, "[Completed]") || strings.HasPrefix(e.Description, "[Canceled]")
 }
 
 // InstanceStatus describes the status of an instance with details.
 //
 // See http://goo.gl/eFch4S for more details.
 type InstanceStatus struct {
-	Status  string                `xml:"status"`  // The instance status.
-	Details InstanceStatusDetails `xml:"details"` // The system instance health or application instance health.
+	Status  string                  `xml:"status"`       // The instance status. Valid values: ok | impaired | insufficient-data | not-applicable | initializing
+	Details []InstanceStatusDetails `xml:"details>item"` // The system instance health or application instance health.
 }
 
 // InstanceStatusDetails describes the instance status with the cause and more detail.
@@ -714,6 +800,54 @@ func (ec2 *EC2) DescribeInstanceStatus(options *DescribeInstanceStatusOptions, f
 	return
 }
 
+// InstanceStatusAll returns the statuses DescribeInstanceStatus returns for
+// options and filter, following the pages of results, which are small by
+// default. Each page is retried as given by the retry policy of ec2 when
+// throttled.
+func (ec2 *EC2) InstanceStatusAll(options *DescribeInstanceStatusOptions, filter *Filter) ([]InstanceStatusItem, error) {
+	var statuses []InstanceStatusItem
+	pageOptions := *options
+	if pageOptions.MaxResults == 0 && len(pageOptions.InstanceIds) == 0 {
+		pageOptions.MaxResults = 1000
+	}
+	for {
+		resp, err := ec2.DescribeInstanceStatus(&pageOptions, filter)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, resp.InstanceStatusSet...)
+		if resp.NextToken == "" {
+			return statuses, nil
+		}
+		pageOptions.NextToken = resp.NextToken
+	}
+}
+
+// InstancesWithEvents returns the statuses of the instances matching
+// filter, which is optional, with scheduled events such as retirements or
+// maintenance. Events which are done are left out of the Events of the
+// statuses, and instances with none left aren't returned.
+func (ec2 *EC2) InstancesWithEvents(filter *Filter) ([]InstanceStatusItem, error) {
+	statuses, err := ec2.InstanceStatusAll(&DescribeInstanceStatusOptions{IncludeAllInstances: true}, filter)
+	if err != nil {
+		return nil, err
+	}
+	var withEvents []InstanceStatusItem
+	for _, status := range statuses {
+		var events []InstanceStatusEvent
+		for _, e := range status.Events {
+			if !e.Done() {
+				events = append(events, e)
+			}
+		}
+		if len(events) > 0 {
+			status.Events = events
+			withEvents = append(withEvents, status)
+		}
+	}
+	return withEvents, nil
+}
+
 // ----------------------------------------------------------------------------
 // KeyPair management functions and types.
 
@@ -781,6 +915,123 @@ func (ec2 *EC2) CreateTags(resourceIds []string, tags []Tag) (resp *SimpleResp,
 	return resp, nil
 }
 
+// DeleteTags removes tags from the specified resources. Each tag is only
+// removed where its value is the one given, so a tag with an empty value
+// only removes tags with an empty value. See DeleteTagKeys to remove tags
+// whatever their value.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteTags.html for more details.
+func (ec2 *EC2) DeleteTags(resourceIds []string, tags []Tag) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteTags")
+	addParamsList(params, "ResourceId", resourceIds)
+	for j, tag := range tags {
+		params["Tag."+strconv.Itoa(j+1)+".Key"] = tag.Key
+		params["Tag."+strconv.Itoa(j+1)+".Value"] = tag.Value
+	}
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteTagKeys removes the tags with the given keys from the specified
+// resources, whatever their value. Without keys, it removes all the tags
+// of the resources.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteTags.html for more details.
+func (ec2 *EC2) DeleteTagKeys(resourceIds []string, keys []string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteTags")
+	addParamsList(params, "ResourceId", resourceIds)
+	for j, key := range keys {
+		params["Tag."+strconv.Itoa(j+1)+".Key"] = key
+	}
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// TagDescription describes a tag of a resource.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_TagDescription.html for more details.
+type TagDescription struct {
+	ResourceId   string `xml:"resourceId"`
+	ResourceType string `xml:"resourceType"`
+	Key          string `xml:"key"`
+	Value        string `xml:"value"`
+}
+
+// DescribeTagsOptions encapsulates the query parameters of a page of
+// DescribeTags results.
+type DescribeTagsOptions struct {
+	MaxResults int    // Maximum number of tags to return, between 5 and 1000. Zero returns all of them.
+	NextToken  string // The token for the next page of results, from a prior call.
+}
+
+// DescribeTagsResp represents a response from a DescribeTags request.
+type DescribeTagsResp struct {
+	RequestId string           `xml:"requestId"`
+	Tags      []TagDescription `xml:"tagSet>item"`
+	NextToken string           `xml:"nextToken"`
+}
+
+// DescribeTags returns a page of the tags of the resources in EC2, as
+// limited by filter, continued by the call with the NextToken of the
+// response, if any.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeTags.html for more details.
+func (ec2 *EC2) DescribeTags(options *DescribeTagsOptions, filter *Filter) (resp *DescribeTagsResp, err error) {
+	params := makeParams("DescribeTags")
+	params["Version"] = latestVersion
+	if options.MaxResults != 0 {
+		params["MaxResults"] = strconv.Itoa(options.MaxResults)
+	}
+	if options.NextToken != "" {
+		params["NextToken"] = options.NextToken
+	}
+	filter.addParams(params)
+	resp = &DescribeTagsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// TagsAll returns the tags of all the resources in EC2 matching filter,
+// which is optional, following the pages of results.
+func (ec2 *EC2) TagsAll(filter *Filter) ([]TagDescription, error) {
+	var tags []TagDescription
+	options := DescribeTagsOptions{MaxResults: 1000}
+	for {
+		resp, err := ec2.DescribeTags(&options, filter)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, resp.Tags...)
+		if resp.NextToken == "" {
+			return tags, nil
+		}
+		options.NextToken = resp.NextToken
+	}
+}
+
+// InstancesByTag returns all the instances with the tag key set to value,
+// or set to any value if value is empty.
+func (ec2 *EC2) InstancesByTag(key, value string) ([]Instance, error) {
+	filter := NewFilter()
+	if value == "" {
+		filter.Add("tag-key", key)
+	} else {
+		filter.Add("tag:"+key, value)
+	}
+	return ec2.InstancesAll(filter)
+}
+
 // Response to a StartInstances request.
 //
 // See http://goo.gl/awKeF for more details.
@@ -868,7 +1119,8 @@ type ModifyInstanceResp struct {
 
 // ModifyImageAttribute modifies the specified attribute of the specified instance.
 // You can specify only one attribute at a time. To modify some attributes, the
-// instance must be stopped.
+// instance must be stopped. ModifyInstanceAttribute makes sure a single
+// attribute is sent.
 //
 // See http://goo.gl/icuXh5 for more details.
 func (ec2 *EC2) ModifyInstance(instId string, options *ModifyInstance) (resp *ModifyInstanceResp, err error) {
@@ -930,21 +1182,367 @@ func (ec2 *EC2) ModifyInstance(instId string, options *ModifyInstance) (resp *Mo
 	return
 }
 
-// Reserved Instances
+// Names of the attributes of instances, for ModifyInstanceAttribute and
+// DescribeInstanceAttribute.
+const (
+	AttrInstanceType                      = "instanceType"
+	AttrKernel                            = "kernel"
+	AttrRamdisk                           = "ramdisk"
+	AttrUserData                          = "userData"
+	AttrDisableApiTermination             = "disableApiTermination"
+	AttrInstanceInitiatedShutdownBehavior = "instanceInitiatedShutdownBehavior"
+	AttrRootDeviceName                    = "rootDeviceName"
+	AttrBlockDeviceMapping                = "blockDeviceMapping"
+	AttrSourceDestCheck                   = "sourceDestCheck"
+	AttrGroupSet                          = "groupSet"
+	AttrEbsOptimized                      = "ebsOptimized"
+	AttrSriovNetSupport                   = "sriovNetSupport"
+)
+
+// InstanceAttribute is the new value of the attribute Name of an instance,
+// one of the Attr constants. The security groups of AttrGroupSet are given
+// by GroupIds, the block devices of AttrBlockDeviceMapping by BlockDevices
+// and the user data by UserData, which is base64-encoded when sent. The
+// other attributes take Value, which is "true" or "false" for the boolean
+// ones.
+type InstanceAttribute struct {
+	Name         string
+	Value        string
+	UserData     []byte
+	GroupIds     []string
+	BlockDevices []BlockDeviceMapping
+}
+
+// ModifyInstanceAttribute changes one attribute of the instance instId, as
+// the API allows a single attribute per call. The instance must be stopped
+// to change its type, kernel, ramdisk or user data.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ModifyInstanceAttribute.html for more details.
+func (ec2 *EC2) ModifyInstanceAttribute(instId string, attr InstanceAttribute) (resp *ModifyInstanceResp, err error) {
+	params := makeParams("ModifyInstanceAttribute")
+	params["InstanceId"] = instId
+	switch attr.Name {
+	case AttrGroupSet:
+		addParamsList(params, "GroupId", attr.GroupIds)
+	case AttrBlockDeviceMapping:
+		addBlockDeviceParams(params, attr.BlockDevices)
+	case AttrUserData:
+		params["UserData.Value"] = b64.EncodeToString(attr.UserData)
+	case "":
+		return nil, errors.New("ec2: no instance attribute to modify")
+	default:
+		params[strings.ToUpper(attr.Name[:1])+attr.Name[1:]+".Value"] = attr.Value
+	}
+
+	resp = &ModifyInstanceResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Response to a DescribeInstanceAttribute request. Only the field of the
+// attribute asked for is set, with UserData decoded from base64.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInstanceAttribute.html for more details.
+type InstanceAttributeResp struct {
+	RequestId             string               `xml:"requestId"`
+	InstanceId            string               `xml:"instanceId"`
+	InstanceType          string               `xml:"instanceType>value"`
+	KernelId              string               `xml:"kernel>value"`
+	RamdiskId             string               `xml:"ramdisk>value"`
+	UserData              []byte               `xml:"userData>value"`
+	DisableAPITermination bool                 `xml:"disableApiTermination>value"`
+	ShutdownBehavior      string               `xml:"instanceInitiatedShutdownBehavior>value"`
+	RootDeviceName        string               `xml:"rootDeviceName>value"`
+	BlockDevices          []BlockDeviceMapping `xml:"blockDeviceMapping>item"`
+	SourceDestCheck       bool                 `xml:"sourceDestCheck>value"`
+	SecurityGroups        []SecurityGroup      `xml:"groupSet>item"`
+	EbsOptimized          bool                 `xml:"ebsOptimized>value"`
+	SriovNetSupport       string               `xml:"sriovNetSupport>value"`
+}
+
+// DescribeInstanceAttribute returns the attribute attr of the instance
+// instId, one of the Attr constants.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInstanceAttribute.html for more details.
+func (ec2 *EC2) DescribeInstanceAttribute(instId, attr string) (resp *InstanceAttributeResp, err error) {
+	params := makeParams("DescribeInstanceAttribute")
+	params["InstanceId"] = instId
+	params["Attribute"] = attr
+
+	resp = &InstanceAttributeResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.UserData) > 0 {
+		resp.UserData, err = b64.DecodeString(string(resp.UserData))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// InstanceWaitPolicy is the backoff between the polls of
+// WaitUntilInstanceState and the WaitUntilInstance functions, which give
+// up after MaxAttempts polls.
+var InstanceWaitPolicy = aws.RetryPolicy{
+	MaxAttempts: 60,
+	BaseDelay:   5 * time.Second,
+	MaxDelay:    30 * time.Second,
+	Jitter:      0.1,
+}
+
+// InstanceStateError is returned by WaitUntilInstanceState and the
+// WaitUntilInstance functions when an instance was terminated, or isn't in
+// the state waited for in time.
+type InstanceStateError struct {
+	Instance Instance
+	Want     string
+}
+
+func (e *InstanceStateError) Error() string {
+	if e.Instance.StateReason.Message != "" {
+		return fmt.Sprintf("instance %s is %s, not %s: %s", e.Instance.InstanceId, e.Instance.State.Name, e.Want, e.Instance.StateReason.Message)
+	}
+	return fmt.Sprintf("instance %s is %s, not %s", e.Instance.InstanceId, e.Instance.State.Name, e.Want)
+}
+
+// WaitUntilInstanceState polls the instance with the given id, backing off
+// as given by InstanceWaitPolicy, until it is in state, such as "running"
+// or "stopped", and returns it. An instance that isn't found yet, as
+// happens right after RunInstances, is polled again. It fails with an
+// *InstanceStateError if the instance is terminated while waiting for
+// another state, or isn't in state after the last poll.
+func (ec2 *EC2) WaitUntilInstanceState(id, state string) (*Instance, error) {
+	insts, err := ec2.waitUntilInstances(context.Background(), []string{id}, state)
+	if err != nil {
+		return nil, err
+	}
+	return &insts[0], nil
+}
+
+// WaitUntilInstanceRunning waits as WaitUntilInstanceState until the
+// instances with the given ids are all running, and returns them in the
+// order of ids. It fails as soon as one of them is shutting down or
+// terminated, and with the error of ctx once it is done.
+func (ec2 *EC2) WaitUntilInstanceRunning(ctx context.Context, ids []string) ([]Instance, error) {
+	return ec2.waitUntilInstances(ctx, ids, "running")
+}
+
+// WaitUntilInstanceStopped waits as WaitUntilInstanceRunning until the
+// instances with the given ids are all stopped.
+func (ec2 *EC2) WaitUntilInstanceStopped(ctx context.Context, ids []string) ([]Instance, error) {
+	return ec2.waitUntilInstances(ctx, ids, "stopped")
+}
+
+// WaitUntilInstanceTerminated waits as WaitUntilInstanceRunning until the
+// instances with the given ids are all terminated.
+func (ec2 *EC2) WaitUntilInstanceTerminated(ctx context.Context, ids []string) ([]Instance, error) {
+	return ec2.waitUntilInstances(ctx, ids, "terminated")
+}
+
+func (ec2 *EC2) waitUntilInstances(ctx context.Context, ids []string, state string) ([]Instance, error) {
+	policy := InstanceWaitPolicy
+	found := make(map[string]Instance)
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		resp, err := ec2.DescribeInstances(ids, nil)
+		if err != nil && !aws.IsCode(err, "InvalidInstanceID.NotFound") {
+			return nil, err
+		}
+		if err == nil {
+			for _, rsv := range resp.Reservations {
+				for _, inst := range rsv.Instances {
+					found[inst.InstanceId] = inst
+				}
+			}
+			done := true
+			for _, id := range ids {
+				inst, ok := found[id]
+				if !ok || inst.State.Name != state {
+					done = false
+				}
+				if ok && state != "terminated" && (inst.State.Name == "shutting-down" || inst.State.Name == "terminated") {
+					return nil, &InstanceStateError{inst, state}
+				}
+			}
+			if done {
+				insts := make([]Instance, len(ids))
+				for i, id := range ids {
+					insts[i] = found[id]
+				}
+				return insts, nil
+			}
+		}
+		if attempt+1 >= policy.MaxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(policy.Delay(attempt)):
+		}
+	}
+	var inst Instance
+	for _, id := range ids {
+		var ok bool
+		if inst, ok = found[id]; !ok {
+			inst.InstanceId = id
+		}
+		if inst.State.Name != state {
+			break
+		}
+	}
+	return nil, &InstanceStateError{inst, state}
+}
+
+// ResizeInstance changes the type of the EBS-backed instance id to
+// newType. It stops the instance, changes its type once it's stopped, and
+// starts it again, returning once it's running. The instance keeps its
+// volumes, but gets a new public IP address unless it has an Elastic IP.
+func (ec2 *EC2) ResizeInstance(id, newType string) error {
+	if _, err := ec2.StopInstances(id); err != nil {
+		return err
+	}
+	if _, err := ec2.WaitUntilInstanceState(id, "stopped"); err != nil {
+		return err
+	}
+	if _, err := ec2.ModifyInstanceAttribute(id, InstanceAttribute{Name: AttrInstanceType, Value: newType}); err != nil {
+		return err
+	}
+	if _, err := ec2.StartInstances(id); err != nil {
+		return err
+	}
+	_, err := ec2.WaitUntilInstanceState(id, "running")
+	return err
+}
+
+// IamInstanceProfileAssociation associates an IAM instance profile with
+// an instance. State is one of "associating", "associated",
+// "disassociating" and "disassociated".
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_IamInstanceProfileAssociation.html for more details.
+type IamInstanceProfileAssociation struct {
+	AssociationId      string             `xml:"associationId"`
+	InstanceId         string             `xml:"instanceId"`
+	IamInstanceProfile IamInstanceProfile `xml:"iamInstanceProfile"`
+	State              string             `xml:"state"`
+	Timestamp          string             `xml:"timestamp"`
+}
+
+// Response to an AssociateIamInstanceProfile,
+// ReplaceIamInstanceProfileAssociation or DisassociateIamInstanceProfile
+// request.
+type IamInstanceProfileAssociationResp struct {
+	RequestId   string                        `xml:"requestId"`
+	Association IamInstanceProfileAssociation `xml:"iamInstanceProfileAssociation"`
+}
+
+// AssociateIamInstanceProfile associates the IAM instance profile, given
+// by ARN or by name, with the running or stopped instance instId, which
+// has none.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AssociateIamInstanceProfile.html for more details.
+func (ec2 *EC2) AssociateIamInstanceProfile(instId string, profile IamInstanceProfile) (resp *IamInstanceProfileAssociationResp, err error) {
+	params := makeParams("AssociateIamInstanceProfile")
+	params["Version"] = latestVersion
+	params["InstanceId"] = instId
+	profile.addParams(params)
+
+	resp = &IamInstanceProfileAssociationResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ReplaceIamInstanceProfileAssociation replaces the IAM instance profile of
+// the association associationId, changing the profile of a running
+// instance without disassociating the old one first.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ReplaceIamInstanceProfileAssociation.html for more details.
+func (ec2 *EC2) ReplaceIamInstanceProfileAssociation(associationId string, profile IamInstanceProfile) (resp *IamInstanceProfileAssociationResp, err error) {
+	params := makeParams("ReplaceIamInstanceProfileAssociation")
+	params["Version"] = latestVersion
+	params["AssociationId"] = associationId
+	profile.addParams(params)
+
+	resp = &IamInstanceProfileAssociationResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DisassociateIamInstanceProfile removes the IAM instance profile of the
+// association associationId from its instance.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DisassociateIamInstanceProfile.html for more details.
+func (ec2 *EC2) DisassociateIamInstanceProfile(associationId string) (resp *IamInstanceProfileAssociationResp, err error) {
+	params := makeParams("DisassociateIamInstanceProfile")
+	params["Version"] = latestVersion
+	params["AssociationId"] = associationId
+
+	resp = &IamInstanceProfileAssociationResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Response to a DescribeIamInstanceProfileAssociations request.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeIamInstanceProfileAssociations.html for more details.
+type IamInstanceProfileAssociationsResp struct {
+	RequestId    string                          `xml:"requestId"`
+	Associations []IamInstanceProfileAssociation `xml:"iamInstanceProfileAssociationSet>item"`
+	NextToken    string                          `xml:"nextToken"`
+}
+
+// DescribeIamInstanceProfileAssociations returns the IAM instance profile
+// associations with the given ids, or all of them, as limited by filter,
+// which may use the "instance-id" and "state" names.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeIamInstanceProfileAssociations.html for more details.
+func (ec2 *EC2) DescribeIamInstanceProfileAssociations(associationIds []string, filter *Filter) (resp *IamInstanceProfileAssociationsResp, err error) {
+	params := makeParams("DescribeIamInstanceProfileAssociations")
+	params["Version"] = latestVersion
+	addParamsList(params, "AssociationId", associationIds)
+	filter.addParams(params)
+
+	resp = &IamInstanceProfileAssociationsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
 
-// Structures
+// Reserved Instances
 
 // DescribeReservedInstancesResponse structure returned from a DescribeReservedInstances request.
 //
-// See
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeReservedInstances.html for more details.
 type DescribeReservedInstancesResponse struct {
 	RequestId         string                          `xml:"requestId"`
 	ReservedInstances []ReservedInstancesResponseItem `xml:"reservedInstancesSet>item"`
 }
 
+// ReservedInstancesResponseItem describes a purchase of reserved
+// instances. State is one of payment-pending, active, payment-failed and
+// retired. OfferingClass is standard or convertible, and Scope is
+// Availability Zone or Region.
 //
-//
-// See
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ReservedInstances.html for more details.
 type ReservedInstancesResponseItem struct {
 	ReservedInstanceId string            `xml:"reservedInstancesId"`
 	InstanceType       string            `xml:"instanceType"`
@@ -957,27 +1555,29 @@ type ReservedInstancesResponseItem struct {
 	InstanceCount      int               `xml:"instanceCount"`
 	ProductDescription string            `xml:"productDescription"`
 	State              string            `xml:"state"`
-	Tags               []Tag             `xml:"tagSet->item"`
+	Tags               []Tag             `xml:"tagSet>item"`
 	InstanceTenancy    string            `xml:"instanceTenancy"`
 	CurrencyCode       string            `xml:"currencyCode"`
 	OfferingType       string            `xml:"offeringType"`
+	OfferingClass      string            `xml:"offeringClass"`
+	Scope              string            `xml:"scope"`
 	RecurringCharges   []RecurringCharge `xml:"recurringCharges>item"`
 }
 
-//
-//
-// See
+// RecurringCharge is a charge of reserved instances paid every period of
+// Frequency, which is Hourly.
 type RecurringCharge struct {
 	Frequency string  `xml:"frequency"`
 	Amount    float32 `xml:"amount"`
 }
 
-// functions
-// DescribeReservedInstances
+// DescribeReservedInstances returns the reserved instances with the given
+// ids, or all of them, as limited by filter.
 //
-// See
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeReservedInstances.html for more details.
 func (ec2 *EC2) DescribeReservedInstances(instIds []string, filter *Filter) (resp *DescribeReservedInstancesResponse, err error) {
 	params := makeParams("DescribeReservedInstances")
+	params["Version"] = latestVersion
 
 	for i, id := range instIds {
 		params["ReservedInstancesId."+strconv.Itoa(i+1)] = id
@@ -992,6 +1592,203 @@ func (ec2 *EC2) DescribeReservedInstances(instIds []string, filter *Filter) (res
 	return resp, nil
 }
 
+// ReservedInstancesOffering describes reserved instances on sale, by AWS
+// or on the Reserved Instance Marketplace if Marketplace is set, in which
+// case PricingDetails tells how many are on sale at each price.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ReservedInstancesOffering.html for more details.
+type ReservedInstancesOffering struct {
+	Id                 string            `xml:"reservedInstancesOfferingId"`
+	InstanceType       string            `xml:"instanceType"`
+	AvailabilityZone   string            `xml:"availabilityZone"`
+	Duration           uint64            `xml:"duration"`
+	FixedPrice         float32           `xml:"fixedPrice"`
+	UsagePrice         float32           `xml:"usagePrice"`
+	ProductDescription string            `xml:"productDescription"`
+	InstanceTenancy    string            `xml:"instanceTenancy"`
+	CurrencyCode       string            `xml:"currencyCode"`
+	OfferingType       string            `xml:"offeringType"`
+	OfferingClass      string            `xml:"offeringClass"`
+	Scope              string            `xml:"scope"`
+	Marketplace        bool              `xml:"marketplace"`
+	RecurringCharges   []RecurringCharge `xml:"recurringCharges>item"`
+	PricingDetails     []PricingDetail   `xml:"pricingDetailsSet>item"`
+}
+
+// PricingDetail is the number of reserved instances on sale at a price on
+// the Reserved Instance Marketplace.
+type PricingDetail struct {
+	Price float32 `xml:"price"`
+	Count int     `xml:"count"`
+}
+
+// DescribeReservedInstancesOfferingsOptions encapsulates the query
+// parameters of a page of DescribeReservedInstancesOfferings results.
+// The fields which aren't set don't limit the offerings returned.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeReservedInstancesOfferings.html for more details.
+type DescribeReservedInstancesOfferingsOptions struct {
+	OfferingIds        []string
+	InstanceType       string
+	AvailabilityZone   string
+	OfferingClass      string // standard or convertible
+	OfferingType       string // e.g. No Upfront, Partial Upfront or All Upfront
+	ProductDescription string
+	InstanceTenancy    string
+	ExcludeMarketplace bool   // Only return the offerings sold by AWS
+	MinDuration        uint64 // In seconds
+	MaxDuration        uint64 // In seconds
+	MaxResults         int    // Maximum number of offerings to return, at most 100. Zero returns 100 of them.
+	NextToken          string // The token for the next page of results, from a prior call.
+}
+
+// ReservedInstancesOfferingsResp represents a response from a
+// DescribeReservedInstancesOfferings request.
+type ReservedInstancesOfferingsResp struct {
+	RequestId string                      `xml:"requestId"`
+	Offerings []ReservedInstancesOffering `xml:"reservedInstancesOfferingsSet>item"`
+	NextToken string                      `xml:"nextToken"`
+}
+
+// DescribeReservedInstancesOfferings returns a page of the reserved
+// instances offerings matching options, as limited by filter, continued by
+// the call with the NextToken of the response, if any.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeReservedInstancesOfferings.html for more details.
+func (ec2 *EC2) DescribeReservedInstancesOfferings(options *DescribeReservedInstancesOfferingsOptions, filter *Filter) (resp *ReservedInstancesOfferingsResp, err error) {
+	params := makeParams("DescribeReservedInstancesOfferings")
+	params["Version"] = latestVersion
+	addParamsList(params, "ReservedInstancesOfferingId", options.OfferingIds)
+	if options.InstanceType != "" {
+		params["InstanceType"] = options.InstanceType
+	}
+	if options.AvailabilityZone != "" {
+		params["AvailabilityZone"] = options.AvailabilityZone
+	}
+	if options.OfferingClass != "" {
+		params["OfferingClass"] = options.OfferingClass
+	}
+	if options.OfferingType != "" {
+		params["OfferingType"] = options.OfferingType
+	}
+	if options.ProductDescription != "" {
+		params["ProductDescription"] = options.ProductDescription
+	}
+	if options.InstanceTenancy != "" {
+		params["InstanceTenancy"] = options.InstanceTenancy
+	}
+	if options.ExcludeMarketplace {
+		params["IncludeMarketplace"] = "false"
+	}
+	if options.MinDuration != 0 {
+		params["MinDuration"] = strconv.FormatUint(options.MinDuration, 10)
+	}
+	if options.MaxDuration != 0 {
+		params["MaxDuration"] = strconv.FormatUint(options.MaxDuration, 10)
+	}
+	if options.MaxResults != 0 {
+		params["MaxResults"] = strconv.Itoa(options.MaxResults)
+	}
+	if options.NextToken != "" {
+		params["NextToken"] = options.NextToken
+	}
+	filter.addParams(params)
+	resp = &ReservedInstancesOfferingsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ReservedInstancesOfferings returns up to limit of the reserved instances
+// offerings matching options and filter, following the pages of results
+// from options.NextToken. It stops requesting pages once it has limit
+// offerings, as the offerings of all the instance types and zones are
+// numerous.
+func (ec2 *EC2) ReservedInstancesOfferings(options *DescribeReservedInstancesOfferingsOptions, filter *Filter, limit int) ([]ReservedInstancesOffering, error) {
+	var offerings []ReservedInstancesOffering
+	pageOptions := *options
+	for len(offerings) < limit {
+		if pageOptions.MaxResults == 0 || pageOptions.MaxResults > limit-len(offerings) {
+			pageOptions.MaxResults = limit - len(offerings)
+		}
+		resp, err := ec2.DescribeReservedInstancesOfferings(&pageOptions, filter)
+		if err != nil {
+			return nil, err
+		}
+		offerings = append(offerings, resp.Offerings...)
+		if resp.NextToken == "" {
+			break
+		}
+		pageOptions.NextToken = resp.NextToken
+	}
+	if len(offerings) > limit {
+		offerings = offerings[:limit]
+	}
+	return offerings, nil
+}
+
+// ReservedInstancesConfiguration is a configuration reserved instances are
+// modified to. Scope is Availability Zone or Region, in which case
+// AvailabilityZone is empty. The fields which aren't set are left as they
+// were.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ReservedInstancesConfiguration.html for more details.
+type ReservedInstancesConfiguration struct {
+	AvailabilityZone string
+	InstanceCount    int
+	InstanceType     string
+	Platform         string // EC2-Classic or EC2-VPC
+	Scope            string
+}
+
+// ModifyReservedInstancesResp represents a response from a
+// ModifyReservedInstances request.
+type ModifyReservedInstancesResp struct {
+	RequestId      string `xml:"requestId"`
+	ModificationId string `xml:"reservedInstancesModificationId"`
+}
+
+// ModifyReservedInstances splits or moves the reserved instances with the
+// given ids, e.g. to other Availability Zones, into configs, which must
+// cover the instances modified. The modification is idempotent for a
+// clientToken, which is optional.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ModifyReservedInstances.html for more details.
+func (ec2 *EC2) ModifyReservedInstances(clientToken string, ids []string, configs []ReservedInstancesConfiguration) (resp *ModifyReservedInstancesResp, err error) {
+	params := makeParams("ModifyReservedInstances")
+	params["Version"] = latestVersion
+	if clientToken != "" {
+		params["ClientToken"] = clientToken
+	}
+	addParamsList(params, "ReservedInstancesId", ids)
+	for i, config := range configs {
+		prefix := "ReservedInstancesConfigurationSetItemType." + strconv.Itoa(i+1) + "."
+		if config.AvailabilityZone != "" {
+			params[prefix+"AvailabilityZone"] = config.AvailabilityZone
+		}
+		if config.InstanceCount != 0 {
+			params[prefix+"InstanceCount"] = strconv.Itoa(config.InstanceCount)
+		}
+		if config.InstanceType != "" {
+			params[prefix+"InstanceType"] = config.InstanceType
+		}
+		if config.Platform != "" {
+			params[prefix+"Platform"] = config.Platform
+		}
+		if config.Scope != "" {
+			params[prefix+"Scope"] = config.Scope
+		}
+	}
+	resp = &ModifyReservedInstancesResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
 // ----------------------------------------------------------------------------
 // Image and snapshot management functions and types.
 
@@ -1075,9 +1872,33 @@ type BlockDeviceMapping struct {
 	VolumeSize          int64  `xml:"ebs>volumeSize"`
 	DeleteOnTermination bool   `xml:"ebs>deleteOnTermination"`
 	NoDevice            bool   `xml:"noDevice"`
+	Encrypted           bool   `xml:"ebs>encrypted"`
+	KmsKeyId            string `xml:"ebs>kmsKeyId"`
 
 	// The number of I/O operations per second (IOPS) that the volume supports.
 	IOPS int64 `xml:"ebs>iops"`
+
+	// The volume attached to an instance, as returned by DescribeInstances.
+	// Only VolumeId is sent, by ModifyInstanceAttribute.
+	VolumeId   string `xml:"ebs>volumeId"`
+	Status     string `xml:"ebs>status"`
+	AttachTime string `xml:"ebs>attachTime"`
+}
+
+// UnmarshalXML decodes a mapping, which is NoDevice if it has a noDevice
+// element, as EC2 sends it empty.
+func (m *BlockDeviceMapping) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type mapping BlockDeviceMapping
+	var doc struct {
+		mapping
+		NoDevice *string `xml:"noDevice"`
+	}
+	if err := d.DecodeElement(&doc, &start); err != nil {
+		return err
+	}
+	*m = BlockDeviceMapping(doc.mapping)
+	m.NoDevice = doc.NoDevice != nil
+	return nil
 }
 
 // Image represents details about an image.
@@ -1096,7 +1917,8 @@ type Image struct {
 	ProductCodes       []string             `xml:"productCode>item>productCode"`
 	KernelId           string               `xml:"kernelId"`
 	RamdiskId          string               `xml:"ramdiskId"`
-	StateReason        string               `xml:"stateReason"`
+	StateReasonCode    string               `xml:"stateReason>code"`
+	StateReason        string               `xml:"stateReason>message"`
 	OwnerId            string               `xml:"imageOwnerId"`
 	OwnerAlias         string               `xml:"imageOwnerAlias"`
 	RootDeviceType     string               `xml:"rootDeviceType"`
@@ -1126,6 +1948,8 @@ type CopyImage struct {
 	Name          string
 	Description   string
 	ClientToken   string
+	Encrypted     bool
+	KmsKeyId      string
 }
 
 // Response to a CopyImage request.
@@ -1372,6 +2196,14 @@ func (ec2 *EC2) CopyImage(options *CopyImage) (resp *CopyImageResp, err error) {
 		params["ClientToken"] = options.ClientToken
 	}
 
+	if options.Encrypted {
+		params["Encrypted"] = "true"
+	}
+
+	if options.KmsKeyId != "" {
+		params["KmsKeyId"] = options.KmsKeyId
+	}
+
 	resp = &CopyImageResp{}
 	err = ec2.query(params, resp)
 	if err != nil {
@@ -1381,6 +2213,61 @@ func (ec2 *EC2) CopyImage(options *CopyImage) (resp *CopyImageResp, err error) {
 	return
 }
 
+// ImageWaitPolicy is the backoff between the polls of
+// WaitUntilImageAvailable, which gives up after MaxAttempts polls.
+var ImageWaitPolicy = aws.RetryPolicy{
+	MaxAttempts: 80,
+	BaseDelay:   15 * time.Second,
+	MaxDelay:    time.Minute,
+	Jitter:      0.1,
+}
+
+// ImageError is returned by WaitUntilImageAvailable when the image
+// failed, or didn't become available in time.
+type ImageError struct {
+	Image Image
+}
+
+func (e *ImageError) Error() string {
+	if e.Image.StateReason != "" {
+		return fmt.Sprintf("image %s is %s: %s", e.Image.Id, e.Image.State, e.Image.StateReason)
+	}
+	return fmt.Sprintf("image %s is %s", e.Image.Id, e.Image.State)
+}
+
+// WaitUntilImageAvailable polls the image with the given id, backing off
+// as given by ImageWaitPolicy, until it is available, and returns it. An
+// image that isn't found yet, as happens right after CreateImage or
+// CopyImage, is polled again. It fails with an *ImageError if the image
+// fails or isn't available after the last poll.
+func (ec2 *EC2) WaitUntilImageAvailable(id string) (*Image, error) {
+	policy := ImageWaitPolicy
+	var image Image
+	for attempt := 0; ; attempt++ {
+		resp, err := ec2.Images([]string{id}, nil)
+		if err != nil && !aws.IsCode(err, "InvalidAMIID.NotFound") {
+			return nil, err
+		}
+		if err == nil && len(resp.Images) > 0 {
+			image = resp.Images[0]
+			switch image.State {
+			case "available":
+				return &image, nil
+			case "failed", "error", "invalid", "deregistered":
+				return nil, &ImageError{image}
+			}
+		}
+		if attempt+1 >= policy.MaxAttempts {
+			break
+		}
+		policy.Backoff(attempt)
+	}
+	if image.Id == "" {
+		image.Id = id
+	}
+	return nil, &ImageError{image}
+}
+
 // Response to a CreateSnapshot request.
 //
 // See http://goo.gl/ttcda for more details.
@@ -1394,6 +2281,7 @@ type CreateSnapshotResp struct {
 // See http://goo.gl/ttcda for more details.
 func (ec2 *EC2) CreateSnapshot(volumeId, description string) (resp *CreateSnapshotResp, err error) {
 	params := makeParams("CreateSnapshot")
+	params["Version"] = latestVersion
 	params["VolumeId"] = volumeId
 	params["Description"] = description
 
@@ -1430,12 +2318,76 @@ func (ec2 *EC2) DeleteSnapshots(ids []string) (resp *SimpleResp, err error) {
 	return
 }
 
+// DeleteSnapshot deletes the volume snapshot with the given id. A snapshot
+// used by a registered image can't be deleted, which fails with an *Error
+// with the code InvalidSnapshot.InUse.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteSnapshot.html for more details.
+func (ec2 *EC2) DeleteSnapshot(id string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteSnapshot")
+	params["SnapshotId"] = id
+
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// The CopySnapshot request parameters. The snapshot is copied to the
+// region of the EC2 from SourceRegion, and encrypted with the KMS key
+// KmsKeyId, or the default one if empty, if Encrypted is set.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CopySnapshot.html for more details.
+type CopySnapshot struct {
+	SourceRegion     string
+	SourceSnapshotId string
+	Description      string
+	Encrypted        bool
+	KmsKeyId         string
+}
+
+// Response to a CopySnapshot request.
+type CopySnapshotResp struct {
+	RequestId  string `xml:"requestId"`
+	SnapshotId string `xml:"snapshotId"`
+}
+
+// CopySnapshot copies a completed snapshot, which may be in another
+// region.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CopySnapshot.html for more details.
+func (ec2 *EC2) CopySnapshot(options *CopySnapshot) (resp *CopySnapshotResp, err error) {
+	params := makeParams("CopySnapshot")
+	params["Version"] = latestVersion
+	params["SourceRegion"] = options.SourceRegion
+	params["SourceSnapshotId"] = options.SourceSnapshotId
+	if options.Description != "" {
+		params["Description"] = options.Description
+	}
+	if options.Encrypted {
+		params["Encrypted"] = "true"
+	}
+	if options.KmsKeyId != "" {
+		params["KmsKeyId"] = options.KmsKeyId
+	}
+
+	resp = &CopySnapshotResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
 // Response to a DescribeSnapshots request.
 //
 // See http://goo.gl/nClDT for more details.
 type SnapshotsResp struct {
 	RequestId string     `xml:"requestId"`
 	Snapshots []Snapshot `xml:"snapshotSet>item"`
+	NextToken string     `xml:"nextToken"`
 }
 
 // Snapshot represents details about a volume snapshot.
@@ -1452,6 +2404,10 @@ type Snapshot struct {
 	OwnerId     string `xml:"ownerId"`
 	OwnerAlias  string `xml:"ownerAlias"`
 	Tags        []Tag  `xml:"tagSet>item"`
+
+	Encrypted    bool   `xml:"encrypted"`
+	KmsKeyId     string `xml:"kmsKeyId"`     // The ARN of the KMS key the snapshot is encrypted with
+	StateMessage string `xml:"stateMessage"` // Why the snapshot failed, if Status is error
 }
 
 // Snapshots returns details about volume snapshots available to the user.
@@ -1460,6 +2416,7 @@ type Snapshot struct {
 // See http://goo.gl/ogJL4 for more details.
 func (ec2 *EC2) Snapshots(ids []string, filter *Filter) (resp *SnapshotsResp, err error) {
 	params := makeParams("DescribeSnapshots")
+	params["Version"] = latestVersion
 	for i, id := range ids {
 		params["SnapshotId."+strconv.Itoa(i+1)] = id
 	}
@@ -1473,6 +2430,102 @@ func (ec2 *EC2) Snapshots(ids []string, filter *Filter) (resp *SnapshotsResp, er
 	return
 }
 
+// DescribeSnapshotsOptions encapsulates the options of a
+// DescribeSnapshots request. All are optional. OwnerIds holds account IDs,
+// "self" or "amazon", and RestorableBy the account IDs allowed to create
+// volumes from the snapshots, or "all". A response with a NextToken is
+// continued by the request with that NextToken.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSnapshots.html for more details.
+type DescribeSnapshotsOptions struct {
+	SnapshotIds  []string
+	OwnerIds     []string
+	RestorableBy []string
+	MaxResults   int
+	NextToken    string
+}
+
+// DescribeSnapshots returns details about volume snapshots as Snapshots
+// does, with more options. Snapshots may be filtered by their tags with
+// the "tag:<key>" filters.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSnapshots.html for more details.
+func (ec2 *EC2) DescribeSnapshots(options *DescribeSnapshotsOptions, filter *Filter) (resp *SnapshotsResp, err error) {
+	params := makeParams("DescribeSnapshots")
+	params["Version"] = latestVersion
+	addParamsList(params, "SnapshotId", options.SnapshotIds)
+	addParamsList(params, "Owner", options.OwnerIds)
+	addParamsList(params, "RestorableBy", options.RestorableBy)
+	if options.MaxResults != 0 {
+		params["MaxResults"] = strconv.Itoa(options.MaxResults)
+	}
+	if options.NextToken != "" {
+		params["NextToken"] = options.NextToken
+	}
+	filter.addParams(params)
+
+	resp = &SnapshotsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// SnapshotWaitPolicy is the backoff between the polls of
+// WaitUntilSnapshotCompleted, which gives up after MaxAttempts polls.
+var SnapshotWaitPolicy = aws.RetryPolicy{
+	MaxAttempts: 100,
+	BaseDelay:   5 * time.Second,
+	MaxDelay:    time.Minute,
+	Jitter:      0.1,
+}
+
+// SnapshotError is returned by WaitUntilSnapshotCompleted when the
+// snapshot failed, or didn't complete in time.
+type SnapshotError struct {
+	Snapshot Snapshot
+}
+
+func (e *SnapshotError) Error() string {
+	if e.Snapshot.StateMessage != "" {
+		return fmt.Sprintf("snapshot %s is %s: %s", e.Snapshot.Id, e.Snapshot.Status, e.Snapshot.StateMessage)
+	}
+	return fmt.Sprintf("snapshot %s is %s (%s)", e.Snapshot.Id, e.Snapshot.Status, e.Snapshot.Progress)
+}
+
+// WaitUntilSnapshotCompleted polls the snapshot with the given id, backing
+// off as given by SnapshotWaitPolicy, until it is completed, and returns
+// it. It fails with a *SnapshotError if the snapshot fails or isn't
+// completed after the last poll.
+func (ec2 *EC2) WaitUntilSnapshotCompleted(id string) (*Snapshot, error) {
+	policy := SnapshotWaitPolicy
+	var snapshot Snapshot
+	for attempt := 0; ; attempt++ {
+		resp, err := ec2.Snapshots([]string{id}, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Snapshots) > 0 {
+			snapshot = resp.Snapshots[0]
+			switch snapshot.Status {
+			case "completed":
+				return &snapshot, nil
+			case "error":
+				return nil, &SnapshotError{snapshot}
+			}
+		}
+		if attempt+1 >= policy.MaxAttempts {
+			break
+		}
+		policy.Backoff(attempt)
+	}
+	if snapshot.Id == "" {
+		snapshot.Id = id
+	}
+	return nil, &SnapshotError{snapshot}
+}
+
 // ----------------------------------------------------------------------------
 // Volume management
 
@@ -1485,6 +2538,12 @@ type CreateVolume struct {
 	SnapshotId string
 	VolumeType string
 	IOPS       int64
+
+	// Encrypted creates an encrypted volume, with the KMS key KmsKeyId or
+	// the default one if empty. Volumes created from encrypted snapshots
+	// are always encrypted.
+	Encrypted bool
+	KmsKeyId  string
 }
 
 // Response to an AttachVolume request
@@ -1508,6 +2567,8 @@ type CreateVolumeResp struct {
 	CreateTime string `xml:"createTime"`
 	VolumeType string `xml:"volumeType"`
 	IOPS       int64  `xml:"iops"`
+	Encrypted  bool   `xml:"encrypted"`
+	KmsKeyId   string `xml:"kmsKeyId"`
 }
 
 // Volume is a single volume.
@@ -1521,13 +2582,18 @@ type Volume struct {
 	VolumeType  string             `xml:"volumeType"`
 	IOPS        int64              `xml:"iops"`
 	Tags        []Tag              `xml:"tagSet>item"`
+	CreateTime  string             `xml:"createTime"`
+	Encrypted   bool               `xml:"encrypted"`
+	KmsKeyId    string             `xml:"kmsKeyId"`
 }
 
 type VolumeAttachment struct {
-	VolumeId   string `xml:"volumeId"`
-	InstanceId string `xml:"instanceId"`
-	Device     string `xml:"device"`
-	Status     string `xml:"status"`
+	VolumeId            string `xml:"volumeId"`
+	InstanceId          string `xml:"instanceId"`
+	Device              string `xml:"device"`
+	Status              string `xml:"status"` // Valid values: attaching | attached | detaching | detached
+	AttachTime          string `xml:"attachTime"`
+	DeleteOnTermination bool   `xml:"deleteOnTermination"`
 }
 
 // Response to a DescribeVolumes request
@@ -1555,6 +2621,7 @@ func (ec2 *EC2) AttachVolume(volumeId string, instanceId string, device string)
 // Create a new volume.
 func (ec2 *EC2) CreateVolume(options *CreateVolume) (resp *CreateVolumeResp, err error) {
 	params := makeParams("CreateVolume")
+	params["Version"] = latestVersion
 	params["AvailabilityZone"] = options.AvailZone
 	if options.Size > 0 {
 		params["Size"] = strconv.FormatInt(options.Size, 10)
@@ -1572,6 +2639,14 @@ func (ec2 *EC2) CreateVolume(options *CreateVolume) (resp *CreateVolumeResp, err
 		params["Iops"] = strconv.FormatInt(options.IOPS, 10)
 	}
 
+	if options.Encrypted {
+		params["Encrypted"] = "true"
+	}
+
+	if options.KmsKeyId != "" {
+		params["KmsKeyId"] = options.KmsKeyId
+	}
+
 	resp = &CreateVolumeResp{}
 	err = ec2.query(params, resp)
 	if err != nil {
@@ -1609,6 +2684,7 @@ func (ec2 *EC2) DetachVolume(id string) (resp *SimpleResp, err error) {
 // Finds or lists all volumes.
 func (ec2 *EC2) Volumes(volIds []string, filter *Filter) (resp *VolumesResp, err error) {
 	params := makeParams("DescribeVolumes")
+	params["Version"] = latestVersion
 	addParamsList(params, "VolumeId", volIds)
 	filter.addParams(params)
 	resp = &VolumesResp{}
@@ -1680,19 +2756,102 @@ type SecurityGroupInfo struct {
 //
 // See http://goo.gl/4oTxv for more details.
 type IPPerm struct {
-	Protocol     string              `xml:"ipProtocol"`
-	FromPort     int                 `xml:"fromPort"`
-	ToPort       int                 `xml:"toPort"`
-	SourceIPs    []string            `xml:"ipRanges>item>cidrIp"`
-	SourceGroups []UserSecurityGroup `xml:"groups>item"`
+	Protocol      string              `xml:"ipProtocol"`
+	FromPort      int                 `xml:"fromPort"`
+	ToPort        int                 `xml:"toPort"`
+	SourceIPs     []string            `xml:"ipRanges>item>cidrIp"`
+	SourceIPv6s   []string            `xml:"ipv6Ranges>item>cidrIpv6"`
+	PrefixListIds []string            `xml:"prefixListIds>item>prefixListId"`
+	SourceGroups  []UserSecurityGroup `xml:"groups>item"`
+
+	// Descriptions holds the descriptions of the rules for SourceIPs,
+	// SourceIPv6s and PrefixListIds, by CIDR block or prefix list ID.
+	Descriptions map[string]string `xml:"-"`
+}
+
+// ipPermDocument is the form of an IPPerm in responses, which have a
+// description with each range and prefix list.
+type ipPermDocument struct {
+	Protocol   string              `xml:"ipProtocol"`
+	FromPort   int                 `xml:"fromPort"`
+	ToPort     int                 `xml:"toPort"`
+	IPRanges   []ipPermSource      `xml:"ipRanges>item"`
+	IPv6Ranges []ipPermSource      `xml:"ipv6Ranges>item"`
+	PrefixList []ipPermSource      `xml:"prefixListIds>item"`
+	Groups     []UserSecurityGroup `xml:"groups>item"`
+}
+
+type ipPermSource struct {
+	CidrIp       string `xml:"cidrIp,omitempty"`
+	CidrIpv6     string `xml:"cidrIpv6,omitempty"`
+	PrefixListId string `xml:"prefixListId,omitempty"`
+	Description  string `xml:"description,omitempty"`
+}
+
+// MarshalXML encodes perm as in responses, for the test server.
+func (perm IPPerm) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	doc := ipPermDocument{
+		Protocol: perm.Protocol,
+		FromPort: perm.FromPort,
+		ToPort:   perm.ToPort,
+		Groups:   perm.SourceGroups,
+	}
+	for _, ip := range perm.SourceIPs {
+		doc.IPRanges = append(doc.IPRanges, ipPermSource{CidrIp: ip, Description: perm.Descriptions[ip]})
+	}
+	for _, ip := range perm.SourceIPv6s {
+		doc.IPv6Ranges = append(doc.IPv6Ranges, ipPermSource{CidrIpv6: ip, Description: perm.Descriptions[ip]})
+	}
+	for _, id := range perm.PrefixListIds {
+		doc.PrefixList = append(doc.PrefixList, ipPermSource{PrefixListId: id, Description: perm.Descriptions[id]})
+	}
+	return e.EncodeElement(doc, start)
+}
+
+// UnmarshalXML decodes perm from a response, with the descriptions of its
+// rules.
+func (perm *IPPerm) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var doc ipPermDocument
+	if err := d.DecodeElement(&doc, &start); err != nil {
+		return err
+	}
+	*perm = IPPerm{
+		Protocol:     doc.Protocol,
+		FromPort:     doc.FromPort,
+		ToPort:       doc.ToPort,
+		SourceGroups: doc.Groups,
+	}
+	describe := func(source, description string) {
+		if description == "" {
+			return
+		}
+		if perm.Descriptions == nil {
+			perm.Descriptions = make(map[string]string)
+		}
+		perm.Descriptions[source] = description
+	}
+	for _, r := range doc.IPRanges {
+		perm.SourceIPs = append(perm.SourceIPs, r.CidrIp)
+		describe(r.CidrIp, r.Description)
+	}
+	for _, r := range doc.IPv6Ranges {
+		perm.SourceIPv6s = append(perm.SourceIPv6s, r.CidrIpv6)
+		describe(r.CidrIpv6, r.Description)
+	}
+	for _, r := range doc.PrefixList {
+		perm.PrefixListIds = append(perm.PrefixListIds, r.PrefixListId)
+		describe(r.PrefixListId, r.Description)
+	}
+	return nil
 }
 
 // UserSecurityGroup holds a security group and the owner
 // of that group.
 type UserSecurityGroup struct {
-	Id      string `xml:"groupId"`
-	Name    string `xml:"groupName"`
-	OwnerId string `xml:"userId"`
+	Id          string `xml:"groupId"`
+	Name        string `xml:"groupName"`
+	OwnerId     string `xml:"userId"`
+	Description string `xml:"description,omitempty"` // The description of the rule for the group.
 }
 
 // SecurityGroup represents an EC2 security group.
@@ -1732,6 +2891,7 @@ func SecurityGroupIds(ids ...string) []SecurityGroup {
 // See http://goo.gl/k12Uy for more details.
 func (ec2 *EC2) SecurityGroups(groups []SecurityGroup, filter *Filter) (resp *SecurityGroupsResp, err error) {
 	params := makeParams("DescribeSecurityGroups")
+	params["Version"] = latestVersion
 	i, j := 1, 1
 	for _, g := range groups {
 		if g.Id != "" {
@@ -1779,7 +2939,8 @@ func (ec2 *EC2) AuthorizeSecurityGroup(group SecurityGroup, perms []IPPerm) (res
 	return ec2.authOrRevoke("AuthorizeSecurityGroupIngress", group, perms)
 }
 
-// RevokeSecurityGroup revokes permissions from a group.
+// RevokeSecurityGroup revokes permissions from a group. It fails with a
+// *PermissionNotFoundError if the group lacks some of them.
 //
 // See http://goo.gl/ZgdxA for more details.
 func (ec2 *EC2) RevokeSecurityGroup(group SecurityGroup, perms []IPPerm) (resp *SimpleResp, err error) {
@@ -1794,15 +2955,35 @@ func (ec2 *EC2) AuthorizeSecurityGroupEgress(group SecurityGroup, perms []IPPerm
 	return ec2.authOrRevoke("AuthorizeSecurityGroupEgress", group, perms)
 }
 
-// RevokeSecurityGroupEgress revokes egress permissions from a group
+// RevokeSecurityGroupEgress revokes egress permissions from a group. It
+// fails with a *PermissionNotFoundError if the group lacks some of them.
 //
 // see http://goo.gl/Zv4wh8
 func (ec2 *EC2) RevokeSecurityGroupEgress(group SecurityGroup, perms []IPPerm) (resp *SimpleResp, err error) {
 	return ec2.authOrRevoke("RevokeSecurityGroupEgress", group, perms)
 }
 
+// PermissionNotFoundError is the error of RevokeSecurityGroup and
+// RevokeSecurityGroupEgress when some of the permissions to revoke
+// aren't in Group, which EC2 reports with the InvalidPermission.NotFound
+// code.
+type PermissionNotFoundError struct {
+	Group SecurityGroup
+	Err   *Error
+}
+
+func (e *PermissionNotFoundError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error returned by EC2.
+func (e *PermissionNotFoundError) Unwrap() error {
+	return e.Err
+}
+
 func (ec2 *EC2) authOrRevoke(op string, group SecurityGroup, perms []IPPerm) (resp *SimpleResp, err error) {
 	params := makeParams(op)
+	params["Version"] = latestVersion
 	if group.Id != "" {
 		params["GroupId"] = group.Id
 	} else {
@@ -1814,14 +2995,26 @@ func (ec2 *EC2) authOrRevoke(op string, group SecurityGroup, perms []IPPerm) (re
 		params[prefix+".IpProtocol"] = perm.Protocol
 		params[prefix+".FromPort"] = strconv.Itoa(perm.FromPort)
 		params[prefix+".ToPort"] = strconv.Itoa(perm.ToPort)
-		for j, ip := range perm.SourceIPs {
-			params[prefix+".IpRanges."+strconv.Itoa(j+1)+".CidrIp"] = ip
+		sources := func(label, field string, values []string) {
+			for j, v := range values {
+				subprefix := prefix + "." + label + "." + strconv.Itoa(j+1)
+				params[subprefix+"."+field] = v
+				if d := perm.Descriptions[v]; d != "" {
+					params[subprefix+".Description"] = d
+				}
+			}
 		}
+		sources("IpRanges", "CidrIp", perm.SourceIPs)
+		sources("Ipv6Ranges", "CidrIpv6", perm.SourceIPv6s)
+		sources("PrefixListIds", "PrefixListId", perm.PrefixListIds)
 		for j, g := range perm.SourceGroups {
 			subprefix := prefix + ".Groups." + strconv.Itoa(j+1)
 			if g.OwnerId != "" {
 				params[subprefix+".UserId"] = g.OwnerId
 			}
+			if g.Description != "" {
+				params[subprefix+".Description"] = g.Description
+			}
 			if g.Id != "" {
 				params[subprefix+".GroupId"] = g.Id
 			} else {
@@ -1832,6 +3025,9 @@ func (ec2 *EC2) authOrRevoke(op string, group SecurityGroup, perms []IPPerm) (re
 
 	resp = &SimpleResp{}
 	err = ec2.query(params, resp)
+	if e, ok := aws.AsError(err); ok && e.Code == "InvalidPermission.NotFound" {
+		return nil, &PermissionNotFoundError{Group: group, Err: e}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1860,6 +3056,7 @@ type Address struct {
 	NetworkInterfaceId      string `xml:"networkInterfaceId"`
 	NetworkInterfaceOwnerId string `xml:"networkInterfaceOwnerId"`
 	PrivateIpAddress        string `xml:"privateIpAddress"`
+	Tags                    []Tag  `xml:"tagSet>item"`
 }
 
 // DescribeAddresses returns details about one or more
@@ -1880,7 +3077,9 @@ func (ec2 *EC2) DescribeAddresses(publicIps []string, allocationIds []string, fi
 	return
 }
 
-// AllocateAddressOptions are request parameters for allocating an Elastic IP Address
+// AllocateAddressOptions are request parameters for allocating an Elastic IP Address.
+// Domain is "vpc" for addresses used in a VPC, which are then referred to
+// by their allocation and association ids.
 //
 // See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/ApiReference-query-AllocateAddress.html
 type AllocateAddressOptions struct {
@@ -1904,7 +3103,9 @@ type AllocateAddressResp struct {
 // See http://goo.gl/aLPmbm for more details
 func (ec2 *EC2) AllocateAddress(options *AllocateAddressOptions) (resp *AllocateAddressResp, err error) {
 	params := makeParams("AllocateAddress")
-	params["Domain"] = options.Domain
+	if options.Domain != "" {
+		params["Domain"] = options.Domain
+	}
 	resp = &AllocateAddressResp{}
 	err = ec2.query(params, resp)
 	if err != nil {
@@ -1966,12 +3167,17 @@ type AssociateAddressResp struct {
 	AssociationId string `xml:"associationId"`
 }
 
-// Associate an Elastic ip address to an instance id or a network interface
+// Associate an Elastic ip address to an instance id or a network interface.
+// In a VPC the address is given by AllocationId. Associating an address
+// which is already associated elsewhere fails with a
+// Resource.AlreadyAssociated error unless AllowReassociation is set.
 //
 // See http://goo.gl/hhj4z7 for more details
 func (ec2 *EC2) AssociateAddress(options *AssociateAddressOptions) (resp *AssociateAddressResp, err error) {
 	params := makeParams("AssociateAddress")
-	params["InstanceId"] = options.InstanceId
+	if options.InstanceId != "" {
+		params["InstanceId"] = options.InstanceId
+	}
 	if options.PublicIp != "" {
 		params["PublicIp"] = options.PublicIp
 	}
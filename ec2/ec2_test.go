@@ -1,7 +1,16 @@
 package ec2_test
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/goamz/goamz/aws"
 	"github.com/goamz/goamz/ec2"
@@ -58,6 +67,9 @@ func (s *S) TestRunInstancesErrorDump(c *C) {
 	c.Assert(ec2err.Code, Equals, "UnsupportedOperation")
 	c.Assert(ec2err.Message, Matches, msg)
 	c.Assert(ec2err.RequestId, Equals, "0503f4e9-bbd6-483c-b54f-c4ae9f3b30f4")
+	c.Assert(ec2err.Retryable, Equals, false)
+	c.Assert(aws.IsCode(err, "UnsupportedOperation"), Equals, true)
+	c.Assert(aws.RequestID(err), Equals, "0503f4e9-bbd6-483c-b54f-c4ae9f3b30f4")
 }
 
 func (s *S) TestRunInstancesErrorWithoutXML(c *C) {
@@ -77,6 +89,7 @@ func (s *S) TestRunInstancesErrorWithoutXML(c *C) {
 	c.Assert(ec2err.Code, Equals, "")
 	c.Assert(ec2err.Message, Equals, "500 Internal Server Error")
 	c.Assert(ec2err.RequestId, Equals, "")
+	c.Assert(ec2err.Retryable, Equals, true)
 }
 
 func (s *S) TestRunInstancesExample(c *C) {
@@ -97,6 +110,8 @@ func (s *S) TestRunInstancesExample(c *C) {
 		DisableAPITermination: true,
 		ShutdownBehavior:      "terminate",
 		PrivateIPAddress:      "10.0.0.25",
+		IamInstanceProfile:    ec2.IamInstanceProfile{Name: "web-server"},
+		EbsOptimized:          true,
 		BlockDevices: []ec2.BlockDeviceMapping{
 			{DeviceName: "/dev/sdb", VirtualName: "ephemeral0"},
 			{DeviceName: "/dev/sdc", SnapshotId: "snap-a08912c9", DeleteOnTermination: true},
@@ -125,6 +140,9 @@ func (s *S) TestRunInstancesExample(c *C) {
 	c.Assert(req.Form["DisableApiTermination"], DeepEquals, []string{"true"})
 	c.Assert(req.Form["InstanceInitiatedShutdownBehavior"], DeepEquals, []string{"terminate"})
 	c.Assert(req.Form["PrivateIpAddress"], DeepEquals, []string{"10.0.0.25"})
+	c.Assert(req.Form["IamInstanceProfile.Name"], DeepEquals, []string{"web-server"})
+	c.Assert(req.Form["IamInstanceProfile.Arn"], IsNil)
+	c.Assert(req.Form["EbsOptimized"], DeepEquals, []string{"true"})
 	c.Assert(req.Form["BlockDeviceMapping.1.DeviceName"], DeepEquals, []string{"/dev/sdb"})
 	c.Assert(req.Form["BlockDeviceMapping.1.VirtualName"], DeepEquals, []string{"ephemeral0"})
 	c.Assert(req.Form["BlockDeviceMapping.2.Ebs.SnapshotId"], DeepEquals, []string{"snap-a08912c9"})
@@ -269,6 +287,72 @@ func (s *S) TestDescribeInstancesExample2(c *C) {
 	c.Assert(r0t1.Value, Equals, "Production")
 }
 
+// instancesPage returns a page of DescribeInstances results with a
+// reservation per owner holding the given instances, continued by next.
+func instancesPage(next string, owners map[string][]string) string {
+	var buf bytes.Buffer
+	buf.WriteString(`<DescribeInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><reservationSet>`)
+	var names []string
+	for owner := range owners {
+		names = append(names, owner)
+	}
+	sort.Strings(names)
+	for _, owner := range names {
+		fmt.Fprintf(&buf, "<item><reservationId>r-%s</reservationId><ownerId>%s</ownerId><instancesSet>", owner, owner)
+		for _, id := range owners[owner] {
+			fmt.Fprintf(&buf, "<item><instanceId>%s</instanceId></item>", id)
+		}
+		buf.WriteString("</instancesSet></item>")
+	}
+	buf.WriteString("</reservationSet>")
+	if next != "" {
+		fmt.Fprintf(&buf, "<nextToken>%s</nextToken>", next)
+	}
+	buf.WriteString("</DescribeInstancesResponse>")
+	return buf.String()
+}
+
+func (s *S) TestInstancesAll(c *C) {
+	testServer.Response(200, nil, instancesPage("token1", map[string][]string{"1": {"i-1", "i-2"}, "2": {"i-3"}}))
+	testServer.Response(503, nil, RequestLimitExceededErrorDump)
+	testServer.Response(200, nil, instancesPage("token2", map[string][]string{"1": {"i-4"}}))
+	testServer.Response(200, nil, instancesPage("", map[string][]string{"3": {"i-5", "i-6"}}))
+
+	filter := ec2.NewFilter()
+	filter.Add("instance-state-name", "running")
+
+	instances, err := s.ec2.InstancesAll(filter)
+	c.Assert(err, IsNil)
+
+	var ids, owners []string
+	for _, inst := range instances {
+		ids = append(ids, inst.InstanceId)
+		owners = append(owners, inst.OwnerId)
+	}
+	c.Assert(ids, DeepEquals, []string{"i-1", "i-2", "i-3", "i-4", "i-5", "i-6"})
+	c.Assert(owners, DeepEquals, []string{"1", "1", "2", "1", "3", "3"})
+
+	// The throttled page is retried with the same token
+	reqs := testServer.WaitRequests(4)
+	tokens := []string{"", "token1", "token1", "token2"}
+	for i, req := range reqs {
+		c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeInstances"})
+		c.Assert(req.Form["MaxResults"], DeepEquals, []string{"1000"})
+		c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"instance-state-name"})
+		c.Assert(req.Form.Get("NextToken"), Equals, tokens[i])
+	}
+}
+
+func (s *S) TestInstancesAllError(c *C) {
+	testServer.Response(200, nil, instancesPage("token1", map[string][]string{"1": {"i-1"}}))
+	testServer.Response(400, nil, ErrorDump)
+
+	instances, err := s.ec2.InstancesAll(nil)
+	c.Assert(instances, IsNil)
+	c.Assert(aws.IsCode(err, "UnsupportedOperation"), Equals, true)
+	testServer.WaitRequests(2)
+}
+
 func (s *S) TestDescribeInstanceStatusExample(c *C) {
 	testServer.Response(200, nil, DescribeInstanceStatusExample)
 
@@ -290,19 +374,69 @@ func (s *S) TestDescribeInstanceStatusExample(c *C) {
 	e0 := i0.Events[0]
 	c.Assert(e0.Code, Equals, "instance-reboot")
 	c.Assert(e0.Description, Equals, "example description")
-	c.Assert(e0.NotBefore, Equals, "2010-08-17T01:15:18.000Z")
-	c.Assert(e0.NotAfter, Equals, "2010-08-17T01:15:18.000Z")
+	c.Assert(e0.NotBefore, Equals, time.Date(2010, 8, 17, 1, 15, 18, 0, time.UTC))
+	c.Assert(e0.NotAfter, Equals, time.Date(2010, 8, 17, 1, 15, 18, 0, time.UTC))
 
 	c.Assert(i0.InstanceState.Code, Equals, 16)
 	c.Assert(i0.InstanceState.Name, Equals, "running")
 	c.Assert(i0.SystemStatus.Status, Equals, "ok")
-	c.Assert(i0.SystemStatus.Details.Name, Equals, "reachability")
-	c.Assert(i0.SystemStatus.Details.Status, Equals, "passed")
-	c.Assert(i0.SystemStatus.Details.ImpairedSince, Equals, "2010-08-17T01:15:18.000Z")
+	c.Assert(i0.SystemStatus.Details, HasLen, 1)
+	c.Assert(i0.SystemStatus.Details[0].Name, Equals, "reachability")
+	c.Assert(i0.SystemStatus.Details[0].Status, Equals, "passed")
+	c.Assert(i0.SystemStatus.Details[0].ImpairedSince, Equals, "2010-08-17T01:15:18.000Z")
 	c.Assert(i0.InstanceStatus.Status, Equals, "ok")
-	c.Assert(i0.InstanceStatus.Details.Name, Equals, "reachability")
-	c.Assert(i0.InstanceStatus.Details.Status, Equals, "passed")
-	c.Assert(i0.InstanceStatus.Details.ImpairedSince, Equals, "2010-08-17T01:15:18.000Z")
+	c.Assert(i0.InstanceStatus.Details[0].Name, Equals, "reachability")
+	c.Assert(i0.InstanceStatus.Details[0].Status, Equals, "passed")
+	c.Assert(i0.InstanceStatus.Details[0].ImpairedSince, Equals, "2010-08-17T01:15:18.000Z")
+}
+
+func (s *S) TestInstancesWithEvents(c *C) {
+	testServer.Response(200, nil, DescribeInstanceStatusEventsPage1)
+	testServer.Response(200, nil, DescribeInstanceStatusEventsPage2)
+
+	filter := ec2.NewFilter()
+	filter.Add("availability-zone", "us-east-1b")
+	statuses, err := s.ec2.InstancesWithEvents(filter)
+	c.Assert(err, IsNil)
+
+	reqs := testServer.WaitRequests(2)
+	for _, req := range reqs {
+		c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeInstanceStatus"})
+		c.Assert(req.Form["IncludeAllInstances"], DeepEquals, []string{"true"})
+		c.Assert(req.Form["MaxResults"], DeepEquals, []string{"1000"})
+		c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"availability-zone"})
+	}
+	c.Assert(reqs[0].Form["NextToken"], IsNil)
+	c.Assert(reqs[1].Form["NextToken"], DeepEquals, []string{"page2"})
+
+	// i-2 has no events and the only event of i-3 is completed
+	c.Assert(statuses, HasLen, 2)
+	c.Assert(statuses[0].InstanceId, Equals, "i-1")
+	c.Assert(statuses[0].Events, DeepEquals, []ec2.InstanceStatusEvent{{
+		Id:          "instance-event-0d59937288b749b32",
+		Code:        "instance-retirement",
+		Description: "The instance is running on degraded hardware",
+		NotBefore:   time.Date(2016, 11, 24, 0, 0, 0, 0, time.UTC),
+	}})
+	c.Assert(statuses[1].InstanceId, Equals, "i-4")
+	c.Assert(statuses[1].Events, HasLen, 1)
+	c.Assert(statuses[1].Events[0].Code, Equals, "system-maintenance")
+	c.Assert(statuses[1].Events[0].NotAfter, Equals, time.Date(2016, 12, 1, 6, 0, 0, 0, time.UTC))
+	c.Assert(statuses[1].SystemStatus.Status, Equals, "impaired")
+	c.Assert(statuses[1].SystemStatus.Details[0].ImpairedSince, Equals, "2016-11-20T10:00:00.000Z")
+}
+
+func (s *S) TestInstanceStatusAllIds(c *C) {
+	testServer.Response(200, nil, DescribeInstanceStatusEventsPage2)
+
+	statuses, err := s.ec2.InstanceStatusAll(&ec2.DescribeInstanceStatusOptions{InstanceIds: []string{"i-3", "i-4"}}, nil)
+	c.Assert(err, IsNil)
+	c.Assert(statuses, HasLen, 2)
+
+	// MaxResults can't be given with instance ids
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["InstanceId.1"], DeepEquals, []string{"i-3"})
+	c.Assert(req.Form["MaxResults"], IsNil)
 }
 
 func (s *S) TestDescribeAddressesPublicIPExample(c *C) {
@@ -417,6 +551,138 @@ func (s *S) TestCreateImageExample(c *C) {
 	c.Assert(resp.ImageId, Equals, "ami-4fa54026")
 }
 
+// blockDeviceForm returns the block device mapping parameters of req.
+func blockDeviceForm(req *http.Request) url.Values {
+	form := url.Values{}
+	for k, v := range req.Form {
+		if strings.HasPrefix(k, "BlockDeviceMapping.") {
+			form[k] = v
+		}
+	}
+	return form
+}
+
+var blockDeviceTests = []struct {
+	mapping ec2.BlockDeviceMapping
+	form    url.Values
+}{{
+	ec2.BlockDeviceMapping{DeviceName: "/dev/sdb", VirtualName: "ephemeral0"},
+	url.Values{"BlockDeviceMapping.1.DeviceName": {"/dev/sdb"}, "BlockDeviceMapping.1.VirtualName": {"ephemeral0"}},
+}, {
+	ec2.BlockDeviceMapping{DeviceName: "/dev/sdc", NoDevice: true},
+	url.Values{"BlockDeviceMapping.1.DeviceName": {"/dev/sdc"}, "BlockDeviceMapping.1.NoDevice": {""}},
+}, {
+	ec2.BlockDeviceMapping{DeviceName: "/dev/sdf", VolumeType: "gp2", VolumeSize: 100, DeleteOnTermination: true},
+	url.Values{
+		"BlockDeviceMapping.1.DeviceName":              {"/dev/sdf"},
+		"BlockDeviceMapping.1.Ebs.VolumeType":          {"gp2"},
+		"BlockDeviceMapping.1.Ebs.VolumeSize":          {"100"},
+		"BlockDeviceMapping.1.Ebs.DeleteOnTermination": {"true"},
+	},
+}, {
+	ec2.BlockDeviceMapping{DeviceName: "/dev/sdg", VolumeType: "io1", VolumeSize: 200, IOPS: 3000},
+	url.Values{
+		"BlockDeviceMapping.1.DeviceName":     {"/dev/sdg"},
+		"BlockDeviceMapping.1.Ebs.VolumeType": {"io1"},
+		"BlockDeviceMapping.1.Ebs.VolumeSize": {"200"},
+		"BlockDeviceMapping.1.Ebs.Iops":       {"3000"},
+	},
+}, {
+	ec2.BlockDeviceMapping{DeviceName: "/dev/sdh", VolumeSize: 50, Encrypted: true},
+	url.Values{
+		"BlockDeviceMapping.1.DeviceName":     {"/dev/sdh"},
+		"BlockDeviceMapping.1.Ebs.VolumeSize": {"50"},
+		"BlockDeviceMapping.1.Ebs.Encrypted":  {"true"},
+	},
+}, {
+	ec2.BlockDeviceMapping{DeviceName: "/dev/sdi", SnapshotId: "snap-1a2b3c4d", Encrypted: true, KmsKeyId: "alias/volumes"},
+	url.Values{
+		"BlockDeviceMapping.1.DeviceName":     {"/dev/sdi"},
+		"BlockDeviceMapping.1.Ebs.SnapshotId": {"snap-1a2b3c4d"},
+		"BlockDeviceMapping.1.Ebs.Encrypted":  {"true"},
+		"BlockDeviceMapping.1.Ebs.KmsKeyId":   {"alias/volumes"},
+	},
+}}
+
+func (s *S) TestBlockDeviceMappingParams(c *C) {
+	for i, t := range blockDeviceTests {
+		c.Logf("test %d: %#v", i, t.mapping)
+		testServer.Response(200, nil, RunInstancesExample)
+		options := ec2.RunInstancesOptions{
+			ImageId:      "image-id",
+			BlockDevices: []ec2.BlockDeviceMapping{t.mapping},
+		}
+		_, err := s.ec2.RunInstances(&options)
+		c.Assert(err, IsNil)
+		c.Assert(blockDeviceForm(testServer.WaitRequest()), DeepEquals, t.form)
+	}
+}
+
+func (s *S) TestDescribeInstancesBlockDevices(c *C) {
+	testServer.Response(200, nil, DescribeInstancesBlockDevicesExample)
+
+	resp, err := s.ec2.DescribeInstances([]string{"i-1a2b3c4d"}, nil)
+	testServer.WaitRequest()
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.Reservations[0].Instances[0].BlockDevices, DeepEquals, []ec2.BlockDeviceMapping{{
+		DeviceName:          "/dev/xvda",
+		VolumeId:            "vol-1a2b3c4d",
+		Status:              "attached",
+		AttachTime:          "2016-11-02T21:12:38.000Z",
+		DeleteOnTermination: true,
+	}, {
+		DeviceName: "/dev/sdf",
+		VolumeId:   "vol-2a2b3c4d",
+		Status:     "attaching",
+		AttachTime: "2016-11-02T21:12:39.000Z",
+	}})
+}
+
+func (s *S) TestBlockDeviceMappingRoundTrip(c *C) {
+	testServer.Response(200, nil, DescribeImagesBlockDevicesExample)
+	resp, err := s.ec2.Images([]string{"ami-1a2b3c4d"}, nil)
+	testServer.WaitRequest()
+	c.Assert(err, IsNil)
+
+	devices := resp.Images[0].BlockDevices
+	c.Assert(devices, DeepEquals, []ec2.BlockDeviceMapping{{
+		DeviceName:          "/dev/xvda",
+		SnapshotId:          "snap-1a2b3c4d",
+		VolumeType:          "io1",
+		VolumeSize:          8,
+		IOPS:                400,
+		DeleteOnTermination: true,
+		Encrypted:           true,
+		KmsKeyId:            "arn:aws:kms:us-east-1:123456789012:key/abcd1234-a123-456a-a12b-a123b4cd56ef",
+	}, {
+		DeviceName:  "/dev/sdb",
+		VirtualName: "ephemeral0",
+	}, {
+		DeviceName: "/dev/sdc",
+		NoDevice:   true,
+	}})
+
+	// The mappings of the image are launched as they are
+	testServer.Response(200, nil, RunInstancesExample)
+	_, err = s.ec2.RunInstances(&ec2.RunInstancesOptions{ImageId: "ami-1a2b3c4d", BlockDevices: devices})
+	c.Assert(err, IsNil)
+	c.Assert(blockDeviceForm(testServer.WaitRequest()), DeepEquals, url.Values{
+		"BlockDeviceMapping.1.DeviceName":              {"/dev/xvda"},
+		"BlockDeviceMapping.1.Ebs.SnapshotId":          {"snap-1a2b3c4d"},
+		"BlockDeviceMapping.1.Ebs.VolumeType":          {"io1"},
+		"BlockDeviceMapping.1.Ebs.VolumeSize":          {"8"},
+		"BlockDeviceMapping.1.Ebs.Iops":                {"400"},
+		"BlockDeviceMapping.1.Ebs.DeleteOnTermination": {"true"},
+		"BlockDeviceMapping.1.Ebs.Encrypted":           {"true"},
+		"BlockDeviceMapping.1.Ebs.KmsKeyId":            {"arn:aws:kms:us-east-1:123456789012:key/abcd1234-a123-456a-a12b-a123b4cd56ef"},
+		"BlockDeviceMapping.2.DeviceName":              {"/dev/sdb"},
+		"BlockDeviceMapping.2.VirtualName":             {"ephemeral0"},
+		"BlockDeviceMapping.3.DeviceName":              {"/dev/sdc"},
+		"BlockDeviceMapping.3.NoDevice":                {""},
+	})
+}
+
 func (s *S) TestDescribeImagesExample(c *C) {
 	testServer.Response(200, nil, DescribeImagesExample)
 
@@ -601,6 +867,185 @@ func (s *S) TestDescribeSnapshotsExample(c *C) {
 	c.Assert(s0.Tags[0].Value, Equals, "demo_db_14_backup")
 }
 
+func (s *S) TestDeleteSnapshotInUse(c *C) {
+	testServer.Response(400, nil, InvalidSnapshotInUseErrorDump)
+
+	_, err := s.ec2.DeleteSnapshot("snap-1a2b3c4d")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DeleteSnapshot"})
+	c.Assert(req.Form["SnapshotId"], DeepEquals, []string{"snap-1a2b3c4d"})
+	c.Assert(aws.IsCode(err, "InvalidSnapshot.InUse"), Equals, true)
+	c.Assert(err, ErrorMatches, "The snapshot snap-1a2b3c4d is currently in use by ami-1a2b3c4d .*")
+}
+
+func (s *S) TestDescribeSnapshotsOptions(c *C) {
+	testServer.Response(200, nil, DescribeSnapshotsCompletedExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("tag:Purpose", "backup")
+	options := ec2.DescribeSnapshotsOptions{
+		OwnerIds:   []string{"self"},
+		MaxResults: 5,
+		NextToken:  "token0",
+	}
+	resp, err := s.ec2.DescribeSnapshots(&options, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeSnapshots"})
+	c.Assert(req.Form["Owner.1"], DeepEquals, []string{"self"})
+	c.Assert(req.Form["SnapshotId.1"], IsNil)
+	c.Assert(req.Form["MaxResults"], DeepEquals, []string{"5"})
+	c.Assert(req.Form["NextToken"], DeepEquals, []string{"token0"})
+	c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"tag:Purpose"})
+	c.Assert(req.Form["Filter.1.Value.1"], DeepEquals, []string{"backup"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.NextToken, Equals, "token1")
+	c.Assert(resp.Snapshots, HasLen, 1)
+	c.Assert(resp.Snapshots[0].Encrypted, Equals, true)
+	c.Assert(resp.Snapshots[0].KmsKeyId, Equals, "arn:aws:kms:us-east-1:111122223333:key/bc3ca4a5-1a2b-3c4d-86e0-54ef3EXAMPLE")
+}
+
+func (s *S) TestCopySnapshot(c *C) {
+	testServer.Response(200, nil, CopySnapshotExample)
+
+	resp, err := s.ec2.CopySnapshot(&ec2.CopySnapshot{
+		SourceRegion:     "us-west-1",
+		SourceSnapshotId: "snap-1a2b3c4d",
+		Description:      "Copy of daily backup",
+		Encrypted:        true,
+		KmsKeyId:         "alias/backups",
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"CopySnapshot"})
+	c.Assert(req.Form["SourceRegion"], DeepEquals, []string{"us-west-1"})
+	c.Assert(req.Form["SourceSnapshotId"], DeepEquals, []string{"snap-1a2b3c4d"})
+	c.Assert(req.Form["Description"], DeepEquals, []string{"Copy of daily backup"})
+	c.Assert(req.Form["Encrypted"], DeepEquals, []string{"true"})
+	c.Assert(req.Form["KmsKeyId"], DeepEquals, []string{"alias/backups"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.SnapshotId, Equals, "snap-2a2b3c4d")
+}
+
+func (s *S) TestWaitUntilSnapshotCompleted(c *C) {
+	defer func(policy aws.RetryPolicy) { ec2.SnapshotWaitPolicy = policy }(ec2.SnapshotWaitPolicy)
+	ec2.SnapshotWaitPolicy = aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	testServer.Response(200, nil, DescribeSnapshotsExample)
+	testServer.Response(200, nil, DescribeSnapshotsCompletedExample)
+
+	snapshot, err := s.ec2.WaitUntilSnapshotCompleted("snap-1a2b3c4d")
+	c.Assert(err, IsNil)
+	c.Assert(snapshot.Status, Equals, "completed")
+	c.Assert(snapshot.Progress, Equals, "100%")
+	for _, req := range testServer.WaitRequests(2) {
+		c.Assert(req.Form["SnapshotId.1"], DeepEquals, []string{"snap-1a2b3c4d"})
+	}
+
+	// Failed snapshots are reported
+	testServer.Response(200, nil, DescribeSnapshotsErrorStateExample)
+	_, err = s.ec2.WaitUntilSnapshotCompleted("snap-1a2b3c4d")
+	c.Assert(err, ErrorMatches, "snapshot snap-1a2b3c4d is error: Client.InternalError: .*")
+	testServer.WaitRequest()
+
+	// So are snapshots still pending after the last poll
+	for i := 0; i < 3; i++ {
+		testServer.Response(200, nil, DescribeSnapshotsExample)
+	}
+	_, err = s.ec2.WaitUntilSnapshotCompleted("snap-1a2b3c4d")
+	c.Assert(err, ErrorMatches, `snapshot snap-1a2b3c4d is pending \(30%\)`)
+	c.Assert(err.(*ec2.SnapshotError).Snapshot.Progress, Equals, "30%")
+	testServer.WaitRequests(3)
+}
+
+func (s *S) TestCreateVolume(c *C) {
+	testServer.Response(200, nil, CreateVolumeExample)
+
+	resp, err := s.ec2.CreateVolume(&ec2.CreateVolume{
+		AvailZone:  "us-east-1a",
+		SnapshotId: "snap-1a2b3c4d",
+		VolumeType: "io1",
+		IOPS:       3000,
+		Encrypted:  true,
+		KmsKeyId:   "alias/backups",
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"CreateVolume"})
+	c.Assert(req.Form["AvailabilityZone"], DeepEquals, []string{"us-east-1a"})
+	c.Assert(req.Form["SnapshotId"], DeepEquals, []string{"snap-1a2b3c4d"})
+	c.Assert(req.Form["Size"], IsNil)
+	c.Assert(req.Form["VolumeType"], DeepEquals, []string{"io1"})
+	c.Assert(req.Form["Iops"], DeepEquals, []string{"3000"})
+	c.Assert(req.Form["Encrypted"], DeepEquals, []string{"true"})
+	c.Assert(req.Form["KmsKeyId"], DeepEquals, []string{"alias/backups"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.VolumeId, Equals, "vol-1a2b3c4d")
+	c.Assert(resp.Size, Equals, int64(80))
+	c.Assert(resp.Status, Equals, "creating")
+	c.Assert(resp.Encrypted, Equals, true)
+	c.Assert(resp.IOPS, Equals, int64(3000))
+}
+
+func (s *S) TestAttachVolume(c *C) {
+	testServer.Response(200, nil, AttachVolumeExample)
+
+	resp, err := s.ec2.AttachVolume("vol-1a2b3c4d", "i-1a2b3c4d", "/dev/sdh")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"AttachVolume"})
+	c.Assert(req.Form["VolumeId"], DeepEquals, []string{"vol-1a2b3c4d"})
+	c.Assert(req.Form["InstanceId"], DeepEquals, []string{"i-1a2b3c4d"})
+	c.Assert(req.Form["Device"], DeepEquals, []string{"/dev/sdh"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.Status, Equals, "attaching")
+	c.Assert(resp.AttachTime, Equals, "2014-05-12T22:25:04.000Z")
+}
+
+func (s *S) TestDetachVolume(c *C) {
+	testServer.Response(200, nil, DetachVolumeExample)
+
+	_, err := s.ec2.DetachVolume("vol-1a2b3c4d")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DetachVolume"})
+	c.Assert(req.Form["VolumeId"], DeepEquals, []string{"vol-1a2b3c4d"})
+	c.Assert(err, IsNil)
+}
+
+func (s *S) TestDescribeVolumes(c *C) {
+	testServer.Response(200, nil, DescribeVolumesExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("status", "in-use")
+
+	resp, err := s.ec2.Volumes([]string{"vol-1a2b3c4d"}, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeVolumes"})
+	c.Assert(req.Form["VolumeId.1"], DeepEquals, []string{"vol-1a2b3c4d"})
+	c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"status"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.Volumes, HasLen, 1)
+	v := resp.Volumes[0]
+	c.Assert(v.Status, Equals, "in-use")
+	c.Assert(v.VolumeType, Equals, "gp2")
+	c.Assert(v.CreateTime, Equals, "2014-05-12T22:19:44.000Z")
+	c.Assert(v.Attachments, DeepEquals, []ec2.VolumeAttachment{{
+		VolumeId:   "vol-1a2b3c4d",
+		InstanceId: "i-1a2b3c4d",
+		Device:     "/dev/sdh",
+		Status:     "attached",
+		AttachTime: "2014-05-12T22:25:04.000Z",
+	}})
+}
+
 func (s *S) TestModifyImageAttributeExample(c *C) {
 	testServer.Response(200, nil, ModifyImageAttributeExample)
 
@@ -650,15 +1095,69 @@ func (s *S) TestCopyImageExample(c *C) {
 		SourceRegion:  "us-west-2",
 		SourceImageId: "ami-1a2b3c4d",
 		Description:   "Test Description",
+		Encrypted:     true,
+		KmsKeyId:      "alias/images",
 	}
 
 	resp, err := s.ec2.CopyImage(&options)
 
 	req := testServer.WaitRequest()
 	c.Assert(req.Form["Action"], DeepEquals, []string{"CopyImage"})
+	c.Assert(req.Form["SourceRegion"], DeepEquals, []string{"us-west-2"})
+	c.Assert(req.Form["SourceImageId"], DeepEquals, []string{"ami-1a2b3c4d"})
+	c.Assert(req.Form["Encrypted"], DeepEquals, []string{"true"})
+	c.Assert(req.Form["KmsKeyId"], DeepEquals, []string{"alias/images"})
 
 	c.Assert(err, IsNil)
 	c.Assert(resp.RequestId, Equals, "60bc441d-fa2c-494d-b155-5d6a3EXAMPLE")
+	c.Assert(resp.ImageId, Equals, "ami-4d3c2b1a")
+}
+
+func (s *S) TestDeregisterImageExample(c *C) {
+	testServer.Response(200, nil, DeregisterImageExample)
+
+	resp, err := s.ec2.DeregisterImage("ami-4fa54026")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DeregisterImage"})
+	c.Assert(req.Form["ImageId"], DeepEquals, []string{"ami-4fa54026"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.RequestId, Equals, "59dbff89-35bd-4eac-99ed-be587EXAMPLE")
+}
+
+func (s *S) TestWaitUntilImageAvailable(c *C) {
+	defer func(policy aws.RetryPolicy) { ec2.ImageWaitPolicy = policy }(ec2.ImageWaitPolicy)
+	ec2.ImageWaitPolicy = aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	// New images may not be found at first
+	testServer.Response(400, nil, InvalidAMIIDNotFoundErrorDump)
+	testServer.Response(200, nil, DescribeImagesPendingExample)
+	testServer.Response(200, nil, DescribeImagesAvailableExample)
+
+	image, err := s.ec2.WaitUntilImageAvailable("ami-4fa54026")
+	c.Assert(err, IsNil)
+	c.Assert(image.Id, Equals, "ami-4fa54026")
+	c.Assert(image.State, Equals, "available")
+	for _, req := range testServer.WaitRequests(3) {
+		c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeImages"})
+		c.Assert(req.Form["ImageId.1"], DeepEquals, []string{"ami-4fa54026"})
+	}
+
+	// Failed images are reported with their reason
+	testServer.Response(200, nil, DescribeImagesFailedExample)
+	_, err = s.ec2.WaitUntilImageAvailable("ami-4fa54026")
+	c.Assert(err, ErrorMatches, "image ami-4fa54026 is failed: Client.InvalidSnapshot.NotFound: .*")
+	c.Assert(err.(*ec2.ImageError).Image.StateReasonCode, Equals, "Client.InvalidSnapshot.NotFound")
+	testServer.WaitRequest()
+
+	// So are images still pending after the last poll
+	for i := 0; i < 3; i++ {
+		testServer.Response(200, nil, DescribeImagesPendingExample)
+	}
+	_, err = s.ec2.WaitUntilImageAvailable("ami-4fa54026")
+	c.Assert(err, ErrorMatches, "image ami-4fa54026 is pending")
+	testServer.WaitRequests(3)
 }
 
 func (s *S) TestCreateKeyPairExample(c *C) {
@@ -920,6 +1419,78 @@ func (s *S) TestRevokeSecurityGroupExample(c *C) {
 	c.Assert(resp.RequestId, Equals, "59dbff89-35bd-4eac-99ed-be587EXAMPLE")
 }
 
+func (s *S) TestAuthorizeSecurityGroupEgressSources(c *C) {
+	testServer.Response(200, nil, AuthorizeSecurityGroupIngressExample)
+
+	perms := []ec2.IPPerm{{
+		Protocol:      "tcp",
+		FromPort:      443,
+		ToPort:        443,
+		SourceIPs:     []string{"203.0.113.0/24"},
+		SourceIPv6s:   []string{"2001:db8::/32"},
+		PrefixListIds: []string{"pl-12345678"},
+		SourceGroups:  []ec2.UserSecurityGroup{{Id: "sg-5e6f7a8b", Description: "Load balancers"}},
+		Descriptions:  map[string]string{"2001:db8::/32": "Office IPv6", "pl-12345678": "S3"},
+	}}
+	_, err := s.ec2.AuthorizeSecurityGroupEgress(ec2.SecurityGroup{Id: "sg-1a2b3c4d"}, perms)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"AuthorizeSecurityGroupEgress"})
+	c.Assert(req.Form["Version"], DeepEquals, []string{"2016-11-15"})
+	c.Assert(req.Form["IpPermissions.1.IpRanges.1.CidrIp"], DeepEquals, []string{"203.0.113.0/24"})
+	c.Assert(req.Form["IpPermissions.1.IpRanges.1.Description"], IsNil)
+	c.Assert(req.Form["IpPermissions.1.Ipv6Ranges.1.CidrIpv6"], DeepEquals, []string{"2001:db8::/32"})
+	c.Assert(req.Form["IpPermissions.1.Ipv6Ranges.1.Description"], DeepEquals, []string{"Office IPv6"})
+	c.Assert(req.Form["IpPermissions.1.PrefixListIds.1.PrefixListId"], DeepEquals, []string{"pl-12345678"})
+	c.Assert(req.Form["IpPermissions.1.PrefixListIds.1.Description"], DeepEquals, []string{"S3"})
+	c.Assert(req.Form["IpPermissions.1.Groups.1.GroupId"], DeepEquals, []string{"sg-5e6f7a8b"})
+	c.Assert(req.Form["IpPermissions.1.Groups.1.Description"], DeepEquals, []string{"Load balancers"})
+	c.Assert(err, IsNil)
+}
+
+func (s *S) TestDescribeSecurityGroupsDescriptions(c *C) {
+	testServer.Response(200, nil, DescribeSecurityGroupsDescriptionsExample)
+
+	resp, err := s.ec2.SecurityGroups(nil, nil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Version"], DeepEquals, []string{"2016-11-15"})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Groups, HasLen, 1)
+	g := resp.Groups[0]
+	c.Assert(g.VpcId, Equals, "vpc-1a2b3c4d")
+	c.Assert(g.IPPerms, DeepEquals, []ec2.IPPerm{{
+		Protocol:     "tcp",
+		FromPort:     443,
+		ToPort:       443,
+		SourceIPs:    []string{"203.0.113.0/24", "198.51.100.0/24"},
+		SourceIPv6s:  []string{"2001:db8::/32"},
+		SourceGroups: []ec2.UserSecurityGroup{{Id: "sg-5e6f7a8b", OwnerId: "123456789012", Description: "Load balancers"}},
+		Descriptions: map[string]string{"203.0.113.0/24": "Office", "2001:db8::/32": "Office IPv6"},
+	}})
+	c.Assert(g.IPPermsEgress, DeepEquals, []ec2.IPPerm{{
+		Protocol:      "-1",
+		PrefixListIds: []string{"pl-12345678"},
+		Descriptions:  map[string]string{"pl-12345678": "S3"},
+	}})
+}
+
+func (s *S) TestRevokeSecurityGroupNotFound(c *C) {
+	testServer.Response(400, nil, InvalidPermissionNotFoundErrorDump)
+
+	group := ec2.SecurityGroup{Id: "sg-1a2b3c4d"}
+	perms := []ec2.IPPerm{{Protocol: "tcp", FromPort: 22, ToPort: 22, SourceIPs: []string{"0.0.0.0/0"}}}
+	_, err := s.ec2.RevokeSecurityGroupEgress(group, perms)
+	testServer.WaitRequest()
+
+	notFound, ok := err.(*ec2.PermissionNotFoundError)
+	c.Assert(ok, Equals, true)
+	c.Assert(notFound.Group, Equals, group)
+	c.Assert(notFound.Err.Code, Equals, "InvalidPermission.NotFound")
+	c.Assert(aws.IsCode(err, "InvalidPermission.NotFound"), Equals, true)
+	c.Assert(err, ErrorMatches, "The specified rule does not exist in this security group. .*")
+}
+
 func (s *S) TestCreateTags(c *C) {
 	testServer.Response(200, nil, CreateTagsExample)
 
@@ -937,6 +1508,123 @@ func (s *S) TestCreateTags(c *C) {
 	c.Assert(resp.RequestId, Equals, "59dbff89-35bd-4eac-99ed-be587EXAMPLE")
 }
 
+func (s *S) TestDeleteTags(c *C) {
+	testServer.Response(200, nil, DeleteTagsExample)
+	_, err := s.ec2.DeleteTags([]string{"ami-1a2b3c4d"}, []ec2.Tag{{"webserver", ""}, {"stack", "Production"}})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DeleteTags"})
+	c.Assert(req.Form["ResourceId.1"], DeepEquals, []string{"ami-1a2b3c4d"})
+	c.Assert(req.Form["Tag.1.Key"], DeepEquals, []string{"webserver"})
+	c.Assert(req.Form["Tag.1.Value"], DeepEquals, []string{""})
+	c.Assert(req.Form["Tag.2.Key"], DeepEquals, []string{"stack"})
+	c.Assert(req.Form["Tag.2.Value"], DeepEquals, []string{"Production"})
+
+	// Keys alone remove the tags whatever their value
+	testServer.Response(200, nil, DeleteTagsExample)
+	resp, err := s.ec2.DeleteTagKeys([]string{"ami-1a2b3c4d", "i-7f4d3a2b"}, []string{"stack"})
+	c.Assert(err, IsNil)
+	c.Assert(resp.RequestId, Equals, "7a62c49f-347e-4fc4-9331-6e8eEXAMPLE")
+
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["ResourceId.2"], DeepEquals, []string{"i-7f4d3a2b"})
+	c.Assert(req.Form["Tag.1.Key"], DeepEquals, []string{"stack"})
+	_, ok := req.Form["Tag.1.Value"]
+	c.Assert(ok, Equals, false)
+}
+
+func (s *S) TestTagsAll(c *C) {
+	testServer.Response(200, nil, DescribeTagsPage1)
+	testServer.Response(200, nil, DescribeTagsPage2)
+
+	filter := ec2.NewFilter()
+	filter.Add("resource-type", "instance")
+	tags, err := s.ec2.TagsAll(filter)
+	c.Assert(err, IsNil)
+	c.Assert(tags, DeepEquals, []ec2.TagDescription{
+		{"i-5f4e3d2a", "instance", "webserver", ""},
+		{"i-5f4e3d2a", "instance", "stack", "Production"},
+		{"i-12345678", "instance", "database_server", ""},
+	})
+
+	reqs := testServer.WaitRequests(2)
+	c.Assert(reqs[0].Form["Action"], DeepEquals, []string{"DescribeTags"})
+	c.Assert(reqs[0].Form["MaxResults"], DeepEquals, []string{"1000"})
+	c.Assert(reqs[0].Form["NextToken"], IsNil)
+	c.Assert(reqs[1].Form["NextToken"], DeepEquals, []string{"token-page-2"})
+	for _, req := range reqs {
+		c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"resource-type"})
+		c.Assert(req.Form["Filter.1.Value.1"], DeepEquals, []string{"instance"})
+	}
+}
+
+func (s *S) TestInstancesByTag(c *C) {
+	testServer.Response(200, nil, DescribeInstancesExample2)
+	insts, err := s.ec2.InstancesByTag("Name", "café a=b")
+	c.Assert(err, IsNil)
+	c.Assert(insts, HasLen, 1)
+
+	// The query is sent as it was signed
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeInstances"})
+	c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"tag:Name"})
+	c.Assert(req.Form["Filter.1.Value.1"], DeepEquals, []string{"café a=b"})
+	c.Assert(strings.Contains(req.URL.RawQuery, "Filter.1.Value.1=caf%C3%A9%20a%3Db&"), Equals, true)
+
+	testServer.Response(200, nil, DescribeInstancesExample2)
+	_, err = s.ec2.InstancesByTag("Name", "")
+	c.Assert(err, IsNil)
+
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"tag-key"})
+	c.Assert(req.Form["Filter.1.Value.1"], DeepEquals, []string{"Name"})
+}
+
+// rotatingCredentials returns new keys each time they are requested.
+type rotatingCredentials struct {
+	n int
+}
+
+func (r *rotatingCredentials) Credentials() (aws.Auth, error) {
+	r.n++
+	return aws.Auth{AccessKey: fmt.Sprintf("access-%d", r.n), SecretKey: "secret"}, nil
+}
+
+func (s *S) TestCredentialsRotation(c *C) {
+	s.ec2.Credentials = &rotatingCredentials{}
+	defer func() {
+		s.ec2.Credentials = nil
+	}()
+
+	for i := 1; i <= 2; i++ {
+		testServer.Response(200, nil, StartInstancesExample)
+		_, err := s.ec2.StartInstances("i-10a64379")
+		c.Assert(err, IsNil)
+
+		req := testServer.WaitRequest()
+		c.Assert(req.Form["AWSAccessKeyId"], DeepEquals, []string{fmt.Sprintf("access-%d", i)})
+	}
+}
+
+func (s *S) TestHTTPClientTimeout(c *C) {
+	hang := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-hang
+	}))
+	defer srv.Close()
+	defer close(hang)
+
+	e := ec2.NewWithClient(aws.Auth{AccessKey: "abc", SecretKey: "123"}, aws.Region{EC2Endpoint: srv.URL},
+		&http.Client{Timeout: 50 * time.Millisecond})
+	e.RetryPolicy = &aws.NoRetry
+
+	start := time.Now()
+	_, err := e.StartInstances("i-10a64379")
+	c.Assert(err, ErrorMatches, ".*Timeout.*")
+	c.Assert(time.Since(start) < time.Second, Equals, true)
+}
+
 func (s *S) TestStartInstances(c *C) {
 	testServer.Response(200, nil, StartInstancesExample)
 
@@ -990,6 +1678,30 @@ func (s *S) TestRebootInstances(c *C) {
 	c.Assert(resp.RequestId, Equals, "59dbff89-35bd-4eac-99ed-be587EXAMPLE")
 }
 
+func (s *S) TestRebootInstancesClockSkew(c *C) {
+	// The server clock is 10 minutes ahead
+	date := time.Now().Add(10 * time.Minute).UTC().Format(http.TimeFormat)
+	testServer.Response(400, map[string]string{"Date": date}, ErrorRequestExpired)
+	testServer.Response(200, nil, RebootInstancesExample)
+
+	// Use a new client so the offset doesn't apply to other tests
+	e := ec2.NewWithClient(s.ec2.Auth, s.ec2.Region, testutil.DefaultClient)
+	resp, err := e.RebootInstances("i-10a64379")
+	reqs := testServer.WaitRequests(2)
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.RequestId, Equals, "59dbff89-35bd-4eac-99ed-be587EXAMPLE")
+
+	// The request is signed again with the server time
+	first, err := time.Parse(time.RFC3339, reqs[0].Form.Get("Timestamp"))
+	c.Assert(err, IsNil)
+	second, err := time.Parse(time.RFC3339, reqs[1].Form.Get("Timestamp"))
+	c.Assert(err, IsNil)
+	skew := second.Sub(first)
+	c.Assert(skew > 9*time.Minute && skew < 11*time.Minute, Equals, true, Commentf("skew %v", skew))
+	c.Assert(reqs[1].Form.Get("Signature"), Not(Equals), reqs[0].Form.Get("Signature"))
+}
+
 func (s *S) TestSignatureWithEndpointPath(c *C) {
 	ec2.FakeTime(true)
 	defer ec2.FakeTime(false)
@@ -1066,6 +1778,71 @@ func (s *S) TestAssociateAddressExample(c *C) {
 	c.Assert(resp.Return, Equals, true)
 }
 
+func (s *S) TestAssociateAddressReassociation(c *C) {
+	// The address is associated with another network interface
+	testServer.Response(400, nil, ResourceAlreadyAssociatedErrorDump)
+	testServer.Response(200, nil, AssociateAddressExample)
+
+	options := &ec2.AssociateAddressOptions{
+		AllocationId:       "eipalloc-5723d13e",
+		NetworkInterfaceId: "eni-1a2b3c4d",
+	}
+	resp, err := s.ec2.AssociateAddress(options)
+	c.Assert(resp, IsNil)
+	c.Assert(err, ErrorMatches, `resource eipalloc-5723d13e is already associated with associate-id eipassoc-fc5ca095 \(Resource.AlreadyAssociated\)`)
+	ec2err, ok := err.(*ec2.Error)
+	c.Assert(ok, Equals, true)
+	c.Assert(ec2err.StatusCode, Equals, 400)
+	c.Assert(ec2err.Code, Equals, "Resource.AlreadyAssociated")
+	c.Assert(ec2err.RequestId, Equals, "2ee5f1a4-7c2b-4c5e-9d3b-8a1f0EXAMPLE")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["AllocationId"], DeepEquals, []string{"eipalloc-5723d13e"})
+	c.Assert(req.Form["NetworkInterfaceId"], DeepEquals, []string{"eni-1a2b3c4d"})
+	c.Assert(req.Form["InstanceId"], IsNil)
+	c.Assert(req.Form["AllowReassociation"], IsNil)
+
+	// It moves when reassociation is allowed
+	options.AllowReassociation = true
+	resp, err = s.ec2.AssociateAddress(options)
+	c.Assert(err, IsNil)
+	c.Assert(resp.AssociationId, Equals, "eipassoc-fc5ca095")
+
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["AllowReassociation"], DeepEquals, []string{"true"})
+}
+
+func (s *S) TestDescribeAddressesFilter(c *C) {
+	testServer.Response(200, nil, DescribeAddressesAllocationIdExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("domain", "vpc")
+	filter.Add("instance-id", "i-64600030")
+	resp, err := s.ec2.DescribeAddresses(nil, nil, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["PublicIp.1"], IsNil)
+	c.Assert(req.Form["AllocationId.1"], IsNil)
+	c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"domain"})
+	c.Assert(req.Form["Filter.1.Value.1"], DeepEquals, []string{"vpc"})
+	c.Assert(req.Form["Filter.2.Name"], DeepEquals, []string{"instance-id"})
+	c.Assert(req.Form["Filter.2.Value.1"], DeepEquals, []string{"i-64600030"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.Addresses, HasLen, 2)
+	c.Assert(resp.Addresses[0].Domain, Equals, "vpc")
+}
+
+func (s *S) TestAllocateAddressStandard(c *C) {
+	testServer.Response(200, nil, AllocateAddressExample)
+
+	_, err := s.ec2.AllocateAddress(&ec2.AllocateAddressOptions{})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Domain"], IsNil)
+}
+
 func (s *S) TestDisassociateAddressExample(c *C) {
 	testServer.Response(200, nil, DisassociateAddressExample)
 
@@ -1081,6 +1858,294 @@ func (s *S) TestDisassociateAddressExample(c *C) {
 	c.Assert(resp.Return, Equals, true)
 }
 
+func (s *S) TestModifyInstanceAttribute(c *C) {
+	attrs := []struct {
+		attr ec2.InstanceAttribute
+		form url.Values
+	}{
+		{ec2.InstanceAttribute{Name: ec2.AttrInstanceType, Value: "m3.large"}, url.Values{"InstanceType.Value": {"m3.large"}}},
+		{ec2.InstanceAttribute{Name: ec2.AttrSourceDestCheck, Value: "false"}, url.Values{"SourceDestCheck.Value": {"false"}}},
+		{ec2.InstanceAttribute{Name: ec2.AttrDisableApiTermination, Value: "true"}, url.Values{"DisableApiTermination.Value": {"true"}}},
+		{ec2.InstanceAttribute{Name: ec2.AttrUserData, UserData: []byte("1234")}, url.Values{"UserData.Value": {"MTIzNA=="}}},
+		{ec2.InstanceAttribute{Name: ec2.AttrGroupSet, GroupIds: []string{"sg-1", "sg-2"}}, url.Values{"GroupId.1": {"sg-1"}, "GroupId.2": {"sg-2"}}},
+	}
+	for _, t := range attrs {
+		testServer.Response(200, nil, ModifyInstanceExample)
+		resp, err := s.ec2.ModifyInstanceAttribute("i-2ba64342", t.attr)
+		c.Assert(err, IsNil)
+		c.Assert(resp.Return, Equals, true)
+
+		req := testServer.WaitRequest()
+		c.Assert(req.Form["Action"], DeepEquals, []string{"ModifyInstanceAttribute"})
+		c.Assert(req.Form["InstanceId"], DeepEquals, []string{"i-2ba64342"})
+		for _, k := range []string{"Action", "InstanceId", "Version", "Timestamp", "AWSAccessKeyId", "Signature", "SignatureMethod", "SignatureVersion"} {
+			req.Form.Del(k)
+		}
+		c.Assert(req.Form, DeepEquals, t.form)
+	}
+
+	_, err := s.ec2.ModifyInstanceAttribute("i-2ba64342", ec2.InstanceAttribute{})
+	c.Assert(err, ErrorMatches, "ec2: no instance attribute to modify")
+}
+
+func (s *S) TestDescribeInstanceAttribute(c *C) {
+	testServer.Response(200, nil, DescribeInstanceAttributeUserDataExample)
+	resp, err := s.ec2.DescribeInstanceAttribute("i-10a64379", ec2.AttrUserData)
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeInstanceAttribute"})
+	c.Assert(req.Form["InstanceId"], DeepEquals, []string{"i-10a64379"})
+	c.Assert(req.Form["Attribute"], DeepEquals, []string{"userData"})
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceId, Equals, "i-10a64379")
+	c.Assert(string(resp.UserData), Equals, "#!/bin/sh\necho hello\n")
+
+	testServer.Response(200, nil, DescribeInstanceAttributeGroupSetExample)
+	resp, err = s.ec2.DescribeInstanceAttribute("i-10a64379", ec2.AttrGroupSet)
+	testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(resp.SecurityGroups, DeepEquals, []ec2.SecurityGroup{{Id: "sg-1a2b3c4d"}, {Id: "sg-2a2b3c4d"}})
+	c.Assert(resp.UserData, IsNil)
+
+	testServer.Response(200, nil, DescribeInstanceAttributeSourceDestCheckExample)
+	resp, err = s.ec2.DescribeInstanceAttribute("i-10a64379", ec2.AttrSourceDestCheck)
+	testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(resp.SourceDestCheck, Equals, true)
+}
+
+// instanceStateExample returns a DescribeInstances response with the
+// instances of the given ids and states, as alternating arguments.
+func instanceStateExample(idStates ...string) string {
+	var buf bytes.Buffer
+	buf.WriteString(`<DescribeInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2014-02-01/"><reservationSet><item><instancesSet>`)
+	for i := 0; i < len(idStates); i += 2 {
+		fmt.Fprintf(&buf, "<item><instanceId>%s</instanceId><instanceState><name>%s</name></instanceState><ipAddress>192.0.2.%d</ipAddress></item>", idStates[i], idStates[i+1], i/2+1)
+	}
+	buf.WriteString(`</instancesSet></item></reservationSet></DescribeInstancesResponse>`)
+	return buf.String()
+}
+
+func (s *S) TestResizeInstance(c *C) {
+	defer func(policy aws.RetryPolicy) { ec2.InstanceWaitPolicy = policy }(ec2.InstanceWaitPolicy)
+	ec2.InstanceWaitPolicy = aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	testServer.Response(200, nil, StopInstancesExample)
+	testServer.Response(200, nil, instanceStateExample("i-10a64379", "stopping"))
+	testServer.Response(200, nil, instanceStateExample("i-10a64379", "stopped"))
+	testServer.Response(200, nil, ModifyInstanceExample)
+	testServer.Response(200, nil, StartInstancesExample)
+	testServer.Response(200, nil, instanceStateExample("i-10a64379", "pending"))
+	testServer.Response(200, nil, instanceStateExample("i-10a64379", "running"))
+
+	err := s.ec2.ResizeInstance("i-10a64379", "m3.large")
+	c.Assert(err, IsNil)
+
+	var actions []string
+	for _, req := range testServer.WaitRequests(7) {
+		actions = append(actions, req.Form.Get("Action"))
+		c.Assert(req.Form.Get("InstanceId.1")+req.Form.Get("InstanceId"), Equals, "i-10a64379")
+		if req.Form.Get("Action") == "ModifyInstanceAttribute" {
+			c.Assert(req.Form["InstanceType.Value"], DeepEquals, []string{"m3.large"})
+		}
+	}
+	c.Assert(actions, DeepEquals, []string{
+		"StopInstances", "DescribeInstances", "DescribeInstances",
+		"ModifyInstanceAttribute",
+		"StartInstances", "DescribeInstances", "DescribeInstances",
+	})
+}
+
+func (s *S) TestWaitUntilInstanceState(c *C) {
+	defer func(policy aws.RetryPolicy) { ec2.InstanceWaitPolicy = policy }(ec2.InstanceWaitPolicy)
+	ec2.InstanceWaitPolicy = aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	// Terminated instances won't reach the state
+	testServer.Response(200, nil, instanceStateExample("i-10a64379", "terminated"))
+	_, err := s.ec2.WaitUntilInstanceState("i-10a64379", "running")
+	c.Assert(err, ErrorMatches, "instance i-10a64379 is terminated, not running")
+	testServer.WaitRequest()
+
+	// Nor do instances still stopping after the last poll
+	for i := 0; i < 3; i++ {
+		testServer.Response(200, nil, instanceStateExample("i-10a64379", "stopping"))
+	}
+	_, err = s.ec2.WaitUntilInstanceState("i-10a64379", "stopped")
+	c.Assert(err, ErrorMatches, "instance i-10a64379 is stopping, not stopped")
+	c.Assert(err.(*ec2.InstanceStateError).Want, Equals, "stopped")
+	testServer.WaitRequests(3)
+}
+
+func (s *S) TestRunInstancesIamInstanceProfileArn(c *C) {
+	testServer.Response(200, nil, RunInstancesExample)
+
+	options := ec2.RunInstancesOptions{
+		ImageId:            "image-id",
+		InstanceType:       "inst-type",
+		IamInstanceProfile: ec2.IamInstanceProfile{ARN: "arn:aws:iam::123456789012:instance-profile/web-server"},
+	}
+	_, err := s.ec2.RunInstances(&options)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["IamInstanceProfile.Arn"], DeepEquals, []string{"arn:aws:iam::123456789012:instance-profile/web-server"})
+	c.Assert(req.Form["IamInstanceProfile.Name"], IsNil)
+	c.Assert(req.Form["Monitoring.Enabled"], IsNil)
+	c.Assert(req.Form["EbsOptimized"], IsNil)
+	c.Assert(req.Form["InstanceInitiatedShutdownBehavior"], IsNil)
+}
+
+func (s *S) TestAssociateIamInstanceProfile(c *C) {
+	testServer.Response(200, nil, AssociateIamInstanceProfileExample)
+
+	resp, err := s.ec2.AssociateIamInstanceProfile("i-1234567890abcdef0", ec2.IamInstanceProfile{Name: "admin-role"})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"AssociateIamInstanceProfile"})
+	c.Assert(req.Form["Version"], DeepEquals, []string{"2016-11-15"})
+	c.Assert(req.Form["InstanceId"], DeepEquals, []string{"i-1234567890abcdef0"})
+	c.Assert(req.Form["IamInstanceProfile.Name"], DeepEquals, []string{"admin-role"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.Association.AssociationId, Equals, "iip-assoc-0e7736511a163c209")
+	c.Assert(resp.Association.State, Equals, "associating")
+	c.Assert(resp.Association.IamInstanceProfile.Id, Equals, "AIPAJBLK7RKJKWDXVHIEC")
+}
+
+func (s *S) TestReplaceIamInstanceProfileAssociation(c *C) {
+	testServer.Response(200, nil, ReplaceIamInstanceProfileAssociationExample)
+
+	resp, err := s.ec2.ReplaceIamInstanceProfileAssociation("iip-assoc-0044d817db6c0a4ba", ec2.IamInstanceProfile{
+		ARN: "arn:aws:iam::123456789012:instance-profile/admin-role",
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"ReplaceIamInstanceProfileAssociation"})
+	c.Assert(req.Form["Version"], DeepEquals, []string{"2016-11-15"})
+	c.Assert(req.Form["AssociationId"], DeepEquals, []string{"iip-assoc-0044d817db6c0a4ba"})
+	c.Assert(req.Form["IamInstanceProfile.Arn"], DeepEquals, []string{"arn:aws:iam::123456789012:instance-profile/admin-role"})
+	c.Assert(req.Form["IamInstanceProfile.Name"], IsNil)
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.Association, DeepEquals, ec2.IamInstanceProfileAssociation{
+		AssociationId: "iip-assoc-08049da59357d598c",
+		InstanceId:    "i-1234567890abcdef0",
+		IamInstanceProfile: ec2.IamInstanceProfile{
+			ARN: "arn:aws:iam::123456789012:instance-profile/admin-role",
+			Id:  "AIPAJ2TNUXJAAX4UKBOSI",
+		},
+		State: "associating",
+	})
+}
+
+func (s *S) TestDisassociateIamInstanceProfile(c *C) {
+	testServer.Response(200, nil, DisassociateIamInstanceProfileExample)
+
+	resp, err := s.ec2.DisassociateIamInstanceProfile("iip-assoc-05020b59952902f5f")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DisassociateIamInstanceProfile"})
+	c.Assert(req.Form["AssociationId"], DeepEquals, []string{"iip-assoc-05020b59952902f5f"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.Association.State, Equals, "disassociating")
+}
+
+func (s *S) TestDescribeIamInstanceProfileAssociations(c *C) {
+	testServer.Response(200, nil, DescribeIamInstanceProfileAssociationsExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("instance-id", "i-09eb09efa73ec1dee")
+	resp, err := s.ec2.DescribeIamInstanceProfileAssociations([]string{"iip-assoc-0db249b1f25fa24b8"}, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeIamInstanceProfileAssociations"})
+	c.Assert(req.Form["Version"], DeepEquals, []string{"2016-11-15"})
+	c.Assert(req.Form["AssociationId.1"], DeepEquals, []string{"iip-assoc-0db249b1f25fa24b8"})
+	c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"instance-id"})
+	c.Assert(req.Form["Filter.1.Value.1"], DeepEquals, []string{"i-09eb09efa73ec1dee"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.Associations, HasLen, 1)
+	a := resp.Associations[0]
+	c.Assert(a.AssociationId, Equals, "iip-assoc-0db249b1f25fa24b8")
+	c.Assert(a.InstanceId, Equals, "i-09eb09efa73ec1dee")
+	c.Assert(a.IamInstanceProfile.ARN, Equals, "arn:aws:iam::123456789012:instance-profile/admin-role")
+	c.Assert(a.State, Equals, "associated")
+	c.Assert(a.Timestamp, Equals, "2017-01-23T16:05:52.000Z")
+}
+
+func (s *S) TestWaitUntilInstanceRunning(c *C) {
+	defer func(policy aws.RetryPolicy) { ec2.InstanceWaitPolicy = policy }(ec2.InstanceWaitPolicy)
+	ec2.InstanceWaitPolicy = aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	// New instances may not be found at first
+	testServer.Response(400, nil, InvalidInstanceIDNotFoundErrorDump)
+	testServer.Response(200, nil, instanceStateExample("i-2", "running", "i-1", "pending"))
+	testServer.Response(200, nil, instanceStateExample("i-2", "running", "i-1", "running"))
+
+	insts, err := s.ec2.WaitUntilInstanceRunning(context.Background(), []string{"i-1", "i-2"})
+	c.Assert(err, IsNil)
+	c.Assert(insts, HasLen, 2)
+	c.Assert(insts[0].InstanceId, Equals, "i-1")
+	c.Assert(insts[0].IPAddress, Equals, "192.0.2.2")
+	c.Assert(insts[1].InstanceId, Equals, "i-2")
+	c.Assert(insts[1].State.Name, Equals, "running")
+	for _, req := range testServer.WaitRequests(3) {
+		c.Assert(req.Form["InstanceId.1"], DeepEquals, []string{"i-1"})
+		c.Assert(req.Form["InstanceId.2"], DeepEquals, []string{"i-2"})
+	}
+}
+
+func (s *S) TestWaitUntilInstanceRunningTerminated(c *C) {
+	defer func(policy aws.RetryPolicy) { ec2.InstanceWaitPolicy = policy }(ec2.InstanceWaitPolicy)
+	ec2.InstanceWaitPolicy = aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	// Instances going straight to terminated fail without waiting
+	testServer.Response(200, nil, DescribeInstancesTerminatedExample)
+	_, err := s.ec2.WaitUntilInstanceRunning(context.Background(), []string{"i-1"})
+	c.Assert(err, ErrorMatches, "instance i-1 is terminated, not running: Server.InternalError: Internal error on launch")
+	c.Assert(err.(*ec2.InstanceStateError).Instance.StateReason.Code, Equals, "Server.InternalError")
+	testServer.WaitRequest()
+}
+
+func (s *S) TestWaitUntilInstanceTerminated(c *C) {
+	defer func(policy aws.RetryPolicy) { ec2.InstanceWaitPolicy = policy }(ec2.InstanceWaitPolicy)
+	ec2.InstanceWaitPolicy = aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	testServer.Response(200, nil, instanceStateExample("i-1", "shutting-down"))
+	testServer.Response(200, nil, instanceStateExample("i-1", "terminated"))
+	insts, err := s.ec2.WaitUntilInstanceTerminated(context.Background(), []string{"i-1"})
+	c.Assert(err, IsNil)
+	c.Assert(insts[0].State.Name, Equals, "terminated")
+	testServer.WaitRequests(2)
+
+	testServer.Response(200, nil, instanceStateExample("i-1", "stopping"))
+	testServer.Response(200, nil, instanceStateExample("i-1", "stopped"))
+	insts, err = s.ec2.WaitUntilInstanceStopped(context.Background(), []string{"i-1"})
+	c.Assert(err, IsNil)
+	c.Assert(insts[0].State.Name, Equals, "stopped")
+	testServer.WaitRequests(2)
+}
+
+func (s *S) TestWaitUntilInstanceRunningContext(c *C) {
+	defer func(policy aws.RetryPolicy) { ec2.InstanceWaitPolicy = policy }(ec2.InstanceWaitPolicy)
+	ec2.InstanceWaitPolicy = aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour}
+
+	// The wait between polls is cut short by the deadline
+	testServer.Response(200, nil, instanceStateExample("i-1", "pending"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := s.ec2.WaitUntilInstanceRunning(ctx, []string{"i-1"})
+	c.Assert(err, Equals, context.DeadlineExceeded)
+	testServer.WaitRequest()
+
+	// Nothing is polled once canceled
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = s.ec2.WaitUntilInstanceRunning(ctx, []string{"i-1"})
+	c.Assert(err, Equals, context.Canceled)
+}
+
 func (s *S) TestModifyInstance(c *C) {
 	testServer.Response(200, nil, ModifyInstanceExample)
 
@@ -1138,5 +2203,95 @@ func (s *S) TestDescribeReservedInstancesExample(c *C) {
 
 	r0 := resp.ReservedInstances[0]
 	c.Assert(r0.ReservedInstanceId, Equals, "e5a2ff3b-7d14-494f-90af-0b5d0EXAMPLE")
+	c.Assert(r0.FixedPrice, Equals, float32(61.0))
+	c.Assert(r0.UsagePrice, Equals, float32(0.034))
+	c.Assert(r0.State, Equals, "active")
+	c.Assert(r0.OfferingClass, Equals, "standard")
+	c.Assert(r0.RecurringCharges, DeepEquals, []ec2.RecurringCharge{{"Hourly", 0.01}})
+	c.Assert(r0.Tags, DeepEquals, []ec2.Tag{{"team", "web"}})
+}
+
+func (s *S) TestDescribeReservedInstancesOfferings(c *C) {
+	testServer.Response(200, nil, DescribeReservedInstancesOfferingsPage1)
+
+	filter := ec2.NewFilter()
+	filter.Add("scope", "Region")
+	resp, err := s.ec2.DescribeReservedInstancesOfferings(&ec2.DescribeReservedInstancesOfferingsOptions{
+		InstanceType:       "m4.large",
+		AvailabilityZone:   "us-east-1a",
+		OfferingClass:      "convertible",
+		ExcludeMarketplace: true,
+		MaxDuration:        31536000,
+		MaxResults:         2,
+	}, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeReservedInstancesOfferings"})
+	c.Assert(req.Form["InstanceType"], DeepEquals, []string{"m4.large"})
+	c.Assert(req.Form["AvailabilityZone"], DeepEquals, []string{"us-east-1a"})
+	c.Assert(req.Form["OfferingClass"], DeepEquals, []string{"convertible"})
+	c.Assert(req.Form["OfferingType"], IsNil)
+	c.Assert(req.Form["IncludeMarketplace"], DeepEquals, []string{"false"})
+	c.Assert(req.Form["MinDuration"], IsNil)
+	c.Assert(req.Form["MaxDuration"], DeepEquals, []string{"31536000"})
+	c.Assert(req.Form["MaxResults"], DeepEquals, []string{"2"})
+	c.Assert(req.Form["NextToken"], IsNil)
+	c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"scope"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.NextToken, Equals, "token-page-2")
+	c.Assert(resp.Offerings, HasLen, 2)
+	o := resp.Offerings[1]
+	c.Assert(o.Id, Equals, "649fd0c8-5d76-4881-a522-fe5224c10fcc")
+	c.Assert(o.Marketplace, Equals, true)
+	c.Assert(o.FixedPrice, Equals, float32(700))
+	c.Assert(o.RecurringCharges, DeepEquals, []ec2.RecurringCharge{{"Hourly", 0.02}})
+	c.Assert(o.PricingDetails, DeepEquals, []ec2.PricingDetail{{700, 2}, {725, 1}})
+}
+
+func (s *S) TestReservedInstancesOfferingsLimit(c *C) {
+	testServer.Response(200, nil, DescribeReservedInstancesOfferingsPage1)
+	testServer.Response(200, nil, DescribeReservedInstancesOfferingsPage2)
+
+	// Pages aren't requested beyond the limit
+	offerings, err := s.ec2.ReservedInstancesOfferings(&ec2.DescribeReservedInstancesOfferingsOptions{InstanceType: "m4.large"}, nil, 3)
+	c.Assert(err, IsNil)
+	c.Assert(offerings, HasLen, 3)
+	c.Assert(offerings[2].Id, Equals, "9a0c4f5e-0d5b-4a0b-8e3c-2a2b1EXAMPLE")
+
+	reqs := testServer.WaitRequests(2)
+	c.Assert(reqs[0].Form["MaxResults"], DeepEquals, []string{"3"})
+	c.Assert(reqs[1].Form["MaxResults"], DeepEquals, []string{"1"})
+	c.Assert(reqs[1].Form["NextToken"], DeepEquals, []string{"token-page-2"})
+	c.Assert(reqs[1].Form["InstanceType"], DeepEquals, []string{"m4.large"})
+
+	testServer.Response(200, nil, DescribeReservedInstancesOfferingsPage1)
+	offerings, err = s.ec2.ReservedInstancesOfferings(&ec2.DescribeReservedInstancesOfferingsOptions{}, nil, 1)
+	c.Assert(err, IsNil)
+	c.Assert(offerings, HasLen, 1)
+	testServer.WaitRequest()
+}
 
+func (s *S) TestModifyReservedInstances(c *C) {
+	testServer.Response(200, nil, ModifyReservedInstancesExample)
+
+	resp, err := s.ec2.ModifyReservedInstances("token", []string{"e5a2ff3b-7d14-494f-90af-0b5d0EXAMPLE"}, []ec2.ReservedInstancesConfiguration{
+		{AvailabilityZone: "us-east-1a", InstanceCount: 1},
+		{AvailabilityZone: "us-east-1b", InstanceCount: 2, Platform: "EC2-VPC"},
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"ModifyReservedInstances"})
+	c.Assert(req.Form["ClientToken"], DeepEquals, []string{"token"})
+	c.Assert(req.Form["ReservedInstancesId.1"], DeepEquals, []string{"e5a2ff3b-7d14-494f-90af-0b5d0EXAMPLE"})
+	c.Assert(req.Form["ReservedInstancesConfigurationSetItemType.1.AvailabilityZone"], DeepEquals, []string{"us-east-1a"})
+	c.Assert(req.Form["ReservedInstancesConfigurationSetItemType.1.InstanceCount"], DeepEquals, []string{"1"})
+	c.Assert(req.Form["ReservedInstancesConfigurationSetItemType.1.Platform"], IsNil)
+	c.Assert(req.Form["ReservedInstancesConfigurationSetItemType.2.AvailabilityZone"], DeepEquals, []string{"us-east-1b"})
+	c.Assert(req.Form["ReservedInstancesConfigurationSetItemType.2.InstanceCount"], DeepEquals, []string{"2"})
+	c.Assert(req.Form["ReservedInstancesConfigurationSetItemType.2.Platform"], DeepEquals, []string{"EC2-VPC"})
+	c.Assert(req.Form["ReservedInstancesConfigurationSetItemType.2.InstanceType"], IsNil)
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.ModificationId, Equals, "rimod-3aae219d-3d63-47a9-a7e9-e764example")
 }
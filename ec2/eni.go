@@ -0,0 +1,288 @@
+package ec2
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+)
+
+// NetworkInterface describes an elastic network interface in a VPC.
+// Status is one of available, attaching, in-use and detaching.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_NetworkInterface.html for more details.
+type NetworkInterface struct {
+	Id                 string                              `xml:"networkInterfaceId"`
+	SubnetId           string                              `xml:"subnetId"`
+	VpcId              string                              `xml:"vpcId"`
+	AvailabilityZone   string                              `xml:"availabilityZone"`
+	Description        string                              `xml:"description"`
+	OwnerId            string                              `xml:"ownerId"`
+	RequesterManaged   bool                                `xml:"requesterManaged"`
+	Status             string                              `xml:"status"`
+	MacAddress         string                              `xml:"macAddress"`
+	PrivateIPAddress   string                              `xml:"privateIpAddress"`
+	PrivateDNSName     string                              `xml:"privateDnsName"`
+	SourceDestCheck    bool                                `xml:"sourceDestCheck"`
+	SecurityGroups     []SecurityGroup                     `xml:"groupSet>item"`
+	Attachment         NetworkInterfaceAttachment          `xml:"attachment"`
+	Association        InstanceNetworkInterfaceAssociation `xml:"association"`
+	PrivateIPAddresses []InstancePrivateIpAddress          `xml:"privateIpAddressesSet>item"`
+	Tags               []Tag                               `xml:"tagSet>item"`
+}
+
+// NetworkInterfaceAttachment describes the attachment of a network
+// interface to an instance, which is detached by its Id. Status is one of
+// attaching, attached, detaching and detached, and is empty if the
+// interface isn't attached.
+type NetworkInterfaceAttachment struct {
+	Id                  string `xml:"attachmentId"`
+	InstanceId          string `xml:"instanceId"`
+	InstanceOwnerId     string `xml:"instanceOwnerId"`
+	DeviceIndex         int    `xml:"deviceIndex"`
+	Status              string `xml:"status"`
+	AttachTime          string `xml:"attachTime"`
+	DeleteOnTermination bool   `xml:"deleteOnTermination"`
+}
+
+// CreateNetworkInterface holds the options of a CreateNetworkInterface
+// request. The primary private address is PrivateIPAddress, or is chosen
+// in the subnet if empty, and the secondary ones are
+// SecondaryPrivateIPAddresses, or as many as
+// SecondaryPrivateIPAddressCount chosen in the subnet.
+type CreateNetworkInterface struct {
+	SubnetId                       string
+	PrivateIPAddress               string
+	SecondaryPrivateIPAddresses    []string
+	SecondaryPrivateIPAddressCount int
+	SecurityGroupIds               []string
+	Description                    string
+}
+
+// CreateNetworkInterfaceResp represents a response from a
+// CreateNetworkInterface request.
+type CreateNetworkInterfaceResp struct {
+	RequestId        string           `xml:"requestId"`
+	NetworkInterface NetworkInterface `xml:"networkInterface"`
+}
+
+// CreateNetworkInterface creates a network interface in a subnet.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateNetworkInterface.html for more details.
+func (ec2 *EC2) CreateNetworkInterface(options *CreateNetworkInterface) (resp *CreateNetworkInterfaceResp, err error) {
+	params := makeParams("CreateNetworkInterface")
+	params["SubnetId"] = options.SubnetId
+	if options.PrivateIPAddress != "" {
+		params["PrivateIpAddress"] = options.PrivateIPAddress
+	}
+	for i, addr := range options.SecondaryPrivateIPAddresses {
+		prefix := "PrivateIpAddresses." + strconv.Itoa(i+1) + "."
+		params[prefix+"PrivateIpAddress"] = addr
+		params[prefix+"Primary"] = "false"
+	}
+	if options.SecondaryPrivateIPAddressCount > 0 {
+		params["SecondaryPrivateIpAddressCount"] = strconv.Itoa(options.SecondaryPrivateIPAddressCount)
+	}
+	addParamsList(params, "SecurityGroupId", options.SecurityGroupIds)
+	if options.Description != "" {
+		params["Description"] = options.Description
+	}
+	resp = &CreateNetworkInterfaceResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteNetworkInterface deletes a detached network interface.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteNetworkInterface.html for more details.
+func (ec2 *EC2) DeleteNetworkInterface(id string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteNetworkInterface")
+	params["NetworkInterfaceId"] = id
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// NetworkInterfacesResp represents a response from a
+// DescribeNetworkInterfaces request.
+type NetworkInterfacesResp struct {
+	RequestId         string             `xml:"requestId"`
+	NetworkInterfaces []NetworkInterface `xml:"networkInterfaceSet>item"`
+}
+
+// DescribeNetworkInterfaces returns the network interfaces with the given
+// ids, or all of them, as limited by filter.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeNetworkInterfaces.html for more details.
+func (ec2 *EC2) DescribeNetworkInterfaces(ids []string, filter *Filter) (resp *NetworkInterfacesResp, err error) {
+	params := makeParams("DescribeNetworkInterfaces")
+	addParamsList(params, "NetworkInterfaceId", ids)
+	filter.addParams(params)
+	resp = &NetworkInterfacesResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// AttachNetworkInterfaceResp represents a response from an
+// AttachNetworkInterface request.
+type AttachNetworkInterfaceResp struct {
+	RequestId    string `xml:"requestId"`
+	AttachmentId string `xml:"attachmentId"`
+}
+
+// AttachNetworkInterface attaches a network interface to an instance, as
+// its device deviceIndex.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AttachNetworkInterface.html for more details.
+func (ec2 *EC2) AttachNetworkInterface(id, instanceId string, deviceIndex int) (resp *AttachNetworkInterfaceResp, err error) {
+	params := makeParams("AttachNetworkInterface")
+	params["NetworkInterfaceId"] = id
+	params["InstanceId"] = instanceId
+	params["DeviceIndex"] = strconv.Itoa(deviceIndex)
+	resp = &AttachNetworkInterfaceResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DetachNetworkInterface detaches the network interface of an attachment.
+// Forcing the detachment is meant for instances which don't respond, and
+// may leave their operating system unaware of it.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DetachNetworkInterface.html for more details.
+func (ec2 *EC2) DetachNetworkInterface(attachmentId string, force bool) (resp *SimpleResp, err error) {
+	params := makeParams("DetachNetworkInterface")
+	params["AttachmentId"] = attachmentId
+	if force {
+		params["Force"] = "true"
+	}
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// AssignPrivateIpAddresses holds the options of an AssignPrivateIpAddresses
+// request, which assigns PrivateIPAddresses, or as many addresses as
+// SecondaryPrivateIPAddressCount chosen in the subnet, to a network
+// interface. Addresses assigned to another interface are moved only if
+// AllowReassignment is set.
+type AssignPrivateIpAddresses struct {
+	NetworkInterfaceId             string
+	PrivateIPAddresses             []string
+	SecondaryPrivateIPAddressCount int
+	AllowReassignment              bool
+}
+
+// AssignPrivateIpAddresses assigns secondary private addresses to a
+// network interface.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AssignPrivateIpAddresses.html for more details.
+func (ec2 *EC2) AssignPrivateIpAddresses(options *AssignPrivateIpAddresses) (resp *SimpleResp, err error) {
+	params := makeParams("AssignPrivateIpAddresses")
+	params["NetworkInterfaceId"] = options.NetworkInterfaceId
+	addParamsList(params, "PrivateIpAddress", options.PrivateIPAddresses)
+	if options.SecondaryPrivateIPAddressCount > 0 {
+		params["SecondaryPrivateIpAddressCount"] = strconv.Itoa(options.SecondaryPrivateIPAddressCount)
+	}
+	if options.AllowReassignment {
+		params["AllowReassignment"] = "true"
+	}
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// UnassignPrivateIpAddresses removes secondary private addresses from a
+// network interface.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_UnassignPrivateIpAddresses.html for more details.
+func (ec2 *EC2) UnassignPrivateIpAddresses(id string, addresses []string) (resp *SimpleResp, err error) {
+	params := makeParams("UnassignPrivateIpAddresses")
+	params["NetworkInterfaceId"] = id
+	addParamsList(params, "PrivateIpAddress", addresses)
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// NetworkInterfaceWaitPolicy is the backoff between the polls of
+// WaitUntilNetworkInterfaceAttachment, which gives up after MaxAttempts
+// polls.
+var NetworkInterfaceWaitPolicy = aws.RetryPolicy{
+	MaxAttempts: 40,
+	BaseDelay:   2 * time.Second,
+	MaxDelay:    15 * time.Second,
+	Jitter:      0.1,
+}
+
+// NetworkInterfaceError is returned by WaitUntilNetworkInterfaceAttachment
+// when the attachment of the interface isn't in the status waited for in
+// time.
+type NetworkInterfaceError struct {
+	NetworkInterface NetworkInterface
+	Want             string
+}
+
+func (e *NetworkInterfaceError) Error() string {
+	status := e.NetworkInterface.Attachment.Status
+	if status == "" {
+		status = "detached"
+	}
+	return fmt.Sprintf("network interface %s is %s, not %s", e.NetworkInterface.Id, status, e.Want)
+}
+
+// WaitUntilNetworkInterfaceAttachment polls the network interface with the
+// given id, backing off as given by NetworkInterfaceWaitPolicy, until its
+// attachment is in status, "attached" or "detached", and returns it. An
+// interface without attachment is detached. It fails with a
+// *NetworkInterfaceError if the attachment isn't in status after the last
+// poll.
+func (ec2 *EC2) WaitUntilNetworkInterfaceAttachment(id, status string) (*NetworkInterface, error) {
+	policy := NetworkInterfaceWaitPolicy
+	var iface NetworkInterface
+	for attempt := 0; ; attempt++ {
+		resp, err := ec2.DescribeNetworkInterfaces([]string{id}, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.NetworkInterfaces) > 0 {
+			iface = resp.NetworkInterfaces[0]
+			current := iface.Attachment.Status
+			if current == "" {
+				current = "detached"
+			}
+			if current == status {
+				return &iface, nil
+			}
+		}
+		if attempt+1 >= policy.MaxAttempts {
+			break
+		}
+		policy.Backoff(attempt)
+	}
+	if iface.Id == "" {
+		iface.Id = id
+	}
+	return nil, &NetworkInterfaceError{iface, status}
+}
@@ -0,0 +1,189 @@
+package ec2_test
+
+import (
+	"time"
+
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/ec2"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) TestCreateNetworkInterface(c *C) {
+	testServer.Response(200, nil, CreateNetworkInterfaceExample)
+
+	resp, err := s.ec2.CreateNetworkInterface(&ec2.CreateNetworkInterface{
+		SubnetId:                    "subnet-b61f49f0",
+		PrivateIPAddress:            "10.0.2.157",
+		SecondaryPrivateIPAddresses: []string{"10.0.2.158"},
+		SecurityGroupIds:            []string{"sg-1a2b3c4d"},
+		Description:                 "failover",
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"CreateNetworkInterface"})
+	c.Assert(req.Form["SubnetId"], DeepEquals, []string{"subnet-b61f49f0"})
+	c.Assert(req.Form["PrivateIpAddress"], DeepEquals, []string{"10.0.2.157"})
+	c.Assert(req.Form["PrivateIpAddresses.1.PrivateIpAddress"], DeepEquals, []string{"10.0.2.158"})
+	c.Assert(req.Form["PrivateIpAddresses.1.Primary"], DeepEquals, []string{"false"})
+	c.Assert(req.Form["SecondaryPrivateIpAddressCount"], IsNil)
+	c.Assert(req.Form["SecurityGroupId.1"], DeepEquals, []string{"sg-1a2b3c4d"})
+	c.Assert(req.Form["Description"], DeepEquals, []string{"failover"})
+
+	c.Assert(err, IsNil)
+	iface := resp.NetworkInterface
+	c.Assert(iface.Id, Equals, "eni-cfca76a6")
+	c.Assert(iface.SubnetId, Equals, "subnet-b61f49f0")
+	c.Assert(iface.Status, Equals, "pending")
+	c.Assert(iface.SecurityGroups, DeepEquals, []ec2.SecurityGroup{{Id: "sg-1a2b3c4d", Name: "default"}})
+	c.Assert(iface.PrivateIPAddresses, HasLen, 2)
+	c.Assert(iface.PrivateIPAddresses[1].PrivateIPAddress, Equals, "10.0.2.158")
+	c.Assert(iface.PrivateIPAddresses[1].Primary, Equals, false)
+}
+
+func (s *S) TestDescribeNetworkInterfaces(c *C) {
+	testServer.Response(200, nil, DescribeNetworkInterfacesExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("attachment.instance-id", "i-22197876")
+	resp, err := s.ec2.DescribeNetworkInterfaces([]string{"eni-0f62d866"}, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeNetworkInterfaces"})
+	c.Assert(req.Form["NetworkInterfaceId.1"], DeepEquals, []string{"eni-0f62d866"})
+	c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"attachment.instance-id"})
+	c.Assert(req.Form["Filter.1.Value.1"], DeepEquals, []string{"i-22197876"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.NetworkInterfaces, HasLen, 1)
+	iface := resp.NetworkInterfaces[0]
+	c.Assert(iface.Id, Equals, "eni-0f62d866")
+	c.Assert(iface.Status, Equals, "in-use")
+	c.Assert(iface.MacAddress, Equals, "02:81:60:cb:27:37")
+	c.Assert(iface.Attachment, DeepEquals, ec2.NetworkInterfaceAttachment{
+		Id:              "eni-attach-6537fc0c",
+		InstanceId:      "i-22197876",
+		InstanceOwnerId: "053230519467",
+		DeviceIndex:     1,
+		Status:          "attached",
+		AttachTime:      "2012-07-01T21:45:27.000Z",
+	})
+}
+
+func (s *S) TestAttachNetworkInterface(c *C) {
+	testServer.Response(200, nil, AttachNetworkInterfaceExample)
+
+	resp, err := s.ec2.AttachNetworkInterface("eni-ffda3197", "i-640a3c17", 1)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"AttachNetworkInterface"})
+	c.Assert(req.Form["NetworkInterfaceId"], DeepEquals, []string{"eni-ffda3197"})
+	c.Assert(req.Form["InstanceId"], DeepEquals, []string{"i-640a3c17"})
+	c.Assert(req.Form["DeviceIndex"], DeepEquals, []string{"1"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.AttachmentId, Equals, "eni-attach-d94b09b0")
+}
+
+func (s *S) TestDetachNetworkInterface(c *C) {
+	testServer.Response(200, nil, SimpleNetworkInterfaceExample)
+	_, err := s.ec2.DetachNetworkInterface("eni-attach-6537fc0c", false)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DetachNetworkInterface"})
+	c.Assert(req.Form["AttachmentId"], DeepEquals, []string{"eni-attach-6537fc0c"})
+	c.Assert(req.Form["Force"], IsNil)
+
+	testServer.Response(200, nil, SimpleNetworkInterfaceExample)
+	_, err = s.ec2.DetachNetworkInterface("eni-attach-6537fc0c", true)
+	c.Assert(err, IsNil)
+
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["Force"], DeepEquals, []string{"true"})
+}
+
+func (s *S) TestDeleteNetworkInterface(c *C) {
+	testServer.Response(200, nil, SimpleNetworkInterfaceExample)
+
+	resp, err := s.ec2.DeleteNetworkInterface("eni-0f62d866")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DeleteNetworkInterface"})
+	c.Assert(req.Form["NetworkInterfaceId"], DeepEquals, []string{"eni-0f62d866"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.RequestId, Equals, "ce540707-0635-46bc-97da-33a8a362a0e8")
+}
+
+func (s *S) TestAssignPrivateIpAddresses(c *C) {
+	testServer.Response(200, nil, SimpleNetworkInterfaceExample)
+
+	_, err := s.ec2.AssignPrivateIpAddresses(&ec2.AssignPrivateIpAddresses{
+		NetworkInterfaceId: "eni-d83388b1",
+		PrivateIPAddresses: []string{"10.0.0.82", "10.0.0.83"},
+		AllowReassignment:  true,
+	})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"AssignPrivateIpAddresses"})
+	c.Assert(req.Form["NetworkInterfaceId"], DeepEquals, []string{"eni-d83388b1"})
+	c.Assert(req.Form["PrivateIpAddress.1"], DeepEquals, []string{"10.0.0.82"})
+	c.Assert(req.Form["PrivateIpAddress.2"], DeepEquals, []string{"10.0.0.83"})
+	c.Assert(req.Form["SecondaryPrivateIpAddressCount"], IsNil)
+	c.Assert(req.Form["AllowReassignment"], DeepEquals, []string{"true"})
+
+	testServer.Response(200, nil, SimpleNetworkInterfaceExample)
+	_, err = s.ec2.AssignPrivateIpAddresses(&ec2.AssignPrivateIpAddresses{
+		NetworkInterfaceId:             "eni-d83388b1",
+		SecondaryPrivateIPAddressCount: 2,
+	})
+	c.Assert(err, IsNil)
+
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["PrivateIpAddress.1"], IsNil)
+	c.Assert(req.Form["SecondaryPrivateIpAddressCount"], DeepEquals, []string{"2"})
+	c.Assert(req.Form["AllowReassignment"], IsNil)
+}
+
+func (s *S) TestUnassignPrivateIpAddresses(c *C) {
+	testServer.Response(200, nil, SimpleNetworkInterfaceExample)
+
+	_, err := s.ec2.UnassignPrivateIpAddresses("eni-d83388b1", []string{"10.0.0.82"})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"UnassignPrivateIpAddresses"})
+	c.Assert(req.Form["NetworkInterfaceId"], DeepEquals, []string{"eni-d83388b1"})
+	c.Assert(req.Form["PrivateIpAddress.1"], DeepEquals, []string{"10.0.0.82"})
+}
+
+func (s *S) TestWaitUntilNetworkInterfaceAttachment(c *C) {
+	defer func(policy aws.RetryPolicy) { ec2.NetworkInterfaceWaitPolicy = policy }(ec2.NetworkInterfaceWaitPolicy)
+	ec2.NetworkInterfaceWaitPolicy = aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	// Interfaces without attachment are detached
+	testServer.Response(200, nil, DescribeNetworkInterfacesDetachingExample)
+	testServer.Response(200, nil, DescribeNetworkInterfacesAvailableExample)
+
+	iface, err := s.ec2.WaitUntilNetworkInterfaceAttachment("eni-0f62d866", "detached")
+	c.Assert(err, IsNil)
+	c.Assert(iface.Status, Equals, "available")
+	for _, req := range testServer.WaitRequests(2) {
+		c.Assert(req.Form["NetworkInterfaceId.1"], DeepEquals, []string{"eni-0f62d866"})
+	}
+
+	testServer.Response(200, nil, DescribeNetworkInterfacesExample)
+	iface, err = s.ec2.WaitUntilNetworkInterfaceAttachment("eni-0f62d866", "attached")
+	c.Assert(err, IsNil)
+	c.Assert(iface.Attachment.InstanceId, Equals, "i-22197876")
+	testServer.WaitRequest()
+
+	// Attachments not reaching the status after the last poll fail
+	for i := 0; i < 3; i++ {
+		testServer.Response(200, nil, DescribeNetworkInterfacesAvailableExample)
+	}
+	_, err = s.ec2.WaitUntilNetworkInterfaceAttachment("eni-0f62d866", "attached")
+	c.Assert(err, ErrorMatches, "network interface eni-0f62d866 is detached, not attached")
+	testServer.WaitRequests(3)
+}
@@ -341,17 +341,21 @@ var DescribeInstanceStatusExample = `
       <systemStatus>
         <status>ok</status>
 	<details>
-	  <name>reachability</name>
-	  <status>passed</status>
-	  <impairedSince>2010-08-17T01:15:18.000Z</impairedSince>
+	  <item>
+	    <name>reachability</name>
+	    <status>passed</status>
+	    <impairedSince>2010-08-17T01:15:18.000Z</impairedSince>
+	  </item>
 	</details>
       </systemStatus>
       <instanceStatus>
         <status>ok</status>
 	<details>
-	  <name>reachability</name>
-	  <status>passed</status>
-	  <impairedSince>2010-08-17T01:15:18.000Z</impairedSince>
+	  <item>
+	    <name>reachability</name>
+	    <status>passed</status>
+	    <impairedSince>2010-08-17T01:15:18.000Z</impairedSince>
+	  </item>
 	</details>
       </instanceStatus>
     </item>
@@ -810,6 +814,11 @@ var StopInstancesExample = `
 </StopInstancesResponse>
 `
 
+var ErrorRequestExpired = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Response><Errors><Error><Code>RequestExpired</Code><Message>Request has expired.</Message></Error></Errors><RequestID>7c630b0a-6a5a-4cff-a094-0bbd4e5e6b9c</RequestID></Response>
+`
+
 // http://goo.gl/baoUf
 var RebootInstancesExample = `
 <RebootInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2011-12-15/">
@@ -934,8 +943,1202 @@ var DescribeReservedInstancesExample = `
          <instanceTenancy>default</instanceTenancy>
          <currencyCode>USD</currencyCode>
          <offeringType>Light Utilization</offeringType>
-         <recurringCharges/>
+         <offeringClass>standard</offeringClass>
+         <scope>Availability Zone</scope>
+         <recurringCharges>
+            <item>
+               <frequency>Hourly</frequency>
+               <amount>0.01</amount>
+            </item>
+         </recurringCharges>
+         <tagSet>
+            <item>
+               <key>team</key>
+               <value>web</value>
+            </item>
+         </tagSet>
       </item>
    </reservedInstancesSet>
 </DescribeReservedInstancesResponse>
 `
+
+var CreateVpcExample = `
+<CreateVpcResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>7a62c49f-347e-4fc4-9331-6e8eEXAMPLE</requestId>
+   <vpc>
+      <vpcId>vpc-1a2b3c4d</vpcId>
+      <state>pending</state>
+      <cidrBlock>10.0.0.0/16</cidrBlock>
+      <dhcpOptionsId>dopt-1a2b3c4d2</dhcpOptionsId>
+      <instanceTenancy>default</instanceTenancy>
+      <tagSet/>
+   </vpc>
+</CreateVpcResponse>
+`
+
+var DeleteVpcExample = `
+<DeleteVpcResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>7a62c49f-347e-4fc4-9331-6e8eEXAMPLE</requestId>
+   <return>true</return>
+</DeleteVpcResponse>
+`
+
+var DescribeVpcsExample = `
+<DescribeVpcsResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>7a62c49f-347e-4fc4-9331-6e8eEXAMPLE</requestId>
+   <vpcSet>
+      <item>
+         <vpcId>vpc-1a2b3c4d</vpcId>
+         <state>available</state>
+         <cidrBlock>10.0.0.0/23</cidrBlock>
+         <dhcpOptionsId>dopt-7a8b9c2d</dhcpOptionsId>
+         <instanceTenancy>default</instanceTenancy>
+         <isDefault>false</isDefault>
+         <tagSet>
+            <item>
+               <key>Name</key>
+               <value>production</value>
+            </item>
+         </tagSet>
+      </item>
+      <item>
+         <vpcId>vpc-5e6f7a8b</vpcId>
+         <state>available</state>
+         <cidrBlock>172.31.0.0/16</cidrBlock>
+         <dhcpOptionsId>dopt-7a8b9c2d</dhcpOptionsId>
+         <instanceTenancy>default</instanceTenancy>
+         <isDefault>true</isDefault>
+         <tagSet/>
+      </item>
+   </vpcSet>
+</DescribeVpcsResponse>
+`
+
+var CreateSubnetExample = `
+<CreateSubnetResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>7a62c49f-347e-4fc4-9331-6e8eEXAMPLE</requestId>
+   <subnet>
+      <subnetId>subnet-9d4a7b6c</subnetId>
+      <state>pending</state>
+      <vpcId>vpc-1a2b3c4d</vpcId>
+      <cidrBlock>10.0.1.0/24</cidrBlock>
+      <availableIpAddressCount>251</availableIpAddressCount>
+      <availabilityZone>us-east-1a</availabilityZone>
+      <tagSet/>
+   </subnet>
+</CreateSubnetResponse>
+`
+
+var DeleteSubnetExample = `
+<DeleteSubnetResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>7a62c49f-347e-4fc4-9331-6e8eEXAMPLE</requestId>
+   <return>true</return>
+</DeleteSubnetResponse>
+`
+
+var DescribeSubnetsExample = `
+<DescribeSubnetsResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>7a62c49f-347e-4fc4-9331-6e8eEXAMPLE</requestId>
+   <subnetSet>
+      <item>
+         <subnetId>subnet-9d4a7b6c</subnetId>
+         <state>available</state>
+         <vpcId>vpc-1a2b3c4d</vpcId>
+         <cidrBlock>10.0.1.0/24</cidrBlock>
+         <availableIpAddressCount>251</availableIpAddressCount>
+         <availabilityZone>us-east-1a</availabilityZone>
+         <defaultForAz>false</defaultForAz>
+         <mapPublicIpOnLaunch>false</mapPublicIpOnLaunch>
+         <tagSet/>
+      </item>
+      <item>
+         <subnetId>subnet-6e7f829e</subnetId>
+         <state>available</state>
+         <vpcId>vpc-1a2b3c4d</vpcId>
+         <cidrBlock>10.0.0.0/24</cidrBlock>
+         <availableIpAddressCount>251</availableIpAddressCount>
+         <availabilityZone>us-east-1a</availabilityZone>
+         <defaultForAz>false</defaultForAz>
+         <mapPublicIpOnLaunch>true</mapPublicIpOnLaunch>
+         <tagSet>
+            <item>
+               <key>Name</key>
+               <value>public</value>
+            </item>
+         </tagSet>
+      </item>
+   </subnetSet>
+</DescribeSubnetsResponse>
+`
+
+var CreateRouteExample = `
+<CreateRouteResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <return>true</return>
+</CreateRouteResponse>
+`
+
+var DeleteRouteExample = `
+<DeleteRouteResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <return>true</return>
+</DeleteRouteResponse>
+`
+
+var CreateInternetGatewayExample = `
+<CreateInternetGatewayResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <internetGateway>
+      <internetGatewayId>igw-eaad4883</internetGatewayId>
+      <attachmentSet/>
+      <tagSet/>
+   </internetGateway>
+</CreateInternetGatewayResponse>
+`
+
+var AttachInternetGatewayExample = `
+<AttachInternetGatewayResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <return>true</return>
+</AttachInternetGatewayResponse>
+`
+
+var DetachInternetGatewayExample = `
+<DetachInternetGatewayResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <return>true</return>
+</DetachInternetGatewayResponse>
+`
+
+var DeleteInternetGatewayExample = `
+<DeleteInternetGatewayResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <return>true</return>
+</DeleteInternetGatewayResponse>
+`
+
+var DescribeInternetGatewaysExample = `
+<DescribeInternetGatewaysResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <internetGatewaySet>
+      <item>
+         <internetGatewayId>igw-eaad4883EXAMPLE</internetGatewayId>
+         <attachmentSet>
+            <item>
+               <vpcId>vpc-11ad4878</vpcId>
+               <state>available</state>
+            </item>
+         </attachmentSet>
+         <tagSet/>
+      </item>
+   </internetGatewaySet>
+</DescribeInternetGatewaysResponse>
+`
+
+var DescribeSecurityGroupsDescriptionsExample = `
+<DescribeSecurityGroupsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <securityGroupInfo>
+      <item>
+         <ownerId>123456789012</ownerId>
+         <groupId>sg-1a2b3c4d</groupId>
+         <groupName>WebServers</groupName>
+         <groupDescription>Web Servers</groupDescription>
+         <vpcId>vpc-1a2b3c4d</vpcId>
+         <ipPermissions>
+            <item>
+               <ipProtocol>tcp</ipProtocol>
+               <fromPort>443</fromPort>
+               <toPort>443</toPort>
+               <groups>
+                  <item>
+                     <userId>123456789012</userId>
+                     <groupId>sg-5e6f7a8b</groupId>
+                     <description>Load balancers</description>
+                  </item>
+               </groups>
+               <ipRanges>
+                  <item>
+                     <cidrIp>203.0.113.0/24</cidrIp>
+                     <description>Office</description>
+                  </item>
+                  <item>
+                     <cidrIp>198.51.100.0/24</cidrIp>
+                  </item>
+               </ipRanges>
+               <ipv6Ranges>
+                  <item>
+                     <cidrIpv6>2001:db8::/32</cidrIpv6>
+                     <description>Office IPv6</description>
+                  </item>
+               </ipv6Ranges>
+               <prefixListIds/>
+            </item>
+         </ipPermissions>
+         <ipPermissionsEgress>
+            <item>
+               <ipProtocol>-1</ipProtocol>
+               <groups/>
+               <ipRanges/>
+               <ipv6Ranges/>
+               <prefixListIds>
+                  <item>
+                     <prefixListId>pl-12345678</prefixListId>
+                     <description>S3</description>
+                  </item>
+               </prefixListIds>
+            </item>
+         </ipPermissionsEgress>
+      </item>
+   </securityGroupInfo>
+</DescribeSecurityGroupsResponse>
+`
+
+var InvalidPermissionNotFoundErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Response><Errors><Error><Code>InvalidPermission.NotFound</Code>
+<Message>The specified rule does not exist in this security group.</Message>
+</Error></Errors><RequestID>a4ec8f8a-5d5e-4a0c-9a3f-0c4b1EXAMPLE</RequestID></Response>
+`
+
+var RequestSpotInstancesExample = `
+<RequestSpotInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <spotInstanceRequestSet>
+      <item>
+         <spotInstanceRequestId>sir-1a2b3c4d</spotInstanceRequestId>
+         <spotPrice>0.5</spotPrice>
+         <type>one-time</type>
+         <state>open</state>
+         <status>
+            <code>pending-evaluation</code>
+            <updateTime>2014-04-30T18:14:55.000Z</updateTime>
+            <message>Your Spot request has been submitted for review, and is pending evaluation.</message>
+         </status>
+         <availabilityZoneGroup>MyAzGroup</availabilityZoneGroup>
+         <launchSpecification>
+            <imageId>ami-1a2b3c4d</imageId>
+            <keyName>my-key-pair</keyName>
+            <groupSet>
+               <item>
+                  <groupId>sg-1a2b3c4d</groupId>
+                  <groupName>websrv</groupName>
+               </item>
+            </groupSet>
+            <instanceType>m1.small</instanceType>
+            <blockDeviceMapping/>
+            <monitoring>
+               <enabled>false</enabled>
+            </monitoring>
+            <ebsOptimized>false</ebsOptimized>
+         </launchSpecification>
+         <createTime>2014-04-30T18:14:55.000Z</createTime>
+         <productDescription>Linux/UNIX</productDescription>
+      </item>
+   </spotInstanceRequestSet>
+</RequestSpotInstancesResponse>
+`
+
+var DescribeSpotInstanceRequestsExample = `
+<DescribeSpotInstanceRequestsResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>b1719f2a-5334-4479-b2f1-26926EXAMPLE</requestId>
+   <spotInstanceRequestSet>
+      <item>
+         <spotInstanceRequestId>sir-1a2b3c4d</spotInstanceRequestId>
+         <spotPrice>0.09</spotPrice>
+         <type>one-time</type>
+         <state>active</state>
+         <status>
+            <code>fulfilled</code>
+            <updateTime>2014-04-30T18:16:21.000Z</updateTime>
+            <message>Your Spot request is fulfilled.</message>
+         </status>
+         <launchSpecification>
+            <imageId>ami-7aba833f</imageId>
+            <keyName>my-key-pair</keyName>
+            <groupSet>
+               <item>
+                  <groupId>sg-e38f24a7</groupId>
+                  <groupName>websrv</groupName>
+               </item>
+            </groupSet>
+            <instanceType>m1.small</instanceType>
+            <placement>
+               <availabilityZone>us-west-1b</availabilityZone>
+            </placement>
+            <monitoring>
+               <enabled>false</enabled>
+            </monitoring>
+            <ebsOptimized>false</ebsOptimized>
+         </launchSpecification>
+         <instanceId>i-1a2b3c4d</instanceId>
+         <createTime>2014-04-30T18:14:55.000Z</createTime>
+         <productDescription>Linux/UNIX</productDescription>
+         <tagSet>
+            <item>
+               <key>batch</key>
+               <value>nightly</value>
+            </item>
+         </tagSet>
+         <launchedAvailabilityZone>us-west-1b</launchedAvailabilityZone>
+      </item>
+   </spotInstanceRequestSet>
+</DescribeSpotInstanceRequestsResponse>
+`
+
+var DescribeSpotInstanceRequestsPriceTooLowExample = `
+<DescribeSpotInstanceRequestsResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>b1719f2a-5334-4479-b2f1-26926EXAMPLE</requestId>
+   <spotInstanceRequestSet>
+      <item>
+         <spotInstanceRequestId>sir-1a2b3c4d</spotInstanceRequestId>
+         <spotPrice>0.001</spotPrice>
+         <type>one-time</type>
+         <state>open</state>
+         <status>
+            <code>price-too-low</code>
+            <updateTime>2014-04-30T18:16:21.000Z</updateTime>
+            <message>Your Spot request price of 0.001 is lower than the minimum required Spot request fulfillment price of 0.0083.</message>
+         </status>
+         <createTime>2014-04-30T18:14:55.000Z</createTime>
+         <productDescription>Linux/UNIX</productDescription>
+      </item>
+   </spotInstanceRequestSet>
+</DescribeSpotInstanceRequestsResponse>
+`
+
+var DescribeSpotInstanceRequestsCancelledExample = `
+<DescribeSpotInstanceRequestsResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>b1719f2a-5334-4479-b2f1-26926EXAMPLE</requestId>
+   <spotInstanceRequestSet>
+      <item>
+         <spotInstanceRequestId>sir-1a2b3c4d</spotInstanceRequestId>
+         <spotPrice>0.09</spotPrice>
+         <type>one-time</type>
+         <state>cancelled</state>
+         <status>
+            <code>canceled-before-fulfillment</code>
+            <updateTime>2014-04-30T18:16:21.000Z</updateTime>
+            <message>The Spot request was canceled before fulfillment.</message>
+         </status>
+      </item>
+   </spotInstanceRequestSet>
+</DescribeSpotInstanceRequestsResponse>
+`
+
+var CancelSpotInstanceRequestsExample = `
+<CancelSpotInstanceRequestsResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <spotInstanceRequestSet>
+      <item>
+         <spotInstanceRequestId>sir-1a2b3c4d</spotInstanceRequestId>
+         <state>cancelled</state>
+      </item>
+   </spotInstanceRequestSet>
+</CancelSpotInstanceRequestsResponse>
+`
+
+var DescribeSpotPriceHistoryExample = `
+<DescribeSpotPriceHistoryResponse xmlns="http://ec2.amazonaws.com/doc/2014-06-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <spotPriceHistorySet>
+      <item>
+         <instanceType>m3.medium</instanceType>
+         <productDescription>Linux/UNIX</productDescription>
+         <spotPrice>0.0100</spotPrice>
+         <timestamp>2014-01-06T04:32:53.000Z</timestamp>
+         <availabilityZone>us-west-2a</availabilityZone>
+      </item>
+      <item>
+         <instanceType>m3.medium</instanceType>
+         <productDescription>Linux/UNIX</productDescription>
+         <spotPrice>0.0105</spotPrice>
+         <timestamp>2014-01-05T11:28:26.000Z</timestamp>
+         <availabilityZone>us-west-2b</availabilityZone>
+      </item>
+   </spotPriceHistorySet>
+   <nextToken>token1</nextToken>
+</DescribeSpotPriceHistoryResponse>
+`
+
+var DescribeSnapshotsCompletedExample = `
+<DescribeSnapshotsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <snapshotSet>
+      <item>
+         <snapshotId>snap-1a2b3c4d</snapshotId>
+         <volumeId>vol-8875daef</volumeId>
+         <status>completed</status>
+         <startTime>2010-07-29T04:12:01.000Z</startTime>
+         <progress>100%</progress>
+         <ownerId>111122223333</ownerId>
+         <volumeSize>15</volumeSize>
+         <description>Daily Backup</description>
+         <encrypted>true</encrypted>
+         <kmsKeyId>arn:aws:kms:us-east-1:111122223333:key/bc3ca4a5-1a2b-3c4d-86e0-54ef3EXAMPLE</kmsKeyId>
+      </item>
+   </snapshotSet>
+   <nextToken>token1</nextToken>
+</DescribeSnapshotsResponse>
+`
+
+var DescribeSnapshotsErrorStateExample = `
+<DescribeSnapshotsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <snapshotSet>
+      <item>
+         <snapshotId>snap-1a2b3c4d</snapshotId>
+         <volumeId>vol-8875daef</volumeId>
+         <status>error</status>
+         <stateMessage>Client.InternalError: The snapshot could not be completed</stateMessage>
+         <progress>30%</progress>
+      </item>
+   </snapshotSet>
+</DescribeSnapshotsResponse>
+`
+
+var InvalidSnapshotInUseErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Response><Errors><Error><Code>InvalidSnapshot.InUse</Code>
+<Message>The snapshot snap-1a2b3c4d is currently in use by ami-1a2b3c4d</Message>
+</Error></Errors><RequestID>5a4a6f5c-1f16-4d82-8b3e-4a6e5EXAMPLE</RequestID></Response>
+`
+
+var CopySnapshotExample = `
+<CopySnapshotResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>60bc441d-fa2c-494d-b155-5d6a3EXAMPLE</requestId>
+   <snapshotId>snap-2a2b3c4d</snapshotId>
+</CopySnapshotResponse>
+`
+
+var CreateVolumeExample = `
+<CreateVolumeResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <volumeId>vol-1a2b3c4d</volumeId>
+   <size>80</size>
+   <snapshotId>snap-1a2b3c4d</snapshotId>
+   <availabilityZone>us-east-1a</availabilityZone>
+   <status>creating</status>
+   <createTime>2014-05-12T22:19:44.000Z</createTime>
+   <volumeType>io1</volumeType>
+   <iops>3000</iops>
+   <encrypted>true</encrypted>
+   <kmsKeyId>arn:aws:kms:us-east-1:111122223333:key/bc3ca4a5-1a2b-3c4d-86e0-54ef3EXAMPLE</kmsKeyId>
+</CreateVolumeResponse>
+`
+
+var AttachVolumeExample = `
+<AttachVolumeResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <volumeId>vol-1a2b3c4d</volumeId>
+   <instanceId>i-1a2b3c4d</instanceId>
+   <device>/dev/sdh</device>
+   <status>attaching</status>
+   <attachTime>2014-05-12T22:25:04.000Z</attachTime>
+</AttachVolumeResponse>
+`
+
+var DetachVolumeExample = `
+<DetachVolumeResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <volumeId>vol-1a2b3c4d</volumeId>
+   <instanceId>i-1a2b3c4d</instanceId>
+   <device>/dev/sdh</device>
+   <status>detaching</status>
+   <attachTime>2014-05-12T22:25:04.000Z</attachTime>
+</DetachVolumeResponse>
+`
+
+var DescribeVolumesExample = `
+<DescribeVolumesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <volumeSet>
+      <item>
+         <volumeId>vol-1a2b3c4d</volumeId>
+         <size>80</size>
+         <snapshotId/>
+         <availabilityZone>us-east-1a</availabilityZone>
+         <status>in-use</status>
+         <createTime>2014-05-12T22:19:44.000Z</createTime>
+         <attachmentSet>
+            <item>
+               <volumeId>vol-1a2b3c4d</volumeId>
+               <instanceId>i-1a2b3c4d</instanceId>
+               <device>/dev/sdh</device>
+               <status>attached</status>
+               <attachTime>2014-05-12T22:25:04.000Z</attachTime>
+               <deleteOnTermination>false</deleteOnTermination>
+            </item>
+         </attachmentSet>
+         <volumeType>gp2</volumeType>
+         <iops>240</iops>
+         <encrypted>false</encrypted>
+      </item>
+   </volumeSet>
+</DescribeVolumesResponse>
+`
+
+var RequestLimitExceededErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Response><Errors><Error><Code>RequestLimitExceeded</Code>
+<Message>Request limit exceeded.</Message>
+</Error></Errors><RequestID>8f7724cf-496f-496e-8fe3-5bc0aEXAMPLE</RequestID></Response>
+`
+
+var DeregisterImageExample = `
+<DeregisterImageResponse xmlns="http://ec2.amazonaws.com/doc/2014-02-01/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <return>true</return>
+</DeregisterImageResponse>
+`
+
+var InvalidAMIIDNotFoundErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Response><Errors><Error><Code>InvalidAMIID.NotFound</Code>
+<Message>The image id '[ami-4fa54026]' does not exist</Message>
+</Error></Errors><RequestID>c2eb7af3-a2a6-4b5e-8a0f-8f1d3EXAMPLE</RequestID></Response>
+`
+
+var DescribeImagesPendingExample = `
+<DescribeImagesResponse xmlns="http://ec2.amazonaws.com/doc/2014-02-01/">
+   <requestId>4a4a27a2-2e7c-475d-b35b-ca822EXAMPLE</requestId>
+   <imagesSet>
+      <item>
+         <imageId>ami-4fa54026</imageId>
+         <imageState>pending</imageState>
+         <imageOwnerId>123456789012</imageOwnerId>
+         <name>standard-web-server</name>
+         <rootDeviceType>ebs</rootDeviceType>
+      </item>
+   </imagesSet>
+</DescribeImagesResponse>
+`
+
+var DescribeImagesAvailableExample = `
+<DescribeImagesResponse xmlns="http://ec2.amazonaws.com/doc/2014-02-01/">
+   <requestId>4a4a27a2-2e7c-475d-b35b-ca822EXAMPLE</requestId>
+   <imagesSet>
+      <item>
+         <imageId>ami-4fa54026</imageId>
+         <imageState>available</imageState>
+         <imageOwnerId>123456789012</imageOwnerId>
+         <name>standard-web-server</name>
+         <rootDeviceType>ebs</rootDeviceType>
+      </item>
+   </imagesSet>
+</DescribeImagesResponse>
+`
+
+var DescribeImagesFailedExample = `
+<DescribeImagesResponse xmlns="http://ec2.amazonaws.com/doc/2014-02-01/">
+   <requestId>4a4a27a2-2e7c-475d-b35b-ca822EXAMPLE</requestId>
+   <imagesSet>
+      <item>
+         <imageId>ami-4fa54026</imageId>
+         <imageState>failed</imageState>
+         <imageOwnerId>123456789012</imageOwnerId>
+         <name>standard-web-server</name>
+         <stateReason>
+            <code>Client.InvalidSnapshot.NotFound</code>
+            <message>Client.InvalidSnapshot.NotFound: snapshot snap-1a2b3c4d does not exist</message>
+         </stateReason>
+         <rootDeviceType>ebs</rootDeviceType>
+      </item>
+   </imagesSet>
+</DescribeImagesResponse>
+`
+
+var DescribeInstanceAttributeUserDataExample = `
+<DescribeInstanceAttributeResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <instanceId>i-10a64379</instanceId>
+   <userData>
+      <value>IyEvYmluL3NoCmVjaG8gaGVsbG8K</value>
+   </userData>
+</DescribeInstanceAttributeResponse>
+`
+
+var DescribeInstanceAttributeGroupSetExample = `
+<DescribeInstanceAttributeResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <instanceId>i-10a64379</instanceId>
+   <groupSet>
+      <item>
+         <groupId>sg-1a2b3c4d</groupId>
+      </item>
+      <item>
+         <groupId>sg-2a2b3c4d</groupId>
+      </item>
+   </groupSet>
+</DescribeInstanceAttributeResponse>
+`
+
+var DescribeInstanceAttributeSourceDestCheckExample = `
+<DescribeInstanceAttributeResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+   <instanceId>i-10a64379</instanceId>
+   <sourceDestCheck>
+      <value>true</value>
+   </sourceDestCheck>
+</DescribeInstanceAttributeResponse>
+`
+
+var ResourceAlreadyAssociatedErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Response><Errors><Error><Code>Resource.AlreadyAssociated</Code>
+<Message>resource eipalloc-5723d13e is already associated with associate-id eipassoc-fc5ca095</Message>
+</Error></Errors><RequestID>2ee5f1a4-7c2b-4c5e-9d3b-8a1f0EXAMPLE</RequestID></Response>
+`
+
+// https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AssociateIamInstanceProfile.html
+var AssociateIamInstanceProfileExample = `
+<AssociateIamInstanceProfileResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>e10deeaf-7cda-48e7-950b-example</requestId>
+   <iamInstanceProfileAssociation>
+      <associationId>iip-assoc-0e7736511a163c209</associationId>
+      <iamInstanceProfile>
+         <arn>arn:aws:iam::123456789012:instance-profile/admin-role</arn>
+         <id>AIPAJBLK7RKJKWDXVHIEC</id>
+      </iamInstanceProfile>
+      <instanceId>i-1234567890abcdef0</instanceId>
+      <state>associating</state>
+   </iamInstanceProfileAssociation>
+</AssociateIamInstanceProfileResponse>
+`
+
+// https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ReplaceIamInstanceProfileAssociation.html
+var ReplaceIamInstanceProfileAssociationExample = `
+<ReplaceIamInstanceProfileAssociationResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>e10deeaf-7cda-48e7-950b-example</requestId>
+   <iamInstanceProfileAssociation>
+      <associationId>iip-assoc-08049da59357d598c</associationId>
+      <iamInstanceProfile>
+         <arn>arn:aws:iam::123456789012:instance-profile/admin-role</arn>
+         <id>AIPAJ2TNUXJAAX4UKBOSI</id>
+      </iamInstanceProfile>
+      <instanceId>i-1234567890abcdef0</instanceId>
+      <state>associating</state>
+   </iamInstanceProfileAssociation>
+</ReplaceIamInstanceProfileAssociationResponse>
+`
+
+// https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DisassociateIamInstanceProfile.html
+var DisassociateIamInstanceProfileExample = `
+<DisassociateIamInstanceProfileResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>e10deeaf-7cda-48e7-950b-example</requestId>
+   <iamInstanceProfileAssociation>
+      <associationId>iip-assoc-05020b59952902f5f</associationId>
+      <iamInstanceProfile>
+         <arn>arn:aws:iam::123456789012:instance-profile/admin-role</arn>
+         <id>AIPAI5IVIHMFFYY2DKV5Y</id>
+      </iamInstanceProfile>
+      <instanceId>i-123456789abcde123</instanceId>
+      <state>disassociating</state>
+   </iamInstanceProfileAssociation>
+</DisassociateIamInstanceProfileResponse>
+`
+
+// https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeIamInstanceProfileAssociations.html
+var DescribeIamInstanceProfileAssociationsExample = `
+<DescribeIamInstanceProfileAssociationsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>84c2d2a6-12dc-491f-a9ee-example</requestId>
+   <iamInstanceProfileAssociationSet>
+      <item>
+         <associationId>iip-assoc-0db249b1f25fa24b8</associationId>
+         <iamInstanceProfile>
+            <arn>arn:aws:iam::123456789012:instance-profile/admin-role</arn>
+            <id>AIPAJVQN4F5WVLGCJDRGM</id>
+         </iamInstanceProfile>
+         <instanceId>i-09eb09efa73ec1dee</instanceId>
+         <state>associated</state>
+         <timestamp>2017-01-23T16:05:52.000Z</timestamp>
+      </item>
+   </iamInstanceProfileAssociationSet>
+</DescribeIamInstanceProfileAssociationsResponse>
+`
+
+var DescribeInstancesBlockDevicesExample = `
+<DescribeInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>8f7724cf-496f-496e-8fe3-5bc0aEXAMPLE</requestId>
+   <reservationSet>
+      <item>
+         <reservationId>r-1a2b3c4d</reservationId>
+         <ownerId>123456789012</ownerId>
+         <instancesSet>
+            <item>
+               <instanceId>i-1a2b3c4d</instanceId>
+               <rootDeviceType>ebs</rootDeviceType>
+               <rootDeviceName>/dev/xvda</rootDeviceName>
+               <blockDeviceMapping>
+                  <item>
+                     <deviceName>/dev/xvda</deviceName>
+                     <ebs>
+                        <volumeId>vol-1a2b3c4d</volumeId>
+                        <status>attached</status>
+                        <attachTime>2016-11-02T21:12:38.000Z</attachTime>
+                        <deleteOnTermination>true</deleteOnTermination>
+                     </ebs>
+                  </item>
+                  <item>
+                     <deviceName>/dev/sdf</deviceName>
+                     <ebs>
+                        <volumeId>vol-2a2b3c4d</volumeId>
+                        <status>attaching</status>
+                        <attachTime>2016-11-02T21:12:39.000Z</attachTime>
+                        <deleteOnTermination>false</deleteOnTermination>
+                     </ebs>
+                  </item>
+               </blockDeviceMapping>
+            </item>
+         </instancesSet>
+      </item>
+   </reservationSet>
+</DescribeInstancesResponse>
+`
+
+var DescribeImagesBlockDevicesExample = `
+<DescribeImagesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>4a4a27a2-2e7c-475d-b35b-ca822EXAMPLE</requestId>
+   <imagesSet>
+      <item>
+         <imageId>ami-1a2b3c4d</imageId>
+         <imageState>available</imageState>
+         <blockDeviceMapping>
+            <item>
+               <deviceName>/dev/xvda</deviceName>
+               <ebs>
+                  <snapshotId>snap-1a2b3c4d</snapshotId>
+                  <volumeSize>8</volumeSize>
+                  <deleteOnTermination>true</deleteOnTermination>
+                  <volumeType>io1</volumeType>
+                  <iops>400</iops>
+                  <encrypted>true</encrypted>
+                  <kmsKeyId>arn:aws:kms:us-east-1:123456789012:key/abcd1234-a123-456a-a12b-a123b4cd56ef</kmsKeyId>
+               </ebs>
+            </item>
+            <item>
+               <deviceName>/dev/sdb</deviceName>
+               <virtualName>ephemeral0</virtualName>
+            </item>
+            <item>
+               <deviceName>/dev/sdc</deviceName>
+               <noDevice/>
+            </item>
+         </blockDeviceMapping>
+      </item>
+   </imagesSet>
+</DescribeImagesResponse>
+`
+
+// https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateNetworkInterface.html
+var CreateNetworkInterfaceExample = `
+<CreateNetworkInterfaceResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>8dbe591e-5a22-48cb-b948-dd0aadd55adf</requestId>
+   <networkInterface>
+      <networkInterfaceId>eni-cfca76a6</networkInterfaceId>
+      <subnetId>subnet-b61f49f0</subnetId>
+      <vpcId>vpc-c31dafaa</vpcId>
+      <availabilityZone>ap-southeast-1b</availabilityZone>
+      <description>failover</description>
+      <ownerId>251839141158</ownerId>
+      <requesterManaged>false</requesterManaged>
+      <status>pending</status>
+      <macAddress>02:74:b0:72:79:61</macAddress>
+      <privateIpAddress>10.0.2.157</privateIpAddress>
+      <sourceDestCheck>true</sourceDestCheck>
+      <groupSet>
+         <item>
+            <groupId>sg-1a2b3c4d</groupId>
+            <groupName>default</groupName>
+         </item>
+      </groupSet>
+      <tagSet/>
+      <privateIpAddressesSet>
+         <item>
+            <privateIpAddress>10.0.2.157</privateIpAddress>
+            <primary>true</primary>
+         </item>
+         <item>
+            <privateIpAddress>10.0.2.158</privateIpAddress>
+            <primary>false</primary>
+         </item>
+      </privateIpAddressesSet>
+   </networkInterface>
+</CreateNetworkInterfaceResponse>
+`
+
+// https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeNetworkInterfaces.html
+var DescribeNetworkInterfacesExample = `
+<DescribeNetworkInterfacesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>fc45294c-006b-457b-bab9-012f5b3b0e40</requestId>
+   <networkInterfaceSet>
+      <item>
+         <networkInterfaceId>eni-0f62d866</networkInterfaceId>
+         <subnetId>subnet-c53c87ac</subnetId>
+         <vpcId>vpc-cc3c87a5</vpcId>
+         <availabilityZone>ap-southeast-1b</availabilityZone>
+         <description/>
+         <ownerId>053230519467</ownerId>
+         <requesterManaged>false</requesterManaged>
+         <status>in-use</status>
+         <macAddress>02:81:60:cb:27:37</macAddress>
+         <privateIpAddress>10.0.0.146</privateIpAddress>
+         <sourceDestCheck>true</sourceDestCheck>
+         <groupSet>
+            <item>
+               <groupId>sg-3f4b5653</groupId>
+               <groupName>default</groupName>
+            </item>
+         </groupSet>
+         <attachment>
+            <attachmentId>eni-attach-6537fc0c</attachmentId>
+            <instanceId>i-22197876</instanceId>
+            <instanceOwnerId>053230519467</instanceOwnerId>
+            <deviceIndex>1</deviceIndex>
+            <status>attached</status>
+            <attachTime>2012-07-01T21:45:27.000Z</attachTime>
+            <deleteOnTermination>false</deleteOnTermination>
+         </attachment>
+         <tagSet/>
+         <privateIpAddressesSet>
+            <item>
+               <privateIpAddress>10.0.0.146</privateIpAddress>
+               <primary>true</primary>
+            </item>
+            <item>
+               <privateIpAddress>10.0.0.148</privateIpAddress>
+               <primary>false</primary>
+            </item>
+         </privateIpAddressesSet>
+      </item>
+   </networkInterfaceSet>
+</DescribeNetworkInterfacesResponse>
+`
+
+var DescribeNetworkInterfacesDetachingExample = `
+<DescribeNetworkInterfacesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>fc45294c-006b-457b-bab9-012f5b3b0e40</requestId>
+   <networkInterfaceSet>
+      <item>
+         <networkInterfaceId>eni-0f62d866</networkInterfaceId>
+         <status>in-use</status>
+         <attachment>
+            <attachmentId>eni-attach-6537fc0c</attachmentId>
+            <instanceId>i-22197876</instanceId>
+            <deviceIndex>1</deviceIndex>
+            <status>detaching</status>
+         </attachment>
+      </item>
+   </networkInterfaceSet>
+</DescribeNetworkInterfacesResponse>
+`
+
+var DescribeNetworkInterfacesAvailableExample = `
+<DescribeNetworkInterfacesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>fc45294c-006b-457b-bab9-012f5b3b0e40</requestId>
+   <networkInterfaceSet>
+      <item>
+         <networkInterfaceId>eni-0f62d866</networkInterfaceId>
+         <status>available</status>
+      </item>
+   </networkInterfaceSet>
+</DescribeNetworkInterfacesResponse>
+`
+
+var AttachNetworkInterfaceExample = `
+<AttachNetworkInterfaceResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>ace8cd1e-e685-4e44-90fb-92014d907212</requestId>
+   <attachmentId>eni-attach-d94b09b0</attachmentId>
+</AttachNetworkInterfaceResponse>
+`
+
+var SimpleNetworkInterfaceExample = `
+<DetachNetworkInterfaceResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>ce540707-0635-46bc-97da-33a8a362a0e8</requestId>
+   <return>true</return>
+</DetachNetworkInterfaceResponse>
+`
+
+var DescribeInstanceStatusEventsPage1 = `
+<DescribeInstanceStatusResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>3be1508e-c444-4fef-89cc-0b1223c4f02fEXAMPLE</requestId>
+   <instanceStatusSet>
+      <item>
+         <instanceId>i-1</instanceId>
+         <availabilityZone>us-east-1b</availabilityZone>
+         <instanceState>
+            <code>16</code>
+            <name>running</name>
+         </instanceState>
+         <systemStatus>
+            <status>ok</status>
+            <details>
+               <item>
+                  <name>reachability</name>
+                  <status>passed</status>
+               </item>
+            </details>
+         </systemStatus>
+         <instanceStatus>
+            <status>ok</status>
+            <details>
+               <item>
+                  <name>reachability</name>
+                  <status>passed</status>
+               </item>
+            </details>
+         </instanceStatus>
+         <eventsSet>
+            <item>
+               <instanceEventId>instance-event-0d59937288b749b32</instanceEventId>
+               <code>instance-retirement</code>
+               <description>The instance is running on degraded hardware</description>
+               <notBefore>2016-11-24T00:00:00.000Z</notBefore>
+            </item>
+         </eventsSet>
+      </item>
+      <item>
+         <instanceId>i-2</instanceId>
+         <availabilityZone>us-east-1b</availabilityZone>
+         <instanceState>
+            <code>80</code>
+            <name>stopped</name>
+         </instanceState>
+         <systemStatus>
+            <status>not-applicable</status>
+         </systemStatus>
+         <instanceStatus>
+            <status>not-applicable</status>
+         </instanceStatus>
+      </item>
+   </instanceStatusSet>
+   <nextToken>page2</nextToken>
+</DescribeInstanceStatusResponse>
+`
+
+var DescribeInstanceStatusEventsPage2 = `
+<DescribeInstanceStatusResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>3be1508e-c444-4fef-89cc-0b1223c4f02fEXAMPLE</requestId>
+   <instanceStatusSet>
+      <item>
+         <instanceId>i-3</instanceId>
+         <availabilityZone>us-east-1b</availabilityZone>
+         <instanceState>
+            <code>16</code>
+            <name>running</name>
+         </instanceState>
+         <eventsSet>
+            <item>
+               <code>instance-reboot</code>
+               <description>[Completed] Scheduled reboot</description>
+               <notBefore>2016-10-01T00:00:00.000Z</notBefore>
+               <notAfter>2016-10-01T02:00:00.000Z</notAfter>
+            </item>
+         </eventsSet>
+      </item>
+      <item>
+         <instanceId>i-4</instanceId>
+         <availabilityZone>us-east-1b</availabilityZone>
+         <instanceState>
+            <code>16</code>
+            <name>running</name>
+         </instanceState>
+         <systemStatus>
+            <status>impaired</status>
+            <details>
+               <item>
+                  <name>reachability</name>
+                  <status>failed</status>
+                  <impairedSince>2016-11-20T10:00:00.000Z</impairedSince>
+               </item>
+            </details>
+         </systemStatus>
+         <eventsSet>
+            <item>
+               <code>system-maintenance</code>
+               <description>[Canceled] Scheduled network maintenance</description>
+               <notBefore>2016-11-01T00:00:00.000Z</notBefore>
+            </item>
+            <item>
+               <code>system-maintenance</code>
+               <description>Scheduled power maintenance</description>
+               <notBefore>2016-12-01T00:00:00.000Z</notBefore>
+               <notAfter>2016-12-01T06:00:00.000Z</notAfter>
+            </item>
+         </eventsSet>
+      </item>
+   </instanceStatusSet>
+</DescribeInstanceStatusResponse>
+`
+
+var InvalidInstanceIDNotFoundErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Response><Errors><Error><Code>InvalidInstanceID.NotFound</Code>
+<Message>The instance ID 'i-1' does not exist</Message>
+</Error></Errors><RequestID>b1d5c1e4-53b2-4f6c-91a5-7c4f3EXAMPLE</RequestID></Response>
+`
+
+var DescribeInstancesTerminatedExample = `
+<DescribeInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>8f7724cf-496f-496e-8fe3-5bc0aEXAMPLE</requestId>
+   <reservationSet>
+      <item>
+         <reservationId>r-1a2b3c4d</reservationId>
+         <instancesSet>
+            <item>
+               <instanceId>i-1</instanceId>
+               <instanceState>
+                  <code>48</code>
+                  <name>terminated</name>
+               </instanceState>
+               <stateReason>
+                  <code>Server.InternalError</code>
+                  <message>Server.InternalError: Internal error on launch</message>
+               </stateReason>
+            </item>
+         </instancesSet>
+      </item>
+   </reservationSet>
+</DescribeInstancesResponse>
+`
+
+// https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteTags.html
+var DeleteTagsExample = `
+<DeleteTagsResponse xmlns="http://ec2.amazonaws.com/doc/2014-02-01/">
+   <requestId>7a62c49f-347e-4fc4-9331-6e8eEXAMPLE</requestId>
+   <return>true</return>
+</DeleteTagsResponse>
+`
+
+// https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeTags.html
+var DescribeTagsPage1 = `
+<DescribeTagsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>7a62c49f-347e-4fc4-9331-6e8eEXAMPLE</requestId>
+   <tagSet>
+      <item>
+         <resourceId>i-5f4e3d2a</resourceId>
+         <resourceType>instance</resourceType>
+         <key>webserver</key>
+         <value/>
+      </item>
+      <item>
+         <resourceId>i-5f4e3d2a</resourceId>
+         <resourceType>instance</resourceType>
+         <key>stack</key>
+         <value>Production</value>
+      </item>
+   </tagSet>
+   <nextToken>token-page-2</nextToken>
+</DescribeTagsResponse>
+`
+
+var DescribeTagsPage2 = `
+<DescribeTagsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>7a62c49f-347e-4fc4-9331-6e8eEXAMPLE</requestId>
+   <tagSet>
+      <item>
+         <resourceId>i-12345678</resourceId>
+         <resourceType>instance</resourceType>
+         <key>database_server</key>
+         <value/>
+      </item>
+   </tagSet>
+</DescribeTagsResponse>
+`
+
+// https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeReservedInstancesOfferings.html
+var DescribeReservedInstancesOfferingsPage1 = `
+<DescribeReservedInstancesOfferingsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>2bc7dafa-dafd-4257-bdf9-c0814EXAMPLE</requestId>
+   <reservedInstancesOfferingsSet>
+      <item>
+         <reservedInstancesOfferingId>a6ce8269-7b8c-42cd-a7f5-0841cEXAMPLE</reservedInstancesOfferingId>
+         <instanceType>m4.large</instanceType>
+         <availabilityZone>us-east-1a</availabilityZone>
+         <duration>31536000</duration>
+         <fixedPrice>0.0</fixedPrice>
+         <usagePrice>0.0</usagePrice>
+         <productDescription>Linux/UNIX</productDescription>
+         <instanceTenancy>default</instanceTenancy>
+         <currencyCode>USD</currencyCode>
+         <offeringType>No Upfront</offeringType>
+         <offeringClass>convertible</offeringClass>
+         <scope>Availability Zone</scope>
+         <recurringCharges>
+            <item>
+               <frequency>Hourly</frequency>
+               <amount>0.07</amount>
+            </item>
+         </recurringCharges>
+         <marketplace>false</marketplace>
+         <pricingDetailsSet/>
+      </item>
+      <item>
+         <reservedInstancesOfferingId>649fd0c8-5d76-4881-a522-fe5224c10fcc</reservedInstancesOfferingId>
+         <instanceType>m4.large</instanceType>
+         <availabilityZone>us-east-1a</availabilityZone>
+         <duration>31536000</duration>
+         <fixedPrice>700.0</fixedPrice>
+         <usagePrice>0.0</usagePrice>
+         <productDescription>Linux/UNIX</productDescription>
+         <instanceTenancy>default</instanceTenancy>
+         <currencyCode>USD</currencyCode>
+         <offeringType>Partial Upfront</offeringType>
+         <offeringClass>standard</offeringClass>
+         <scope>Availability Zone</scope>
+         <recurringCharges>
+            <item>
+               <frequency>Hourly</frequency>
+               <amount>0.02</amount>
+            </item>
+         </recurringCharges>
+         <marketplace>true</marketplace>
+         <pricingDetailsSet>
+            <item>
+               <price>700.0</price>
+               <count>2</count>
+            </item>
+            <item>
+               <price>725.0</price>
+               <count>1</count>
+            </item>
+         </pricingDetailsSet>
+      </item>
+   </reservedInstancesOfferingsSet>
+   <nextToken>token-page-2</nextToken>
+</DescribeReservedInstancesOfferingsResponse>
+`
+
+var DescribeReservedInstancesOfferingsPage2 = `
+<DescribeReservedInstancesOfferingsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>2bc7dafa-dafd-4257-bdf9-c0814EXAMPLE</requestId>
+   <reservedInstancesOfferingsSet>
+      <item>
+         <reservedInstancesOfferingId>9a0c4f5e-0d5b-4a0b-8e3c-2a2b1EXAMPLE</reservedInstancesOfferingId>
+         <instanceType>m4.large</instanceType>
+         <availabilityZone>us-east-1b</availabilityZone>
+         <duration>94608000</duration>
+         <fixedPrice>1500.0</fixedPrice>
+         <usagePrice>0.0</usagePrice>
+         <productDescription>Linux/UNIX</productDescription>
+         <instanceTenancy>default</instanceTenancy>
+         <currencyCode>USD</currencyCode>
+         <offeringType>All Upfront</offeringType>
+         <offeringClass>standard</offeringClass>
+         <scope>Availability Zone</scope>
+         <recurringCharges/>
+         <marketplace>false</marketplace>
+         <pricingDetailsSet/>
+      </item>
+   </reservedInstancesOfferingsSet>
+   <nextToken>token-page-3</nextToken>
+</DescribeReservedInstancesOfferingsResponse>
+`
+
+// https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_ModifyReservedInstances.html
+var ModifyReservedInstancesExample = `
+<ModifyReservedInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+   <requestId>bef729b6-0731-4489-8881-2258example</requestId>
+   <reservedInstancesModificationId>rimod-3aae219d-3d63-47a9-a7e9-e764example</reservedInstancesModificationId>
+</ModifyReservedInstancesResponse>
+`
@@ -22,6 +22,19 @@ func sign(auth aws.Auth, method, path string, params map[string]string, host str
 		params["SecurityToken"] = auth.Token()
 	}
 
+	payload := method + "\n" + host + "\n" + path + "\n" + canonicalQuery(params)
+	hash := hmac.New(sha256.New, []byte(auth.SecretKey))
+	hash.Write([]byte(payload))
+	signature := make([]byte, b64.EncodedLen(hash.Size()))
+	b64.Encode(signature, hash.Sum(nil))
+
+	params["Signature"] = string(signature)
+}
+
+// canonicalQuery returns the query string of params as it is signed,
+// which is also the query string sent so that values with spaces or
+// non-ASCII characters reach EC2 exactly as they were signed.
+func canonicalQuery(params map[string]string) string {
 	// AWS specifies that the parameters in a signed request must
 	// be provided in the natural order of the keys. This is distinct
 	// from the natural order of the encoded value of key=value.
@@ -34,12 +47,5 @@ func sign(auth aws.Auth, method, path string, params map[string]string, host str
 	for _, k := range keys {
 		sarray = append(sarray, aws.Encode(k)+"="+aws.Encode(params[k]))
 	}
-	joined := strings.Join(sarray, "&")
-	payload := method + "\n" + host + "\n" + path + "\n" + joined
-	hash := hmac.New(sha256.New, []byte(auth.SecretKey))
-	hash.Write([]byte(payload))
-	signature := make([]byte, b64.EncodedLen(hash.Size()))
-	b64.Encode(signature, hash.Sum(nil))
-
-	params["Signature"] = string(signature)
+	return strings.Join(sarray, "&")
 }
@@ -0,0 +1,310 @@
+package ec2
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+)
+
+// RequestSpotInstancesOptions encapsulates options for a
+// RequestSpotInstances request. LaunchSpecification describes the
+// instances to launch as for RunInstances, without their counts,
+// tenancy, termination protection, shutdown behavior and private address.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_RequestSpotInstances.html for more details.
+type RequestSpotInstancesOptions struct {
+	SpotPrice             string // The maximum hourly price, e.g. "0.05"
+	InstanceCount         int    // The number of instances, 1 if zero
+	Type                  string // Valid values: one-time | persistent
+	ValidFrom             time.Time
+	ValidUntil            time.Time
+	LaunchGroup           string // Launch or terminate the instances of the requests with this group together
+	AvailabilityZoneGroup string // Launch the instances of the requests with this group in the same zone
+	LaunchSpecification   RunInstancesOptions
+}
+
+// SpotInstanceRequest describes a request for spot instances. State is
+// one of open, active, closed, cancelled and failed, and Status tells
+// why, e.g. fulfilled, price-too-low or capacity-not-available.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_SpotInstanceRequest.html for more details.
+type SpotInstanceRequest struct {
+	Id                       string                  `xml:"spotInstanceRequestId"`
+	SpotPrice                string                  `xml:"spotPrice"`
+	Type                     string                  `xml:"type"`
+	State                    string                  `xml:"state"`
+	Fault                    SpotInstanceStateFault  `xml:"fault"`
+	Status                   SpotInstanceStatus      `xml:"status"`
+	ValidFrom                string                  `xml:"validFrom"`
+	ValidUntil               string                  `xml:"validUntil"`
+	LaunchGroup              string                  `xml:"launchGroup"`
+	AvailabilityZoneGroup    string                  `xml:"availabilityZoneGroup"`
+	LaunchSpecification      SpotLaunchSpecification `xml:"launchSpecification"`
+	InstanceId               string                  `xml:"instanceId"` // The ID of the instance fulfilling the request, if any
+	CreateTime               string                  `xml:"createTime"`
+	ProductDescription       string                  `xml:"productDescription"`
+	LaunchedAvailabilityZone string                  `xml:"launchedAvailabilityZone"`
+	Tags                     []Tag                   `xml:"tagSet>item"`
+}
+
+// SpotInstanceStateFault describes why a spot instance request failed.
+type SpotInstanceStateFault struct {
+	Code    string `xml:"code"`
+	Message string `xml:"message"`
+}
+
+// SpotInstanceStatus describes the status of a spot instance request.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/spot-request-status.html for the codes.
+type SpotInstanceStatus struct {
+	Code       string `xml:"code"`
+	UpdateTime string `xml:"updateTime"`
+	Message    string `xml:"message"`
+}
+
+// SpotLaunchSpecification describes the instances a spot instance
+// request launches.
+type SpotLaunchSpecification struct {
+	ImageId          string          `xml:"imageId"`
+	KeyName          string          `xml:"keyName"`
+	InstanceType     string          `xml:"instanceType"`
+	AvailabilityZone string          `xml:"placement>availabilityZone"`
+	SubnetId         string          `xml:"subnetId"`
+	SecurityGroups   []SecurityGroup `xml:"groupSet>item"`
+	Monitoring       bool            `xml:"monitoring>enabled"`
+	EbsOptimized     bool            `xml:"ebsOptimized"`
+}
+
+// RequestSpotInstancesResp represents a response to a RequestSpotInstances
+// request.
+type RequestSpotInstancesResp struct {
+	RequestId    string                `xml:"requestId"`
+	SpotRequests []SpotInstanceRequest `xml:"spotInstanceRequestSet>item"`
+}
+
+// RequestSpotInstances requests spot instances, which are launched when
+// their maximum price exceeds the spot price and there is capacity for
+// them.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_RequestSpotInstances.html for more details.
+func (ec2 *EC2) RequestSpotInstances(options *RequestSpotInstancesOptions) (resp *RequestSpotInstancesResp, err error) {
+	params := makeParams("RequestSpotInstances")
+	params["SpotPrice"] = options.SpotPrice
+	if options.InstanceCount != 0 {
+		params["InstanceCount"] = strconv.Itoa(options.InstanceCount)
+	}
+	if options.Type != "" {
+		params["Type"] = options.Type
+	}
+	if !options.ValidFrom.IsZero() {
+		params["ValidFrom"] = options.ValidFrom.In(time.UTC).Format(time.RFC3339)
+	}
+	if !options.ValidUntil.IsZero() {
+		params["ValidUntil"] = options.ValidUntil.In(time.UTC).Format(time.RFC3339)
+	}
+	if options.LaunchGroup != "" {
+		params["LaunchGroup"] = options.LaunchGroup
+	}
+	if options.AvailabilityZoneGroup != "" {
+		params["AvailabilityZoneGroup"] = options.AvailabilityZoneGroup
+	}
+	token, err := clientToken()
+	if err != nil {
+		return nil, err
+	}
+	params["ClientToken"] = token
+	spec := make(map[string]string)
+	addLaunchParams(spec, &options.LaunchSpecification)
+	for k, v := range spec {
+		params["LaunchSpecification."+k] = v
+	}
+
+	resp = &RequestSpotInstancesResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DescribeSpotInstanceRequestsResp represents a response to a
+// DescribeSpotInstanceRequests request.
+type DescribeSpotInstanceRequestsResp struct {
+	RequestId    string                `xml:"requestId"`
+	SpotRequests []SpotInstanceRequest `xml:"spotInstanceRequestSet>item"`
+}
+
+// DescribeSpotInstanceRequests describes one or more of your spot instance
+// requests, or all of them if requestIds is empty, optionally filtered.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSpotInstanceRequests.html for more details.
+func (ec2 *EC2) DescribeSpotInstanceRequests(requestIds []string, filter *Filter) (resp *DescribeSpotInstanceRequestsResp, err error) {
+	params := makeParams("DescribeSpotInstanceRequests")
+	addParamsList(params, "SpotInstanceRequestId", requestIds)
+	filter.addParams(params)
+	resp = &DescribeSpotInstanceRequestsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// CancelledSpotInstanceRequest describes a cancelled spot instance
+// request.
+type CancelledSpotInstanceRequest struct {
+	Id    string `xml:"spotInstanceRequestId"`
+	State string `xml:"state"` // Valid values: active | open | closed | cancelled | completed
+}
+
+// CancelSpotInstanceRequestsResp represents a response to a
+// CancelSpotInstanceRequests request.
+type CancelSpotInstanceRequestsResp struct {
+	RequestId         string                         `xml:"requestId"`
+	CancelledRequests []CancelledSpotInstanceRequest `xml:"spotInstanceRequestSet>item"`
+}
+
+// CancelSpotInstanceRequests cancels spot instance requests. The instances
+// launched by them keep running.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CancelSpotInstanceRequests.html for more details.
+func (ec2 *EC2) CancelSpotInstanceRequests(requestIds []string) (resp *CancelSpotInstanceRequestsResp, err error) {
+	params := makeParams("CancelSpotInstanceRequests")
+	addParamsList(params, "SpotInstanceRequestId", requestIds)
+	resp = &CancelSpotInstanceRequestsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// SpotRequestWaitPolicy is the backoff between the polls of
+// WaitUntilSpotInstanceRequestFulfilled, which gives up after MaxAttempts
+// polls.
+var SpotRequestWaitPolicy = aws.RetryPolicy{
+	MaxAttempts: 40,
+	BaseDelay:   5 * time.Second,
+	MaxDelay:    30 * time.Second,
+	Jitter:      0.1,
+}
+
+// SpotRequestError is returned by WaitUntilSpotInstanceRequestFulfilled
+// when the request won't be fulfilled, or wasn't in time.
+type SpotRequestError struct {
+	Request SpotInstanceRequest
+}
+
+func (e *SpotRequestError) Error() string {
+	r := &e.Request
+	if r.Status.Message != "" {
+		return fmt.Sprintf("spot instance request %s is %s (%s): %s", r.Id, r.State, r.Status.Code, r.Status.Message)
+	}
+	return fmt.Sprintf("spot instance request %s is %s (%s)", r.Id, r.State, r.Status.Code)
+}
+
+// WaitUntilSpotInstanceRequestFulfilled polls the spot instance request
+// with the given id, backing off as given by SpotRequestWaitPolicy, until
+// it is fulfilled, and returns the ID of the instance launched. A request
+// that isn't found yet is polled again. It fails with a *SpotRequestError
+// if the request is closed, cancelled or failed first, or is still open
+// after the last poll, e.g. with the status price-too-low.
+func (ec2 *EC2) WaitUntilSpotInstanceRequestFulfilled(id string) (instanceId string, err error) {
+	policy := SpotRequestWaitPolicy
+	var last SpotInstanceRequest
+	for attempt := 0; ; attempt++ {
+		resp, err := ec2.DescribeSpotInstanceRequests([]string{id}, nil)
+		switch {
+		case aws.IsCode(err, "InvalidSpotInstanceRequestID.NotFound"):
+			// The request may not be visible yet.
+		case err != nil:
+			return "", err
+		case len(resp.SpotRequests) > 0:
+			last = resp.SpotRequests[0]
+			if last.State == "active" && last.InstanceId != "" {
+				return last.InstanceId, nil
+			}
+			if last.State != "open" && last.State != "active" {
+				return "", &SpotRequestError{last}
+			}
+		}
+		if attempt+1 >= policy.MaxAttempts {
+			if err != nil {
+				return "", err
+			}
+			break
+		}
+		policy.Backoff(attempt)
+	}
+	if last.Id == "" {
+		last.Id = id
+	}
+	return "", &SpotRequestError{last}
+}
+
+// DescribeSpotPriceHistoryOptions encapsulates options for a
+// DescribeSpotPriceHistory request. All are optional. A response with a
+// NextToken is continued by the request with that NextToken.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSpotPriceHistory.html for more details.
+type DescribeSpotPriceHistoryOptions struct {
+	StartTime           time.Time
+	EndTime             time.Time
+	InstanceTypes       []string
+	ProductDescriptions []string // e.g. "Linux/UNIX"
+	AvailabilityZone    string
+	MaxResults          int
+	NextToken           string
+}
+
+// SpotPrice describes the spot price of an instance type in an
+// availability zone from Timestamp.
+type SpotPrice struct {
+	InstanceType       string `xml:"instanceType"`
+	ProductDescription string `xml:"productDescription"`
+	SpotPrice          string `xml:"spotPrice"`
+	Timestamp          string `xml:"timestamp"`
+	AvailabilityZone   string `xml:"availabilityZone"`
+}
+
+// DescribeSpotPriceHistoryResp represents a response to a
+// DescribeSpotPriceHistory request.
+type DescribeSpotPriceHistoryResp struct {
+	RequestId string      `xml:"requestId"`
+	History   []SpotPrice `xml:"spotPriceHistorySet>item"`
+	NextToken string      `xml:"nextToken"`
+}
+
+// DescribeSpotPriceHistory describes the history of spot prices,
+// optionally filtered.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSpotPriceHistory.html for more details.
+func (ec2 *EC2) DescribeSpotPriceHistory(options *DescribeSpotPriceHistoryOptions, filter *Filter) (resp *DescribeSpotPriceHistoryResp, err error) {
+	params := makeParams("DescribeSpotPriceHistory")
+	if !options.StartTime.IsZero() {
+		params["StartTime"] = options.StartTime.In(time.UTC).Format(time.RFC3339)
+	}
+	if !options.EndTime.IsZero() {
+		params["EndTime"] = options.EndTime.In(time.UTC).Format(time.RFC3339)
+	}
+	addParamsList(params, "InstanceType", options.InstanceTypes)
+	addParamsList(params, "ProductDescription", options.ProductDescriptions)
+	if options.AvailabilityZone != "" {
+		params["AvailabilityZone"] = options.AvailabilityZone
+	}
+	if options.MaxResults != 0 {
+		params["MaxResults"] = strconv.Itoa(options.MaxResults)
+	}
+	if options.NextToken != "" {
+		params["NextToken"] = options.NextToken
+	}
+	filter.addParams(params)
+	resp = &DescribeSpotPriceHistoryResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
@@ -0,0 +1,183 @@
+package ec2_test
+
+import (
+	"time"
+
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/ec2"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) TestRequestSpotInstances(c *C) {
+	testServer.Response(200, nil, RequestSpotInstancesExample)
+
+	options := ec2.RequestSpotInstancesOptions{
+		SpotPrice:             "0.5",
+		InstanceCount:         2,
+		Type:                  "one-time",
+		ValidUntil:            time.Date(2014, 5, 1, 0, 0, 0, 0, time.UTC),
+		AvailabilityZoneGroup: "MyAzGroup",
+		LaunchSpecification: ec2.RunInstancesOptions{
+			ImageId:        "ami-1a2b3c4d",
+			InstanceType:   "m1.small",
+			KeyName:        "my-key-pair",
+			SecurityGroups: []ec2.SecurityGroup{{Id: "sg-1a2b3c4d"}},
+			UserData:       []byte("1234"),
+			BlockDevices:   []ec2.BlockDeviceMapping{{DeviceName: "/dev/sdb", VirtualName: "ephemeral0"}},
+		},
+	}
+	resp, err := s.ec2.RequestSpotInstances(&options)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"RequestSpotInstances"})
+	c.Assert(req.Form["SpotPrice"], DeepEquals, []string{"0.5"})
+	c.Assert(req.Form["InstanceCount"], DeepEquals, []string{"2"})
+	c.Assert(req.Form["Type"], DeepEquals, []string{"one-time"})
+	c.Assert(req.Form["ValidUntil"], DeepEquals, []string{"2014-05-01T00:00:00Z"})
+	c.Assert(req.Form["ValidFrom"], IsNil)
+	c.Assert(req.Form["AvailabilityZoneGroup"], DeepEquals, []string{"MyAzGroup"})
+	c.Assert(req.Form["ClientToken"], HasLen, 1)
+	c.Assert(req.Form["LaunchSpecification.ImageId"], DeepEquals, []string{"ami-1a2b3c4d"})
+	c.Assert(req.Form["LaunchSpecification.InstanceType"], DeepEquals, []string{"m1.small"})
+	c.Assert(req.Form["LaunchSpecification.KeyName"], DeepEquals, []string{"my-key-pair"})
+	c.Assert(req.Form["LaunchSpecification.SecurityGroupId.1"], DeepEquals, []string{"sg-1a2b3c4d"})
+	c.Assert(req.Form["LaunchSpecification.UserData"], DeepEquals, []string{"MTIzNA=="})
+	c.Assert(req.Form["LaunchSpecification.BlockDeviceMapping.1.DeviceName"], DeepEquals, []string{"/dev/sdb"})
+	c.Assert(req.Form["LaunchSpecification.BlockDeviceMapping.1.VirtualName"], DeepEquals, []string{"ephemeral0"})
+	c.Assert(req.Form["MinCount"], IsNil)
+	c.Assert(req.Form["ImageId"], IsNil)
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.RequestId, Equals, "59dbff89-35bd-4eac-99ed-be587EXAMPLE")
+	c.Assert(resp.SpotRequests, HasLen, 1)
+	r := resp.SpotRequests[0]
+	c.Assert(r.Id, Equals, "sir-1a2b3c4d")
+	c.Assert(r.SpotPrice, Equals, "0.5")
+	c.Assert(r.State, Equals, "open")
+	c.Assert(r.Status.Code, Equals, "pending-evaluation")
+	c.Assert(r.AvailabilityZoneGroup, Equals, "MyAzGroup")
+	c.Assert(r.LaunchSpecification.ImageId, Equals, "ami-1a2b3c4d")
+	c.Assert(r.LaunchSpecification.SecurityGroups, DeepEquals, []ec2.SecurityGroup{{Id: "sg-1a2b3c4d", Name: "websrv"}})
+	c.Assert(r.InstanceId, Equals, "")
+}
+
+func (s *S) TestDescribeSpotInstanceRequests(c *C) {
+	testServer.Response(200, nil, DescribeSpotInstanceRequestsExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("state", "active", "open")
+
+	resp, err := s.ec2.DescribeSpotInstanceRequests([]string{"sir-1a2b3c4d"}, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeSpotInstanceRequests"})
+	c.Assert(req.Form["SpotInstanceRequestId.1"], DeepEquals, []string{"sir-1a2b3c4d"})
+	c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"state"})
+	c.Assert(req.Form["Filter.1.Value.2"], DeepEquals, []string{"open"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.SpotRequests, HasLen, 1)
+	r := resp.SpotRequests[0]
+	c.Assert(r.State, Equals, "active")
+	c.Assert(r.Status, DeepEquals, ec2.SpotInstanceStatus{
+		Code:       "fulfilled",
+		UpdateTime: "2014-04-30T18:16:21.000Z",
+		Message:    "Your Spot request is fulfilled.",
+	})
+	c.Assert(r.InstanceId, Equals, "i-1a2b3c4d")
+	c.Assert(r.LaunchSpecification.AvailabilityZone, Equals, "us-west-1b")
+	c.Assert(r.LaunchedAvailabilityZone, Equals, "us-west-1b")
+	c.Assert(r.Tags, DeepEquals, []ec2.Tag{{"batch", "nightly"}})
+}
+
+func (s *S) TestCancelSpotInstanceRequests(c *C) {
+	testServer.Response(200, nil, CancelSpotInstanceRequestsExample)
+
+	resp, err := s.ec2.CancelSpotInstanceRequests([]string{"sir-1a2b3c4d"})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"CancelSpotInstanceRequests"})
+	c.Assert(req.Form["SpotInstanceRequestId.1"], DeepEquals, []string{"sir-1a2b3c4d"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.CancelledRequests, DeepEquals, []ec2.CancelledSpotInstanceRequest{{Id: "sir-1a2b3c4d", State: "cancelled"}})
+}
+
+func (s *S) TestWaitUntilSpotInstanceRequestFulfilled(c *C) {
+	defer func(policy aws.RetryPolicy) { ec2.SpotRequestWaitPolicy = policy }(ec2.SpotRequestWaitPolicy)
+	ec2.SpotRequestWaitPolicy = aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	testServer.Response(200, nil, DescribeSpotInstanceRequestsPriceTooLowExample)
+	testServer.Response(200, nil, DescribeSpotInstanceRequestsExample)
+
+	id, err := s.ec2.WaitUntilSpotInstanceRequestFulfilled("sir-1a2b3c4d")
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, "i-1a2b3c4d")
+
+	for _, req := range testServer.WaitRequests(2) {
+		c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeSpotInstanceRequests"})
+		c.Assert(req.Form["SpotInstanceRequestId.1"], DeepEquals, []string{"sir-1a2b3c4d"})
+	}
+}
+
+func (s *S) TestWaitUntilSpotInstanceRequestFulfilledCancelled(c *C) {
+	defer func(policy aws.RetryPolicy) { ec2.SpotRequestWaitPolicy = policy }(ec2.SpotRequestWaitPolicy)
+	ec2.SpotRequestWaitPolicy = aws.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	testServer.Response(200, nil, DescribeSpotInstanceRequestsCancelledExample)
+
+	_, err := s.ec2.WaitUntilSpotInstanceRequestFulfilled("sir-1a2b3c4d")
+	c.Assert(err, ErrorMatches, `spot instance request sir-1a2b3c4d is cancelled \(canceled-before-fulfillment\): .*`)
+	c.Assert(err.(*ec2.SpotRequestError).Request.State, Equals, "cancelled")
+	testServer.WaitRequest()
+}
+
+func (s *S) TestWaitUntilSpotInstanceRequestFulfilledTimeout(c *C) {
+	defer func(policy aws.RetryPolicy) { ec2.SpotRequestWaitPolicy = policy }(ec2.SpotRequestWaitPolicy)
+	ec2.SpotRequestWaitPolicy = aws.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+	testServer.Response(200, nil, DescribeSpotInstanceRequestsPriceTooLowExample)
+	testServer.Response(200, nil, DescribeSpotInstanceRequestsPriceTooLowExample)
+
+	// The status of the request when giving up is returned
+	_, err := s.ec2.WaitUntilSpotInstanceRequestFulfilled("sir-1a2b3c4d")
+	c.Assert(err, ErrorMatches, `spot instance request sir-1a2b3c4d is open \(price-too-low\): .*`)
+	c.Assert(err.(*ec2.SpotRequestError).Request.Status.Code, Equals, "price-too-low")
+	testServer.WaitRequests(2)
+}
+
+func (s *S) TestDescribeSpotPriceHistory(c *C) {
+	testServer.Response(200, nil, DescribeSpotPriceHistoryExample)
+
+	options := ec2.DescribeSpotPriceHistoryOptions{
+		StartTime:           time.Date(2014, 1, 5, 0, 0, 0, 0, time.UTC),
+		EndTime:             time.Date(2014, 1, 6, 12, 0, 0, 0, time.UTC),
+		InstanceTypes:       []string{"m3.medium", "m3.large"},
+		ProductDescriptions: []string{"Linux/UNIX"},
+		MaxResults:          2,
+		NextToken:           "token0",
+	}
+	filter := ec2.NewFilter()
+	filter.Add("availability-zone", "us-west-2a", "us-west-2b")
+
+	resp, err := s.ec2.DescribeSpotPriceHistory(&options, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeSpotPriceHistory"})
+	c.Assert(req.Form["StartTime"], DeepEquals, []string{"2014-01-05T00:00:00Z"})
+	c.Assert(req.Form["EndTime"], DeepEquals, []string{"2014-01-06T12:00:00Z"})
+	c.Assert(req.Form["InstanceType.1"], DeepEquals, []string{"m3.medium"})
+	c.Assert(req.Form["InstanceType.2"], DeepEquals, []string{"m3.large"})
+	c.Assert(req.Form["ProductDescription.1"], DeepEquals, []string{"Linux/UNIX"})
+	c.Assert(req.Form["MaxResults"], DeepEquals, []string{"2"})
+	c.Assert(req.Form["NextToken"], DeepEquals, []string{"token0"})
+	c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"availability-zone"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.NextToken, Equals, "token1")
+	c.Assert(resp.History, HasLen, 2)
+	c.Assert(resp.History[1], DeepEquals, ec2.SpotPrice{
+		InstanceType:       "m3.medium",
+		ProductDescription: "Linux/UNIX",
+		SpotPrice:          "0.0105",
+		Timestamp:          "2014-01-05T11:28:26.000Z",
+		AvailabilityZone:   "us-west-2b",
+	})
+}
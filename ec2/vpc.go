@@ -187,3 +187,319 @@ func (ec2 *EC2) DeleteRouteTable(routeTableId string) (resp *DeleteRouteTableRes
 	}
 	return
 }
+
+// Vpc describes a virtual private cloud, an isolated network with the
+// addresses of CidrBlock.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_Vpc.html for more details.
+type Vpc struct {
+	Id              string `xml:"vpcId"`
+	State           string `xml:"state"`     // pending | available
+	CidrBlock       string `xml:"cidrBlock"` // The CIDR block of the VPC, e.g. 10.0.0.0/16.
+	DhcpOptionsId   string `xml:"dhcpOptionsId"`
+	InstanceTenancy string `xml:"instanceTenancy"` // default | dedicated
+	IsDefault       bool   `xml:"isDefault"`       // Whether this is the default VPC of the region.
+	Tags            []Tag  `xml:"tagSet>item"`
+}
+
+// CreateVpcResp represents a response from a CreateVpc request.
+type CreateVpcResp struct {
+	RequestId string `xml:"requestId"`
+	Vpc       Vpc    `xml:"vpc"`
+}
+
+// CreateVpc creates a VPC with the addresses of cidrBlock, whose
+// instances are launched with the given tenancy, "default" or
+// "dedicated", or the default one if empty.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateVpc.html for more details.
+func (ec2 *EC2) CreateVpc(cidrBlock, instanceTenancy string) (resp *CreateVpcResp, err error) {
+	params := makeParams("CreateVpc")
+	params["CidrBlock"] = cidrBlock
+	if instanceTenancy != "" {
+		params["InstanceTenancy"] = instanceTenancy
+	}
+	resp = &CreateVpcResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteVpc deletes the specified VPC. Its instances, subnets, gateways
+// and non-main route tables must be deleted or detached first.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteVpc.html for more details.
+func (ec2 *EC2) DeleteVpc(vpcId string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteVpc")
+	params["VpcId"] = vpcId
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DescribeVpcsResp represents a response from a DescribeVpcs request.
+type DescribeVpcsResp struct {
+	RequestId string `xml:"requestId"`
+	Vpcs      []Vpc  `xml:"vpcSet>item"`
+}
+
+// DescribeVpcs describes one or more of your VPCs, or all of them if
+// vpcIds is empty, optionally filtered.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeVpcs.html for more details.
+func (ec2 *EC2) DescribeVpcs(vpcIds []string, filter *Filter) (resp *DescribeVpcsResp, err error) {
+	params := makeParams("DescribeVpcs")
+	addParamsList(params, "VpcId", vpcIds)
+	filter.addParams(params)
+	resp = &DescribeVpcsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Subnet describes a range of the addresses of a VPC in an availability
+// zone.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_Subnet.html for more details.
+type Subnet struct {
+	Id                      string `xml:"subnetId"`
+	State                   string `xml:"state"` // pending | available
+	VpcId                   string `xml:"vpcId"`
+	CidrBlock               string `xml:"cidrBlock"`
+	AvailableIpAddressCount int    `xml:"availableIpAddressCount"`
+	AvailabilityZone        string `xml:"availabilityZone"`
+	DefaultForAz            bool   `xml:"defaultForAz"`        // Whether this is the default subnet of the availability zone.
+	MapPublicIpOnLaunch     bool   `xml:"mapPublicIpOnLaunch"` // Whether instances launched in the subnet get a public address.
+	Tags                    []Tag  `xml:"tagSet>item"`
+}
+
+// CreateSubnetResp represents a response from a CreateSubnet request.
+type CreateSubnetResp struct {
+	RequestId string `xml:"requestId"`
+	Subnet    Subnet `xml:"subnet"`
+}
+
+// CreateSubnet creates a subnet of the specified VPC with the addresses
+// of cidrBlock, in availabilityZone or one chosen by EC2 if empty.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateSubnet.html for more details.
+func (ec2 *EC2) CreateSubnet(vpcId, cidrBlock, availabilityZone string) (resp *CreateSubnetResp, err error) {
+	params := makeParams("CreateSubnet")
+	params["VpcId"] = vpcId
+	params["CidrBlock"] = cidrBlock
+	if availabilityZone != "" {
+		params["AvailabilityZone"] = availabilityZone
+	}
+	resp = &CreateSubnetResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteSubnet deletes the specified subnet, which must have no running
+// instances.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteSubnet.html for more details.
+func (ec2 *EC2) DeleteSubnet(subnetId string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteSubnet")
+	params["SubnetId"] = subnetId
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DescribeSubnetsResp represents a response from a DescribeSubnets request.
+type DescribeSubnetsResp struct {
+	RequestId string   `xml:"requestId"`
+	Subnets   []Subnet `xml:"subnetSet>item"`
+}
+
+// DescribeSubnets describes one or more of your subnets, or all of them
+// if subnetIds is empty, optionally filtered.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeSubnets.html for more details.
+func (ec2 *EC2) DescribeSubnets(subnetIds []string, filter *Filter) (resp *DescribeSubnetsResp, err error) {
+	params := makeParams("DescribeSubnets")
+	addParamsList(params, "SubnetId", subnetIds)
+	filter.addParams(params)
+	resp = &DescribeSubnetsResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// CreateRoute holds the options of a CreateRoute request. Traffic to
+// DestinationCidrBlock is routed to exactly one of the gateway, NAT
+// instance, network interface or VPC peering connection.
+type CreateRoute struct {
+	RouteTableId           string
+	DestinationCidrBlock   string
+	GatewayId              string
+	InstanceId             string
+	NetworkInterfaceId     string
+	VpcPeeringConnectionId string
+}
+
+// CreateRoute adds a route to a route table.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateRoute.html for more details.
+func (ec2 *EC2) CreateRoute(options *CreateRoute) (resp *SimpleResp, err error) {
+	params := makeParams("CreateRoute")
+	params["RouteTableId"] = options.RouteTableId
+	params["DestinationCidrBlock"] = options.DestinationCidrBlock
+	if options.GatewayId != "" {
+		params["GatewayId"] = options.GatewayId
+	}
+	if options.InstanceId != "" {
+		params["InstanceId"] = options.InstanceId
+	}
+	if options.NetworkInterfaceId != "" {
+		params["NetworkInterfaceId"] = options.NetworkInterfaceId
+	}
+	if options.VpcPeeringConnectionId != "" {
+		params["VpcPeeringConnectionId"] = options.VpcPeeringConnectionId
+	}
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteRoute deletes the route to destinationCidrBlock from a route
+// table.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteRoute.html for more details.
+func (ec2 *EC2) DeleteRoute(routeTableId, destinationCidrBlock string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteRoute")
+	params["RouteTableId"] = routeTableId
+	params["DestinationCidrBlock"] = destinationCidrBlock
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// InternetGateway describes a gateway connecting the VPCs it is attached
+// to to the Internet.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_InternetGateway.html for more details.
+type InternetGateway struct {
+	Id          string                      `xml:"internetGatewayId"`
+	Attachments []InternetGatewayAttachment `xml:"attachmentSet>item"`
+	Tags        []Tag                       `xml:"tagSet>item"`
+}
+
+// InternetGatewayAttachment describes the attachment of an internet
+// gateway to a VPC.
+type InternetGatewayAttachment struct {
+	VpcId string `xml:"vpcId"`
+	State string `xml:"state"` // attaching | attached | detaching | detached | available
+}
+
+// CreateInternetGatewayResp represents a response from a
+// CreateInternetGateway request.
+type CreateInternetGatewayResp struct {
+	RequestId       string          `xml:"requestId"`
+	InternetGateway InternetGateway `xml:"internetGateway"`
+}
+
+// CreateInternetGateway creates an internet gateway, to be attached to a
+// VPC with AttachInternetGateway.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_CreateInternetGateway.html for more details.
+func (ec2 *EC2) CreateInternetGateway() (resp *CreateInternetGatewayResp, err error) {
+	params := makeParams("CreateInternetGateway")
+	resp = &CreateInternetGatewayResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// AttachInternetGateway attaches an internet gateway to a VPC.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_AttachInternetGateway.html for more details.
+func (ec2 *EC2) AttachInternetGateway(internetGatewayId, vpcId string) (resp *SimpleResp, err error) {
+	params := makeParams("AttachInternetGateway")
+	params["InternetGatewayId"] = internetGatewayId
+	params["VpcId"] = vpcId
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DetachInternetGateway detaches an internet gateway from a VPC.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DetachInternetGateway.html for more details.
+func (ec2 *EC2) DetachInternetGateway(internetGatewayId, vpcId string) (resp *SimpleResp, err error) {
+	params := makeParams("DetachInternetGateway")
+	params["InternetGatewayId"] = internetGatewayId
+	params["VpcId"] = vpcId
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DeleteInternetGateway deletes the specified internet gateway, which
+// must be detached first.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DeleteInternetGateway.html for more details.
+func (ec2 *EC2) DeleteInternetGateway(internetGatewayId string) (resp *SimpleResp, err error) {
+	params := makeParams("DeleteInternetGateway")
+	params["InternetGatewayId"] = internetGatewayId
+	resp = &SimpleResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// DescribeInternetGatewaysResp represents a response from a
+// DescribeInternetGateways request.
+type DescribeInternetGatewaysResp struct {
+	RequestId        string            `xml:"requestId"`
+	InternetGateways []InternetGateway `xml:"internetGatewaySet>item"`
+}
+
+// DescribeInternetGateways describes one or more of your internet
+// gateways, or all of them if internetGatewayIds is empty, optionally
+// filtered.
+//
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInternetGateways.html for more details.
+func (ec2 *EC2) DescribeInternetGateways(internetGatewayIds []string, filter *Filter) (resp *DescribeInternetGatewaysResp, err error) {
+	params := makeParams("DescribeInternetGateways")
+	addParamsList(params, "InternetGatewayId", internetGatewayIds)
+	filter.addParams(params)
+	resp = &DescribeInternetGatewaysResp{}
+	err = ec2.query(params, resp)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
@@ -125,3 +125,219 @@ func (s *S) TestDeleteRouteTable(c *C) {
 	c.Assert(resp.RequestId, Equals, "49dbff89-35bd-4eac-99ed-be587EXAMPLE")
 	c.Assert(resp.Return, Equals, true)
 }
+
+func (s *S) TestCreateVpc(c *C) {
+	testServer.Response(200, nil, CreateVpcExample)
+
+	resp, err := s.ec2.CreateVpc("10.0.0.0/16", "default")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"CreateVpc"})
+	c.Assert(req.Form["CidrBlock"], DeepEquals, []string{"10.0.0.0/16"})
+	c.Assert(req.Form["InstanceTenancy"], DeepEquals, []string{"default"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.RequestId, Equals, "7a62c49f-347e-4fc4-9331-6e8eEXAMPLE")
+	c.Assert(resp.Vpc, DeepEquals, ec2.Vpc{
+		Id:              "vpc-1a2b3c4d",
+		State:           "pending",
+		CidrBlock:       "10.0.0.0/16",
+		DhcpOptionsId:   "dopt-1a2b3c4d2",
+		InstanceTenancy: "default",
+	})
+}
+
+func (s *S) TestDeleteVpc(c *C) {
+	testServer.Response(200, nil, DeleteVpcExample)
+
+	resp, err := s.ec2.DeleteVpc("vpc-1a2b3c4d")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DeleteVpc"})
+	c.Assert(req.Form["VpcId"], DeepEquals, []string{"vpc-1a2b3c4d"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.RequestId, Equals, "7a62c49f-347e-4fc4-9331-6e8eEXAMPLE")
+}
+
+func (s *S) TestDescribeVpcs(c *C) {
+	testServer.Response(200, nil, DescribeVpcsExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("key1", "value1")
+	filter.Add("key2", "value2", "value3")
+
+	resp, err := s.ec2.DescribeVpcs([]string{"vpc-1a2b3c4d", "vpc-5e6f7a8b"}, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeVpcs"})
+	c.Assert(req.Form["VpcId.1"], DeepEquals, []string{"vpc-1a2b3c4d"})
+	c.Assert(req.Form["VpcId.2"], DeepEquals, []string{"vpc-5e6f7a8b"})
+	c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"key1"})
+	c.Assert(req.Form["Filter.1.Value.1"], DeepEquals, []string{"value1"})
+	c.Assert(req.Form["Filter.2.Name"], DeepEquals, []string{"key2"})
+	c.Assert(req.Form["Filter.2.Value.2"], DeepEquals, []string{"value3"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.Vpcs, HasLen, 2)
+	c.Assert(resp.Vpcs[0].Id, Equals, "vpc-1a2b3c4d")
+	c.Assert(resp.Vpcs[0].CidrBlock, Equals, "10.0.0.0/23")
+	c.Assert(resp.Vpcs[0].IsDefault, Equals, false)
+	c.Assert(resp.Vpcs[0].Tags, DeepEquals, []ec2.Tag{{"Name", "production"}})
+	c.Assert(resp.Vpcs[1].Id, Equals, "vpc-5e6f7a8b")
+	c.Assert(resp.Vpcs[1].IsDefault, Equals, true)
+}
+
+func (s *S) TestCreateSubnet(c *C) {
+	testServer.Response(200, nil, CreateSubnetExample)
+
+	resp, err := s.ec2.CreateSubnet("vpc-1a2b3c4d", "10.0.1.0/24", "us-east-1a")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"CreateSubnet"})
+	c.Assert(req.Form["VpcId"], DeepEquals, []string{"vpc-1a2b3c4d"})
+	c.Assert(req.Form["CidrBlock"], DeepEquals, []string{"10.0.1.0/24"})
+	c.Assert(req.Form["AvailabilityZone"], DeepEquals, []string{"us-east-1a"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.Subnet, DeepEquals, ec2.Subnet{
+		Id:                      "subnet-9d4a7b6c",
+		State:                   "pending",
+		VpcId:                   "vpc-1a2b3c4d",
+		CidrBlock:               "10.0.1.0/24",
+		AvailableIpAddressCount: 251,
+		AvailabilityZone:        "us-east-1a",
+	})
+}
+
+func (s *S) TestDeleteSubnet(c *C) {
+	testServer.Response(200, nil, DeleteSubnetExample)
+
+	_, err := s.ec2.DeleteSubnet("subnet-9d4a7b6c")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DeleteSubnet"})
+	c.Assert(req.Form["SubnetId"], DeepEquals, []string{"subnet-9d4a7b6c"})
+	c.Assert(err, IsNil)
+}
+
+func (s *S) TestDescribeSubnets(c *C) {
+	testServer.Response(200, nil, DescribeSubnetsExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("vpc-id", "vpc-1a2b3c4d")
+
+	resp, err := s.ec2.DescribeSubnets(nil, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeSubnets"})
+	c.Assert(req.Form["SubnetId.1"], IsNil)
+	c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"vpc-id"})
+	c.Assert(req.Form["Filter.1.Value.1"], DeepEquals, []string{"vpc-1a2b3c4d"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.Subnets, HasLen, 2)
+	c.Assert(resp.Subnets[0].Id, Equals, "subnet-9d4a7b6c")
+	c.Assert(resp.Subnets[0].MapPublicIpOnLaunch, Equals, false)
+	c.Assert(resp.Subnets[1].Id, Equals, "subnet-6e7f829e")
+	c.Assert(resp.Subnets[1].CidrBlock, Equals, "10.0.0.0/24")
+	c.Assert(resp.Subnets[1].MapPublicIpOnLaunch, Equals, true)
+	c.Assert(resp.Subnets[1].Tags, DeepEquals, []ec2.Tag{{"Name", "public"}})
+}
+
+func (s *S) TestCreateRoute(c *C) {
+	testServer.Response(200, nil, CreateRouteExample)
+
+	_, err := s.ec2.CreateRoute(&ec2.CreateRoute{
+		RouteTableId:         "rtb-e4ad488d",
+		DestinationCidrBlock: "0.0.0.0/0",
+		GatewayId:            "igw-eaad4883",
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"CreateRoute"})
+	c.Assert(req.Form["RouteTableId"], DeepEquals, []string{"rtb-e4ad488d"})
+	c.Assert(req.Form["DestinationCidrBlock"], DeepEquals, []string{"0.0.0.0/0"})
+	c.Assert(req.Form["GatewayId"], DeepEquals, []string{"igw-eaad4883"})
+	c.Assert(req.Form["InstanceId"], IsNil)
+	c.Assert(err, IsNil)
+}
+
+func (s *S) TestDeleteRoute(c *C) {
+	testServer.Response(200, nil, DeleteRouteExample)
+
+	_, err := s.ec2.DeleteRoute("rtb-e4ad488d", "172.16.1.0/24")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DeleteRoute"})
+	c.Assert(req.Form["RouteTableId"], DeepEquals, []string{"rtb-e4ad488d"})
+	c.Assert(req.Form["DestinationCidrBlock"], DeepEquals, []string{"172.16.1.0/24"})
+	c.Assert(err, IsNil)
+}
+
+func (s *S) TestCreateInternetGateway(c *C) {
+	testServer.Response(200, nil, CreateInternetGatewayExample)
+
+	resp, err := s.ec2.CreateInternetGateway()
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"CreateInternetGateway"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.InternetGateway.Id, Equals, "igw-eaad4883")
+	c.Assert(resp.InternetGateway.Attachments, HasLen, 0)
+}
+
+func (s *S) TestAttachInternetGateway(c *C) {
+	testServer.Response(200, nil, AttachInternetGatewayExample)
+
+	_, err := s.ec2.AttachInternetGateway("igw-eaad4883", "vpc-11ad4878")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"AttachInternetGateway"})
+	c.Assert(req.Form["InternetGatewayId"], DeepEquals, []string{"igw-eaad4883"})
+	c.Assert(req.Form["VpcId"], DeepEquals, []string{"vpc-11ad4878"})
+	c.Assert(err, IsNil)
+}
+
+func (s *S) TestDetachInternetGateway(c *C) {
+	testServer.Response(200, nil, DetachInternetGatewayExample)
+
+	_, err := s.ec2.DetachInternetGateway("igw-eaad4883", "vpc-11ad4878")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DetachInternetGateway"})
+	c.Assert(req.Form["InternetGatewayId"], DeepEquals, []string{"igw-eaad4883"})
+	c.Assert(req.Form["VpcId"], DeepEquals, []string{"vpc-11ad4878"})
+	c.Assert(err, IsNil)
+}
+
+func (s *S) TestDeleteInternetGateway(c *C) {
+	testServer.Response(200, nil, DeleteInternetGatewayExample)
+
+	_, err := s.ec2.DeleteInternetGateway("igw-eaad4883")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DeleteInternetGateway"})
+	c.Assert(req.Form["InternetGatewayId"], DeepEquals, []string{"igw-eaad4883"})
+	c.Assert(err, IsNil)
+}
+
+func (s *S) TestDescribeInternetGateways(c *C) {
+	testServer.Response(200, nil, DescribeInternetGatewaysExample)
+
+	filter := ec2.NewFilter()
+	filter.Add("attachment.vpc-id", "vpc-11ad4878")
+
+	resp, err := s.ec2.DescribeInternetGateways(nil, filter)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"DescribeInternetGateways"})
+	c.Assert(req.Form["Filter.1.Name"], DeepEquals, []string{"attachment.vpc-id"})
+
+	c.Assert(err, IsNil)
+	c.Assert(resp.InternetGateways, DeepEquals, []ec2.InternetGateway{{
+		Id:          "igw-eaad4883EXAMPLE",
+		Attachments: []ec2.InternetGatewayAttachment{{VpcId: "vpc-11ad4878", State: "available"}},
+	}})
+}
@@ -379,41 +379,10 @@ func (elb *ELB) query(params map[string]string, resp interface{}) error {
 }
 
 // Error encapsulates an error returned by ELB.
-type Error struct {
-	// HTTP status code
-	StatusCode int
-	// AWS error code
-	Code string
-	// The human-oriented error message
-	Message string
-}
-
-func (err *Error) Error() string {
-	if err.Code == "" {
-		return err.Message
-	}
-
-	return fmt.Sprintf("%s (%s)", err.Message, err.Code)
-}
-
-type xmlErrors struct {
-	Errors []Error `xml:"Error"`
-}
+type Error = aws.Error
 
 func buildError(r *http.Response) error {
-	var (
-		err    Error
-		errors xmlErrors
-	)
-	xml.NewDecoder(r.Body).Decode(&errors)
-	if len(errors.Errors) > 0 {
-		err = errors.Errors[0]
-	}
-	err.StatusCode = r.StatusCode
-	if err.Message == "" {
-		err.Message = r.Status
-	}
-	return &err
+	return aws.BuildError(r)
 }
 
 func multimap(p map[string]string) url.Values {
@@ -28,6 +28,10 @@ import (
 type SNS struct {
 	aws.Auth
 	aws.Region
+
+	// HTTPClient performs the requests. It defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
 	private byte // Reserve the right of using private data.
 }
 
@@ -42,7 +46,7 @@ type ResponseMetadata struct {
 }
 
 func New(auth aws.Auth, region aws.Region) *SNS {
-	return &SNS{auth, region, 0}
+	return &SNS{Auth: auth, Region: region}
 }
 
 func makeParams(action string) map[string]string {
@@ -51,22 +55,8 @@ func makeParams(action string) map[string]string {
 	return params
 }
 
-type Error struct {
-	StatusCode int
-	Code       string
-	Message    string
-	RequestId  string
-}
-
-func (err *Error) Error() string {
-	return err.Message
-}
-
-type xmlErrors struct {
-	RequestId string
-	Errors    []Error `xml:"Errors>Error"`
-	Error     Error   `xml:"Error"`
-}
+// Error encapsulates an error returned by SNS.
+type Error = aws.Error
 
 func (sns *SNS) query(params map[string]string, resp interface{}) error {
 	params["Timestamp"] = time.Now().UTC().Format(time.RFC3339)
@@ -77,7 +67,11 @@ func (sns *SNS) query(params map[string]string, resp interface{}) error {
 
 	sign(sns.Auth, "GET", "/", params, u.Host)
 	u.RawQuery = multimap(params).Encode()
-	r, err := http.Get(u.String())
+	client := sns.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	r, err := client.Get(u.String())
 	if err != nil {
 		return err
 	}
@@ -91,21 +85,7 @@ func (sns *SNS) query(params map[string]string, resp interface{}) error {
 }
 
 func buildError(r *http.Response) error {
-
-	errors := xmlErrors{}
-	xml.NewDecoder(r.Body).Decode(&errors)
-
-	err := errors.Error
-	if len(errors.Errors) > 0 {
-		err = errors.Errors[0]
-	}
-
-	err.RequestId = errors.RequestId
-	err.StatusCode = r.StatusCode
-	if err.Message == "" {
-		err.Message = r.Status
-	}
-	return &err
+	return aws.BuildError(r)
 }
 
 func multimap(p map[string]string) url.Values {
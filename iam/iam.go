@@ -79,19 +79,7 @@ func (iam *IAM) postQuery(params map[string]string, resp interface{}) error {
 }
 
 func buildError(r *http.Response) error {
-	var (
-		err    Error
-		errors xmlErrors
-	)
-	xml.NewDecoder(r.Body).Decode(&errors)
-	if len(errors.Errors) > 0 {
-		err = errors.Errors[0]
-	}
-	err.StatusCode = r.StatusCode
-	if err.Message == "" {
-		err.Message = r.Status
-	}
-	return &err
+	return aws.BuildError(r)
 }
 
 func multimap(p map[string]string) url.Values {
@@ -500,10 +488,6 @@ type SimpleResp struct {
 	RequestId string `xml:"ResponseMetadata>RequestId"`
 }
 
-type xmlErrors struct {
-	Errors []Error `xml:"Error"`
-}
-
 // ServerCertificateMetadata represents a ServerCertificateMetadata object
 //
 // See http://goo.gl/Rfu7LD for more details.
@@ -620,24 +604,5 @@ func (iam *IAM) DeleteServerCertificate(serverCertificateName string) (*SimpleRe
 }
 
 // Error encapsulates an IAM error.
-type Error struct {
-	// HTTP status code of the error.
-	StatusCode int
-
-	// AWS code of the error.
-	Code string
+type Error = aws.Error
 
-	// Message explaining the error.
-	Message string
-}
-
-func (e *Error) Error() string {
-	var prefix string
-	if e.Code != "" {
-		prefix = e.Code + ": "
-	}
-	if prefix == "" && e.StatusCode > 0 {
-		prefix = strconv.Itoa(e.StatusCode) + ": "
-	}
-	return prefix + e.Message
-}
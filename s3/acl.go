@@ -0,0 +1,155 @@
+package s3
+
+import (
+	"encoding/xml"
+	"net/url"
+)
+
+// Permissions granted by ACLs.
+const (
+	PermissionFullControl = "FULL_CONTROL"
+	PermissionRead        = "READ"
+	PermissionWrite       = "WRITE"
+	PermissionReadACP     = "READ_ACP"
+	PermissionWriteACP    = "WRITE_ACP"
+)
+
+// Types of grantees.
+const (
+	GranteeCanonicalUser = "CanonicalUser"
+	GranteeEmail         = "AmazonCustomerByEmail"
+	GranteeGroup         = "Group"
+)
+
+// URIs of the predefined groups of grantees.
+const (
+	AllUsersGroup           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	AuthenticatedUsersGroup = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+	LogDeliveryGroup        = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+)
+
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// AccessControlPolicy is the ACL of a bucket or an object, made of the
+// grants of permissions given by its owner.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/dev/acl-overview.html for details.
+type AccessControlPolicy struct {
+	XMLName xml.Name `xml:"AccessControlPolicy"`
+	Owner   Owner
+	Grants  []Grant `xml:"AccessControlList>Grant"`
+}
+
+// Grant gives Permission to Grantee.
+type Grant struct {
+	Grantee    Grantee
+	Permission string
+}
+
+// Grantee is who a grant is given to: a user by canonical ID or email
+// address, or a group by URI. Type is inferred from the field which is set
+// when empty.
+type Grantee struct {
+	Type         string
+	ID           string
+	DisplayName  string
+	EmailAddress string
+	URI          string
+}
+
+// grantee holds the elements of a Grantee, whose type is given by the
+// xsi:type attribute.
+type grantee struct {
+	ID           string `xml:",omitempty"`
+	DisplayName  string `xml:",omitempty"`
+	EmailAddress string `xml:",omitempty"`
+	URI          string `xml:",omitempty"`
+}
+
+// MarshalXML encodes g with the xsi:type attribute, which encoding/xml
+// can't name with the usual prefix.
+func (g Grantee) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	t := g.Type
+	if t == "" {
+		switch {
+		case g.URI != "":
+			t = GranteeGroup
+		case g.EmailAddress != "":
+			t = GranteeEmail
+		default:
+			t = GranteeCanonicalUser
+		}
+	}
+	start.Attr = []xml.Attr{
+		{Name: xml.Name{Local: "xmlns:xsi"}, Value: xsiNamespace},
+		{Name: xml.Name{Local: "xsi:type"}, Value: t},
+	}
+	return e.EncodeElement(grantee{g.ID, g.DisplayName, g.EmailAddress, g.URI}, start)
+}
+
+// UnmarshalXML decodes g, with its type from the xsi:type attribute.
+func (g *Grantee) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v grantee
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*g = Grantee{ID: v.ID, DisplayName: v.DisplayName, EmailAddress: v.EmailAddress, URI: v.URI}
+	for _, attr := range start.Attr {
+		if attr.Name.Space == xsiNamespace && attr.Name.Local == "type" {
+			g.Type = attr.Value
+		}
+	}
+	return nil
+}
+
+// GetACL returns the ACL of the object at path, or of b itself if path is
+// empty.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectAcl.html for details.
+func (b *Bucket) GetACL(path string) (*AccessControlPolicy, error) {
+	acl := &AccessControlPolicy{}
+	err := b.getSubresource(aclPath(path), "acl", acl)
+	if err != nil {
+		return nil, err
+	}
+	return acl, nil
+}
+
+// PutACL sets the ACL of the object at path, or of b itself if path is
+// empty, replacing the previous one.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObjectAcl.html for details.
+func (b *Bucket) PutACL(path string, acl *AccessControlPolicy) error {
+	return b.putSubresourceXML(aclPath(path), "acl", acl)
+}
+
+// PutCannedACL sets the ACL of the object at path, or of b itself if path
+// is empty, to the canned ACL perm.
+func (b *Bucket) PutCannedACL(path string, perm ACL) error {
+	headers := map[string][]string{
+		"Content-Length": {"0"},
+		"x-amz-acl":      {string(perm)},
+	}
+	var err error
+	for attempt := b.S3.AttemptStrategy.Start(); attempt.Next(); {
+		req := &request{
+			method:  "PUT",
+			bucket:  b.Name,
+			path:    aclPath(path),
+			params:  url.Values{"acl": {""}},
+			headers: headers,
+		}
+		err = b.S3.query(req, nil)
+		if !shouldRetry(err) {
+			break
+		}
+	}
+	return err
+}
+
+func aclPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
@@ -0,0 +1,107 @@
+package s3_test
+
+import (
+	"encoding/xml"
+
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+const ownerID = "75aa57f09aa0c8caeab4f8c24e99d10f8e7faeebf76c078efc7c6caea54ba06a"
+
+// aclExample holds the ACL of GetACLResultDump.
+var aclExample = &s3.AccessControlPolicy{
+	XMLName: xml.Name{Space: "http://s3.amazonaws.com/doc/2006-03-01/", Local: "AccessControlPolicy"},
+	Owner:   s3.Owner{ID: ownerID, DisplayName: "CustomersName@amazon.com"},
+	Grants: []s3.Grant{{
+		Grantee:    s3.Grantee{Type: s3.GranteeCanonicalUser, ID: ownerID, DisplayName: "CustomersName@amazon.com"},
+		Permission: s3.PermissionFullControl,
+	}, {
+		Grantee:    s3.Grantee{Type: s3.GranteeGroup, URI: s3.AllUsersGroup},
+		Permission: s3.PermissionRead,
+	}},
+}
+
+func (s *S) TestGetACL(c *C) {
+	testServer.Response(200, nil, GetACLResultDump)
+
+	b := s.s3.Bucket("sample")
+	acl, err := b.GetACL("photos/cat.jpg")
+	c.Assert(err, IsNil)
+	c.Assert(acl, DeepEquals, aclExample)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.URL.Path, Equals, "/sample/photos/cat.jpg")
+	c.Assert(req.URL.RawQuery, Equals, "acl=")
+}
+
+func (s *S) TestGetBucketACL(c *C) {
+	testServer.Response(200, nil, GetACLResultDump)
+
+	b := s.s3.Bucket("sample")
+	_, err := b.GetACL("")
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "acl=")
+}
+
+func (s *S) TestPutACL(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	acl := &s3.AccessControlPolicy{
+		Owner: s3.Owner{ID: ownerID},
+		Grants: []s3.Grant{{
+			Grantee:    s3.Grantee{ID: ownerID},
+			Permission: s3.PermissionFullControl,
+		}, {
+			Grantee:    s3.Grantee{EmailAddress: "someone@example.com"},
+			Permission: s3.PermissionReadACP,
+		}, {
+			Grantee:    s3.Grantee{URI: s3.LogDeliveryGroup},
+			Permission: s3.PermissionWrite,
+		}},
+	}
+	err := b.PutACL("photos/cat.jpg", acl)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.Path, Equals, "/sample/photos/cat.jpg")
+	c.Assert(req.URL.RawQuery, Equals, "acl=")
+	c.Assert(req.Header["Content-Md5"], HasLen, 1)
+	body := readAll(req.Body)
+	c.Assert(body, Equals, xml.Header+"<AccessControlPolicy>"+
+		"<Owner><ID>"+ownerID+"</ID><DisplayName></DisplayName></Owner>"+
+		"<AccessControlList>"+
+		`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser"><ID>`+ownerID+"</ID></Grantee><Permission>FULL_CONTROL</Permission></Grant>"+
+		`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="AmazonCustomerByEmail"><EmailAddress>someone@example.com</EmailAddress></Grantee><Permission>READ_ACP</Permission></Grant>`+
+		`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group"><URI>http://acs.amazonaws.com/groups/s3/LogDelivery</URI></Grantee><Permission>WRITE</Permission></Grant>`+
+		"</AccessControlList></AccessControlPolicy>")
+
+	// The document round-trips
+	var decoded s3.AccessControlPolicy
+	err = xml.Unmarshal([]byte(body), &decoded)
+	c.Assert(err, IsNil)
+	c.Assert(decoded.Grants[0].Grantee.Type, Equals, s3.GranteeCanonicalUser)
+	c.Assert(decoded.Grants[1].Grantee, Equals, s3.Grantee{Type: s3.GranteeEmail, EmailAddress: "someone@example.com"})
+	c.Assert(decoded.Grants[2].Grantee, Equals, s3.Grantee{Type: s3.GranteeGroup, URI: s3.LogDeliveryGroup})
+}
+
+func (s *S) TestPutCannedACL(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.PutCannedACL("photos/cat.jpg", s3.PublicRead)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.Path, Equals, "/sample/photos/cat.jpg")
+	c.Assert(req.URL.RawQuery, Equals, "acl=")
+	c.Assert(req.Header["X-Amz-Acl"], DeepEquals, []string{"public-read"})
+	c.Assert(readAll(req.Body), Equals, "")
+}
@@ -0,0 +1,128 @@
+package s3_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+// addressingS3 returns an S3 for region whose requests all reach
+// testServer, whatever host they are addressed to.
+func addressingS3(region aws.Region, addressing s3.Addressing) *s3.S3 {
+	u, _ := url.Parse(testServer.URL)
+	client := s3.New(aws.Auth{AccessKey: "abc", SecretKey: "123"}, region)
+	client.AttemptStrategy = aws.AttemptStrategy{}
+	client.RetryPolicy = &aws.NoRetry
+	client.Addressing = addressing
+	client.HTTPClient = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return net.Dial(network, u.Host)
+		},
+	}}
+	return client
+}
+
+var addressingTests = []struct {
+	addressing s3.Addressing
+	bucket     string
+	host       string
+	path       string
+}{
+	{s3.AutoAddressing, "bucket", "bucket.s3.faux-region-1.amazonaws.com", "/name"},
+	{s3.AutoAddressing, "my.bucket", "s3.faux-region-1.amazonaws.com", "/my.bucket/name"},
+	{s3.PathStyle, "bucket", "s3.faux-region-1.amazonaws.com", "/bucket/name"},
+	{s3.VirtualHostedStyle, "my.bucket", "my.bucket.s3.faux-region-1.amazonaws.com", "/name"},
+}
+
+func (s *S) TestAddressing(c *C) {
+	region := aws.Region{
+		Name:             "faux-region-1",
+		S3Endpoint:       "http://s3.faux-region-1.amazonaws.com",
+		S3BucketEndpoint: "http://${bucket}.s3.faux-region-1.amazonaws.com",
+	}
+	for _, t := range addressingTests {
+		c.Logf("addressing %d, bucket %q", t.addressing, t.bucket)
+		b := addressingS3(region, t.addressing).Bucket(t.bucket)
+		c.Assert(b.URL("name"), Equals, "http://"+t.host+t.path)
+
+		testServer.Response(500, nil, InternalErrorDump)
+		err := b.Put("name", []byte("content"), "text/plain", s3.Private, s3.Options{})
+		c.Assert(err, NotNil)
+
+		// The bucket is in the signed resource in both modes
+		req := testServer.WaitRequest()
+		c.Assert(req.Host, Equals, t.host)
+		c.Assert(req.URL.Path, Equals, t.path)
+		headers := map[string][]string{}
+		for k, v := range req.Header {
+			headers[k] = v
+		}
+		s3.Sign(aws.Auth{AccessKey: "abc", SecretKey: "123"}, "PUT", "/"+t.bucket+"/name", nil, headers)
+		c.Assert(req.Header.Get("Authorization"), Equals, headers["Authorization"][0])
+	}
+}
+
+func (s *S) TestAddressingResign(c *C) {
+	region := aws.Region{
+		Name:             "faux-region-1",
+		S3Endpoint:       "http://s3.faux-region-1.amazonaws.com",
+		S3BucketEndpoint: "http://${bucket}.s3.faux-region-1.amazonaws.com",
+	}
+	client := addressingS3(region, s3.AutoAddressing)
+	client.AttemptStrategy = aws.AttemptStrategy{Min: 2}
+	b := client.Bucket("bucket")
+
+	testServer.Response(500, nil, InternalErrorDump)
+	testServer.Response(200, nil, "content")
+	_, err := b.Get("name")
+	c.Assert(err, IsNil)
+
+	for _, req := range testServer.WaitRequests(2) {
+		headers := map[string][]string{}
+		for k, v := range req.Header {
+			headers[k] = v
+		}
+		s3.Sign(aws.Auth{AccessKey: "abc", SecretKey: "123"}, "GET", "/bucket/name", nil, headers)
+		c.Assert(req.Header.Get("Authorization"), Equals, headers["Authorization"][0])
+	}
+}
+
+func (s *S) TestAddressingSignatureV4(c *C) {
+	region := aws.Region{
+		Name:          "faux-region-1",
+		S3Endpoint:    "http://s3.faux-region-1.amazonaws.com",
+		S3SignatureV4: true,
+	}
+	for _, addressing := range []s3.Addressing{s3.PathStyle, s3.VirtualHostedStyle} {
+		b := addressingS3(region, addressing).Bucket("bucket")
+		testServer.Response(200, nil, "")
+		err := b.Put("name", []byte("content"), "text/plain", s3.Private, s3.Options{})
+		c.Assert(err, IsNil)
+
+		// Signing the request as received gives the same signature
+		req := testServer.WaitRequest()
+		if addressing == s3.PathStyle {
+			c.Assert(req.Host+req.URL.Path, Equals, "s3.faux-region-1.amazonaws.com/bucket/name")
+		} else {
+			c.Assert(req.Host+req.URL.Path, Equals, "bucket.s3.faux-region-1.amazonaws.com/name")
+		}
+		signed := &http.Request{
+			Method: "PUT",
+			URL:    &url.URL{Scheme: "http", Host: req.Host, Path: req.URL.Path},
+			Host:   req.Host,
+			Header: http.Header{},
+		}
+		for _, k := range strings.Split("Content-Type X-Amz-Acl X-Amz-Content-Sha256 X-Amz-Date", " ") {
+			signed.Header[k] = req.Header[k]
+		}
+		signed.Header.Set("Content-Length", "7")
+		aws.SignV4(signed, aws.Auth{AccessKey: "abc", SecretKey: "123"}, "faux-region-1", "s3")
+		c.Assert(req.Header.Get("Authorization"), Equals, signed.Header.Get("Authorization"))
+	}
+}
@@ -0,0 +1,150 @@
+package s3
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotModified is returned by GetWithConditions and HeadWithConditions
+// when the object matches IfNoneMatch or wasn't modified since
+// IfModifiedSince.
+var ErrNotModified = errors.New("s3: object not modified")
+
+// ErrPreconditionFailed is returned by GetWithConditions and
+// HeadWithConditions when the object doesn't match IfMatch or was
+// modified since IfUnmodifiedSince.
+var ErrPreconditionFailed = errors.New("s3: object precondition failed")
+
+// Conditions on an object retrieved with GetWithConditions or
+// HeadWithConditions. The conditions which are set must all hold.
+type Conditions struct {
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   time.Time
+	IfUnmodifiedSince time.Time
+}
+
+func (c Conditions) headers() map[string][]string {
+	headers := make(map[string][]string)
+	if c.IfMatch != "" {
+		headers["If-Match"] = []string{c.IfMatch}
+	}
+	if c.IfNoneMatch != "" {
+		headers["If-None-Match"] = []string{c.IfNoneMatch}
+	}
+	if !c.IfModifiedSince.IsZero() {
+		headers["If-Modified-Since"] = []string{c.IfModifiedSince.UTC().Format(http.TimeFormat)}
+	}
+	if !c.IfUnmodifiedSince.IsZero() {
+		headers["If-Unmodified-Since"] = []string{c.IfUnmodifiedSince.UTC().Format(http.TimeFormat)}
+	}
+	return headers
+}
+
+// ObjectInfo is the metadata of an object reported by the headers of S3
+// responses.
+type ObjectInfo struct {
+	ETag          string
+	LastModified  time.Time
+	ContentLength int64
+	ContentType   string
+
+	// StorageClass is empty for objects in StorageStandard.
+	StorageClass string
+
+	// VersionId is empty for objects in buckets without versioning.
+	VersionId string
+
+	// SSE is the server-side encryption of the object, "AES256" or
+	// "aws:kms", and SSEKMSKeyId the KMS key for the latter.
+	// SSECustomerAlgorithm is set instead for objects encrypted with a key
+	// of the caller.
+	SSE                  string
+	SSEKMSKeyId          string
+	SSECustomerAlgorithm string
+
+	// Restore is the status of the restoration of an archived object,
+	// nil if none was requested.
+	Restore *RestoreStatus
+
+	// Meta holds the x-amz-meta-* headers, by lowercase name without the
+	// prefix as in Options.Meta.
+	Meta map[string][]string
+}
+
+// ResponseInfo returns the metadata of the object reported by the headers
+// of resp, as returned by GetResponse or Head.
+func ResponseInfo(resp *http.Response) *ObjectInfo {
+	info := &ObjectInfo{
+		ETag:         resp.Header.Get("ETag"),
+		ContentType:  resp.Header.Get("Content-Type"),
+		StorageClass: resp.Header.Get("x-amz-storage-class"),
+		VersionId:    resp.Header.Get("x-amz-version-id"),
+		SSE:          resp.Header.Get("x-amz-server-side-encryption"),
+		SSEKMSKeyId:  resp.Header.Get("x-amz-server-side-encryption-aws-kms-key-id"),
+		Restore:      parseRestore(resp.Header.Get("x-amz-restore")),
+		Meta:         responseMeta(resp.Header),
+
+		SSECustomerAlgorithm: resp.Header.Get("x-amz-server-side-encryption-customer-algorithm"),
+	}
+	info.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	info.ContentLength, _ = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	return info
+}
+
+// responseMeta returns the x-amz-meta-* headers of header, by lowercase
+// name without the prefix.
+func responseMeta(header http.Header) map[string][]string {
+	meta := make(map[string][]string)
+	for k, v := range header {
+		if strings.HasPrefix(strings.ToLower(k), "x-amz-meta-") {
+			meta[strings.ToLower(k[len("x-amz-meta-"):])] = v
+		}
+	}
+	return meta
+}
+
+// GetWithConditions retrieves an object from an S3 bucket if it meets the
+// given conditions, returning its body and metadata. It returns
+// ErrNotModified or ErrPreconditionFailed if it doesn't.
+//
+// It is the caller's responsibility to call Close on rc when finished
+// reading.
+func (b *Bucket) GetWithConditions(path string, c Conditions) (rc io.ReadCloser, info *ObjectInfo, err error) {
+	resp, err := b.getResponse(path, "", c.headers())
+	if err != nil {
+		return nil, nil, conditionError(err)
+	}
+	return resp.Body, ResponseInfo(resp), nil
+}
+
+// HeadWithConditions returns the metadata of an object in an S3 bucket if
+// it meets the given conditions, and ErrNotModified or
+// ErrPreconditionFailed if it doesn't.
+func (b *Bucket) HeadWithConditions(path string, c Conditions) (*ObjectInfo, error) {
+	resp, err := b.Head(path, c.headers())
+	if err != nil {
+		return nil, conditionError(err)
+	}
+	resp.Body.Close()
+	return ResponseInfo(resp), nil
+}
+
+// conditionError returns the error of the failure of conditions err
+// reports, if any, and err otherwise. The responses to HEAD requests have
+// no body telling the code of the error.
+func conditionError(err error) error {
+	if e, ok := err.(*Error); ok {
+		switch e.StatusCode {
+		case 304:
+			return ErrNotModified
+		case 412:
+			return ErrPreconditionFailed
+		}
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+package s3_test
+
+import (
+	"time"
+
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) TestGetWithConditions(c *C) {
+	testServer.Response(200, map[string]string{
+		"ETag":              `"828ef3fdfa96f00ad9f27c383fc9ac7f"`,
+		"Last-Modified":     "Wed, 28 Oct 2009 22:32:00 GMT",
+		"Content-Type":      "text/plain",
+		"x-amz-meta-color":  "blue",
+		"x-amz-meta-Origin": "upload",
+	}, "content")
+
+	b := s.s3.Bucket("bucket")
+	rc, info, err := b.GetWithConditions("name", s3.Conditions{
+		IfNoneMatch:     `"0123"`,
+		IfModifiedSince: time.Date(2009, 10, 1, 0, 0, 0, 0, time.UTC),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(readAll(rc), Equals, "content")
+	rc.Close()
+	c.Assert(info, DeepEquals, &s3.ObjectInfo{
+		ETag:          `"828ef3fdfa96f00ad9f27c383fc9ac7f"`,
+		LastModified:  time.Date(2009, 10, 28, 22, 32, 0, 0, time.UTC),
+		ContentLength: 7,
+		ContentType:   "text/plain",
+		Meta:          map[string][]string{"color": {"blue"}, "origin": {"upload"}},
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.Header["If-None-Match"], DeepEquals, []string{`"0123"`})
+	c.Assert(req.Header["If-Modified-Since"], DeepEquals, []string{"Thu, 01 Oct 2009 00:00:00 GMT"})
+	c.Assert(req.Header["If-Match"], IsNil)
+	c.Assert(req.Header["If-Unmodified-Since"], IsNil)
+}
+
+func (s *S) TestGetWithConditionsNotModified(c *C) {
+	testServer.Response(304, nil, "")
+
+	b := s.s3.Bucket("bucket")
+	_, _, err := b.GetWithConditions("name", s3.Conditions{IfNoneMatch: `"0123"`})
+	c.Assert(err, Equals, s3.ErrNotModified)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestGetWithConditionsPreconditionFailed(c *C) {
+	testServer.Response(412, nil, PreconditionFailedErrorDump)
+
+	b := s.s3.Bucket("bucket")
+	_, _, err := b.GetWithConditions("name", s3.Conditions{IfMatch: `"0123"`})
+	c.Assert(err, Equals, s3.ErrPreconditionFailed)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["If-Match"], DeepEquals, []string{`"0123"`})
+}
+
+func (s *S) TestHeadWithConditions(c *C) {
+	testServer.Response(200, map[string]string{"ETag": `"0123"`, "Content-Length": "42"}, "")
+
+	b := s.s3.Bucket("bucket")
+	info, err := b.HeadWithConditions("name", s3.Conditions{IfUnmodifiedSince: time.Date(2009, 10, 28, 22, 32, 0, 0, time.UTC)})
+	c.Assert(err, IsNil)
+	c.Assert(info.ETag, Equals, `"0123"`)
+	c.Assert(info.ContentLength, Equals, int64(42))
+	c.Assert(info.Meta, HasLen, 0)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "HEAD")
+	c.Assert(req.Header["If-Unmodified-Since"], DeepEquals, []string{"Wed, 28 Oct 2009 22:32:00 GMT"})
+
+	// Responses to HEAD requests have no body
+	testServer.Response(304, nil, "")
+	_, err = b.HeadWithConditions("name", s3.Conditions{IfNoneMatch: `"0123"`})
+	c.Assert(err, Equals, s3.ErrNotModified)
+	testServer.WaitRequest()
+
+	testServer.Response(412, nil, "")
+	_, err = b.HeadWithConditions("name", s3.Conditions{IfMatch: `"4567"`})
+	c.Assert(err, Equals, s3.ErrPreconditionFailed)
+	testServer.WaitRequest()
+}
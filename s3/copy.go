@@ -0,0 +1,100 @@
+package s3
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxCopySize is the size of the largest object copied with a single
+// request, above which objects are copied with a multipart upload.
+var maxCopySize int64 = 5 << 30
+
+// copyPartSize is the size of the parts of multipart copies, raised for
+// the largest objects to stay within the limit of parts of an upload.
+var copyPartSize int64 = 512 << 20
+
+const maxParts = 10000
+
+// Copy copies the source object, given as "bucket/key", to dest in b as
+// PutCopy does, with a multipart upload copying it in parts for objects
+// larger than the 5 GB PutCopy is limited to.
+//
+// The metadata of the source object is kept unless options.MetadataDirective
+// is REPLACE, in which case the content type and metadata of options are
+// used. Unless conditions are given in options, a multipart copy fails if
+// the source object changes while it is copied. The result of a multipart
+// copy has no LastModified.
+func (b *Bucket) Copy(dest string, perm ACL, options CopyOptions, source string) (*CopyObjectResult, error) {
+	source = strings.TrimPrefix(source, "/")
+	i := strings.Index(source, "/")
+	if i < 0 {
+		return nil, fmt.Errorf("s3: invalid copy source %q", source)
+	}
+	key, err := url.PathUnescape(source[i+1:])
+	if err != nil {
+		return nil, fmt.Errorf("s3: invalid copy source %q", source)
+	}
+	srcBucket := b.S3.Bucket(source[:i])
+
+	headers := sseCustomerHeaders("x-amz-server-side-encryption-customer-", options.CopySourceSSECustomerAlgorithm, options.CopySourceSSECustomerKey, options.CopySourceSSECustomerKeyMD5)
+	head, err := srcBucket.HeadVersion(key, options.CopySourceVersionId, headers)
+	if err != nil {
+		return nil, err
+	}
+	head.Body.Close()
+	size, err := strconv.ParseInt(head.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("s3: invalid size of copy source %q: %v", source, err)
+	}
+	if size <= maxCopySize {
+		return b.PutCopy(dest, perm, options, source)
+	}
+
+	contType := options.ContentType
+	if options.MetadataDirective != "REPLACE" {
+		contType = head.Header.Get("Content-Type")
+		copySourceMetadata(&options.Options, head.Header)
+	}
+	if options.CopySourceIfMatch == "" && options.CopySourceIfUnmodifiedSince.IsZero() {
+		options.CopySourceIfMatch = head.Header.Get("ETag")
+	}
+	m, err := b.InitMultiWithOptions(dest, contType, perm, options.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	partSize := copyPartSize
+	if size > partSize*maxParts {
+		partSize = (size + maxParts - 1) / maxParts
+	}
+	var parts []Part
+	for start, n := int64(0), 1; start < size; start, n = start+partSize, n+1 {
+		end := start + partSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		part, err := m.PutPartCopy(n, options, source, start, end)
+		if err != nil {
+			m.Abort()
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	result, err := m.complete(parts)
+	if err != nil {
+		m.Abort()
+		return nil, err
+	}
+	return &CopyObjectResult{ETag: result.ETag}, nil
+}
+
+// copySourceMetadata sets the metadata of o to the one of the object with
+// the given headers.
+func copySourceMetadata(o *Options, header http.Header) {
+	o.ContentEncoding = header.Get("Content-Encoding")
+	o.CacheControl = header.Get("Cache-Control")
+	o.Meta = responseMeta(header)
+}
@@ -0,0 +1,102 @@
+package s3
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+// CORSRule allows the requests of browsers from AllowedOrigins, such as
+// "https://example.com" or "*", with AllowedMethods and AllowedHeaders.
+// ExposeHeaders are the response headers browsers let scripts read, and
+// MaxAgeSeconds is how long browsers may cache the response to a preflight
+// request.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/dev/cors.html for details.
+type CORSRule struct {
+	ID             string   `xml:"ID,omitempty"`
+	AllowedOrigins []string `xml:"AllowedOrigin"`
+	AllowedMethods []string `xml:"AllowedMethod"`
+	AllowedHeaders []string `xml:"AllowedHeader,omitempty"`
+	ExposeHeaders  []string `xml:"ExposeHeader,omitempty"`
+	MaxAgeSeconds  int      `xml:"MaxAgeSeconds,omitempty"`
+}
+
+type corsConfiguration struct {
+	XMLName xml.Name   `xml:"CORSConfiguration"`
+	Rules   []CORSRule `xml:"CORSRule"`
+}
+
+// ErrNoCORSConfiguration is returned by GetCORS for buckets without a CORS
+// configuration.
+var ErrNoCORSConfiguration = errors.New("s3: the bucket has no CORS configuration")
+
+// maxCORSRules is the maximum number of rules of a CORS configuration.
+const maxCORSRules = 100
+
+var corsMethods = map[string]bool{
+	"GET":    true,
+	"PUT":    true,
+	"POST":   true,
+	"DELETE": true,
+	"HEAD":   true,
+}
+
+// validateCORS returns an error if S3 would reject rules.
+func validateCORS(rules []CORSRule) error {
+	if len(rules) == 0 {
+		return errors.New("CORS configuration has no rules, use DelCORS to remove it")
+	}
+	if len(rules) > maxCORSRules {
+		return fmt.Errorf("CORS configuration has %d rules, more than %d", len(rules), maxCORSRules)
+	}
+	for i, rule := range rules {
+		if len(rule.AllowedOrigins) == 0 || len(rule.AllowedMethods) == 0 {
+			return fmt.Errorf("CORS rule %d has no allowed origin or method", i)
+		}
+		for _, method := range rule.AllowedMethods {
+			if !corsMethods[method] {
+				return fmt.Errorf("CORS rule %d allows unsupported method %q", i, method)
+			}
+		}
+		if rule.MaxAgeSeconds < 0 {
+			return fmt.Errorf("CORS rule %d has negative MaxAgeSeconds %d", i, rule.MaxAgeSeconds)
+		}
+	}
+	return nil
+}
+
+// PutCORS sets the CORS configuration of b to rules, replacing any previous
+// one. At most 100 rules are allowed, with the GET, PUT, POST, DELETE and
+// HEAD methods.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketPUTcors.html for details.
+func (b *Bucket) PutCORS(rules []CORSRule) error {
+	if err := validateCORS(rules); err != nil {
+		return err
+	}
+	return b.putSubresourceXML("/", "cors", &corsConfiguration{Rules: rules})
+}
+
+// GetCORS returns the CORS rules of b, or ErrNoCORSConfiguration if it has
+// none.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketGETcors.html for details.
+func (b *Bucket) GetCORS() ([]CORSRule, error) {
+	var config corsConfiguration
+	err := b.getSubresource("/", "cors", &config)
+	if hasCode(err, "NoSuchCORSConfiguration") {
+		return nil, ErrNoCORSConfiguration
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config.Rules, nil
+}
+
+// DelCORS removes the CORS configuration of b.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketDELETEcors.html for details.
+func (b *Bucket) DelCORS() error {
+	return b.delSubresource("/", "cors")
+}
@@ -0,0 +1,119 @@
+package s3_test
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"io/ioutil"
+
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+// corsExample holds the rules of GetCORSResultDump.
+var corsExample = []s3.CORSRule{{
+	ID:             "uploads",
+	AllowedOrigins: []string{"https://example.com", "https://www.example.com"},
+	AllowedMethods: []string{"PUT", "POST"},
+	AllowedHeaders: []string{"*"},
+	ExposeHeaders:  []string{"ETag"},
+	MaxAgeSeconds:  3000,
+}, {
+	AllowedOrigins: []string{"*"},
+	AllowedMethods: []string{"GET"},
+}}
+
+func (s *S) TestPutCORS(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.PutCORS(corsExample)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "cors=")
+
+	body, err := ioutil.ReadAll(req.Body)
+	c.Assert(err, IsNil)
+	sum := md5.Sum(body)
+	c.Assert(req.Header["Content-Md5"], DeepEquals, []string{base64.StdEncoding.EncodeToString(sum[:])})
+
+	// The body holds the same rules as the fixture
+	var sent, expected struct {
+		XMLName xml.Name
+		Rules   []s3.CORSRule `xml:"CORSRule"`
+	}
+	c.Assert(xml.Unmarshal(body, &sent), IsNil)
+	c.Assert(xml.Unmarshal([]byte(GetCORSResultDump), &expected), IsNil)
+	c.Assert(sent.XMLName.Local, Equals, "CORSConfiguration")
+	c.Assert(sent.Rules, DeepEquals, expected.Rules)
+}
+
+var invalidCORSTests = []struct {
+	rules []s3.CORSRule
+	err   string
+}{{
+	nil,
+	"CORS configuration has no rules, use DelCORS to remove it",
+}, {
+	make([]s3.CORSRule, 101),
+	"CORS configuration has 101 rules, more than 100",
+}, {
+	[]s3.CORSRule{{AllowedMethods: []string{"GET"}}},
+	"CORS rule 0 has no allowed origin or method",
+}, {
+	[]s3.CORSRule{corsExample[0], {AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET", "PATCH"}}},
+	`CORS rule 1 allows unsupported method "PATCH"`,
+}, {
+	[]s3.CORSRule{{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"get"}}},
+	`CORS rule 0 allows unsupported method "get"`,
+}, {
+	[]s3.CORSRule{{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}, MaxAgeSeconds: -1}},
+	"CORS rule 0 has negative MaxAgeSeconds -1",
+}}
+
+func (s *S) TestPutCORSInvalid(c *C) {
+	b := s.s3.Bucket("sample")
+	for i, t := range invalidCORSTests {
+		err := b.PutCORS(t.rules)
+		c.Check(err, ErrorMatches, t.err, Commentf("test %d", i))
+	}
+}
+
+func (s *S) TestGetCORS(c *C) {
+	testServer.Response(200, nil, GetCORSResultDump)
+
+	b := s.s3.Bucket("sample")
+	rules, err := b.GetCORS()
+	c.Assert(err, IsNil)
+	c.Assert(rules, DeepEquals, corsExample)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "cors=")
+}
+
+func (s *S) TestGetCORSNotConfigured(c *C) {
+	testServer.Response(404, nil, NoSuchCORSConfigurationErrorDump)
+
+	b := s.s3.Bucket("sample")
+	rules, err := b.GetCORS()
+	c.Assert(rules, IsNil)
+	c.Assert(err, Equals, s3.ErrNoCORSConfiguration)
+}
+
+func (s *S) TestDelCORS(c *C) {
+	testServer.Response(204, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.DelCORS()
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "DELETE")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "cors=")
+}
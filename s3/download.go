@@ -0,0 +1,137 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// GetRange retrieves the bytes from from to to, inclusive, of an object in
+// the S3 bucket. A negative to retrieves the object up to its end. The
+// range is cut short at the end of the object, and an InvalidRange error
+// is returned if it starts past it.
+//
+// It is the caller's responsibility to call Close on rc when finished
+// reading.
+func (b *Bucket) GetRange(path string, from, to int64) (rc io.ReadCloser, err error) {
+	return b.getRange(path, from, to, "")
+}
+
+// getRange retrieves a range of the object as GetRange does, if it still
+// has the given ETag.
+func (b *Bucket) getRange(path string, from, to int64, etag string) (io.ReadCloser, error) {
+	r := fmt.Sprintf("bytes=%d-", from)
+	if to >= 0 {
+		r += strconv.FormatInt(to, 10)
+	}
+	headers := map[string][]string{"Range": {r}}
+	if etag != "" {
+		headers["If-Match"] = []string{etag}
+	}
+	resp, err := b.getResponse(path, "", headers)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 206 || !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", from)) {
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3: range %s of %q got status %d and Content-Range %q", r, path, resp.StatusCode, resp.Header.Get("Content-Range"))
+	}
+	return resp.Body, nil
+}
+
+// DownloadTo writes the object at path to w, retrieving ranges of
+// partSize bytes with up to concurrency requests at a time. Ranges which
+// fail are retrieved again from where they stopped, as the AttemptStrategy
+// of the S3 allows.
+//
+// Every range is requested with the ETag of the object at the start of
+// the download, so that the download fails with a PreconditionFailed
+// error if the object changes meanwhile.
+func (b *Bucket) DownloadTo(w io.WriterAt, path string, partSize int64, concurrency int) error {
+	if partSize <= 0 {
+		return errors.New("s3: download part size must be positive")
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	head, err := b.Head(path, nil)
+	if err != nil {
+		return err
+	}
+	head.Body.Close()
+	size, err := strconv.ParseInt(head.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("s3: invalid size of %q: %v", path, err)
+	}
+	etag := head.Header.Get("ETag")
+
+	var (
+		mu      sync.Mutex
+		failure error
+		wg      sync.WaitGroup
+	)
+	failed := make(chan struct{})
+	fail := func(err error) {
+		mu.Lock()
+		if failure == nil {
+			failure = err
+			close(failed)
+		}
+		mu.Unlock()
+	}
+	starts := make(chan int64)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range starts {
+				end := start + partSize - 1
+				if end >= size {
+					end = size - 1
+				}
+				if err := b.downloadRange(w, path, etag, start, end); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+NextRange:
+	for start := int64(0); start < size; start += partSize {
+		select {
+		case starts <- start:
+		case <-failed:
+			break NextRange
+		}
+	}
+	close(starts)
+	wg.Wait()
+	return failure
+}
+
+// downloadRange writes the bytes from start to end of the object with the
+// given ETag to w, at the same offsets.
+func (b *Bucket) downloadRange(w io.WriterAt, path, etag string, start, end int64) error {
+	for attempt := b.S3.AttemptStrategy.Start(); attempt.Next(); {
+		rc, err := b.getRange(path, start, end, etag)
+		if err == nil {
+			var n int64
+			n, err = io.Copy(io.NewOffsetWriter(w, start), io.LimitReader(rc, end-start+1))
+			rc.Close()
+			start += n
+			if err == nil && start <= end {
+				err = io.ErrUnexpectedEOF
+			}
+			if err == nil {
+				return nil
+			}
+		}
+		if shouldRetry(err) && attempt.HasNext() {
+			continue
+		}
+		return err
+	}
+	panic("unreachable")
+}
@@ -0,0 +1,108 @@
+package s3_test
+
+import (
+	"sync"
+
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+// writerAt is an io.WriterAt writing to a byte slice grown as needed.
+type writerAt struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if end := int(off) + len(p); end > len(w.data) {
+		w.data = append(w.data, make([]byte, end-len(w.data))...)
+	}
+	copy(w.data[off:], p)
+	return len(p), nil
+}
+
+func (s *S) TestGetRange(c *C) {
+	testServer.Response(206, map[string]string{"Content-Range": "bytes 2-5/10"}, "cdef")
+
+	b := s.s3.Bucket("bucket")
+	rc, err := b.GetRange("name", 2, 5)
+	c.Assert(err, IsNil)
+	c.Assert(readAll(rc), Equals, "cdef")
+	rc.Close()
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.URL.Path, Equals, "/bucket/name")
+	c.Assert(req.Header["Range"], DeepEquals, []string{"bytes=2-5"})
+
+	// To the end
+	testServer.Response(206, map[string]string{"Content-Range": "bytes 2-9/10"}, "cdefghij")
+	rc, err = b.GetRange("name", 2, -1)
+	c.Assert(err, IsNil)
+	rc.Close()
+	req = testServer.WaitRequest()
+	c.Assert(req.Header["Range"], DeepEquals, []string{"bytes=2-"})
+}
+
+func (s *S) TestGetRangeIgnored(c *C) {
+	testServer.Response(200, nil, "abcdefghij")
+
+	b := s.s3.Bucket("bucket")
+	_, err := b.GetRange("name", 2, 5)
+	c.Assert(err, ErrorMatches, `s3: range bytes=2-5 of "name" got status 200 and Content-Range ""`)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestGetRangePastEnd(c *C) {
+	testServer.Response(416, nil, InvalidRangeErrorDump)
+
+	b := s.s3.Bucket("bucket")
+	_, err := b.GetRange("name", 100, -1)
+	c.Assert(err, ErrorMatches, `The requested range is not satisfiable \(InvalidRange\)`)
+	c.Assert(err.(*s3.Error).StatusCode, Equals, 416)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestDownloadToResumes(c *C) {
+	testServer.Response(200, map[string]string{"Content-Length": "10", "ETag": `"etag"`}, "")
+	// The first range is cut short and resumed
+	testServer.Response(206, map[string]string{"Content-Range": "bytes 0-5/10"}, "abc")
+	testServer.Response(206, map[string]string{"Content-Range": "bytes 3-5/10"}, "def")
+	testServer.Response(206, map[string]string{"Content-Range": "bytes 6-9/10"}, "ghij")
+
+	b := s.s3.Bucket("bucket")
+	w := &writerAt{}
+	err := b.DownloadTo(w, "name", 6, 1)
+	c.Assert(err, IsNil)
+	c.Assert(string(w.data), Equals, "abcdefghij")
+
+	reqs := testServer.WaitRequests(4)
+	c.Assert(reqs[0].Method, Equals, "HEAD")
+	for i, r := range []string{"bytes=0-5", "bytes=3-5", "bytes=6-9"} {
+		c.Assert(reqs[i+1].Header["Range"], DeepEquals, []string{r})
+		c.Assert(reqs[i+1].Header["If-Match"], DeepEquals, []string{`"etag"`})
+	}
+}
+
+func (s *S) TestDownloadToEmpty(c *C) {
+	testServer.Response(200, map[string]string{"Content-Length": "0"}, "")
+
+	b := s.s3.Bucket("bucket")
+	w := &writerAt{}
+	err := b.DownloadTo(w, "name", 6, 4)
+	c.Assert(err, IsNil)
+	c.Assert(w.data, HasLen, 0)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestDownloadToChanged(c *C) {
+	testServer.Response(200, map[string]string{"Content-Length": "10", "ETag": `"etag"`}, "")
+	testServer.Response(412, nil, PreconditionFailedErrorDump)
+
+	b := s.s3.Bucket("bucket")
+	err := b.DownloadTo(&writerAt{}, "name", 10, 1)
+	c.Assert(err, ErrorMatches, `.* \(PreconditionFailed\)`)
+	testServer.WaitRequests(2)
+}
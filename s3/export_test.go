@@ -1,6 +1,8 @@
 package s3
 
 import (
+	"time"
+
 	"github.com/goamz/goamz/aws"
 )
 
@@ -15,3 +17,19 @@ func SetListPartsMax(n int) {
 func SetListMultiMax(n int) {
 	listMultiMax = n
 }
+
+func SetDelMultiMax(n int) {
+	delMultiMax = n
+}
+
+func SetMaxCopySize(n int64) {
+	maxCopySize = n
+}
+
+func SetCopyPartSize(n int64) {
+	copyPartSize = n
+}
+
+func PostPolicyAt(b *Bucket, key string, expires time.Time, conds []PostCondition, now time.Time) (map[string]string, error) {
+	return b.postPolicy(key, expires, conds, now)
+}
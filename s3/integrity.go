@@ -0,0 +1,129 @@
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// IntegrityError is returned when the MD5 digest of the content of an
+// object doesn't match the ETag S3 reports for it.
+type IntegrityError struct {
+	Path string
+
+	// Expected and Actual are the hex encoded MD5 digests reported by S3
+	// and computed from the content.
+	Expected string
+	Actual   string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("s3: integrity check of %q failed: S3 has MD5 %s, content has %s", e.Path, e.Expected, e.Actual)
+}
+
+// etagMD5 returns the hex encoded MD5 digest etag is of, or an empty
+// string if it isn't the digest of the content, as for objects uploaded
+// in parts.
+func etagMD5(etag string) string {
+	etag = strings.Trim(etag, `"`)
+	if len(etag) != 2*md5.Size || strings.Contains(etag, "-") {
+		return ""
+	}
+	return etag
+}
+
+// etagIsMD5 reports whether the ETag of the object with the given headers
+// is the MD5 digest of its content, which isn't the case when the object
+// was uploaded in parts or is encrypted with a KMS key or a key of the
+// caller.
+func etagIsMD5(header http.Header) bool {
+	return etagMD5(header.Get("ETag")) != "" &&
+		header.Get("x-amz-server-side-encryption") != "aws:kms" &&
+		header.Get("x-amz-server-side-encryption-customer-algorithm") == ""
+}
+
+// seekerMD5 returns the base64 encoded MD5 digest of the content of r
+// from its current position, to which r is rewound.
+func seekerMD5(r io.ReadSeeker) (string, error) {
+	pos, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", err
+	}
+	h := md5.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	if _, err := r.Seek(pos, io.SeekStart); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// checkETag returns an IntegrityError if the ETag of the object at path
+// given by header isn't the digest sum.
+func checkETag(path string, header http.Header, sum []byte) error {
+	if !etagIsMD5(header) {
+		return nil
+	}
+	expected := etagMD5(header.Get("ETag"))
+	if actual := hex.EncodeToString(sum); actual != expected {
+		return &IntegrityError{Path: path, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// verifyingReader reads the body of an object and returns an
+// IntegrityError instead of io.EOF if the content doesn't match the ETag.
+type verifyingReader struct {
+	io.ReadCloser
+	path   string
+	header http.Header
+	h      hash.Hash
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.h.Write(p[:n])
+	if err == io.EOF {
+		if cerr := checkETag(r.path, r.header, r.h.Sum(nil)); cerr != nil {
+			return n, cerr
+		}
+	}
+	return n, err
+}
+
+// GetReaderVerified retrieves an object from an S3 bucket as GetReader
+// does. Reading the body returns an IntegrityError at its end if it
+// doesn't match the ETag of the object. Objects whose ETag isn't the MD5
+// digest of their content, because they were uploaded in parts or are
+// encrypted with a KMS key or a key of the caller, aren't verified.
+//
+// It is the caller's responsibility to call Close on rc when finished
+// reading.
+func (b *Bucket) GetReaderVerified(path string) (rc io.ReadCloser, err error) {
+	resp, err := b.GetResponse(path)
+	if err != nil {
+		return nil, err
+	}
+	if !etagIsMD5(resp.Header) {
+		return resp.Body, nil
+	}
+	return &verifyingReader{resp.Body, path, resp.Header, md5.New()}, nil
+}
+
+// GetVerified retrieves an object from an S3 bucket as Get does, verified
+// as GetReaderVerified does.
+func (b *Bucket) GetVerified(path string) ([]byte, error) {
+	rc, err := b.GetReaderVerified(path)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
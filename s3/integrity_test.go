@@ -0,0 +1,112 @@
+package s3_test
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+const (
+	contentMD5    = "9a0364b9e99bb480dd25e1f0284c8555"
+	contentMD5b64 = "mgNkuembtIDdJeHwKEyFVQ=="
+)
+
+func (s *S) TestPutChecksum(c *C) {
+	testServer.Response(200, map[string]string{"ETag": `"` + contentMD5 + `"`}, "")
+
+	b := s.s3.Bucket("bucket")
+	err := b.Put("name", []byte("content"), "text/plain", s3.Private, s3.Options{Checksum: true})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["Content-Md5"], DeepEquals, []string{contentMD5b64})
+	c.Assert(readAll(req.Body), Equals, "content")
+}
+
+func (s *S) TestPutChecksumMismatch(c *C) {
+	testServer.Response(200, map[string]string{"ETag": `"0123456789abcdef0123456789abcdef"`}, "")
+
+	b := s.s3.Bucket("bucket")
+	err := b.Put("name", []byte("content"), "text/plain", s3.Private, s3.Options{Checksum: true})
+	c.Assert(err, DeepEquals, &s3.IntegrityError{Path: "name", Expected: "0123456789abcdef0123456789abcdef", Actual: contentMD5})
+	c.Assert(err, ErrorMatches, `s3: integrity check of "name" failed: S3 has MD5 0123456789abcdef0123456789abcdef, content has `+contentMD5)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestPutReaderChecksumStreaming(c *C) {
+	testServer.Response(200, map[string]string{"ETag": `"0123456789abcdef0123456789abcdef"`}, "")
+
+	// Readers which can't be rewound are hashed while they are sent
+	b := s.s3.Bucket("bucket")
+	buf := bytes.NewBufferString("content")
+	err := b.PutReader("name", buf, int64(buf.Len()), "text/plain", s3.Private, s3.Options{Checksum: true})
+	c.Assert(err, FitsTypeOf, &s3.IntegrityError{})
+	c.Assert(err.(*s3.IntegrityError).Actual, Equals, contentMD5)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["Content-Md5"], IsNil)
+	c.Assert(readAll(req.Body), Equals, "content")
+}
+
+func (s *S) TestPutChecksumGivenMD5(c *C) {
+	testServer.Response(200, map[string]string{"ETag": `"` + contentMD5 + `"`}, "")
+
+	b := s.s3.Bucket("bucket")
+	err := b.Put("name", []byte("content"), "text/plain", s3.Private, s3.Options{Checksum: true, ContentMD5: contentMD5b64})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["Content-Md5"], DeepEquals, []string{contentMD5b64})
+}
+
+func (s *S) TestPutChecksumKMS(c *C) {
+	testServer.Response(200, map[string]string{
+		"ETag":                         `"0123456789abcdef0123456789abcdef"`,
+		"x-amz-server-side-encryption": "aws:kms",
+	}, "")
+
+	// The ETag of objects encrypted with KMS isn't their MD5 digest
+	b := s.s3.Bucket("bucket")
+	options := s3.Options{Checksum: true, ServerSideEncryption: "aws:kms"}
+	err := b.Put("name", []byte("content"), "text/plain", s3.Private, options)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["Content-Md5"], DeepEquals, []string{contentMD5b64})
+}
+
+func (s *S) TestGetVerified(c *C) {
+	testServer.Response(200, map[string]string{"ETag": `"` + contentMD5 + `"`}, "content")
+
+	b := s.s3.Bucket("bucket")
+	data, err := b.GetVerified("name")
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "content")
+	testServer.WaitRequest()
+}
+
+func (s *S) TestGetVerifiedMismatch(c *C) {
+	testServer.Response(200, map[string]string{"ETag": `"0123456789abcdef0123456789abcdef"`}, "content")
+
+	b := s.s3.Bucket("bucket")
+	rc, err := b.GetReaderVerified("name")
+	c.Assert(err, IsNil)
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	c.Assert(string(data), Equals, "content")
+	c.Assert(err, DeepEquals, &s3.IntegrityError{Path: "name", Expected: "0123456789abcdef0123456789abcdef", Actual: contentMD5})
+	testServer.WaitRequest()
+}
+
+func (s *S) TestGetVerifiedMultipart(c *C) {
+	testServer.Response(200, map[string]string{"ETag": `"0123456789abcdef0123456789abcdef-2"`}, "content")
+
+	// The ETag of objects uploaded in parts isn't their MD5 digest
+	b := s.s3.Bucket("bucket")
+	data, err := b.GetVerified("name")
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "content")
+	testServer.WaitRequest()
+}
@@ -0,0 +1,133 @@
+package s3
+
+import (
+	"encoding/xml"
+)
+
+// Status of lifecycle rules.
+const (
+	LifecycleEnabled  = "Enabled"
+	LifecycleDisabled = "Disabled"
+)
+
+// LifecycleConfiguration holds the rules managing the lifetime of the
+// objects of a bucket.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/dev/object-lifecycle-mgmt.html for details.
+type LifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration"`
+	Rules   []LifecycleRule `xml:"Rule"`
+}
+
+// LifecycleRule describes the actions applied to the objects it selects,
+// with either Filter or the legacy Prefix. A rule with neither applies to
+// all the objects of the bucket.
+type LifecycleRule struct {
+	ID     string           `xml:"ID,omitempty"`
+	Prefix string           `xml:"Prefix,omitempty"`
+	Filter *LifecycleFilter `xml:"Filter,omitempty"`
+	Status string           `xml:"Status"`
+
+	Expiration                     *Expiration                     `xml:"Expiration,omitempty"`
+	Transitions                    []Transition                    `xml:"Transition,omitempty"`
+	NoncurrentVersionExpiration    *NoncurrentVersionExpiration    `xml:"NoncurrentVersionExpiration,omitempty"`
+	NoncurrentVersionTransitions   []NoncurrentVersionTransition   `xml:"NoncurrentVersionTransition,omitempty"`
+	AbortIncompleteMultipartUpload *AbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty"`
+}
+
+// LifecycleFilter selects the objects a lifecycle rule applies to by key
+// prefix or tag, or by both with And.
+type LifecycleFilter struct {
+	Prefix string        `xml:"Prefix,omitempty"`
+	Tag    *Tag          `xml:"Tag,omitempty"`
+	And    *LifecycleAnd `xml:"And,omitempty"`
+}
+
+// LifecycleAnd selects the objects matching the prefix and all the tags.
+type LifecycleAnd struct {
+	Prefix string `xml:"Prefix,omitempty"`
+	Tags   []Tag  `xml:"Tag"`
+}
+
+// Tag is a tag of an object.
+type Tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+// Expiration deletes objects a number of Days after their creation, or at
+// Date, given in the ISO 8601 format such as "2016-01-01T00:00:00Z".
+// ExpiredObjectDeleteMarker removes delete markers without noncurrent
+// versions in versioned buckets.
+type Expiration struct {
+	Days                      int    `xml:"Days,omitempty"`
+	Date                      string `xml:"Date,omitempty"`
+	ExpiredObjectDeleteMarker bool   `xml:"ExpiredObjectDeleteMarker,omitempty"`
+}
+
+// Transition moves objects to StorageClass, such as GLACIER or
+// STANDARD_IA, a number of Days after their creation or at Date.
+type Transition struct {
+	Days         int    `xml:"Days,omitempty"`
+	Date         string `xml:"Date,omitempty"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+// NoncurrentVersionExpiration deletes the versions of objects a number of
+// days after they became noncurrent.
+type NoncurrentVersionExpiration struct {
+	NoncurrentDays int `xml:"NoncurrentDays"`
+}
+
+// NoncurrentVersionTransition moves the versions of objects to
+// StorageClass a number of days after they became noncurrent.
+type NoncurrentVersionTransition struct {
+	NoncurrentDays int    `xml:"NoncurrentDays"`
+	StorageClass   string `xml:"StorageClass"`
+}
+
+// AbortIncompleteMultipartUpload aborts the multipart uploads which are
+// still not complete a number of days after they were initiated.
+type AbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation"`
+}
+
+// PutLifecycle sets the lifecycle configuration of b, replacing any
+// previous one.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketPUTlifecycle.html for details.
+func (b *Bucket) PutLifecycle(config *LifecycleConfiguration) error {
+	c := *config
+	c.Rules = make([]LifecycleRule, len(config.Rules))
+	for i, rule := range config.Rules {
+		if rule.Prefix == "" && rule.Filter == nil {
+			// An empty filter selects all the objects
+			rule.Filter = &LifecycleFilter{}
+		}
+		c.Rules[i] = rule
+	}
+	return b.putSubresourceXML("/", "lifecycle", &c)
+}
+
+// GetLifecycle returns the lifecycle configuration of b. It has no rules
+// if b has no lifecycle configuration.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketGETlifecycle.html for details.
+func (b *Bucket) GetLifecycle() (*LifecycleConfiguration, error) {
+	config := &LifecycleConfiguration{}
+	err := b.getSubresource("/", "lifecycle", config)
+	if hasCode(err, "NoSuchLifecycleConfiguration") {
+		return &LifecycleConfiguration{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// DelLifecycle removes the lifecycle configuration of b.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketDELETElifecycle.html for details.
+func (b *Bucket) DelLifecycle() error {
+	return b.delSubresource("/", "lifecycle")
+}
@@ -0,0 +1,129 @@
+package s3_test
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"io/ioutil"
+
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+// lifecycleExample holds the rules of GetLifecycleResultDump.
+var lifecycleExample = s3.LifecycleConfiguration{
+	Rules: []s3.LifecycleRule{{
+		ID:     "logs",
+		Filter: &s3.LifecycleFilter{Prefix: "logs/"},
+		Status: s3.LifecycleEnabled,
+		Transitions: []s3.Transition{
+			{Days: 30, StorageClass: "STANDARD_IA"},
+			{Days: 90, StorageClass: "GLACIER"},
+		},
+		Expiration: &s3.Expiration{Days: 365},
+	}, {
+		ID:         "tmp",
+		Prefix:     "tmp/",
+		Status:     s3.LifecycleDisabled,
+		Expiration: &s3.Expiration{Date: "2016-01-01T00:00:00.000Z"},
+	}, {
+		ID: "versions",
+		Filter: &s3.LifecycleFilter{And: &s3.LifecycleAnd{
+			Prefix: "data/",
+			Tags:   []s3.Tag{{Key: "archive", Value: "true"}},
+		}},
+		Status: s3.LifecycleEnabled,
+		NoncurrentVersionTransitions: []s3.NoncurrentVersionTransition{
+			{NoncurrentDays: 10, StorageClass: "GLACIER"},
+		},
+		NoncurrentVersionExpiration:    &s3.NoncurrentVersionExpiration{NoncurrentDays: 30},
+		AbortIncompleteMultipartUpload: &s3.AbortIncompleteMultipartUpload{DaysAfterInitiation: 7},
+	}},
+}
+
+func (s *S) TestPutLifecycle(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.PutLifecycle(&lifecycleExample)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "lifecycle=")
+
+	body, err := ioutil.ReadAll(req.Body)
+	c.Assert(err, IsNil)
+	sum := md5.Sum(body)
+	c.Assert(req.Header["Content-Md5"], DeepEquals, []string{base64.StdEncoding.EncodeToString(sum[:])})
+
+	// The body holds the same rules as the fixture
+	var sent, expected s3.LifecycleConfiguration
+	c.Assert(xml.Unmarshal(body, &sent), IsNil)
+	c.Assert(xml.Unmarshal([]byte(GetLifecycleResultDump), &expected), IsNil)
+	c.Assert(sent.Rules, DeepEquals, expected.Rules)
+}
+
+func (s *S) TestPutLifecycleAllObjects(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	config := &s3.LifecycleConfiguration{Rules: []s3.LifecycleRule{{
+		Status:                         s3.LifecycleEnabled,
+		AbortIncompleteMultipartUpload: &s3.AbortIncompleteMultipartUpload{DaysAfterInitiation: 1},
+	}}}
+	err := b.PutLifecycle(config)
+	c.Assert(err, IsNil)
+	c.Assert(config.Rules[0].Filter, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(readAll(req.Body), Equals, xml.Header+"<LifecycleConfiguration><Rule><Filter></Filter><Status>Enabled</Status>"+
+		"<AbortIncompleteMultipartUpload><DaysAfterInitiation>1</DaysAfterInitiation></AbortIncompleteMultipartUpload></Rule></LifecycleConfiguration>")
+}
+
+func (s *S) TestGetLifecycle(c *C) {
+	testServer.Response(200, nil, GetLifecycleResultDump)
+
+	b := s.s3.Bucket("sample")
+	config, err := b.GetLifecycle()
+	c.Assert(err, IsNil)
+	c.Assert(config.Rules, DeepEquals, lifecycleExample.Rules)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "lifecycle=")
+}
+
+func (s *S) TestGetLifecycleNotConfigured(c *C) {
+	testServer.Response(404, nil, NoSuchLifecycleConfigurationErrorDump)
+
+	b := s.s3.Bucket("sample")
+	config, err := b.GetLifecycle()
+	c.Assert(err, IsNil)
+	c.Assert(config.Rules, HasLen, 0)
+}
+
+func (s *S) TestGetLifecycleError(c *C) {
+	s.DisableRetries()
+	testServer.Response(404, nil, GetObjectErrorDump)
+
+	b := s.s3.Bucket("sample")
+	config, err := b.GetLifecycle()
+	c.Assert(config, IsNil)
+	c.Assert(err, ErrorMatches, ".*\\(NoSuchBucket\\)")
+}
+
+func (s *S) TestDelLifecycle(c *C) {
+	testServer.Response(204, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.DelLifecycle()
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "DELETE")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "lifecycle=")
+}
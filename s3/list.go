@@ -0,0 +1,264 @@
+package s3
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// The ListV2Resp type holds the results of a ListV2 bucket operation.
+type ListV2Resp struct {
+	Name       string
+	Prefix     string
+	Delimiter  string
+	StartAfter string
+	MaxKeys    int
+	KeyCount   int
+
+	// ContinuationToken is the token the listing was continued from, and
+	// NextContinuationToken the one continuing it when IsTruncated is
+	// true.
+	ContinuationToken     string
+	NextContinuationToken string
+
+	IsTruncated    bool
+	Contents       []Key
+	CommonPrefixes []string `xml:">Prefix"`
+
+	// EncodingType is "url" if the keys were requested URL encoded. They
+	// are decoded by then.
+	EncodingType string
+}
+
+// ListV2 returns information about objects in an S3 bucket as List does,
+// with the list-objects-v2 API which performs better on large buckets.
+//
+// Listing starts after the given continuation token, which is either
+// empty or the NextContinuationToken of a previous result.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListObjectsV2.html for details.
+func (b *Bucket) ListV2(prefix, delim, token string, max int) (result *ListV2Resp, err error) {
+	return b.listV2(prefix, delim, token, max, "")
+}
+
+// listV2 is ListV2 requesting the keys with the given encoding type, if
+// any.
+func (b *Bucket) listV2(prefix, delim, token string, max int, encoding string) (result *ListV2Resp, err error) {
+	params := map[string][]string{
+		"list-type": {"2"},
+		"prefix":    {prefix},
+		"delimiter": {delim},
+	}
+	if token != "" {
+		params["continuation-token"] = []string{token}
+	}
+	if max != 0 {
+		params["max-keys"] = []string{strconv.FormatInt(int64(max), 10)}
+	}
+	if encoding != "" {
+		params["encoding-type"] = []string{encoding}
+	}
+	req := &request{
+		bucket: b.Name,
+		params: params,
+	}
+	result = &ListV2Resp{}
+	for attempt := b.S3.AttemptStrategy.Start(); attempt.Next(); {
+		err = b.S3.query(req, result)
+		if !shouldRetry(err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = decodeKeys(result.EncodingType, result.Contents, result.CommonPrefixes,
+		&result.Prefix, &result.Delimiter, &result.StartAfter)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// decodeKeys decodes in place the keys, prefixes and other strings of a
+// listing given with the "url" encoding type, which S3 uses to return
+// keys holding characters XML can't represent.
+func decodeKeys(encoding string, keys []Key, prefixes []string, strs ...*string) error {
+	if encoding != "url" {
+		return nil
+	}
+	var err error
+	for i := range keys {
+		if keys[i].Key, err = url.QueryUnescape(keys[i].Key); err != nil {
+			return err
+		}
+	}
+	for i := range prefixes {
+		if prefixes[i], err = url.QueryUnescape(prefixes[i]); err != nil {
+			return err
+		}
+	}
+	for _, s := range strs {
+		if *s, err = url.QueryUnescape(*s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObjectIterator iterates over the keys of a bucket, requesting them page
+// by page as needed. It is created by Bucket.ListAll and used as:
+//
+//	it := b.ListAll("photos/", "")
+//	for it.Next() {
+//		key := it.Key()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ObjectIterator struct {
+	// PageSize is the number of keys and common prefixes requested per
+	// page. Zero requests the maximum of 1000.
+	PageSize int
+
+	// ListV1 makes the iterator use the original List API, for services
+	// compatible with S3 which lack list-objects-v2.
+	ListV1 bool
+
+	// EncodeKeys requests the keys URL encoded, so that keys holding
+	// characters XML can't represent may be listed. They are returned
+	// decoded.
+	EncodeKeys bool
+
+	bucket   *Bucket
+	prefix   string
+	delim    string
+	marker   string
+	done     bool
+	keys     []Key
+	key      Key
+	prefixes []string
+	err      error
+}
+
+// ListAll returns an iterator over the keys of b beginning with prefix,
+// following the pages of the listing. With a delimiter, the keys sharing
+// a common prefix up to it are grouped and returned by CommonPrefixes
+// instead.
+func (b *Bucket) ListAll(prefix, delim string) *ObjectIterator {
+	return &ObjectIterator{bucket: b, prefix: prefix, delim: delim}
+}
+
+// Next advances the iterator to the next key, which is then returned by
+// Key. It returns false when there are no more keys or on error.
+func (it *ObjectIterator) Next() bool {
+	for it.err == nil {
+		if len(it.keys) > 0 {
+			it.key = it.keys[0]
+			it.keys = it.keys[1:]
+			return true
+		}
+		if it.done {
+			return false
+		}
+		it.err = it.fetch()
+	}
+	return false
+}
+
+// Key returns the current key.
+func (it *ObjectIterator) Key() Key {
+	return it.key
+}
+
+// CommonPrefixes returns the common prefixes in the pages requested so
+// far, which are all of them once Next has returned false.
+func (it *ObjectIterator) CommonPrefixes() []string {
+	return it.prefixes
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *ObjectIterator) Err() error {
+	return it.err
+}
+
+// fetch requests the page after the current marker.
+func (it *ObjectIterator) fetch() error {
+	var truncated bool
+	var prefixes []string
+	encoding := ""
+	if it.EncodeKeys {
+		encoding = "url"
+	}
+	if it.ListV1 {
+		list, err := it.bucket.list(it.prefix, it.delim, it.marker, it.PageSize, encoding)
+		if err != nil {
+			return err
+		}
+		it.keys, prefixes, truncated = list.Contents, list.CommonPrefixes, list.IsTruncated
+		// NextMarker is only given with a delimiter
+		it.marker = list.NextMarker
+		if it.marker == "" {
+			if n := len(it.keys); n > 0 {
+				it.marker = it.keys[n-1].Key
+			}
+			if n := len(prefixes); n > 0 && prefixes[n-1] > it.marker {
+				it.marker = prefixes[n-1]
+			}
+		}
+	} else {
+		list, err := it.bucket.listV2(it.prefix, it.delim, it.marker, it.PageSize, encoding)
+		if err != nil {
+			return err
+		}
+		it.keys, prefixes, truncated = list.Contents, list.CommonPrefixes, list.IsTruncated
+		it.marker = list.NextContinuationToken
+	}
+	it.prefixes = append(it.prefixes, prefixes...)
+	it.done = !truncated || it.marker == ""
+	return nil
+}
+
+// ErrStopWalk may be returned by the function given to Walk to stop the
+// walk without error.
+var ErrStopWalk = errors.New("s3: stop walk")
+
+// Walk calls fn with each key of b beginning with prefix, in order,
+// following the pages of the listing. It stops at the first error,
+// which it returns unless it is ErrStopWalk.
+func (b *Bucket) Walk(prefix string, fn func(Key) error) error {
+	it := b.ListAll(prefix, "")
+	for it.Next() {
+		if err := fn(it.Key()); err != nil {
+			if err == ErrStopWalk {
+				return nil
+			}
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// ListDir lists a single level of b below prefix, as a directory, with
+// the delimiter "/": dirs holds the prefixes of the keys below it, ending
+// with "/", and objects the keys directly in it. A "/" is appended to
+// prefix if it isn't empty and lacks one. The object named prefix
+// itself, which tools create to mark directories, isn't part of objects.
+func (b *Bucket) ListDir(prefix string) (dirs []string, objects []Key, err error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	it := b.ListAll(prefix, "/")
+	it.EncodeKeys = true
+	for it.Next() {
+		if key := it.Key(); key.Key != prefix {
+			objects = append(objects, key)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, nil, err
+	}
+	return it.CommonPrefixes(), objects, nil
+}
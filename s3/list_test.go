@@ -0,0 +1,212 @@
+package s3_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+// listPage returns a page of a listing with the given keys, truncated if
+// next is not empty. Pages of list-objects-v2 are continued by next.
+func listPage(v2 bool, keys []string, next string) string {
+	var buf strings.Builder
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Name>sample</Name>`)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "<Contents><Key>%s</Key><Size>1</Size></Contents>", key)
+	}
+	fmt.Fprintf(&buf, "<IsTruncated>%v</IsTruncated>", next != "")
+	if next != "" && v2 {
+		fmt.Fprintf(&buf, "<NextContinuationToken>%s</NextContinuationToken>", next)
+	}
+	buf.WriteString("</ListBucketResult>")
+	return buf.String()
+}
+
+func (s *S) TestListV2(c *C) {
+	testServer.Response(200, nil, listPage(true, []string{"a", "b"}, "token"))
+
+	b := s.s3.Bucket("sample")
+	result, err := b.ListV2("p", "/", "start", 2)
+	c.Assert(err, IsNil)
+	c.Assert(result.IsTruncated, Equals, true)
+	c.Assert(result.NextContinuationToken, Equals, "token")
+	c.Assert(result.Contents, HasLen, 2)
+	c.Assert(result.Contents[1].Key, Equals, "b")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.Form["list-type"], DeepEquals, []string{"2"})
+	c.Assert(req.Form["prefix"], DeepEquals, []string{"p"})
+	c.Assert(req.Form["delimiter"], DeepEquals, []string{"/"})
+	c.Assert(req.Form["continuation-token"], DeepEquals, []string{"start"})
+	c.Assert(req.Form["max-keys"], DeepEquals, []string{"2"})
+}
+
+func (s *S) TestListAll(c *C) {
+	for i := 0; i < 5; i++ {
+		next := ""
+		if i < 4 {
+			next = fmt.Sprintf("token%d", i)
+		}
+		testServer.Response(200, nil, listPage(true, []string{fmt.Sprintf("key%d-a", i), fmt.Sprintf("key%d-b", i)}, next))
+	}
+
+	b := s.s3.Bucket("sample")
+	it := b.ListAll("key", "")
+	it.PageSize = 2
+	var names []string
+	for it.Next() {
+		names = append(names, it.Key().Key)
+	}
+	c.Assert(it.Err(), IsNil)
+	c.Assert(names, HasLen, 10)
+	c.Assert(names[0], Equals, "key0-a")
+	c.Assert(names[9], Equals, "key4-b")
+	c.Assert(it.Next(), Equals, false)
+
+	reqs := testServer.WaitRequests(5)
+	for i, req := range reqs {
+		c.Assert(req.Form["list-type"], DeepEquals, []string{"2"})
+		c.Assert(req.Form["prefix"], DeepEquals, []string{"key"})
+		c.Assert(req.Form["max-keys"], DeepEquals, []string{"2"})
+		if i == 0 {
+			c.Assert(req.Form["continuation-token"], IsNil)
+		} else {
+			c.Assert(req.Form["continuation-token"], DeepEquals, []string{fmt.Sprintf("token%d", i-1)})
+		}
+	}
+}
+
+func (s *S) TestListAllV1(c *C) {
+	// Without a delimiter, pages continue after their last key
+	for i := 0; i < 5; i++ {
+		next := ""
+		if i < 4 {
+			next = "more"
+		}
+		testServer.Response(200, nil, listPage(false, []string{fmt.Sprintf("key%d", i)}, next))
+	}
+
+	b := s.s3.Bucket("sample")
+	it := b.ListAll("", "")
+	it.ListV1 = true
+	var names []string
+	for it.Next() {
+		names = append(names, it.Key().Key)
+	}
+	c.Assert(it.Err(), IsNil)
+	c.Assert(names, DeepEquals, []string{"key0", "key1", "key2", "key3", "key4"})
+
+	reqs := testServer.WaitRequests(5)
+	for i, req := range reqs {
+		c.Assert(req.Form["list-type"], IsNil)
+		if i > 0 {
+			c.Assert(req.Form["marker"], DeepEquals, []string{fmt.Sprintf("key%d", i-1)})
+		}
+	}
+}
+
+func (s *S) TestListAllError(c *C) {
+	s.DisableRetries()
+	testServer.Response(200, nil, listPage(true, []string{"a"}, "token"))
+	testServer.Response(404, nil, GetObjectErrorDump)
+
+	b := s.s3.Bucket("sample")
+	it := b.ListAll("", "")
+	c.Assert(it.Next(), Equals, true)
+	c.Assert(it.Next(), Equals, false)
+	c.Assert(it.Err(), ErrorMatches, `The specified bucket does not exist \(NoSuchBucket\)`)
+	testServer.WaitRequests(2)
+}
+
+func (s *S) TestListAllSlowDown(c *C) {
+	testServer.Response(503, nil, SlowDownErrorDump)
+	testServer.Response(200, nil, listPage(true, []string{"a"}, ""))
+
+	// Throttled pages are retried
+	b := s.s3.Bucket("sample")
+	it := b.ListAll("", "")
+	c.Assert(it.Next(), Equals, true)
+	c.Assert(it.Key().Key, Equals, "a")
+	c.Assert(it.Next(), Equals, false)
+	c.Assert(it.Err(), IsNil)
+	testServer.WaitRequests(2)
+}
+
+func (s *S) TestWalk(c *C) {
+	testServer.Response(200, nil, listPage(true, []string{"a", "b"}, "token"))
+	testServer.Response(200, nil, listPage(true, []string{"c", "d"}, "token"))
+
+	b := s.s3.Bucket("sample")
+	var names []string
+	err := b.Walk("", func(key s3.Key) error {
+		names = append(names, key.Key)
+		if key.Key == "c" {
+			return s3.ErrStopWalk
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(names, DeepEquals, []string{"a", "b", "c"})
+	testServer.WaitRequests(2)
+
+	// Other errors are returned
+	testServer.Response(200, nil, listPage(true, []string{"a", "b"}, ""))
+	failure := errors.New("failure")
+	err = b.Walk("", func(key s3.Key) error {
+		return failure
+	})
+	c.Assert(err, Equals, failure)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestListEncodingType(c *C) {
+	testServer.Response(200, nil, `<?xml version="1.0" encoding="UTF-8"?><ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`+
+		`<Name>sample</Name><Prefix>a+b%2F</Prefix><Delimiter>%2F</Delimiter><EncodingType>url</EncodingType>`+
+		`<Contents><Key>a+b%2Fc%01d</Key><Size>1</Size><Owner><ID>id</ID><DisplayName>name</DisplayName></Owner></Contents>`+
+		`<IsTruncated>false</IsTruncated><CommonPrefixes><Prefix>a+b%2Fe%2F</Prefix></CommonPrefixes></ListBucketResult>`)
+
+	b := s.s3.Bucket("sample")
+	it := b.ListAll("a b/", "/")
+	it.ListV1 = true
+	it.EncodeKeys = true
+	c.Assert(it.Next(), Equals, true)
+	c.Assert(it.Key().Key, Equals, "a b/c\x01d")
+	c.Assert(it.Key().Owner, Equals, s3.Owner{ID: "id", DisplayName: "name"})
+	c.Assert(it.Next(), Equals, false)
+	c.Assert(it.Err(), IsNil)
+	c.Assert(it.CommonPrefixes(), DeepEquals, []string{"a b/e/"})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["encoding-type"], DeepEquals, []string{"url"})
+}
+
+func (s *S) TestListDir(c *C) {
+	testServer.Response(200, nil, `<?xml version="1.0" encoding="UTF-8"?><ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`+
+		`<Name>sample</Name><Prefix>photos%2F</Prefix><EncodingType>url</EncodingType>`+
+		`<Contents><Key>photos%2F</Key><Size>0</Size></Contents>`+
+		`<Contents><Key>photos%2Findex.html</Key><Size>1</Size></Contents>`+
+		`<IsTruncated>true</IsTruncated><NextContinuationToken>token</NextContinuationToken>`+
+		`<CommonPrefixes><Prefix>photos%2F2006%2F</Prefix></CommonPrefixes></ListBucketResult>`)
+	testServer.Response(200, nil, `<?xml version="1.0" encoding="UTF-8"?><ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`+
+		`<Name>sample</Name><Prefix>photos%2F</Prefix><EncodingType>url</EncodingType>`+
+		`<IsTruncated>false</IsTruncated>`+
+		`<CommonPrefixes><Prefix>photos%2F2007%2F</Prefix></CommonPrefixes></ListBucketResult>`)
+
+	b := s.s3.Bucket("sample")
+	dirs, objects, err := b.ListDir("photos")
+	c.Assert(err, IsNil)
+	c.Assert(dirs, DeepEquals, []string{"photos/2006/", "photos/2007/"})
+	c.Assert(objects, HasLen, 1)
+	c.Assert(objects[0].Key, Equals, "photos/index.html")
+
+	reqs := testServer.WaitRequests(2)
+	c.Assert(reqs[0].Form["prefix"], DeepEquals, []string{"photos/"})
+	c.Assert(reqs[0].Form["delimiter"], DeepEquals, []string{"/"})
+	c.Assert(reqs[0].Form["encoding-type"], DeepEquals, []string{"url"})
+	c.Assert(reqs[1].Form["continuation-token"], DeepEquals, []string{"token"})
+}
@@ -0,0 +1,111 @@
+package s3_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) TestLocation(c *C) {
+	testServer.Response(200, nil, GetLocationResultDump)
+
+	b := s.s3.Bucket("bucket")
+	location, err := b.Location()
+	c.Assert(err, IsNil)
+	c.Assert(location, Equals, "eu-central-1")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.URL.Path, Equals, "/bucket/")
+	c.Assert(req.Form["location"], DeepEquals, []string{""})
+}
+
+func (s *S) TestLocationUSEast(c *C) {
+	testServer.Response(200, nil, `<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/"/>`)
+
+	b := s.s3.Bucket("bucket")
+	location, err := b.Location()
+	c.Assert(err, IsNil)
+	c.Assert(location, Equals, "us-east-1")
+	testServer.WaitRequest()
+}
+
+// regionServer starts a server for the S3 endpoint of the region named
+// faux-region-2, which it registers in aws.Regions until stop is called.
+// It records the requests it gets and answers them with body.
+func regionServer(signatureV4 bool, body string) (reqs *[]*http.Request, stop func()) {
+	reqs = new([]*http.Request)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, _ := ioutil.ReadAll(req.Body)
+		req.Body = ioutil.NopCloser(bytes.NewReader(data))
+		*reqs = append(*reqs, req)
+		w.Write([]byte(body))
+	}))
+	aws.Regions["faux-region-2"] = aws.Region{Name: "faux-region-2", S3Endpoint: ts.URL, S3SignatureV4: signatureV4}
+	return reqs, func() {
+		delete(aws.Regions, "faux-region-2")
+		ts.Close()
+	}
+}
+
+func (s *S) TestRegionRedirect(c *C) {
+	reqs, stop := regionServer(false, "content")
+	defer stop()
+
+	client := s3.New(aws.Auth{AccessKey: "abc", SecretKey: "123"}, aws.Region{Name: "faux-region-1", S3Endpoint: testServer.URL})
+	client.AttemptStrategy = aws.AttemptStrategy{}
+	b := client.Bucket("bucket")
+
+	testServer.Response(301, map[string]string{"x-amz-bucket-region": "faux-region-2"}, PermanentRedirectErrorDump)
+	err := b.Put("name", []byte("content"), "text/plain", s3.Private, s3.Options{})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(*reqs, HasLen, 1)
+	req = (*reqs)[0]
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.Path, Equals, "/bucket/name")
+	c.Assert(req.ContentLength, Equals, int64(7))
+	c.Assert(readAll(req.Body), Equals, "content")
+
+	// The region of the bucket is remembered.
+	data, err := b.Get("name")
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "content")
+	c.Assert(*reqs, HasLen, 2)
+	c.Assert((*reqs)[1].URL.Path, Equals, "/bucket/name")
+}
+
+func (s *S) TestRegionRedirectSignatureV4(c *C) {
+	reqs, stop := regionServer(true, GetListResultDump1)
+	defer stop()
+
+	client := s3.New(aws.Auth{AccessKey: "abc", SecretKey: "123"}, aws.Region{Name: "faux-region-1", S3Endpoint: testServer.URL, S3SignatureV4: true})
+	client.AttemptStrategy = aws.AttemptStrategy{}
+
+	testServer.Response(400, map[string]string{"x-amz-bucket-region": "faux-region-2"}, AuthorizationHeaderMalformedErrorDump)
+	_, err := client.Bucket("bucket").List("", "", "", 0)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header.Get("Authorization"), Matches, "AWS4-HMAC-SHA256 Credential=abc/[0-9]{8}/faux-region-1/s3/aws4_request, .*")
+	c.Assert(*reqs, HasLen, 1)
+	req = (*reqs)[0]
+	c.Assert(req.URL.Path, Equals, "/bucket/")
+	c.Assert(req.Header.Get("Authorization"), Matches, "AWS4-HMAC-SHA256 Credential=abc/[0-9]{8}/faux-region-2/s3/aws4_request, .*")
+}
+
+func (s *S) TestRegionRedirectError(c *C) {
+	s.DisableRetries()
+	testServer.Response(301, nil, PermanentRedirectErrorDump)
+
+	_, err := s.s3.Bucket("bucket").Get("name")
+	c.Assert(err, ErrorMatches, `The bucket you are attempting to access must be addressed using the specified endpoint.* \(PermanentRedirect\)`)
+	testServer.WaitRequest()
+}
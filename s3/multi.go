@@ -1,15 +1,19 @@
 package s3
 
 import (
+	"bufio"
 	"bytes"
 	"crypto/md5"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
+	"net/url"
 	"sort"
 	"strconv"
+	"sync"
 )
 
 // Multi represents an unfinished multipart upload.
@@ -23,6 +27,14 @@ type Multi struct {
 	Bucket   *Bucket
 	Key      string
 	UploadId string
+
+	// Workers is the number of parts PutAll sends concurrently. Zero
+	// means one, sending the parts in order.
+	Workers int
+
+	// sseCustomer holds the headers giving the key provided by the caller
+	// to InitMultiWithOptions, which are sent with each part.
+	sseCustomer map[string][]string
 }
 
 // That's the default. Here just for testing.
@@ -106,11 +118,23 @@ func (b *Bucket) Multi(key, contType string, perm ACL) (*Multi, error) {
 //
 // See http://goo.gl/XP8kL for details.
 func (b *Bucket) InitMulti(key string, contType string, perm ACL) (*Multi, error) {
+	return b.InitMultiWithOptions(key, contType, perm, Options{})
+}
+
+// InitMultiWithOptions initializes a new multipart upload as InitMulti,
+// with the metadata, tags and encryption of the object given in options.
+// When the object is encrypted with a key provided by the caller, parts
+// are sent with the key.
+func (b *Bucket) InitMultiWithOptions(key string, contType string, perm ACL, options Options) (*Multi, error) {
+	if err := validateTags(options.Tags); err != nil {
+		return nil, err
+	}
 	headers := map[string][]string{
 		"Content-Type":   {contType},
 		"Content-Length": {"0"},
 		"x-amz-acl":      {string(perm)},
 	}
+	options.addHeaders(headers)
 	params := map[string][]string{
 		"uploads": {""},
 	}
@@ -134,7 +158,13 @@ func (b *Bucket) InitMulti(key string, contType string, perm ACL) (*Multi, error
 	if err != nil {
 		return nil, err
 	}
-	return &Multi{Bucket: b, Key: key, UploadId: resp.UploadId}, nil
+	return &Multi{Bucket: b, Key: key, UploadId: resp.UploadId, sseCustomer: options.SSECustomerHeaders()}, nil
+}
+
+// InitMultipart initializes a new multipart upload at the provided key
+// inside b. It is the same as InitMulti.
+func (b *Bucket) InitMultipart(key string, contType string, perm ACL) (*Multi, error) {
+	return b.InitMulti(key, contType, perm)
 }
 
 // PutPart sends part n of the multipart upload, reading all the content from r.
@@ -154,6 +184,9 @@ func (m *Multi) putPart(n int, r io.ReadSeeker, partSize int64, md5b64 string) (
 		"Content-Length": {strconv.FormatInt(partSize, 10)},
 		"Content-MD5":    {md5b64},
 	}
+	for k, v := range m.sseCustomer {
+		headers[k] = v
+	}
 	params := map[string][]string{
 		"uploadId":   {m.UploadId},
 		"partNumber": {strconv.FormatInt(int64(n), 10)},
@@ -169,7 +202,10 @@ func (m *Multi) putPart(n int, r io.ReadSeeker, partSize int64, md5b64 string) (
 			path:    m.Key,
 			headers: headers,
 			params:  params,
-			payload: r,
+		}
+		// Without a body an empty part is sent with its Content-Length
+		if partSize > 0 {
+			req.payload = r
 		}
 		err = m.Bucket.S3.prepare(req)
 		if err != nil {
@@ -191,6 +227,67 @@ func (m *Multi) putPart(n int, r io.ReadSeeker, partSize int64, md5b64 string) (
 	panic("unreachable")
 }
 
+type copyPartResult struct {
+	ETag         string
+	LastModified string
+}
+
+// PutPartCopy sends part n of the multipart upload by copying the bytes
+// from start to end, inclusive, of the source object, given as
+// "bucket/key". Of options, only the version, the conditions and the key
+// of the source are used.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/API_UploadPartCopy.html for details.
+func (m *Multi) PutPartCopy(n int, options CopyOptions, source string, start, end int64) (Part, error) {
+	if options.CopySourceVersionId != "" {
+		source += "?versionId=" + url.QueryEscape(options.CopySourceVersionId)
+	}
+	headers := map[string][]string{
+		"x-amz-copy-source":       {source},
+		"x-amz-copy-source-range": {fmt.Sprintf("bytes=%d-%d", start, end)},
+	}
+	for k, v := range m.sseCustomer {
+		headers[k] = v
+	}
+	for k, v := range sseCustomerHeaders("x-amz-copy-source-server-side-encryption-customer-", options.CopySourceSSECustomerAlgorithm, options.CopySourceSSECustomerKey, options.CopySourceSSECustomerKeyMD5) {
+		headers[k] = v
+	}
+	options.addConditionHeaders(headers)
+	params := map[string][]string{
+		"uploadId":   {m.UploadId},
+		"partNumber": {strconv.FormatInt(int64(n), 10)},
+	}
+	for attempt := m.Bucket.S3.AttemptStrategy.Start(); attempt.Next(); {
+		req := &request{
+			method:  "PUT",
+			bucket:  m.Bucket.Name,
+			path:    m.Key,
+			headers: headers,
+			params:  params,
+		}
+		err := m.Bucket.S3.prepare(req)
+		if err != nil {
+			return Part{}, err
+		}
+		var result copyPartResult
+		resp, err := m.Bucket.S3.run(req, nil)
+		if err == nil {
+			err = readResult(resp, &result)
+		}
+		if shouldRetry(err) && attempt.HasNext() {
+			continue
+		}
+		if err != nil {
+			return Part{}, err
+		}
+		if result.ETag == "" {
+			return Part{}, errors.New("part copy succeeded with no ETag")
+		}
+		return Part{n, result.ETag, end - start + 1}, nil
+	}
+	panic("unreachable")
+}
+
 func seekerInfo(r io.ReadSeeker) (size int64, md5hex string, md5b64 string, err error) {
 	_, err = r.Seek(0, 0)
 	if err != nil {
@@ -273,53 +370,248 @@ type ReaderAtSeeker interface {
 // than partSize bytes, which must be set to at least 5MB.
 // Parts previously uploaded are either reused if their checksum
 // and size match the new part, or otherwise overwritten with the
-// new content.
-// PutAll returns all the parts of m (reused or not).
-func (m *Multi) PutAll(r ReaderAtSeeker, partSize int64) ([]Part, error) {
+// new content. r is read until its end, and m.Workers parts are sent
+// concurrently.
+// PutAll returns all the parts of m (reused or not), ordered by part
+// number.
+func (m *Multi) PutAll(r io.ReaderAt, partSize int64) ([]Part, error) {
+	if partSize <= 0 {
+		return nil, errors.New("multipart upload part size must be positive")
+	}
 	old, err := m.ListParts()
 	if err != nil && !hasCode(err, "NoSuchUpload") {
 		return nil, err
 	}
-	reuse := 0   // Index of next old part to consider reusing.
-	current := 1 // Part number of latest good part handled.
-	totalSize, err := r.Seek(0, 2)
-	if err != nil {
-		return nil, err
+	oldParts := make(map[int]Part)
+	for _, part := range old {
+		oldParts[part.N] = part
 	}
-	first := true // Must send at least one empty part if the file is empty.
-	var result []Part
-NextSection:
-	for offset := int64(0); offset < totalSize || first; offset += partSize {
-		first = false
-		if offset+partSize > totalSize {
-			partSize = totalSize - offset
+
+	workers := m.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	var (
+		mu      sync.Mutex
+		result  partSlice
+		failure error
+		wg      sync.WaitGroup
+	)
+	failed := make(chan struct{})
+	fail := func(err error) {
+		mu.Lock()
+		if failure == nil {
+			failure = err
+			close(failed)
 		}
-		section := io.NewSectionReader(r, offset, partSize)
-		_, md5hex, md5b64, err := seekerInfo(section)
+		mu.Unlock()
+	}
+	jobs := make(chan partJob)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				select {
+				case <-failed:
+					// Drain the remaining jobs without sending them.
+					continue
+				default:
+				}
+				part, err := m.putPart(job.n, job.section, job.section.Size(), job.md5b64)
+				if err != nil {
+					fail(err)
+					continue
+				}
+				mu.Lock()
+				result = append(result, part)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// Must send at least one empty part if the file is empty.
+NextSection:
+	for n, offset := 1, int64(0); ; n, offset = n+1, offset+partSize {
+		size, md5hex, md5b64, err := seekerInfo(io.NewSectionReader(r, offset, partSize))
 		if err != nil {
-			return nil, err
+			fail(err)
+			break
 		}
-		for reuse < len(old) && old[reuse].N <= current {
-			// Looks like this part was already sent.
-			part := &old[reuse]
-			etag := `"` + md5hex + `"`
-			if part.N == current && part.Size == partSize && part.ETag == etag {
-				// Checksum matches. Reuse the old part.
-				result = append(result, *part)
-				current++
-				continue NextSection
+		if size == 0 && n > 1 {
+			break
+		}
+		if part, ok := oldParts[n]; ok && part.Size == size && part.ETag == `"`+md5hex+`"` {
+			// Checksum matches. Reuse the old part.
+			mu.Lock()
+			result = append(result, part)
+			mu.Unlock()
+		} else {
+			// Part wasn't found or doesn't match. Send it.
+			select {
+			case jobs <- partJob{n, io.NewSectionReader(r, offset, size), md5b64}:
+			case <-failed:
+				break NextSection
 			}
-			reuse++
 		}
+		if size < partSize {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
 
-		// Part wasn't found or doesn't match. Send it.
-		part, err := m.putPart(current, section, partSize, md5b64)
-		if err != nil {
-			return nil, err
+	if failure != nil {
+		return nil, failure
+	}
+	sort.Sort(result)
+	return result, nil
+}
+
+// partJob is a part PutAll has to send.
+type partJob struct {
+	n       int
+	section *io.SectionReader
+	md5b64  string
+}
+
+// PutStream inserts into the S3 bucket an object read from r until EOF,
+// when its length isn't known ahead. r is read partSize bytes at a time,
+// which are sent as the parts of a multipart upload, or as the whole
+// object with a single request if r has no more than partSize bytes.
+//
+// Up to b.UploadConcurrency parts are sent at a time, and as many parts
+// are held in memory. The multipart upload is aborted on error.
+func (b *Bucket) PutStream(path string, r io.Reader, contType string, perm ACL, partSize int64) error {
+	if partSize <= 0 {
+		return errors.New("multipart upload part size must be positive")
+	}
+	br := bufio.NewReader(r)
+	data := make([]byte, partSize)
+	n, err := io.ReadFull(br, data)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return b.Put(path, data[:n], contType, perm, Options{})
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := br.Peek(1); err == io.EOF {
+		return b.Put(path, data, contType, perm, Options{})
+	} else if err != nil {
+		return err
+	}
+
+	m, err := b.InitMulti(path, contType, perm)
+	if err != nil {
+		return err
+	}
+	m.Workers = b.UploadConcurrency
+	parts, err := m.putStream(br, data)
+	if err == nil {
+		err = m.Complete(parts)
+	}
+	if err != nil {
+		m.Abort()
+		return err
+	}
+	return nil
+}
+
+// putStream sends the parts read from r, starting with the first one
+// already read in data, which has the size of all the parts but the last.
+func (m *Multi) putStream(r io.Reader, data []byte) ([]Part, error) {
+	workers := m.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	var (
+		mu      sync.Mutex
+		result  partSlice
+		failure error
+		wg      sync.WaitGroup
+	)
+	failed := make(chan struct{})
+	fail := func(err error) {
+		mu.Lock()
+		if failure == nil {
+			failure = err
+			close(failed)
 		}
-		result = append(result, part)
-		current++
+		mu.Unlock()
 	}
+	type streamJob struct {
+		n    int
+		data []byte
+	}
+	jobs := make(chan streamJob)
+	// Buffers are given back by the workers once their part is sent
+	free := make(chan []byte, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				select {
+				case <-failed:
+				default:
+					sum := md5.Sum(job.data)
+					part, err := m.putPart(job.n, bytes.NewReader(job.data), int64(len(job.data)), base64.StdEncoding.EncodeToString(sum[:]))
+					if err != nil {
+						fail(err)
+						break
+					}
+					mu.Lock()
+					result = append(result, part)
+					mu.Unlock()
+				}
+				free <- job.data[:cap(job.data)]
+			}
+		}()
+	}
+
+	partSize := len(data)
+	buffers := 1
+NextPart:
+	for n := 1; ; n++ {
+		if n > maxParts {
+			fail(fmt.Errorf("s3: stream has more than %d parts of %d bytes", maxParts, partSize))
+			break
+		}
+		select {
+		case jobs <- streamJob{n, data}:
+		case <-failed:
+			break NextPart
+		}
+		if len(data) < partSize {
+			break
+		}
+		if buffers < workers {
+			data = make([]byte, partSize)
+			buffers++
+		} else {
+			select {
+			case data = <-free:
+			case <-failed:
+				break NextPart
+			}
+		}
+		size, err := io.ReadFull(r, data)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			fail(err)
+			break
+		}
+		data = data[:size]
+	}
+	close(jobs)
+	wg.Wait()
+
+	if failure != nil {
+		return nil, failure
+	}
+	sort.Sort(result)
 	return result, nil
 }
 
@@ -344,6 +636,18 @@ func (p completeParts) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
 //
 // See http://goo.gl/2Z7Tw for details.
 func (m *Multi) Complete(parts []Part) error {
+	_, err := m.complete(parts)
+	return err
+}
+
+type completeUploadResult struct {
+	Location string
+	Bucket   string
+	Key      string
+	ETag     string
+}
+
+func (m *Multi) complete(parts []Part) (*completeUploadResult, error) {
 	params := map[string][]string{
 		"uploadId": {m.UploadId},
 	}
@@ -354,7 +658,7 @@ func (m *Multi) Complete(parts []Part) error {
 	sort.Sort(c.Parts)
 	data, err := xml.Marshal(&c)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for attempt := m.Bucket.S3.AttemptStrategy.Start(); attempt.Next(); {
 		req := &request{
@@ -364,11 +668,24 @@ func (m *Multi) Complete(parts []Part) error {
 			params:  params,
 			payload: bytes.NewReader(data),
 		}
-		err := m.Bucket.S3.query(req, nil)
+		err := m.Bucket.S3.prepare(req)
+		if err != nil {
+			return nil, err
+		}
+		var result completeUploadResult
+		resp, err := m.Bucket.S3.run(req, nil)
+		if err == nil {
+			// Completing holds the connection while the object is
+			// assembled, and may report a late error.
+			err = readResult(resp, &result)
+		}
 		if shouldRetry(err) && attempt.HasNext() {
 			continue
 		}
-		return err
+		if err != nil {
+			return nil, err
+		}
+		return &result, nil
 	}
 	panic("unreachable")
 }
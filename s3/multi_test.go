@@ -2,9 +2,11 @@ package s3_test
 
 import (
 	"encoding/xml"
+	"errors"
 	"io"
 	"io/ioutil"
 	"strings"
+	"testing/iotest"
 
 	"github.com/goamz/goamz/s3"
 	. "gopkg.in/check.v1"
@@ -28,6 +30,26 @@ func (s *S) TestInitMulti(c *C) {
 	c.Assert(multi.UploadId, Matches, "JNbR_[A-Za-z0-9.]+QQ--")
 }
 
+func (s *S) TestInitMultiSSECustomer(c *C) {
+	testServer.Response(200, nil, InitMultiResultDump)
+	testServer.Response(200, map[string]string{"ETag": `"etag1"`}, "")
+
+	b := s.s3.Bucket("sample")
+
+	options := s3.Options{SSECustomerKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="}
+	multi, err := b.InitMultiWithOptions("multi", "text/plain", s3.Private, options)
+	c.Assert(err, IsNil)
+	_, err = multi.PutPart(1, strings.NewReader("<part 1>"))
+	c.Assert(err, IsNil)
+
+	// The key is sent to initiate the upload and with each part.
+	for _, req := range testServer.WaitRequests(2) {
+		c.Assert(req.Header["X-Amz-Server-Side-Encryption-Customer-Algorithm"], DeepEquals, []string{"AES256"})
+		c.Assert(req.Header["X-Amz-Server-Side-Encryption-Customer-Key"], DeepEquals, []string{options.SSECustomerKey})
+		c.Assert(req.Header["X-Amz-Server-Side-Encryption-Customer-Key-Md5"], DeepEquals, []string{"hRasmdxgYDKV3nvbahU1MA=="})
+	}
+}
+
 func (s *S) TestMultiNoPreviousUpload(c *C) {
 	// Don't retry the NoSuchUpload error.
 	s.DisableRetries()
@@ -241,6 +263,148 @@ func (s *S) TestPutAllZeroSizeFile(c *C) {
 	c.Assert(readAll(req.Body), Equals, "")
 }
 
+func (s *S) TestPutAllConcurrent(c *C) {
+	// Don't retry the NoSuchUpload error.
+	s.DisableRetries()
+
+	testServer.Response(200, nil, InitMultiResultDump)
+	testServer.Response(404, nil, NoSuchUploadErrorDump)
+	testServer.Responses(4, 200, map[string]string{"ETag": `"etag"`}, "")
+
+	b := s.s3.Bucket("sample")
+
+	multi, err := b.InitMultipart("multi", "text/plain", s3.Private)
+	c.Assert(err, IsNil)
+	multi.Workers = 3
+
+	parts, err := multi.PutAll(strings.NewReader("part1part2part3last"), 5)
+	c.Assert(err, IsNil)
+	c.Assert(parts, HasLen, 4)
+	for i, part := range parts {
+		c.Assert(part.N, Equals, i+1)
+		c.Assert(part.ETag, Equals, `"etag"`)
+	}
+	c.Assert(parts[3].Size, Equals, int64(4))
+
+	// Init and list old parts.
+	testServer.WaitRequests(2)
+
+	// Parts are sent in any order.
+	bodies := make(map[string]string)
+	for _, req := range testServer.WaitRequests(4) {
+		c.Assert(req.Method, Equals, "PUT")
+		bodies[req.Form.Get("partNumber")] = readAll(req.Body)
+	}
+	c.Assert(bodies, DeepEquals, map[string]string{"1": "part1", "2": "part2", "3": "part3", "4": "last"})
+}
+
+func (s *S) TestPutStreamOnePart(c *C) {
+	testServer.Response(200, nil, "")
+
+	// A stream filling one part exactly is sent with a single request
+	b := s.s3.Bucket("sample")
+	err := b.PutStream("stream", strings.NewReader("part1"), "text/plain", s3.Private, 5)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.Path, Equals, "/sample/stream")
+	c.Assert(req.URL.RawQuery, Equals, "")
+	c.Assert(req.Header["Content-Type"], DeepEquals, []string{"text/plain"})
+	c.Assert(req.Header["Content-Length"], DeepEquals, []string{"5"})
+	c.Assert(readAll(req.Body), Equals, "part1")
+}
+
+func (s *S) TestPutStreamOneByteOver(c *C) {
+	testServer.Response(200, nil, InitMultiResultDump)
+	testServer.Responses(2, 200, map[string]string{"ETag": `"etag"`}, "")
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.PutStream("stream", strings.NewReader("part1!"), "text/plain", s3.Private, 5)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "POST")
+	c.Assert(req.URL.RawQuery, Equals, "uploads=")
+	c.Assert(req.Header["Content-Type"], DeepEquals, []string{"text/plain"})
+	for i, body := range []string{"part1", "!"} {
+		req = testServer.WaitRequest()
+		c.Assert(req.Method, Equals, "PUT")
+		c.Assert(req.Form.Get("partNumber"), Equals, []string{"1", "2"}[i])
+		c.Assert(readAll(req.Body), Equals, body)
+	}
+	req = testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "POST")
+	c.Assert(readAll(req.Body), Matches, "<CompleteMultipartUpload>.*<PartNumber>2</PartNumber>.*")
+}
+
+func (s *S) TestPutStreamConcurrent(c *C) {
+	s.s3.UploadConcurrency = 3
+	defer func() { s.s3.UploadConcurrency = 0 }()
+
+	testServer.Response(200, nil, InitMultiResultDump)
+	testServer.Responses(4, 200, map[string]string{"ETag": `"etag"`}, "")
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.PutStream("stream", strings.NewReader("part1part2part3last"), "text/plain", s3.Private, 5)
+	c.Assert(err, IsNil)
+
+	testServer.WaitRequest()
+	bodies := make(map[string]string)
+	for _, req := range testServer.WaitRequests(4) {
+		c.Assert(req.Method, Equals, "PUT")
+		bodies[req.Form.Get("partNumber")] = readAll(req.Body)
+	}
+	c.Assert(bodies, DeepEquals, map[string]string{"1": "part1", "2": "part2", "3": "part3", "4": "last"})
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "POST")
+}
+
+func (s *S) TestPutStreamReadError(c *C) {
+	testServer.Response(200, nil, InitMultiResultDump)
+	testServer.Response(200, map[string]string{"ETag": `"etag"`}, "")
+	testServer.Response(204, nil, "")
+
+	// The upload is aborted when the stream fails halfway
+	b := s.s3.Bucket("sample")
+	r := io.MultiReader(strings.NewReader("part1par"), iotest.ErrReader(errors.New("broken pipe")))
+	err := b.PutStream("stream", r, "text/plain", s3.Private, 5)
+	c.Assert(err, ErrorMatches, "broken pipe")
+
+	testServer.WaitRequest()
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(readAll(req.Body), Equals, "part1")
+	req = testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "DELETE")
+	c.Assert(req.Form.Get("uploadId"), Matches, "JNbR_[A-Za-z0-9.]+QQ--")
+}
+
+func (s *S) TestPutAllError(c *C) {
+	s.DisableRetries()
+
+	testServer.Response(200, nil, InitMultiResultDump)
+	testServer.Response(404, nil, NoSuchUploadErrorDump)
+	testServer.Response(200, map[string]string{"ETag": `"etag1"`}, "")
+	testServer.Response(403, nil, "")
+
+	b := s.s3.Bucket("sample")
+
+	multi, err := b.InitMulti("multi", "text/plain", s3.Private)
+	c.Assert(err, IsNil)
+
+	parts, err := multi.PutAll(strings.NewReader("part1part2part3"), 5)
+	c.Assert(parts, IsNil)
+	c.Assert(err, ErrorMatches, "403 Forbidden")
+
+	// Sending stops at the failed part.
+	reqs := testServer.WaitRequests(4)
+	c.Assert(reqs[3].Form["partNumber"], DeepEquals, []string{"2"})
+	c.Assert(reqs[3].Method, Equals, "PUT")
+}
+
 func (s *S) TestPutAllResume(c *C) {
 	etag2 := map[string]string{"ETag": `"etag2"`}
 	testServer.Response(200, nil, InitMultiResultDump)
@@ -326,6 +490,31 @@ func (s *S) TestMultiComplete(c *C) {
 	c.Assert(payload.Part[0].ETag, Equals, `"ETag1"`)
 	c.Assert(payload.Part[1].PartNumber, Equals, 2)
 	c.Assert(payload.Part[1].ETag, Equals, `"ETag2"`)
+
+	// The late error is retried
+	req = testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "POST")
+	c.Assert(req.URL.Path, Equals, "/sample/multi")
+}
+
+func (s *S) TestMultiCompleteErrorBody(c *C) {
+	s.DisableRetries()
+
+	testServer.Response(200, nil, InitMultiResultDump)
+	testServer.Response(200, nil, InternalErrorDump)
+
+	b := s.s3.Bucket("sample")
+
+	multi, err := b.InitMulti("multi", "text/plain", s3.Private)
+	c.Assert(err, IsNil)
+
+	err = multi.Complete([]s3.Part{{1, `"ETag1"`, 64}})
+	c.Assert(err, ErrorMatches, `Not relevant \(InternalError\)`)
+	s3err, ok := err.(*s3.Error)
+	c.Assert(ok, Equals, true)
+	c.Assert(s3err.StatusCode, Equals, 200)
+	c.Assert(s3err.RequestId, Equals, "3F1B667FAD71C3D8")
+	c.Assert(s3err.Retryable, Equals, true)
 }
 
 func (s *S) TestMultiAbort(c *C) {
@@ -0,0 +1,102 @@
+package s3
+
+import (
+	"encoding/xml"
+)
+
+// Types of events notifications are sent for.
+const (
+	EventObjectCreated                = "s3:ObjectCreated:*"
+	EventObjectCreatedPut             = "s3:ObjectCreated:Put"
+	EventObjectCreatedPost            = "s3:ObjectCreated:Post"
+	EventObjectCreatedCopy            = "s3:ObjectCreated:Copy"
+	EventObjectCreatedMultipartUpload = "s3:ObjectCreated:CompleteMultipartUpload"
+	EventObjectRemoved                = "s3:ObjectRemoved:*"
+	EventObjectRemovedDelete          = "s3:ObjectRemoved:Delete"
+	EventObjectRemovedDeleteMarker    = "s3:ObjectRemoved:DeleteMarkerCreated"
+	EventObjectRestorePost            = "s3:ObjectRestore:Post"
+	EventObjectRestoreCompleted       = "s3:ObjectRestore:Completed"
+	EventReducedRedundancyLostObject  = "s3:ReducedRedundancyLostObject"
+)
+
+// Names of the rules filtering the keys of the objects notifications are
+// sent for.
+const (
+	FilterPrefix = "prefix"
+	FilterSuffix = "suffix"
+)
+
+// NotificationConfiguration holds where the notifications of the events of
+// a bucket are published. A configuration without destinations disables
+// notifications.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/dev/NotificationHowTo.html for details.
+type NotificationConfiguration struct {
+	XMLName                     xml.Name                     `xml:"NotificationConfiguration"`
+	TopicConfigurations         []TopicConfiguration         `xml:"TopicConfiguration,omitempty"`
+	QueueConfigurations         []QueueConfiguration         `xml:"QueueConfiguration,omitempty"`
+	CloudFunctionConfigurations []CloudFunctionConfiguration `xml:"CloudFunctionConfiguration,omitempty"`
+}
+
+// TopicConfiguration publishes Events to the SNS topic with the ARN Topic.
+type TopicConfiguration struct {
+	ID     string              `xml:"Id,omitempty"`
+	Filter *NotificationFilter `xml:"Filter,omitempty"`
+	Topic  string              `xml:"Topic"`
+	Events []string            `xml:"Event"`
+}
+
+// QueueConfiguration sends Events to the SQS queue with the ARN Queue.
+type QueueConfiguration struct {
+	ID     string              `xml:"Id,omitempty"`
+	Filter *NotificationFilter `xml:"Filter,omitempty"`
+	Queue  string              `xml:"Queue"`
+	Events []string            `xml:"Event"`
+}
+
+// CloudFunctionConfiguration invokes the Lambda function with the ARN
+// CloudFunction for Events. InvocationRole is only needed by the legacy
+// configurations which invoke the function with an IAM role.
+type CloudFunctionConfiguration struct {
+	ID             string              `xml:"Id,omitempty"`
+	Filter         *NotificationFilter `xml:"Filter,omitempty"`
+	CloudFunction  string              `xml:"CloudFunction"`
+	InvocationRole string              `xml:"InvocationRole,omitempty"`
+	Events         []string            `xml:"Event"`
+}
+
+// NotificationFilter selects the objects notifications are sent for by
+// the prefix and suffix of their keys.
+type NotificationFilter struct {
+	Rules []FilterRule `xml:"S3Key>FilterRule"`
+}
+
+// FilterRule requires the keys of objects to have the prefix or suffix
+// Value, as given by Name.
+type FilterRule struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+// PutNotification sets the notification configuration of b, replacing any
+// previous one. S3 checks that it may publish to the destinations, and
+// fails with an InvalidArgument error telling the destinations it
+// couldn't validate otherwise.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketPUTnotification.html for details.
+func (b *Bucket) PutNotification(config *NotificationConfiguration) error {
+	return b.putSubresourceXML("/", "notification", config)
+}
+
+// GetNotification returns the notification configuration of b, which has
+// no destinations if notifications aren't enabled.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketGETnotification.html for details.
+func (b *Bucket) GetNotification() (*NotificationConfiguration, error) {
+	config := &NotificationConfiguration{}
+	err := b.getSubresource("/", "notification", config)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
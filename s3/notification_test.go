@@ -0,0 +1,99 @@
+package s3_test
+
+import (
+	"encoding/xml"
+
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+// notificationExample holds the destinations of GetNotificationResultDump.
+var notificationExample = s3.NotificationConfiguration{
+	TopicConfigurations: []s3.TopicConfiguration{{
+		ID: "images",
+		Filter: &s3.NotificationFilter{Rules: []s3.FilterRule{
+			{Name: s3.FilterPrefix, Value: "images/"},
+			{Name: s3.FilterSuffix, Value: ".jpg"},
+		}},
+		Topic:  "arn:aws:sns:us-east-1:123456789012:images",
+		Events: []string{s3.EventObjectCreated},
+	}},
+	QueueConfigurations: []s3.QueueConfiguration{{
+		ID:     "removals",
+		Queue:  "arn:aws:sqs:us-east-1:123456789012:removals",
+		Events: []string{s3.EventObjectRemovedDelete, s3.EventObjectRemovedDeleteMarker},
+	}},
+	CloudFunctionConfigurations: []s3.CloudFunctionConfiguration{{
+		ID:            "thumbnails",
+		CloudFunction: "arn:aws:lambda:us-east-1:123456789012:function:thumbnails",
+		Events:        []string{s3.EventObjectCreatedPut},
+	}},
+}
+
+func (s *S) TestPutNotification(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.PutNotification(&notificationExample)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "notification=")
+	c.Assert(req.Header["Content-Md5"], HasLen, 1)
+
+	// The body holds the same destinations as the fixture
+	var sent, expected s3.NotificationConfiguration
+	c.Assert(xml.Unmarshal([]byte(readAll(req.Body)), &sent), IsNil)
+	c.Assert(xml.Unmarshal([]byte(GetNotificationResultDump), &expected), IsNil)
+	sent.XMLName, expected.XMLName = xml.Name{}, xml.Name{}
+	c.Assert(sent, DeepEquals, expected)
+}
+
+func (s *S) TestPutNotificationEmpty(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.PutNotification(&s3.NotificationConfiguration{})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(readAll(req.Body), Equals, xml.Header+"<NotificationConfiguration></NotificationConfiguration>")
+}
+
+func (s *S) TestPutNotificationInvalidDestination(c *C) {
+	testServer.Response(400, nil, InvalidNotificationErrorDump)
+
+	b := s.s3.Bucket("sample")
+	err := b.PutNotification(&notificationExample)
+	c.Assert(err, ErrorMatches, `Unable to validate the following destination configurations \(InvalidArgument\)`)
+	c.Assert(err.(*s3.Error).StatusCode, Equals, 400)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestGetNotification(c *C) {
+	testServer.Response(200, nil, GetNotificationResultDump)
+
+	b := s.s3.Bucket("sample")
+	config, err := b.GetNotification()
+	c.Assert(err, IsNil)
+	config.XMLName = xml.Name{}
+	c.Assert(*config, DeepEquals, notificationExample)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "notification=")
+}
+
+func (s *S) TestGetNotificationNotConfigured(c *C) {
+	testServer.Response(200, nil, `<NotificationConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"/>`)
+
+	b := s.s3.Bucket("sample")
+	config, err := b.GetNotification()
+	c.Assert(err, IsNil)
+	c.Assert(config.TopicConfigurations, HasLen, 0)
+	c.Assert(config.QueueConfigurations, HasLen, 0)
+	c.Assert(config.CloudFunctionConfigurations, HasLen, 0)
+}
@@ -0,0 +1,60 @@
+package s3
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/url"
+)
+
+// ErrNoBucketPolicy is returned by GetPolicy for buckets without a policy.
+var ErrNoBucketPolicy = errors.New("s3: the bucket has no policy")
+
+// PutPolicy sets the policy of b, a JSON document, replacing any previous
+// one. A policy S3 rejects fails with a MalformedPolicy error, whose
+// message tells what is wrong with it.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketPolicy.html for details.
+func (b *Bucket) PutPolicy(policy []byte) error {
+	return b.sendSubresource("PUT", "/", "policy", "application/json", policy)
+}
+
+// GetPolicy returns the policy of b, or ErrNoBucketPolicy if it has none.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketPolicy.html for details.
+func (b *Bucket) GetPolicy() ([]byte, error) {
+	req := &request{
+		bucket: b.Name,
+		path:   "/",
+		params: url.Values{"policy": {""}},
+	}
+	err := b.S3.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := b.S3.AttemptStrategy.Start(); attempt.Next(); {
+		resp, err := b.S3.run(req, nil)
+		if err == nil {
+			var data []byte
+			data, err = ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err == nil {
+				return data, nil
+			}
+		}
+		if shouldRetry(err) && attempt.HasNext() {
+			continue
+		}
+		if hasCode(err, "NoSuchBucketPolicy") {
+			return nil, ErrNoBucketPolicy
+		}
+		return nil, err
+	}
+	panic("unreachable")
+}
+
+// DelPolicy removes the policy of b.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketPolicy.html for details.
+func (b *Bucket) DelPolicy() error {
+	return b.delSubresource("/", "policy")
+}
@@ -0,0 +1,74 @@
+package s3_test
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+var policyExample = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::111122223333:root"},"Action":"s3:PutObject","Resource":"arn:aws:s3:::sample/*"}]}`
+
+func (s *S) TestPutPolicy(c *C) {
+	testServer.Response(204, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.PutPolicy([]byte(policyExample))
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "policy=")
+	c.Assert(req.Header["Content-Type"], DeepEquals, []string{"application/json"})
+	sum := md5.Sum([]byte(policyExample))
+	c.Assert(req.Header["Content-Md5"], DeepEquals, []string{base64.StdEncoding.EncodeToString(sum[:])})
+	c.Assert(readAll(req.Body), Equals, policyExample)
+}
+
+func (s *S) TestPutPolicyMalformed(c *C) {
+	testServer.Response(400, nil, MalformedPolicyErrorDump)
+
+	b := s.s3.Bucket("sample")
+	err := b.PutPolicy([]byte(`{"Version":"2012-10-17"}`))
+	c.Assert(err, ErrorMatches, `Policy has invalid resource \(MalformedPolicy\)`)
+	c.Assert(err.(*s3.Error).Message, Equals, "Policy has invalid resource")
+	testServer.WaitRequest()
+}
+
+func (s *S) TestGetPolicy(c *C) {
+	testServer.Response(200, map[string]string{"Content-Type": "application/json"}, policyExample)
+
+	b := s.s3.Bucket("sample")
+	policy, err := b.GetPolicy()
+	c.Assert(err, IsNil)
+	c.Assert(string(policy), Equals, policyExample)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "policy=")
+}
+
+func (s *S) TestGetPolicyNone(c *C) {
+	testServer.Response(404, nil, NoSuchBucketPolicyErrorDump)
+
+	b := s.s3.Bucket("sample")
+	_, err := b.GetPolicy()
+	c.Assert(err, Equals, s3.ErrNoBucketPolicy)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestDelPolicy(c *C) {
+	testServer.Response(204, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.DelPolicy()
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "DELETE")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "policy=")
+}
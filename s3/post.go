@@ -0,0 +1,134 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+)
+
+// PostCondition is a condition of a POST policy that the fields of an
+// upload form must meet. Conditions are made with PostEquals,
+// PostStartsWith, PostContentLengthRange, PostACL and PostSuccessRedirect.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-HTTPPOSTConstructPolicy.html for details.
+type PostCondition struct {
+	match    string
+	field    string
+	value    string
+	min, max int64
+}
+
+// PostEquals requires the form field to be value. The field is included
+// with that value in the fields returned by PostPolicy.
+func PostEquals(field, value string) PostCondition {
+	return PostCondition{match: "eq", field: field, value: value}
+}
+
+// PostStartsWith requires the form field to start with prefix, which may
+// be empty to allow any value. The browser sets the field.
+func PostStartsWith(field, prefix string) PostCondition {
+	return PostCondition{match: "starts-with", field: field, value: prefix}
+}
+
+// PostContentLengthRange requires the size of the uploaded file to be
+// between min and max bytes, inclusive.
+func PostContentLengthRange(min, max int64) PostCondition {
+	return PostCondition{match: "content-length-range", min: min, max: max}
+}
+
+// PostACL requires the uploaded object to be given the canned ACL perm.
+func PostACL(perm ACL) PostCondition {
+	return PostEquals("acl", string(perm))
+}
+
+// PostSuccessRedirect requires the browser to be redirected to url once
+// the upload succeeds.
+func PostSuccessRedirect(url string) PostCondition {
+	return PostEquals("success_action_redirect", url)
+}
+
+// policyCondition returns the form of c in the JSON policy document.
+func (c PostCondition) policyCondition() interface{} {
+	switch c.match {
+	case "eq":
+		return map[string]string{c.field: c.value}
+	case "content-length-range":
+		return []interface{}{c.match, c.min, c.max}
+	}
+	return []interface{}{c.match, "$" + c.field, c.value}
+}
+
+// PostPolicy returns the fields of an HTML form, to be sent as
+// multipart/form-data to b.URL("/") along with the "file" field, which
+// allows anyone to upload an object at key until expires. The upload must
+// meet conds. key may hold ${filename} to be replaced by the name of the
+// file, if conds has a PostStartsWith condition on the "key" field.
+//
+// The policy is signed with Signature Version 4 in regions which require
+// it, and with the legacy S3 signature otherwise.
+func (b *Bucket) PostPolicy(key string, expires time.Time, conds []PostCondition) (fields map[string]string, err error) {
+	return b.postPolicy(key, expires, conds, b.S3.clock.Now())
+}
+
+func (b *Bucket) postPolicy(key string, expires time.Time, conds []PostCondition, now time.Time) (map[string]string, error) {
+	auth, err := aws.CurrentAuth(b.S3.Auth, b.S3.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]string{"key": key}
+	conditions := []interface{}{map[string]string{"bucket": b.Name}}
+	keyMatched := false
+	for _, c := range conds {
+		if c.match == "eq" {
+			fields[c.field] = c.value
+		}
+		keyMatched = keyMatched || c.field == "key"
+		conditions = append(conditions, c.policyCondition())
+	}
+	if !keyMatched {
+		conditions = append(conditions, PostEquals("key", key).policyCondition())
+	}
+	v4 := b.S3.Region.S3SignatureV4
+	if v4 {
+		now = now.UTC()
+		fields["x-amz-algorithm"] = "AWS4-HMAC-SHA256"
+		fields["x-amz-credential"] = auth.AccessKey + "/" + now.Format(aws.ISO8601BasicFormatShort) + "/" + b.S3.Region.Name + "/s3/aws4_request"
+		fields["x-amz-date"] = now.Format(aws.ISO8601BasicFormat)
+		for _, name := range []string{"x-amz-algorithm", "x-amz-credential", "x-amz-date"} {
+			conditions = append(conditions, map[string]string{name: fields[name]})
+		}
+	} else {
+		fields["AWSAccessKeyId"] = auth.AccessKey
+	}
+	if token := auth.Token(); token != "" {
+		fields["x-amz-security-token"] = token
+		conditions = append(conditions, map[string]string{"x-amz-security-token": token})
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err = enc.Encode(struct {
+		Expiration string        `json:"expiration"`
+		Conditions []interface{} `json:"conditions"`
+	}{expires.UTC().Format("2006-01-02T15:04:05Z"), conditions})
+	if err != nil {
+		return nil, err
+	}
+	policy := base64.StdEncoding.EncodeToString(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	fields["policy"] = policy
+
+	if v4 {
+		fields["x-amz-signature"] = aws.SignPolicyV4(policy, auth, b.S3.Region.Name, "s3", now)
+	} else {
+		signer := hmac.New(sha1.New, []byte(auth.SecretKey))
+		signer.Write([]byte(policy))
+		fields["signature"] = base64.StdEncoding.EncodeToString(signer.Sum(nil))
+	}
+	return fields, nil
+}
@@ -200,3 +200,416 @@ var InternalErrorDump = `
   <HostId>kjhwqk</HostId>
 </Error>
 `
+
+var SlowDownErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>SlowDown</Code>
+  <Message>Please reduce your request rate.</Message>
+  <RequestId>3F1B667FAD71C3D8</RequestId>
+</Error>
+`
+
+var InvalidRangeErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>InvalidRange</Code>
+  <Message>The requested range is not satisfiable</Message>
+  <RangeRequested>bytes=100-</RangeRequested>
+  <ActualObjectSize>10</ActualObjectSize>
+  <RequestId>3F1B667FAD71C3D8</RequestId>
+</Error>
+`
+
+var PreconditionFailedErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>PreconditionFailed</Code>
+  <Message>At least one of the pre-conditions you specified did not hold</Message>
+  <Condition>If-Match</Condition>
+  <RequestId>3F1B667FAD71C3D8</RequestId>
+</Error>
+`
+
+var PutCopyResultDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<CopyObjectResult>
+  <LastModified>2009-10-28T22:32:00</LastModified>
+  <ETag>"9b2cf535f27731c974343645a3985328"</ETag>
+</CopyObjectResult>
+`
+
+var CopyPartResultDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<CopyPartResult>
+  <LastModified>2011-04-11T20:34:56.000Z</LastModified>
+  <ETag>"b54357faf0632cce46e942fa68356b38"</ETag>
+</CopyPartResult>
+`
+
+var CompleteMultiResultDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<CompleteMultipartUploadResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Location>http://sample.s3.amazonaws.com/multi</Location>
+  <Bucket>sample</Bucket>
+  <Key>multi</Key>
+  <ETag>"3858f62230ac3c915f300c664312c11f-3"</ETag>
+</CompleteMultipartUploadResult>
+`
+
+var GetTaggingResultDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Tagging xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <TagSet>
+    <Tag>
+      <Key>project</Key>
+      <Value>goamz</Value>
+    </Tag>
+    <Tag>
+      <Key>owner</Key>
+      <Value>Zoë Ångström</Value>
+    </Tag>
+  </TagSet>
+</Tagging>
+`
+
+var DeleteResultDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<DeleteResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Deleted>
+    <Key>sample1.txt</Key>
+  </Deleted>
+  <Deleted>
+    <Key>sample2.txt</Key>
+    <VersionId>v2</VersionId>
+  </Deleted>
+  <Error>
+    <Key>sample3.txt</Key>
+    <Code>AccessDenied</Code>
+    <Message>Access Denied</Message>
+  </Error>
+</DeleteResult>
+`
+
+var DeleteResultQuietDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<DeleteResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Error>
+    <Key>sample3.txt</Key>
+    <Code>AccessDenied</Code>
+    <Message>Access Denied</Message>
+  </Error>
+</DeleteResult>
+`
+
+var GetLifecycleResultDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Rule>
+    <ID>logs</ID>
+    <Filter>
+      <Prefix>logs/</Prefix>
+    </Filter>
+    <Status>Enabled</Status>
+    <Transition>
+      <Days>30</Days>
+      <StorageClass>STANDARD_IA</StorageClass>
+    </Transition>
+    <Transition>
+      <Days>90</Days>
+      <StorageClass>GLACIER</StorageClass>
+    </Transition>
+    <Expiration>
+      <Days>365</Days>
+    </Expiration>
+  </Rule>
+  <Rule>
+    <ID>tmp</ID>
+    <Prefix>tmp/</Prefix>
+    <Status>Disabled</Status>
+    <Expiration>
+      <Date>2016-01-01T00:00:00.000Z</Date>
+    </Expiration>
+  </Rule>
+  <Rule>
+    <ID>versions</ID>
+    <Filter>
+      <And>
+        <Prefix>data/</Prefix>
+        <Tag>
+          <Key>archive</Key>
+          <Value>true</Value>
+        </Tag>
+      </And>
+    </Filter>
+    <Status>Enabled</Status>
+    <NoncurrentVersionTransition>
+      <NoncurrentDays>10</NoncurrentDays>
+      <StorageClass>GLACIER</StorageClass>
+    </NoncurrentVersionTransition>
+    <NoncurrentVersionExpiration>
+      <NoncurrentDays>30</NoncurrentDays>
+    </NoncurrentVersionExpiration>
+    <AbortIncompleteMultipartUpload>
+      <DaysAfterInitiation>7</DaysAfterInitiation>
+    </AbortIncompleteMultipartUpload>
+  </Rule>
+</LifecycleConfiguration>
+`
+
+var NoSuchLifecycleConfigurationErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>NoSuchLifecycleConfiguration</Code>
+  <Message>The lifecycle configuration does not exist</Message>
+  <BucketName>sample</BucketName>
+  <RequestId>7B5D1BDB2B4AF0A9</RequestId>
+  <HostId>kjhwqk</HostId>
+</Error>
+`
+
+var GetCORSResultDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<CORSConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <CORSRule>
+    <ID>uploads</ID>
+    <AllowedOrigin>https://example.com</AllowedOrigin>
+    <AllowedOrigin>https://www.example.com</AllowedOrigin>
+    <AllowedMethod>PUT</AllowedMethod>
+    <AllowedMethod>POST</AllowedMethod>
+    <AllowedHeader>*</AllowedHeader>
+    <ExposeHeader>ETag</ExposeHeader>
+    <MaxAgeSeconds>3000</MaxAgeSeconds>
+  </CORSRule>
+  <CORSRule>
+    <AllowedOrigin>*</AllowedOrigin>
+    <AllowedMethod>GET</AllowedMethod>
+  </CORSRule>
+</CORSConfiguration>
+`
+
+var NoSuchCORSConfigurationErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>NoSuchCORSConfiguration</Code>
+  <Message>The CORS configuration does not exist</Message>
+  <BucketName>sample</BucketName>
+  <RequestId>0A49CE4060975EAC</RequestId>
+  <HostId>kjhwqk</HostId>
+</Error>
+`
+
+var ListVersionsResultDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<ListVersionsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01">
+  <Name>bucket</Name>
+  <Prefix>my</Prefix>
+  <KeyMarker/>
+  <VersionIdMarker/>
+  <NextKeyMarker>my-second-image.jpg</NextKeyMarker>
+  <NextVersionIdMarker>03jpff543dhffds434rfdsFDN943fdsFkdmqnh892</NextVersionIdMarker>
+  <MaxKeys>3</MaxKeys>
+  <IsTruncated>true</IsTruncated>
+  <Version>
+    <Key>my-image.jpg</Key>
+    <VersionId>3/L4kqtJl40Nr8X8gdRQBpUMLUo</VersionId>
+    <IsLatest>true</IsLatest>
+    <LastModified>2009-10-12T17:50:30.000Z</LastModified>
+    <ETag>&quot;fba9dede5f27731c9771645a39863328&quot;</ETag>
+    <Size>434234</Size>
+    <StorageClass>STANDARD</StorageClass>
+    <Owner>
+      <ID>75aa57f09aa0c8caeab4f8c24e99d10f8e7faeebf76c078efc7c6caea54ba06a</ID>
+      <DisplayName>mtd@amazon.com</DisplayName>
+    </Owner>
+  </Version>
+  <DeleteMarker>
+    <Key>my-second-image.jpg</Key>
+    <VersionId>03jpff543dhffds434rfdsFDN943fdsFkdmqnh892</VersionId>
+    <IsLatest>true</IsLatest>
+    <LastModified>2009-11-12T17:50:30.000Z</LastModified>
+    <Owner>
+      <ID>75aa57f09aa0c8caeab4f8c24e99d10f8e7faeebf76c078efc7c6caea54ba06a</ID>
+      <DisplayName>mtd@amazon.com</DisplayName>
+    </Owner>
+  </DeleteMarker>
+  <Version>
+    <Key>my-second-image.jpg</Key>
+    <VersionId>QUpfdndhfd8438MNFDN93jdnJFkdmqnh893</VersionId>
+    <IsLatest>false</IsLatest>
+    <LastModified>2009-10-10T17:50:30.000Z</LastModified>
+    <ETag>&quot;9b2cf535f27731c974343645a3985328&quot;</ETag>
+    <Size>166434</Size>
+    <StorageClass>STANDARD</StorageClass>
+    <Owner>
+      <ID>75aa57f09aa0c8caeab4f8c24e99d10f8e7faeebf76c078efc7c6caea54ba06a</ID>
+      <DisplayName>mtd@amazon.com</DisplayName>
+    </Owner>
+  </Version>
+</ListVersionsResult>
+`
+
+var NoSuchBucketPolicyErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>NoSuchBucketPolicy</Code>
+  <Message>The bucket policy does not exist</Message>
+  <BucketName>sample</BucketName>
+  <RequestId>3F1B667FAD71C3D8</RequestId>
+</Error>
+`
+
+var MalformedPolicyErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>MalformedPolicy</Code>
+  <Message>Policy has invalid resource</Message>
+  <RequestId>3F1B667FAD71C3D8</RequestId>
+</Error>
+`
+
+var GetACLResultDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<AccessControlPolicy xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Owner>
+    <ID>75aa57f09aa0c8caeab4f8c24e99d10f8e7faeebf76c078efc7c6caea54ba06a</ID>
+    <DisplayName>CustomersName@amazon.com</DisplayName>
+  </Owner>
+  <AccessControlList>
+    <Grant>
+      <Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser">
+        <ID>75aa57f09aa0c8caeab4f8c24e99d10f8e7faeebf76c078efc7c6caea54ba06a</ID>
+        <DisplayName>CustomersName@amazon.com</DisplayName>
+      </Grantee>
+      <Permission>FULL_CONTROL</Permission>
+    </Grant>
+    <Grant>
+      <Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group">
+        <URI>http://acs.amazonaws.com/groups/global/AllUsers</URI>
+      </Grantee>
+      <Permission>READ</Permission>
+    </Grant>
+  </AccessControlList>
+</AccessControlPolicy>
+`
+
+var GetVersioningResultDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Status>Enabled</Status>
+</VersioningConfiguration>
+`
+
+var GetLocationResultDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">eu-central-1</LocationConstraint>
+`
+
+var PermanentRedirectErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>PermanentRedirect</Code>
+  <Message>The bucket you are attempting to access must be addressed using the specified endpoint. Please send all future requests to this endpoint.</Message>
+  <Bucket>bucket</Bucket>
+  <Endpoint>bucket.s3.faux-region-2.amazonaws.com</Endpoint>
+  <RequestId>3F1B667FAD71C3D8</RequestId>
+</Error>
+`
+
+var AuthorizationHeaderMalformedErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>AuthorizationHeaderMalformed</Code>
+  <Message>The authorization header is malformed; the region 'faux-region-1' is wrong; expecting 'faux-region-2'</Message>
+  <Region>faux-region-2</Region>
+  <RequestId>3F1B667FAD71C3D8</RequestId>
+</Error>
+`
+
+var GetNotificationResultDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<NotificationConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <TopicConfiguration>
+    <Id>images</Id>
+    <Filter>
+      <S3Key>
+        <FilterRule>
+          <Name>prefix</Name>
+          <Value>images/</Value>
+        </FilterRule>
+        <FilterRule>
+          <Name>suffix</Name>
+          <Value>.jpg</Value>
+        </FilterRule>
+      </S3Key>
+    </Filter>
+    <Topic>arn:aws:sns:us-east-1:123456789012:images</Topic>
+    <Event>s3:ObjectCreated:*</Event>
+  </TopicConfiguration>
+  <QueueConfiguration>
+    <Id>removals</Id>
+    <Queue>arn:aws:sqs:us-east-1:123456789012:removals</Queue>
+    <Event>s3:ObjectRemoved:Delete</Event>
+    <Event>s3:ObjectRemoved:DeleteMarkerCreated</Event>
+  </QueueConfiguration>
+  <CloudFunctionConfiguration>
+    <Id>thumbnails</Id>
+    <CloudFunction>arn:aws:lambda:us-east-1:123456789012:function:thumbnails</CloudFunction>
+    <Event>s3:ObjectCreated:Put</Event>
+  </CloudFunctionConfiguration>
+</NotificationConfiguration>
+`
+
+var InvalidNotificationErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>InvalidArgument</Code>
+  <Message>Unable to validate the following destination configurations</Message>
+  <ArgumentName1>arn:aws:sqs:us-east-1:123456789012:removals</ArgumentName1>
+  <ArgumentValue1>Permissions on the destination queue do not allow S3 to publish notifications from this bucket</ArgumentValue1>
+  <RequestId>3F1B667FAD71C3D8</RequestId>
+</Error>
+`
+
+var GetWebsiteResultDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<WebsiteConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <IndexDocument>
+    <Suffix>index.html</Suffix>
+  </IndexDocument>
+  <ErrorDocument>
+    <Key>error.html</Key>
+  </ErrorDocument>
+  <RoutingRules>
+    <RoutingRule>
+      <Condition>
+        <KeyPrefixEquals>docs/</KeyPrefixEquals>
+      </Condition>
+      <Redirect>
+        <ReplaceKeyPrefixWith>documents/</ReplaceKeyPrefixWith>
+      </Redirect>
+    </RoutingRule>
+    <RoutingRule>
+      <Condition>
+        <HttpErrorCodeReturnedEquals>404</HttpErrorCodeReturnedEquals>
+      </Condition>
+      <Redirect>
+        <Protocol>https</Protocol>
+        <HostName>example.com</HostName>
+        <ReplaceKeyWith>not-found.html</ReplaceKeyWith>
+        <HttpRedirectCode>302</HttpRedirectCode>
+      </Redirect>
+    </RoutingRule>
+  </RoutingRules>
+</WebsiteConfiguration>
+`
+
+var NoSuchWebsiteConfigurationErrorDump = `
+<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>NoSuchWebsiteConfiguration</Code>
+  <Message>The specified bucket does not have a website configuration</Message>
+  <BucketName>sample</BucketName>
+  <RequestId>3F1B667FAD71C3D8</RequestId>
+</Error>
+`
@@ -18,6 +18,7 @@ import (
 	"encoding/base64"
 	"encoding/xml"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
 	"log"
@@ -27,6 +28,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/goamz/goamz/aws"
@@ -39,6 +41,11 @@ type S3 struct {
 	aws.Auth
 	aws.Region
 
+	// Credentials, when not nil, provides the credentials used to sign
+	// each request instead of Auth, so that temporary credentials can be
+	// renewed without creating a new S3.
+	Credentials aws.CredentialsProvider
+
 	// ConnectTimeout is the maximum time a request attempt will
 	// wait for a successful connection to be made.
 	//
@@ -70,11 +77,36 @@ type S3 struct {
 	// AttemptStrategy is the attempt strategy used for requests.
 	aws.AttemptStrategy
 
+	// Addressing selects whether buckets are addressed in the path or in
+	// the host name of requests.
+	Addressing Addressing
+
+	// RetryPolicy is used to retry each single request before the
+	// AttemptStrategy applies. It defaults to aws.DefaultRetryPolicy for
+	// requests other than POST, which aren't retried unless RetryPolicy
+	// is set. Only bodies implementing io.Seeker can be replayed.
+	RetryPolicy *aws.RetryPolicy
+
+	// UploadConcurrency is the number of parts Bucket.PutStream sends at
+	// a time. Zero means one.
+	UploadConcurrency int
+
+	// HTTPClient, when not nil, performs the requests instead of a client
+	// built from the timeouts above, which are then ignored.
+	HTTPClient *http.Client
+
 	// Reserve the right of using private data.
 	private byte
 
+	// regions holds the regions buckets were redirected to, by name.
+	regions sync.Map
+
 	// client used for requests
 	client *http.Client
+
+	// clock corrects the time requests are signed with when the local
+	// clock is skewed.
+	clock aws.Clock
 }
 
 // The Bucket type encapsulates operations with an S3 bucket.
@@ -98,6 +130,38 @@ type Options struct {
 	CacheControl     string
 	RedirectLocation string
 	ContentMD5       string
+
+	// Checksum makes Put and PutReader send the Content-MD5 of the object
+	// unless ContentMD5 is given, so that S3 rejects corrupted uploads.
+	// The ETag S3 returns is checked against it, and an IntegrityError is
+	// returned if they differ. Readers which aren't an io.ReadSeeker are
+	// hashed while they are sent, and only their ETag is checked.
+	Checksum bool
+
+	// ServerSideEncryption is the algorithm S3 encrypts the object with,
+	// AES256 or aws:kms. SSE is the same as AES256.
+	ServerSideEncryption string
+	// SSEKMSKeyId is the KMS key encrypting the object with aws:kms. The
+	// default key of the account is used when empty.
+	SSEKMSKeyId string
+
+	// SSECustomerKey is the base64 encoded key, provided by the caller,
+	// S3 encrypts the object with (SSE-C). S3 doesn't keep the key, which
+	// must be given again to read the object, see SSECustomerHeaders.
+	// SSECustomerAlgorithm defaults to AES256 and SSECustomerKeyMD5 to the
+	// base64 encoded MD5 digest of the key.
+	SSECustomerAlgorithm string
+	SSECustomerKey       string
+	SSECustomerKeyMD5    string
+
+	// StorageClass is the storage class of the object, such as
+	// StorageStandardIA. Objects are stored in StorageStandard by default.
+	StorageClass string
+
+	// Tags are set on the object when it is written. At most 10 tags are
+	// allowed, with keys of up to 128 characters and values of up to 256.
+	// Tags given to PutCopy replace the ones of the source object.
+	Tags map[string]string
 	// What else?
 	// Content-Disposition string
 	//// The following become headers so they are []strings rather than strings... I think
@@ -108,6 +172,49 @@ type CopyOptions struct {
 	Options
 	MetadataDirective string
 	ContentType       string
+
+	// CopySourceSSECustomerKey is the base64 encoded key, provided by the
+	// caller, the source object is encrypted with. The algorithm and MD5
+	// digest default as for Options.SSECustomerKey.
+	CopySourceSSECustomerAlgorithm string
+	CopySourceSSECustomerKey       string
+	CopySourceSSECustomerKeyMD5    string
+
+	// CopySourceVersionId is the version of the source object copied. Its
+	// current version is copied when empty.
+	CopySourceVersionId string
+
+	// The copy fails with PreconditionFailed unless the source object
+	// matches the conditions which are set.
+	CopySourceIfMatch           string
+	CopySourceIfNoneMatch       string
+	CopySourceIfModifiedSince   time.Time
+	CopySourceIfUnmodifiedSince time.Time
+}
+
+// Encryption describes how an object is encrypted at rest, as reported by
+// the headers of S3 responses.
+type Encryption struct {
+	// ServerSideEncryption is AES256 or aws:kms when S3 manages the key,
+	// and empty otherwise.
+	ServerSideEncryption string
+	SSEKMSKeyId          string
+
+	// SSECustomerAlgorithm and SSECustomerKeyMD5 identify the key provided
+	// by the caller, if any.
+	SSECustomerAlgorithm string
+	SSECustomerKeyMD5    string
+}
+
+// ResponseEncryption returns the encryption of the object reported by the
+// headers of resp, as returned by GetResponse or Head.
+func ResponseEncryption(resp *http.Response) Encryption {
+	return Encryption{
+		ServerSideEncryption: resp.Header.Get("x-amz-server-side-encryption"),
+		SSEKMSKeyId:          resp.Header.Get("x-amz-server-side-encryption-aws-kms-key-id"),
+		SSECustomerAlgorithm: resp.Header.Get("x-amz-server-side-encryption-customer-algorithm"),
+		SSECustomerKeyMD5:    resp.Header.Get("x-amz-server-side-encryption-customer-key-MD5"),
+	}
 }
 
 // CopyObjectResult is the output from a Copy request
@@ -123,6 +230,32 @@ var DefaultAttemptStrategy = aws.AttemptStrategy{
 	Delay: 200 * time.Millisecond,
 }
 
+// expectContinueTimeout is how long PUT requests wait for S3 to accept
+// them, or reject them early, before their body is sent anyway.
+const expectContinueTimeout = time.Second
+
+// Addressing is how buckets are addressed in the URLs of requests.
+type Addressing int
+
+const (
+	// AutoAddressing, the default, addresses buckets in the host name
+	// when the Region has an S3BucketEndpoint, unless their names have
+	// dots or other characters which don't fit in a host name matched by
+	// the certificates of S3, and in the path otherwise.
+	AutoAddressing Addressing = iota
+
+	// PathStyle addresses buckets in the path, as in
+	// https://s3.amazonaws.com/bucket/key, which suits local servers
+	// such as localstack.
+	PathStyle
+
+	// VirtualHostedStyle addresses buckets in the host name, as in
+	// https://bucket.s3.amazonaws.com/key, using the S3BucketEndpoint of
+	// the Region or otherwise prepending the bucket to the host of its
+	// S3Endpoint.
+	VirtualHostedStyle
+)
+
 // New creates a new S3.
 func New(auth aws.Auth, region aws.Region) *S3 {
 	return &S3{Auth: auth, Region: region, AttemptStrategy: DefaultAttemptStrategy}
@@ -199,6 +332,26 @@ func (b *Bucket) DelBucket() (err error) {
 	return err
 }
 
+// Location returns the name of the region b is in.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketLocation.html for details.
+func (b *Bucket) Location() (string, error) {
+	var constraint struct {
+		Region string `xml:",chardata"`
+	}
+	err := b.getSubresource("/", "location", &constraint)
+	if err != nil {
+		return "", err
+	}
+	switch constraint.Region {
+	case "":
+		return aws.USEast.Name, nil
+	case "EU":
+		return aws.EUWest.Name, nil
+	}
+	return constraint.Region, nil
+}
+
 // Get retrieves an object from an S3 bucket.
 //
 // See http://goo.gl/isCO7 for details.
@@ -242,9 +395,33 @@ func (b *Bucket) GetResponse(path string) (resp *http.Response, err error) {
 // It is the caller's responsibility to call Close on rc when
 // finished reading
 func (b *Bucket) GetResponseWithHeaders(path string, headers map[string][]string) (resp *http.Response, err error) {
+	return b.getResponse(path, "", headers)
+}
+
+// GetVersion retrieves the given version of an object from a versioned
+// S3 bucket.
+func (b *Bucket) GetVersion(path, versionId string) (data []byte, err error) {
+	resp, err := b.GetResponseVersion(path, versionId)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+// GetResponseVersion retrieves the given version of an object from a
+// versioned S3 bucket, returning the HTTP response.
+// It is the caller's responsibility to call Close on the response body
+// when finished reading.
+func (b *Bucket) GetResponseVersion(path, versionId string) (resp *http.Response, err error) {
+	return b.getResponse(path, versionId, make(http.Header))
+}
+
+func (b *Bucket) getResponse(path, versionId string, headers map[string][]string) (resp *http.Response, err error) {
 	req := &request{
 		bucket:  b.Name,
 		path:    path,
+		params:  versionParams(versionId),
 		headers: headers,
 	}
 	err = b.S3.prepare(req)
@@ -301,10 +478,17 @@ func (b *Bucket) Exists(path string) (exists bool, err error) {
 // Head HEADs an object in the S3 bucket, returns the response with
 // no body see http://bit.ly/17K1ylI
 func (b *Bucket) Head(path string, headers map[string][]string) (*http.Response, error) {
+	return b.HeadVersion(path, "", headers)
+}
+
+// HeadVersion HEADs the given version of an object in a versioned S3
+// bucket, as Head does.
+func (b *Bucket) HeadVersion(path, versionId string, headers map[string][]string) (*http.Response, error) {
 	req := &request{
 		method:  "HEAD",
 		bucket:  b.Name,
 		path:    path,
+		params:  versionParams(versionId),
 		headers: headers,
 	}
 	err := b.S3.prepare(req)
@@ -329,29 +513,50 @@ func (b *Bucket) Head(path string, headers map[string][]string) (*http.Response,
 //
 // See http://goo.gl/FEBPD for details.
 func (b *Bucket) Put(path string, data []byte, contType string, perm ACL, options Options) error {
-	body := bytes.NewBuffer(data)
+	body := bytes.NewReader(data)
 	return b.PutReader(path, body, int64(len(data)), contType, perm, options)
 }
 
 // PutCopy puts a copy of an object given by the key path into bucket b using b.Path as the target key
 func (b *Bucket) PutCopy(path string, perm ACL, options CopyOptions, source string) (*CopyObjectResult, error) {
+	if err := validateTags(options.Tags); err != nil {
+		return nil, err
+	}
+	if options.CopySourceVersionId != "" {
+		source += "?versionId=" + url.QueryEscape(options.CopySourceVersionId)
+	}
 	headers := map[string][]string{
 		"x-amz-acl":         {string(perm)},
 		"x-amz-copy-source": {source},
 	}
 	options.addHeaders(headers)
-	req := &request{
-		method:  "PUT",
-		bucket:  b.Name,
-		path:    path,
-		headers: headers,
-	}
-	resp := &CopyObjectResult{}
-	err := b.S3.query(req, resp)
-	if err != nil {
-		return resp, err
+	for attempt := b.S3.AttemptStrategy.Start(); attempt.Next(); {
+		req := &request{
+			method:  "PUT",
+			bucket:  b.Name,
+			path:    path,
+			headers: headers,
+		}
+		err := b.S3.prepare(req)
+		if err != nil {
+			return nil, err
+		}
+		result := &CopyObjectResult{}
+		resp, err := b.S3.run(req, nil)
+		if err == nil {
+			// S3 holds the connection while copying, and may report
+			// a late error.
+			err = readResult(resp, result)
+		}
+		if shouldRetry(err) && attempt.HasNext() {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
 	}
-	return resp, nil
+	panic("unreachable")
 }
 
 /*
@@ -359,27 +564,92 @@ PutHeader - like Put, inserts an object into the S3 bucket.
 Instead of Content-Type string, pass in custom headers to override defaults.
 */
 func (b *Bucket) PutHeader(path string, data []byte, customHeaders map[string][]string, perm ACL) error {
-	body := bytes.NewBuffer(data)
+	body := bytes.NewReader(data)
 	return b.PutReaderHeader(path, body, int64(len(data)), customHeaders, perm)
 }
 
 // PutReader inserts an object into the S3 bucket by consuming data
 // from r until EOF.
 func (b *Bucket) PutReader(path string, r io.Reader, length int64, contType string, perm ACL, options Options) error {
+	if err := validateTags(options.Tags); err != nil {
+		return err
+	}
 	headers := map[string][]string{
 		"Content-Length": {strconv.FormatInt(length, 10)},
 		"Content-Type":   {contType},
 		"x-amz-acl":      {string(perm)},
 	}
+	var h hash.Hash
+	if options.Checksum && options.ContentMD5 == "" {
+		if rs, ok := r.(io.ReadSeeker); ok {
+			md5b64, err := seekerMD5(rs)
+			if err != nil {
+				return err
+			}
+			options.ContentMD5 = md5b64
+		} else {
+			h = md5.New()
+			r = io.TeeReader(r, h)
+		}
+	}
 	options.addHeaders(headers)
-	req := &request{
-		method:  "PUT",
-		bucket:  b.Name,
-		path:    path,
-		headers: headers,
-		payload: r,
+	resp, err := b.putReader(path, r, headers)
+	if err != nil {
+		return err
 	}
-	return b.S3.query(req, nil)
+	resp.Body.Close()
+	if !options.Checksum {
+		return nil
+	}
+	var sum []byte
+	if h != nil {
+		sum = h.Sum(nil)
+	} else if sum, err = base64.StdEncoding.DecodeString(options.ContentMD5); err != nil {
+		return fmt.Errorf("s3: invalid Content-MD5 %q: %v", options.ContentMD5, err)
+	}
+	return checkETag(path, resp.Header, sum)
+}
+
+// putReader sends the content of r to path with the given headers. If r
+// is an io.ReadSeeker, it is rewound to its current position to be sent
+// again as the AttemptStrategy allows, and otherwise a single attempt is
+// made.
+func (b *Bucket) putReader(path string, r io.Reader, headers map[string][]string) (*http.Response, error) {
+	rs, seekable := r.(io.ReadSeeker)
+	var start int64
+	if seekable {
+		var err error
+		if start, err = rs.Seek(0, io.SeekCurrent); err != nil {
+			return nil, err
+		}
+	}
+	for attempt := b.S3.AttemptStrategy.Start(); attempt.Next(); {
+		// run consumes the Content-Length header.
+		hdrs := make(map[string][]string, len(headers))
+		for k, v := range headers {
+			hdrs[k] = v
+		}
+		req := &request{
+			method:  "PUT",
+			bucket:  b.Name,
+			path:    path,
+			headers: hdrs,
+			payload: r,
+		}
+		err := b.S3.prepare(req)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := b.S3.run(req, nil)
+		if seekable && shouldRetry(err) && attempt.HasNext() {
+			if _, serr := rs.Seek(start, io.SeekStart); serr != nil {
+				return nil, serr
+			}
+			continue
+		}
+		return resp, err
+	}
+	panic("unreachable")
 }
 
 /*
@@ -414,6 +684,15 @@ func (o Options) addHeaders(headers map[string][]string) {
 	if o.SSE {
 		headers["x-amz-server-side-encryption"] = []string{"AES256"}
 	}
+	if len(o.ServerSideEncryption) != 0 {
+		headers["x-amz-server-side-encryption"] = []string{o.ServerSideEncryption}
+	}
+	if len(o.SSEKMSKeyId) != 0 {
+		headers["x-amz-server-side-encryption-aws-kms-key-id"] = []string{o.SSEKMSKeyId}
+	}
+	for k, v := range o.SSECustomerHeaders() {
+		headers[k] = v
+	}
 	if len(o.ContentEncoding) != 0 {
 		headers["Content-Encoding"] = []string{o.ContentEncoding}
 	}
@@ -429,17 +708,75 @@ func (o Options) addHeaders(headers map[string][]string) {
 	for k, v := range o.Meta {
 		headers["x-amz-meta-"+k] = v
 	}
+	if len(o.StorageClass) != 0 {
+		headers["x-amz-storage-class"] = []string{o.StorageClass}
+	}
+	if len(o.Tags) != 0 {
+		headers["x-amz-tagging"] = []string{encodeTags(o.Tags)}
+	}
+}
+
+// SSECustomerHeaders returns the headers giving the key provided by the
+// caller in o, which must be sent to read an object encrypted with it,
+// as with GetResponseWithHeaders or Head. It returns nil if o has no such
+// key.
+func (o Options) SSECustomerHeaders() map[string][]string {
+	return sseCustomerHeaders("x-amz-server-side-encryption-customer-", o.SSECustomerAlgorithm, o.SSECustomerKey, o.SSECustomerKeyMD5)
+}
+
+func sseCustomerHeaders(prefix, algorithm, key, keyMD5 string) map[string][]string {
+	if len(key) == 0 {
+		return nil
+	}
+	if len(algorithm) == 0 {
+		algorithm = "AES256"
+	}
+	if len(keyMD5) == 0 {
+		// An invalid key is reported by S3
+		raw, _ := base64.StdEncoding.DecodeString(key)
+		sum := md5.Sum(raw)
+		keyMD5 = base64.StdEncoding.EncodeToString(sum[:])
+	}
+	return map[string][]string{
+		prefix + "algorithm": {algorithm},
+		prefix + "key":       {key},
+		prefix + "key-MD5":   {keyMD5},
+	}
 }
 
 // addHeaders adds o's specified fields to headers
 func (o CopyOptions) addHeaders(headers map[string][]string) {
 	o.Options.addHeaders(headers)
+	for k, v := range sseCustomerHeaders("x-amz-copy-source-server-side-encryption-customer-", o.CopySourceSSECustomerAlgorithm, o.CopySourceSSECustomerKey, o.CopySourceSSECustomerKeyMD5) {
+		headers[k] = v
+	}
 	if len(o.MetadataDirective) != 0 {
 		headers["x-amz-metadata-directive"] = []string{o.MetadataDirective}
 	}
 	if len(o.ContentType) != 0 {
 		headers["Content-Type"] = []string{o.ContentType}
 	}
+	if len(o.Tags) != 0 {
+		headers["x-amz-tagging-directive"] = []string{"REPLACE"}
+	}
+	o.addConditionHeaders(headers)
+}
+
+// addConditionHeaders adds the headers of the conditions on the source
+// object of a copy.
+func (o CopyOptions) addConditionHeaders(headers map[string][]string) {
+	if o.CopySourceIfMatch != "" {
+		headers["x-amz-copy-source-if-match"] = []string{o.CopySourceIfMatch}
+	}
+	if o.CopySourceIfNoneMatch != "" {
+		headers["x-amz-copy-source-if-none-match"] = []string{o.CopySourceIfNoneMatch}
+	}
+	if !o.CopySourceIfModifiedSince.IsZero() {
+		headers["x-amz-copy-source-if-modified-since"] = []string{o.CopySourceIfModifiedSince.UTC().Format(http.TimeFormat)}
+	}
+	if !o.CopySourceIfUnmodifiedSince.IsZero() {
+		headers["x-amz-copy-source-if-unmodified-since"] = []string{o.CopySourceIfUnmodifiedSince.UTC().Format(http.TimeFormat)}
+	}
 }
 
 func makeXmlBuffer(doc []byte) *bytes.Buffer {
@@ -449,31 +786,6 @@ func makeXmlBuffer(doc []byte) *bytes.Buffer {
 	return buf
 }
 
-type RoutingRule struct {
-	ConditionKeyPrefixEquals     string `xml:"Condition>KeyPrefixEquals"`
-	RedirectReplaceKeyPrefixWith string `xml:"Redirect>ReplaceKeyPrefixWith,omitempty"`
-	RedirectReplaceKeyWith       string `xml:"Redirect>ReplaceKeyWith,omitempty"`
-}
-
-type WebsiteConfiguration struct {
-	XMLName             xml.Name       `xml:"http://s3.amazonaws.com/doc/2006-03-01/ WebsiteConfiguration"`
-	IndexDocumentSuffix string         `xml:"IndexDocument>Suffix"`
-	ErrorDocumentKey    string         `xml:"ErrorDocument>Key"`
-	RoutingRules        *[]RoutingRule `xml:"RoutingRules>RoutingRule,omitempty"`
-}
-
-func (b *Bucket) PutBucketWebsite(configuration WebsiteConfiguration) error {
-
-	doc, err := xml.Marshal(configuration)
-	if err != nil {
-		return err
-	}
-
-	buf := makeXmlBuffer(doc)
-
-	return b.PutBucketSubresource("website", buf, int64(buf.Len()))
-}
-
 func (b *Bucket) PutBucketSubresource(subresource string, r io.Reader, length int64) error {
 	headers := map[string][]string{
 		"Content-Length": {strconv.FormatInt(length, 10)},
@@ -490,6 +802,81 @@ func (b *Bucket) PutBucketSubresource(subresource string, r io.Reader, length in
 	return b.S3.query(req, nil)
 }
 
+// putSubresourceXML sets the subresource of the object at path in b, or
+// of b itself for "/", to the XML document of v.
+func (b *Bucket) putSubresourceXML(path, subresource string, v interface{}) error {
+	doc, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.sendSubresource("PUT", path, subresource, "text/xml", makeXmlBuffer(doc).Bytes())
+}
+
+// sendSubresource sends data to the subresource of the object at path in
+// b, or of b itself for "/", with method and the Content-MD5 of data as S3
+// requires for configurations.
+func (b *Bucket) sendSubresource(method, path, subresource, contType string, data []byte) error {
+	sum := md5.Sum(data)
+	headers := map[string][]string{
+		"Content-Length": {strconv.Itoa(len(data))},
+		"Content-MD5":    {base64.StdEncoding.EncodeToString(sum[:])},
+		"Content-Type":   {contType},
+	}
+	var err error
+	for attempt := b.S3.AttemptStrategy.Start(); attempt.Next(); {
+		req := &request{
+			method:  method,
+			bucket:  b.Name,
+			path:    path,
+			params:  url.Values{subresource: {""}},
+			headers: headers,
+			payload: bytes.NewReader(data),
+		}
+		err = b.S3.query(req, nil)
+		if !shouldRetry(err) {
+			break
+		}
+	}
+	return err
+}
+
+// getSubresource unmarshals the XML document of the subresource of the
+// object at path in b, or of b itself for "/", into resp.
+func (b *Bucket) getSubresource(path, subresource string, resp interface{}) error {
+	var err error
+	for attempt := b.S3.AttemptStrategy.Start(); attempt.Next(); {
+		req := &request{
+			bucket: b.Name,
+			path:   path,
+			params: url.Values{subresource: {""}},
+		}
+		err = b.S3.query(req, resp)
+		if !shouldRetry(err) {
+			break
+		}
+	}
+	return err
+}
+
+// delSubresource removes the subresource of the object at path in b, or
+// of b itself for "/".
+func (b *Bucket) delSubresource(path, subresource string) error {
+	var err error
+	for attempt := b.S3.AttemptStrategy.Start(); attempt.Next(); {
+		req := &request{
+			method: "DELETE",
+			bucket: b.Name,
+			path:   path,
+			params: url.Values{subresource: {""}},
+		}
+		err = b.S3.query(req, nil)
+		if !shouldRetry(err) {
+			break
+		}
+	}
+	return err
+}
+
 // Del removes an object from the S3 bucket.
 //
 // See http://goo.gl/APeTt for details.
@@ -502,6 +889,45 @@ func (b *Bucket) Del(path string) error {
 	return b.S3.query(req, nil)
 }
 
+// DelVersion removes the given version of an object from a versioned S3
+// bucket. Without versionId, a delete marker is created as the current
+// version of the object instead. The result tells whether a delete marker
+// was created or removed, and its version.
+func (b *Bucket) DelVersion(path, versionId string) (*Deleted, error) {
+	req := &request{
+		method: "DELETE",
+		bucket: b.Name,
+		path:   path,
+		params: versionParams(versionId),
+	}
+	err := b.S3.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.S3.run(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	deleted := &Deleted{Key: path, VersionId: versionId}
+	if resp.Header.Get("x-amz-delete-marker") == "true" {
+		deleted.DeleteMarker = true
+		deleted.DeleteMarkerVersionId = resp.Header.Get("x-amz-version-id")
+	} else if versionId == "" {
+		deleted.VersionId = resp.Header.Get("x-amz-version-id")
+	}
+	return deleted, nil
+}
+
+// versionParams returns the query parameters selecting versionId of an
+// object, or nil for its current version.
+func versionParams(versionId string) url.Values {
+	if versionId == "" {
+		return nil
+	}
+	return url.Values{"versionId": {versionId}}
+}
+
 type Delete struct {
 	Quiet   bool     `xml:"Quiet,omitempty"`
 	Objects []Object `xml:"Object"`
@@ -512,37 +938,135 @@ type Object struct {
 	VersionId string `xml:"VersionId,omitempty"`
 }
 
-// DelMulti removes up to 1000 objects from the S3 bucket.
+// The DeleteResult type holds the results of a DelMulti operation. In
+// quiet mode, only the objects which couldn't be deleted are reported.
+type DeleteResult struct {
+	Deleted []Deleted     `xml:"Deleted"`
+	Errors  []DeleteError `xml:"Error"`
+}
+
+// The Deleted type represents an object deleted by DelMulti.
+type Deleted struct {
+	Key                   string
+	VersionId             string
+	DeleteMarker          bool
+	DeleteMarkerVersionId string
+}
+
+// The DeleteError type represents an object DelMulti failed to delete.
+type DeleteError struct {
+	Key       string
+	VersionId string
+	Code      string
+	Message   string
+}
+
+func (e *DeleteError) Error() string {
+	return fmt.Sprintf("cannot delete %s: %s (%s)", e.Key, e.Message, e.Code)
+}
+
+// DeleteErrors is returned by DelPrefix when some objects couldn't be
+// deleted.
+type DeleteErrors []DeleteError
+
+func (e DeleteErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s, and %d more objects", e[0].Error(), len(e)-1)
+}
+
+// That's the default. Here just for testing.
+var delMultiMax = 1000
+
+// DelMulti removes objects from the S3 bucket, sending 1000 objects per
+// request. With quiet, only the objects which couldn't be deleted are
+// reported in the result. Objects of versioned buckets may be given a
+// VersionId to delete a specific version.
 //
 // See http://goo.gl/jx6cWK for details.
-func (b *Bucket) DelMulti(objects Delete) error {
-	doc, err := xml.Marshal(objects)
-	if err != nil {
-		return err
+func (b *Bucket) DelMulti(objects []Object, quiet bool) (*DeleteResult, error) {
+	result := &DeleteResult{}
+	for len(objects) > 0 {
+		n := len(objects)
+		if n > delMultiMax {
+			n = delMultiMax
+		}
+		var resp DeleteResult
+		err := b.delMulti(Delete{Quiet: quiet, Objects: objects[:n]}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		result.Deleted = append(result.Deleted, resp.Deleted...)
+		result.Errors = append(result.Errors, resp.Errors...)
+		objects = objects[n:]
 	}
+	return result, nil
+}
 
-	buf := makeXmlBuffer(doc)
-	digest := md5.New()
-	size, err := digest.Write(buf.Bytes())
+func (b *Bucket) delMulti(objects Delete, resp *DeleteResult) error {
+	doc, err := xml.Marshal(objects)
 	if err != nil {
 		return err
 	}
+	data := makeXmlBuffer(doc).Bytes()
+	sum := md5.Sum(data)
 
 	headers := map[string][]string{
-		"Content-Length": {strconv.FormatInt(int64(size), 10)},
-		"Content-MD5":    {base64.StdEncoding.EncodeToString(digest.Sum(nil))},
+		"Content-Length": {strconv.Itoa(len(data))},
+		"Content-MD5":    {base64.StdEncoding.EncodeToString(sum[:])},
 		"Content-Type":   {"text/xml"},
 	}
-	req := &request{
-		path:    "/",
-		method:  "POST",
-		params:  url.Values{"delete": {""}},
-		bucket:  b.Name,
-		headers: headers,
-		payload: buf,
+	for attempt := b.S3.AttemptStrategy.Start(); attempt.Next(); {
+		req := &request{
+			path:    "/",
+			method:  "POST",
+			params:  url.Values{"delete": {""}},
+			bucket:  b.Name,
+			headers: headers,
+			payload: bytes.NewReader(data),
+		}
+		err = b.S3.query(req, resp)
+		if !shouldRetry(err) {
+			break
+		}
 	}
+	return err
+}
 
-	return b.S3.query(req, nil)
+// DelPrefix removes all the objects of the S3 bucket whose key starts
+// with prefix, listing and deleting them 1000 at a time. It goes on when
+// some objects can't be deleted, and then returns DeleteErrors. Past
+// versions of objects in versioned buckets are left alone.
+func (b *Bucket) DelPrefix(prefix string) error {
+	var errs DeleteErrors
+	marker := ""
+	for {
+		list, err := b.List(prefix, "", marker, delMultiMax)
+		if err != nil {
+			return err
+		}
+		if len(list.Contents) == 0 {
+			break
+		}
+		objects := make([]Object, len(list.Contents))
+		for i, key := range list.Contents {
+			objects[i] = Object{Key: key.Key}
+		}
+		result, err := b.DelMulti(objects, true)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, result.Errors...)
+		if !list.IsTruncated {
+			break
+		}
+		marker = objects[len(objects)-1].Key
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
 
 // The ListResp type holds the results of a List bucket operation.
@@ -561,6 +1085,10 @@ type ListResp struct {
 	IsTruncated    bool
 	Contents       []Key
 	CommonPrefixes []string `xml:">Prefix"`
+
+	// EncodingType is "url" if the keys were requested URL encoded. They
+	// are decoded by then.
+	EncodingType string
 }
 
 // The Key type represents an item stored in an S3 bucket.
@@ -632,6 +1160,11 @@ type Key struct {
 //
 // See http://goo.gl/YjQTc for details.
 func (b *Bucket) List(prefix, delim, marker string, max int) (result *ListResp, err error) {
+	return b.list(prefix, delim, marker, max, "")
+}
+
+// list is List requesting the keys with the given encoding type, if any.
+func (b *Bucket) list(prefix, delim, marker string, max int, encoding string) (result *ListResp, err error) {
 	params := map[string][]string{
 		"prefix":    {prefix},
 		"delimiter": {delim},
@@ -640,6 +1173,9 @@ func (b *Bucket) List(prefix, delim, marker string, max int) (result *ListResp,
 	if max != 0 {
 		params["max-keys"] = []string{strconv.FormatInt(int64(max), 10)}
 	}
+	if encoding != "" {
+		params["encoding-type"] = []string{encoding}
+	}
 	req := &request{
 		bucket: b.Name,
 		params: params,
@@ -654,20 +1190,38 @@ func (b *Bucket) List(prefix, delim, marker string, max int) (result *ListResp,
 	if err != nil {
 		return nil, err
 	}
+	err = decodeKeys(result.EncodingType, result.Contents, result.CommonPrefixes,
+		&result.Prefix, &result.Delimiter, &result.Marker, &result.NextMarker)
+	if err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
 // The VersionsResp type holds the results of a list bucket Versions operation.
 type VersionsResp struct {
-	Name            string
-	Prefix          string
-	KeyMarker       string
-	VersionIdMarker string
-	MaxKeys         int
-	Delimiter       string
-	IsTruncated     bool
-	Versions        []Version
-	CommonPrefixes  []string `xml:">Prefix"`
+	Name                string
+	Prefix              string
+	KeyMarker           string
+	VersionIdMarker     string
+	NextKeyMarker       string
+	NextVersionIdMarker string
+	MaxKeys             int
+	Delimiter           string
+	IsTruncated         bool
+	Versions            []Version      `xml:"Version"`
+	DeleteMarkers       []DeleteMarker `xml:"DeleteMarker"`
+	CommonPrefixes      []string       `xml:">Prefix"`
+}
+
+// The DeleteMarker type represents a delete marker stored in a versioned
+// S3 bucket, taking the place of a deleted object.
+type DeleteMarker struct {
+	Key          string
+	VersionId    string
+	IsLatest     bool
+	LastModified string
+	Owner        Owner
 }
 
 // The Version type represents an object version stored in an S3 bucket.
@@ -685,6 +1239,20 @@ type Version struct {
 }
 
 func (b *Bucket) Versions(prefix, delim, keyMarker string, versionIdMarker string, max int) (result *VersionsResp, err error) {
+	return b.ListVersions(prefix, delim, keyMarker, versionIdMarker, max)
+}
+
+// ListVersions returns information about the versions and delete markers
+// of the objects in a versioned S3 bucket, ordered by key and from the
+// most recent version.
+//
+// The prefix, delim and max parameters are used as with List. Versions
+// are listed after the keyMarker key, or after the versionIdMarker version
+// of that key. When the result is truncated, the following versions are
+// listed with the NextKeyMarker and NextVersionIdMarker of the result.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketGETVersion.html for details.
+func (b *Bucket) ListVersions(prefix, delim, keyMarker string, versionIdMarker string, max int) (result *VersionsResp, err error) {
 	params := map[string][]string{
 		"versions":  {""},
 		"prefix":    {prefix},
@@ -718,6 +1286,40 @@ func (b *Bucket) Versions(prefix, delim, keyMarker string, versionIdMarker strin
 	return result, nil
 }
 
+// Status of the versioning of buckets.
+const (
+	VersioningEnabled   = "Enabled"
+	VersioningSuspended = "Suspended"
+)
+
+// The VersioningConfiguration type holds the versioning state of a bucket.
+// Status is empty for buckets which were never versioned.
+type VersioningConfiguration struct {
+	XMLName   xml.Name `xml:"VersioningConfiguration"`
+	Status    string   `xml:"Status,omitempty"`
+	MfaDelete string   `xml:"MfaDelete,omitempty"`
+}
+
+// PutVersioning enables or suspends the versioning of b, with status
+// VersioningEnabled or VersioningSuspended.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketPUTVersioningStatus.html for details.
+func (b *Bucket) PutVersioning(status string) error {
+	return b.putSubresourceXML("/", "versioning", &VersioningConfiguration{Status: status})
+}
+
+// GetVersioning returns the versioning state of b.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketGETversioningStatus.html for details.
+func (b *Bucket) GetVersioning() (*VersioningConfiguration, error) {
+	config := &VersioningConfiguration{}
+	err := b.getSubresource("/", "versioning", config)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
 // Returns a mapping of all key names in this bucket to Key objects
 func (b *Bucket) GetBucketContents() (*map[string]Key, error) {
 	bucket_contents := map[string]Key{}
@@ -764,38 +1366,125 @@ func (b *Bucket) URL(path string) string {
 
 // SignedURL returns a signed URL that allows anyone holding the URL
 // to retrieve the object at path. The signature is valid until expires.
+// In regions requiring Signature Version 4, the URL is valid for at most
+// seven days, see SignedURLWithMethod.
 func (b *Bucket) SignedURL(path string, expires time.Time) string {
+	return b.SignedURLWithMethod("GET", path, expires, nil, nil)
+}
+
+// SignedURLWithMethod returns a signed URL that allows anyone holding the
+// URL to make a request with method to the object at path, such as a PUT
+// uploading it. The signature is valid until expires.
+//
+// In regions requiring Signature Version 4, the URL is valid for at most
+// seven days, and at least a second, whatever expires is. An empty URL is
+// returned if the credentials can't be read. SignedURLV4 returns these
+// errors instead.
+//
+// params are added to the URL, such as the response-content-type and
+// response-content-disposition parameters overriding the headers of a GET
+// response. The Content-Type, Content-MD5 and x-amz-* headers are signed,
+// and requests using the URL must send the same values. The session token
+// of temporary credentials is included in the URL.
+func (b *Bucket) SignedURLWithMethod(method, path string, expires time.Time, params url.Values, headers http.Header) string {
+	if b.S3.Region.S3SignatureV4 {
+		// Signature Version 4 URLs are valid for one second to seven
+		// days, the expiry is brought within these bounds.
+		d := expires.Sub(time.Now())
+		if d < time.Second {
+			d = time.Second
+		} else if d > aws.MaxPresignExpires {
+			d = aws.MaxPresignExpires
+		}
+		signed, err := b.signedURLV4(method, path, d, params, headers)
+		if err != nil {
+			return ""
+		}
+		return signed
+	}
+	query := make(url.Values)
+	for k, v := range params {
+		query[k] = v
+	}
+	query.Set("Expires", strconv.FormatInt(expires.Unix(), 10))
 	req := &request{
-		bucket: b.Name,
-		path:   path,
-		params: url.Values{"Expires": {strconv.FormatInt(expires.Unix(), 10)}},
+		method:  method,
+		bucket:  b.Name,
+		path:    path,
+		params:  query,
+		headers: headers,
 	}
 	err := b.S3.prepare(req)
 	if err != nil {
-		panic(err)
+		return ""
 	}
 	u, err := req.url()
 	if err != nil {
-		panic(err)
+		return ""
 	}
-	if b.S3.Auth.Token() != "" {
-		return u.String() + "&x-amz-security-token=" + url.QueryEscape(req.headers["X-Amz-Security-Token"][0])
+	if token := req.headers.Get("X-Amz-Security-Token"); token != "" {
+		return u.String() + "&x-amz-security-token=" + url.QueryEscape(token)
 	} else {
 		return u.String()
 	}
 }
 
+// SignedURLV4 returns a URL for the object at path presigned with Signature
+// Version 4, as SignedURLWithMethod. It fails if expires is less than a
+// second or more than aws.MaxPresignExpires away, or if the credentials
+// can't be read.
+func (b *Bucket) SignedURLV4(method, path string, expires time.Time, params url.Values, headers http.Header) (string, error) {
+	return b.signedURLV4(method, path, expires.Sub(time.Now()), params, headers)
+}
+
+func (b *Bucket) signedURLV4(method, path string, expires time.Duration, params url.Values, headers http.Header) (string, error) {
+	req := &request{
+		method: method,
+		bucket: b.Name,
+		path:   path,
+		params: params,
+	}
+	// prepare resolves the URL of the object, the signature it adds to
+	// the headers is replaced by the presigned one.
+	err := b.S3.prepare(req)
+	if err != nil {
+		return "", err
+	}
+	u, err := req.url()
+	if err != nil {
+		return "", err
+	}
+	auth, err := aws.CurrentAuth(b.S3.Auth, b.S3.Credentials)
+	if err != nil {
+		return "", err
+	}
+	hreq := &http.Request{
+		Method: method,
+		URL:    u,
+		Host:   u.Host,
+		Header: make(http.Header),
+	}
+	for k, v := range headers {
+		hreq.Header[k] = v
+	}
+	return aws.PresignV4(hreq, auth, b.S3.Region.Name, "s3", expires)
+}
+
 // UploadSignedURL returns a signed URL that allows anyone holding the URL
 // to upload the object at path. The signature is valid until expires.
 // contenttype is a string like image/png
 // path is the resource name in s3 terminalogy like images/ali.png [obviously exclusing the bucket name itself]
+// An empty URL is returned if the credentials can't be read.
 func (b *Bucket) UploadSignedURL(path, method, content_type string, expires time.Time) string {
 	expire_date := expires.Unix()
 	if method != "POST" {
 		method = "PUT"
 	}
 	stringToSign := method + "\n\n" + content_type + "\n" + strconv.FormatInt(expire_date, 10) + "\n/" + b.Name + "/" + path
-	a := b.S3.Auth
+	a, err := aws.CurrentAuth(b.S3.Auth, b.S3.Credentials)
+	if err != nil {
+		return ""
+	}
 	secretKey := a.SecretKey
 	accessId := a.AccessKey
 	mac := hmac.New(sha1.New, []byte(secretKey))
@@ -823,11 +1512,16 @@ func (b *Bucket) UploadSignedURL(path, method, content_type string, expires time
 }
 
 // PostFormArgs returns the action and input fields needed to allow anonymous
-// uploads to a bucket within the expiration limit
+// uploads to a bucket within the expiration limit.
+// No fields are returned if the credentials can't be read.
 func (b *Bucket) PostFormArgs(path string, expires time.Time, redirect string) (action string, fields map[string]string) {
+	auth, err := aws.CurrentAuth(b.S3.Auth, b.S3.Credentials)
+	if err != nil {
+		return
+	}
 	conditions := make([]string, 0)
 	fields = map[string]string{
-		"AWSAccessKeyId": b.Auth.AccessKey,
+		"AWSAccessKeyId": auth.AccessKey,
 		"key":            path,
 	}
 
@@ -844,7 +1538,7 @@ func (b *Bucket) PostFormArgs(path string, expires time.Time, redirect string) (
 	policy64 := base64.StdEncoding.EncodeToString([]byte(policy))
 	fields["policy"] = policy64
 
-	signer := hmac.New(sha1.New, []byte(b.Auth.SecretKey))
+	signer := hmac.New(sha1.New, []byte(auth.SecretKey))
 	signer.Write([]byte(policy64))
 	fields["signature"] = base64.StdEncoding.EncodeToString(signer.Sum(nil))
 
@@ -862,6 +1556,15 @@ type request struct {
 	baseurl  string
 	payload  io.Reader
 	prepared bool
+
+	// pathStyle is true if the bucket is addressed in path rather than
+	// in the host name of baseurl.
+	pathStyle bool
+
+	// region is where the bucket was found to be, if not in the region
+	// of the S3.
+	region     *aws.Region
+	redirected bool
 }
 
 func (req *request) url() (*url.URL, error) {
@@ -891,9 +1594,11 @@ func (s3 *S3) query(req *request, resp interface{}) error {
 
 // prepare sets up req to be delivered to S3.
 func (s3 *S3) prepare(req *request) error {
-	var signpath = req.path
-
 	if !req.prepared {
+		if r, ok := s3.regions.Load(req.bucket); ok && req.region == nil {
+			region := r.(aws.Region)
+			req.region = &region
+		}
 		req.prepared = true
 		if req.method == "" {
 			req.method = "GET"
@@ -912,21 +1617,28 @@ func (s3 *S3) prepare(req *request) error {
 		if !strings.HasPrefix(req.path, "/") {
 			req.path = "/" + req.path
 		}
-		signpath = req.path
+		// The bucket is part of the signed resource whichever way it
+		// is addressed.
+		req.signpath = req.path
 		if req.bucket != "" {
-			req.baseurl = s3.Region.S3BucketEndpoint
-			if req.baseurl == "" {
-				// Use the path method to address the bucket.
-				req.baseurl = s3.Region.S3Endpoint
-				req.path = "/" + req.bucket + req.path
-			} else {
+			region := req.awsRegion(s3)
+			if s3.virtualHost(region, req.bucket) {
 				// Just in case, prevent injection.
 				if strings.IndexAny(req.bucket, "/:@") >= 0 {
 					return fmt.Errorf("bad S3 bucket: %q", req.bucket)
 				}
-				req.baseurl = strings.Replace(req.baseurl, "${bucket}", req.bucket, -1)
+				baseurl, err := bucketEndpoint(region, req.bucket)
+				if err != nil {
+					return err
+				}
+				req.baseurl = baseurl
+			} else {
+				// Use the path method to address the bucket.
+				req.baseurl = region.S3Endpoint
+				req.path = "/" + req.bucket + req.path
+				req.pathStyle = true
 			}
-			signpath = "/" + req.bucket + signpath
+			req.signpath = "/" + req.bucket + req.signpath
 		}
 	}
 
@@ -936,16 +1648,146 @@ func (s3 *S3) prepare(req *request) error {
 	if err != nil {
 		return fmt.Errorf("bad S3 endpoint URL %q: %v", req.baseurl, err)
 	}
-	reqSignpathSpaceFix := (&url.URL{Path: signpath}).String()
+	auth, err := aws.CurrentAuth(s3.Auth, s3.Credentials)
+	if err != nil {
+		return err
+	}
+	if req.awsRegion(s3).S3SignatureV4 {
+		return s3.prepareV4(req, auth, u.Host)
+	}
+	reqSignpathSpaceFix := (&url.URL{Path: req.signpath}).String()
 	req.headers["Host"] = []string{u.Host}
-	req.headers["Date"] = []string{time.Now().In(time.UTC).Format(time.RFC1123)}
-	if s3.Auth.Token() != "" {
-		req.headers["X-Amz-Security-Token"] = []string{s3.Auth.Token()}
+	req.headers["Date"] = []string{s3.clock.Now().In(time.UTC).Format(time.RFC1123)}
+	delete(req.headers, "X-Amz-Security-Token")
+	if auth.Token() != "" {
+		req.headers["X-Amz-Security-Token"] = []string{auth.Token()}
 	}
-	sign(s3.Auth, req.method, reqSignpathSpaceFix, req.params, req.headers)
+	sign(auth, req.method, reqSignpathSpaceFix, req.params, req.headers)
 	return nil
 }
 
+// prepareV4 signs req with Signature Version 4, for regions that do not
+// accept the legacy S3 signature. Payloads are left unsigned so they don't
+// have to be read twice.
+func (s3 *S3) prepareV4(req *request, auth aws.Auth, host string) error {
+	u, err := req.url()
+	if err != nil {
+		return err
+	}
+	delete(req.headers, "Authorization")
+	delete(req.headers, "X-Amz-Content-Sha256")
+	delete(req.headers, "X-Amz-Security-Token")
+	req.headers.Set("X-Amz-Date", s3.clock.Now().UTC().Format(aws.ISO8601BasicFormat))
+	if req.payload != nil {
+		req.headers.Set("X-Amz-Content-Sha256", aws.UnsignedPayload)
+	}
+	hreq := &http.Request{
+		Method: req.method,
+		URL:    u,
+		Host:   host,
+		Header: req.headers,
+	}
+	aws.SignV4(hreq, auth, req.awsRegion(s3).Name, "s3")
+	return nil
+}
+
+// virtualHost reports whether bucket is addressed in the host name of the
+// requests to region, rather than in their path.
+func (s3 *S3) virtualHost(region aws.Region, bucket string) bool {
+	switch s3.Addressing {
+	case PathStyle:
+		return false
+	case VirtualHostedStyle:
+		return true
+	}
+	return region.S3BucketEndpoint != "" && (dnsCompatible(bucket) || region.S3Endpoint == "")
+}
+
+// dnsCompatible reports whether bucket can be a label of a host name
+// matched by the wildcard certificates of S3, without uppercase letters
+// or dots. Other buckets are addressed in the path by AutoAddressing if
+// the Region has an S3Endpoint.
+func dnsCompatible(bucket string) bool {
+	if len(bucket) < 3 || len(bucket) > 63 || bucket[0] == '-' || bucket[len(bucket)-1] == '-' {
+		return false
+	}
+	for _, c := range bucket {
+		if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// bucketEndpoint returns the endpoint of bucket in region, where it is
+// addressed in the host name. Regions without an S3BucketEndpoint have
+// the bucket prepended to the host of their S3Endpoint.
+func bucketEndpoint(region aws.Region, bucket string) (string, error) {
+	if region.S3BucketEndpoint != "" {
+		return strings.Replace(region.S3BucketEndpoint, "${bucket}", bucket, -1), nil
+	}
+	u, err := url.Parse(region.S3Endpoint)
+	if err != nil {
+		return "", fmt.Errorf("bad S3 endpoint URL %q: %v", region.S3Endpoint, err)
+	}
+	u.Host = bucket + "." + u.Host
+	return u.String(), nil
+}
+
+// awsRegion returns the region req is sent to.
+func (req *request) awsRegion(s3 *S3) aws.Region {
+	if req.region != nil {
+		return *req.region
+	}
+	return s3.Region
+}
+
+// bucketRegion returns the Region named name, as reported by S3 for
+// buckets outside the region of the S3. Regions unknown to aws.Regions
+// are reached at their standard endpoint.
+func bucketRegion(name string) aws.Region {
+	if r, ok := aws.Regions[name]; ok {
+		return r
+	}
+	return aws.Region{
+		Name:                 name,
+		S3Endpoint:           "https://s3." + name + ".amazonaws.com",
+		S3LocationConstraint: true,
+		S3LowercaseBucket:    true,
+		S3SignatureV4:        true,
+	}
+}
+
+// redirect reports whether hresp tells that the bucket of req is in
+// another region, in which case req is prepared again to be sent there
+// and the region is remembered for the bucket. Requests are redirected
+// once at most.
+func (s3 *S3) redirect(req *request, hresp *http.Response) (bool, error) {
+	if req.bucket == "" || req.redirected {
+		return false, nil
+	}
+	if hresp.StatusCode != 301 && hresp.StatusCode != 400 {
+		return false, nil
+	}
+	name := hresp.Header.Get("x-amz-bucket-region")
+	if name == "" || name == req.awsRegion(s3).Name {
+		return false, nil
+	}
+	if req.pathStyle {
+		req.path = strings.TrimPrefix(req.path, "/"+req.bucket)
+		req.pathStyle = false
+	}
+	region := bucketRegion(name)
+	s3.regions.Store(req.bucket, region)
+	req.region = &region
+	req.redirected = true
+	req.prepared = false
+	if debug {
+		log.Printf("bucket %q is in region %q", req.bucket, name)
+	}
+	return true, s3.prepare(req)
+}
+
 // run sends req and returns the http response from the server.
 // If resp is not nil, the XML data contained in the response
 // body will be unmarshalled on it.
@@ -954,31 +1796,81 @@ func (s3 *S3) run(req *request, resp interface{}) (*http.Response, error) {
 		log.Printf("Running S3 request: %#v", req)
 	}
 
-	u, err := req.url()
-	if err != nil {
-		return nil, err
-	}
-
-	hreq := http.Request{
-		URL:        u,
-		Method:     req.method,
-		ProtoMajor: 1,
-		ProtoMinor: 1,
-		Close:      true,
-		Header:     req.headers,
+	// The payload is rewound to start if the request has to be signed
+	// again because of a skewed clock.
+	var seeker io.Seeker
+	var start int64
+	if req.payload != nil {
+		if sk, ok := req.payload.(io.Seeker); ok {
+			if pos, err := sk.Seek(0, io.SeekCurrent); err == nil {
+				seeker, start = sk, pos
+			}
+		}
 	}
 
+	var contentLength int64
 	if v, ok := req.headers["Content-Length"]; ok {
-		hreq.ContentLength, _ = strconv.ParseInt(v[0], 10, 64)
+		contentLength, _ = strconv.ParseInt(v[0], 10, 64)
 		delete(req.headers, "Content-Length")
 	}
-	if req.payload != nil {
-		hreq.Body = ioutil.NopCloser(req.payload)
+
+	// The caller prepared req for the first attempt
+	resign := false
+	build := func(time.Time) (*http.Request, error) {
+		if resign {
+			if req.payload != nil {
+				if seeker == nil {
+					return nil, aws.ErrBodyNotReplayable
+				}
+				if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+					return nil, err
+				}
+			}
+			if err := s3.prepare(req); err != nil {
+				return nil, err
+			}
+		}
+		resign = true
+
+		u, err := req.url()
+		if err != nil {
+			return nil, err
+		}
+
+		hreq := &http.Request{
+			URL:           u,
+			Method:        req.method,
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Close:         true,
+			Header:        req.headers,
+			ContentLength: contentLength,
+		}
+		if req.method == "PUT" && contentLength > 0 {
+			// S3 may reject the request before the body is sent. The
+			// header isn't signed, so it is added to a copy.
+			hreq.Header = make(http.Header, len(req.headers)+1)
+			for k, v := range req.headers {
+				hreq.Header[k] = v
+			}
+			hreq.Header.Set("Expect", "100-continue")
+		}
+		if req.payload != nil {
+			hreq.Body = ioutil.NopCloser(req.payload)
+			if seeker != nil {
+				hreq.GetBody = func() (io.ReadCloser, error) {
+					_, err := seeker.Seek(start, io.SeekStart)
+					return ioutil.NopCloser(req.payload), err
+				}
+			}
+		}
+		return hreq, nil
 	}
 
-	if s3.client == nil {
+	if s3.client == nil && s3.HTTPClient == nil {
 		s3.client = &http.Client{
 			Transport: &http.Transport{
+				ExpectContinueTimeout: expectContinueTimeout,
 				Dial: func(netw, addr string) (c net.Conn, err error) {
 					c, err = net.DialTimeout(netw, addr, s3.ConnectTimeout)
 					if err != nil {
@@ -1005,15 +1897,45 @@ func (s3 *S3) run(req *request, resp interface{}) (*http.Response, error) {
 		}
 	}
 
-	hresp, err := s3.client.Do(&hreq)
+	policy := s3.RetryPolicy
+	if policy == nil {
+		policy = &aws.DefaultRetryPolicy
+		if req.method == "POST" {
+			policy = &aws.NoRetry
+		}
+	}
+	client := s3.client
+	if s3.HTTPClient != nil {
+		client = s3.HTTPClient
+	}
+	hresp, err := s3.clock.Do(policy, client, build)
 	if err != nil {
 		return nil, err
 	}
+	// Bodies which can't be sent again are left to fail.
+	if req.payload == nil || seeker != nil {
+		ok, err := s3.redirect(req, hresp)
+		if ok || err != nil {
+			hresp.Body.Close()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if seeker != nil {
+				if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+					return nil, err
+				}
+				req.headers["Content-Length"] = []string{strconv.FormatInt(contentLength, 10)}
+			}
+			return s3.run(req, resp)
+		}
+	}
 	if debug {
 		dump, _ := httputil.DumpResponse(hresp, true)
 		log.Printf("} -> %s\n", dump)
 	}
-	if hresp.StatusCode != 200 && hresp.StatusCode != 204 && hresp.StatusCode != 206 {
+	if hresp.StatusCode != 200 && hresp.StatusCode != 202 && hresp.StatusCode != 204 && hresp.StatusCode != 206 {
 		defer hresp.Body.Close()
 		return nil, buildError(hresp)
 	}
@@ -1028,18 +1950,7 @@ func (s3 *S3) run(req *request, resp interface{}) (*http.Response, error) {
 }
 
 // Error represents an error in an operation with S3.
-type Error struct {
-	StatusCode int    // HTTP status code (200, 403, ...)
-	Code       string // EC2 error code ("UnsupportedOperation", ...)
-	Message    string // The human-oriented error message
-	BucketName string
-	RequestId  string
-	HostId     string
-}
-
-func (e *Error) Error() string {
-	return e.Message
-}
+type Error = aws.Error
 
 func buildError(r *http.Response) error {
 	if debug {
@@ -1053,18 +1964,38 @@ func buildError(r *http.Response) error {
 		r.Body = ioutil.NopCloser(bytes.NewBuffer(data))
 	}
 
-	err := Error{}
-	// TODO return error if Unmarshal fails?
-	xml.NewDecoder(r.Body).Decode(&err)
-	r.Body.Close()
-	err.StatusCode = r.StatusCode
-	if err.Message == "" {
-		err.Message = r.Status
-	}
+	err := aws.BuildError(r)
 	if debug {
 		log.Printf("err: %#v\n", err)
 	}
-	return &err
+	return err
+}
+
+// readResult unmarshals the body of resp into result, unless it is an
+// error. S3 sends the status of some requests taking long, such as copies,
+// before their outcome, and reports errors in the body of 200 responses.
+// Internal errors reported this way are retryable.
+func readResult(resp *http.Response, result interface{}) error {
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var root struct {
+		XMLName xml.Name
+	}
+	if xml.Unmarshal(data, &root) != nil {
+		return nil
+	}
+	if root.XMLName.Local != "Error" {
+		return xml.Unmarshal(data, result)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	err = buildError(resp)
+	if e, ok := err.(*Error); ok && e.Code == "InternalError" {
+		e.Retryable = true
+	}
+	return err
 }
 
 func shouldRetry(err error) bool {
@@ -1094,7 +2025,7 @@ func shouldRetry(err error) bool {
 		}
 	case *Error:
 		switch e.Code {
-		case "InternalError", "NoSuchUpload", "NoSuchBucket":
+		case "InternalError", "NoSuchUpload", "NoSuchBucket", "SlowDown":
 			return true
 		}
 	}
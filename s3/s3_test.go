@@ -2,8 +2,17 @@ package s3_test
 
 import (
 	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -170,7 +179,7 @@ func (s *S) TestGetNotFound(c *C) {
 	c.Assert(s3err.HostId, Equals, "L4ee/zrm1irFXY5F45fKXIRdOf9ktsKY/8TDVawuMK2jWRb1RF84i1uBzkdNqS5D")
 	c.Assert(s3err.Code, Equals, "NoSuchBucket")
 	c.Assert(s3err.Message, Equals, "The specified bucket does not exist")
-	c.Assert(s3err.Error(), Equals, "The specified bucket does not exist")
+	c.Assert(s3err.Error(), Equals, "The specified bucket does not exist (NoSuchBucket)")
 	c.Assert(data, IsNil)
 }
 
@@ -193,6 +202,257 @@ func (s *S) TestPutObject(c *C) {
 	c.Assert(req.Header["X-Amz-Acl"], DeepEquals, []string{"private"})
 }
 
+func (s *S) TestPutObjectSignatureV4(c *C) {
+	testServer.Response(200, nil, "")
+
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	s3v4 := s3.New(auth, aws.Region{Name: "faux-region-1", S3Endpoint: testServer.URL, S3SignatureV4: true})
+	b := s3v4.Bucket("bucket")
+	err := b.Put("name", []byte("content"), "content-type", s3.Private, s3.Options{})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.Path, Equals, "/bucket/name")
+	c.Assert(req.Header["Date"], IsNil)
+	c.Assert(req.Header["X-Amz-Date"], HasLen, 1)
+	c.Assert(req.Header["X-Amz-Content-Sha256"], DeepEquals, []string{aws.UnsignedPayload})
+	c.Assert(req.Header.Get("Authorization"), Matches, "AWS4-HMAC-SHA256 Credential=abc/[0-9]{8}/faux-region-1/s3/aws4_request, SignedHeaders=content-length;content-type;host;x-amz-acl;x-amz-content-sha256;x-amz-date, Signature=[0-9a-f]{64}")
+}
+
+func (s *S) TestSignedURLSignatureV4(c *C) {
+	auth := aws.NewAuth("abc", "123", "token", time.Now().Add(time.Hour))
+	s3v4 := s3.New(*auth, aws.Region{Name: "faux-region-1", S3Endpoint: "https://s3.faux-region-1.amazonaws.com", S3SignatureV4: true})
+	b := s3v4.Bucket("bucket")
+
+	u, err := url.Parse(b.SignedURL("dir/name", time.Now().Add(time.Hour)))
+	c.Assert(err, IsNil)
+	c.Assert(u.Host, Equals, "s3.faux-region-1.amazonaws.com")
+	c.Assert(u.Path, Equals, "/bucket/dir/name")
+	query := u.Query()
+	c.Assert(query.Get("X-Amz-Algorithm"), Equals, "AWS4-HMAC-SHA256")
+	c.Assert(query.Get("X-Amz-Credential"), Matches, "abc/[0-9]{8}/faux-region-1/s3/aws4_request")
+	c.Assert(query.Get("X-Amz-Expires"), Matches, "35[0-9]{2}")
+	c.Assert(query.Get("X-Amz-SignedHeaders"), Equals, "host")
+	c.Assert(query.Get("X-Amz-Security-Token"), Equals, "token")
+	c.Assert(query.Get("X-Amz-Signature"), Matches, "[0-9a-f]{64}")
+	c.Assert(query.Get("Signature"), Equals, "")
+}
+
+func (s *S) TestSignedURLWithMethodSignatureV4(c *C) {
+	auth := aws.NewAuth("abc", "123", "token", time.Now().Add(time.Hour))
+	s3v4 := s3.New(*auth, aws.Region{Name: "faux-region-1", S3Endpoint: "https://s3.faux-region-1.amazonaws.com", S3SignatureV4: true})
+	b := s3v4.Bucket("bucket")
+
+	params := url.Values{"response-content-disposition": {"attachment"}}
+	headers := http.Header{"Content-Type": {"text/plain"}, "X-Amz-Acl": {"public-read"}}
+	u, err := url.Parse(b.SignedURLWithMethod("PUT", "dir/name", time.Now().Add(time.Hour), params, headers))
+	c.Assert(err, IsNil)
+	c.Assert(u.Path, Equals, "/bucket/dir/name")
+	query := u.Query()
+	c.Assert(query.Get("response-content-disposition"), Equals, "attachment")
+	c.Assert(query.Get("X-Amz-SignedHeaders"), Equals, "content-type;host;x-amz-acl")
+	c.Assert(query.Get("X-Amz-Security-Token"), Equals, "token")
+	c.Assert(query.Get("X-Amz-Signature"), Matches, "[0-9a-f]{64}")
+	c.Assert(headers, HasLen, 2)
+}
+
+func (s *S) TestSignedURLSignatureV4Expires(c *C) {
+	auth := aws.NewAuth("abc", "123", "", time.Now().Add(time.Hour))
+	s3v4 := s3.New(*auth, aws.Region{Name: "faux-region-1", S3Endpoint: "https://s3.faux-region-1.amazonaws.com", S3SignatureV4: true})
+	b := s3v4.Bucket("bucket")
+
+	// Expiries out of bounds are brought within them
+	u, err := url.Parse(b.SignedURL("dir/name", time.Now().Add(8*24*time.Hour)))
+	c.Assert(err, IsNil)
+	c.Assert(u.Query().Get("X-Amz-Expires"), Equals, "604800")
+	u, err = url.Parse(b.SignedURL("dir/name", time.Now().Add(-time.Hour)))
+	c.Assert(err, IsNil)
+	c.Assert(u.Query().Get("X-Amz-Expires"), Equals, "1")
+
+	_, err = b.SignedURLV4("GET", "dir/name", time.Now().Add(8*24*time.Hour), nil, nil)
+	c.Assert(err, ErrorMatches, "presigned request expiration must be between 1s and .*")
+	signed, err := b.SignedURLV4("GET", "dir/name", time.Now().Add(time.Hour), nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(signed, Matches, "https://s3.faux-region-1.amazonaws.com/bucket/dir/name\\?.*X-Amz-Expires=35[0-9]{2}.*")
+
+	// Credentials that can't be read give no URL
+	s3v4.Credentials = failingCredentials{}
+	_, err = b.SignedURLV4("GET", "dir/name", time.Now().Add(time.Hour), nil, nil)
+	c.Assert(err, ErrorMatches, "no credentials")
+	c.Assert(b.SignedURL("dir/name", time.Now().Add(time.Hour)), Equals, "")
+	s3v4.Region.S3SignatureV4 = false
+	c.Assert(b.SignedURL("dir/name", time.Now().Add(time.Hour)), Equals, "")
+}
+
+type failingCredentials struct{}
+
+func (failingCredentials) Credentials() (aws.Auth, error) {
+	return aws.Auth{}, fmt.Errorf("no credentials")
+}
+
+// rotatingCredentials returns new keys each time they are requested.
+type rotatingCredentials struct {
+	n int
+}
+
+func (r *rotatingCredentials) Credentials() (aws.Auth, error) {
+	r.n++
+	return aws.Auth{AccessKey: fmt.Sprintf("access-%d", r.n), SecretKey: "secret"}, nil
+}
+
+func (s *S) TestCredentialsRotation(c *C) {
+	s.s3.Credentials = &rotatingCredentials{}
+	defer func() {
+		s.s3.Credentials = nil
+	}()
+
+	b := s.s3.Bucket("bucket")
+	for i := 1; i <= 2; i++ {
+		testServer.Response(200, nil, "")
+		err := b.Put("name", []byte("content"), "content-type", s3.Private, s3.Options{})
+		c.Assert(err, IsNil)
+
+		req := testServer.WaitRequest()
+		c.Assert(req.Header.Get("Authorization"), Matches, fmt.Sprintf("AWS access-%d:.*", i))
+	}
+}
+
+func (s *S) TestPutObjectRetryPolicy(c *C) {
+	s.DisableRetries()
+	s.s3.RetryPolicy = &aws.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+	defer func() {
+		s.s3.RetryPolicy = nil
+	}()
+
+	testServer.Response(503, nil, "")
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("bucket")
+	err := b.Put("name", []byte("content"), "content-type", s3.Private, s3.Options{})
+	c.Assert(err, IsNil)
+
+	for _, req := range testServer.WaitRequests(2) {
+		body, err := ioutil.ReadAll(req.Body)
+		c.Assert(err, IsNil)
+		c.Assert(string(body), Equals, "content")
+	}
+
+	// Streamed content can't be sent again
+	testServer.Response(503, nil, "")
+	err = b.PutReader("name", ioutil.NopCloser(bytes.NewReader([]byte("content"))), 7, "content-type", s3.Private, s3.Options{})
+	c.Assert(err, Equals, aws.ErrBodyNotReplayable)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestPutObjectFlakyServer(c *C) {
+	var times []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c.Check(req.Header.Get("Expect"), Equals, "100-continue")
+		body, err := ioutil.ReadAll(req.Body)
+		c.Check(err, IsNil)
+		c.Check(string(body), Equals, "content")
+		times = append(times, time.Now())
+		switch len(times) {
+		case 1:
+			w.WriteHeader(500)
+			fmt.Fprint(w, InternalErrorDump)
+		case 2:
+			w.WriteHeader(503)
+			fmt.Fprint(w, SlowDownErrorDump)
+		}
+	}))
+	defer srv.Close()
+
+	client := s3.New(aws.Auth{AccessKey: "abc", SecretKey: "123"}, aws.Region{Name: "faux-region-1", S3Endpoint: srv.URL})
+	client.AttemptStrategy = aws.AttemptStrategy{}
+	client.RetryPolicy = &aws.RetryPolicy{MaxAttempts: 3, BaseDelay: 50 * time.Millisecond}
+
+	err := client.Bucket("bucket").Put("name", []byte("content"), "content-type", s3.Private, s3.Options{})
+	c.Assert(err, IsNil)
+	c.Assert(times, HasLen, 3)
+	c.Assert(times[1].Sub(times[0]) >= 50*time.Millisecond, Equals, true)
+	c.Assert(times[2].Sub(times[1]) >= 100*time.Millisecond, Equals, true)
+}
+
+func (s *S) TestPutReaderRewind(c *C) {
+	s.s3.RetryPolicy = &aws.NoRetry
+	defer func() {
+		s.s3.RetryPolicy = nil
+	}()
+
+	testServer.Response(500, nil, InternalErrorDump)
+	testServer.Response(200, nil, "")
+
+	r := strings.NewReader("skipped content")
+	r.Seek(8, io.SeekStart)
+	b := s.s3.Bucket("bucket")
+	err := b.PutReader("name", r, 7, "content-type", s3.Private, s3.Options{})
+	c.Assert(err, IsNil)
+
+	for _, req := range testServer.WaitRequests(2) {
+		c.Assert(req.ContentLength, Equals, int64(7))
+		body, err := ioutil.ReadAll(req.Body)
+		c.Assert(err, IsNil)
+		c.Assert(string(body), Equals, "content")
+	}
+}
+
+func (s *S) TestHTTPClient(c *C) {
+	testServer.Response(200, nil, "content")
+
+	var used bool
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		used = true
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	s.s3.HTTPClient = client
+	defer func() {
+		s.s3.HTTPClient = nil
+	}()
+
+	data, err := s.s3.Bucket("bucket").Get("name")
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "content")
+	c.Assert(used, Equals, true)
+	testServer.WaitRequest()
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func (s *S) TestCustomRegion(c *C) {
+	objects := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case "PUT":
+			data, _ := ioutil.ReadAll(req.Body)
+			objects[req.URL.Path] = string(data)
+		case "GET":
+			data, ok := objects[req.URL.Path]
+			if !ok {
+				w.WriteHeader(404)
+				return
+			}
+			w.Write([]byte(data))
+		}
+	}))
+	defer srv.Close()
+
+	local := s3.New(aws.Auth{AccessKey: "abc", SecretKey: "123"}, aws.CustomRegion("local", srv.URL))
+	b := local.Bucket("bucket")
+	err := b.Put("dir/name", []byte("content"), "text/plain", s3.Private, s3.Options{})
+	c.Assert(err, IsNil)
+
+	data, err := b.Get("dir/name")
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "content")
+	c.Assert(objects, DeepEquals, map[string]string{"/bucket/dir/name": "content"})
+}
+
 func (s *S) TestPutObjectReadTimeout(c *C) {
 	s.s3.ReadTimeout = 50 * time.Millisecond
 	defer func() {
@@ -258,6 +518,95 @@ func (s *S) TestPutReader(c *C) {
 	c.Assert(req.Header["X-Amz-Acl"], DeepEquals, []string{"private"})
 }
 
+func (s *S) TestPutReaderSSEKMS(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("bucket")
+	buf := bytes.NewBufferString("content")
+	options := s3.Options{ServerSideEncryption: "aws:kms", SSEKMSKeyId: "key-id"}
+	err := b.PutReader("name", buf, int64(buf.Len()), "content-type", s3.Private, options)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Server-Side-Encryption"], DeepEquals, []string{"aws:kms"})
+	c.Assert(req.Header["X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"], DeepEquals, []string{"key-id"})
+}
+
+// The 256 bit key "0123456789abcdef0123456789abcdef".
+const (
+	sseCustomerKey    = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	sseCustomerKeyMD5 = "hRasmdxgYDKV3nvbahU1MA=="
+)
+
+func (s *S) TestPutSSECustomer(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("bucket")
+	err := b.Put("name", []byte("content"), "content-type", s3.Private, s3.Options{SSECustomerKey: sseCustomerKey})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Server-Side-Encryption"], IsNil)
+	c.Assert(req.Header["X-Amz-Server-Side-Encryption-Customer-Algorithm"], DeepEquals, []string{"AES256"})
+	c.Assert(req.Header["X-Amz-Server-Side-Encryption-Customer-Key"], DeepEquals, []string{sseCustomerKey})
+	c.Assert(req.Header["X-Amz-Server-Side-Encryption-Customer-Key-Md5"], DeepEquals, []string{sseCustomerKeyMD5})
+}
+
+func (s *S) TestPutCopySSECustomer(c *C) {
+	testServer.Response(200, nil, PutCopyResultDump)
+
+	b := s.s3.Bucket("bucket")
+	options := s3.CopyOptions{
+		Options:                  s3.Options{ServerSideEncryption: "AES256"},
+		CopySourceSSECustomerKey: sseCustomerKey,
+	}
+	_, err := b.PutCopy("name", s3.Private, options, "source-bucket/source")
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Server-Side-Encryption"], DeepEquals, []string{"AES256"})
+	c.Assert(req.Header["X-Amz-Server-Side-Encryption-Customer-Key"], IsNil)
+	c.Assert(req.Header["X-Amz-Copy-Source-Server-Side-Encryption-Customer-Algorithm"], DeepEquals, []string{"AES256"})
+	c.Assert(req.Header["X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key"], DeepEquals, []string{sseCustomerKey})
+	c.Assert(req.Header["X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key-Md5"], DeepEquals, []string{sseCustomerKeyMD5})
+}
+
+func (s *S) TestGetSSECustomerSignatureV4(c *C) {
+	headers := map[string]string{
+		"x-amz-server-side-encryption-customer-algorithm": "AES256",
+		"x-amz-server-side-encryption-customer-key-MD5":   sseCustomerKeyMD5,
+	}
+	testServer.Response(200, headers, "content")
+
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	s3v4 := s3.New(auth, aws.Region{Name: "faux-region-1", S3Endpoint: testServer.URL, S3SignatureV4: true})
+	b := s3v4.Bucket("bucket")
+	options := s3.Options{SSECustomerKey: sseCustomerKey}
+	resp, err := b.GetResponseWithHeaders("name", options.SSECustomerHeaders())
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(s3.ResponseEncryption(resp), Equals, s3.Encryption{SSECustomerAlgorithm: "AES256", SSECustomerKeyMD5: sseCustomerKeyMD5})
+
+	// The key headers are signed
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Server-Side-Encryption-Customer-Key"], DeepEquals, []string{sseCustomerKey})
+	c.Assert(req.Header.Get("Authorization"), Matches, ".* SignedHeaders=host;x-amz-content-sha256;x-amz-date;"+
+		"x-amz-server-side-encryption-customer-algorithm;x-amz-server-side-encryption-customer-key;x-amz-server-side-encryption-customer-key-md5, .*")
+}
+
+func (s *S) TestHeadEncryption(c *C) {
+	headers := map[string]string{
+		"x-amz-server-side-encryption":                "aws:kms",
+		"x-amz-server-side-encryption-aws-kms-key-id": "key-id",
+	}
+	testServer.Response(200, headers, "")
+
+	b := s.s3.Bucket("bucket")
+	resp, err := b.Head("name", nil)
+	c.Assert(err, IsNil)
+	c.Assert(s3.ResponseEncryption(resp), Equals, s3.Encryption{ServerSideEncryption: "aws:kms", SSEKMSKeyId: "key-id"})
+}
+
 func (s *S) TestPutReaderHeader(c *C) {
 	testServer.Response(200, nil, "")
 
@@ -298,23 +647,351 @@ func (s *S) TestDelObject(c *C) {
 }
 
 func (s *S) TestDelMultiObjects(c *C) {
-	testServer.Response(200, nil, "")
+	testServer.Response(200, nil, DeleteResultDump)
 
 	b := s.s3.Bucket("bucket")
-	objects := []s3.Object{s3.Object{Key: "test"}}
-	err := b.DelMulti(s3.Delete{
-		Quiet:   false,
-		Objects: objects,
-	})
+	objects := []s3.Object{{Key: "sample1.txt"}, {Key: "sample2.txt", VersionId: "v2"}, {Key: "sample3.txt"}}
+	result, err := b.DelMulti(objects, false)
 	c.Assert(err, IsNil)
+	c.Assert(result.Deleted, DeepEquals, []s3.Deleted{
+		{Key: "sample1.txt"},
+		{Key: "sample2.txt", VersionId: "v2"},
+	})
+	c.Assert(result.Errors, DeepEquals, []s3.DeleteError{
+		{Key: "sample3.txt", Code: "AccessDenied", Message: "Access Denied"},
+	})
+	c.Assert((&result.Errors[0]).Error(), Equals, "cannot delete sample3.txt: Access Denied (AccessDenied)")
 
 	req := testServer.WaitRequest()
 	c.Assert(req.Method, Equals, "POST")
 	c.Assert(req.URL.RawQuery, Equals, "delete=")
 	c.Assert(req.Header["Date"], Not(Equals), "")
-	c.Assert(req.Header["Content-MD5"], Not(Equals), "")
-	c.Assert(req.Header["Content-Type"], Not(Equals), "")
-	c.Assert(req.ContentLength, Not(Equals), "")
+	c.Assert(req.Header["Content-Type"], DeepEquals, []string{"text/xml"})
+
+	body, err := ioutil.ReadAll(req.Body)
+	c.Assert(err, IsNil)
+	sum := md5.Sum(body)
+	c.Assert(req.Header["Content-Md5"], DeepEquals, []string{base64.StdEncoding.EncodeToString(sum[:])})
+	c.Assert(string(body), Equals, xml.Header+"<Delete>"+
+		"<Object><Key>sample1.txt</Key></Object>"+
+		"<Object><Key>sample2.txt</Key><VersionId>v2</VersionId></Object>"+
+		"<Object><Key>sample3.txt</Key></Object>"+
+		"</Delete>")
+}
+
+func (s *S) TestDelMultiChunks(c *C) {
+	s3.SetDelMultiMax(2)
+	defer s3.SetDelMultiMax(1000)
+	testServer.Response(200, nil, "<DeleteResult/>")
+	testServer.Response(200, nil, DeleteResultQuietDump)
+
+	b := s.s3.Bucket("bucket")
+	objects := []s3.Object{{Key: "sample1.txt"}, {Key: "sample2.txt"}, {Key: "sample3.txt"}}
+	result, err := b.DelMulti(objects, true)
+	c.Assert(err, IsNil)
+	c.Assert(result.Deleted, HasLen, 0)
+	c.Assert(result.Errors, DeepEquals, []s3.DeleteError{
+		{Key: "sample3.txt", Code: "AccessDenied", Message: "Access Denied"},
+	})
+
+	reqs := testServer.WaitRequests(2)
+	for i, keys := range []int{2, 1} {
+		var payload struct {
+			Quiet  bool
+			Object []s3.Object
+		}
+		err := xml.NewDecoder(reqs[i].Body).Decode(&payload)
+		c.Assert(err, IsNil)
+		c.Assert(payload.Quiet, Equals, true)
+		c.Assert(payload.Object, HasLen, keys)
+	}
+}
+
+func (s *S) TestDelPrefix(c *C) {
+	s3.SetDelMultiMax(2)
+	defer s3.SetDelMultiMax(1000)
+	testServer.Response(200, nil, GetListResultDump1)
+	testServer.Response(200, nil, DeleteResultQuietDump)
+
+	b := s.s3.Bucket("quotes")
+	err := b.DelPrefix("N")
+	c.Assert(err, FitsTypeOf, s3.DeleteErrors{})
+	c.Assert(err, ErrorMatches, `cannot delete sample3.txt: Access Denied \(AccessDenied\)`)
+
+	reqs := testServer.WaitRequests(2)
+	c.Assert(reqs[0].Method, Equals, "GET")
+	c.Assert(reqs[0].Form["prefix"], DeepEquals, []string{"N"})
+	c.Assert(reqs[0].Form["max-keys"], DeepEquals, []string{"2"})
+	c.Assert(reqs[1].Method, Equals, "POST")
+	c.Assert(reqs[1].URL.RawQuery, Equals, "delete=")
+	c.Assert(readAll(reqs[1].Body), Matches, "(?s).*<Object><Key>Nelson</Key></Object><Object><Key>Neo</Key></Object>.*")
+}
+
+func (s *S) TestDelPrefixTruncated(c *C) {
+	testServer.Response(200, nil, "<ListBucketResult><IsTruncated>true</IsTruncated><Contents><Key>Nelson</Key></Contents></ListBucketResult>")
+	testServer.Response(200, nil, "<DeleteResult/>")
+	testServer.Response(200, nil, "<ListBucketResult><IsTruncated>false</IsTruncated><Contents><Key>Neo</Key></Contents></ListBucketResult>")
+	testServer.Response(200, nil, "<DeleteResult/>")
+
+	b := s.s3.Bucket("quotes")
+	err := b.DelPrefix("N")
+	c.Assert(err, IsNil)
+
+	// The listing goes on after the deleted keys
+	reqs := testServer.WaitRequests(4)
+	c.Assert(reqs[0].Form["marker"], DeepEquals, []string{""})
+	c.Assert(reqs[2].Method, Equals, "GET")
+	c.Assert(reqs[2].Form["marker"], DeepEquals, []string{"Nelson"})
+	c.Assert(readAll(reqs[3].Body), Matches, "(?s).*<Object><Key>Neo</Key></Object>.*")
+}
+
+func (s *S) TestDelVersion(c *C) {
+	testServer.Response(204, map[string]string{"x-amz-version-id": "3/L4kqtJl40Nr8X8gdRQBpUMLUo"}, "")
+
+	b := s.s3.Bucket("bucket")
+	deleted, err := b.DelVersion("name", "3/L4kqtJl40Nr8X8gdRQBpUMLUo")
+	c.Assert(err, IsNil)
+	c.Assert(deleted, DeepEquals, &s3.Deleted{Key: "name", VersionId: "3/L4kqtJl40Nr8X8gdRQBpUMLUo"})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "DELETE")
+	c.Assert(req.URL.Path, Equals, "/bucket/name")
+	c.Assert(req.Form["versionId"], DeepEquals, []string{"3/L4kqtJl40Nr8X8gdRQBpUMLUo"})
+}
+
+func (s *S) TestDelVersionDeleteMarker(c *C) {
+	headers := map[string]string{"x-amz-delete-marker": "true", "x-amz-version-id": "03jpff543dhffds434rfdsFDN943fdsFkdmqnh892"}
+	testServer.Response(204, headers, "")
+	testServer.Response(204, headers, "")
+
+	// A delete marker is created
+	b := s.s3.Bucket("bucket")
+	deleted, err := b.DelVersion("name", "")
+	c.Assert(err, IsNil)
+	c.Assert(deleted, DeepEquals, &s3.Deleted{Key: "name", DeleteMarker: true, DeleteMarkerVersionId: "03jpff543dhffds434rfdsFDN943fdsFkdmqnh892"})
+	req := testServer.WaitRequest()
+	c.Assert(req.URL.RawQuery, Equals, "")
+
+	// The delete marker is removed
+	deleted, err = b.DelVersion("name", "03jpff543dhffds434rfdsFDN943fdsFkdmqnh892")
+	c.Assert(err, IsNil)
+	c.Assert(deleted, DeepEquals, &s3.Deleted{
+		Key:                   "name",
+		VersionId:             "03jpff543dhffds434rfdsFDN943fdsFkdmqnh892",
+		DeleteMarker:          true,
+		DeleteMarkerVersionId: "03jpff543dhffds434rfdsFDN943fdsFkdmqnh892",
+	})
+}
+
+func (s *S) TestGetVersion(c *C) {
+	testServer.Response(200, map[string]string{"x-amz-version-id": "v1"}, "content")
+
+	b := s.s3.Bucket("bucket")
+	data, err := b.GetVersion("name", "v1")
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "content")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.URL.Path, Equals, "/bucket/name")
+	c.Assert(req.Form["versionId"], DeepEquals, []string{"v1"})
+}
+
+func (s *S) TestHeadVersion(c *C) {
+	testServer.Response(200, map[string]string{"x-amz-version-id": "v1"}, "")
+
+	b := s.s3.Bucket("bucket")
+	resp, err := b.HeadVersion("name", "v1", nil)
+	c.Assert(err, IsNil)
+	c.Assert(resp.Header.Get("x-amz-version-id"), Equals, "v1")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "HEAD")
+	c.Assert(req.Form["versionId"], DeepEquals, []string{"v1"})
+}
+
+func (s *S) TestPutCopyVersion(c *C) {
+	testServer.Response(200, nil, PutCopyResultDump)
+
+	b := s.s3.Bucket("bucket")
+	options := s3.CopyOptions{CopySourceVersionId: "3/L4kqtJl40Nr8X8gdRQBpUMLUo"}
+	result, err := b.PutCopy("name", s3.Private, options, "source-bucket/source")
+	c.Assert(err, IsNil)
+	c.Assert(result.ETag, Equals, `"9b2cf535f27731c974343645a3985328"`)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Copy-Source"], DeepEquals, []string{"source-bucket/source?versionId=3%2FL4kqtJl40Nr8X8gdRQBpUMLUo"})
+}
+
+func (s *S) TestPutCopyConditions(c *C) {
+	testServer.Response(200, nil, PutCopyResultDump)
+
+	b := s.s3.Bucket("bucket")
+	options := s3.CopyOptions{
+		MetadataDirective:           "REPLACE",
+		ContentType:                 "text/plain",
+		CopySourceIfMatch:           `"9b2cf535f27731c974343645a3985328"`,
+		CopySourceIfUnmodifiedSince: time.Date(2009, 10, 28, 22, 32, 0, 0, time.UTC),
+	}
+	_, err := b.PutCopy("name", s3.Private, options, "source-bucket/source")
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Metadata-Directive"], DeepEquals, []string{"REPLACE"})
+	c.Assert(req.Header["Content-Type"], DeepEquals, []string{"text/plain"})
+	c.Assert(req.Header["X-Amz-Copy-Source-If-Match"], DeepEquals, []string{`"9b2cf535f27731c974343645a3985328"`})
+	c.Assert(req.Header["X-Amz-Copy-Source-If-Unmodified-Since"], DeepEquals, []string{"Wed, 28 Oct 2009 22:32:00 GMT"})
+	c.Assert(req.Header["X-Amz-Copy-Source-If-None-Match"], IsNil)
+	c.Assert(req.Header["X-Amz-Copy-Source-If-Modified-Since"], IsNil)
+}
+
+func (s *S) TestPutCopyErrorBody(c *C) {
+	testServer.Response(200, nil, InternalErrorDump)
+	testServer.Response(200, nil, PutCopyResultDump)
+
+	// The copy is retried
+	b := s.s3.Bucket("bucket")
+	result, err := b.PutCopy("name", s3.Private, s3.CopyOptions{}, "source-bucket/source")
+	c.Assert(err, IsNil)
+	c.Assert(result.ETag, Equals, `"9b2cf535f27731c974343645a3985328"`)
+	testServer.WaitRequests(2)
+
+	s.DisableRetries()
+	testServer.Response(200, nil, InternalErrorDump)
+	_, err = b.PutCopy("name", s3.Private, s3.CopyOptions{}, "source-bucket/source")
+	c.Assert(err, ErrorMatches, `Not relevant \(InternalError\)`)
+	c.Assert(err.(*s3.Error).StatusCode, Equals, 200)
+}
+
+func (s *S) TestCopySmallObject(c *C) {
+	testServer.Response(200, map[string]string{"Content-Length": "5"}, "")
+	testServer.Response(200, nil, PutCopyResultDump)
+
+	b := s.s3.Bucket("bucket")
+	result, err := b.Copy("name", s3.Private, s3.CopyOptions{}, "source-bucket/source%20key")
+	c.Assert(err, IsNil)
+	c.Assert(result.ETag, Equals, `"9b2cf535f27731c974343645a3985328"`)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "HEAD")
+	c.Assert(req.URL.Path, Equals, "/source-bucket/source key")
+	req = testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.Path, Equals, "/bucket/name")
+	c.Assert(req.Header["X-Amz-Copy-Source"], DeepEquals, []string{"source-bucket/source%20key"})
+}
+
+func (s *S) TestCopyLargeObject(c *C) {
+	s3.SetMaxCopySize(8)
+	s3.SetCopyPartSize(4)
+	defer s3.SetMaxCopySize(5 << 30)
+	defer s3.SetCopyPartSize(512 << 20)
+
+	testServer.Response(200, map[string]string{
+		"Content-Length":   "10",
+		"Content-Type":     "text/plain",
+		"ETag":             `"source-etag"`,
+		"x-amz-meta-color": "blue",
+	}, "")
+	testServer.Response(200, nil, InitMultiResultDump)
+	testServer.Response(200, nil, CopyPartResultDump)
+	testServer.Response(200, nil, CopyPartResultDump)
+	testServer.Response(200, nil, CopyPartResultDump)
+	testServer.Response(200, nil, CompleteMultiResultDump)
+
+	b := s.s3.Bucket("sample")
+	result, err := b.Copy("multi", s3.Private, s3.CopyOptions{}, "source-bucket/source")
+	c.Assert(err, IsNil)
+	c.Assert(result.ETag, Equals, `"3858f62230ac3c915f300c664312c11f-3"`)
+
+	testServer.WaitRequest()
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "POST")
+	c.Assert(req.Header["Content-Type"], DeepEquals, []string{"text/plain"})
+	c.Assert(req.Header["X-Amz-Meta-Color"], DeepEquals, []string{"blue"})
+	for i, r := range []string{"bytes=0-3", "bytes=4-7", "bytes=8-9"} {
+		req = testServer.WaitRequest()
+		c.Assert(req.Method, Equals, "PUT")
+		c.Assert(req.Form["partNumber"], DeepEquals, []string{strconv.Itoa(i + 1)})
+		c.Assert(req.Header["X-Amz-Copy-Source"], DeepEquals, []string{"source-bucket/source"})
+		c.Assert(req.Header["X-Amz-Copy-Source-Range"], DeepEquals, []string{r})
+		c.Assert(req.Header["X-Amz-Copy-Source-If-Match"], DeepEquals, []string{`"source-etag"`})
+	}
+	req = testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "POST")
+	c.Assert(readAll(req.Body), Matches, `(?s).*<PartNumber>3</PartNumber><ETag>&#34;b54357faf0632cce46e942fa68356b38&#34;</ETag>.*`)
+}
+
+func (s *S) TestPutVersioning(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("bucket")
+	err := b.PutVersioning(s3.VersioningSuspended)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.RawQuery, Equals, "versioning=")
+	c.Assert(readAll(req.Body), Equals, xml.Header+"<VersioningConfiguration><Status>Suspended</Status></VersioningConfiguration>")
+}
+
+func (s *S) TestGetVersioning(c *C) {
+	testServer.Response(200, nil, GetVersioningResultDump)
+	testServer.Response(200, nil, `<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"/>`)
+
+	b := s.s3.Bucket("bucket")
+	config, err := b.GetVersioning()
+	c.Assert(err, IsNil)
+	c.Assert(config.Status, Equals, s3.VersioningEnabled)
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.URL.RawQuery, Equals, "versioning=")
+
+	// Never versioned
+	config, err = b.GetVersioning()
+	c.Assert(err, IsNil)
+	c.Assert(config.Status, Equals, "")
+}
+
+func (s *S) TestListVersions(c *C) {
+	testServer.Response(200, nil, ListVersionsResultDump)
+
+	b := s.s3.Bucket("bucket")
+	result, err := b.ListVersions("my", "", "", "", 3)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.URL.Path, Equals, "/bucket/")
+	c.Assert(req.Form["versions"], DeepEquals, []string{""})
+	c.Assert(req.Form["prefix"], DeepEquals, []string{"my"})
+	c.Assert(req.Form["max-keys"], DeepEquals, []string{"3"})
+
+	c.Assert(result.IsTruncated, Equals, true)
+	c.Assert(result.NextKeyMarker, Equals, "my-second-image.jpg")
+	c.Assert(result.NextVersionIdMarker, Equals, "03jpff543dhffds434rfdsFDN943fdsFkdmqnh892")
+	c.Assert(result.Versions, HasLen, 2)
+	c.Assert(result.Versions[0].Key, Equals, "my-image.jpg")
+	c.Assert(result.Versions[0].VersionId, Equals, "3/L4kqtJl40Nr8X8gdRQBpUMLUo")
+	c.Assert(result.Versions[0].IsLatest, Equals, true)
+	c.Assert(result.Versions[0].Size, Equals, int64(434234))
+	c.Assert(result.Versions[1].Key, Equals, "my-second-image.jpg")
+	c.Assert(result.Versions[1].IsLatest, Equals, false)
+	c.Assert(result.DeleteMarkers, DeepEquals, []s3.DeleteMarker{{
+		Key:          "my-second-image.jpg",
+		VersionId:    "03jpff543dhffds434rfdsFDN943fdsFkdmqnh892",
+		IsLatest:     true,
+		LastModified: "2009-11-12T17:50:30.000Z",
+		Owner:        s3.Owner{ID: "75aa57f09aa0c8caeab4f8c24e99d10f8e7faeebf76c078efc7c6caea54ba06a", DisplayName: "mtd@amazon.com"},
+	}})
+
+	// The markers are sent to list the following versions
+	testServer.Response(200, nil, "<ListVersionsResult/>")
+	_, err = b.ListVersions("my", "", result.NextKeyMarker, result.NextVersionIdMarker, 3)
+	c.Assert(err, IsNil)
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["key-marker"], DeepEquals, []string{"my-second-image.jpg"})
+	c.Assert(req.Form["version-id-marker"], DeepEquals, []string{"03jpff543dhffds434rfdsFDN943fdsFkdmqnh892"})
 }
 
 // Bucket List Objects docs: http://goo.gl/YjQTc
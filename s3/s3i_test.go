@@ -389,6 +389,92 @@ func (s *ClientTests) TestBucketList(c *C) {
 	}
 }
 
+func (s *ClientTests) TestBucketListAll(c *C) {
+	b := testBucket(s.s3)
+	err := b.PutBucket(s3.Private)
+	c.Assert(err, IsNil)
+
+	for _, path := range objectNames {
+		err := b.Put(path, nil, "text/plain", s3.Private, s3.Options{})
+		c.Assert(err, IsNil)
+		defer b.Del(path)
+	}
+
+	for _, listV1 := range []bool{false, true} {
+		c.Logf("ListV1 %v", listV1)
+		it := b.ListAll("", "/")
+		it.PageSize = 1
+		it.ListV1 = listV1
+		var names []string
+		for it.Next() {
+			names = append(names, it.Key().Key)
+		}
+		c.Assert(it.Err(), IsNil)
+		c.Check(names, DeepEquals, []string{"index.html", "index2.html"})
+		c.Check(it.CommonPrefixes(), DeepEquals, []string{"photos/", "test/"})
+	}
+
+	var names []string
+	err = b.Walk("photos/", func(key s3.Key) error {
+		names = append(names, key.Key)
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Check(names, DeepEquals, objectNames[2:6])
+
+	dirs, objects, err := b.ListDir("photos/2006")
+	c.Assert(err, IsNil)
+	c.Check(dirs, DeepEquals, []string{"photos/2006/February/", "photos/2006/January/"})
+	c.Check(objects, HasLen, 0)
+}
+
+func (s *ClientTests) TestDownloadTo(c *C) {
+	b := testBucket(s.s3)
+	err := b.PutBucket(s3.Private)
+	c.Assert(err, IsNil)
+
+	data := []byte(strings.Repeat("0123456789", 100))
+	err = b.Put("download", data, "text/plain", s3.Private, s3.Options{})
+	c.Assert(err, IsNil)
+	defer b.Del("download")
+
+	w := &writerAt{}
+	err = b.DownloadTo(w, "download", 64, 4)
+	c.Assert(err, IsNil)
+	c.Assert(w.data, DeepEquals, data)
+
+	rc, err := b.GetRange("download", 995, 2000)
+	c.Assert(err, IsNil)
+	defer rc.Close()
+	c.Assert(readAll(rc), Equals, "56789")
+
+	_, err = b.GetRange("download", 1000, -1)
+	c.Assert(err, FitsTypeOf, new(s3.Error))
+	c.Assert(err.(*s3.Error).Code, Equals, "InvalidRange")
+}
+
+func (s *ClientTests) TestStat(c *C) {
+	b := testBucket(s.s3)
+	err := b.PutBucket(s3.Private)
+	c.Assert(err, IsNil)
+
+	err = b.Put("stat", []byte("content"), "text/plain", s3.Private, s3.Options{
+		Meta: map[string][]string{"color": {"blue"}},
+	})
+	c.Assert(err, IsNil)
+	defer b.Del("stat")
+
+	info, err := b.Stat("stat")
+	c.Assert(err, IsNil)
+	c.Check(info.ContentLength, Equals, int64(7))
+	c.Check(info.ContentType, Equals, "text/plain")
+	c.Check(strings.Trim(info.ETag, `"`), Equals, strings.Trim(etag([]byte("content")), `"`))
+	c.Check(info.Meta, DeepEquals, map[string][]string{"color": {"blue"}})
+
+	_, err = b.Stat("non-existent")
+	c.Assert(err, FitsTypeOf, new(s3.NotFoundError))
+}
+
 func etag(data []byte) string {
 	sum := md5.New()
 	sum.Write(data)
@@ -74,6 +74,18 @@ func (s *LocalServerSuite) TestBucketList(c *C) {
 	s.clientTests.TestBucketList(c)
 }
 
+func (s *LocalServerSuite) TestBucketListAll(c *C) {
+	s.clientTests.TestBucketListAll(c)
+}
+
+func (s *LocalServerSuite) TestDownloadTo(c *C) {
+	s.clientTests.TestDownloadTo(c)
+}
+
+func (s *LocalServerSuite) TestStat(c *C) {
+	s.clientTests.TestStat(c)
+}
+
 func (s *LocalServerSuite) TestDoublePutBucket(c *C) {
 	s.clientTests.TestDoublePutBucket(c)
 }
@@ -297,6 +297,14 @@ func (r bucketResource) get(a *action) interface{} {
 	}
 	delimiter := a.req.Form.Get("delimiter")
 	marker := a.req.Form.Get("marker")
+	listV2 := a.req.Form.Get("list-type") == "2"
+	if listV2 {
+		// The continuation token is the last name listed
+		marker = a.req.Form.Get("start-after")
+		if token := a.req.Form.Get("continuation-token"); token != "" {
+			marker = token
+		}
+	}
 	maxKeys := -1
 	if s := a.req.Form.Get("max-keys"); s != "" {
 		i, err := strconv.Atoi(s)
@@ -364,9 +372,61 @@ func (r bucketResource) get(a *action) interface{} {
 		}
 	}
 	resp.CommonPrefixes = prefixes
+	urlEncoded := a.req.Form.Get("encoding-type") == "url"
+	if listV2 {
+		v2 := listV2Resp(resp, a.req.Form.Get("continuation-token"), a.req.Form.Get("start-after"))
+		if urlEncoded {
+			v2.EncodingType = "url"
+			encodeKeys(v2.Contents, v2.CommonPrefixes, &v2.Prefix, &v2.Delimiter, &v2.StartAfter)
+		}
+		return v2
+	}
+	if urlEncoded {
+		resp.EncodingType = "url"
+		encodeKeys(resp.Contents, resp.CommonPrefixes, &resp.Prefix, &resp.Delimiter, &resp.Marker)
+	}
 	return resp
 }
 
+// encodeKeys URL encodes in place the keys, prefixes and other strings of
+// a listing requested with the "url" encoding type.
+func encodeKeys(keys []s3.Key, prefixes []string, strs ...*string) {
+	for i := range keys {
+		keys[i].Key = url.QueryEscape(keys[i].Key)
+	}
+	for i := range prefixes {
+		prefixes[i] = url.QueryEscape(prefixes[i])
+	}
+	for _, s := range strs {
+		*s = url.QueryEscape(*s)
+	}
+}
+
+// listV2Resp converts the result of a List to the one of a ListV2.
+func listV2Resp(resp *s3.ListResp, token, startAfter string) *s3.ListV2Resp {
+	v2 := &s3.ListV2Resp{
+		Name:              resp.Name,
+		Prefix:            resp.Prefix,
+		Delimiter:         resp.Delimiter,
+		StartAfter:        startAfter,
+		MaxKeys:           resp.MaxKeys,
+		KeyCount:          len(resp.Contents) + len(resp.CommonPrefixes),
+		ContinuationToken: token,
+		IsTruncated:       resp.IsTruncated,
+		Contents:          resp.Contents,
+		CommonPrefixes:    resp.CommonPrefixes,
+	}
+	if resp.IsTruncated {
+		if n := len(resp.Contents); n > 0 {
+			v2.NextContinuationToken = resp.Contents[n-1].Key
+		}
+		if n := len(resp.CommonPrefixes); n > 0 && resp.CommonPrefixes[n-1] > v2.NextContinuationToken {
+			v2.NextContinuationToken = resp.CommonPrefixes[n-1]
+		}
+	}
+	return v2
+}
+
 // orderedObjects holds a slice of objects that can be sorted
 // by name.
 type orderedObjects []*object
@@ -512,24 +572,33 @@ func (objr objectResource) get(a *action) interface{} {
 			h.Set(name, vals[0])
 		}
 	}
+	etag := hex.EncodeToString(obj.checksum)
+	if m := a.req.Header.Get("If-Match"); m != "" && strings.Trim(m, `"`) != etag {
+		fatalf(412, "PreconditionFailed", "At least one of the preconditions you specified did not hold.")
+	}
+	data := obj.data
+	status := 200
 	if r := a.req.Header.Get("Range"); r != "" {
-		fatalf(400, "NotImplemented", "range unimplemented")
+		start, end := parseRange(r, len(data))
+		h.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		data = data[start : end+1]
+		status = 206
 	}
 	// TODO Last-Modified-Since
 	// TODO If-Modified-Since
 	// TODO If-Unmodified-Since
-	// TODO If-Match
 	// TODO If-None-Match
 	// TODO Connection: close ??
 	// TODO x-amz-request-id
-	h.Set("Content-Length", fmt.Sprint(len(obj.data)))
-	h.Set("ETag", hex.EncodeToString(obj.checksum))
+	h.Set("Content-Length", fmt.Sprint(len(data)))
+	h.Set("ETag", etag)
 	h.Set("Last-Modified", obj.mtime.Format(time.RFC1123))
 	if a.req.Method == "HEAD" {
 		return nil
 	}
+	a.w.WriteHeader(status)
 	// TODO avoid holding the lock when writing data.
-	_, err := a.w.Write(obj.data)
+	_, err := a.w.Write(data)
 	if err != nil {
 		// we can't do much except just log the fact.
 		log.Printf("error writing data: %v", err)
@@ -537,6 +606,34 @@ func (objr objectResource) get(a *action) interface{} {
 	return nil
 }
 
+// parseRange returns the first and last bytes of the single range of
+// bytes given in the Range header r, for an object of the given size.
+func parseRange(r string, size int) (start, end int) {
+	var err error
+	spec := strings.SplitN(strings.TrimPrefix(r, "bytes="), "-", 2)
+	if !strings.HasPrefix(r, "bytes=") || len(spec) != 2 || spec[0] == "" || strings.Contains(r, ",") {
+		fatalf(400, "NotImplemented", "range %q unimplemented", r)
+	}
+	start, err = strconv.Atoi(spec[0])
+	if err != nil {
+		fatalf(400, "InvalidArgument", "invalid range %q", r)
+	}
+	end = size - 1
+	if spec[1] != "" {
+		end, err = strconv.Atoi(spec[1])
+		if err != nil || end < start {
+			fatalf(400, "InvalidArgument", "invalid range %q", r)
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	if start >= size {
+		fatalf(416, "InvalidRange", "The requested range is not satisfiable")
+	}
+	return start, end
+}
+
 var metaHeaders = map[string]bool{
 	"Content-MD5":         true,
 	"x-amz-acl":           true,
@@ -37,6 +37,10 @@ var s3ParamsToSign = map[string]bool{
 	"response-content-encoding":    true,
 	"website":                      true,
 	"delete":                       true,
+	"lifecycle":                    true,
+	"cors":                         true,
+	"tagging":                      true,
+	"restore":                      true,
 }
 
 func sign(auth aws.Auth, method, canonicalPath string, params, headers map[string][]string) {
@@ -0,0 +1,26 @@
+package s3
+
+import "fmt"
+
+// NotFoundError is returned by Stat when the object doesn't exist. S3
+// doesn't tell missing objects apart from forbidden ones to callers who
+// lack the s3:ListBucket permission, which get an *Error with status 403.
+type NotFoundError struct {
+	Bucket string
+	Path   string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("s3: object %q not found in bucket %q", e.Path, e.Bucket)
+}
+
+// Stat returns the metadata of the object at path without retrieving its
+// content. It returns a *NotFoundError if the object doesn't exist, which
+// the empty body of the response to a HEAD request doesn't otherwise tell.
+func (b *Bucket) Stat(path string) (*ObjectInfo, error) {
+	info, err := b.HeadWithConditions(path, Conditions{})
+	if e, ok := err.(*Error); ok && e.StatusCode == 404 {
+		return nil, &NotFoundError{Bucket: b.Name, Path: path}
+	}
+	return info, err
+}
@@ -0,0 +1,62 @@
+package s3_test
+
+import (
+	"time"
+
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) TestStat(c *C) {
+	testServer.Response(200, map[string]string{
+		"ETag":                         `"828ef3fdfa96f00ad9f27c383fc9ac7f"`,
+		"Last-Modified":                "Wed, 28 Oct 2009 22:32:00 GMT",
+		"Content-Type":                 "text/plain",
+		"Content-Length":               "434234",
+		"x-amz-storage-class":          "STANDARD_IA",
+		"x-amz-version-id":             "3HL4kqtJlcpXroDTDmjVBH40Nrjfkd",
+		"x-amz-server-side-encryption": "aws:kms",
+		"x-amz-server-side-encryption-aws-kms-key-id": "key-id",
+		"x-amz-meta-Color": "blue",
+	}, "")
+
+	b := s.s3.Bucket("bucket")
+	info, err := b.Stat("name")
+	c.Assert(err, IsNil)
+	c.Assert(info, DeepEquals, &s3.ObjectInfo{
+		ETag:          `"828ef3fdfa96f00ad9f27c383fc9ac7f"`,
+		LastModified:  time.Date(2009, 10, 28, 22, 32, 0, 0, time.UTC),
+		ContentLength: 434234,
+		ContentType:   "text/plain",
+		StorageClass:  s3.StorageStandardIA,
+		VersionId:     "3HL4kqtJlcpXroDTDmjVBH40Nrjfkd",
+		SSE:           "aws:kms",
+		SSEKMSKeyId:   "key-id",
+		Meta:          map[string][]string{"color": {"blue"}},
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "HEAD")
+	c.Assert(req.URL.Path, Equals, "/bucket/name")
+}
+
+func (s *S) TestStatNotFound(c *C) {
+	testServer.Response(404, nil, "")
+
+	b := s.s3.Bucket("bucket")
+	info, err := b.Stat("name")
+	c.Assert(info, IsNil)
+	c.Assert(err, DeepEquals, &s3.NotFoundError{Bucket: "bucket", Path: "name"})
+	c.Assert(err, ErrorMatches, `s3: object "name" not found in bucket "bucket"`)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestStatForbidden(c *C) {
+	testServer.Response(403, nil, "")
+
+	b := s.s3.Bucket("bucket")
+	_, err := b.Stat("name")
+	c.Assert(err, FitsTypeOf, &s3.Error{})
+	c.Assert(err.(*s3.Error).StatusCode, Equals, 403)
+	testServer.WaitRequest()
+}
@@ -0,0 +1,94 @@
+package s3
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Storage classes of objects.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/dev/storage-class-intro.html for details.
+const (
+	StorageStandard           = "STANDARD"
+	StorageReducedRedundancy  = "REDUCED_REDUNDANCY"
+	StorageStandardIA         = "STANDARD_IA"
+	StorageOneZoneIA          = "ONEZONE_IA"
+	StorageIntelligentTiering = "INTELLIGENT_TIERING"
+	StorageGlacier            = "GLACIER"
+	StorageGlacierIR          = "GLACIER_IR"
+	StorageDeepArchive        = "DEEP_ARCHIVE"
+)
+
+// Tiers of the restoration of archived objects, from the fastest to the
+// cheapest.
+const (
+	RestoreExpedited = "Expedited"
+	RestoreStandard  = "Standard"
+	RestoreBulk      = "Bulk"
+)
+
+// RestoreStatus is the status of the restoration of an archived object,
+// reported by the x-amz-restore header.
+type RestoreStatus struct {
+	// Ongoing is true while the object is being restored.
+	Ongoing bool
+
+	// Expiry is when the restored copy of the object is removed, once
+	// the restoration completed.
+	Expiry time.Time
+}
+
+// parseRestore parses the x-amz-restore header h, such as:
+//
+//	ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"
+func parseRestore(h string) *RestoreStatus {
+	if h == "" {
+		return nil
+	}
+	status := &RestoreStatus{}
+	for _, field := range strings.Split(h, `",`) {
+		kv := strings.SplitN(strings.TrimSpace(field), `="`, 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.TrimSuffix(kv[1], `"`)
+		switch kv[0] {
+		case "ongoing-request":
+			status.Ongoing = value == "true"
+		case "expiry-date":
+			status.Expiry, _ = http.ParseTime(value)
+		}
+	}
+	return status
+}
+
+type restoreRequest struct {
+	XMLName              xml.Name `xml:"RestoreRequest"`
+	Days                 int
+	GlacierJobParameters *glacierJobParameters `xml:",omitempty"`
+}
+
+type glacierJobParameters struct {
+	Tier string
+}
+
+// RestoreObject restores a copy of the archived object at path for the
+// given number of days, with the given tier or the default one if empty.
+// The restoration is complete when the Restore of the ResponseInfo of the
+// object isn't Ongoing anymore. Restoring an object already restored
+// changes when its copy expires.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/API_RestoreObject.html for details.
+func (b *Bucket) RestoreObject(path string, days int, tier string) error {
+	r := &restoreRequest{Days: days}
+	if tier != "" {
+		r.GlacierJobParameters = &glacierJobParameters{tier}
+	}
+	doc, err := xml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return b.sendSubresource("POST", path, "restore", "text/xml", makeXmlBuffer(doc).Bytes())
+}
@@ -0,0 +1,75 @@
+package s3_test
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) TestPutStorageClass(c *C) {
+	testServer.Response(200, nil, "")
+	testServer.Response(200, nil, PutCopyResultDump)
+	testServer.Response(200, nil, InitMultiResultDump)
+
+	b := s.s3.Bucket("sample")
+	options := s3.Options{StorageClass: s3.StorageStandardIA}
+	err := b.Put("name", []byte("content"), "text/plain", s3.Private, options)
+	c.Assert(err, IsNil)
+	_, err = b.PutCopy("copy", s3.Private, s3.CopyOptions{Options: s3.Options{StorageClass: s3.StorageGlacier}}, "sample/name")
+	c.Assert(err, IsNil)
+	_, err = b.InitMultiWithOptions("multi", "text/plain", s3.Private, s3.Options{StorageClass: s3.StorageDeepArchive})
+	c.Assert(err, IsNil)
+
+	for _, class := range []string{"STANDARD_IA", "GLACIER", "DEEP_ARCHIVE"} {
+		req := testServer.WaitRequest()
+		c.Assert(req.Header["X-Amz-Storage-Class"], DeepEquals, []string{class})
+	}
+}
+
+func (s *S) TestRestoreObject(c *C) {
+	testServer.Response(202, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.RestoreObject("archive.tar", 7, s3.RestoreBulk)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "POST")
+	c.Assert(req.URL.Path, Equals, "/sample/archive.tar")
+	c.Assert(req.URL.RawQuery, Equals, "restore=")
+	c.Assert(req.Header["Content-Md5"], HasLen, 1)
+	c.Assert(readAll(req.Body), Equals, xml.Header+"<RestoreRequest><Days>7</Days><GlacierJobParameters><Tier>Bulk</Tier></GlacierJobParameters></RestoreRequest>")
+
+	// Without a tier
+	testServer.Response(200, nil, "")
+	err = b.RestoreObject("archive.tar", 1, "")
+	c.Assert(err, IsNil)
+	req = testServer.WaitRequest()
+	c.Assert(readAll(req.Body), Equals, xml.Header+"<RestoreRequest><Days>1</Days></RestoreRequest>")
+}
+
+func (s *S) TestHeadRestore(c *C) {
+	testServer.Response(200, map[string]string{"x-amz-storage-class": "GLACIER", "x-amz-restore": `ongoing-request="true"`}, "")
+	testServer.Response(200, map[string]string{"x-amz-storage-class": "GLACIER", "x-amz-restore": `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`}, "")
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	info, err := b.HeadWithConditions("archive.tar", s3.Conditions{})
+	c.Assert(err, IsNil)
+	c.Assert(info.StorageClass, Equals, "GLACIER")
+	c.Assert(info.Restore, DeepEquals, &s3.RestoreStatus{Ongoing: true})
+
+	resp, err := b.Head("archive.tar", nil)
+	c.Assert(err, IsNil)
+	info = s3.ResponseInfo(resp)
+	c.Assert(info.Restore, DeepEquals, &s3.RestoreStatus{Expiry: time.Date(2012, 12, 21, 0, 0, 0, 0, time.UTC)})
+
+	// Objects in the standard storage class have neither
+	info, err = b.HeadWithConditions("name", s3.Conditions{})
+	c.Assert(err, IsNil)
+	c.Assert(info.StorageClass, Equals, "")
+	c.Assert(info.Restore, IsNil)
+	testServer.WaitRequests(3)
+}
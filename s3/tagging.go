@@ -0,0 +1,99 @@
+package s3
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits of the tags of an object.
+const (
+	maxTags           = 10
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+type tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	Tags    []Tag    `xml:"TagSet>Tag"`
+}
+
+// validateTags reports the first reason S3 would reject tags for.
+func validateTags(tags map[string]string) error {
+	if len(tags) > maxTags {
+		return fmt.Errorf("s3: %d tags given, at most %d are allowed", len(tags), maxTags)
+	}
+	for k, v := range tags {
+		if k == "" {
+			return fmt.Errorf("s3: empty tag key")
+		}
+		if n := utf8.RuneCountInString(k); n > maxTagKeyLength {
+			return fmt.Errorf("s3: tag key %q has %d characters, at most %d are allowed", k, n, maxTagKeyLength)
+		}
+		if n := utf8.RuneCountInString(v); n > maxTagValueLength {
+			return fmt.Errorf("s3: value of tag %q has %d characters, at most %d are allowed", k, n, maxTagValueLength)
+		}
+	}
+	return nil
+}
+
+// sortedTags returns tags sorted by key.
+func sortedTags(tags map[string]string) []Tag {
+	sorted := make([]Tag, 0, len(tags))
+	for k, v := range tags {
+		sorted = append(sorted, Tag{Key: k, Value: v})
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	return sorted
+}
+
+// encodeTags returns tags as the URL encoded query of the x-amz-tagging
+// header.
+func encodeTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for _, tag := range sortedTags(tags) {
+		pairs = append(pairs, tagEscape(tag.Key)+"="+tagEscape(tag.Value))
+	}
+	return strings.Join(pairs, "&")
+}
+
+func tagEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+// PutTagging sets the tags of the object at path, replacing any previous
+// ones.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObjectTagging.html for details.
+func (b *Bucket) PutTagging(path string, tags map[string]string) error {
+	if err := validateTags(tags); err != nil {
+		return err
+	}
+	return b.putSubresourceXML(path, "tagging", &tagging{Tags: sortedTags(tags)})
+}
+
+// GetTagging returns the tags of the object at path.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectTagging.html for details.
+func (b *Bucket) GetTagging(path string) (map[string]string, error) {
+	var t tagging
+	err := b.getSubresource(path, "tagging", &t)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string, len(t.Tags))
+	for _, tag := range t.Tags {
+		tags[tag.Key] = tag.Value
+	}
+	return tags, nil
+}
+
+// DelTagging removes the tags of the object at path.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteObjectTagging.html for details.
+func (b *Bucket) DelTagging(path string) error {
+	return b.delSubresource(path, "tagging")
+}
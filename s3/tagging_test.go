@@ -0,0 +1,120 @@
+package s3_test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) TestPutTagging(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.PutTagging("photos/cat.jpg", map[string]string{"project": "goamz", "owner": "Zoë Ångström"})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.Path, Equals, "/sample/photos/cat.jpg")
+	c.Assert(req.URL.RawQuery, Equals, "tagging=")
+	c.Assert(req.Header["Content-Md5"], HasLen, 1)
+	c.Assert(readAll(req.Body), Equals, xml.Header+"<Tagging><TagSet>"+
+		"<Tag><Key>owner</Key><Value>Zoë Ångström</Value></Tag>"+
+		"<Tag><Key>project</Key><Value>goamz</Value></Tag>"+
+		"</TagSet></Tagging>")
+}
+
+func (s *S) TestGetTagging(c *C) {
+	testServer.Response(200, nil, GetTaggingResultDump)
+
+	b := s.s3.Bucket("sample")
+	tags, err := b.GetTagging("photos/cat.jpg")
+	c.Assert(err, IsNil)
+	c.Assert(tags, DeepEquals, map[string]string{"project": "goamz", "owner": "Zoë Ångström"})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.URL.Path, Equals, "/sample/photos/cat.jpg")
+	c.Assert(req.URL.RawQuery, Equals, "tagging=")
+}
+
+func (s *S) TestDelTagging(c *C) {
+	testServer.Response(204, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.DelTagging("photos/cat.jpg")
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "DELETE")
+	c.Assert(req.URL.Path, Equals, "/sample/photos/cat.jpg")
+	c.Assert(req.URL.RawQuery, Equals, "tagging=")
+}
+
+func (s *S) TestPutWithTags(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	options := s3.Options{Tags: map[string]string{"b": "x y&z", "a": "é=1"}}
+	err := b.Put("name", []byte("content"), "text/plain", s3.Private, options)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Tagging"], DeepEquals, []string{"a=%C3%A9%3D1&b=x%20y%26z"})
+}
+
+func (s *S) TestInitMultiWithTags(c *C) {
+	testServer.Response(200, nil, InitMultiResultDump)
+
+	b := s.s3.Bucket("sample")
+	_, err := b.InitMultiWithOptions("multi", "text/plain", s3.Private, s3.Options{Tags: map[string]string{"project": "goamz"}})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Tagging"], DeepEquals, []string{"project=goamz"})
+}
+
+func (s *S) TestPutCopyWithTags(c *C) {
+	testServer.Response(200, nil, PutCopyResultDump)
+
+	b := s.s3.Bucket("sample")
+	options := s3.CopyOptions{Options: s3.Options{Tags: map[string]string{"project": "goamz"}}}
+	_, err := b.PutCopy("name", s3.Private, options, "sample/source")
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Header["X-Amz-Tagging"], DeepEquals, []string{"project=goamz"})
+	c.Assert(req.Header["X-Amz-Tagging-Directive"], DeepEquals, []string{"REPLACE"})
+}
+
+func (s *S) TestTagLimits(c *C) {
+	many := make(map[string]string)
+	for i := 0; i < 11; i++ {
+		many[fmt.Sprint("key", i)] = "value"
+	}
+	tests := []struct {
+		tags map[string]string
+		err  string
+	}{
+		{many, "s3: 11 tags given, at most 10 are allowed"},
+		{map[string]string{"": "value"}, "s3: empty tag key"},
+		{map[string]string{strings.Repeat("k", 129): "value"}, `s3: tag key "k+" has 129 characters, at most 128 are allowed`},
+		{map[string]string{"key": strings.Repeat("é", 257)}, `s3: value of tag "key" has 257 characters, at most 256 are allowed`},
+	}
+	b := s.s3.Bucket("sample")
+	for i, t := range tests {
+		err := b.PutTagging("name", t.tags)
+		c.Check(err, ErrorMatches, t.err, Commentf("test %d", i))
+		err = b.Put("name", nil, "text/plain", s3.Private, s3.Options{Tags: t.tags})
+		c.Check(err, ErrorMatches, t.err, Commentf("test %d", i))
+	}
+
+	// Limits count characters rather than bytes
+	testServer.Response(200, nil, "")
+	err := b.PutTagging("name", map[string]string{strings.Repeat("é", 128): strings.Repeat("é", 256)})
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+}
@@ -0,0 +1,170 @@
+package s3
+
+import (
+	"encoding/xml"
+
+	"github.com/goamz/goamz/aws"
+)
+
+// WebsiteConfiguration makes a bucket serve its objects as a static
+// website, or redirect all the requests to another host with
+// RedirectAllRequestsTo.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/dev/WebsiteHosting.html for details.
+type WebsiteConfiguration struct {
+	XMLName               xml.Name               `xml:"http://s3.amazonaws.com/doc/2006-03-01/ WebsiteConfiguration"`
+	RedirectAllRequestsTo *RedirectAllRequestsTo `xml:"RedirectAllRequestsTo,omitempty"`
+	IndexDocumentSuffix   string                 `xml:"IndexDocument>Suffix"`
+	ErrorDocumentKey      string                 `xml:"ErrorDocument>Key"`
+	RoutingRules          *[]RoutingRule         `xml:"RoutingRules>RoutingRule,omitempty"`
+}
+
+// RedirectAllRequestsTo redirects all the requests to the website to
+// HostName, with Protocol "http" or "https", or the protocol of the
+// request if empty.
+type RedirectAllRequestsTo struct {
+	HostName string `xml:"HostName"`
+	Protocol string `xml:"Protocol,omitempty"`
+}
+
+// RoutingRule redirects the requests meeting its conditions, for keys
+// with a prefix or which would return an HTTP error code, or all the
+// requests if it has none. The redirect replaces the key or its prefix,
+// and may change the host, the protocol and the status code of the
+// redirect.
+type RoutingRule struct {
+	ConditionKeyPrefixEquals             string `xml:"Condition>KeyPrefixEquals"`
+	ConditionHttpErrorCodeReturnedEquals string `xml:"Condition>HttpErrorCodeReturnedEquals,omitempty"`
+	RedirectProtocol                     string `xml:"Redirect>Protocol,omitempty"`
+	RedirectHostName                     string `xml:"Redirect>HostName,omitempty"`
+	RedirectReplaceKeyPrefixWith         string `xml:"Redirect>ReplaceKeyPrefixWith,omitempty"`
+	RedirectReplaceKeyWith               string `xml:"Redirect>ReplaceKeyWith,omitempty"`
+	RedirectHttpRedirectCode             string `xml:"Redirect>HttpRedirectCode,omitempty"`
+}
+
+// websiteDocument is the XML document of a WebsiteConfiguration without
+// the elements which aren't set, which encoding/xml would otherwise write
+// around empty fields.
+type websiteDocument struct {
+	XMLName               xml.Name               `xml:"http://s3.amazonaws.com/doc/2006-03-01/ WebsiteConfiguration"`
+	RedirectAllRequestsTo *RedirectAllRequestsTo `xml:"RedirectAllRequestsTo,omitempty"`
+	IndexDocument         *struct{ Suffix string }
+	ErrorDocument         *struct{ Key string }
+	RoutingRules          *routingRules
+}
+
+type routingRules struct {
+	Rules []routingRuleDocument `xml:"RoutingRule"`
+}
+
+type routingRuleDocument struct {
+	Condition *routingCondition
+	Redirect  routingRedirect
+}
+
+type routingCondition struct {
+	KeyPrefixEquals             string `xml:",omitempty"`
+	HttpErrorCodeReturnedEquals string `xml:",omitempty"`
+}
+
+type routingRedirect struct {
+	Protocol             string `xml:",omitempty"`
+	HostName             string `xml:",omitempty"`
+	ReplaceKeyPrefixWith string `xml:",omitempty"`
+	ReplaceKeyWith       string `xml:",omitempty"`
+	HttpRedirectCode     string `xml:",omitempty"`
+}
+
+func (config *WebsiteConfiguration) document() *websiteDocument {
+	doc := &websiteDocument{RedirectAllRequestsTo: config.RedirectAllRequestsTo}
+	if config.IndexDocumentSuffix != "" {
+		doc.IndexDocument = &struct{ Suffix string }{config.IndexDocumentSuffix}
+	}
+	if config.ErrorDocumentKey != "" {
+		doc.ErrorDocument = &struct{ Key string }{config.ErrorDocumentKey}
+	}
+	if config.RoutingRules == nil {
+		return doc
+	}
+	doc.RoutingRules = &routingRules{}
+	for _, rule := range *config.RoutingRules {
+		r := routingRuleDocument{Redirect: routingRedirect{
+			Protocol:             rule.RedirectProtocol,
+			HostName:             rule.RedirectHostName,
+			ReplaceKeyPrefixWith: rule.RedirectReplaceKeyPrefixWith,
+			ReplaceKeyWith:       rule.RedirectReplaceKeyWith,
+			HttpRedirectCode:     rule.RedirectHttpRedirectCode,
+		}}
+		if rule.ConditionKeyPrefixEquals != "" || rule.ConditionHttpErrorCodeReturnedEquals != "" {
+			r.Condition = &routingCondition{rule.ConditionKeyPrefixEquals, rule.ConditionHttpErrorCodeReturnedEquals}
+		}
+		doc.RoutingRules.Rules = append(doc.RoutingRules.Rules, r)
+	}
+	return doc
+}
+
+// PutWebsite sets the website configuration of b, replacing any previous
+// one.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketPUTwebsite.html for details.
+func (b *Bucket) PutWebsite(config WebsiteConfiguration) error {
+	return b.putSubresourceXML("/", "website", config.document())
+}
+
+// PutBucketWebsite sets the website configuration of b.
+//
+// Deprecated: use PutWebsite.
+func (b *Bucket) PutBucketWebsite(configuration WebsiteConfiguration) error {
+	return b.PutWebsite(configuration)
+}
+
+// GetWebsite returns the website configuration of b. It is empty if b
+// isn't a website.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketGETwebsite.html for details.
+func (b *Bucket) GetWebsite() (*WebsiteConfiguration, error) {
+	config := &WebsiteConfiguration{}
+	err := b.getSubresource("/", "website", config)
+	if hasCode(err, "NoSuchWebsiteConfiguration") {
+		return &WebsiteConfiguration{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// DelWebsite removes the website configuration of b.
+//
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketDELETEwebsite.html for details.
+func (b *Bucket) DelWebsite() error {
+	return b.delSubresource("/", "website")
+}
+
+// Regions whose website endpoints are named s3-website-<region> rather
+// than s3-website.<region>.
+var dashWebsiteRegions = map[string]bool{
+	"us-east-1":      true,
+	"us-west-1":      true,
+	"us-west-2":      true,
+	"ap-southeast-1": true,
+	"ap-southeast-2": true,
+	"ap-northeast-1": true,
+	"eu-west-1":      true,
+	"sa-east-1":      true,
+	"us-gov-west-1":  true,
+}
+
+// WebsiteEndpoint returns the host name b is served at as a website, which
+// differs from its REST endpoint, for the region of the S3 or that b was
+// found to be in.
+func (b *Bucket) WebsiteEndpoint() string {
+	region := b.S3.Region.Name
+	if r, ok := b.S3.regions.Load(b.Name); ok {
+		region = r.(aws.Region).Name
+	}
+	if dashWebsiteRegions[region] {
+		return b.Name + ".s3-website-" + region + ".amazonaws.com"
+	}
+	return b.Name + ".s3-website." + region + ".amazonaws.com"
+}
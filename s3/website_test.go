@@ -0,0 +1,120 @@
+package s3_test
+
+import (
+	"encoding/xml"
+
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/s3"
+	. "gopkg.in/check.v1"
+)
+
+// websiteExample holds the configuration of GetWebsiteResultDump.
+var websiteExample = s3.WebsiteConfiguration{
+	IndexDocumentSuffix: "index.html",
+	ErrorDocumentKey:    "error.html",
+	RoutingRules: &[]s3.RoutingRule{{
+		ConditionKeyPrefixEquals:     "docs/",
+		RedirectReplaceKeyPrefixWith: "documents/",
+	}, {
+		ConditionHttpErrorCodeReturnedEquals: "404",
+		RedirectProtocol:                     "https",
+		RedirectHostName:                     "example.com",
+		RedirectReplaceKeyWith:               "not-found.html",
+		RedirectHttpRedirectCode:             "302",
+	}},
+}
+
+func (s *S) TestPutWebsite(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.PutWebsite(websiteExample)
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "website=")
+	c.Assert(req.Header["Content-Md5"], HasLen, 1)
+
+	// The body holds the same configuration as the fixture
+	var sent, expected s3.WebsiteConfiguration
+	c.Assert(xml.Unmarshal([]byte(readAll(req.Body)), &sent), IsNil)
+	c.Assert(xml.Unmarshal([]byte(GetWebsiteResultDump), &expected), IsNil)
+	c.Assert(sent, DeepEquals, expected)
+}
+
+func (s *S) TestPutWebsiteRedirectAll(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.PutWebsite(s3.WebsiteConfiguration{
+		RedirectAllRequestsTo: &s3.RedirectAllRequestsTo{HostName: "example.com", Protocol: "https"},
+	})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(readAll(req.Body), Equals, xml.Header+`<WebsiteConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`+
+		`<RedirectAllRequestsTo><HostName>example.com</HostName><Protocol>https</Protocol></RedirectAllRequestsTo></WebsiteConfiguration>`)
+}
+
+func (s *S) TestGetWebsite(c *C) {
+	testServer.Response(200, nil, GetWebsiteResultDump)
+
+	b := s.s3.Bucket("sample")
+	config, err := b.GetWebsite()
+	c.Assert(err, IsNil)
+	c.Assert(config.IndexDocumentSuffix, Equals, websiteExample.IndexDocumentSuffix)
+	c.Assert(config.ErrorDocumentKey, Equals, websiteExample.ErrorDocumentKey)
+	c.Assert(config.RedirectAllRequestsTo, IsNil)
+	c.Assert(*config.RoutingRules, DeepEquals, *websiteExample.RoutingRules)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "GET")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "website=")
+}
+
+func (s *S) TestGetWebsiteNotConfigured(c *C) {
+	testServer.Response(404, nil, NoSuchWebsiteConfigurationErrorDump)
+
+	b := s.s3.Bucket("sample")
+	config, err := b.GetWebsite()
+	c.Assert(err, IsNil)
+	c.Assert(config.IndexDocumentSuffix, Equals, "")
+	c.Assert(config.RoutingRules, IsNil)
+}
+
+func (s *S) TestDelWebsite(c *C) {
+	testServer.Response(204, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.DelWebsite()
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "DELETE")
+	c.Assert(req.URL.Path, Equals, "/sample/")
+	c.Assert(req.URL.RawQuery, Equals, "website=")
+}
+
+func (s *S) TestWebsiteEndpoint(c *C) {
+	c.Assert(s3.New(aws.Auth{}, aws.USEast).Bucket("sample").WebsiteEndpoint(), Equals, "sample.s3-website-us-east-1.amazonaws.com")
+	c.Assert(s3.New(aws.Auth{}, aws.EUCentral).Bucket("sample").WebsiteEndpoint(), Equals, "sample.s3-website.eu-central-1.amazonaws.com")
+}
+
+func (s *S) TestPutWebsiteRuleWithoutCondition(c *C) {
+	testServer.Response(200, nil, "")
+
+	b := s.s3.Bucket("sample")
+	err := b.PutWebsite(s3.WebsiteConfiguration{
+		IndexDocumentSuffix: "index.html",
+		RoutingRules:        &[]s3.RoutingRule{{RedirectHostName: "example.com"}},
+	})
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(readAll(req.Body), Equals, xml.Header+`<WebsiteConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`+
+		`<IndexDocument><Suffix>index.html</Suffix></IndexDocument>`+
+		`<RoutingRules><RoutingRule><Redirect><HostName>example.com</HostName></Redirect></RoutingRule></RoutingRules></WebsiteConfiguration>`)
+}
@@ -0,0 +1,141 @@
+package sqs
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// IntegrityError is returned when the MD5 digest of the message
+// attributes SQS reports for a message doesn't match the attributes sent
+// or received, or the digest of the body of a message sent doesn't match
+// it.
+type IntegrityError struct {
+	// Id is the MessageId of the message, or the id of its batch entry.
+	Id string
+
+	// Expected and Actual are the hex encoded MD5 digests reported by SQS
+	// and computed from the attributes, or from the body if Body is set.
+	Expected string
+	Actual   string
+	Body     bool
+}
+
+func (e *IntegrityError) Error() string {
+	if e.Body {
+		return fmt.Sprintf("sqs: integrity check of the body of message %q failed: SQS has MD5 %s, body has %s", e.Id, e.Expected, e.Actual)
+	}
+	return fmt.Sprintf("sqs: integrity check of the attributes of message %q failed: SQS has MD5 %s, attributes have %s", e.Id, e.Expected, e.Actual)
+}
+
+// StringAttribute returns a String message attribute value.
+func StringAttribute(s string) MessageAttributeValue {
+	return MessageAttributeValue{DataType: "String", StringValue: s}
+}
+
+// BinaryAttribute returns a Binary message attribute value.
+func BinaryAttribute(b []byte) MessageAttributeValue {
+	return MessageAttributeValue{DataType: "Binary", BinaryValue: b}
+}
+
+// isBinary reports whether the attribute value is transported as bytes,
+// as the Binary type and its custom subtypes such as Binary.gif are,
+// rather than as a string as String and Number are.
+func (v *MessageAttributeValue) isBinary() bool {
+	return v.DataType == "Binary" || strings.HasPrefix(v.DataType, "Binary.")
+}
+
+// MessageAttributesMD5 returns the hex encoded MD5 digest of attrs, which
+// SQS returns as MD5OfMessageAttributes. The names, data types and values
+// of the attributes are digested in the order of the names, each prefixed
+// by its length, and the values by their transport type.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/SQSDeveloperGuide/sqs-message-metadata.html for details.
+func MessageAttributesMD5(attrs map[string]MessageAttributeValue) string {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := md5.New()
+	write := func(b []byte) {
+		var n [4]byte
+		binary.BigEndian.PutUint32(n[:], uint32(len(b)))
+		h.Write(n[:])
+		h.Write(b)
+	}
+	for _, name := range names {
+		v := attrs[name]
+		write([]byte(name))
+		write([]byte(v.DataType))
+		if v.isBinary() {
+			h.Write([]byte{2})
+			write(v.BinaryValue)
+		} else {
+			h.Write([]byte{1})
+			write([]byte(v.StringValue))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// messageAttributesSize returns the size of attrs, which counts against
+// the size of the message.
+func messageAttributesSize(attrs map[string]MessageAttributeValue) int {
+	n := 0
+	for name, v := range attrs {
+		n += len(name) + len(v.DataType) + len(v.StringValue) + len(v.BinaryValue)
+	}
+	return n
+}
+
+// addMessageAttributeParams adds the parameters of attrs, in the order of
+// their names, prefixed by prefix.
+func addMessageAttributeParams(params map[string]string, prefix string, attrs map[string]MessageAttributeValue) {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		v := attrs[name]
+		attrPrefix := fmt.Sprintf("%sMessageAttribute.%d.", prefix, i+1)
+		params[attrPrefix+"Name"] = name
+		params[attrPrefix+"Value.DataType"] = v.DataType
+		if v.isBinary() {
+			params[attrPrefix+"Value.BinaryValue"] = base64.StdEncoding.EncodeToString(v.BinaryValue)
+		} else {
+			params[attrPrefix+"Value.StringValue"] = v.StringValue
+		}
+	}
+}
+
+// checkAttributesMD5 checks that the digest SQS has of the attributes of a
+// message matches attrs. Messages without attributes aren't checked.
+func checkAttributesMD5(id, expected string, attrs map[string]MessageAttributeValue) error {
+	if len(attrs) == 0 || expected == "" {
+		return nil
+	}
+	if actual := MessageAttributesMD5(attrs); actual != expected {
+		return &IntegrityError{Id: id, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// checkBodyMD5 checks that the digest SQS has of the body of a message
+// sent matches it.
+func checkBodyMD5(id, expected, body string) error {
+	if expected == "" {
+		return nil
+	}
+	sum := md5.Sum([]byte(body))
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return &IntegrityError{id, expected, actual, true}
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package sqs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+)
+
+// ConsumerBackoff is the default backoff of a Consumer between receives
+// which failed.
+var ConsumerBackoff = aws.RetryPolicy{
+	BaseDelay: time.Second,
+	MaxDelay:  time.Minute,
+	Jitter:    0.2,
+}
+
+// consumerQueue holds the calls of a Queue a Consumer makes.
+type consumerQueue interface {
+	ReceiveMessageWithOptions(options *ReceiveMessageOptions) (*ReceiveMessageResponse, error)
+	DeleteMessage(msg *Message) (*DeleteMessageResponse, error)
+	ChangeMessageVisibility(msg *Message, visibilityTimeout int) (*ChangeMessageVisibilityResponse, error)
+}
+
+// Consumer receives the messages of a queue and calls Handle with them,
+// with up to Concurrency handlers running at once. The messages Handle
+// returns nil for are deleted. The other ones are received again once
+// their visibility timeout is over, or at once with ReleaseFailed.
+//
+// The visibility of the messages being handled is extended every
+// HeartbeatInterval, so that the messages of handlers running longer than
+// the visibility timeout aren't received again meanwhile.
+type Consumer struct {
+	Queue  *Queue
+	Handle func(msg *Message) error
+
+	// Concurrency is the most handlers running at once, 1 if zero.
+	Concurrency int
+
+	// VisibilityTimeout is the number of seconds the messages are hidden
+	// when received, and by every heartbeat. It is 30 if zero.
+	VisibilityTimeout int
+
+	// HeartbeatInterval is the time between the extensions of the
+	// visibility of a message being handled, half VisibilityTimeout if
+	// zero.
+	HeartbeatInterval time.Duration
+
+	// WaitTimeSeconds is the duration of the long polls for messages, 20
+	// if zero. Stopping the consumer waits for the poll in progress.
+	WaitTimeSeconds int
+
+	// ReleaseFailed makes the messages Handle fails for visible again at
+	// once, rather than once their visibility timeout is over.
+	ReleaseFailed bool
+
+	// The attributes received with the messages, as in
+	// ReceiveMessageOptions.
+	AttributeNames        []string
+	MessageAttributeNames []string
+
+	// Backoff is the backoff between failed receives, ConsumerBackoff if
+	// nil.
+	Backoff *aws.RetryPolicy
+
+	// OnError is called, if set, with the errors of the calls to the
+	// queue and of Handle. msg is nil for receive errors.
+	OnError func(msg *Message, err error)
+
+	queue consumerQueue
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	stopped bool
+	done    chan struct{}
+}
+
+func (c *Consumer) init() {
+	if c.stop == nil {
+		c.stop = make(chan struct{})
+	}
+}
+
+// Stop makes Run return, and waits for it to do so once the handlers in
+// progress are over. A stopped consumer can't be run again.
+func (c *Consumer) Stop() {
+	c.mu.Lock()
+	c.init()
+	if !c.stopped {
+		close(c.stop)
+		c.stopped = true
+	}
+	done := c.done
+	c.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// Run receives and handles messages until the consumer is stopped, or ctx
+// is done, and returns once the handlers in progress are over. It returns
+// nil if stopped and ctx.Err() otherwise. The messages of the receive in
+// progress when stopping are made visible again without being handled.
+func (c *Consumer) Run(ctx context.Context) error {
+	c.mu.Lock()
+	c.init()
+	stop := c.stop
+	done := make(chan struct{})
+	c.done = done
+	c.mu.Unlock()
+	defer close(done)
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	queue := c.queue
+	if queue == nil {
+		queue = c.Queue
+	}
+	concurrency := c.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	backoff := c.Backoff
+	if backoff == nil {
+		backoff = &ConsumerBackoff
+	}
+	waitTime := c.WaitTimeSeconds
+	if waitTime == 0 {
+		waitTime = 20
+	}
+
+	// Each handler running holds a slot
+	slots := make(chan struct{}, concurrency)
+	var handlers sync.WaitGroup
+	for attempt := 0; ; {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		n := 1
+		for n < concurrency && n < MaxBatchEntries {
+			select {
+			case slots <- struct{}{}:
+				n++
+				continue
+			default:
+			}
+			break
+		}
+
+		resp, err := queue.ReceiveMessageWithOptions(&ReceiveMessageOptions{
+			MaxNumberOfMessages:   n,
+			VisibilityTimeout:     c.visibilityTimeout(),
+			WaitTimeSeconds:       waitTime,
+			AttributeNames:        c.AttributeNames,
+			MessageAttributeNames: c.MessageAttributeNames,
+		})
+		var msgs []Message
+		if err == nil {
+			msgs = resp.Messages
+		}
+		for i := len(msgs); i < n; i++ {
+			<-slots
+		}
+		if err != nil {
+			c.report(nil, err)
+			select {
+			case <-time.After(backoff.Delay(attempt)):
+			case <-ctx.Done():
+			}
+			attempt++
+			continue
+		}
+		attempt = 0
+
+		for i := range msgs {
+			msg := &msgs[i]
+			if ctx.Err() != nil {
+				c.release(queue, msg)
+				<-slots
+				continue
+			}
+			handlers.Add(1)
+			go func() {
+				defer handlers.Done()
+				c.handle(queue, msg)
+				<-slots
+			}()
+		}
+	}
+	handlers.Wait()
+
+	select {
+	case <-stop:
+		return nil
+	default:
+		return parent.Err()
+	}
+}
+
+func (c *Consumer) visibilityTimeout() int {
+	if c.VisibilityTimeout == 0 {
+		return 30
+	}
+	return c.VisibilityTimeout
+}
+
+func (c *Consumer) report(msg *Message, err error) {
+	if c.OnError != nil {
+		c.OnError(msg, err)
+	}
+}
+
+// release makes msg visible again.
+func (c *Consumer) release(queue consumerQueue, msg *Message) {
+	if _, err := queue.ChangeMessageVisibility(msg, 0); err != nil {
+		c.report(msg, err)
+	}
+}
+
+// handle calls Handle with msg, extending its visibility meanwhile, and
+// deletes it if handled.
+func (c *Consumer) handle(queue consumerQueue, msg *Message) {
+	interval := c.HeartbeatInterval
+	if interval <= 0 {
+		interval = time.Duration(c.visibilityTimeout()) * time.Second / 2
+	}
+	handled := make(chan struct{})
+	heartbeats := make(chan struct{})
+	go func() {
+		defer close(heartbeats)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := queue.ChangeMessageVisibility(msg, c.visibilityTimeout()); err != nil {
+					c.report(msg, err)
+				}
+			case <-handled:
+				return
+			}
+		}
+	}()
+
+	err := c.Handle(msg)
+	close(handled)
+	<-heartbeats
+
+	if err != nil {
+		c.report(msg, err)
+		if c.ReleaseFailed {
+			c.release(queue, msg)
+		}
+		return
+	}
+	if _, err := queue.DeleteMessage(msg); err != nil {
+		c.report(msg, err)
+	}
+}
@@ -0,0 +1,306 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&ConsumerS{})
+
+type ConsumerS struct{}
+
+// fakeQueue is a queue kept in memory, whose visibility timeouts are in
+// units of unit rather than seconds.
+type fakeQueue struct {
+	unit time.Duration
+
+	mu         sync.Mutex
+	messages   []*fakeMessage
+	receiveErr int
+	receives   int
+	handles    int
+	deleted    []string
+	visibility []string
+}
+
+type fakeMessage struct {
+	Message
+	visibleAt time.Time
+	deleted   bool
+}
+
+func newFakeQueue(bodies ...string) *fakeQueue {
+	q := &fakeQueue{unit: 10 * time.Millisecond}
+	for i, body := range bodies {
+		q.messages = append(q.messages, &fakeMessage{Message: Message{MessageId: fmt.Sprint("m", i), Body: body}})
+	}
+	return q
+}
+
+func (q *fakeQueue) ReceiveMessageWithOptions(options *ReceiveMessageOptions) (*ReceiveMessageResponse, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.receiveErr > 0 {
+		q.receiveErr--
+		return nil, errors.New("receive failed")
+	}
+	q.receives++
+	resp := &ReceiveMessageResponse{}
+	now := time.Now()
+	for _, m := range q.messages {
+		if len(resp.Messages) == options.MaxNumberOfMessages {
+			break
+		}
+		if m.deleted || now.Before(m.visibleAt) {
+			continue
+		}
+		q.handles++
+		m.ReceiptHandle = fmt.Sprint(m.MessageId, "-", q.handles)
+		m.ApproximateReceiveCount++
+		m.visibleAt = now.Add(time.Duration(options.VisibilityTimeout) * q.unit)
+		resp.Messages = append(resp.Messages, m.Message)
+	}
+	if len(resp.Messages) == 0 {
+		// A short long poll
+		q.mu.Unlock()
+		time.Sleep(q.unit)
+		q.mu.Lock()
+	}
+	return resp, nil
+}
+
+// message returns the message with the receipt handle of msg, or an error
+// if the handle is no longer valid.
+func (q *fakeQueue) message(msg *Message) (*fakeMessage, error) {
+	for _, m := range q.messages {
+		if m.ReceiptHandle == msg.ReceiptHandle && !m.deleted {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid receipt handle %s", msg.ReceiptHandle)
+}
+
+func (q *fakeQueue) DeleteMessage(msg *Message) (*DeleteMessageResponse, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	m, err := q.message(msg)
+	if err != nil {
+		return nil, err
+	}
+	m.deleted = true
+	q.deleted = append(q.deleted, m.Body)
+	return &DeleteMessageResponse{}, nil
+}
+
+func (q *fakeQueue) ChangeMessageVisibility(msg *Message, visibilityTimeout int) (*ChangeMessageVisibilityResponse, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	m, err := q.message(msg)
+	if err != nil {
+		return nil, err
+	}
+	m.visibleAt = time.Now().Add(time.Duration(visibilityTimeout) * q.unit)
+	q.visibility = append(q.visibility, fmt.Sprint(m.Body, "=", visibilityTimeout))
+	return &ChangeMessageVisibilityResponse{}, nil
+}
+
+// waitDeleted waits until n messages of q were deleted.
+func (q *fakeQueue) waitDeleted(c *C, n int) {
+	for i := 0; i < 500; i++ {
+		q.mu.Lock()
+		deleted := len(q.deleted)
+		q.mu.Unlock()
+		if deleted >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	c.Fatalf("%d messages were not deleted in time", n)
+}
+
+// run runs consumer on q until stopped.
+func run(consumer *Consumer, q *fakeQueue) <-chan error {
+	consumer.queue = q
+	if consumer.VisibilityTimeout == 0 {
+		consumer.VisibilityTimeout = 10
+	}
+	if consumer.HeartbeatInterval == 0 {
+		consumer.HeartbeatInterval = time.Hour
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- consumer.Run(context.Background()) }()
+	return errc
+}
+
+func (s *ConsumerS) TestConsumerDeletesHandled(c *C) {
+	q := newFakeQueue("a", "b", "c", "d", "e")
+	var mu sync.Mutex
+	var handled []string
+	consumer := &Consumer{Concurrency: 3, Handle: func(msg *Message) error {
+		mu.Lock()
+		handled = append(handled, msg.Body)
+		mu.Unlock()
+		return nil
+	}}
+	errc := run(consumer, q)
+
+	q.waitDeleted(c, 5)
+	consumer.Stop()
+	c.Assert(<-errc, IsNil)
+	c.Assert(handled, HasLen, 5)
+	c.Assert(q.deleted, HasLen, 5)
+	c.Assert(q.handles, Equals, 5)
+	c.Assert(q.visibility, HasLen, 0)
+}
+
+func (s *ConsumerS) TestConsumerRedelivery(c *C) {
+	q := newFakeQueue("a", "b")
+	var mu sync.Mutex
+	failures := 0
+	var errs []error
+	consumer := &Consumer{
+		VisibilityTimeout: 5,
+		Handle: func(msg *Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if msg.Body == "b" && failures < 2 {
+				failures++
+				return errors.New("handler failed")
+			}
+			return nil
+		},
+		OnError: func(msg *Message, err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		},
+	}
+	errc := run(consumer, q)
+
+	// The failed message is received again once its visibility timeout
+	// is over
+	q.waitDeleted(c, 2)
+	consumer.Stop()
+	c.Assert(<-errc, IsNil)
+	c.Assert(q.deleted, DeepEquals, []string{"a", "b"})
+	c.Assert(q.messages[1].ApproximateReceiveCount, Equals, 3)
+	c.Assert(q.visibility, HasLen, 0)
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errs[0], ErrorMatches, "handler failed")
+}
+
+func (s *ConsumerS) TestConsumerReleaseFailed(c *C) {
+	q := newFakeQueue("a")
+	failed := false
+	consumer := &Consumer{
+		VisibilityTimeout: 1000,
+		ReleaseFailed:     true,
+		Handle: func(msg *Message) error {
+			if !failed {
+				failed = true
+				return errors.New("handler failed")
+			}
+			return nil
+		},
+	}
+	errc := run(consumer, q)
+
+	// The message is received again long before its visibility timeout
+	q.waitDeleted(c, 1)
+	consumer.Stop()
+	c.Assert(<-errc, IsNil)
+	c.Assert(q.visibility, DeepEquals, []string{"a=0"})
+	c.Assert(q.messages[0].ApproximateReceiveCount, Equals, 2)
+}
+
+func (s *ConsumerS) TestConsumerHeartbeat(c *C) {
+	q := newFakeQueue("slow")
+	consumer := &Consumer{
+		Concurrency:       2,
+		VisibilityTimeout: 5,
+		HeartbeatInterval: 15 * time.Millisecond,
+		Handle: func(msg *Message) error {
+			time.Sleep(150 * time.Millisecond)
+			return nil
+		},
+	}
+	errc := run(consumer, q)
+
+	// The handler runs for three times the visibility timeout, and the
+	// message isn't received again meanwhile by the other handler
+	q.waitDeleted(c, 1)
+	consumer.Stop()
+	c.Assert(<-errc, IsNil)
+	c.Assert(q.handles, Equals, 1)
+	c.Assert(len(q.visibility) >= 5, Equals, true)
+	c.Assert(q.visibility[0], Equals, "slow=5")
+}
+
+func (s *ConsumerS) TestConsumerStopWaitsForHandlers(c *C) {
+	q := newFakeQueue("a")
+	started := make(chan bool)
+	release := make(chan bool)
+	consumer := &Consumer{Handle: func(msg *Message) error {
+		started <- true
+		<-release
+		return nil
+	}}
+	errc := run(consumer, q)
+	<-started
+
+	stopped := make(chan bool)
+	go func() {
+		consumer.Stop()
+		stopped <- true
+	}()
+	select {
+	case <-stopped:
+		c.Fatalf("Stop returned with a handler in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release <- true
+	<-stopped
+	c.Assert(<-errc, IsNil)
+	c.Assert(q.deleted, DeepEquals, []string{"a"})
+}
+
+func (s *ConsumerS) TestConsumerReceiveErrors(c *C) {
+	q := newFakeQueue("a")
+	q.receiveErr = 2
+	var receiveErrs int
+	consumer := &Consumer{
+		Backoff: &aws.RetryPolicy{BaseDelay: time.Millisecond},
+		Handle:  func(msg *Message) error { return nil },
+		OnError: func(msg *Message, err error) {
+			c.Check(msg, IsNil)
+			c.Check(err, ErrorMatches, "receive failed")
+			receiveErrs++
+		},
+	}
+	errc := run(consumer, q)
+
+	q.waitDeleted(c, 1)
+	consumer.Stop()
+	c.Assert(<-errc, IsNil)
+	c.Assert(receiveErrs, Equals, 2)
+}
+
+func (s *ConsumerS) TestConsumerContext(c *C) {
+	q := newFakeQueue()
+	consumer := &Consumer{queue: q, Handle: func(msg *Message) error { return nil }}
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- consumer.Run(ctx) }()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	c.Assert(<-errc, Equals, context.Canceled)
+	c.Assert(q.receives > 0, Equals, true)
+}
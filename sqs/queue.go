@@ -0,0 +1,127 @@
+package sqs
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// QueueAttributes holds the attributes of a queue. The attributes from
+// QueueArn on are set by SQS and only returned by Attributes.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_GetQueueAttributes.html for details.
+type QueueAttributes struct {
+	DelaySeconds                  int
+	MaximumMessageSize            int // In bytes, from 1024 to 262144
+	MessageRetentionPeriod        int // In seconds, from 60 to 1209600
+	ReceiveMessageWaitTimeSeconds int
+	VisibilityTimeout             int
+	Policy                        string // The JSON access policy of the queue
+	RedrivePolicy                 *RedrivePolicy
+
+	// KmsMasterKeyId is the KMS key the messages are encrypted with, or
+	// empty if they aren't.
+	KmsMasterKeyId               string
+	KmsDataKeyReusePeriodSeconds int
+
+	FifoQueue                 bool
+	ContentBasedDeduplication bool
+
+	QueueArn                              string
+	ApproximateNumberOfMessages           int
+	ApproximateNumberOfMessagesNotVisible int
+	ApproximateNumberOfMessagesDelayed    int
+	CreatedTimestamp                      time.Time
+	LastModifiedTimestamp                 time.Time
+}
+
+// Attributes returns all the attributes of the queue.
+func (q *Queue) Attributes() (*QueueAttributes, error) {
+	resp, err := q.GetQueueAttributes("All")
+	if err != nil {
+		return nil, err
+	}
+	attrs := &QueueAttributes{}
+	for _, attr := range resp.Attributes {
+		n, _ := strconv.Atoi(attr.Value)
+		switch attr.Name {
+		case "DelaySeconds":
+			attrs.DelaySeconds = n
+		case "MaximumMessageSize":
+			attrs.MaximumMessageSize = n
+		case "MessageRetentionPeriod":
+			attrs.MessageRetentionPeriod = n
+		case "ReceiveMessageWaitTimeSeconds":
+			attrs.ReceiveMessageWaitTimeSeconds = n
+		case "VisibilityTimeout":
+			attrs.VisibilityTimeout = n
+		case "Policy":
+			attrs.Policy = attr.Value
+		case "RedrivePolicy":
+			if attr.Value != "" {
+				attrs.RedrivePolicy = &RedrivePolicy{}
+				if err := json.Unmarshal([]byte(attr.Value), attrs.RedrivePolicy); err != nil {
+					return nil, err
+				}
+			}
+		case "KmsMasterKeyId":
+			attrs.KmsMasterKeyId = attr.Value
+		case "KmsDataKeyReusePeriodSeconds":
+			attrs.KmsDataKeyReusePeriodSeconds = n
+		case "FifoQueue":
+			attrs.FifoQueue = attr.Value == "true"
+		case "ContentBasedDeduplication":
+			attrs.ContentBasedDeduplication = attr.Value == "true"
+		case "QueueArn":
+			attrs.QueueArn = attr.Value
+		case "ApproximateNumberOfMessages":
+			attrs.ApproximateNumberOfMessages = n
+		case "ApproximateNumberOfMessagesNotVisible":
+			attrs.ApproximateNumberOfMessagesNotVisible = n
+		case "ApproximateNumberOfMessagesDelayed":
+			attrs.ApproximateNumberOfMessagesDelayed = n
+		case "CreatedTimestamp":
+			attrs.CreatedTimestamp = time.Unix(int64(n), 0)
+		case "LastModifiedTimestamp":
+			attrs.LastModifiedTimestamp = time.Unix(int64(n), 0)
+		}
+	}
+	return attrs, nil
+}
+
+// SetAttributes sets all the attributes of the queue which may be changed
+// to attrs, which are meant to be the Attributes of the queue with the
+// changes to make. An empty Policy, RedrivePolicy or KmsMasterKeyId
+// removes the attribute. MaximumMessageSize and MessageRetentionPeriod are
+// left as they were if zero, and ContentBasedDeduplication is only set
+// for FIFO queues.
+func (q *Queue) SetAttributes(attrs *QueueAttributes) (*SetQueueAttributesResponse, error) {
+	values := map[string]string{
+		"DelaySeconds":                  strconv.Itoa(attrs.DelaySeconds),
+		"ReceiveMessageWaitTimeSeconds": strconv.Itoa(attrs.ReceiveMessageWaitTimeSeconds),
+		"VisibilityTimeout":             strconv.Itoa(attrs.VisibilityTimeout),
+		"Policy":                        attrs.Policy,
+		"RedrivePolicy":                 "",
+		"KmsMasterKeyId":                attrs.KmsMasterKeyId,
+	}
+	if attrs.MaximumMessageSize != 0 {
+		values["MaximumMessageSize"] = strconv.Itoa(attrs.MaximumMessageSize)
+	}
+	if attrs.MessageRetentionPeriod != 0 {
+		values["MessageRetentionPeriod"] = strconv.Itoa(attrs.MessageRetentionPeriod)
+	}
+	if attrs.RedrivePolicy != nil {
+		policy, err := json.Marshal(attrs.RedrivePolicy)
+		if err != nil {
+			return nil, err
+		}
+		values["RedrivePolicy"] = string(policy)
+	}
+	if attrs.KmsMasterKeyId != "" && attrs.KmsDataKeyReusePeriodSeconds != 0 {
+		values["KmsDataKeyReusePeriodSeconds"] = strconv.Itoa(attrs.KmsDataKeyReusePeriodSeconds)
+	}
+	if attrs.FifoQueue {
+		values["ContentBasedDeduplication"] = strconv.FormatBool(attrs.ContentBasedDeduplication)
+	}
+	return q.SetQueueAttributes(values)
+}
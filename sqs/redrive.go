@@ -0,0 +1,109 @@
+package sqs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// RedrivePolicy moves the messages of a queue received more than
+// MaxReceiveCount times to the dead-letter queue with the ARN
+// DeadLetterTargetArn.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/SQSDeveloperGuide/sqs-dead-letter-queues.html for details.
+type RedrivePolicy struct {
+	MaxReceiveCount     int    `json:"maxReceiveCount"`
+	DeadLetterTargetArn string `json:"deadLetterTargetArn"`
+}
+
+// UnmarshalJSON decodes a redrive policy, which SQS gives with
+// maxReceiveCount as a number or as a string.
+func (p *RedrivePolicy) UnmarshalJSON(data []byte) error {
+	var policy struct {
+		MaxReceiveCount     json.RawMessage `json:"maxReceiveCount"`
+		DeadLetterTargetArn string          `json:"deadLetterTargetArn"`
+	}
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return err
+	}
+	count := string(policy.MaxReceiveCount)
+	if unquoted, err := strconv.Unquote(count); err == nil {
+		count = unquoted
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return fmt.Errorf("sqs: invalid maxReceiveCount %s in redrive policy", policy.MaxReceiveCount)
+	}
+	*p = RedrivePolicy{n, policy.DeadLetterTargetArn}
+	return nil
+}
+
+// attribute returns the value of the queue attribute name, which is
+// empty if it isn't set.
+func (q *Queue) attribute(name string) (string, error) {
+	resp, err := q.GetQueueAttributes(name)
+	if err != nil {
+		return "", err
+	}
+	for _, attr := range resp.Attributes {
+		if attr.Name == name {
+			return attr.Value, nil
+		}
+	}
+	return "", nil
+}
+
+// Arn returns the ARN of the queue, as dead-letter queues and other
+// policies refer to it.
+func (q *Queue) Arn() (string, error) {
+	return q.attribute("QueueArn")
+}
+
+// SetRedrivePolicy sets the redrive policy of the queue, which moves
+// its messages received more than maxReceiveCount times to the
+// dead-letter queue with the ARN deadLetterTargetArn, as given by the Arn
+// of that queue.
+func (q *Queue) SetRedrivePolicy(maxReceiveCount int, deadLetterTargetArn string) (*SetQueueAttributesResponse, error) {
+	policy, err := json.Marshal(RedrivePolicy{maxReceiveCount, deadLetterTargetArn})
+	if err != nil {
+		return nil, err
+	}
+	return q.SetQueueAttributes(map[string]string{"RedrivePolicy": string(policy)})
+}
+
+// RemoveRedrivePolicy removes the redrive policy of the queue, so that
+// its messages are no longer moved to a dead-letter queue.
+func (q *Queue) RemoveRedrivePolicy() (*SetQueueAttributesResponse, error) {
+	return q.SetQueueAttributes(map[string]string{"RedrivePolicy": ""})
+}
+
+// GetRedrivePolicy returns the redrive policy of the queue, or nil if it
+// has none.
+func (q *Queue) GetRedrivePolicy() (*RedrivePolicy, error) {
+	value, err := q.attribute("RedrivePolicy")
+	if err != nil || value == "" {
+		return nil, err
+	}
+	policy := &RedrivePolicy{}
+	if err := json.Unmarshal([]byte(value), policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+type ListDeadLetterSourceQueuesResponse struct {
+	QueueUrls        []string `xml:"ListDeadLetterSourceQueuesResult>QueueUrl"`
+	ResponseMetadata ResponseMetadata
+}
+
+// ListDeadLetterSourceQueues returns the URLs of the queues which have the
+// queue as their dead-letter queue.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_ListDeadLetterSourceQueues.html for details.
+func (q *Queue) ListDeadLetterSourceQueues() (resp *ListDeadLetterSourceQueuesResponse, err error) {
+	resp = &ListDeadLetterSourceQueuesResponse{}
+	params := makeParams("ListDeadLetterSourceQueues")
+
+	err = q.SQS.query(q.Url, params, resp)
+	return
+}
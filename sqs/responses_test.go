@@ -109,6 +109,7 @@ var TestReceiveMessageXmlOK = `
           <BinaryValue>iVBORw0KGgoAAAANSUhEUgAAABIAAAASCAYAAABWzo5XAAABA0lEQVQ4T72UrQ4CMRCEewhyiiBPopBgcfAUSIICB88CDhRB8hTgsCBRyJMEdUFwZJpMs/3LHQlhVdPufJ1ut03UjyKJcR5zVc4umbW87eeqvVFBjTdJwP54D+4xGXVUCGiBxoOsJOCd9IKgRnnV8wAezrnRmwGcpKtCJ8UgJBNWLFNzVAOimyqIhElXGkQ3LmQ6fKrdqaW1cixhdKVBcEOBLEwViBugVv8B1elVuLYcoTea624drcl5LW4KTRsFhQpLtVzzQKGCh2DuHI8FvdVH7vGQKEPerHRjgegKMESsXgAgWBtu5D1a9BQWCXSrzx9BvjPPkRQR6IJcQNTRV/cvkj93DqUTWzVDIQAAAABJRU5ErkJggg==</BinaryValue>
         </Value>
       </MessageAttribute>
+      <MD5OfMessageAttributes>08e31730d133fd976258852e922b475b</MD5OfMessageAttributes>
     </Message>
   </ReceiveMessageResult>
 <ResponseMetadata>
@@ -194,3 +195,236 @@ var TestGetQueueAttributesXmlOK = `
   </ResponseMetadata>
 </GetQueueAttributesResponse>
 `
+
+var TestSetQueueAttributesXmlOK = `
+<SetQueueAttributesResponse>
+    <ResponseMetadata>
+        <RequestId>e5cca473-4fc0-4198-a451-8abb94d02c75</RequestId>
+    </ResponseMetadata>
+</SetQueueAttributesResponse>
+`
+
+var TestSendMessageBatchFailedXml = `
+<SendMessageBatchResponse>
+<SendMessageBatchResult>
+    <SendMessageBatchResultEntry>
+        <Id>test_msg_001</Id>
+        <MessageId>0a5231c7-8bff-4955-be2e-8dc7c50a25fa</MessageId>
+        <MD5OfMessageBody>0e024d309850c78cba5eabbeff7cae71</MD5OfMessageBody>
+    </SendMessageBatchResultEntry>
+    <BatchResultErrorEntry>
+        <Id>test_msg_002</Id>
+        <Code>InternalError</Code>
+        <Message>Internal error</Message>
+        <SenderFault>false</SenderFault>
+    </BatchResultErrorEntry>
+</SendMessageBatchResult>
+<ResponseMetadata>
+    <RequestId>ca1ad5d0-8271-408b-8d0f-1351bf547e74</RequestId>
+</ResponseMetadata>
+</SendMessageBatchResponse>
+`
+
+var TestDeleteMessageBatchFailedXml = `
+<DeleteMessageBatchResponse>
+    <DeleteMessageBatchResult>
+        <DeleteMessageBatchResultEntry>
+            <Id>id-1</Id>
+        </DeleteMessageBatchResultEntry>
+        <BatchResultErrorEntry>
+            <Id>msg-2</Id>
+            <Code>ReceiptHandleIsInvalid</Code>
+            <Message>The input receipt handle is invalid.</Message>
+            <SenderFault>true</SenderFault>
+        </BatchResultErrorEntry>
+    </DeleteMessageBatchResult>
+    <ResponseMetadata>
+        <RequestId>d6f86b7a-74d1-4439-b43f-196a1e29cd85</RequestId>
+    </ResponseMetadata>
+</DeleteMessageBatchResponse>
+`
+
+var TestChangeMessageVisibilityBatchXml = `
+<ChangeMessageVisibilityBatchResponse>
+    <ChangeMessageVisibilityBatchResult>
+        <ChangeMessageVisibilityBatchResultEntry>
+            <Id>change_visibility_msg_2</Id>
+        </ChangeMessageVisibilityBatchResultEntry>
+        <BatchResultErrorEntry>
+            <Id>change_visibility_msg_3</Id>
+            <Code>ReceiptHandleIsInvalid</Code>
+            <Message>The input receipt handle is invalid.</Message>
+            <SenderFault>true</SenderFault>
+        </BatchResultErrorEntry>
+    </ChangeMessageVisibilityBatchResult>
+    <ResponseMetadata>
+        <RequestId>ca9668f7-ab1b-4f7a-8859-f15747ab17a7</RequestId>
+    </ResponseMetadata>
+</ChangeMessageVisibilityBatchResponse>
+`
+
+var TestSendMessageAttributesXmlOK = `
+<SendMessageResponse>
+  <SendMessageResult>
+    <MD5OfMessageBody>fafb00f5732ab283681e124bf8747ed1</MD5OfMessageBody>
+    <MessageId>5fea7756-0ea4-451a-a703-a558b933e274</MessageId>
+    <MD5OfMessageAttributes>5b88d18574e1078692bfb04b5226508e</MD5OfMessageAttributes>
+  </SendMessageResult>
+  <ResponseMetadata>
+    <RequestId>27daac76-34dd-47df-bd01-1f6e873584a0</RequestId>
+  </ResponseMetadata>
+</SendMessageResponse>
+`
+
+var TestGetRedrivePolicyXmlOK = `
+<GetQueueAttributesResponse>
+  <GetQueueAttributesResult>
+    <Attribute>
+      <Name>RedrivePolicy</Name>
+      <Value>{"deadLetterTargetArn":"arn:aws:sqs:us-east-1:123456789012:testQueue-dlq","maxReceiveCount":"5"}</Value>
+    </Attribute>
+  </GetQueueAttributesResult>
+  <ResponseMetadata>
+    <RequestId>1ea71be5-b5a2-4f9d-b85a-945d8d08cd0b</RequestId>
+  </ResponseMetadata>
+</GetQueueAttributesResponse>
+`
+
+var TestGetQueueAttributesEmptyXmlOK = `
+<GetQueueAttributesResponse>
+  <GetQueueAttributesResult/>
+  <ResponseMetadata>
+    <RequestId>1ea71be5-b5a2-4f9d-b85a-945d8d08cd0b</RequestId>
+  </ResponseMetadata>
+</GetQueueAttributesResponse>
+`
+
+var TestListDeadLetterSourceQueuesXmlOK = `
+<ListDeadLetterSourceQueuesResponse>
+  <ListDeadLetterSourceQueuesResult>
+    <QueueUrl>http://sqs.us-east-1.amazonaws.com/123456789012/testQueue</QueueUrl>
+    <QueueUrl>http://sqs.us-east-1.amazonaws.com/123456789012/otherQueue</QueueUrl>
+  </ListDeadLetterSourceQueuesResult>
+  <ResponseMetadata>
+    <RequestId>8ffb921f-b85e-53d9-abcf-d8d0057f38fc</RequestId>
+  </ResponseMetadata>
+</ListDeadLetterSourceQueuesResponse>
+`
+
+var TestCreateFifoQueueXmlOK = `
+<CreateQueueResponse>
+  <CreateQueueResult>
+    <QueueUrl>http://sqs.us-east-1.amazonaws.com/123456789012/testQueue.fifo</QueueUrl>
+  </CreateQueueResult>
+  <ResponseMetadata>
+    <RequestId>7a62c49f-347e-4fc4-9331-6e8e7a96aa73</RequestId>
+  </ResponseMetadata>
+</CreateQueueResponse>
+`
+
+var TestSendMessageFifoXmlOK = `
+<SendMessageResponse>
+  <SendMessageResult>
+    <MD5OfMessageBody>fafb00f5732ab283681e124bf8747ed1</MD5OfMessageBody>
+    <MessageId>5fea7756-0ea4-451a-a703-a558b933e274</MessageId>
+    <SequenceNumber>18849496460467696128</SequenceNumber>
+  </SendMessageResult>
+  <ResponseMetadata>
+    <RequestId>27daac76-34dd-47df-bd01-1f6e873584a0</RequestId>
+  </ResponseMetadata>
+</SendMessageResponse>
+`
+
+var TestReceiveMessageFifoXmlOK = `
+<ReceiveMessageResponse>
+  <ReceiveMessageResult>
+    <Message>
+      <MessageId>5fea7756-0ea4-451a-a703-a558b933e274</MessageId>
+      <ReceiptHandle>MbZj6wDWli+JvwwJaBV+3dcjk2YW2vA3+STFFljTM8tJJg6HRG6PYSasuWXPJB+CwLj1FjgXUv1uSj1gUPAWV66FU/WeR4mq2OKpEGYWbnLmpRCJVAyeMjeU5ZBdtcQ+QEauMZc8ZRv37sIW2iJKq3M9MFx1YvV11A2x/KSbkJ0=</ReceiptHandle>
+      <MD5OfBody>fafb00f5732ab283681e124bf8747ed1</MD5OfBody>
+      <Body>This is a test message</Body>
+      <Attribute>
+        <Name>SequenceNumber</Name>
+        <Value>18849496460467696128</Value>
+      </Attribute>
+      <Attribute>
+        <Name>MessageGroupId</Name>
+        <Value>orders</Value>
+      </Attribute>
+      <Attribute>
+        <Name>MessageDeduplicationId</Name>
+        <Value>4c6c4d9e8d1f5b0e2a3f1b7c9d0e8f6a4b2c1d3e5f7a9b0c2d4e6f8a1b3c5d7e</Value>
+      </Attribute>
+    </Message>
+  </ReceiveMessageResult>
+  <ResponseMetadata>
+    <RequestId>b6633655-283d-45b4-aee4-4e84e0ae6afa</RequestId>
+  </ResponseMetadata>
+</ReceiveMessageResponse>
+`
+
+var TestPurgeQueueInProgressXmlError = `
+<ErrorResponse>
+  <Error>
+    <Type>Sender</Type>
+    <Code>AWS.SimpleQueueService.PurgeQueueInProgress</Code>
+    <Message>Only one PurgeQueue operation on testQueue is allowed every 60 seconds.</Message>
+    <Detail/>
+  </Error>
+  <RequestId>9e1bcd3b-5b6f-5ea4-8f4c-9fb7b01d5ff3</RequestId>
+</ErrorResponse>
+`
+
+var TestGetAllQueueAttributesXmlOK = `
+<GetQueueAttributesResponse>
+  <GetQueueAttributesResult>
+    <Attribute><Name>QueueArn</Name><Value>arn:aws:sqs:us-east-1:123456789012:testQueue.fifo</Value></Attribute>
+    <Attribute><Name>ApproximateNumberOfMessages</Name><Value>3</Value></Attribute>
+    <Attribute><Name>ApproximateNumberOfMessagesNotVisible</Name><Value>1</Value></Attribute>
+    <Attribute><Name>ApproximateNumberOfMessagesDelayed</Name><Value>2</Value></Attribute>
+    <Attribute><Name>CreatedTimestamp</Name><Value>1286771522</Value></Attribute>
+    <Attribute><Name>LastModifiedTimestamp</Name><Value>1286771600</Value></Attribute>
+    <Attribute><Name>VisibilityTimeout</Name><Value>30</Value></Attribute>
+    <Attribute><Name>MaximumMessageSize</Name><Value>8192</Value></Attribute>
+    <Attribute><Name>MessageRetentionPeriod</Name><Value>345600</Value></Attribute>
+    <Attribute><Name>DelaySeconds</Name><Value>5</Value></Attribute>
+    <Attribute><Name>ReceiveMessageWaitTimeSeconds</Name><Value>20</Value></Attribute>
+    <Attribute><Name>Policy</Name><Value>{"Version":"2012-10-17"}</Value></Attribute>
+    <Attribute><Name>RedrivePolicy</Name><Value>{"deadLetterTargetArn":"arn:aws:sqs:us-east-1:123456789012:dead.fifo","maxReceiveCount":5}</Value></Attribute>
+    <Attribute><Name>KmsMasterKeyId</Name><Value>alias/aws/sqs</Value></Attribute>
+    <Attribute><Name>KmsDataKeyReusePeriodSeconds</Name><Value>300</Value></Attribute>
+    <Attribute><Name>FifoQueue</Name><Value>true</Value></Attribute>
+    <Attribute><Name>ContentBasedDeduplication</Name><Value>true</Value></Attribute>
+  </GetQueueAttributesResult>
+  <ResponseMetadata>
+    <RequestId>1ea71be5-b5a2-4f9d-b85a-945d8d08cd0b</RequestId>
+  </ResponseMetadata>
+</GetQueueAttributesResponse>
+`
+
+var TestGetQueueUrlOfOwnerXmlOK = `
+<GetQueueUrlResponse>
+  <GetQueueUrlResult>
+    <QueueUrl>http://localhost:4455/999999999999/theirQueue</QueueUrl>
+  </GetQueueUrlResult>
+  <ResponseMetadata>
+    <RequestId>470a6f13-2ed9-4181-ad8a-2fdea142988e</RequestId>
+  </ResponseMetadata>
+</GetQueueUrlResponse>
+`
+
+var TestAddPermissionXmlOK = `
+<AddPermissionResponse>
+  <ResponseMetadata>
+    <RequestId>9a285199-c8d6-47c2-bdb2-314cb47d599d</RequestId>
+  </ResponseMetadata>
+</AddPermissionResponse>
+`
+
+var TestRemovePermissionXmlOK = `
+<RemovePermissionResponse>
+  <ResponseMetadata>
+    <RequestId>f8bdb362-6616-42c0-977a-ce9a8bcce3bb</RequestId>
+  </ResponseMetadata>
+</RemovePermissionResponse>
+`
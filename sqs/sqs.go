@@ -10,6 +10,7 @@
 package sqs
 
 import (
+	"encoding/base64"
 	"encoding/xml"
 	"errors"
 	"fmt"
@@ -19,6 +20,7 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -34,12 +36,31 @@ const debug = false
 type SQS struct {
 	aws.Auth
 	aws.Region
+
+	// Credentials, when not nil, provides the credentials used to sign
+	// each request instead of Auth, so that temporary credentials can be
+	// renewed without creating a new SQS.
+	Credentials aws.CredentialsProvider
+
+	// RetryPolicy is used to retry failed requests. It defaults to
+	// aws.DefaultRetryPolicy.
+	RetryPolicy *aws.RetryPolicy
+
 	private byte // Reserve the right of using private data.
-	Client  http.Client
+
+	// Client performs the requests. Its zero value behaves like
+	// http.DefaultClient, and its Timeout and Transport may be set to
+	// control timeouts, proxies and connection pooling.
+	Client http.Client
+
+	// clock corrects the time requests are signed with when the local
+	// clock is skewed.
+	clock aws.Clock
 }
 
 // NewFrom Create A new SQS Client given an access and secret Key
-// region must be one of "us.east, us.west, eu.west"
+// region must be one of "us.east, us.west, eu.west", or a region name
+// of aws.Regions such as "us-east-1"
 func NewFrom(accessKey, secretKey, region string) (*SQS, error) {
 
 	auth := aws.Auth{AccessKey: accessKey, SecretKey: secretKey}
@@ -65,7 +86,10 @@ func NewFrom(accessKey, secretKey, region string) (*SQS, error) {
 	case "cn.north", "cn.north.1":
 		aws_region = aws.CNNorth
 	default:
-		return nil, errors.New(fmt.Sprintf("Unknow/Unsupported region %s", region))
+		var ok bool
+		if aws_region, ok = aws.Regions[region]; !ok {
+			return nil, errors.New(fmt.Sprintf("Unknow/Unsupported region %s", region))
+		}
 	}
 
 	aws_sqs := New(auth, aws_region)
@@ -74,7 +98,7 @@ func NewFrom(accessKey, secretKey, region string) (*SQS, error) {
 
 // NewFrom Create A new SQS Client from an exisisting aws.Auth
 func New(auth aws.Auth, region aws.Region) *SQS {
-	return &SQS{auth, region, 0, http.Client{}}
+	return &SQS{Auth: auth, Region: region}
 }
 
 // Queue Reference to a Queue
@@ -110,10 +134,19 @@ type PurgeQueueResponse struct {
 	ResponseMetadata ResponseMetadata
 }
 
+type AddPermissionResponse struct {
+	ResponseMetadata ResponseMetadata
+}
+
+type RemovePermissionResponse struct {
+	ResponseMetadata ResponseMetadata
+}
+
 type SendMessageResponse struct {
 	MD5                    string `xml:"SendMessageResult>MD5OfMessageBody"`
 	MD5OfMessageAttributes string `xml:"SendMessageResult>MD5OfMessageAttributes"`
 	Id                     string `xml:"SendMessageResult>MessageId"`
+	SequenceNumber         string `xml:"SendMessageResult>SequenceNumber"`
 	ResponseMetadata       ResponseMetadata
 }
 
@@ -130,6 +163,72 @@ type Message struct {
 	Attribute              []Attribute        `xml:"Attribute"`
 	MessageAttribute       []MessageAttribute `xml:"MessageAttribute"`
 	MD5OfMessageAttributes string             `xml:"MD5OfMessageAttributes"`
+
+	// The message attributes of a received message by name, parsed
+	// from MessageAttribute, and its system attributes, parsed from
+	// Attribute. They are only set when the attributes were requested,
+	// which ReceiveMessage does for all of them.
+	MessageAttributes                map[string]MessageAttributeValue `xml:"-"`
+	SenderId                         string                           `xml:"-"`
+	SentTimestamp                    time.Time                        `xml:"-"`
+	ApproximateReceiveCount          int                              `xml:"-"`
+	ApproximateFirstReceiveTimestamp time.Time                        `xml:"-"`
+
+	// The system attributes of messages received from FIFO queues.
+	SequenceNumber         string `xml:"-"`
+	MessageGroupId         string `xml:"-"`
+	MessageDeduplicationId string `xml:"-"`
+}
+
+// UnmarshalXML decodes a message and parses its system attributes.
+func (m *Message) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type message Message
+	if err := d.DecodeElement((*message)(m), &start); err != nil {
+		return err
+	}
+	for i := range m.MessageAttribute {
+		attr := &m.MessageAttribute[i]
+		if attr.Value.isBinary() {
+			b, err := base64.StdEncoding.DecodeString(string(attr.Value.BinaryValue))
+			if err != nil {
+				return err
+			}
+			attr.Value.BinaryValue = b
+		}
+		if m.MessageAttributes == nil {
+			m.MessageAttributes = make(map[string]MessageAttributeValue)
+		}
+		m.MessageAttributes[attr.Name] = attr.Value
+	}
+	for _, attr := range m.Attribute {
+		switch attr.Name {
+		case "SenderId":
+			m.SenderId = attr.Value
+		case "SentTimestamp":
+			m.SentTimestamp = parseTimestamp(attr.Value)
+		case "ApproximateReceiveCount":
+			m.ApproximateReceiveCount, _ = strconv.Atoi(attr.Value)
+		case "ApproximateFirstReceiveTimestamp":
+			m.ApproximateFirstReceiveTimestamp = parseTimestamp(attr.Value)
+		case "SequenceNumber":
+			m.SequenceNumber = attr.Value
+		case "MessageGroupId":
+			m.MessageGroupId = attr.Value
+		case "MessageDeduplicationId":
+			m.MessageDeduplicationId = attr.Value
+		}
+	}
+	return nil
+}
+
+// parseTimestamp parses a time in milliseconds since the epoch, as SQS
+// gives the times of messages, or returns the zero time.
+func parseTimestamp(ms string) time.Time {
+	n, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(n/1000, n%1000*int64(time.Millisecond))
 }
 
 type Attribute struct {
@@ -142,6 +241,10 @@ type MessageAttribute struct {
 	Value MessageAttributeValue `xml:"Value"`
 }
 
+// MessageAttributeValue is the value of a message attribute. DataType is
+// String, Number or Binary, optionally followed by a custom type such as
+// Number.float. Binary values are in BinaryValue, and the others in
+// StringValue.
 type MessageAttributeValue struct {
 	DataType    string `xml:"DataType"`
 	BinaryValue []byte `xml:"BinaryValue"`
@@ -156,6 +259,10 @@ type ChangeMessageVisibilityResponse struct {
 	ResponseMetadata ResponseMetadata
 }
 
+type SetQueueAttributesResponse struct {
+	ResponseMetadata ResponseMetadata
+}
+
 type GetQueueAttributesResponse struct {
 	Attributes       []Attribute `xml:"GetQueueAttributesResult>Attribute"`
 	ResponseMetadata ResponseMetadata
@@ -166,29 +273,8 @@ type ResponseMetadata struct {
 	BoxUsage  float64
 }
 
-type Error struct {
-	StatusCode int
-	Code       string
-	Message    string
-	RequestId  string
-}
-
-func (err *Error) Error() string {
-	if err.Code == "" {
-		return err.Message
-	}
-	return fmt.Sprintf("%s (%s)", err.Message, err.Code)
-}
-
-func (err *Error) String() string {
-	return err.Message
-}
-
-type xmlErrors struct {
-	RequestId string
-	Errors    []Error `xml:"Errors>Error"`
-	Error     Error
-}
+// Error encapsulates an error returned by SQS.
+type Error = aws.Error
 
 // CreateQueue create a queue with a specific name
 func (s *SQS) CreateQueue(queueName string) (*Queue, error) {
@@ -203,7 +289,25 @@ func (s *SQS) CreateQueueWithTimeout(queueName string, timeout int) (*Queue, err
 	return s.CreateQueueWithAttributes(queueName, params)
 }
 
+// CreateQueueWithAttributes creates a queue with the given attributes. The
+// queues with names ending with ".fifo" are FIFO queues, which is set in
+// the FifoQueue attribute if it isn't. FIFO queues deduplicate messages by
+// the SHA-256 of their body if ContentBasedDeduplication is "true", and
+// by the MessageDeduplicationId of the messages otherwise.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_CreateQueue.html for details.
 func (s *SQS) CreateQueueWithAttributes(queueName string, attrs map[string]string) (q *Queue, err error) {
+	fifo := strings.HasSuffix(queueName, ".fifo")
+	if attrs["FifoQueue"] == "true" && !fifo {
+		return nil, fmt.Errorf("sqs: the name of FIFO queue %q must end with .fifo", queueName)
+	}
+	if fifo && attrs["FifoQueue"] == "" {
+		fifoAttrs := map[string]string{"FifoQueue": "true"}
+		for k, v := range attrs {
+			fifoAttrs[k] = v
+		}
+		attrs = fifoAttrs
+	}
 	resp, err := s.newQueue(queueName, attrs)
 	if err != nil {
 		return nil, err
@@ -212,6 +316,16 @@ func (s *SQS) CreateQueueWithAttributes(queueName string, attrs map[string]strin
 	return
 }
 
+// CreateFifoQueue creates a FIFO queue, which name must end with ".fifo".
+// Messages are deduplicated by their content if contentBasedDeduplication
+// is set, and by their MessageDeduplicationId otherwise.
+func (s *SQS) CreateFifoQueue(queueName string, contentBasedDeduplication bool) (*Queue, error) {
+	return s.CreateQueueWithAttributes(queueName, map[string]string{
+		"FifoQueue":                 "true",
+		"ContentBasedDeduplication": strconv.FormatBool(contentBasedDeduplication),
+	})
+}
+
 // GetQueue get a reference to the given quename
 func (s *SQS) GetQueue(queueName string) (*Queue, error) {
 	var q *Queue
@@ -223,15 +337,36 @@ func (s *SQS) GetQueue(queueName string) (*Queue, error) {
 	return q, nil
 }
 
+// GetQueueOfOwner returns the queue with the given name owned by the AWS
+// account ownerAccountId, which must have granted access to the queue
+// with AddPermission. Its Url is on the host of the owner's queue, and is
+// used as is by the calls of the queue.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_GetQueueUrl.html for details.
+func (s *SQS) GetQueueOfOwner(queueName, ownerAccountId string) (*Queue, error) {
+	resp, err := s.getQueueUrlOfOwner(queueName, ownerAccountId)
+	if err != nil {
+		return nil, err
+	}
+	return &Queue{s, resp.QueueUrl}, nil
+}
+
 func (s *SQS) QueueFromArn(queueUrl string) (q *Queue) {
 	q = &Queue{s, queueUrl}
 	return
 }
 
 func (s *SQS) getQueueUrl(queueName string) (resp *GetQueueUrlResponse, err error) {
+	return s.getQueueUrlOfOwner(queueName, "")
+}
+
+func (s *SQS) getQueueUrlOfOwner(queueName, ownerAccountId string) (resp *GetQueueUrlResponse, err error) {
 	resp = &GetQueueUrlResponse{}
 	params := makeParams("GetQueueUrl")
 	params["QueueName"] = queueName
+	if ownerAccountId != "" {
+		params["QueueOwnerAWSAccountId"] = ownerAccountId
+	}
 	err = s.query("", params, resp)
 	return resp, err
 }
@@ -274,54 +409,199 @@ func (q *Queue) Delete() (resp *DeleteQueueResponse, err error) {
 	return
 }
 
+// PurgeInProgressError is returned by Purge when the queue was purged in
+// the last 60 seconds, which SQS reports with the
+// AWS.SimpleQueueService.PurgeQueueInProgress code. The queue may be
+// purged again once the previous purge is over.
+type PurgeInProgressError struct {
+	QueueUrl string
+	Err      *Error
+}
+
+func (e *PurgeInProgressError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error returned by SQS.
+func (e *PurgeInProgressError) Unwrap() error {
+	return e.Err
+}
+
+// Purge deletes all the messages of the queue, which is quicker than
+// deleting the queue and creating it again under the same name, which
+// SQS only allows after 60 seconds. A queue may be purged once every 60
+// seconds, and Purge fails with a *PurgeInProgressError otherwise.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_PurgeQueue.html for details.
 func (q *Queue) Purge() (resp *PurgeQueueResponse, err error) {
 	resp = &PurgeQueueResponse{}
 	params := makeParams("PurgeQueue")
 
 	err = q.SQS.query(q.Url, params, resp)
+	if e, ok := aws.AsError(err); ok && e.Code == "AWS.SimpleQueueService.PurgeQueueInProgress" {
+		return nil, &PurgeInProgressError{q.Url, e}
+	}
 	return
 }
 
-func (q *Queue) SendMessageWithDelay(MessageBody string, DelaySeconds int64) (resp *SendMessageResponse, err error) {
-	resp = &SendMessageResponse{}
-	params := makeParams("SendMessage")
+// AddPermission allows the AWS accounts accountIds to call actions on the
+// queue, which are action names such as "SendMessage" and
+// "ReceiveMessage", or "*" for all of them. The permission is added to
+// the policy of the queue under label, which RemovePermission takes.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_AddPermission.html for details.
+func (q *Queue) AddPermission(label string, accountIds, actions []string) (resp *AddPermissionResponse, err error) {
+	resp = &AddPermissionResponse{}
+	params := makeParams("AddPermission")
+	params["Label"] = label
+	for i, id := range accountIds {
+		params["AWSAccountId."+strconv.Itoa(i+1)] = id
+	}
+	for i, action := range actions {
+		params["ActionName."+strconv.Itoa(i+1)] = action
+	}
 
-	params["MessageBody"] = MessageBody
-	params["DelaySeconds"] = strconv.Itoa(int(DelaySeconds))
+	err = q.SQS.query(q.Url, params, resp)
+	return
+}
+
+// RemovePermission removes the permission added with the given label by
+// AddPermission.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_RemovePermission.html for details.
+func (q *Queue) RemovePermission(label string) (resp *RemovePermissionResponse, err error) {
+	resp = &RemovePermissionResponse{}
+	params := makeParams("RemovePermission")
+	params["Label"] = label
 
 	err = q.SQS.query(q.Url, params, resp)
 	return
 }
 
-func (q *Queue) SendMessage(MessageBody string) (resp *SendMessageResponse, err error) {
+// ErrNoMessageGroupId is returned when a message is sent to a FIFO queue
+// without MessageGroupId.
+var ErrNoMessageGroupId = errors.New("sqs: messages sent to FIFO queues need a MessageGroupId")
+
+// IsFifo reports whether the queue is a FIFO queue, as the names of FIFO
+// queues end with ".fifo".
+func (q *Queue) IsFifo() bool {
+	return strings.HasSuffix(strings.TrimSuffix(q.Url, "/"), ".fifo")
+}
+
+// MaxDelaySeconds is the longest delay of the delivery of a message.
+const MaxDelaySeconds = 900
+
+// ErrInvalidDelay is returned when a message is sent with a delay out of
+// the range from 0 to MaxDelaySeconds.
+var ErrInvalidDelay = fmt.Errorf("sqs: DelaySeconds must be between 0 and %d", MaxDelaySeconds)
+
+func validDelay(delaySeconds int) bool {
+	return delaySeconds >= 0 && delaySeconds <= MaxDelaySeconds
+}
+
+// SendMessageWithDelay sends a message delivered after DelaySeconds, which
+// overrides the DelaySeconds of the queue even if zero.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_SendMessage.html for details.
+func (q *Queue) SendMessageWithDelay(MessageBody string, DelaySeconds int64) (resp *SendMessageResponse, err error) {
+	if q.IsFifo() {
+		return nil, ErrNoMessageGroupId
+	}
+	if DelaySeconds < 0 || DelaySeconds > MaxDelaySeconds {
+		return nil, ErrInvalidDelay
+	}
 	resp = &SendMessageResponse{}
 	params := makeParams("SendMessage")
 
 	params["MessageBody"] = MessageBody
+	params["DelaySeconds"] = strconv.Itoa(int(DelaySeconds))
 
 	err = q.SQS.query(q.Url, params, resp)
-	return
+	if err != nil {
+		return resp, err
+	}
+	return resp, checkBodyMD5(resp.Id, resp.MD5, MessageBody)
+}
+
+func (q *Queue) SendMessage(MessageBody string) (resp *SendMessageResponse, err error) {
+	return q.SendMessageWithOptions(MessageBody, &SendMessageOptions{})
 }
 
 func (q *Queue) SendMessageWithAttributes(MessageBody string, attrs map[string]string) (resp *SendMessageResponse, err error) {
+	values := make(map[string]MessageAttributeValue, len(attrs))
+	for k, v := range attrs {
+		values[k] = StringAttribute(v)
+	}
+	return q.SendMessageWithMessageAttributes(MessageBody, values)
+}
+
+// SendMessageWithMessageAttributes sends a message with typed attributes.
+// It fails with an *IntegrityError if the digest SQS has of the
+// attributes doesn't match them.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_SendMessage.html for details.
+func (q *Queue) SendMessageWithMessageAttributes(MessageBody string, attrs map[string]MessageAttributeValue) (resp *SendMessageResponse, err error) {
+	return q.SendMessageWithOptions(MessageBody, &SendMessageOptions{MessageAttributes: attrs})
+}
+
+// SendMessageOptions holds the optional parameters of a SendMessage
+// request.
+type SendMessageOptions struct {
+	// DelaySeconds delays the delivery of the message by up to
+	// MaxDelaySeconds, rather than by the DelaySeconds of the queue, if
+	// it isn't zero. FIFO queues only have queue delays.
+	DelaySeconds int
+
+	MessageAttributes map[string]MessageAttributeValue
+
+	// MessageGroupId is required by FIFO queues, which deliver the
+	// messages of a group in order.
+	MessageGroupId string
+
+	// MessageDeduplicationId identifies the message for FIFO queues,
+	// which don't deliver the messages sent again with this id in the
+	// next 5 minutes. It may be omitted for the queues with
+	// ContentBasedDeduplication.
+	MessageDeduplicationId string
+}
+
+// SendMessageWithOptions sends a message with options. Messages sent to a
+// FIFO queue without MessageGroupId fail with ErrNoMessageGroupId, and
+// messages with an invalid delay with ErrInvalidDelay, before the request
+// is made. Messages SQS has another digest of the body or the attributes
+// of fail with an *IntegrityError.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_SendMessage.html for details.
+func (q *Queue) SendMessageWithOptions(MessageBody string, options *SendMessageOptions) (resp *SendMessageResponse, err error) {
+	if q.IsFifo() && options.MessageGroupId == "" {
+		return nil, ErrNoMessageGroupId
+	}
+	if !validDelay(options.DelaySeconds) {
+		return nil, ErrInvalidDelay
+	}
 	resp = &SendMessageResponse{}
 	params := makeParams("SendMessage")
 
 	params["MessageBody"] = MessageBody
-
-	i := 1
-	for k, v := range attrs {
-		nameParam := fmt.Sprintf("MessageAttribute.%d.Name", i)
-		valParam := fmt.Sprintf("MessageAttribute.%d.Value.StringValue", i)
-		typeParam := fmt.Sprintf("MessageAttribute.%d.Value.DataType", i)
-		params[nameParam] = k
-		params[valParam] = v
-		params[typeParam] = "String"
-		i++
+	if options.DelaySeconds != 0 {
+		params["DelaySeconds"] = strconv.Itoa(options.DelaySeconds)
 	}
+	if options.MessageGroupId != "" {
+		params["MessageGroupId"] = options.MessageGroupId
+	}
+	if options.MessageDeduplicationId != "" {
+		params["MessageDeduplicationId"] = options.MessageDeduplicationId
+	}
+	addMessageAttributeParams(params, "", options.MessageAttributes)
 
 	err = q.SQS.query(q.Url, params, resp)
-	return
+	if err != nil {
+		return resp, err
+	}
+	if err := checkBodyMD5(resp.Id, resp.MD5, MessageBody); err != nil {
+		return resp, err
+	}
+	return resp, checkAttributesMD5(resp.Id, resp.MD5OfMessageAttributes, options.MessageAttributes)
 }
 
 // ReceiveMessageWithVisibilityTimeout
@@ -352,7 +632,21 @@ func (q *Queue) ReceiveMessageWithParameters(p map[string]string) (resp *Receive
 	}
 
 	err = q.SQS.query(q.Url, params, resp)
-	return
+	if err != nil {
+		return
+	}
+	return resp, resp.checkAttributesMD5()
+}
+
+// checkAttributesMD5 checks the digests of the message attributes of the
+// messages received.
+func (resp *ReceiveMessageResponse) checkAttributesMD5() error {
+	for _, m := range resp.Messages {
+		if err := checkAttributesMD5(m.MessageId, m.MD5OfMessageAttributes, m.MessageAttributes); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ReceiveMessageWithWaitTimeSeconds adds Amazon SQS Long Polling Support.
@@ -376,6 +670,68 @@ func (q *Queue) ReceiveMessageWithWaitTimeSeconds(MaxNumberOfMessages, Visibilit
 	return q.ReceiveMessageWithParameters(params)
 }
 
+// ReceiveMessageOptions holds the parameters of a ReceiveMessage request.
+// The fields which are zero are left to the defaults of the queue, and
+// all the attributes are returned if none are named.
+type ReceiveMessageOptions struct {
+	// MaxNumberOfMessages is the most messages returned, up to 10.
+	MaxNumberOfMessages int
+
+	// VisibilityTimeout is the number of seconds the messages received
+	// are hidden from other receives.
+	VisibilityTimeout int
+
+	// WaitTimeSeconds, up to 20, makes a long poll waiting that long for
+	// messages before returning without any. The timeout of the Client
+	// of the SQS must be longer.
+	WaitTimeSeconds int
+
+	// AttributeNames are the system attributes returned, such as
+	// SentTimestamp or ApproximateReceiveCount, or All.
+	AttributeNames []string
+
+	// MessageAttributeNames are the message attributes returned, or All.
+	// Names ending with ".*" select the attributes with that prefix.
+	MessageAttributeNames []string
+}
+
+// ReceiveMessageWithOptions receives messages from the queue as given by
+// options, which include long polling.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_ReceiveMessage.html for details.
+func (q *Queue) ReceiveMessageWithOptions(options *ReceiveMessageOptions) (resp *ReceiveMessageResponse, err error) {
+	resp = &ReceiveMessageResponse{}
+	params := makeParams("ReceiveMessage")
+	if options.MaxNumberOfMessages != 0 {
+		params["MaxNumberOfMessages"] = strconv.Itoa(options.MaxNumberOfMessages)
+	}
+	if options.VisibilityTimeout != 0 {
+		params["VisibilityTimeout"] = strconv.Itoa(options.VisibilityTimeout)
+	}
+	if options.WaitTimeSeconds != 0 {
+		params["WaitTimeSeconds"] = strconv.Itoa(options.WaitTimeSeconds)
+	}
+	addParamsList(params, "AttributeName", options.AttributeNames, "All")
+	addParamsList(params, "MessageAttributeName", options.MessageAttributeNames, "All")
+
+	err = q.SQS.query(q.Url, params, resp)
+	if err != nil {
+		return
+	}
+	return resp, resp.checkAttributesMD5()
+}
+
+// addParamsList adds the numbered parameters label.1, label.2... of
+// values, or the single value def if there are none.
+func addParamsList(params map[string]string, label string, values []string, def string) {
+	if len(values) == 0 {
+		values = []string{def}
+	}
+	for i, v := range values {
+		params[label+"."+strconv.Itoa(i+1)] = v
+	}
+}
+
 func (q *Queue) ChangeMessageVisibility(M *Message, VisibilityTimeout int) (resp *ChangeMessageVisibilityResponse, err error) {
 	resp = &ChangeMessageVisibilityResponse{}
 	params := makeParams("ChangeMessageVisibility")
@@ -395,6 +751,29 @@ func (q *Queue) GetQueueAttributes(A string) (resp *GetQueueAttributesResponse,
 	return
 }
 
+// SetQueueAttributes sets attributes of the queue, such as
+// ReceiveMessageWaitTimeSeconds which makes receives long poll by
+// default, VisibilityTimeout or MessageRetentionPeriod.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_SetQueueAttributes.html for details.
+func (q *Queue) SetQueueAttributes(attrs map[string]string) (resp *SetQueueAttributesResponse, err error) {
+	resp = &SetQueueAttributesResponse{}
+	params := makeParams("SetQueueAttributes")
+
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		params[fmt.Sprintf("Attribute.%d.Name", i+1)] = name
+		params[fmt.Sprintf("Attribute.%d.Value", i+1)] = attrs[name]
+	}
+
+	err = q.SQS.query(q.Url, params, resp)
+	return
+}
+
 func (q *Queue) DeleteMessage(M *Message) (resp *DeleteMessageResponse, err error) {
 	return q.DeleteMessageUsingReceiptHandle(M.ReceiptHandle)
 }
@@ -408,49 +787,212 @@ func (q *Queue) DeleteMessageUsingReceiptHandle(receiptHandle string) (resp *Del
 	return
 }
 
+// Limits of the batch requests.
+const (
+	MaxBatchEntries     = 10
+	MaxBatchPayloadSize = 256 * 1024
+)
+
+// BatchEntryError reports an entry which can't be sent in a batch request,
+// before the request is made. Index is the position of the entry in the
+// entries given.
+type BatchEntryError struct {
+	Index  int
+	Id     string
+	Reason string
+}
+
+func (e *BatchEntryError) Error() string {
+	return fmt.Sprintf("sqs: batch entry %d (%q): %s", e.Index, e.Id, e.Reason)
+}
+
+// BatchResultErrorEntry describes an entry of a batch request which
+// failed. Entries failing with SenderFault would fail again as they are,
+// while the others may be retried.
+type BatchResultErrorEntry struct {
+	Id          string `xml:"Id"`
+	Code        string `xml:"Code"`
+	Message     string `xml:"Message"`
+	SenderFault bool   `xml:"SenderFault"`
+}
+
+// checkBatchIds checks that there are at most MaxBatchEntries ids in a
+// batch, and that they are set and distinct.
+func checkBatchIds(ids []string) error {
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		switch {
+		case i >= MaxBatchEntries:
+			return &BatchEntryError{i, id, fmt.Sprintf("more than %d entries in a batch", MaxBatchEntries)}
+		case id == "":
+			return &BatchEntryError{i, id, "entry without id"}
+		case seen[id]:
+			return &BatchEntryError{i, id, "id used by another entry"}
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// SendMessageBatchRequestEntry is a message sent by SendMessageBatchEntries,
+// which results are identified by Id. The other fields are as in
+// SendMessageOptions.
+type SendMessageBatchRequestEntry struct {
+	Id                     string
+	MessageBody            string
+	DelaySeconds           int
+	MessageAttributes      map[string]MessageAttributeValue
+	MessageGroupId         string
+	MessageDeduplicationId string
+}
+
+// size returns the size of the message, which counts against
+// MaxBatchPayloadSize.
+func (e *SendMessageBatchRequestEntry) size() int {
+	return len(e.MessageBody) + messageAttributesSize(e.MessageAttributes)
+}
+
 type SendMessageBatchResultEntry struct {
-	Id               string `xml:"Id"`
-	MessageId        string `xml:"MessageId"`
-	MD5OfMessageBody string `xml:"MD5OfMessageBody"`
+	Id                     string `xml:"Id"`
+	MessageId              string `xml:"MessageId"`
+	MD5OfMessageBody       string `xml:"MD5OfMessageBody"`
+	MD5OfMessageAttributes string `xml:"MD5OfMessageAttributes"`
+	SequenceNumber         string `xml:"SequenceNumber"`
 }
 
+// SendMessageBatchResponse holds the messages of a batch which were sent
+// in SendMessageBatchResult, and the ones which failed in Failed.
 type SendMessageBatchResponse struct {
 	SendMessageBatchResult []SendMessageBatchResultEntry `xml:"SendMessageBatchResult>SendMessageBatchResultEntry"`
+	Failed                 []BatchResultErrorEntry       `xml:"SendMessageBatchResult>BatchResultErrorEntry"`
 	ResponseMetadata       ResponseMetadata
 }
 
-/* SendMessageBatch
- */
-func (q *Queue) SendMessageBatch(msgList []Message) (resp *SendMessageBatchResponse, err error) {
+// SendMessageBatchEntries sends up to MaxBatchEntries messages, of
+// MaxBatchPayloadSize in total, in a request. Entries breaking these
+// limits, without distinct ids, or without MessageGroupId for a FIFO
+// queue, or with an invalid DelaySeconds, fail with a *BatchEntryError
+// before the request is made. The messages which failed are in
+// resp.Failed, and may be retried if they aren't a SenderFault. It fails
+// with an *IntegrityError if the digest SQS has of the body or the
+// attributes of a message sent doesn't match them.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_SendMessageBatch.html for details.
+func (q *Queue) SendMessageBatchEntries(entries []SendMessageBatchRequestEntry) (resp *SendMessageBatchResponse, err error) {
+	ids := make([]string, len(entries))
+	size := 0
+	for i := range entries {
+		ids[i] = entries[i].Id
+		if q.IsFifo() && entries[i].MessageGroupId == "" {
+			return nil, &BatchEntryError{i, entries[i].Id, "no MessageGroupId for a FIFO queue"}
+		}
+		if !validDelay(entries[i].DelaySeconds) {
+			return nil, &BatchEntryError{i, entries[i].Id, fmt.Sprintf("DelaySeconds not between 0 and %d", MaxDelaySeconds)}
+		}
+		if entries[i].size() > MaxBatchPayloadSize {
+			return nil, &BatchEntryError{i, entries[i].Id, fmt.Sprintf("message larger than %d bytes", MaxBatchPayloadSize)}
+		}
+		size += entries[i].size()
+		if size > MaxBatchPayloadSize {
+			return nil, &BatchEntryError{i, entries[i].Id, fmt.Sprintf("batch larger than %d bytes", MaxBatchPayloadSize)}
+		}
+	}
+	if err := checkBatchIds(ids); err != nil {
+		return nil, err
+	}
+
 	resp = &SendMessageBatchResponse{}
 	params := makeParams("SendMessageBatch")
 
-	for idx, msg := range msgList {
-		count := idx + 1
-		params[fmt.Sprintf("SendMessageBatchRequestEntry.%d.Id", count)] = fmt.Sprintf("msg-%d", count)
-		params[fmt.Sprintf("SendMessageBatchRequestEntry.%d.MessageBody", count)] = msg.Body
+	for idx, entry := range entries {
+		prefix := fmt.Sprintf("SendMessageBatchRequestEntry.%d.", idx+1)
+		params[prefix+"Id"] = entry.Id
+		params[prefix+"MessageBody"] = entry.MessageBody
+		if entry.DelaySeconds != 0 {
+			params[prefix+"DelaySeconds"] = strconv.Itoa(entry.DelaySeconds)
+		}
+		if entry.MessageGroupId != "" {
+			params[prefix+"MessageGroupId"] = entry.MessageGroupId
+		}
+		if entry.MessageDeduplicationId != "" {
+			params[prefix+"MessageDeduplicationId"] = entry.MessageDeduplicationId
+		}
+		addMessageAttributeParams(params, prefix, entry.MessageAttributes)
 	}
 
 	err = q.SQS.query(q.Url, params, resp)
+	if err != nil {
+		return
+	}
+	byId := make(map[string]*SendMessageBatchRequestEntry, len(entries))
+	for i := range entries {
+		byId[entries[i].Id] = &entries[i]
+	}
+	for _, result := range resp.SendMessageBatchResult {
+		if entry := byId[result.Id]; entry != nil {
+			if err := checkBodyMD5(result.Id, result.MD5OfMessageBody, entry.MessageBody); err != nil {
+				return resp, err
+			}
+			if err := checkAttributesMD5(result.Id, result.MD5OfMessageAttributes, entry.MessageAttributes); err != nil {
+				return resp, err
+			}
+		}
+	}
 	return
 }
 
+// SendMessageBatchAll sends any number of messages, in as few batches
+// within MaxBatchEntries and MaxBatchPayloadSize as it can, and returns the
+// results of all of them. If a batch request fails, the results of the
+// batches sent before it are returned with the error.
+func (q *Queue) SendMessageBatchAll(entries []SendMessageBatchRequestEntry) (*SendMessageBatchResponse, error) {
+	all := &SendMessageBatchResponse{}
+	for start := 0; start < len(entries); {
+		end, size := start, 0
+		for end < len(entries) && end-start < MaxBatchEntries {
+			if end > start && size+entries[end].size() > MaxBatchPayloadSize {
+				break
+			}
+			size += entries[end].size()
+			end++
+		}
+		resp, err := q.SendMessageBatchEntries(entries[start:end])
+		if e, ok := err.(*BatchEntryError); ok {
+			e.Index += start
+		}
+		if err != nil {
+			return all, err
+		}
+		all.SendMessageBatchResult = append(all.SendMessageBatchResult, resp.SendMessageBatchResult...)
+		all.Failed = append(all.Failed, resp.Failed...)
+		all.ResponseMetadata = resp.ResponseMetadata
+		start = end
+	}
+	return all, nil
+}
+
+/* SendMessageBatch
+ */
+func (q *Queue) SendMessageBatch(msgList []Message) (resp *SendMessageBatchResponse, err error) {
+	entries := make([]SendMessageBatchRequestEntry, len(msgList))
+	for idx, msg := range msgList {
+		entries[idx] = SendMessageBatchRequestEntry{Id: fmt.Sprintf("msg-%d", idx+1), MessageBody: msg.Body}
+	}
+	return q.SendMessageBatchEntries(entries)
+}
+
 /* SendMessageBatchString
  */
 func (q *Queue) SendMessageBatchString(msgList []string) (resp *SendMessageBatchResponse, err error) {
-	resp = &SendMessageBatchResponse{}
-	params := makeParams("SendMessageBatch")
-
+	entries := make([]SendMessageBatchRequestEntry, len(msgList))
 	for idx, msg := range msgList {
-		count := idx + 1
-		params[fmt.Sprintf("SendMessageBatchRequestEntry.%d.Id", count)] = fmt.Sprintf("msg-%d", count)
-		params[fmt.Sprintf("SendMessageBatchRequestEntry.%d.MessageBody", count)] = msg
+		entries[idx] = SendMessageBatchRequestEntry{Id: fmt.Sprintf("msg-%d", idx+1), MessageBody: msg}
 	}
-
-	err = q.SQS.query(q.Url, params, resp)
-	return
+	return q.SendMessageBatchEntries(entries)
 }
 
+// DeleteMessageBatchResponse holds the messages of a batch which were
+// deleted in DeleteMessageBatchResult, and the ones which failed in Failed.
 type DeleteMessageBatchResponse struct {
 	DeleteMessageBatchResult []struct {
 		Id          string
@@ -458,54 +1000,127 @@ type DeleteMessageBatchResponse struct {
 		Code        string
 		Message     string
 	} `xml:"DeleteMessageBatchResult>DeleteMessageBatchResultEntry"`
+	Failed           []BatchResultErrorEntry `xml:"DeleteMessageBatchResult>BatchResultErrorEntry"`
 	ResponseMetadata ResponseMetadata
 }
 
-/* DeleteMessageBatch */
+// DeleteMessageBatch deletes up to MaxBatchEntries messages in a request.
+// The results are identified by the MessageId of the messages, or by
+// "msg-N" for the Nth message if it has none. The messages which failed
+// are in resp.Failed.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_DeleteMessageBatch.html for details.
 func (q *Queue) DeleteMessageBatch(msgList []Message) (resp *DeleteMessageBatchResponse, err error) {
+	ids := make([]string, len(msgList))
+	for idx := range msgList {
+		ids[idx] = msgList[idx].MessageId
+		if ids[idx] == "" {
+			ids[idx] = fmt.Sprintf("msg-%d", idx+1)
+		}
+	}
+	if err := checkBatchIds(ids); err != nil {
+		return nil, err
+	}
+
 	resp = &DeleteMessageBatchResponse{}
 	params := makeParams("DeleteMessageBatch")
 
-	lutMsg := make(map[string]Message)
-
 	for idx := range msgList {
-		params[fmt.Sprintf("DeleteMessageBatchRequestEntry.%d.Id", idx+1)] = msgList[idx].MessageId
+		params[fmt.Sprintf("DeleteMessageBatchRequestEntry.%d.Id", idx+1)] = ids[idx]
 		params[fmt.Sprintf("DeleteMessageBatchRequestEntry.%d.ReceiptHandle", idx+1)] = msgList[idx].ReceiptHandle
-
-		lutMsg[string(msgList[idx].MessageId)] = msgList[idx]
 	}
 
 	err = q.SQS.query(q.Url, params, resp)
+	return
+}
 
-	messageWithErrors := make([]Message, 0, len(msgList))
-
-	for idx := range resp.DeleteMessageBatchResult {
-		if resp.DeleteMessageBatchResult[idx].SenderFault {
-			msg, ok := lutMsg[resp.DeleteMessageBatchResult[idx].Id]
-			if ok {
-				messageWithErrors = append(messageWithErrors, msg)
-			}
+// DeleteMessageBatchAll deletes any number of messages, MaxBatchEntries per
+// request, and returns the results of all of them. If a batch request
+// fails, the results of the batches sent before it are returned with the
+// error.
+func (q *Queue) DeleteMessageBatchAll(msgList []Message) (*DeleteMessageBatchResponse, error) {
+	all := &DeleteMessageBatchResponse{}
+	for start := 0; start < len(msgList); start += MaxBatchEntries {
+		end := start + MaxBatchEntries
+		if end > len(msgList) {
+			end = len(msgList)
+		}
+		resp, err := q.DeleteMessageBatch(msgList[start:end])
+		if e, ok := err.(*BatchEntryError); ok {
+			e.Index += start
 		}
+		if err != nil {
+			return all, err
+		}
+		all.DeleteMessageBatchResult = append(all.DeleteMessageBatchResult, resp.DeleteMessageBatchResult...)
+		all.Failed = append(all.Failed, resp.Failed...)
+		all.ResponseMetadata = resp.ResponseMetadata
 	}
+	return all, nil
+}
+
+// ChangeMessageVisibilityBatchRequestEntry changes the visibility timeout
+// of the message received with ReceiptHandle to VisibilityTimeout seconds.
+// Its result is identified by Id.
+type ChangeMessageVisibilityBatchRequestEntry struct {
+	Id                string
+	ReceiptHandle     string
+	VisibilityTimeout int
+}
+
+// ChangeMessageVisibilityBatchResponse holds the ids of the entries of a
+// batch which succeeded in Successful, and the ones which failed in
+// Failed.
+type ChangeMessageVisibilityBatchResponse struct {
+	Successful       []string                `xml:"ChangeMessageVisibilityBatchResult>ChangeMessageVisibilityBatchResultEntry>Id"`
+	Failed           []BatchResultErrorEntry `xml:"ChangeMessageVisibilityBatchResult>BatchResultErrorEntry"`
+	ResponseMetadata ResponseMetadata
+}
 
-	if len(messageWithErrors) > 0 {
-		log.Printf("%d Message have not been sent", len(messageWithErrors))
+// ChangeMessageVisibilityBatch changes the visibility timeout of up to
+// MaxBatchEntries messages in a request. The entries which failed are in
+// resp.Failed.
+//
+// See https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_ChangeMessageVisibilityBatch.html for details.
+func (q *Queue) ChangeMessageVisibilityBatch(entries []ChangeMessageVisibilityBatchRequestEntry) (resp *ChangeMessageVisibilityBatchResponse, err error) {
+	ids := make([]string, len(entries))
+	for i := range entries {
+		ids[i] = entries[i].Id
+	}
+	if err := checkBatchIds(ids); err != nil {
+		return nil, err
 	}
 
+	resp = &ChangeMessageVisibilityBatchResponse{}
+	params := makeParams("ChangeMessageVisibilityBatch")
+
+	for idx, entry := range entries {
+		prefix := fmt.Sprintf("ChangeMessageVisibilityBatchRequestEntry.%d.", idx+1)
+		params[prefix+"Id"] = entry.Id
+		params[prefix+"ReceiptHandle"] = entry.ReceiptHandle
+		params[prefix+"VisibilityTimeout"] = strconv.Itoa(entry.VisibilityTimeout)
+	}
+
+	err = q.SQS.query(q.Url, params, resp)
 	return
 }
 
 func (s *SQS) query(queueUrl string, params map[string]string, resp interface{}) (err error) {
 	params["Version"] = API_VERSION
-	params["Timestamp"] = time.Now().In(time.UTC).Format(time.RFC3339)
 	var url_ *url.URL
 	var path string
 
 	switch {
-	// fully qualified queueUrl
+	// fully qualified queueUrl, which may be on another host than the
+	// endpoint of the region for queues of other accounts
 	case strings.HasPrefix(queueUrl, "http"):
 		url_, err = url.Parse(queueUrl)
-		path = queueUrl[len(s.Region.SQSEndpoint):]
+		if err == nil {
+			path = url_.EscapedPath()
+			if path == "" {
+				path = "/"
+			}
+		}
 		// relative queueUrl
 	case strings.HasPrefix(queueUrl, "/"):
 		url_, err = url.Parse(s.Region.SQSEndpoint + queueUrl)
@@ -520,30 +1135,40 @@ func (s *SQS) query(queueUrl string, params map[string]string, resp interface{})
 		return err
 	}
 
-	if s.Auth.Token() != "" {
-		params["SecurityToken"] = s.Auth.Token()
+	auth, err := aws.CurrentAuth(s.Auth, s.Credentials)
+	if err != nil {
+		return err
+	}
+	if auth.Token() != "" {
+		params["SecurityToken"] = auth.Token()
 	}
 
-	var r *http.Response
-	if s.Region.Name == "cn-north-1" {
-		var sarray []string
-		for k, v := range params {
-			sarray = append(sarray, aws.Encode(k)+"="+aws.Encode(v))
-		}
+	policy := s.RetryPolicy
+	if policy == nil {
+		policy = &aws.DefaultRetryPolicy
+	}
+	r, err := s.clock.Do(policy, &s.Client, func(now time.Time) (*http.Request, error) {
+		params["Timestamp"] = now.In(time.UTC).Format(time.RFC3339)
+		if s.Region.Name == "cn-north-1" {
+			var sarray []string
+			for k, v := range params {
+				sarray = append(sarray, aws.Encode(k)+"="+aws.Encode(v))
+			}
 
-		req, err := http.NewRequest("GET", fmt.Sprintf("%s?%s", url_, strings.Join(sarray, "&")), nil)
-		if err != nil {
-			return err
+			req, err := http.NewRequest("GET", fmt.Sprintf("%s?%s", url_, strings.Join(sarray, "&")), nil)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("X-Amz-Date", now.In(time.UTC).Format(aws.ISO8601BasicFormat))
+			signer := aws.NewV4Signer(auth, "sqs", s.Region)
+			signer.Sign(req)
+			return req, nil
 		}
-		signer := aws.NewV4Signer(s.Auth, "sqs", s.Region)
-		signer.Sign(req)
-		client := http.Client{}
-		r, err = client.Do(req)
-	} else {
-		sign(s.Auth, "GET", path, params, url_.Host)
+		delete(params, "Signature")
+		sign(auth, "GET", path, params, url_.Host)
 		url_.RawQuery = multimap(params).Encode()
-		r, err = http.Get(url_.String())
-	}
+		return http.NewRequest("GET", url_.String(), nil)
+	})
 
 	if debug {
 		log.Printf("GET ", url_.String())
@@ -570,20 +1195,7 @@ func (s *SQS) query(queueUrl string, params map[string]string, resp interface{})
 }
 
 func buildError(r *http.Response) error {
-	errors := xmlErrors{}
-	xml.NewDecoder(r.Body).Decode(&errors)
-	var err Error
-	if len(errors.Errors) > 0 {
-		err = errors.Errors[0]
-	} else {
-		err = errors.Error
-	}
-	err.RequestId = errors.RequestId
-	err.StatusCode = r.StatusCode
-	if err.Message == "" {
-		err.Message = r.Status
-	}
-	return &err
+	return aws.BuildError(r)
 }
 
 func makeParams(action string) map[string]string {
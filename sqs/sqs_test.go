@@ -2,9 +2,13 @@ package sqs
 
 import (
 	"crypto/md5"
+	"encoding/base64"
 	"encoding/binary"
 	"fmt"
 	"hash"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/goamz/goamz/aws"
 	"github.com/motain/gocheck"
@@ -42,6 +46,32 @@ func (s *S) TestCreateQueue(c *C) {
 	c.Assert(err, IsNil)
 }
 
+// rotatingCredentials returns new keys each time they are requested.
+type rotatingCredentials struct {
+	n int
+}
+
+func (r *rotatingCredentials) Credentials() (aws.Auth, error) {
+	r.n++
+	return aws.Auth{AccessKey: fmt.Sprintf("access-%d", r.n), SecretKey: "secret"}, nil
+}
+
+func (s *S) TestCredentialsRotation(c *C) {
+	s.sqs.Credentials = &rotatingCredentials{}
+	defer func() {
+		s.sqs.Credentials = nil
+	}()
+
+	for i := 1; i <= 2; i++ {
+		testServer.PrepareResponse(200, nil, TestCreateQueueXmlOK)
+		_, err := s.sqs.CreateQueue("testQueue")
+		c.Assert(err, IsNil)
+
+		req := testServer.WaitRequest()
+		c.Assert(req.Form["AWSAccessKeyId"], DeepEquals, []string{fmt.Sprintf("access-%d", i)})
+	}
+}
+
 func (s *S) TestCreateQueueWithTimeout(c *C) {
 	testServer.PrepareResponse(200, nil, TestCreateQueueXmlOK)
 
@@ -348,7 +378,7 @@ func (s *S) TestReceiveMessage(c *C) {
 	for i, expected := range expectedMessageAttributeResults {
 		c.Assert(resp.Messages[0].MessageAttribute[i].Name, Equals, expected.Name)
 		c.Assert(resp.Messages[0].MessageAttribute[i].Value.DataType, Equals, expected.Value.DataType)
-		c.Assert(string(resp.Messages[0].MessageAttribute[i].Value.BinaryValue), Equals, string(expected.Value.BinaryValue))
+		c.Assert(base64.StdEncoding.EncodeToString(resp.Messages[0].MessageAttribute[i].Value.BinaryValue), Equals, string(expected.Value.BinaryValue))
 		c.Assert(resp.Messages[0].MessageAttribute[i].Value.StringValue, Equals, expected.Value.StringValue)
 	}
 
@@ -438,7 +468,7 @@ func (s *S) TestReceiveMessageWithWaitTimeSeconds(c *gocheck.C) {
 	for i, expected := range expectedMessageAttributeResults {
 		c.Assert(resp.Messages[0].MessageAttribute[i].Name, gocheck.Equals, expected.Name)
 		c.Assert(resp.Messages[0].MessageAttribute[i].Value.DataType, gocheck.Equals, expected.Value.DataType)
-		c.Assert(string(resp.Messages[0].MessageAttribute[i].Value.BinaryValue), gocheck.Equals, string(expected.Value.BinaryValue))
+		c.Assert(base64.StdEncoding.EncodeToString(resp.Messages[0].MessageAttribute[i].Value.BinaryValue), gocheck.Equals, string(expected.Value.BinaryValue))
 		c.Assert(resp.Messages[0].MessageAttribute[i].Value.StringValue, gocheck.Equals, expected.Value.StringValue)
 	}
 
@@ -503,3 +533,692 @@ func (s *S) TestGetQueueAttributes(c *C) {
 
 	c.Assert(err, IsNil)
 }
+
+func (s *S) TestReceiveMessageLongPoll(c *C) {
+	testServer.PrepareResponse(200, nil, TestReceiveMessageXmlOK)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	resp, err := q.ReceiveMessageWithOptions(&ReceiveMessageOptions{
+		MaxNumberOfMessages:   10,
+		VisibilityTimeout:     60,
+		WaitTimeSeconds:       20,
+		AttributeNames:        []string{"SentTimestamp", "ApproximateReceiveCount"},
+		MessageAttributeNames: []string{"CustomAttribute"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Messages, HasLen, 1)
+	req := testServer.WaitRequest()
+
+	// Only the parameters of the receive are sent besides the signature
+	for _, name := range []string{"AWSAccessKeyId", "Signature", "SignatureMethod", "SignatureVersion", "Timestamp"} {
+		c.Assert(req.Form[name], HasLen, 1)
+		delete(req.Form, name)
+	}
+	c.Assert(req.Form, DeepEquals, url.Values{
+		"Action":                 {"ReceiveMessage"},
+		"Version":                {"2012-11-05"},
+		"MaxNumberOfMessages":    {"10"},
+		"VisibilityTimeout":      {"60"},
+		"WaitTimeSeconds":        {"20"},
+		"AttributeName.1":        {"SentTimestamp"},
+		"AttributeName.2":        {"ApproximateReceiveCount"},
+		"MessageAttributeName.1": {"CustomAttribute"},
+	})
+
+	// All the attributes are returned by default
+	testServer.PrepareResponse(200, nil, TestReceiveMessageXmlOK)
+	_, err = q.ReceiveMessageWithOptions(&ReceiveMessageOptions{})
+	c.Assert(err, IsNil)
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["AttributeName.1"], DeepEquals, []string{"All"})
+	c.Assert(req.Form["MessageAttributeName.1"], DeepEquals, []string{"All"})
+	c.Assert(req.Form["WaitTimeSeconds"], IsNil)
+	c.Assert(req.Form["MaxNumberOfMessages"], IsNil)
+}
+
+func (s *S) TestReceiveMessageSystemAttributes(c *C) {
+	testServer.PrepareResponse(200, nil, TestReceiveMessageXmlOK)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	resp, err := q.ReceiveMessage(1)
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+
+	msg := resp.Messages[0]
+	c.Assert(msg.SenderId, Equals, "195004372649")
+	c.Assert(msg.ApproximateReceiveCount, Equals, 5)
+	c.Assert(msg.SentTimestamp.Equal(time.Unix(1238099229, 0)), Equals, true)
+	c.Assert(msg.ApproximateFirstReceiveTimestamp.Equal(time.Unix(1250700979, 248e6)), Equals, true)
+	c.Assert(msg.Attribute, HasLen, 4)
+}
+
+func (s *S) TestSetQueueAttributes(c *C) {
+	testServer.PrepareResponse(200, nil, TestSetQueueAttributesXmlOK)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	resp, err := q.SetQueueAttributes(map[string]string{
+		"ReceiveMessageWaitTimeSeconds": "20",
+		"VisibilityTimeout":             "90",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(resp.ResponseMetadata.RequestId, Equals, "e5cca473-4fc0-4198-a451-8abb94d02c75")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.URL.Path, Equals, "/123456789012/testQueue/")
+	c.Assert(req.Form["Action"], DeepEquals, []string{"SetQueueAttributes"})
+	c.Assert(req.Form["Attribute.1.Name"], DeepEquals, []string{"ReceiveMessageWaitTimeSeconds"})
+	c.Assert(req.Form["Attribute.1.Value"], DeepEquals, []string{"20"})
+	c.Assert(req.Form["Attribute.2.Name"], DeepEquals, []string{"VisibilityTimeout"})
+	c.Assert(req.Form["Attribute.2.Value"], DeepEquals, []string{"90"})
+}
+
+func (s *S) TestPurgeQueueInProgress(c *C) {
+	testServer.PrepareResponse(403, nil, TestPurgeQueueInProgressXmlError)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	_, err := q.Purge()
+	testServer.WaitRequest()
+
+	c.Assert(err, FitsTypeOf, &PurgeInProgressError{})
+	c.Assert(err.(*PurgeInProgressError).QueueUrl, Equals, q.Url)
+	c.Assert(aws.IsCode(err, "AWS.SimpleQueueService.PurgeQueueInProgress"), Equals, true)
+	c.Assert(err, ErrorMatches, "Only one PurgeQueue operation on testQueue is allowed every 60 seconds.*")
+}
+
+func (s *S) TestQueueAttributes(c *C) {
+	testServer.PrepareResponse(200, nil, TestGetAllQueueAttributesXmlOK)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue.fifo/"}
+	attrs, err := q.Attributes()
+	c.Assert(err, IsNil)
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"GetQueueAttributes"})
+	c.Assert(req.Form["AttributeName"], DeepEquals, []string{"All"})
+
+	c.Assert(attrs, DeepEquals, &QueueAttributes{
+		DelaySeconds:                  5,
+		MaximumMessageSize:            8192,
+		MessageRetentionPeriod:        345600,
+		ReceiveMessageWaitTimeSeconds: 20,
+		VisibilityTimeout:             30,
+		Policy:                        `{"Version":"2012-10-17"}`,
+		RedrivePolicy:                 &RedrivePolicy{5, "arn:aws:sqs:us-east-1:123456789012:dead.fifo"},
+		KmsMasterKeyId:                "alias/aws/sqs",
+		KmsDataKeyReusePeriodSeconds:  300,
+		FifoQueue:                     true,
+		ContentBasedDeduplication:     true,
+
+		QueueArn:                              "arn:aws:sqs:us-east-1:123456789012:testQueue.fifo",
+		ApproximateNumberOfMessages:           3,
+		ApproximateNumberOfMessagesNotVisible: 1,
+		ApproximateNumberOfMessagesDelayed:    2,
+		CreatedTimestamp:                      time.Unix(1286771522, 0),
+		LastModifiedTimestamp:                 time.Unix(1286771600, 0),
+	})
+
+	// The attributes read are written back, without the read-only ones
+	testServer.PrepareResponse(200, nil, TestSetQueueAttributesXmlOK)
+	attrs.VisibilityTimeout = 90
+	_, err = q.SetAttributes(attrs)
+	c.Assert(err, IsNil)
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"SetQueueAttributes"})
+	c.Assert(formAttributes(req.Form), DeepEquals, map[string]string{
+		"DelaySeconds":                  "5",
+		"MaximumMessageSize":            "8192",
+		"MessageRetentionPeriod":        "345600",
+		"ReceiveMessageWaitTimeSeconds": "20",
+		"VisibilityTimeout":             "90",
+		"Policy":                        `{"Version":"2012-10-17"}`,
+		"RedrivePolicy":                 `{"maxReceiveCount":5,"deadLetterTargetArn":"arn:aws:sqs:us-east-1:123456789012:dead.fifo"}`,
+		"KmsMasterKeyId":                "alias/aws/sqs",
+		"KmsDataKeyReusePeriodSeconds":  "300",
+		"ContentBasedDeduplication":     "true",
+	})
+
+	// Empty attributes are removed, and invalid zero values left out
+	testServer.PrepareResponse(200, nil, TestSetQueueAttributesXmlOK)
+	_, err = q.SetAttributes(&QueueAttributes{VisibilityTimeout: 30})
+	c.Assert(err, IsNil)
+	req = testServer.WaitRequest()
+	c.Assert(formAttributes(req.Form), DeepEquals, map[string]string{
+		"DelaySeconds":                  "0",
+		"ReceiveMessageWaitTimeSeconds": "0",
+		"VisibilityTimeout":             "30",
+		"Policy":                        "",
+		"RedrivePolicy":                 "",
+		"KmsMasterKeyId":                "",
+	})
+}
+
+// formAttributes returns the attributes sent as Attribute.N.Name and
+// Attribute.N.Value.
+func formAttributes(form url.Values) map[string]string {
+	attrs := map[string]string{}
+	for i := 1; form.Get(fmt.Sprintf("Attribute.%d.Name", i)) != ""; i++ {
+		attrs[form.Get(fmt.Sprintf("Attribute.%d.Name", i))] = form.Get(fmt.Sprintf("Attribute.%d.Value", i))
+	}
+	return attrs
+}
+
+func (s *S) TestGetQueueOfOwner(c *C) {
+	testServer.PrepareResponse(200, nil, TestGetQueueUrlOfOwnerXmlOK)
+
+	q, err := s.sqs.GetQueueOfOwner("theirQueue", "999999999999")
+	c.Assert(err, IsNil)
+	c.Assert(q.Url, Equals, "http://localhost:4455/999999999999/theirQueue")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.URL.Path, Equals, "/")
+	c.Assert(req.Form["Action"], DeepEquals, []string{"GetQueueUrl"})
+	c.Assert(req.Form["QueueName"], DeepEquals, []string{"theirQueue"})
+	c.Assert(req.Form["QueueOwnerAWSAccountId"], DeepEquals, []string{"999999999999"})
+}
+
+func (s *S) TestForeignQueue(c *C) {
+	// The queue is on another host than the endpoint of the region
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	sqs := New(auth, aws.Region{SQSEndpoint: "https://sqs.us-east-1.amazonaws.com"})
+	q := &Queue{sqs, testServer.URL + "/999999999999/theirQueue"}
+
+	testServer.PrepareResponse(200, nil, TestSendMessageXmlOK)
+	_, err := q.SendMessage("This is a test message")
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.URL.Path, Equals, "/999999999999/theirQueue")
+	c.Assert(req.Form["Action"], DeepEquals, []string{"SendMessage"})
+	params := map[string]string{}
+	for k, v := range req.Form {
+		if k != "Signature" {
+			params[k] = v[0]
+		}
+	}
+	sign(auth, "GET", "/999999999999/theirQueue", params, req.Host)
+	c.Assert(req.Form["Signature"], DeepEquals, []string{params["Signature"]})
+}
+
+func (s *S) TestAddPermission(c *C) {
+	testServer.PrepareResponse(200, nil, TestAddPermissionXmlOK)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	resp, err := q.AddPermission("consumers", []string{"999999999999", "888888888888"}, []string{"ReceiveMessage", "DeleteMessage"})
+	c.Assert(err, IsNil)
+	c.Assert(resp.ResponseMetadata.RequestId, Equals, "9a285199-c8d6-47c2-bdb2-314cb47d599d")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.URL.Path, Equals, "/123456789012/testQueue/")
+	c.Assert(req.Form["Action"], DeepEquals, []string{"AddPermission"})
+	c.Assert(req.Form["Label"], DeepEquals, []string{"consumers"})
+	c.Assert(req.Form["AWSAccountId.1"], DeepEquals, []string{"999999999999"})
+	c.Assert(req.Form["AWSAccountId.2"], DeepEquals, []string{"888888888888"})
+	c.Assert(req.Form["ActionName.1"], DeepEquals, []string{"ReceiveMessage"})
+	c.Assert(req.Form["ActionName.2"], DeepEquals, []string{"DeleteMessage"})
+}
+
+func (s *S) TestRemovePermission(c *C) {
+	testServer.PrepareResponse(200, nil, TestRemovePermissionXmlOK)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	resp, err := q.RemovePermission("consumers")
+	c.Assert(err, IsNil)
+	c.Assert(resp.ResponseMetadata.RequestId, Equals, "f8bdb362-6616-42c0-977a-ce9a8bcce3bb")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"RemovePermission"})
+	c.Assert(req.Form["Label"], DeepEquals, []string{"consumers"})
+}
+
+func (s *S) TestSendMessageBatchEntries(c *C) {
+	testServer.PrepareResponse(200, nil, TestSendMessageBatchFailedXml)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	resp, err := q.SendMessageBatchEntries([]SendMessageBatchRequestEntry{
+		{Id: "test_msg_001", MessageBody: "test message body 1"},
+		{Id: "test_msg_002", MessageBody: "test message body 2", DelaySeconds: 30, MessageAttributes: map[string]MessageAttributeValue{"b": StringAttribute("2"), "a": StringAttribute("1")}},
+	})
+	c.Assert(err, IsNil)
+	req := testServer.WaitRequest()
+
+	c.Assert(req.Form["Action"], DeepEquals, []string{"SendMessageBatch"})
+	c.Assert(req.Form["SendMessageBatchRequestEntry.1.Id"], DeepEquals, []string{"test_msg_001"})
+	c.Assert(req.Form["SendMessageBatchRequestEntry.1.MessageBody"], DeepEquals, []string{"test message body 1"})
+	c.Assert(req.Form["SendMessageBatchRequestEntry.1.DelaySeconds"], IsNil)
+	c.Assert(req.Form["SendMessageBatchRequestEntry.2.Id"], DeepEquals, []string{"test_msg_002"})
+	c.Assert(req.Form["SendMessageBatchRequestEntry.2.DelaySeconds"], DeepEquals, []string{"30"})
+	c.Assert(req.Form["SendMessageBatchRequestEntry.2.MessageAttribute.1.Name"], DeepEquals, []string{"a"})
+	c.Assert(req.Form["SendMessageBatchRequestEntry.2.MessageAttribute.1.Value.StringValue"], DeepEquals, []string{"1"})
+	c.Assert(req.Form["SendMessageBatchRequestEntry.2.MessageAttribute.1.Value.DataType"], DeepEquals, []string{"String"})
+	c.Assert(req.Form["SendMessageBatchRequestEntry.2.MessageAttribute.2.Name"], DeepEquals, []string{"b"})
+
+	c.Assert(resp.SendMessageBatchResult, HasLen, 1)
+	c.Assert(resp.SendMessageBatchResult[0].Id, Equals, "test_msg_001")
+	c.Assert(resp.Failed, DeepEquals, []BatchResultErrorEntry{
+		{Id: "test_msg_002", Code: "InternalError", Message: "Internal error", SenderFault: false},
+	})
+}
+
+func (s *S) TestSendMessageBatchEntriesInvalid(c *C) {
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+
+	entries := make([]SendMessageBatchRequestEntry, 11)
+	for i := range entries {
+		entries[i] = SendMessageBatchRequestEntry{Id: fmt.Sprintf("m%d", i), MessageBody: "body"}
+	}
+	_, err := q.SendMessageBatchEntries(entries)
+	c.Assert(err, ErrorMatches, `sqs: batch entry 10 \("m10"\): more than 10 entries in a batch`)
+
+	entries[3].Id = "m2"
+	_, err = q.SendMessageBatchEntries(entries[:10])
+	c.Assert(err, DeepEquals, &BatchEntryError{3, "m2", "id used by another entry"})
+
+	big := strings.Repeat("x", 100*1024)
+	_, err = q.SendMessageBatchEntries([]SendMessageBatchRequestEntry{{Id: "a", MessageBody: big}, {Id: "b", MessageBody: big}, {Id: "c", MessageBody: big}})
+	c.Assert(err, ErrorMatches, `sqs: batch entry 2 \("c"\): batch larger than 262144 bytes`)
+
+	_, err = q.SendMessageBatchEntries([]SendMessageBatchRequestEntry{{Id: "a", MessageBody: big + big + big}})
+	c.Assert(err, ErrorMatches, `sqs: batch entry 0 \("a"\): message larger than 262144 bytes`)
+}
+
+func (s *S) TestSendMessageBatchAll(c *C) {
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+
+	// Batches are cut at 10 entries, or earlier when they get too large
+	entries := make([]SendMessageBatchRequestEntry, 25)
+	for i := range entries {
+		entries[i] = SendMessageBatchRequestEntry{Id: fmt.Sprintf("m%d", i), MessageBody: "body"}
+	}
+	entries[22].MessageBody = strings.Repeat("x", 200*1024)
+	entries[23].MessageBody = strings.Repeat("x", 100*1024)
+	for i := 0; i < 4; i++ {
+		testServer.PrepareResponse(200, nil, TestSendMessageBatchFailedXml)
+	}
+	resp, err := q.SendMessageBatchAll(entries)
+	c.Assert(err, IsNil)
+	c.Assert(resp.SendMessageBatchResult, HasLen, 4)
+	c.Assert(resp.Failed, HasLen, 4)
+
+	var sizes []int
+	for i := 0; i < 4; i++ {
+		req := testServer.WaitRequest()
+		n := 0
+		for req.Form[fmt.Sprintf("SendMessageBatchRequestEntry.%d.Id", n+1)] != nil {
+			n++
+		}
+		sizes = append(sizes, n)
+	}
+	c.Assert(sizes, DeepEquals, []int{10, 10, 3, 2})
+
+	// Errors name the entry in the whole slice
+	entries[21].Id = "m20"
+	testServer.PrepareResponse(200, nil, TestSendMessageBatchFailedXml)
+	resp, err = q.SendMessageBatchAll(entries[10:])
+	c.Assert(err, DeepEquals, &BatchEntryError{11, "m20", "id used by another entry"})
+	c.Assert(resp.SendMessageBatchResult, HasLen, 1)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestDeleteMessageBatchFailed(c *C) {
+	testServer.PrepareResponse(200, nil, TestDeleteMessageBatchFailedXml)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	resp, err := q.DeleteMessageBatch([]Message{{MessageId: "id-1", ReceiptHandle: "rh-1"}, {ReceiptHandle: "rh-2"}})
+	c.Assert(err, IsNil)
+	req := testServer.WaitRequest()
+
+	c.Assert(req.Form["DeleteMessageBatchRequestEntry.1.Id"], DeepEquals, []string{"id-1"})
+	c.Assert(req.Form["DeleteMessageBatchRequestEntry.1.ReceiptHandle"], DeepEquals, []string{"rh-1"})
+	c.Assert(req.Form["DeleteMessageBatchRequestEntry.2.Id"], DeepEquals, []string{"msg-2"})
+
+	c.Assert(resp.DeleteMessageBatchResult, HasLen, 1)
+	c.Assert(resp.DeleteMessageBatchResult[0].Id, Equals, "id-1")
+	c.Assert(resp.Failed, DeepEquals, []BatchResultErrorEntry{
+		{Id: "msg-2", Code: "ReceiptHandleIsInvalid", Message: "The input receipt handle is invalid.", SenderFault: true},
+	})
+}
+
+func (s *S) TestDeleteMessageBatchAll(c *C) {
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+
+	msgs := make([]Message, 12)
+	for i := range msgs {
+		msgs[i] = Message{MessageId: fmt.Sprintf("id-%d", i), ReceiptHandle: fmt.Sprintf("rh-%d", i)}
+	}
+	testServer.PrepareResponse(200, nil, TestDeleteMessageBatchFailedXml)
+	testServer.PrepareResponse(200, nil, TestDeleteMessageBatchFailedXml)
+	resp, err := q.DeleteMessageBatchAll(msgs)
+	c.Assert(err, IsNil)
+	c.Assert(resp.Failed, HasLen, 2)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["DeleteMessageBatchRequestEntry.10.Id"], DeepEquals, []string{"id-9"})
+	c.Assert(req.Form["DeleteMessageBatchRequestEntry.11.Id"], IsNil)
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["DeleteMessageBatchRequestEntry.1.Id"], DeepEquals, []string{"id-10"})
+	c.Assert(req.Form["DeleteMessageBatchRequestEntry.2.Id"], DeepEquals, []string{"id-11"})
+}
+
+func (s *S) TestChangeMessageVisibilityBatch(c *C) {
+	testServer.PrepareResponse(200, nil, TestChangeMessageVisibilityBatchXml)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	resp, err := q.ChangeMessageVisibilityBatch([]ChangeMessageVisibilityBatchRequestEntry{
+		{"change_visibility_msg_2", "rh-2", 45},
+		{"change_visibility_msg_3", "rh-3", 0},
+	})
+	c.Assert(err, IsNil)
+	req := testServer.WaitRequest()
+
+	c.Assert(req.Form["Action"], DeepEquals, []string{"ChangeMessageVisibilityBatch"})
+	c.Assert(req.Form["ChangeMessageVisibilityBatchRequestEntry.1.Id"], DeepEquals, []string{"change_visibility_msg_2"})
+	c.Assert(req.Form["ChangeMessageVisibilityBatchRequestEntry.1.ReceiptHandle"], DeepEquals, []string{"rh-2"})
+	c.Assert(req.Form["ChangeMessageVisibilityBatchRequestEntry.1.VisibilityTimeout"], DeepEquals, []string{"45"})
+	c.Assert(req.Form["ChangeMessageVisibilityBatchRequestEntry.2.VisibilityTimeout"], DeepEquals, []string{"0"})
+
+	c.Assert(resp.Successful, DeepEquals, []string{"change_visibility_msg_2"})
+	c.Assert(resp.Failed, DeepEquals, []BatchResultErrorEntry{
+		{Id: "change_visibility_msg_3", Code: "ReceiptHandleIsInvalid", Message: "The input receipt handle is invalid.", SenderFault: true},
+	})
+	c.Assert(resp.ResponseMetadata.RequestId, Equals, "ca9668f7-ab1b-4f7a-8859-f15747ab17a7")
+
+	_, err = q.ChangeMessageVisibilityBatch([]ChangeMessageVisibilityBatchRequestEntry{{"", "rh-1", 10}})
+	c.Assert(err, ErrorMatches, `sqs: batch entry 0 \(""\): entry without id`)
+}
+
+var messageAttributesMD5Tests = []struct {
+	attrs map[string]MessageAttributeValue
+	md5   string
+}{
+	{nil, "d41d8cd98f00b204e9800998ecf8427e"},
+	// The digest SQS returns in TestSendMessageXmlOK
+	{map[string]MessageAttributeValue{
+		"test_attribute_name_1": StringAttribute("test_attribute_value_1"),
+	}, "ba056227cfd9533dba1f72ad9816d233"},
+	{map[string]MessageAttributeValue{
+		"b": StringAttribute("2"),
+		"a": StringAttribute("1"),
+	}, "fb921ed26b602b1dbe2a595741287c4a"},
+	{map[string]MessageAttributeValue{
+		"count": {DataType: "Number", StringValue: "42"},
+		"ratio": {DataType: "Number.float", StringValue: "0.5"},
+		"img":   {DataType: "Binary.gif", BinaryValue: []byte{0, 1, 2}},
+		"name":  StringAttribute("héllo"),
+	}, "5b88d18574e1078692bfb04b5226508e"},
+}
+
+func (s *S) TestMessageAttributesMD5(c *C) {
+	for _, t := range messageAttributesMD5Tests {
+		c.Check(MessageAttributesMD5(t.attrs), Equals, t.md5, Commentf("%v", t.attrs))
+	}
+}
+
+func (s *S) TestSendMessageWithMessageAttributes(c *C) {
+	testServer.PrepareResponse(200, nil, TestSendMessageAttributesXmlOK)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	resp, err := q.SendMessageWithMessageAttributes("This is a test message", messageAttributesMD5Tests[3].attrs)
+	c.Assert(err, IsNil)
+	c.Assert(resp.MD5OfMessageAttributes, Equals, "5b88d18574e1078692bfb04b5226508e")
+	req := testServer.WaitRequest()
+
+	c.Assert(req.Form["Action"], DeepEquals, []string{"SendMessage"})
+	c.Assert(req.Form["MessageAttribute.1.Name"], DeepEquals, []string{"count"})
+	c.Assert(req.Form["MessageAttribute.1.Value.DataType"], DeepEquals, []string{"Number"})
+	c.Assert(req.Form["MessageAttribute.1.Value.StringValue"], DeepEquals, []string{"42"})
+	c.Assert(req.Form["MessageAttribute.2.Name"], DeepEquals, []string{"img"})
+	c.Assert(req.Form["MessageAttribute.2.Value.DataType"], DeepEquals, []string{"Binary.gif"})
+	c.Assert(req.Form["MessageAttribute.2.Value.BinaryValue"], DeepEquals, []string{"AAEC"})
+	c.Assert(req.Form["MessageAttribute.2.Value.StringValue"], IsNil)
+	c.Assert(req.Form["MessageAttribute.3.Name"], DeepEquals, []string{"name"})
+	c.Assert(req.Form["MessageAttribute.3.Value.StringValue"], DeepEquals, []string{"héllo"})
+	c.Assert(req.Form["MessageAttribute.4.Name"], DeepEquals, []string{"ratio"})
+	c.Assert(req.Form["MessageAttribute.4.Value.DataType"], DeepEquals, []string{"Number.float"})
+
+	// Attributes SQS has another digest of fail
+	testServer.PrepareResponse(200, nil, TestSendMessageXmlOK)
+	resp, err = q.SendMessageWithMessageAttributes("This is a test message", map[string]MessageAttributeValue{"a": StringAttribute("1")})
+	c.Assert(err, DeepEquals, &IntegrityError{"5fea7756-0ea4-451a-a703-a558b933e274", "ba056227cfd9533dba1f72ad9816d233", MessageAttributesMD5(map[string]MessageAttributeValue{"a": StringAttribute("1")}), false})
+	c.Assert(resp.Id, Equals, "5fea7756-0ea4-451a-a703-a558b933e274")
+	testServer.WaitRequest()
+}
+
+func (s *S) TestReceiveMessageAttributes(c *C) {
+	testServer.PrepareResponse(200, nil, TestReceiveMessageXmlOK)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	resp, err := q.ReceiveMessageWithOptions(&ReceiveMessageOptions{})
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+
+	attrs := resp.Messages[0].MessageAttributes
+	c.Assert(attrs, HasLen, 2)
+	c.Assert(attrs["CustomAttribute"], DeepEquals, StringAttribute("Testing, testing, 1, 2, 3"))
+	c.Assert(attrs["BinaryCustomAttribute"].DataType, Equals, "Binary")
+	c.Assert(string(attrs["BinaryCustomAttribute"].BinaryValue[:4]), Equals, "\x89PNG")
+	c.Assert(MessageAttributesMD5(attrs), Equals, resp.Messages[0].MD5OfMessageAttributes)
+
+	testServer.PrepareResponse(200, nil, strings.Replace(TestReceiveMessageXmlOK, "08e31730d133fd976258852e922b475b", "0123456789abcdef0123456789abcdef", 1))
+	_, err = q.ReceiveMessage(1)
+	c.Assert(err, ErrorMatches, `sqs: integrity check of the attributes of message "5fea7756-0ea4-451a-a703-a558b933e274" failed: SQS has MD5 0123456789abcdef0123456789abcdef, attributes have 08e31730d133fd976258852e922b475b`)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestQueueArn(c *C) {
+	testServer.PrepareResponse(200, nil, TestGetQueueAttributesXmlOK)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	arn, err := q.Arn()
+	c.Assert(err, IsNil)
+	c.Assert(arn, Equals, "arn:aws:sqs:us-east-1:123456789012:qfoo")
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"GetQueueAttributes"})
+	c.Assert(req.Form["AttributeName"], DeepEquals, []string{"QueueArn"})
+}
+
+func (s *S) TestSetRedrivePolicy(c *C) {
+	testServer.PrepareResponse(200, nil, TestSetQueueAttributesXmlOK)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	_, err := q.SetRedrivePolicy(5, "arn:aws:sqs:us-east-1:123456789012:testQueue-dlq")
+	c.Assert(err, IsNil)
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"SetQueueAttributes"})
+	c.Assert(req.Form["Attribute.1.Name"], DeepEquals, []string{"RedrivePolicy"})
+	c.Assert(req.Form["Attribute.1.Value"], DeepEquals, []string{`{"maxReceiveCount":5,"deadLetterTargetArn":"arn:aws:sqs:us-east-1:123456789012:testQueue-dlq"}`})
+
+	testServer.PrepareResponse(200, nil, TestSetQueueAttributesXmlOK)
+	_, err = q.RemoveRedrivePolicy()
+	c.Assert(err, IsNil)
+
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["Attribute.1.Name"], DeepEquals, []string{"RedrivePolicy"})
+	c.Assert(req.Form["Attribute.1.Value"], DeepEquals, []string{""})
+}
+
+func (s *S) TestGetRedrivePolicy(c *C) {
+	testServer.PrepareResponse(200, nil, TestGetRedrivePolicyXmlOK)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	policy, err := q.GetRedrivePolicy()
+	c.Assert(err, IsNil)
+	c.Assert(policy, DeepEquals, &RedrivePolicy{5, "arn:aws:sqs:us-east-1:123456789012:testQueue-dlq"})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["AttributeName"], DeepEquals, []string{"RedrivePolicy"})
+
+	// Queues without policy have no attribute
+	testServer.PrepareResponse(200, nil, TestGetQueueAttributesEmptyXmlOK)
+	policy, err = q.GetRedrivePolicy()
+	c.Assert(err, IsNil)
+	c.Assert(policy, IsNil)
+	testServer.WaitRequest()
+
+	p := &RedrivePolicy{}
+	c.Assert(p.UnmarshalJSON([]byte(`{"maxReceiveCount":3,"deadLetterTargetArn":"arn"}`)), IsNil)
+	c.Assert(p, DeepEquals, &RedrivePolicy{3, "arn"})
+	c.Assert(p.UnmarshalJSON([]byte(`{"maxReceiveCount":"x"}`)), ErrorMatches, `sqs: invalid maxReceiveCount "x" in redrive policy`)
+}
+
+func (s *S) TestListDeadLetterSourceQueues(c *C) {
+	testServer.PrepareResponse(200, nil, TestListDeadLetterSourceQueuesXmlOK)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue-dlq/"}
+	resp, err := q.ListDeadLetterSourceQueues()
+	c.Assert(err, IsNil)
+	c.Assert(resp.QueueUrls, DeepEquals, []string{
+		"http://sqs.us-east-1.amazonaws.com/123456789012/testQueue",
+		"http://sqs.us-east-1.amazonaws.com/123456789012/otherQueue",
+	})
+
+	req := testServer.WaitRequest()
+	c.Assert(req.URL.Path, Equals, "/123456789012/testQueue-dlq/")
+	c.Assert(req.Form["Action"], DeepEquals, []string{"ListDeadLetterSourceQueues"})
+}
+
+func (s *S) TestCreateFifoQueue(c *C) {
+	testServer.PrepareResponse(200, nil, TestCreateFifoQueueXmlOK)
+
+	q, err := s.sqs.CreateFifoQueue("testQueue.fifo", true)
+	c.Assert(err, IsNil)
+	c.Assert(q.IsFifo(), Equals, true)
+	req := testServer.WaitRequest()
+
+	attrs := map[string]string{}
+	for i := 1; i <= 2; i++ {
+		attrs[req.FormValue(fmt.Sprintf("Attribute.%d.Name", i))] = req.FormValue(fmt.Sprintf("Attribute.%d.Value", i))
+	}
+	c.Assert(req.Form["QueueName"], DeepEquals, []string{"testQueue.fifo"})
+	c.Assert(attrs, DeepEquals, map[string]string{"FifoQueue": "true", "ContentBasedDeduplication": "true"})
+
+	// The FifoQueue attribute follows the name
+	testServer.PrepareResponse(200, nil, TestCreateFifoQueueXmlOK)
+	_, err = s.sqs.CreateQueueWithAttributes("testQueue.fifo", nil)
+	c.Assert(err, IsNil)
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["Attribute.1.Name"], DeepEquals, []string{"FifoQueue"})
+	c.Assert(req.Form["Attribute.1.Value"], DeepEquals, []string{"true"})
+
+	_, err = s.sqs.CreateFifoQueue("testQueue", false)
+	c.Assert(err, ErrorMatches, `sqs: the name of FIFO queue "testQueue" must end with .fifo`)
+}
+
+func (s *S) TestSendMessageDelay(c *C) {
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+
+	testServer.PrepareResponse(200, nil, TestSendMessageXmlOK)
+	resp, err := q.SendMessageWithOptions("This is a test message", &SendMessageOptions{DelaySeconds: 900})
+	c.Assert(err, IsNil)
+	c.Assert(resp.MD5, Equals, "fafb00f5732ab283681e124bf8747ed1")
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"SendMessage"})
+	c.Assert(req.Form["DelaySeconds"], DeepEquals, []string{"900"})
+
+	// A zero delay overrides the delay of the queue
+	testServer.PrepareResponse(200, nil, TestSendMessageXmlOK)
+	_, err = q.SendMessageWithDelay("This is a test message", 0)
+	c.Assert(err, IsNil)
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["DelaySeconds"], DeepEquals, []string{"0"})
+
+	// Invalid delays fail before being sent
+	_, err = q.SendMessageWithOptions("This is a test message", &SendMessageOptions{DelaySeconds: 901})
+	c.Assert(err, Equals, ErrInvalidDelay)
+	_, err = q.SendMessageWithDelay("This is a test message", -1)
+	c.Assert(err, Equals, ErrInvalidDelay)
+	_, err = q.SendMessageBatchEntries([]SendMessageBatchRequestEntry{{Id: "a", MessageBody: "body", DelaySeconds: 901}})
+	c.Assert(err, ErrorMatches, `sqs: batch entry 0 \("a"\): DelaySeconds not between 0 and 900`)
+
+	// The body SQS has another digest of fails
+	testServer.PrepareResponse(200, nil, TestSendMessageXmlOK)
+	_, err = q.SendMessageWithOptions("Another message", &SendMessageOptions{DelaySeconds: 60})
+	c.Assert(err, DeepEquals, &IntegrityError{"5fea7756-0ea4-451a-a703-a558b933e274", "fafb00f5732ab283681e124bf8747ed1", "ccbdfc22242804411bba4b3f983144cc", true})
+	c.Assert(err, ErrorMatches, `sqs: integrity check of the body of message .* failed: .*`)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestSendMessageBatchDelay(c *C) {
+	testServer.PrepareResponse(200, nil, TestSendMessageBatchXmlOk)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue/"}
+	_, err := q.SendMessageBatchEntries([]SendMessageBatchRequestEntry{
+		{Id: "test_msg_001", MessageBody: "test message body 1", DelaySeconds: 900},
+		{Id: "test_msg_002", MessageBody: "test message body 2"},
+	})
+	c.Assert(err, IsNil)
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["Action"], DeepEquals, []string{"SendMessageBatch"})
+	c.Assert(req.Form["SendMessageBatchRequestEntry.1.DelaySeconds"], DeepEquals, []string{"900"})
+	c.Assert(req.Form["SendMessageBatchRequestEntry.2.DelaySeconds"], IsNil)
+
+	// The bodies are checked against their digests
+	testServer.PrepareResponse(200, nil, TestSendMessageBatchXmlOk)
+	_, err = q.SendMessageBatchEntries([]SendMessageBatchRequestEntry{
+		{Id: "test_msg_001", MessageBody: "test message body 1", DelaySeconds: 30},
+		{Id: "test_msg_002", MessageBody: "another body"},
+	})
+	c.Assert(err, ErrorMatches, `sqs: integrity check of the body of message "test_msg_002" failed: .*`)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestSendMessageFifo(c *C) {
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue.fifo"}
+
+	// Content based deduplication needs no deduplication id
+	testServer.PrepareResponse(200, nil, TestSendMessageFifoXmlOK)
+	resp, err := q.SendMessageWithOptions("This is a test message", &SendMessageOptions{MessageGroupId: "orders"})
+	c.Assert(err, IsNil)
+	c.Assert(resp.SequenceNumber, Equals, "18849496460467696128")
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["MessageGroupId"], DeepEquals, []string{"orders"})
+	c.Assert(req.Form["MessageDeduplicationId"], IsNil)
+	c.Assert(req.Form["DelaySeconds"], IsNil)
+
+	testServer.PrepareResponse(200, nil, TestSendMessageFifoXmlOK)
+	_, err = q.SendMessageWithOptions("This is a test message", &SendMessageOptions{MessageGroupId: "orders", MessageDeduplicationId: "order-1"})
+	c.Assert(err, IsNil)
+	req = testServer.WaitRequest()
+	c.Assert(req.Form["MessageDeduplicationId"], DeepEquals, []string{"order-1"})
+
+	// Messages without group fail before being sent
+	_, err = q.SendMessage("This is a test message")
+	c.Assert(err, Equals, ErrNoMessageGroupId)
+	_, err = q.SendMessageWithDelay("This is a test message", 10)
+	c.Assert(err, Equals, ErrNoMessageGroupId)
+}
+
+func (s *S) TestSendMessageBatchFifo(c *C) {
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue.fifo"}
+
+	testServer.PrepareResponse(200, nil, TestSendMessageBatchXmlOk)
+	_, err := q.SendMessageBatchEntries([]SendMessageBatchRequestEntry{
+		{Id: "test_msg_001", MessageBody: "test message body 1", MessageGroupId: "orders"},
+		{Id: "test_msg_002", MessageBody: "test message body 2", MessageGroupId: "orders", MessageDeduplicationId: "order-2"},
+	})
+	c.Assert(err, IsNil)
+	req := testServer.WaitRequest()
+	c.Assert(req.Form["SendMessageBatchRequestEntry.1.MessageGroupId"], DeepEquals, []string{"orders"})
+	c.Assert(req.Form["SendMessageBatchRequestEntry.1.MessageDeduplicationId"], IsNil)
+	c.Assert(req.Form["SendMessageBatchRequestEntry.2.MessageDeduplicationId"], DeepEquals, []string{"order-2"})
+
+	_, err = q.SendMessageBatchEntries([]SendMessageBatchRequestEntry{
+		{Id: "test_msg_001", MessageBody: "test message body 1", MessageGroupId: "orders"},
+		{Id: "test_msg_002", MessageBody: "test message body 2"},
+	})
+	c.Assert(err, ErrorMatches, `sqs: batch entry 1 \("test_msg_002"\): no MessageGroupId for a FIFO queue`)
+}
+
+func (s *S) TestReceiveMessageFifo(c *C) {
+	testServer.PrepareResponse(200, nil, TestReceiveMessageFifoXmlOK)
+
+	q := &Queue{s.sqs, testServer.URL + "/123456789012/testQueue.fifo"}
+	resp, err := q.ReceiveMessage(1)
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+
+	msg := resp.Messages[0]
+	c.Assert(msg.SequenceNumber, Equals, "18849496460467696128")
+	c.Assert(msg.MessageGroupId, Equals, "orders")
+	c.Assert(msg.MessageDeduplicationId, Equals, "4c6c4d9e8d1f5b0e2a3f1b7c9d0e8f6a4b2c1d3e5f7a9b0c2d4e6f8a1b3c5d7e")
+}
@@ -8,7 +8,6 @@ package sts
 
 import (
 	"encoding/xml"
-	"fmt"
 	"log"
 	"net/http"
 	"net/http/httputil"
@@ -45,28 +44,7 @@ const debug = false
 // Error encapsulates an error returned by the AWS STS API.
 //
 // See http://goo.gl/zDZbuQ  for more details.
-type Error struct {
-	// HTTP status code (200, 403, ...)
-	StatusCode int
-	// STS error code
-	Code string
-	// The human-oriented error message
-	Message   string
-	RequestId string `xml:"RequestID"`
-}
-
-func (err *Error) Error() string {
-	if err.Code == "" {
-		return err.Message
-	}
-
-	return fmt.Sprintf("%s (%s)", err.Message, err.Code)
-}
-
-type xmlErrors struct {
-	RequestId string  `xml:"RequestId"`
-	Errors    []Error `xml:"Error"`
-}
+type Error = aws.Error
 
 func (sts *STS) query(params map[string]string, resp interface{}) error {
 	params["Version"] = "2011-06-15"
@@ -113,21 +91,7 @@ func (sts *STS) query(params map[string]string, resp interface{}) error {
 }
 
 func buildError(r *http.Response) error {
-	var (
-		err    Error
-		errors xmlErrors
-	)
-	xml.NewDecoder(r.Body).Decode(&errors)
-	if len(errors.Errors) > 0 {
-		err = errors.Errors[0]
-	}
-
-	err.RequestId = errors.RequestId
-	err.StatusCode = r.StatusCode
-	if err.Message == "" {
-		err.Message = r.Status
-	}
-	return &err
+	return aws.BuildError(r)
 }
 
 func makeParams(action string) map[string]string {